@@ -0,0 +1,243 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+// ajvError is a validation error in the shape of the errors of ajv.
+type ajvError struct {
+	InstancePath string                 `json:"instancePath"`
+	SchemaPath   string                 `json:"schemaPath"`
+	Keyword      string                 `json:"keyword"`
+	Params       map[string]interface{} `json:"params"`
+	Message      string                 `json:"message"`
+}
+
+// The units of the length limits in the messages of ajv.
+var ajvLimitUnits = map[string]string{
+	"minLength":     "characters",
+	"maxLength":     "characters",
+	"minItems":      "items",
+	"maxItems":      "items",
+	"minProperties": "properties",
+	"maxProperties": "properties",
+}
+
+// The comparisons of the format limit keywords in the messages of ajv.
+var ajvFormatComparisons = map[string]string{
+	"formatMinimum":          ">=",
+	"formatMaximum":          "<=",
+	"formatExclusiveMinimum": ">",
+	"formatExclusiveMaximum": "<",
+}
+
+// ajvErrors converts the errors of the result to the errors of ajv. The
+// params and messages of ajv are built from the values of the failing
+// keywords, which are read from the schemas that hold them.
+func (r *Result) ajvErrors() ([]ajvError, error) {
+	rootSchemaID := r.schema.id()
+
+	// The json form of the schemas by their URI, so each of them is
+	// marshaled once.
+	schemas := map[string]json.RawMessage{}
+
+	errors := make([]ajvError, 0, len(r.errors))
+	for _, validationError := range r.errors {
+		location := strings.SplitN(validationError.AbsoluteKeywordLocation, "#", 2)
+		uri, fragment := location[0], ""
+		if len(location) > 1 {
+			fragment = location[1]
+		}
+
+		ajv := ajvError{
+			InstancePath: validationError.InstanceLocation,
+			SchemaPath:   validationError.AbsoluteKeywordLocation,
+			Keyword:      validationError.Keyword,
+			Params:       map[string]interface{}{},
+			Message:      validationError.Message,
+		}
+		if uri == rootSchemaID {
+			ajv.SchemaPath = "#" + fragment
+		}
+
+		// The keywords and the false schemas of "additionalProperties" and
+		// "additionalItems" are held by the parent schema.
+		parentFragment := fragment
+		if index := strings.LastIndex(fragment, "/"); index >= 0 {
+			token := fragment[index+1:]
+			if ajv.Keyword != "" || token == "additionalProperties" || token == "additionalItems" {
+				parentFragment = fragment[:index]
+			}
+		}
+
+		raw, ok := schemas[uri]
+		if !ok {
+			rootSchema := r.schema
+			if uri != rootSchemaID {
+				rootSchema, _ = r.schema.registries().lookup(uri)
+			}
+
+			if rootSchema != nil {
+				var err error
+				raw, err = json.Marshal(&rootSchema.JsonSchema)
+				if err != nil {
+					return nil, err
+				}
+			}
+			schemas[uri] = raw
+		}
+
+		var parent map[string]interface{}
+		if pointer, err := jsonwalker.NewJsonPointer("#" + parentFragment); err == nil && raw != nil {
+			value, _ := pointer.EvaluateUseNumber(raw)
+			parent, _ = value.(map[string]interface{})
+		}
+
+		ajv.setParams(validationError.params, parent, fragment)
+		errors = append(errors, ajv)
+	}
+
+	return errors, nil
+}
+
+// setParams sets the params and the message of the error according to its
+// keyword. params are the details of the failure that the keyword reported,
+// and parent is the schema that holds the keyword.
+func (e *ajvError) setParams(params map[string]interface{}, parent map[string]interface{}, fragment string) {
+	for key, value := range params {
+		e.Params[key] = value
+	}
+
+	value := parent[e.Keyword]
+	switch e.Keyword {
+	case "":
+		e.setFalseSchemaParams(parent, fragment)
+	case "type":
+		e.Params["type"] = value
+		e.Message = "must be " + ajvTypes(value)
+	case "required":
+		e.Message = fmt.Sprintf("must have required property '%v'", params["missingProperty"])
+	case "patternRequired":
+		e.Message = fmt.Sprintf("must have property matching pattern \"%v\"", params["missingPattern"])
+	case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum":
+		e.setBoundParams(parent)
+	case "minLength", "maxLength", "minItems", "maxItems", "minProperties", "maxProperties":
+		e.Params["limit"] = value
+		comparison := "more"
+		if strings.HasPrefix(e.Keyword, "min") {
+			comparison = "fewer"
+		}
+		e.Message = fmt.Sprintf("must NOT have %s than %v %s", comparison, value, ajvLimitUnits[e.Keyword])
+	case "formatMinimum", "formatMaximum", "formatExclusiveMinimum", "formatExclusiveMaximum":
+		comparison := ajvFormatComparisons[e.Keyword]
+		e.Params["comparison"] = comparison
+		e.Params["limit"] = value
+		e.Message = fmt.Sprintf("must be %s \"%v\"", comparison, value)
+	case "multipleOf":
+		e.Params["multipleOf"] = value
+		e.Message = fmt.Sprintf("must be multiple of %v", value)
+	case "pattern", "format":
+		e.Params[e.Keyword] = value
+		e.Message = fmt.Sprintf("must match %s \"%v\"", e.Keyword, value)
+	case "enum":
+		e.Params["allowedValues"] = value
+		e.Message = "must be equal to one of the allowed values"
+	case "const":
+		e.Params["allowedValue"] = value
+		e.Message = "must be equal to constant"
+	case "uniqueItems":
+		e.Message = fmt.Sprintf("must NOT have duplicate items (items ## %v and %v are identical)", params["j"], params["i"])
+	case "dependencies":
+		if params == nil {
+			break
+		}
+
+		properties := "property"
+		if params["depsCount"] != 1 {
+			properties = "properties"
+		}
+		e.Message = fmt.Sprintf("must have %s %v when property %v is present", properties, params["deps"], params["property"])
+	case "propertyNames":
+		e.Message = "property name must be valid"
+	case "anyOf":
+		e.Message = "must match a schema in anyOf"
+	case "oneOf":
+		if _, ok := e.Params["passingSchemas"]; !ok {
+			e.Params["passingSchemas"] = nil
+		}
+		e.Message = "must match exactly one schema in oneOf"
+	case "not":
+		e.Message = "must NOT be valid"
+	case "contains":
+		e.Params["minContains"] = 1
+		e.Message = "must contain at least 1 valid item(s)"
+	}
+}
+
+// setFalseSchemaParams sets the params of an error of a false schema. ajv
+// reports the false schemas of "additionalProperties" and "additionalItems"
+// as failures of these keywords, at the location of the parent value.
+func (e *ajvError) setFalseSchemaParams(parent map[string]interface{}, fragment string) {
+	index := strings.LastIndex(e.InstancePath, "/")
+	switch {
+	case strings.HasSuffix(fragment, "/additionalProperties") && index >= 0:
+		e.Keyword = "additionalProperties"
+		e.Params["additionalProperty"] = jsonwalker.UnescapeToken(e.InstancePath[index+1:])
+		e.InstancePath = e.InstancePath[:index]
+		e.Message = "must NOT have additional properties"
+	case strings.HasSuffix(fragment, "/additionalItems") && index >= 0:
+		items, _ := parent["items"].([]interface{})
+		e.Keyword = "additionalItems"
+		e.Params["limit"] = len(items)
+		e.InstancePath = e.InstancePath[:index]
+		e.Message = fmt.Sprintf("must NOT have more than %d items", len(items))
+	default:
+		e.Keyword = "false schema"
+		e.Message = "boolean schema is false"
+	}
+}
+
+// setBoundParams sets the params of an error of a numeric bound, where the
+// exclusive bounds may be numbers or draft 4 flags of the inclusive bounds.
+func (e *ajvError) setBoundParams(parent map[string]interface{}) {
+	bound := strings.ToLower(strings.TrimPrefix(e.Keyword, "exclusive"))
+	exclusive := "exclusiveM" + bound[1:]
+
+	comparison := ">"
+	if bound == "maximum" {
+		comparison = "<"
+	}
+
+	limit := parent[e.Keyword]
+	if flag, ok := parent[exclusive].(bool); ok {
+		limit = parent[bound]
+		if !flag {
+			comparison += "="
+		}
+	} else if e.Keyword == bound {
+		comparison += "="
+	}
+
+	e.Params["comparison"] = comparison
+	e.Params["limit"] = limit
+	e.Message = fmt.Sprintf("must be %s %v", comparison, limit)
+}
+
+// ajvTypes returns the value of a "type" keyword as ajv writes it in its
+// messages.
+func ajvTypes(value interface{}) string {
+	types, ok := value.([]interface{})
+	if !ok {
+		return fmt.Sprint(value)
+	}
+
+	names := make([]string, len(types))
+	for index, jsonType := range types {
+		names[index] = fmt.Sprint(jsonType)
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,153 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+// SchemaAt returns the schema that describes the value at the given location
+// in an instance of the root schema. pointer is a json pointer into the
+// instance (not into the schema), for example "/address/street" or "/tags/0".
+// The schema of every token is looked up in "properties", then in
+// "patternProperties" and "additionalProperties", and for array indices in
+// "items" and "additionalItems", following $ref references.
+// It returns nil if no schema describes the location.
+func (rs *RootJsonSchema) SchemaAt(pointer string) (*JsonSchema, error) {
+	tokens, err := jsonwalker.NewJsonPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	id := rs.id()
+
+	schema, id, err := rs.JsonSchema.followRefs(id, rs.registries())
+	for _, token := range tokens {
+		if schema == nil || err != nil {
+			return nil, err
+		}
+
+		schema, err = schema.childSchema(token)
+		if schema != nil && err == nil {
+			schema, id, err = schema.followRefs(id, rs.registries())
+		}
+	}
+
+	return schema, err
+}
+
+// DefaultAt returns the raw "default" value of the schema that describes the
+// given instance location, or nil if there is none.
+func (rs *RootJsonSchema) DefaultAt(pointer string) (json.RawMessage, error) {
+	schema, err := rs.SchemaAt(pointer)
+	if schema == nil || err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(schema.Default), nil
+}
+
+// ExamplesAt returns the "examples" of the schema that describes the given
+// instance location, or nil if there are none.
+func (rs *RootJsonSchema) ExamplesAt(pointer string) ([]interface{}, error) {
+	schema, err := rs.SchemaAt(pointer)
+	if schema == nil || err != nil {
+		return nil, err
+	}
+
+	return []interface{}(schema.Examples), nil
+}
+
+// TitleAt returns the "title" of the schema that describes the given instance
+// location, or an empty string if there is none.
+func (rs *RootJsonSchema) TitleAt(pointer string) (string, error) {
+	schema, err := rs.SchemaAt(pointer)
+	if schema == nil || schema.Title == nil || err != nil {
+		return "", err
+	}
+
+	return string(*schema.Title), nil
+}
+
+// DescriptionAt returns the "description" of the schema that describes the
+// given instance location, or an empty string if there is none.
+func (rs *RootJsonSchema) DescriptionAt(pointer string) (string, error) {
+	schema, err := rs.SchemaAt(pointer)
+	if schema == nil || schema.Description == nil || err != nil {
+		return "", err
+	}
+
+	return string(*schema.Description), nil
+}
+
+// TypesAt returns the json types that the "type" keyword of the schema that
+// describes the given instance location allows, or nil if the schema does not
+// restrict the type of the location.
+func (rs *RootJsonSchema) TypesAt(pointer string) ([]string, error) {
+	schema, err := rs.SchemaAt(pointer)
+	if schema == nil || schema.Type == nil || err != nil {
+		return nil, err
+	}
+
+	return append([]string(nil), schema.Type.names...), nil
+}
+
+// followRefs returns the schema that the receiver references by $ref (which
+// overrides all of its other keywords), or the receiver itself if it has no
+// $ref field, and the id of the root schema that holds it, against which
+// its own references are resolved. The references are resolved in the
+// registries.
+func (js *JsonSchema) followRefs(rootSchemaID string, registries registries) (*JsonSchema, string, error) {
+	visited := map[*JsonSchema]bool{}
+	for js.Ref != nil && !visited[js] {
+		visited[js] = true
+
+		schema, err := js.Ref.resolveIn(rootSchemaID, registries)
+		if err != nil {
+			return nil, "", err
+		}
+
+		absoluteURI := js.Ref.absoluteURIIn(rootSchemaID, registries)
+		rootSchemaID = absoluteURI[:strings.Index(absoluteURI, "#")]
+		js = schema
+	}
+
+	return js, rootSchemaID, nil
+}
+
+// childSchema returns the sub-schema of the receiver that describes the
+// property or the array item that token represents, or nil if there is none.
+func (js *JsonSchema) childSchema(token string) (*JsonSchema, error) {
+	if schema, ok := js.Properties[token]; ok {
+		return schema, nil
+	}
+
+	// The patterns are sorted so the same schema is returned every time a
+	// property matches more than one pattern.
+	patterns := make([]string, 0, len(js.PatternProperties))
+	for pattern := range js.PatternProperties {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+		if js.PatternProperties[pattern].propertyPattern.MatchString(token) {
+			return js.PatternProperties[pattern], nil
+		}
+	}
+
+	// A token that is not an array index can only be a property name.
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || js.tuple == nil {
+		if js.AdditionalProperties != nil {
+			return &js.AdditionalProperties.JsonSchema, nil
+		}
+
+		return nil, nil
+	}
+
+	return js.tuple.schemaAt(index), nil
+}
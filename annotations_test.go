@@ -0,0 +1,105 @@
+package jsonvalidator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnnotationsAt(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/annotations.json",
+		"title": "order",
+		"properties": {
+			"address": {"$ref": "#/definitions/address"},
+			"tags": {"type": "array", "items": {"title": "tag", "type": ["string", "null"], "examples": ["new", "sale"]}},
+			"point": {"items": [{"title": "x"}, {"title": "y"}], "additionalItems": {"title": "extra"}}
+		},
+		"patternProperties": {"^x-": {"description": "an extension"}},
+		"additionalProperties": {"title": "other"},
+		"definitions": {
+			"address": {
+				"properties": {
+					"city": {"title": "city", "description": "the city name", "default": "Tel Aviv"}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	titles := map[string]string{
+		"":           "order",
+		"/tags/3":    "tag",
+		"/point/0":   "x",
+		"/point/1":   "y",
+		"/point/2":   "extra",
+		"/unknown":   "other",
+		"/address/a": "",
+		"/x-custom":  "",
+	}
+	for pointer, expected := range titles {
+		title, err := rootSchema.TitleAt(pointer)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", pointer, err)
+		} else if title != expected {
+			t.Errorf("%q: expected title %q, got %q", pointer, expected, title)
+		}
+	}
+
+	if description, _ := rootSchema.DescriptionAt("/address/city"); description != "the city name" {
+		t.Errorf("expected the description of the referenced schema, got %q", description)
+	}
+	if description, _ := rootSchema.DescriptionAt("/x-custom"); description != "an extension" {
+		t.Errorf("expected the description of the pattern property, got %q", description)
+	}
+	if value, _ := rootSchema.DefaultAt("/address/city"); string(value) != `"Tel Aviv"` {
+		t.Errorf("expected the default of the referenced schema, got %s", value)
+	}
+	if examples, _ := rootSchema.ExamplesAt("/tags/0"); !reflect.DeepEqual(examples, []interface{}{"new", "sale"}) {
+		t.Errorf("expected the examples of the items schema, got %v", examples)
+	}
+	if types, _ := rootSchema.TypesAt("/tags"); !reflect.DeepEqual(types, []string{"array"}) {
+		t.Errorf("expected the type of tags, got %v", types)
+	}
+	if types, _ := rootSchema.TypesAt("/tags/1"); !reflect.DeepEqual(types, []string{"string", "null"}) {
+		t.Errorf("expected the types of the items schema, got %v", types)
+	}
+	if types, _ := rootSchema.TypesAt("/address"); types != nil {
+		t.Errorf("expected no types, got %v", types)
+	}
+
+	// The items of "tags" have no properties, so nothing describes the location.
+	if schema, err := rootSchema.SchemaAt("/tags/0/name"); schema != nil || err != nil {
+		t.Errorf("expected no schema, got %v, %v", schema, err)
+	}
+	if _, err := rootSchema.TitleAt("address"); err == nil {
+		t.Error("expected an error for a pointer without a '/' prefix")
+	}
+}
+
+func TestSchemaAtAcrossRootSchemas(t *testing.T) {
+	_, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/annotations/common.json",
+		"definitions": {
+			"person": {"properties": {"name": {"$ref": "#/definitions/name"}}},
+			"name": {"title": "name"}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/annotations/team.json",
+		"properties": {"lead": {"$ref": "common.json#/definitions/person"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The reference to "name" is resolved against the schema that holds it.
+	if title, err := rootSchema.TitleAt("/lead/name"); err != nil || title != "name" {
+		t.Errorf("expected the title of the referenced schema, got %q (%v)", title, err)
+	}
+}
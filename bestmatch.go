@@ -0,0 +1,91 @@
+package jsonvalidator
+
+import (
+	"strconv"
+	"strings"
+)
+
+// The keywords whose failures suggest that a sub-schema was not meant for
+// the value at all, rather than that the value is a broken instance of it.
+var weakKeywords = map[string]bool{
+	"type":  true,
+	"const": true,
+	"enum":  true,
+	"anyOf": true,
+	"oneOf": true,
+}
+
+// BestMatch returns the most relevant error of a failed validation. When
+// "anyOf" or "oneOf" fail because the value is not valid against any of
+// their sub-schemas, it returns the error of the sub-schema that matched
+// the value best, descending into nested "anyOf" and "oneOf" keywords.
+// Otherwise, it returns err.
+//
+// The sub-schema that matched best is the one whose error is the deepest in
+// the value, because the sub-schema accepted more of the value before it
+// failed. Between errors of the same depth, errors of keywords like "type"
+// and "const", which suggest that the sub-schema was not meant for the value,
+// are less relevant than the others.
+func BestMatch(err error) error {
+	for {
+		schemaValidationError, ok := err.(SchemaValidationError)
+		if !ok || schemaValidationError.bestMatch == nil {
+			return err
+		}
+
+		err = *schemaValidationError.bestMatch
+	}
+}
+
+// noMatchError returns the error of an "anyOf" or "oneOf" keyword whose
+// sub-schemas all failed with the given errors. The error of the best
+// matching sub-schema is part of the message, and it is kept for
+// BestMatch().
+func noMatchError(keyword string, errs []error) error {
+	reason := "inspected value could not be validated against any of the given schemas"
+
+	index, best, ok := bestMatch(errs)
+	if !ok {
+		return KeywordValidationError{
+			keyword: keyword,
+			reason:  reason,
+		}
+	}
+
+	return KeywordValidationError{
+		keyword:   keyword,
+		reason:    reason + ", the best match is schema " + strconv.Itoa(index) + ": " + best.err,
+		bestMatch: &best,
+		cause:     best,
+	}
+}
+
+// bestMatch returns the index and the error of the sub-schema that matched
+// the value best, as described in BestMatch(). It returns false if none of
+// the errors is a SchemaValidationError.
+func bestMatch(errs []error) (int, SchemaValidationError, bool) {
+	bestIndex := -1
+	var best SchemaValidationError
+	for index, err := range errs {
+		schemaValidationError, ok := err.(SchemaValidationError)
+		if !ok {
+			continue
+		}
+
+		if bestIndex < 0 || isMoreRelevant(schemaValidationError, best) {
+			bestIndex, best = index, schemaValidationError
+		}
+	}
+
+	return bestIndex, best, bestIndex >= 0
+}
+
+// isMoreRelevant returns true if the error e is more relevant than other.
+func isMoreRelevant(e SchemaValidationError, other SchemaValidationError) bool {
+	depth, otherDepth := strings.Count(e.path, "/"), strings.Count(other.path, "/")
+	if depth != otherDepth {
+		return depth > otherDepth
+	}
+
+	return !weakKeywords[e.keyword] && weakKeywords[other.keyword]
+}
@@ -0,0 +1,71 @@
+package jsonvalidator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBestMatch(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/bestmatch.json",
+		"properties": {
+			"shape": {
+				"oneOf": [
+					{"type": "string"},
+					{
+						"type": "object",
+						"required": ["kind", "radius"],
+						"properties": {"kind": {"const": "circle"}, "radius": {"type": "number", "minimum": 0}}
+					},
+					{
+						"type": "object",
+						"required": ["kind", "width"],
+						"properties": {"kind": {"const": "square"}, "width": {"type": "number"}}
+					}
+				]
+			},
+			"id": {"anyOf": [{"type": "integer"}, {"type": "string", "pattern": "^[a-z]+$"}]}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := NewValidator(rootSchema)
+	testCases := []struct {
+		document        string
+		path            string
+		keyword         string
+		keywordLocation string
+	}{
+		{`{"shape": {"kind": "circle", "radius": -1}}`, "/shape/radius", "minimum", "/properties/shape/oneOf/1/properties/radius/minimum"},
+		{`{"shape": {"kind": "square", "width": "1"}}`, "/shape/width", "type", "/properties/shape/oneOf/2/properties/width/type"},
+		{`{"shape": {"kind": "circle"}}`, "/shape", "required", "/properties/shape/oneOf/1/required"},
+		{`{"shape": 1}`, "/shape", "type", "/properties/shape/oneOf/0/type"},
+		{`{"id": "A1"}`, "/id", "pattern", "/properties/id/anyOf/1/pattern"},
+	}
+
+	for _, testCase := range testCases {
+		err := validator.Validate([]byte(testCase.document))
+		if err == nil {
+			t.Errorf("%s: expected an error", testCase.document)
+			continue
+		}
+
+		best, ok := BestMatch(err).(SchemaValidationError)
+		if !ok {
+			t.Errorf("%s: expected a SchemaValidationError, got %v", testCase.document, BestMatch(err))
+			continue
+		}
+		if best.Path() != testCase.path || best.Keyword() != testCase.keyword || best.KeywordLocation() != testCase.keywordLocation {
+			t.Errorf("%s: unexpected best match %s %s %s", testCase.document, best.Path(), best.Keyword(), best.KeywordLocation())
+		}
+		if !strings.Contains(err.Error(), "the best match is schema") {
+			t.Errorf("%s: expected the best match in the message, got %v", testCase.document, err)
+		}
+	}
+
+	if err := BestMatch(validator.Validate([]byte(`{"shape": "x"}`))); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package jsonvalidator
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+)
+
+// ResultCache holds the results of validations by the fingerprint of the
+// schema, the generation of the registries that its references are resolved
+// in and the hash of the document, so validators that use it return
+// the prior result for identical documents, like duplicate webhook
+// deliveries or redelivered queue messages, without validating them again.
+// The least recently used results are evicted once the cache is full. A
+// ResultCache is safe for concurrent use.
+// Results depend on the options of the validators too, so validators that
+// share a cache must have the same options.
+type ResultCache struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  map[resultKey]*list.Element
+	order    *list.List
+}
+
+// resultKey identifies a validation by the fingerprint of the schema, the
+// generation of its registries (see registries.generation()) and the hash
+// of the document.
+type resultKey struct {
+	schema     [sha256.Size]byte
+	generation uint64
+	document   [sha256.Size]byte
+}
+
+type resultEntry struct {
+	key resultKey
+	err error
+}
+
+// NewResultCache creates a ResultCache that holds up to capacity results.
+func NewResultCache(capacity int) *ResultCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &ResultCache{
+		capacity: capacity,
+		entries:  map[resultKey]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+// Len returns the number of results in the cache.
+func (c *ResultCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *ResultCache) get(key resultKey) (error, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return element.Value.(*resultEntry).err, true
+}
+
+func (c *ResultCache) put(key resultKey, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*resultEntry).err = err
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&resultEntry{key, err})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*resultEntry).key)
+	}
+}
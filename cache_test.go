@@ -0,0 +1,84 @@
+package jsonvalidator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidatorCache(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{"properties": {"id": {"type": "integer"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	visits := 0
+	cache := NewResultCache(2)
+	validator := NewValidator(rootSchema).Cache(cache).OnProgress(1, func(progress Progress) error {
+		visits++
+		return nil
+	})
+
+	documents := []string{`{"id": 1}`, `{"id": "1"}`, `{"id": 1}`, `{"id": "1"}`}
+	for index, document := range documents {
+		err := validator.Validate([]byte(document))
+		if (index%2 == 1) != (err != nil) {
+			t.Errorf("%s: unexpected result %v", document, err)
+		}
+	}
+
+	// The repeated documents are not validated again.
+	first := visits
+	validator.Validate([]byte(`{"id": 1}`))
+	if visits != first {
+		t.Errorf("expected a cached result, but the document was validated")
+	}
+
+	// The least recently used result is evicted.
+	validator.Validate([]byte(`{"id": 2}`))
+	if cache.Len() != 2 {
+		t.Errorf("expected 2 cached results, got %d", cache.Len())
+	}
+	first = visits
+	validator.Validate([]byte(`{"id": "1"}`))
+	if visits == first {
+		t.Errorf("expected the evicted document to be validated")
+	}
+
+	// Aborted validations are not cached.
+	abort := errors.New("abort")
+	aborting := NewValidator(rootSchema).Cache(NewResultCache(2)).OnProgress(1, func(progress Progress) error {
+		return abort
+	})
+	if err := aborting.Validate([]byte(`{"id": 3}`)); err != abort {
+		t.Errorf("expected the abort error, got %v", err)
+	}
+	if aborting.cache.Len() != 0 {
+		t.Errorf("expected the aborted validation not to be cached")
+	}
+}
+
+func TestValidatorCacheRegistryChanges(t *testing.T) {
+	registry := NewRegistry()
+	_, err := registry.AddSchema("http://example.com/cache/leaf.json", []byte(`{"type": "string"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootSchema, err := registry.AddSchema("http://example.com/cache/common.json", []byte(`{"$ref": "leaf.json"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := NewValidator(rootSchema).Cache(NewResultCache(10))
+	if err := validator.Validate([]byte(`"abcd"`)); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// A result is not reused once a referenced schema is replaced.
+	_, err = registry.AddSchema("http://example.com/cache/leaf.json", []byte(`{"type": "string", "maxLength": 3}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validator.Validate([]byte(`"abcd"`)); err == nil {
+		t.Error("expected the replaced schema to be used")
+	}
+}
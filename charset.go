@@ -0,0 +1,119 @@
+package jsonvalidator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// The byte order marks of the Unicode encodings.
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf32BEBOM = []byte{0x00, 0x00, 0xFE, 0xFF}
+	utf32LEBOM = []byte{0xFF, 0xFE, 0x00, 0x00}
+)
+
+// toUTF8 returns a json document in UTF-8, without a byte order mark. The
+// document may start with a byte order mark, or be encoded in UTF-16 or
+// UTF-32, which RFC 4627 allowed. Without a byte order mark, the encoding is
+// detected from the pattern of the zero bytes in the first 4 bytes, since
+// the first 2 characters of a json document are ASCII (RFC 4627, section
+// 3 [RFC4627]). Documents in UTF-8 are returned as they are.
+// https://tools.ietf.org/html/rfc4627#section-3
+func toUTF8(document []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(document, utf8BOM):
+		return document[len(utf8BOM):], nil
+	case bytes.HasPrefix(document, utf32BEBOM):
+		return decodeUTF32(document[len(utf32BEBOM):], binary.BigEndian)
+	case bytes.HasPrefix(document, utf32LEBOM):
+		return decodeUTF32(document[len(utf32LEBOM):], binary.LittleEndian)
+	case bytes.HasPrefix(document, utf16BEBOM):
+		return decodeUTF16(document[len(utf16BEBOM):], binary.BigEndian)
+	case bytes.HasPrefix(document, utf16LEBOM):
+		return decodeUTF16(document[len(utf16LEBOM):], binary.LittleEndian)
+	}
+
+	if len(document) >= 4 {
+		switch {
+		case document[0] == 0 && document[1] == 0 && document[2] == 0 && document[3] != 0:
+			return decodeUTF32(document, binary.BigEndian)
+		case document[0] != 0 && document[1] == 0 && document[2] == 0 && document[3] == 0:
+			return decodeUTF32(document, binary.LittleEndian)
+		}
+	}
+
+	if len(document) >= 2 {
+		switch {
+		case document[0] == 0 && document[1] != 0:
+			return decodeUTF16(document, binary.BigEndian)
+		case document[0] != 0 && document[1] == 0:
+			return decodeUTF16(document, binary.LittleEndian)
+		}
+	}
+
+	return document, nil
+}
+
+// decodeUTF16 transcodes a document in UTF-16 to UTF-8.
+func decodeUTF16(document []byte, order binary.ByteOrder) ([]byte, error) {
+	if len(document)%2 != 0 {
+		return nil, errors.New("invalid UTF-16 document: odd number of bytes")
+	}
+
+	units := make([]uint16, len(document)/2)
+	for index := range units {
+		units[index] = order.Uint16(document[2*index:])
+	}
+
+	result := make([]byte, 0, len(units))
+	for index := 0; index < len(units); index++ {
+		r := rune(units[index])
+		if utf16.IsSurrogate(r) {
+			if index+1 == len(units) {
+				return nil, errors.New("invalid UTF-16 document: unpaired surrogate")
+			}
+
+			r = utf16.DecodeRune(r, rune(units[index+1]))
+			if r == utf8.RuneError {
+				return nil, errors.New("invalid UTF-16 document: unpaired surrogate")
+			}
+			index++
+		}
+
+		result = appendRune(result, r)
+	}
+
+	return result, nil
+}
+
+// decodeUTF32 transcodes a document in UTF-32 to UTF-8.
+func decodeUTF32(document []byte, order binary.ByteOrder) ([]byte, error) {
+	if len(document)%4 != 0 {
+		return nil, errors.New("invalid UTF-32 document: the number of bytes is not a multiple of 4")
+	}
+
+	result := make([]byte, 0, len(document)/4)
+	for index := 0; index < len(document); index += 4 {
+		r := rune(order.Uint32(document[index:]))
+		if !utf8.ValidRune(r) {
+			return nil, errors.New("invalid UTF-32 document: invalid code point")
+		}
+
+		result = appendRune(result, r)
+	}
+
+	return result, nil
+}
+
+// appendRune appends the UTF-8 encoding of a rune, like utf8.AppendRune(),
+// which was only added in go 1.18.
+func appendRune(result []byte, r rune) []byte {
+	var encoded [utf8.UTFMax]byte
+	length := utf8.EncodeRune(encoded[:], r)
+	return append(result, encoded[:length]...)
+}
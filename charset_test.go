@@ -0,0 +1,102 @@
+package jsonvalidator
+
+import (
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+)
+
+func encodeUTF16(s string, order binary.ByteOrder, bom bool) []byte {
+	var encoded []byte
+	units := utf16.Encode([]rune(s))
+	if bom {
+		units = append([]uint16{0xFEFF}, units...)
+	}
+	for _, unit := range units {
+		bytes := make([]byte, 2)
+		order.PutUint16(bytes, unit)
+		encoded = append(encoded, bytes...)
+	}
+
+	return encoded
+}
+
+func encodeUTF32(s string, order binary.ByteOrder, bom bool) []byte {
+	var encoded []byte
+	runes := []rune(s)
+	if bom {
+		runes = append([]rune{0xFEFF}, runes...)
+	}
+	for _, r := range runes {
+		bytes := make([]byte, 4)
+		order.PutUint32(bytes, uint32(r))
+		encoded = append(encoded, bytes...)
+	}
+
+	return encoded
+}
+
+func TestToUTF8(t *testing.T) {
+	const document = `{"name": "Zoë 😀"}`
+
+	testCases := []struct {
+		description string
+		input       []byte
+	}{
+		{"UTF-8", []byte(document)},
+		{"UTF-8 with a BOM", append([]byte{0xEF, 0xBB, 0xBF}, document...)},
+		{"UTF-16BE", encodeUTF16(document, binary.BigEndian, false)},
+		{"UTF-16BE with a BOM", encodeUTF16(document, binary.BigEndian, true)},
+		{"UTF-16LE", encodeUTF16(document, binary.LittleEndian, false)},
+		{"UTF-16LE with a BOM", encodeUTF16(document, binary.LittleEndian, true)},
+		{"UTF-32BE", encodeUTF32(document, binary.BigEndian, false)},
+		{"UTF-32BE with a BOM", encodeUTF32(document, binary.BigEndian, true)},
+		{"UTF-32LE", encodeUTF32(document, binary.LittleEndian, false)},
+		{"UTF-32LE with a BOM", encodeUTF32(document, binary.LittleEndian, true)},
+		{"a short UTF-16LE document", encodeUTF16("1", binary.LittleEndian, false)},
+	}
+
+	for _, testCase := range testCases {
+		expected := document
+		if len(testCase.input) == 2 {
+			expected = "1"
+		}
+
+		output, err := toUTF8(testCase.input)
+		if err != nil || string(output) != expected {
+			t.Errorf("%s: expected %q, got %q (%v)", testCase.description, expected, output, err)
+		}
+	}
+
+	invalid := [][]byte{
+		{0xFE, 0xFF, 0x00},
+		{0xFE, 0xFF, 0xD8, 0x3D},
+		{0xFE, 0xFF, 0xDE, 0x00, 0x00, 0x31},
+		{0x00, 0x00, 0xFE, 0xFF, 0x00, 0x11, 0x00, 0x00},
+		{0xFF, 0xFE, 0x00, 0x00, 0x31, 0x00},
+	}
+	for _, input := range invalid {
+		if _, err := toUTF8(input); err == nil {
+			t.Errorf("%x: expected an error", input)
+		}
+	}
+}
+
+func TestEncodedInputs(t *testing.T) {
+	schema := `{"properties": {"name": {"type": "string", "maxLength": 5}}}`
+	rootSchema, err := NewRootJsonSchema(encodeUTF16(schema, binary.LittleEndian, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewJsonSchema(append([]byte{0xEF, 0xBB, 0xBF}, schema...)); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	validator := NewValidator(rootSchema)
+	if err := validator.Validate(encodeUTF32(`{"name": "Zoë"}`, binary.BigEndian, false)); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := validator.Validate(encodeUTF16(`{"name": "Zoë 😀"}`, binary.BigEndian, true)); err == nil {
+		t.Errorf("expected a name that is too long to be invalid")
+	}
+}
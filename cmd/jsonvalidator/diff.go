@@ -0,0 +1,61 @@
+//go:build !js
+// +build !js
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/itayankri/gojsonvalidator/schemadiff"
+)
+
+// runDiff prints the changes between two schema files as
+// "#<json pointer>: breaking|non-breaking: <message>". It fails if one of
+// the changes is breaking, so it can gate schema changes.
+func runDiff(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	breakingOnly := flags.Bool("breaking", false, "print only the breaking changes")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: jsonvalidator diff [-breaking] <old schema file> <new schema file>")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return 2
+	}
+
+	documents := make([][]byte, 2)
+	for index, path := range flags.Args() {
+		var err error
+		documents[index], err = ioutil.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "jsonvalidator: %v\n", err)
+			return 2
+		}
+	}
+
+	changes, err := schemadiff.Diff(documents[0], documents[1])
+	if err != nil {
+		fmt.Fprintf(stderr, "jsonvalidator: %v\n", err)
+		return 2
+	}
+
+	for _, change := range changes {
+		if change.Breaking || !*breakingOnly {
+			fmt.Fprintln(stdout, change)
+		}
+	}
+
+	if schemadiff.HasBreaking(changes) {
+		return 1
+	}
+	return 0
+}
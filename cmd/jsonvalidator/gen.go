@@ -0,0 +1,118 @@
+//go:build !js
+// +build !js
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/codegen"
+	"github.com/itayankri/gojsonvalidator/protoschema"
+)
+
+// The generators of the gen command, by the name of their language.
+var generators = map[string]func(schema *jsonvalidator.RootJsonSchema, packageName string, typeName string) ([]byte, error){
+	"go":    generateGo,
+	"proto": protoschema.GenerateProto,
+	"ts":    codegen.GenerateTypeScript,
+}
+
+// runGen generates the types that describe the objects of a schema file in
+// Go, TypeScript or protobuf, and writes them to stdout or to the -o file.
+func runGen(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	packageName := flags.String("package", "", "the Go package, the proto package or the TypeScript namespace of the types")
+	typeName := flags.String("type", "", "the name of the root type (default: the name of the schema file)")
+	output := flags.String("o", "", "the file to write the types to (default: stdout)")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: jsonvalidator gen <go|ts|proto> [-package name] [-type name] [-o file] <schema file>")
+		flags.PrintDefaults()
+	}
+
+	if len(args) == 0 || generators[args[0]] == nil {
+		flags.Usage()
+		return 2
+	}
+	generate := generators[args[0]]
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	path := flags.Arg(0)
+	rootSchema, err := jsonvalidator.LoadRootJsonSchema(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", path, err)
+		return 1
+	}
+
+	if *typeName == "" {
+		*typeName = typeNameOf(path)
+	}
+
+	source, err := generate(rootSchema, *packageName, *typeName)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", path, err)
+		return 1
+	}
+
+	if *output == "" {
+		stdout.Write(source)
+		return 0
+	}
+
+	err = ioutil.WriteFile(*output, source, 0644)
+	if err != nil {
+		fmt.Fprintf(stderr, "jsonvalidator: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// generateGo generates Go types in the "schema" package, unless another
+// package is given.
+func generateGo(schema *jsonvalidator.RootJsonSchema, packageName string, typeName string) ([]byte, error) {
+	if packageName == "" {
+		packageName = "schema"
+	}
+
+	return codegen.GenerateGo(schema, packageName, typeName)
+}
+
+// typeNameOf returns the CamelCase name of a schema file without its
+// extensions, so "order-line.schema.json" becomes "OrderLine".
+func typeNameOf(path string) string {
+	name := filepath.Base(path)
+	if index := strings.Index(name, "."); index > 0 {
+		name = name[:index]
+	}
+
+	var builder strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+		}
+		builder.WriteRune(r)
+		upper = false
+	}
+
+	return builder.String()
+}
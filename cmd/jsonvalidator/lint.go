@@ -0,0 +1,83 @@
+//go:build !js
+// +build !js
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/schemalint"
+)
+
+// runLint runs the checks of schemalint over schema files, and prints every
+// finding as "<file>#<json pointer>: <severity>: <message> [<check>]". It
+// fails if there are errors, or with -strict if there are warnings too.
+func runLint(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	strict := flags.Bool("strict", false, "fail on warnings too")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: jsonvalidator lint [-strict] <schema file>...")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	failed := false
+	for _, path := range flags.Args() {
+		findings, err := lintFile(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", path, err)
+			failed = true
+			continue
+		}
+
+		for _, finding := range findings {
+			fmt.Fprintf(stdout, "%s%s\n", path, finding)
+			if finding.Severity == schemalint.Error || *strict {
+				failed = true
+			}
+		}
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// lintFile lints the schema file at path. Schemas that cannot be created,
+// for example because of an invalid pattern, are linted as documents, with
+// their creation error as a finding of the root schema.
+func lintFile(path string) ([]schemalint.Finding, error) {
+	rootSchema, err := jsonvalidator.LoadRootJsonSchema(path)
+	if err == nil {
+		return schemalint.Lint(rootSchema)
+	}
+
+	document, readErr := ioutil.ReadFile(path)
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	findings, lintErr := schemalint.LintDocument(document)
+	if lintErr != nil {
+		return nil, lintErr
+	}
+
+	return append([]schemalint.Finding{{
+		Severity: schemalint.Error,
+		Check:    "compile",
+		Message:  err.Error(),
+	}}, findings...), nil
+}
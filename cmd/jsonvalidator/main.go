@@ -0,0 +1,70 @@
+//go:build !js
+// +build !js
+
+// Command jsonvalidator works with json schema files from the command line.
+//
+// Usage:
+//
+//	jsonvalidator <command> [arguments]
+//
+// The commands are:
+//
+//	diff    print the changes between two versions of a schema
+//	gen     generate Go, TypeScript or protobuf types from a schema
+//	lint    report problems in schema files
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// A command runs with the arguments that follow its name, writes its output
+// to stdout and its diagnostics to stderr, and returns the exit code.
+type command struct {
+	description string
+	run         func(args []string, stdout io.Writer, stderr io.Writer) int
+}
+
+var commands = map[string]command{
+	"diff": {"print the changes between two versions of a schema", runDiff},
+	"gen":  {"generate Go, TypeScript or protobuf types from a schema", runGen},
+	"lint": {"report problems in schema files", runLint},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "jsonvalidator: unknown command %q\n", args[0])
+		usage(stderr)
+		return 2
+	}
+
+	return cmd.run(args[1:], stdout, stderr)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: jsonvalidator <command> [arguments]")
+	fmt.Fprintln(w, "\nThe commands are:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "\t%-8s%s\n", name, commands[name].description)
+	}
+}
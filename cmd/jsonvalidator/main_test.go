@@ -0,0 +1,189 @@
+//go:build !js
+// +build !js
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunLint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jsonvalidator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"valid.json":   `{"properties": {"name": {"$ref": "common.json#/definitions/name"}}}`,
+		"common.json":  `{"definitions": {"name": {"type": "string"}}}`,
+		"warning.json": `{"minItems": 2, "maxItems": 1}`,
+		"error.json":   `{"properties": {"name": {"tpye": "string"}}}`,
+	}
+	for name, content := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		args     []string
+		code     int
+		expected string
+	}{
+		{[]string{"lint", "valid.json"}, 0, ""},
+		{[]string{"lint", "warning.json"}, 0, "warning.json#/minItems: warning: \"minItems\" (2) is greater than \"maxItems\" (1) [contradiction]\n"},
+		{[]string{"lint", "-strict", "warning.json"}, 1, "warning.json#/minItems: warning: \"minItems\" (2) is greater than \"maxItems\" (1) [contradiction]\n"},
+		{[]string{"lint", "error.json"}, 1, "error.json#/properties/name/tpye: error: unknown keyword \"tpye\", did you mean \"type\"? [unknown-keyword]\n"},
+		{[]string{"lint"}, 2, ""},
+		{[]string{"format"}, 2, ""},
+	}
+
+	for _, testCase := range testCases {
+		args := make([]string, len(testCase.args))
+		for index, arg := range testCase.args {
+			args[index] = arg
+			if filepath.Ext(arg) == ".json" {
+				args[index] = filepath.Join(dir, arg)
+			}
+		}
+
+		var stdout, stderr bytes.Buffer
+		code := run(args, &stdout, &stderr)
+		if code != testCase.code {
+			t.Errorf("%v: expected exit code %d, got %d (%s)", testCase.args, testCase.code, code, stderr.String())
+		}
+
+		expected := ""
+		if testCase.expected != "" {
+			expected = filepath.Join(dir, testCase.expected)
+		}
+		if stdout.String() != expected {
+			t.Errorf("%v: expected output %q, got %q", testCase.args, expected, stdout.String())
+		}
+	}
+}
+
+func TestRunDiff(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jsonvalidator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"v1.json": `{"properties": {"name": {"type": "string"}}}`,
+		"v2.json": `{"description": "a person", "properties": {"name": {"type": "string"}}}`,
+		"v3.json": `{"description": "a person", "properties": {"name": {"type": "string"}}, "required": ["name"]}`,
+	}
+	for name, content := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		args     []string
+		code     int
+		expected string
+	}{
+		{[]string{"diff", "v1.json", "v1.json"}, 0, ""},
+		{[]string{"diff", "v1.json", "v2.json"}, 0, "#/description: non-breaking: \"description\" changed\n"},
+		{[]string{"diff", "v2.json", "v3.json"}, 1, "#/required: breaking: the property \"name\" is required\n"},
+		{[]string{"diff", "-breaking", "v1.json", "v3.json"}, 1, "#/required: breaking: the property \"name\" is required\n"},
+		{[]string{"diff", "v1.json"}, 2, ""},
+		{[]string{"diff", "v1.json", "missing.json"}, 2, ""},
+	}
+
+	for _, testCase := range testCases {
+		args := make([]string, len(testCase.args))
+		for index, arg := range testCase.args {
+			args[index] = arg
+			if filepath.Ext(arg) == ".json" {
+				args[index] = filepath.Join(dir, arg)
+			}
+		}
+
+		var stdout, stderr bytes.Buffer
+		code := run(args, &stdout, &stderr)
+		if code != testCase.code {
+			t.Errorf("%v: expected exit code %d, got %d (%s)", testCase.args, testCase.code, code, stderr.String())
+		}
+		if stdout.String() != testCase.expected {
+			t.Errorf("%v: expected output %q, got %q", testCase.args, testCase.expected, stdout.String())
+		}
+	}
+}
+
+func TestRunGen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jsonvalidator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"line-item.json": `{"type": "object", "required": ["sku"], "properties": {"sku": {"type": "string"}, "quantity": {"type": "integer"}}}`,
+		"name.json":      `{"type": "string"}`,
+	}
+	for name, content := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		args     []string
+		code     int
+		expected string
+	}{
+		{[]string{"gen", "go", "line-item.json"}, 0, "package schema\n\ntype LineItem struct {\n\tQuantity *int64 `json:\"quantity,omitempty\"`\n\tSku      string `json:\"sku\"`\n}\n"},
+		{[]string{"gen", "go", "-package", "shop", "-type", "Item", "line-item.json"}, 0, "package shop\n\ntype Item struct {\n"},
+		{[]string{"gen", "ts", "-package", "shop", "line-item.json"}, 0, "export namespace shop {\n  export interface LineItem {\n    quantity?: number;\n    sku: string;\n  }\n}\n"},
+		{[]string{"gen", "proto", "-package", "shop", "line-item.json"}, 0, "package shop;\n\nmessage LineItem {\n"},
+		{[]string{"gen", "go", "name.json"}, 1, ""},
+		{[]string{"gen", "rust", "line-item.json"}, 2, ""},
+		{[]string{"gen", "go"}, 2, ""},
+	}
+
+	for _, testCase := range testCases {
+		args := make([]string, len(testCase.args))
+		for index, arg := range testCase.args {
+			args[index] = arg
+			if filepath.Ext(arg) == ".json" {
+				args[index] = filepath.Join(dir, arg)
+			}
+		}
+
+		var stdout, stderr bytes.Buffer
+		code := run(args, &stdout, &stderr)
+		if code != testCase.code {
+			t.Errorf("%v: expected exit code %d, got %d (%s)", testCase.args, testCase.code, code, stderr.String())
+		}
+		if !strings.Contains(stdout.String(), testCase.expected) {
+			t.Errorf("%v: expected output containing %q, got %q", testCase.args, testCase.expected, stdout.String())
+		}
+	}
+
+	output := filepath.Join(dir, "line_item.go")
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"gen", "go", "-o", output, filepath.Join(dir, "line-item.json")}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (%s)", code, stderr.String())
+	}
+
+	source, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stdout.Len() != 0 || !strings.Contains(string(source), "type LineItem struct") {
+		t.Errorf("expected the types in %s, got %q and output %q", output, source, stdout.String())
+	}
+}
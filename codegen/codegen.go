@@ -0,0 +1,445 @@
+// Package codegen generates Go and TypeScript types that describe the values
+// of a json schema, so the consumers of a schema can decode and build its
+// documents without writing the types by hand. Protocol buffers messages are
+// generated by the protoschema package.
+//
+// Both generators map the schema the same way:
+//   - objects with properties become structs (Go) or interfaces
+//     (TypeScript), and "$ref" references to them become top-level types
+//     named after the last token of the reference, while nested objects are
+//     named after their parent type and property,
+//   - objects without properties become maps if "additionalProperties" is a
+//     schema, and maps of any value otherwise,
+//   - string enums become named string types with a constant for every
+//     value (Go) or unions of string literals (TypeScript),
+//   - arrays become slices or arrays of their items, and tuples become
+//     arrays of any value,
+//   - "allOf" sub-schemas are merged into the schema, and values of several
+//     types ("type" arrays, "anyOf" and "oneOf") become interface{} in Go and
+//     unions in TypeScript,
+//   - properties that are not required, and nullable values, become
+//     pointers with "omitempty" (Go) or optional properties and unions with
+//     null (TypeScript).
+//
+// Validation keywords that types cannot express, like "minimum" and
+// "pattern", are dropped, so documents should still be validated.
+package codegen
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/internal/schemautil"
+	"github.com/itayankri/gojsonvalidator/internal/strutil"
+)
+
+// ErrNotAnObject is returned if the root schema does not describe an
+// object, which is the only kind of value the root type can be.
+var ErrNotAnObject = errors.New("codegen: the root schema does not describe an object")
+
+// The kinds of the generated types.
+type kind int
+
+const (
+	kindAny kind = iota
+	kindString
+	kindInteger
+	kindNumber
+	kindBoolean
+	kindNull
+	kindTime
+	kindArray
+	kindMap
+	kindStruct
+	kindEnum
+	kindUnion
+)
+
+// typeRef is the type of a value.
+type typeRef struct {
+	kind kind
+
+	// The name of a struct or an enum.
+	name string
+
+	// The items of an array, the values of a map, or the members of a
+	// union.
+	elem    *typeRef
+	members []*typeRef
+
+	// The value of a "const" string, which TypeScript can express.
+	literal *string
+
+	nullable bool
+}
+
+type structType struct {
+	name    string
+	comment string
+	fields  []*field
+}
+
+type field struct {
+	property string
+	comment  string
+	typ      *typeRef
+	required bool
+}
+
+type enumType struct {
+	name   string
+	values []string
+}
+
+// generation holds the state of a single generation.
+type generation struct {
+	schema *jsonvalidator.RootJsonSchema
+
+	// The generated structs and enums, in the order they were created, and
+	// the type names that are taken.
+	structs []*structType
+	enums   []*enumType
+	names   map[string]bool
+
+	// The names of the structs that were generated for references.
+	refs map[string]string
+}
+
+// build builds the types of a root schema, whose root type has the given
+// name.
+func build(schema *jsonvalidator.RootJsonSchema, typeName string) (*generation, error) {
+	root, err := schemautil.Map(&schema.JsonSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generation{
+		schema: schema,
+		names:  map[string]bool{},
+		refs:   map[string]string{},
+	}
+
+	root = g.mergeAllOf(root)
+	if !schemautil.IsObjectSchema(root) {
+		return nil, ErrNotAnObject
+	}
+
+	rootStruct := &structType{name: g.typeName(typeName)}
+	g.structs = append(g.structs, rootStruct)
+	g.refs["#"] = rootStruct.name
+
+	return g, g.buildStruct(rootStruct, root)
+}
+
+// buildStruct adds the fields of an object schema to a struct.
+func (g *generation) buildStruct(s *structType, schema map[string]interface{}) error {
+	s.comment, _ = schema["description"].(string)
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	required := map[string]bool{}
+	for _, name := range schemautil.StringList(schema["required"]) {
+		required[name] = true
+	}
+
+	for _, name := range names {
+		typ, err := g.valueType(s.name+camelCase(name), properties[name])
+		if err != nil {
+			return err
+		}
+
+		f := &field{property: name, typ: typ, required: required[name]}
+		if object, ok := properties[name].(map[string]interface{}); ok {
+			f.comment, _ = object["description"].(string)
+		}
+		s.fields = append(s.fields, f)
+	}
+
+	return nil
+}
+
+// valueType returns the type of a value. name is the name of a struct or an
+// enum, if one has to be created for the value.
+func (g *generation) valueType(name string, schema interface{}) (*typeRef, error) {
+	object, ok := schema.(map[string]interface{})
+	if !ok {
+		return &typeRef{kind: kindAny}, nil
+	}
+
+	if reference, ok := object["$ref"].(string); ok {
+		return g.referenceType(name, reference)
+	}
+
+	object = g.mergeAllOf(object)
+
+	if value, ok := object["const"].(string); ok {
+		return &typeRef{kind: kindString, literal: &value}, nil
+	}
+	if values, ok := object["enum"].([]interface{}); ok {
+		return g.enumType(name, values), nil
+	}
+
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		if alternatives, ok := object[keyword].([]interface{}); ok && len(alternatives) > 0 {
+			return g.unionType(name, alternatives)
+		}
+	}
+
+	types := schemautil.StringList(object["type"])
+	nullable := false
+	for index := 0; index < len(types); index++ {
+		if types[index] == "null" {
+			types = append(types[:index], types[index+1:]...)
+			nullable = true
+			index--
+		}
+	}
+
+	if len(types) == 0 && schemautil.IsObjectSchema(object) {
+		types = []string{"object"}
+	}
+	if len(types) == 0 && nullable {
+		return &typeRef{kind: kindNull}, nil
+	}
+	if len(types) != 1 {
+		var members []*typeRef
+		for _, jsonType := range types {
+			member, err := g.valueType(name+camelCase(jsonType), map[string]interface{}{"type": jsonType})
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, member)
+		}
+		if len(members) == 0 {
+			return &typeRef{kind: kindAny}, nil
+		}
+		return &typeRef{kind: kindUnion, members: members, nullable: nullable}, nil
+	}
+
+	typ, err := g.singleType(name, types[0], object)
+	if err != nil {
+		return nil, err
+	}
+
+	typ.nullable = nullable
+	return typ, nil
+}
+
+// singleType returns the type of a value of a single json type.
+func (g *generation) singleType(name string, jsonType string, object map[string]interface{}) (*typeRef, error) {
+	switch jsonType {
+	case "string":
+		if format, _ := object["format"].(string); format == "date-time" {
+			return &typeRef{kind: kindTime}, nil
+		}
+		return &typeRef{kind: kindString}, nil
+	case "integer":
+		return &typeRef{kind: kindInteger}, nil
+	case "number":
+		return &typeRef{kind: kindNumber}, nil
+	case "boolean":
+		return &typeRef{kind: kindBoolean}, nil
+	case "array":
+		items, ok := object["items"]
+		if _, isTuple := items.([]interface{}); !ok || isTuple {
+			return &typeRef{kind: kindArray, elem: &typeRef{kind: kindAny}}, nil
+		}
+
+		elem, err := g.valueType(name+"Item", items)
+		if err != nil {
+			return nil, err
+		}
+		return &typeRef{kind: kindArray, elem: elem}, nil
+	case "object":
+		if _, ok := object["properties"].(map[string]interface{}); ok {
+			s := &structType{name: g.typeName(name)}
+			g.structs = append(g.structs, s)
+			return &typeRef{kind: kindStruct, name: s.name}, g.buildStruct(s, object)
+		}
+
+		if values, ok := object["additionalProperties"].(map[string]interface{}); ok {
+			elem, err := g.valueType(name+"Value", values)
+			if err != nil {
+				return nil, err
+			}
+			return &typeRef{kind: kindMap, elem: elem}, nil
+		}
+
+		return &typeRef{kind: kindMap, elem: &typeRef{kind: kindAny}}, nil
+	}
+
+	return &typeRef{kind: kindAny}, nil
+}
+
+// referenceType returns the type of a "$ref". References to objects become
+// top-level structs, which are generated once for every reference.
+func (g *generation) referenceType(name string, reference string) (*typeRef, error) {
+	if structName, ok := g.refs[reference]; ok {
+		return &typeRef{kind: kindStruct, name: structName}, nil
+	}
+
+	resolved, err := g.schema.Resolve(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := schemautil.Map(resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	object = g.mergeAllOf(object)
+	if _, ok := object["properties"].(map[string]interface{}); !ok {
+		return g.valueType(name, object)
+	}
+
+	// The struct is registered before it is built, so recursive references
+	// refer to it.
+	tokens := strings.Split(reference, "/")
+	s := &structType{name: g.typeName(camelCase(tokens[len(tokens)-1]))}
+	g.refs[reference] = s.name
+	g.structs = append(g.structs, s)
+
+	return &typeRef{kind: kindStruct, name: s.name}, g.buildStruct(s, object)
+}
+
+// enumType returns the type of an "enum". Enums of strings become named
+// enums, and other enums are described by the types of their values.
+func (g *generation) enumType(name string, values []interface{}) *typeRef {
+	var strs []string
+	nullable := false
+	for _, value := range values {
+		switch v := value.(type) {
+		case string:
+			strs = append(strs, v)
+		case nil:
+			nullable = true
+		default:
+			return &typeRef{kind: kindAny}
+		}
+	}
+	if len(strs) == 0 {
+		return &typeRef{kind: kindAny}
+	}
+
+	enum := &enumType{name: g.typeName(name), values: strs}
+	g.enums = append(g.enums, enum)
+
+	return &typeRef{kind: kindEnum, name: enum.name, nullable: nullable}
+}
+
+// unionType returns the type of the alternatives of a "oneOf" or an
+// "anyOf".
+func (g *generation) unionType(name string, alternatives []interface{}) (*typeRef, error) {
+	union := &typeRef{kind: kindUnion}
+	for index, alternative := range alternatives {
+		suffix := strconv.Itoa(index + 1)
+		if object, ok := alternative.(map[string]interface{}); ok {
+			if title, ok := object["title"].(string); ok && title != "" {
+				suffix = camelCase(title)
+			}
+		}
+
+		member, err := g.valueType(name+"Option"+suffix, alternative)
+		if err != nil {
+			return nil, err
+		}
+		if member.kind == kindNull {
+			union.nullable = true
+			continue
+		}
+		union.members = append(union.members, member)
+	}
+
+	if len(union.members) == 1 {
+		member := union.members[0]
+		member.nullable = member.nullable || union.nullable
+		return member, nil
+	}
+
+	return union, nil
+}
+
+// mergeAllOf returns the schema with the properties and the required
+// properties of its "allOf" sub-schemas added to it.
+func (g *generation) mergeAllOf(schema map[string]interface{}) map[string]interface{} {
+	subSchemas, ok := schema["allOf"].([]interface{})
+	if !ok {
+		return schema
+	}
+
+	merged := make(map[string]interface{}, len(schema))
+	for keyword, value := range schema {
+		merged[keyword] = value
+	}
+	delete(merged, "allOf")
+
+	properties := map[string]interface{}{}
+	if own, ok := schema["properties"].(map[string]interface{}); ok {
+		for name, property := range own {
+			properties[name] = property
+		}
+	}
+	required := schemautil.StringList(schema["required"])
+
+	for _, subSchema := range subSchemas {
+		object, ok := subSchema.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if reference, ok := object["$ref"].(string); ok {
+			if resolved, err := g.schema.Resolve(reference); err == nil {
+				object, _ = schemautil.Map(resolved)
+			}
+		}
+
+		object = g.mergeAllOf(object)
+		if own, ok := object["properties"].(map[string]interface{}); ok {
+			for name, property := range own {
+				properties[name] = property
+			}
+		}
+		required = append(required, schemautil.StringList(object["required"])...)
+		if _, ok := merged["type"]; !ok && object["type"] != nil {
+			merged["type"] = object["type"]
+		}
+	}
+
+	if len(properties) > 0 {
+		merged["properties"] = properties
+	}
+	if len(required) > 0 {
+		requiredList := make([]interface{}, len(required))
+		for index, name := range required {
+			requiredList[index] = name
+		}
+		merged["required"] = requiredList
+	}
+
+	return merged
+}
+
+// typeName returns a type name that is not taken yet.
+func (g *generation) typeName(name string) string {
+	unique := name
+	for suffix := 2; g.names[unique]; suffix++ {
+		unique = name + strconv.Itoa(suffix)
+	}
+	g.names[unique] = true
+
+	return unique
+}
+
+// camelCase returns the CamelCase name of a type, a field or a constant.
+func camelCase(name string) string {
+	return strutil.CamelCase(name, "X")
+}
@@ -0,0 +1,207 @@
+package codegen_test
+
+import (
+	"testing"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/codegen"
+)
+
+const orderSchema = `{
+	"$id": "http://example.com/codegen/order.json",
+	"description": "An order of the shop.",
+	"type": "object",
+	"required": ["orderId", "lines"],
+	"properties": {
+		"orderId": {"type": "string", "description": "The id of the order."},
+		"lines": {"type": "array", "items": {"$ref": "#/definitions/line"}},
+		"status": {"enum": ["new", "paid", "in-transit"]},
+		"total": {"type": "number"},
+		"note": {"type": ["string", "null"]},
+		"created_at": {"type": "string", "format": "date-time"},
+		"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+		"metadata": {"type": "object"},
+		"shipping": {"type": "object", "properties": {"address": {"type": "string"}, "express": {"type": "boolean"}}},
+		"discount": {"oneOf": [{"type": "number"}, {"title": "coupon", "type": "string"}]},
+		"kind": {"const": "order"},
+		"x-ref": {"type": "string"},
+		"parent": {"$ref": "#"}
+	},
+	"definitions": {
+		"line": {
+			"type": "object",
+			"required": ["sku"],
+			"properties": {"sku": {"type": "string"}, "quantity": {"type": "integer"}}
+		}
+	}
+}`
+
+const orderGo = `// Code generated by jsonvalidator. DO NOT EDIT.
+
+package shop
+
+import "time"
+
+// An order of the shop.
+type Order struct {
+	CreatedAt *time.Time             ` + "`" + `json:"created_at,omitempty"` + "`" + `
+	Discount  interface{}            ` + "`" + `json:"discount,omitempty"` + "`" + `
+	Kind      *string                ` + "`" + `json:"kind,omitempty"` + "`" + `
+	Labels    map[string]string      ` + "`" + `json:"labels,omitempty"` + "`" + `
+	Lines     []Line                 ` + "`" + `json:"lines"` + "`" + `
+	Metadata  map[string]interface{} ` + "`" + `json:"metadata,omitempty"` + "`" + `
+	Note      *string                ` + "`" + `json:"note,omitempty"` + "`" + `
+	// The id of the order.
+	OrderId  string         ` + "`" + `json:"orderId"` + "`" + `
+	Parent   *Order         ` + "`" + `json:"parent,omitempty"` + "`" + `
+	Shipping *OrderShipping ` + "`" + `json:"shipping,omitempty"` + "`" + `
+	Status   *OrderStatus   ` + "`" + `json:"status,omitempty"` + "`" + `
+	Total    *float64       ` + "`" + `json:"total,omitempty"` + "`" + `
+	XRef     *string        ` + "`" + `json:"x-ref,omitempty"` + "`" + `
+}
+
+type Line struct {
+	Quantity *int64 ` + "`" + `json:"quantity,omitempty"` + "`" + `
+	Sku      string ` + "`" + `json:"sku"` + "`" + `
+}
+
+type OrderShipping struct {
+	Address *string ` + "`" + `json:"address,omitempty"` + "`" + `
+	Express *bool   ` + "`" + `json:"express,omitempty"` + "`" + `
+}
+
+type OrderStatus string
+
+const (
+	OrderStatusNew       OrderStatus = "new"
+	OrderStatusPaid      OrderStatus = "paid"
+	OrderStatusInTransit OrderStatus = "in-transit"
+)
+`
+
+const orderTypeScript = `// Code generated by jsonvalidator. DO NOT EDIT.
+
+/** An order of the shop. */
+export interface Order {
+  created_at?: string;
+  discount?: number | string;
+  kind?: "order";
+  labels?: { [key: string]: string };
+  lines: Line[];
+  metadata?: { [key: string]: unknown };
+  note?: string | null;
+  /** The id of the order. */
+  orderId: string;
+  parent?: Order;
+  shipping?: OrderShipping;
+  status?: OrderStatus;
+  total?: number;
+  "x-ref"?: string;
+}
+
+export interface Line {
+  quantity?: number;
+  sku: string;
+}
+
+export interface OrderShipping {
+  address?: string;
+  express?: boolean;
+}
+
+export type OrderStatus = "new" | "paid" | "in-transit";
+`
+
+const orderNamespace = `// Code generated by jsonvalidator. DO NOT EDIT.
+
+export namespace shop {
+  /** An order of the shop. */
+  export interface Order {
+    created_at?: string;
+    discount?: number | string;
+    kind?: "order";
+    labels?: { [key: string]: string };
+    lines: Line[];
+    metadata?: { [key: string]: unknown };
+    note?: string | null;
+    /** The id of the order. */
+    orderId: string;
+    parent?: Order;
+    shipping?: OrderShipping;
+    status?: OrderStatus;
+    total?: number;
+    "x-ref"?: string;
+  }
+
+  export interface Line {
+    quantity?: number;
+    sku: string;
+  }
+
+  export interface OrderShipping {
+    address?: string;
+    express?: boolean;
+  }
+
+  export type OrderStatus = "new" | "paid" | "in-transit";
+}
+`
+
+func TestGenerateGo(t *testing.T) {
+	rootSchema, err := jsonvalidator.NewRootJsonSchema([]byte(orderSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := codegen.GenerateGo(rootSchema, "shop", "Order")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(source) != orderGo {
+		t.Errorf("unexpected Go source:\n%s", source)
+	}
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	rootSchema, err := jsonvalidator.NewRootJsonSchema([]byte(orderSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		namespace string
+		expected  string
+	}{
+		{"", orderTypeScript},
+		{"shop", orderNamespace},
+	}
+
+	for _, testCase := range testCases {
+		source, err := codegen.GenerateTypeScript(rootSchema, testCase.namespace, "Order")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(source) != testCase.expected {
+			t.Errorf("unexpected TypeScript source in namespace %q:\n%s", testCase.namespace, source)
+		}
+	}
+}
+
+func TestGenerateNotAnObject(t *testing.T) {
+	rootSchema, err := jsonvalidator.NewRootJsonSchema([]byte(`{"$id": "http://example.com/codegen/string.json", "type": "string"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = codegen.GenerateGo(rootSchema, "shop", "Name")
+	if err != codegen.ErrNotAnObject {
+		t.Errorf("expected ErrNotAnObject, got %v", err)
+	}
+
+	_, err = codegen.GenerateTypeScript(rootSchema, "", "Name")
+	if err != codegen.ErrNotAnObject {
+		t.Errorf("expected ErrNotAnObject, got %v", err)
+	}
+}
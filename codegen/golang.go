@@ -0,0 +1,143 @@
+package codegen
+
+import (
+	"go/format"
+	"strconv"
+	"strings"
+
+	"github.com/itayankri/gojsonvalidator"
+)
+
+// GenerateGo generates a Go source file of the given package with a struct
+// of the given name that describes the objects of a root schema, and the
+// types that its properties need. The fields are tagged with the names of
+// the properties, so the documents of the schema can be decoded with
+// encoding/json.
+func GenerateGo(schema *jsonvalidator.RootJsonSchema, packageName string, typeName string) ([]byte, error) {
+	g, err := build(schema, camelCase(typeName))
+	if err != nil {
+		return nil, err
+	}
+
+	var builder strings.Builder
+	builder.WriteString("// Code generated by jsonvalidator. DO NOT EDIT.\n\n")
+	builder.WriteString("package " + packageName + "\n")
+	if g.uses(kindTime) {
+		builder.WriteString("\nimport \"time\"\n")
+	}
+
+	for _, s := range g.structs {
+		builder.WriteString("\n")
+		writeGoComment(&builder, "", s.comment)
+		builder.WriteString("type " + s.name + " struct {\n")
+
+		fieldNames := map[string]bool{}
+		for _, f := range s.fields {
+			name := camelCase(f.property)
+			for suffix := 2; fieldNames[name]; suffix++ {
+				name = camelCase(f.property) + strconv.Itoa(suffix)
+			}
+			fieldNames[name] = true
+
+			tag := f.property
+			typ := goType(f.typ)
+			if !f.required {
+				tag += ",omitempty"
+				typ = goOptional(f.typ, typ)
+			}
+
+			writeGoComment(&builder, "\t", f.comment)
+			builder.WriteString("\t" + name + " " + typ + " `json:" + strconv.Quote(tag) + "`\n")
+		}
+		builder.WriteString("}\n")
+	}
+
+	for _, enum := range g.enums {
+		builder.WriteString("\ntype " + enum.name + " string\n\nconst (\n")
+		for _, value := range enum.values {
+			builder.WriteString("\t" + enum.name + camelCase(value) + " " + enum.name + " = " + strconv.Quote(value) + "\n")
+		}
+		builder.WriteString(")\n")
+	}
+
+	return format.Source([]byte(builder.String()))
+}
+
+// goType returns the Go type of a value. Nullable values are pointers,
+// except for the types whose zero value is nil.
+func goType(typ *typeRef) string {
+	var name string
+	switch typ.kind {
+	case kindString:
+		name = "string"
+	case kindInteger:
+		name = "int64"
+	case kindNumber:
+		name = "float64"
+	case kindBoolean:
+		name = "bool"
+	case kindTime:
+		name = "time.Time"
+	case kindArray:
+		return "[]" + goType(typ.elem)
+	case kindMap:
+		return "map[string]" + goType(typ.elem)
+	case kindStruct, kindEnum:
+		name = typ.name
+	default:
+		return "interface{}"
+	}
+
+	if typ.nullable {
+		return "*" + name
+	}
+	return name
+}
+
+// goOptional returns the Go type of a property that is not required, which
+// is a pointer, so a missing property can be told from its zero value.
+func goOptional(typ *typeRef, name string) string {
+	if strings.HasPrefix(name, "*") || strings.HasPrefix(name, "[]") || strings.HasPrefix(name, "map[") || name == "interface{}" {
+		return name
+	}
+
+	return "*" + name
+}
+
+// uses returns true if one of the fields of the structs has a type of the
+// given kind.
+func (g *generation) uses(k kind) bool {
+	var uses func(typ *typeRef) bool
+	uses = func(typ *typeRef) bool {
+		if typ == nil {
+			return false
+		}
+		if typ.kind == k || uses(typ.elem) {
+			return true
+		}
+		for _, member := range typ.members {
+			if uses(member) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, s := range g.structs {
+		for _, f := range s.fields {
+			if uses(f.typ) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func writeGoComment(builder *strings.Builder, indent string, comment string) {
+	for _, line := range strings.Split(comment, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			builder.WriteString(indent + "// " + line + "\n")
+		}
+	}
+}
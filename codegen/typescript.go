@@ -0,0 +1,130 @@
+package codegen
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/itayankri/gojsonvalidator"
+)
+
+// The property names that can be written without quotes.
+var identifierRegexp = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// GenerateTypeScript generates a TypeScript module with an exported
+// interface of the given name that describes the objects of a root schema,
+// and the types that its properties need. If namespace is not empty, the
+// types are declared in an exported namespace of that name.
+func GenerateTypeScript(schema *jsonvalidator.RootJsonSchema, namespace string, typeName string) ([]byte, error) {
+	g, err := build(schema, camelCase(typeName))
+	if err != nil {
+		return nil, err
+	}
+
+	var builder strings.Builder
+	builder.WriteString("// Code generated by jsonvalidator. DO NOT EDIT.\n")
+
+	indent := ""
+	if namespace != "" {
+		builder.WriteString("\nexport namespace " + namespace + " {\n")
+		indent = "  "
+	}
+
+	for index, s := range g.structs {
+		if index > 0 || namespace == "" {
+			builder.WriteString("\n")
+		}
+		writeTypeScriptComment(&builder, indent, s.comment)
+		builder.WriteString(indent + "export interface " + s.name + " {\n")
+
+		for _, f := range s.fields {
+			name := f.property
+			if !identifierRegexp.MatchString(name) {
+				name = strconv.Quote(name)
+			}
+			if !f.required {
+				name += "?"
+			}
+
+			writeTypeScriptComment(&builder, indent+"  ", f.comment)
+			builder.WriteString(indent + "  " + name + ": " + typeScriptType(f.typ) + ";\n")
+		}
+		builder.WriteString(indent + "}\n")
+	}
+
+	for _, enum := range g.enums {
+		values := make([]string, len(enum.values))
+		for index, value := range enum.values {
+			values[index] = strconv.Quote(value)
+		}
+		builder.WriteString("\n" + indent + "export type " + enum.name + " = " + strings.Join(values, " | ") + ";\n")
+	}
+
+	if namespace != "" {
+		builder.WriteString("}\n")
+	}
+
+	return []byte(builder.String()), nil
+}
+
+// typeScriptType returns the TypeScript type of a value.
+func typeScriptType(typ *typeRef) string {
+	var name string
+	switch typ.kind {
+	case kindString, kindTime:
+		name = "string"
+		if typ.literal != nil {
+			name = strconv.Quote(*typ.literal)
+		}
+	case kindInteger, kindNumber:
+		name = "number"
+	case kindBoolean:
+		name = "boolean"
+	case kindNull:
+		return "null"
+	case kindArray:
+		name = typeScriptType(typ.elem)
+		if strings.Contains(name, " ") {
+			name = "(" + name + ")"
+		}
+		name += "[]"
+	case kindMap:
+		name = "{ [key: string]: " + typeScriptType(typ.elem) + " }"
+	case kindStruct, kindEnum:
+		name = typ.name
+	case kindUnion:
+		members := make([]string, len(typ.members))
+		for index, member := range typ.members {
+			members[index] = typeScriptType(member)
+		}
+		name = strings.Join(members, " | ")
+	default:
+		return "unknown"
+	}
+
+	if typ.nullable {
+		return name + " | null"
+	}
+	return name
+}
+
+func writeTypeScriptComment(builder *strings.Builder, indent string, comment string) {
+	var lines []string
+	for _, line := range strings.Split(comment, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	switch len(lines) {
+	case 0:
+	case 1:
+		builder.WriteString(indent + "/** " + lines[0] + " */\n")
+	default:
+		builder.WriteString(indent + "/**\n")
+		for _, line := range lines {
+			builder.WriteString(indent + " * " + line + "\n")
+		}
+		builder.WriteString(indent + " */\n")
+	}
+}
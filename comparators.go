@@ -0,0 +1,49 @@
+package jsonvalidator
+
+import (
+	"strings"
+	"time"
+)
+
+// Comparator tells whether a value of a validated document equals a value
+// of a "const" or an "enum" keyword. schemaValue and value are decoded json
+// values (numbers are float64, or json.Number in the strict numeric mode).
+// A Comparator is set by Validator.CompareValues(), and it may fall back to
+// EqualValues for the values it has no special rule for.
+type Comparator func(schemaValue interface{}, value interface{}) bool
+
+// EqualValues is the Comparator of the json schema specification: numbers
+// are equal if they are mathematically equal, and objects are equal
+// regardless of the order of their properties.
+func EqualValues(schemaValue interface{}, value interface{}) bool {
+	return equalValues(schemaValue, value)
+}
+
+// EqualFold is a Comparator that compares strings case-insensitively, under
+// Unicode case-folding, and other values with EqualValues.
+func EqualFold(schemaValue interface{}, value interface{}) bool {
+	x, ok := schemaValue.(string)
+	y, ok2 := value.(string)
+	if ok && ok2 {
+		return strings.EqualFold(x, y)
+	}
+
+	return EqualValues(schemaValue, value)
+}
+
+// EqualDateTimes is a Comparator that compares RFC 3339 date-time strings by
+// the instants they describe, so "2020-01-01T02:00:00+02:00" equals
+// "2020-01-01T00:00:00Z", and other values with EqualValues.
+func EqualDateTimes(schemaValue interface{}, value interface{}) bool {
+	x, ok := schemaValue.(string)
+	y, ok2 := value.(string)
+	if ok && ok2 {
+		a, err := time.Parse(time.RFC3339Nano, x)
+		b, err2 := time.Parse(time.RFC3339Nano, y)
+		if err == nil && err2 == nil {
+			return a.Equal(b)
+		}
+	}
+
+	return EqualValues(schemaValue, value)
+}
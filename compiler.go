@@ -0,0 +1,706 @@
+package jsonvalidator
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+// compilationContext holds the state of the compilation of a root schema
+// (and all of its sub-schemas) by scanSchema().
+type compilationContext struct {
+	// The registry that the root schema is compiled in, and the root
+	// schema, or nil if a standalone JsonSchema is compiled.
+	registry   *Registry
+	rootSchema *RootJsonSchema
+
+	// The $id of the root schema, or an empty string if it has none.
+	rootSchemaID string
+
+	// The draft that the root schema is compiled with (see resolveDraft()).
+	draft string
+
+	// anchors holds the "$anchor" and "$dynamicAnchor" names of the root
+	// schema's sub-schemas.
+	anchors map[string]schemaAnchor
+
+	// names holds a single copy of every property name that appears in the
+	// root schema, so schemas that repeat the same property names share the
+	// same strings.
+	names map[string]string
+
+	// scanned holds the schemas that were scanned, which are checked for
+	// contradictions once the root schema is compiled.
+	scanned []scannedSchema
+}
+
+func newCompilationContext(registry *Registry, rootSchema *RootJsonSchema, rootSchemaID string, draft string) *compilationContext {
+	return &compilationContext{
+		registry:     registry,
+		rootSchema:   rootSchema,
+		rootSchemaID: rootSchemaID,
+		draft:        draft,
+		anchors:      map[string]schemaAnchor{},
+		names:        map[string]string{},
+	}
+}
+
+// schemaAnchor is the location of a schema that has an "$anchor" or a
+// "$dynamicAnchor", as a json pointer in the root schema.
+type schemaAnchor struct {
+	pointer string
+
+	// The keyword of the anchor, "$anchor" or "$dynamicAnchor".
+	keyword string
+
+	// dynamic is true if the anchor is a "$dynamicAnchor", which
+	// "$dynamicRef" resolves dynamically.
+	dynamic bool
+}
+
+// addAnchors records the "$anchor" and the "$dynamicAnchor" of the schema
+// at schemaPath. An anchor name may be used by a single schema of the root
+// schema.
+func (ctx *compilationContext) addAnchors(schemaPath string, js *JsonSchema) error {
+	var errs SchemaCompilationErrors
+
+	anchors := []struct {
+		keyword string
+		name    *anchor
+	}{
+		{"$anchor", js.Anchor},
+		{"$dynamicAnchor", js.DynamicAnchor},
+	}
+
+	for _, a := range anchors {
+		if a.name == nil {
+			continue
+		}
+
+		name := string(*a.name)
+		if !isValidAnchor(name) {
+			errs = append(errs, SchemaCompilationError{
+				path: schemaPath + "/" + a.keyword,
+				err:  "\"" + name + "\" is not a valid anchor name",
+			})
+			continue
+		}
+
+		// The sub-schemas are scanned in no particular order, so the anchor
+		// belongs to the schema with the first path, and the error is
+		// reported at the other one.
+		existing, ok := ctx.anchors[name]
+		if ok && existing.pointer != schemaPath {
+			duplicate := schemaPath + "/" + a.keyword
+			first := existing.pointer
+			if schemaPath < existing.pointer {
+				duplicate = existing.pointer + "/" + existing.keyword
+				first = schemaPath
+				ctx.anchors[name] = schemaAnchor{schemaPath, a.keyword, a.keyword == "$dynamicAnchor"}
+			}
+
+			errs = append(errs, SchemaCompilationError{
+				path: duplicate,
+				err:  "anchor \"" + name + "\" is already defined at \"" + first + "\"",
+			})
+			continue
+		}
+
+		ctx.anchors[name] = schemaAnchor{
+			pointer: schemaPath,
+			keyword: a.keyword,
+			dynamic: existing.dynamic || a.keyword == "$dynamicAnchor",
+		}
+	}
+
+	return errs.err()
+}
+
+// isValidAnchor returns true if name is a plain name fragment, which starts
+// with a letter or an underscore, followed by letters, digits, hyphens,
+// underscores and periods.
+func isValidAnchor(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for index := 0; index < len(name); index++ {
+		c := name[index]
+		isValid := 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' ||
+			index > 0 && ('0' <= c && c <= '9' || c == '-' || c == '.')
+		if !isValid {
+			return false
+		}
+	}
+
+	return true
+}
+
+// intern returns the single copy of a property name that is shared by all
+// the schemas of the root schema.
+func (ctx *compilationContext) intern(name string) string {
+	if interned, ok := ctx.names[name]; ok {
+		return interned
+	}
+
+	ctx.names[name] = name
+	return name
+}
+
+// internPropertyNames replaces the property names of the schema's
+// "properties" and "required" keywords with their interned copies. Duplicate
+// names in "required" are dropped, so each required property is looked up
+// once during validation. The names in "dependencies" are interned when the
+// keyword is compiled.
+func (js *JsonSchema) internPropertyNames(ctx *compilationContext) {
+	if js.Properties != nil {
+		interned := make(properties, len(js.Properties))
+		for key, value := range js.Properties {
+			interned[ctx.intern(key)] = value
+		}
+		js.Properties = interned
+	}
+
+	if js.Required != nil {
+		seen := make(map[string]bool, len(js.Required))
+		interned := make(required, 0, len(js.Required))
+		for _, name := range js.Required {
+			if !seen[name] {
+				seen[name] = true
+				interned = append(interned, ctx.intern(name))
+			}
+		}
+		js.Required = interned
+	}
+}
+
+// add appends the errors of a compilation step to the errors of the schema.
+// err may be a SchemaCompilationError or the SchemaCompilationErrors of a
+// sub-schema.
+func (e SchemaCompilationErrors) add(err error) SchemaCompilationErrors {
+	switch v := err.(type) {
+	case nil:
+		return e
+	case SchemaCompilationError:
+		return append(e, v)
+	case SchemaCompilationErrors:
+		return append(e, v...)
+	default:
+		return append(e, SchemaCompilationError{path: "", err: v.Error(), cause: v})
+	}
+}
+
+// err returns the errors sorted by their paths, or nil if there are none.
+func (e SchemaCompilationErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(e, func(i, j int) bool {
+		return e[i].path < e[j].path
+	})
+	return e
+}
+
+// keywordCost ranks a keyword by the cost of its validation, and by how
+// likely it is to reject a value, so the cheap and selective keywords of a
+// schema are validated first and invalid values fail fast:
+//   - 0: keywords that compare the value or its size with a constant,
+//   - 1: keywords that look at every property or item once,
+//   - 2: keywords that match strings with regular expressions or formats,
+//     that decode the contents of strings, and that compare the items of
+//     arrays with each other,
+//   - 3: keywords that validate sub-schemas,
+//   - 4: "anyOf" and "oneOf", which may validate all of their sub-schemas.
+func keywordCost(keyword keywordValidator) int {
+	switch keyword.(type) {
+	case *_type, *_const, *minLength, *maxLength, *multipleOf, *minimum,
+		*maximum, *exclusiveMinimum, *exclusiveMaximum, *minProperties,
+		*maxProperties, *minItems, *maxItems:
+		return 0
+	case enum, required, *formatMinimum, *formatMaximum,
+		*formatExclusiveMinimum, *formatExclusiveMaximum:
+		return 1
+	case *pattern, *format, *contentEncoding, *contentMediaType, *patternRequired,
+		*uniqueItems, *uniqueItemProperties:
+		return 2
+	case anyOf, oneOf:
+		return 4
+	default:
+		return 3
+	}
+}
+
+// orderKeywords sets the keywords of the schema in the order of their cost
+// (see keywordCost()). Keywords of the same cost keep the order of
+// getNonNilKeywordsSlice().
+func (js *JsonSchema) orderKeywords() {
+	keywords := getNonNilKeywordsSlice(js)
+	sort.SliceStable(keywords, func(i, j int) bool {
+		return keywordCost(keywords[i]) < keywordCost(keywords[j])
+	})
+
+	js.keywords = keywords
+}
+
+// The drafts that JsonValidator implements.
+var supportedDrafts = []string{DRAFT_04, DRAFT_06, DRAFT_07, DRAFT_2020_12}
+
+// The vocabularies of draft 2020-12 that JsonValidator implements. The
+// "unevaluated" vocabulary is not implemented.
+var supportedVocabularies = []string{
+	"https://json-schema.org/draft/2020-12/vocab/core",
+	"https://json-schema.org/draft/2020-12/vocab/applicator",
+	"https://json-schema.org/draft/2020-12/vocab/validation",
+	"https://json-schema.org/draft/2020-12/vocab/meta-data",
+	"https://json-schema.org/draft/2020-12/vocab/format-annotation",
+	"https://json-schema.org/draft/2020-12/vocab/format-assertion",
+	"https://json-schema.org/draft/2020-12/vocab/content",
+}
+
+// resolveDraft returns the draft that the root schema is compiled with. The
+// draft is override if it is not empty, and otherwise the draft that the
+// "$schema" field of the root schema declares, which is draft-07 if the
+// field is missing. "$schema" may also point to a registered meta-schema,
+// whose "$vocabulary" declares a dialect of draft 2020-12, or whose own
+// "$schema" is a known draft.
+// It returns an InvalidDraftError if the draft is not implemented by
+// JsonValidator, and an UnsupportedVocabularyError if the meta-schema
+// requires an unknown vocabulary, since validating with the keywords of
+// another dialect would silently give wrong results.
+func resolveDraft(rootSchema *JsonSchema, override string, registry *Registry) (string, error) {
+	if override != "" {
+		draft := normalizeDraftURI(override)
+		if !isSupportedDraft(draft) {
+			return "", InvalidDraftError(override)
+		}
+
+		return draft, nil
+	}
+
+	if rootSchema == nil || rootSchema.Schema == nil {
+		return DRAFT_07, nil
+	}
+
+	uri := string(*rootSchema.Schema)
+	draft := normalizeDraftURI(uri)
+	if isSupportedDraft(draft) {
+		return draft, nil
+	}
+
+	metaSchema, ok := registries{list: []*Registry{registry}}.lookup(resourceURI(uri))
+	if !ok || metaSchema == nil {
+		return "", InvalidDraftError(uri)
+	}
+
+	if metaSchema.Vocabulary != nil {
+		for vocabulary, required := range metaSchema.Vocabulary {
+			if required && !isSupportedVocabulary(vocabulary) {
+				return "", UnsupportedVocabularyError(vocabulary)
+			}
+		}
+
+		return DRAFT_2020_12, nil
+	}
+
+	if metaSchema.Schema != nil && isSupportedDraft(normalizeDraftURI(string(*metaSchema.Schema))) {
+		return normalizeDraftURI(string(*metaSchema.Schema)), nil
+	}
+
+	return "", InvalidDraftError(uri)
+}
+
+// isSupportedDraft returns true if draft is one of the supportedDrafts.
+func isSupportedDraft(draft string) bool {
+	for _, supported := range supportedDrafts {
+		if draft == supported {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSupportedVocabulary returns true if vocabulary is one of the
+// supportedVocabularies.
+func isSupportedVocabulary(vocabulary string) bool {
+	for _, supported := range supportedVocabularies {
+		if vocabulary == supported {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeDraftURI returns the DRAFT_* constant that matches a "$schema"
+// value, ignoring the differences in the scheme and the empty fragment that
+// are commonly found in schemas. If the value does not match any of the
+// known drafts, it is returned unchanged.
+func normalizeDraftURI(uri string) string {
+	trimmed := strings.TrimSuffix(uri, "#")
+	trimmed = strings.TrimPrefix(strings.TrimPrefix(trimmed, "http://"), "https://")
+
+	for _, draft := range supportedDrafts {
+		supported := strings.TrimSuffix(draft, "#")
+		supported = strings.TrimPrefix(strings.TrimPrefix(supported, "http://"), "https://")
+		if trimmed == supported {
+			return draft
+		}
+	}
+
+	return uri
+}
+
+// compilePropertyPattern compiles the pattern of a sub-schema of
+// "patternProperties", which is at schemaPath.
+func (js *JsonSchema) compilePropertyPattern(pattern string, schemaPath string) error {
+	var err error
+	js.propertyPattern, err = regexp.Compile(pattern)
+	if err != nil {
+		return SchemaCompilationError{
+			path:  schemaPath,
+			err:   "invalid regular expression: " + err.Error(),
+			cause: err,
+		}
+	}
+
+	return nil
+}
+
+// compile sorts the raw dependencies into sub-schema dependencies and
+// property dependencies, and scans the sub-schemas. The names of the
+// properties are interned, and duplicate names in a dependency array are
+// dropped. All the invalid dependencies are reported.
+func (d *dependencies) compile(schemaPath string, ctx *compilationContext) error {
+	d.schemas = map[string]*JsonSchema{}
+	d.properties = map[string][]string{}
+
+	var errs SchemaCompilationErrors
+	for key, rawDependency := range d.raw {
+		dependencyPath := schemaPath + "/" + jsonwalker.EscapeToken(key)
+
+		var value interface{}
+		err := json.Unmarshal(rawDependency, &value)
+		if err != nil {
+			errs = append(errs, SchemaCompilationError{path: dependencyPath, err: err.Error(), cause: err})
+			continue
+		}
+
+		// A dependency may be a json array of property names, or a json
+		// schema (which may be a boolean schema since draft-06).
+		switch v := value.(type) {
+		case []interface{}:
+			seen := make(map[string]bool, len(v))
+			names := make([]string, 0, len(v))
+			for index, item := range v {
+				name, ok := item.(string)
+				if !ok {
+					errs = append(errs, SchemaCompilationError{
+						path: dependencyPath,
+						err: "all items in dependency array must be strings, item at position " +
+							strconv.Itoa(index) +
+							" is not a string",
+					})
+					continue
+				}
+
+				if !seen[name] {
+					seen[name] = true
+					names = append(names, ctx.intern(name))
+				}
+			}
+			d.properties[ctx.intern(key)] = names
+		case map[string]interface{}, bool:
+			subSchema := new(JsonSchema)
+			err = json.Unmarshal(rawDependency, subSchema)
+			if err != nil {
+				errs = append(errs, SchemaCompilationError{path: dependencyPath, err: err.Error(), cause: err})
+				continue
+			}
+
+			errs = errs.add(subSchema.scanSchema(dependencyPath, ctx))
+			d.schemas[ctx.intern(key)] = subSchema
+		default:
+			errs = append(errs, SchemaCompilationError{
+				path: dependencyPath,
+				err:  "dependency value must be a json object or a json array",
+			})
+		}
+	}
+
+	return errs.err()
+}
+
+// compile scans the sub-schemas of "items" and compiles them with the
+// sibling "additionalItems" of the schema into a tupleItems, so the items of
+// arrays are validated against the schemas that were unmarshaled with the
+// schema.
+func (i *items) compile(schemaPath string, parent *JsonSchema, ctx *compilationContext) error {
+	if len(i.errs) > 0 {
+		var errs SchemaCompilationErrors
+		for pointer, err := range i.errs {
+			errs = append(errs, SchemaCompilationError{path: schemaPath + pointer, err: err.Error(), cause: err})
+		}
+		return errs.err()
+	}
+
+	tuple := &tupleItems{}
+	switch {
+	case i.schema != nil:
+		err := i.schema.scanSchema(schemaPath, ctx)
+		if err != nil {
+			return err
+		}
+		tuple.schema = i.schema
+	case i.isArray():
+		var errs SchemaCompilationErrors
+		for index, subSchema := range i.schemas {
+			errs = errs.add(subSchema.scanSchema(schemaPath+"/"+strconv.Itoa(index), ctx))
+		}
+		if len(errs) > 0 {
+			return errs.err()
+		}
+		tuple.schemas = i.schemas
+
+		// "additionalItems" applies only to the items that follow the
+		// schemas of "items".
+		if parent.AdditionalItems != nil {
+			tuple.additional = &parent.AdditionalItems.JsonSchema
+		}
+	default:
+		return SchemaCompilationError{
+			path: schemaPath,
+			err:  "\"items\" field value in schema must be a valid Json Schema or an array of Json Schema",
+		}
+	}
+
+	parent.tuple = tuple
+	return nil
+}
+
+// compile scans the sub-schemas of "prefixItems" and the sibling "items" of
+// the schema at schemaPath, and compiles them into a tupleItems, in which
+// "items" applies to the items that follow the schemas of "prefixItems", as
+// in draft 2020-12. The array form of "items" is reported by
+// checkDraftKeywords(), and is not compiled.
+func (p prefixItems) compile(schemaPath string, parent *JsonSchema, ctx *compilationContext) error {
+	var errs SchemaCompilationErrors
+	if len(p) == 0 {
+		errs = append(errs, SchemaCompilationError{
+			path: schemaPath + "/prefixItems",
+			err:  "\"prefixItems\" field value in schema must be a non-empty array of Json Schema",
+		})
+	}
+
+	tuple := &tupleItems{prefix: true}
+	for index, subSchema := range p {
+		subSchemaPath := schemaPath + "/prefixItems/" + strconv.Itoa(index)
+		if subSchema == nil {
+			errs = append(errs, SchemaCompilationError{path: subSchemaPath, err: "a valid json schema must be a json object or a boolean"})
+			continue
+		}
+
+		errs = errs.add(subSchema.scanSchema(subSchemaPath, ctx))
+		tuple.schemas = append(tuple.schemas, subSchema)
+	}
+
+	if parent.Items != nil && !parent.Items.isArray() {
+		tuple.additional = parent.Items.schema
+		if tuple.additional == nil {
+			reason := "\"items\" field value in schema must be a valid Json Schema"
+			if err, ok := parent.Items.errs[""]; ok {
+				reason = err.Error()
+			}
+			errs = append(errs, SchemaCompilationError{path: schemaPath + "/items", err: reason})
+		} else {
+			errs = errs.add(tuple.additional.scanSchema(schemaPath+"/items", ctx))
+		}
+	}
+
+	parent.tuple = tuple
+	return errs.err()
+}
+
+// isArray returns true if "items" is an array of schemas, rather than a
+// single schema.
+func (i *items) isArray() bool {
+	trimmed := bytes.TrimSpace(i.raw)
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// checkReferences returns a SchemaCompilationError for every reference of
+// the schemas (the sub-schemas of the root schema that rootSchemaID
+// identifies) that does not resolve, or that is part of a cycle of
+// references in which a value is validated against the same schema again
+// without advancing in it, like {"$ref": "#/definitions/a"} with the
+// definition "a": {"allOf": [{"$ref": "#"}]}, whose validation never ends.
+// The references are resolved in the registries.
+func checkReferences(schemas []scannedSchema, rootSchemaID string, registries registries) error {
+	var errs SchemaCompilationErrors
+	for _, s := range schemas {
+		for _, reference := range s.schema.references() {
+			location := s.path + "/" + reference.keyword
+
+			target, err := reference.resolve(rootSchemaID, registries)
+			if err != nil {
+				errs = append(errs, SchemaCompilationError{path: location, err: err.Error(), cause: err})
+				continue
+			}
+
+			source := locatedSchema{s.schema, rootSchemaID, s.path}
+			if target.reachesInPlace(source.location(), registries) {
+				errs = append(errs, SchemaCompilationError{
+					path: location,
+					err:  "the reference " + string(reference.ref) + " is part of a cycle of references",
+				})
+			}
+		}
+	}
+
+	return errs.err()
+}
+
+// locatedSchema is a schema, the id of the root schema that holds it, and
+// its json pointer in the root schema (in its plain representation). The
+// schemas that references point to are compiled again if they were not
+// mapped when their root schema was scanned, so schemas are told apart by
+// their locations rather than by their addresses.
+type locatedSchema struct {
+	schema       *JsonSchema
+	rootSchemaID string
+	path         string
+}
+
+// location returns the absolute location of the schema, which identifies
+// it.
+func (s locatedSchema) location() string {
+	return s.rootSchemaID + "#" + s.path
+}
+
+// schemaReference is a reference of a schema, and the keyword that holds
+// it.
+type schemaReference struct {
+	keyword string
+	ref     ref
+}
+
+// references returns the references of the schema, in "$ref" and in
+// "$dynamicRef". A "$dynamicRef" is followed to the schema that it points to
+// statically.
+func (js *JsonSchema) references() []schemaReference {
+	var references []schemaReference
+	if js.Ref != nil {
+		references = append(references, schemaReference{"$ref", *js.Ref})
+	}
+	if js.DynamicRef != nil {
+		references = append(references, schemaReference{"$dynamicRef", ref(*js.DynamicRef)})
+	}
+
+	return references
+}
+
+// resolve returns the schema that the reference points to in the
+// registries, and its location.
+func (r schemaReference) resolve(rootSchemaID string, registries registries) (locatedSchema, error) {
+	schema, err := r.ref.resolveIn(rootSchemaID, registries)
+	if err != nil {
+		return locatedSchema{}, err
+	}
+
+	// The fragment is normalized to the plain representation of the json
+	// pointer, which the paths of the scanned schemas are in. Anchors are
+	// replaced by their pointers already.
+	absoluteURI := r.ref.absoluteURIIn(rootSchemaID, registries)
+	index := strings.Index(absoluteURI, "#")
+	path := absoluteURI[index+1:]
+	if !isAnchorFragment(path) {
+		if pointer, err := jsonwalker.NewJsonPointer("#" + path); err == nil {
+			path = pointer.String()
+		}
+	}
+
+	return locatedSchema{schema, absoluteURI[:index], path}, nil
+}
+
+// inPlaceSchemas returns the schemas that a value is validated against when
+// it is validated against the schema, without advancing to its properties
+// or items: the schemas that the references point to, and the sub-schemas
+// of the applicator keywords. References that do not resolve are skipped.
+func (s locatedSchema) inPlaceSchemas(registries registries) []locatedSchema {
+	js := s.schema
+
+	var schemas []locatedSchema
+	for _, reference := range js.references() {
+		if target, err := reference.resolve(s.rootSchemaID, registries); err == nil {
+			schemas = append(schemas, target)
+		}
+	}
+
+	// Before draft 2020-12, the keywords next to "$ref" are ignored.
+	if js.Ref != nil && !js.refWithSiblings {
+		return schemas
+	}
+
+	subSchema := func(schema *JsonSchema, path string) {
+		schemas = append(schemas, locatedSchema{schema, s.rootSchemaID, s.path + path})
+	}
+	for index, schema := range js.AllOf {
+		subSchema(schema, "/allOf/"+strconv.Itoa(index))
+	}
+	for index, schema := range js.AnyOf {
+		subSchema(schema, "/anyOf/"+strconv.Itoa(index))
+	}
+	for index, schema := range js.OneOf {
+		subSchema(schema, "/oneOf/"+strconv.Itoa(index))
+	}
+	if js.Not != nil {
+		subSchema(&js.Not.JsonSchema, "/not")
+	}
+	if js.If != nil {
+		subSchema(&js.If.JsonSchema, "/if")
+	}
+	if js.Then != nil {
+		subSchema(&js.Then.JsonSchema, "/then")
+	}
+	if js.Else != nil {
+		subSchema(&js.Else.JsonSchema, "/else")
+	}
+	if js.Dependencies != nil {
+		for key, schema := range js.Dependencies.schemas {
+			subSchema(schema, "/dependencies/"+jsonwalker.EscapeToken(key))
+		}
+	}
+
+	return schemas
+}
+
+// reachesInPlace returns true if a value that is validated against the
+// receiver is validated against the schema at the target location too,
+// without advancing in the value.
+func (s locatedSchema) reachesInPlace(target string, registries registries) bool {
+	visited := map[string]bool{}
+	pending := []locatedSchema{s}
+	for len(pending) > 0 {
+		current := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		location := current.location()
+		if location == target {
+			return true
+		}
+		if visited[location] {
+			continue
+		}
+		visited[location] = true
+
+		pending = append(pending, current.inPlaceSchemas(registries)...)
+	}
+
+	return false
+}
@@ -0,0 +1,112 @@
+// Package confval validates configuration files against a json schema.
+//
+// A configuration file (json or yaml) is loaded, the schema's default values
+// are applied to it, the result is validated against the schema and finally
+// unmarshalled into a Go value, so a service can check its configuration at
+// startup with a single call.
+//
+// LoadEnv reads the configuration from environment variables instead, and
+// converts their values to the types that the schema declares.
+//
+// Load, which reads the configuration file from the filesystem, is not
+// available when building for js/wasm. LoadBytes is available on all
+// platforms.
+package confval
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/internal/yamlutil"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigError is returned when a configuration file could not be loaded or
+// is not valid against the schema.
+// File is the path of the configuration file, Path is the json pointer of
+// the invalid value and Keyword is the name of the keyword that failed. Path
+// and Keyword are empty if the file could not be read or parsed.
+type ConfigError struct {
+	File    string
+	Path    string
+	Keyword string
+	err     error
+}
+
+func (e ConfigError) Error() string {
+	return fmt.Sprintf("config file %s: %s", e.File, e.err.Error())
+}
+
+// Unwrap returns the error that loading the file failed with, like the
+// jsonvalidator.SchemaValidationError of an invalid value.
+func (e ConfigError) Unwrap() error {
+	return e.err
+}
+
+// LoadBytes is like Load, but gets the content of the configuration file
+// instead of reading it. The name is used to choose the file format and to
+// describe the file in errors.
+func LoadBytes(name string, bytes []byte, schema *jsonvalidator.RootJsonSchema, target interface{}) error {
+	// Convert yaml files to json, since the schema validates json documents.
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		{
+			var err error
+			bytes, err = yamlToJson(bytes)
+			if err != nil {
+				return ConfigError{File: name, err: err}
+			}
+		}
+	}
+
+	err := load(bytes, schema, target)
+	if err != nil {
+		if validationErr, ok := err.(jsonvalidator.SchemaValidationError); ok {
+			return ConfigError{
+				File:    name,
+				Path:    validationErr.Path(),
+				Keyword: validationErr.Keyword(),
+				err:     err,
+			}
+		}
+
+		return ConfigError{File: name, err: err}
+	}
+
+	return nil
+}
+
+// load validates a json document against schema with the default values
+// defined in schema injected (see jsonvalidator.Validator.ValidateDefaults()),
+// and unmarshals the result into target, if target is not nil.
+func load(bytes []byte, schema *jsonvalidator.RootJsonSchema, target interface{}) error {
+	bytes, err := jsonvalidator.NewValidator(schema).ValidateDefaults(bytes)
+	if err != nil {
+		return err
+	}
+
+	if target == nil {
+		return nil
+	}
+
+	return json.Unmarshal(bytes, target)
+}
+
+// yamlToJson converts a yaml document to json.
+func yamlToJson(bytes []byte) ([]byte, error) {
+	var value interface{}
+	err := yaml.Unmarshal(bytes, &value)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err = yamlutil.ConvertValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(value)
+}
@@ -0,0 +1,82 @@
+package confval_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/confval"
+)
+
+const configSchema = `{
+	"type": "object",
+	"required": ["name"],
+	"properties": {
+		"name": {"type": "string"},
+		"server": {
+			"type": "object",
+			"default": {},
+			"properties": {
+				"host": {"type": "string", "default": "localhost"},
+				"port": {"type": "integer", "maximum": 65535, "default": 8080}
+			}
+		}
+	}
+}`
+
+type config struct {
+	Name   string `json:"name"`
+	Server struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	} `json:"server"`
+}
+
+func TestLoadBytes(t *testing.T) {
+	schema, err := jsonvalidator.NewRootJsonSchema([]byte(configSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var yamlConfig config
+	err = confval.LoadBytes("config.yaml", []byte("name: api\nserver:\n  port: 9090\n"), schema, &yamlConfig)
+	if err != nil {
+		t.Fatalf("expected a valid yaml config, got %v", err)
+	}
+
+	if yamlConfig.Name != "api" || yamlConfig.Server.Host != "localhost" || yamlConfig.Server.Port != 9090 {
+		t.Errorf("unexpected yaml config %+v", yamlConfig)
+	}
+
+	var jsonConfig config
+	err = confval.LoadBytes("config.json", []byte(`{"name": "api"}`), schema, &jsonConfig)
+	if err != nil {
+		t.Fatalf("expected a valid json config, got %v", err)
+	}
+
+	if jsonConfig.Server.Host != "localhost" || jsonConfig.Server.Port != 8080 {
+		t.Errorf("expected the defaults to be applied, got %+v", jsonConfig)
+	}
+}
+
+func TestLoadBytesInvalid(t *testing.T) {
+	schema, err := jsonvalidator.NewRootJsonSchema([]byte(configSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = confval.LoadBytes("config.yml", []byte("name: api\nserver:\n  port: 70000\n"), schema, nil)
+	configErr, ok := err.(confval.ConfigError)
+	if !ok {
+		t.Fatalf("expected a ConfigError, got %v", err)
+	}
+
+	if configErr.File != "config.yml" || configErr.Path != "/server/port" || configErr.Keyword != "maximum" {
+		t.Errorf("unexpected error details %+v", configErr)
+	}
+
+	var validationErr jsonvalidator.SchemaValidationError
+	if !errors.As(err, &validationErr) || validationErr.Path() != "/server/port" {
+		t.Errorf("expected the ConfigError to wrap the SchemaValidationError, got %v", err)
+	}
+}
@@ -0,0 +1,281 @@
+package confval
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/internal/strutil"
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+// The nesting level of object properties below which no environment variables
+// are looked up, so recursive schemas are walked a limited number of times.
+const maxEnvDepth = 16
+
+// EnvError is returned when the configuration in the environment variables
+// is not valid against the schema.
+// Variable is the name of the environment variable that holds the invalid
+// value, Path is the json pointer of the value and Keyword is the name of the
+// keyword that failed. Variable is empty if the invalid value does not come
+// from a single variable, for example when a required property is missing.
+type EnvError struct {
+	Variable string
+	Path     string
+	Keyword  string
+	err      error
+}
+
+func (e EnvError) Error() string {
+	if e.Variable == "" {
+		return "environment: " + e.err.Error()
+	}
+
+	return fmt.Sprintf("environment variable %s: %s", e.Variable, e.err.Error())
+}
+
+// Unwrap returns the error that loading the environment variables failed
+// with, like the jsonvalidator.SchemaValidationError of an invalid value.
+func (e EnvError) Unwrap() error {
+	return e.err
+}
+
+// LoadEnv reads the configuration from the environment variables of the
+// process, applies the default values defined in schema, validates the result
+// against schema and unmarshals it into target.
+//
+// Every property of the schema is read from the variable whose name is the
+// prefix, followed by the upper-cased names of the property and its parent
+// properties, joined by underscores. For example, with the prefix "APP", the
+// property "/server/readTimeout" is read from APP_SERVER_READ_TIMEOUT. A
+// property whose schema has an "x-env" keyword is read from the variable that
+// the keyword names instead, and the properties under it use that name as
+// their prefix.
+//
+// The values are converted according to the "type" keyword of the property:
+// integers, numbers, booleans and null ("null" or an empty value) are parsed,
+// objects are parsed as json, arrays are parsed as json or as a comma
+// separated list whose items are converted according to the schema of the
+// items, and all other values are kept as strings.
+func LoadEnv(prefix string, schema *jsonvalidator.RootJsonSchema, target interface{}) error {
+	return LoadEnviron(os.Environ(), prefix, schema, target)
+}
+
+// LoadEnviron is like LoadEnv, but reads the variables from environ, which
+// holds "key=value" strings like os.Environ() returns.
+func LoadEnviron(environ []string, prefix string, schema *jsonvalidator.RootJsonSchema, target interface{}) error {
+	variables := make(map[string]string, len(environ))
+	for _, variable := range environ {
+		if index := strings.IndexByte(variable, '='); index != -1 {
+			variables[variable[:index]] = variable[index+1:]
+		}
+	}
+
+	reader := envReader{
+		schema:    schema,
+		variables: variables,
+		sources:   map[string]string{},
+	}
+
+	object, err := reader.readObject("", prefix, 0)
+	if err != nil {
+		return EnvError{err: err}
+	}
+
+	bytes, err := json.Marshal(object)
+	if err != nil {
+		return EnvError{err: err}
+	}
+
+	err = load(bytes, schema, target)
+	if err != nil {
+		if validationErr, ok := err.(jsonvalidator.SchemaValidationError); ok {
+			return EnvError{
+				Variable: reader.source(validationErr.Path()),
+				Path:     validationErr.Path(),
+				Keyword:  validationErr.Keyword(),
+				err:      err,
+			}
+		}
+
+		return EnvError{err: err}
+	}
+
+	return nil
+}
+
+// envReader builds a configuration from environment variables.
+type envReader struct {
+	schema    *jsonvalidator.RootJsonSchema
+	variables map[string]string
+
+	// The names of the variables that the values were read from, by the json
+	// pointers of the values.
+	sources map[string]string
+}
+
+// readObject returns the properties of the object at the given location
+// that are set by environment variables, or nil if there are none.
+func (r *envReader) readObject(pointer string, prefix string, depth int) (map[string]interface{}, error) {
+	schema, err := r.schema.SchemaAt(pointer)
+	if schema == nil || err != nil || depth == maxEnvDepth {
+		return nil, err
+	}
+
+	var object map[string]interface{}
+	for name := range schema.Properties {
+		propertyPointer := pointer + "/" + jsonwalker.EscapeToken(name)
+		propertySchema, err := r.schema.SchemaAt(propertyPointer)
+		if err != nil {
+			return nil, err
+		}
+
+		variable := envName(prefix, name)
+		if extension := propertySchema.Extension("x-env"); extension != nil {
+			err = json.Unmarshal(extension, &variable)
+			if err != nil {
+				return nil, fmt.Errorf("invalid x-env keyword at %s: %v", propertyPointer, err)
+			}
+		}
+
+		var value interface{}
+		if raw, ok := r.variables[variable]; ok {
+			value, err = r.convert(raw, propertyPointer)
+			r.sources[propertyPointer] = variable
+		} else if len(propertySchema.Properties) > 0 {
+			var properties map[string]interface{}
+			properties, err = r.readObject(propertyPointer, variable, depth+1)
+			if properties != nil {
+				value = properties
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if value != nil || r.sources[propertyPointer] != "" {
+			if object == nil {
+				object = make(map[string]interface{})
+			}
+			object[name] = value
+		}
+	}
+
+	return object, nil
+}
+
+// convert parses the value of a variable according to the types of the
+// schema at the given location. Values that do not match any of the types
+// are kept as strings, so the validator reports them.
+func (r *envReader) convert(value string, pointer string) (interface{}, error) {
+	types, err := r.schema.TypesAt(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, jsonType := range []string{"integer", "number", "boolean", "null", "object", "array"} {
+		if !strutil.Contains(types, jsonType) {
+			continue
+		}
+
+		switch jsonType {
+		case "integer", "number":
+			var number json.Number
+			isNumber := value != "" && (value[0] == '-' || '0' <= value[0] && value[0] <= '9') &&
+				json.Unmarshal([]byte(value), &number) == nil
+			if isNumber && jsonType == "number" {
+				return number, nil
+			}
+
+			if float, err := number.Float64(); isNumber && err == nil && float == math.Trunc(float) {
+				return number, nil
+			}
+		case "boolean":
+			if boolean, err := strconv.ParseBool(value); err == nil {
+				return boolean, nil
+			}
+		case "null":
+			if value == "" || value == "null" {
+				return nil, nil
+			}
+		case "object":
+			var object map[string]interface{}
+			if json.Unmarshal([]byte(value), &object) == nil && object != nil {
+				return object, nil
+			}
+		case "array":
+			var array []interface{}
+			if json.Unmarshal([]byte(value), &array) == nil && array != nil {
+				return array, nil
+			}
+
+			array = []interface{}{}
+			if value == "" {
+				return array, nil
+			}
+
+			for index, item := range strings.Split(value, ",") {
+				converted, err := r.convert(strings.TrimSpace(item), pointer+"/"+strconv.Itoa(index))
+				if err != nil {
+					return nil, err
+				}
+
+				array = append(array, converted)
+			}
+
+			return array, nil
+		}
+	}
+
+	return value, nil
+}
+
+// source returns the name of the variable that the value at the given
+// location, or one of its parents, was read from.
+func (r *envReader) source(pointer string) string {
+	for {
+		if variable, ok := r.sources[pointer]; ok {
+			return variable
+		}
+
+		index := strings.LastIndexByte(pointer, '/')
+		if index == -1 {
+			return ""
+		}
+
+		pointer = pointer[:index]
+	}
+}
+
+// envName returns the name of the variable of a property, which is the
+// upper-cased property name, with underscores between the words of camel
+// cased names and instead of characters that are not letters or digits,
+// after the prefix.
+func envName(prefix string, property string) string {
+	var builder strings.Builder
+	builder.WriteString(prefix)
+	if prefix != "" {
+		builder.WriteByte('_')
+	}
+
+	var previous rune
+	for index, r := range property {
+		switch {
+		case unicode.IsUpper(r) && index > 0 && (unicode.IsLower(previous) || unicode.IsDigit(previous)):
+			builder.WriteByte('_')
+			builder.WriteRune(r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			builder.WriteRune(unicode.ToUpper(r))
+		default:
+			builder.WriteByte('_')
+		}
+		previous = r
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,115 @@
+package confval_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/confval"
+)
+
+const envSchema = `{
+	"$id": "http://example.com/confval/env.json",
+	"type": "object",
+	"required": ["name"],
+	"properties": {
+		"name": {"type": "string"},
+		"debug": {"type": "boolean", "default": false},
+		"server": {"$ref": "#/definitions/server"},
+		"allowedHosts": {"type": "array", "items": {"type": "string"}},
+		"retries": {"type": ["integer", "null"]},
+		"token": {"type": "string", "minLength": 8, "x-env": "SERVICE_TOKEN"}
+	},
+	"definitions": {
+		"server": {
+			"type": "object",
+			"default": {},
+			"properties": {
+				"port": {"type": "integer", "maximum": 65535, "default": 8080},
+				"readTimeout": {"type": "number"}
+			}
+		}
+	}
+}`
+
+type envConfig struct {
+	Name         string   `json:"name"`
+	Debug        bool     `json:"debug"`
+	AllowedHosts []string `json:"allowedHosts"`
+	Retries      *int     `json:"retries"`
+	Token        string   `json:"token"`
+	Server       struct {
+		Port        int     `json:"port"`
+		ReadTimeout float64 `json:"readTimeout"`
+	} `json:"server"`
+}
+
+func TestLoadEnviron(t *testing.T) {
+	schema, err := jsonvalidator.NewRootJsonSchema([]byte(envSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var config envConfig
+	err = confval.LoadEnviron([]string{
+		"APP_NAME=api",
+		"APP_DEBUG=true",
+		"APP_SERVER_READ_TIMEOUT=2.5",
+		"APP_ALLOWED_HOSTS=a.example.com, b.example.com",
+		"APP_RETRIES=",
+		"SERVICE_TOKEN=0123456789",
+		"APP_TOKEN=ignored",
+		"OTHER=1",
+	}, "APP", schema, &config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Name != "api" || !config.Debug || config.Retries != nil || config.Token != "0123456789" {
+		t.Errorf("unexpected config %+v", config)
+	}
+	if config.Server.Port != 8080 || config.Server.ReadTimeout != 2.5 {
+		t.Errorf("unexpected server config %+v", config.Server)
+	}
+	if !reflect.DeepEqual(config.AllowedHosts, []string{"a.example.com", "b.example.com"}) {
+		t.Errorf("unexpected allowed hosts %v", config.AllowedHosts)
+	}
+}
+
+func TestLoadEnvironInvalid(t *testing.T) {
+	schema, err := jsonvalidator.NewRootJsonSchema([]byte(envSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		environ  []string
+		variable string
+		path     string
+		keyword  string
+	}{
+		{[]string{"APP_NAME=api", "APP_SERVER_PORT=70000"}, "APP_SERVER_PORT", "/server/port", "maximum"},
+		{[]string{"APP_NAME=api", "APP_SERVER_PORT=http"}, "APP_SERVER_PORT", "/server/port", "type"},
+		{[]string{"APP_NAME=api", "SERVICE_TOKEN=short"}, "SERVICE_TOKEN", "/token", "minLength"},
+		{[]string{"APP_DEBUG=1"}, "", "", "required"},
+	}
+
+	for _, test := range tests {
+		err := confval.LoadEnviron(test.environ, "APP", schema, nil)
+		envErr, ok := err.(confval.EnvError)
+		if !ok {
+			t.Errorf("%v: expected an EnvError, got %v", test.environ, err)
+			continue
+		}
+
+		if envErr.Variable != test.variable || envErr.Path != test.path || envErr.Keyword != test.keyword {
+			t.Errorf("%v: unexpected error details %q, %q, %q", test.environ, envErr.Variable, envErr.Path, envErr.Keyword)
+		}
+
+		var validationErr jsonvalidator.SchemaValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%v: expected the EnvError to wrap the SchemaValidationError, got %v", test.environ, err)
+		}
+	}
+}
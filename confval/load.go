@@ -0,0 +1,24 @@
+//go:build !js
+// +build !js
+
+package confval
+
+import (
+	"io/ioutil"
+
+	"github.com/itayankri/gojsonvalidator"
+)
+
+// Load reads the configuration file at path, applies the default values
+// defined in schema, validates the result against schema and unmarshals it
+// into target.
+// Files with a ".yaml" or ".yml" extension are parsed as yaml, and all other
+// files are parsed as json.
+func Load(path string, schema *jsonvalidator.RootJsonSchema, target interface{}) error {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ConfigError{File: path, err: err}
+	}
+
+	return LoadBytes(path, bytes, schema, target)
+}
@@ -0,0 +1,221 @@
+package jsonvalidator
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"mime/quotedprintable"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// contentEncoding holds the encoding of the contents of a string, like
+// "base64". The keyword is an annotation unless the validator is in strict
+// content mode, in which case strings that cannot be decoded fail it.
+type contentEncoding string
+
+func (ce *contentEncoding) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	v, ok := jsonData.value.(string)
+	if !ok || !ctx.validator.strictContent {
+		return nil
+	}
+
+	_, known, err := decodeContent(string(*ce), v)
+	if known && err != nil {
+		return KeywordValidationError{
+			keyword: "contentEncoding",
+			reason:  "inspected value is not valid " + string(*ce) + ": " + err.Error(),
+			cause:   err,
+		}
+	}
+
+	return nil
+}
+
+// contentMediaType holds the media type of the contents of a string, which
+// are decoded with the sibling "contentEncoding" first, if it exists. The
+// keyword is an annotation unless the validator is in strict content mode,
+// in which case the contents of json media types (like "application/json"
+// and "application/schema+json") must be valid json documents.
+type contentMediaType struct {
+	mediaType       string
+	siblingEncoding *contentEncoding
+}
+
+func (cmt *contentMediaType) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	v, ok := jsonData.value.(string)
+	if !ok || !ctx.validator.strictContent || !isJSONMediaType(cmt.mediaType) {
+		return nil
+	}
+
+	// Contents that cannot be decoded fail the "contentEncoding" keyword,
+	// and their media type is not checked.
+	contents, known, err := cmt.decode(v)
+	if !known || err != nil {
+		return nil
+	}
+
+	if !json.Valid(contents) {
+		return KeywordValidationError{
+			keyword: "contentMediaType",
+			reason:  "inspected value is not a valid " + cmt.mediaType + " document",
+		}
+	}
+
+	return nil
+}
+
+// decode decodes the contents of a string with the sibling
+// "contentEncoding". known is false if the encoding is not supported.
+func (cmt *contentMediaType) decode(value string) (contents []byte, known bool, err error) {
+	if cmt.siblingEncoding == nil {
+		return []byte(value), true, nil
+	}
+
+	return decodeContent(string(*cmt.siblingEncoding), value)
+}
+
+func (cmt *contentMediaType) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &cmt.mediaType)
+}
+
+func (cmt *contentMediaType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cmt.mediaType)
+}
+
+// contentSchema holds the schema of the contents of a string, which are
+// decoded with the sibling "contentMediaType" (and its "contentEncoding"),
+// like a json payload in a base64 string. The keyword is ignored without a
+// json "contentMediaType", and it is an annotation unless the validator is
+// in strict content mode.
+type contentSchema struct {
+	JsonSchema
+	siblingMediaType *contentMediaType
+}
+
+func (cs *contentSchema) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	v, ok := jsonData.value.(string)
+	if !ok || !ctx.validator.strictContent || cs.siblingMediaType == nil || !isJSONMediaType(cs.siblingMediaType.mediaType) {
+		return nil
+	}
+
+	// Contents that cannot be decoded, or that are not json documents,
+	// fail the sibling keywords.
+	contents, known, err := cs.siblingMediaType.decode(v)
+	if !known || err != nil || !json.Valid(contents) {
+		return nil
+	}
+
+	value, err := ctx.decodeJSON(contents)
+	if err != nil {
+		return err
+	}
+
+	// The contents are not a part of the document, so the output of their
+	// validation is dropped, and their failures are reported by this
+	// keyword.
+	output := ctx.outputMark()
+	mark := ctx.enterSchema("contentSchema")
+	err = cs.validateJsonData(jsonPath, newJsonData(value), rootSchemaId, ctx)
+	ctx.leaveSchema(mark)
+	ctx.discardOutput(output)
+
+	if err == nil {
+		return nil
+	}
+
+	// Errors that abort the validation are returned as they are.
+	if _, ok := err.(SchemaValidationError); !ok {
+		return err
+	}
+
+	return KeywordValidationError{
+		keyword: "contentSchema",
+		reason:  "decoded contents failed in validation: " + err.Error(),
+		cause:   err,
+	}
+}
+
+// decodeContent decodes a string that is encoded with one of the
+// ENCODING_* encodings. known is false if the encoding is not one of them,
+// in which case the string is not checked.
+func decodeContent(encoding string, value string) (contents []byte, known bool, err error) {
+	switch strings.ToLower(encoding) {
+	case ENCODING_BASE64:
+		// RFC 2045 allows line breaks in base64 contents.
+		value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+		contents, err = base64.StdEncoding.Strict().DecodeString(value)
+		return contents, true, err
+	case ENCODING_QUOTED_PRINTABLE:
+		// The decoder of the standard library passes malformed escapes
+		// through, so they are checked first.
+		if err := checkQuotedPrintable(value); err != nil {
+			return nil, true, err
+		}
+		contents, err = ioutil.ReadAll(quotedprintable.NewReader(strings.NewReader(value)))
+		return contents, true, err
+	case ENCODING_7BIT:
+		for index := 0; index < len(value); index++ {
+			if value[index] == 0 || value[index] >= 0x80 {
+				return nil, true, errors.New("7bit contents may only hold non-null ASCII characters")
+			}
+		}
+		return []byte(value), true, nil
+	case ENCODING_8bit:
+		if strings.IndexByte(value, 0) >= 0 {
+			return nil, true, errors.New("8bit contents may not hold null characters")
+		}
+		return []byte(value), true, nil
+	case ENCODING_BINARY:
+		return []byte(value), true, nil
+	}
+
+	return nil, false, nil
+}
+
+// checkQuotedPrintable returns an error if a string is not encoded with
+// quoted-printable, as described in RFC 2045, section 6.7: every "=" starts
+// an escape of two hexadecimal digits or a soft line break, and the other
+// characters are printable ASCII characters, spaces, tabs and line breaks.
+func checkQuotedPrintable(value string) error {
+	for index := 0; index < len(value); index++ {
+		c := value[index]
+		switch {
+		case c == '=':
+			rest := value[index+1:]
+			switch {
+			case len(rest) >= 2 && isHexDigit(rest[0]) && isHexDigit(rest[1]):
+				index += 2
+			case strings.HasPrefix(rest, "\r\n"):
+				index += 2
+			case strings.HasPrefix(rest, "\n"):
+				index++
+			default:
+				return errors.New("invalid quoted-printable escape at offset " + strconv.Itoa(index))
+			}
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || (c >= 33 && c <= 126):
+		default:
+			return errors.New("invalid quoted-printable character at offset " + strconv.Itoa(index))
+		}
+	}
+
+	return nil
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// isJSONMediaType returns true if the media type is "application/json", or
+// a structured syntax media type of json, like "application/schema+json".
+func isJSONMediaType(mediaType string) bool {
+	parsed, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return false
+	}
+
+	return parsed == "application/json" || strings.HasSuffix(parsed, "+json")
+}
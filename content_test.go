@@ -0,0 +1,126 @@
+package jsonvalidator
+
+import (
+	"testing"
+)
+
+func TestValidatorStrictContent(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/content/envelope.json",
+		"properties": {
+			"payload": {"contentEncoding": "base64", "contentMediaType": "application/json"},
+			"body": {"contentEncoding": "quoted-printable"},
+			"manifest": {"contentMediaType": "application/schema+json; charset=utf-8"},
+			"image": {"contentEncoding": "base64", "contentMediaType": "image/png"}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		document string
+		valid    bool
+		keyword  string
+	}{
+		{`{"payload": "eyJzdWIiOiAiMTIzIn0="}`, true, ""},
+		{"{\"payload\": \"eyJzdWIiOi\\r\\nAiMTIzIn0=\"}", true, ""},
+		{`{"payload": "not base64!"}`, false, "contentEncoding"},
+		{`{"payload": "bm90IGpzb24="}`, false, "contentMediaType"},
+		{`{"body": "caf=C3=A9"}`, true, ""},
+		{`{"body": "caf=ZZ"}`, false, "contentEncoding"},
+		{`{"manifest": "{\"type\": \"string\"}"}`, true, ""},
+		{`{"manifest": "{"}`, false, "contentMediaType"},
+		{`{"image": "iVBORw0KGgo="}`, true, ""},
+	}
+
+	for _, testCase := range testCases {
+		document := []byte(testCase.document)
+		if err := NewValidator(rootSchema).Validate(document); err != nil {
+			t.Errorf("%s: expected the content keywords to be annotations by default, got %v", testCase.document, err)
+		}
+
+		err := NewValidator(rootSchema).StrictContent(true).Validate(document)
+		if valid := err == nil; valid != testCase.valid {
+			t.Errorf("%s: expected valid: %v, got %v", testCase.document, testCase.valid, err)
+			continue
+		}
+		if err != nil {
+			if keywordErr, ok := err.(SchemaValidationError); !ok || keywordErr.Keyword() != testCase.keyword {
+				t.Errorf("%s: expected a failure of %s, got %v", testCase.document, testCase.keyword, err)
+			}
+		}
+	}
+
+	result, err := NewValidator(rootSchema).ValidateResult([]byte(`{"payload": "not base64!"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	annotations := map[string]interface{}{}
+	for _, annotation := range result.Annotations() {
+		annotations[annotation.KeywordLocation] = annotation.Value
+	}
+	if annotations["/properties/payload/contentEncoding"] != "base64" || annotations["/properties/payload/contentMediaType"] != "application/json" {
+		t.Errorf("expected the content keywords to be recorded as annotations, got %v", annotations)
+	}
+}
+
+func TestContentSchema(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/content/token.json",
+		"properties": {
+			"claims": {
+				"contentEncoding": "base64",
+				"contentMediaType": "application/json",
+				"contentSchema": {
+					"required": ["sub"],
+					"properties": {"sub": {"type": "string"}, "exp": {"type": "integer"}}
+				}
+			},
+			"ignored": {"contentSchema": {"type": "object"}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		document string
+		valid    bool
+	}{
+		// {"sub": "123", "exp": 1}
+		{`{"claims": "eyJzdWIiOiAiMTIzIiwgImV4cCI6IDF9"}`, true},
+		// {"exp": 1}
+		{`{"claims": "eyJleHAiOiAxfQ=="}`, false},
+		// {"sub": 123}
+		{`{"claims": "eyJzdWIiOiAxMjN9"}`, false},
+		// Without "contentMediaType" the keyword is ignored.
+		{`{"ignored": "not an object"}`, true},
+	}
+
+	for _, testCase := range testCases {
+		document := []byte(testCase.document)
+		if err := NewValidator(rootSchema).Validate(document); err != nil {
+			t.Errorf("%s: expected contentSchema to be an annotation by default, got %v", testCase.document, err)
+		}
+
+		err := NewValidator(rootSchema).StrictContent(true).Validate(document)
+		if valid := err == nil; valid != testCase.valid {
+			t.Errorf("%s: expected valid: %v, got %v", testCase.document, testCase.valid, err)
+			continue
+		}
+		if err != nil {
+			if keywordErr, ok := err.(SchemaValidationError); !ok || keywordErr.Keyword() != "contentSchema" || keywordErr.Path() != "/claims" {
+				t.Errorf("%s: expected a failure of contentSchema at /claims, got %v", testCase.document, err)
+			}
+		}
+	}
+
+	result, err := NewValidator(rootSchema).StrictContent(true).ValidateResult([]byte(`{"claims": "eyJleHAiOiAxfQ=="}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := result.Errors(); len(errs) != 1 || errs[0].Keyword != "contentSchema" {
+		t.Errorf("expected only the failure of contentSchema, got %v", errs)
+	}
+}
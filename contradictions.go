@@ -0,0 +1,199 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+// Contradiction describes keywords of a schema that no value can satisfy
+// together, like a "minLength" above the "maxLength". Such schemas are valid,
+// but they reject values silently, so the contradictions of a root schema
+// are found when it is created, and are returned by
+// RootJsonSchema.Contradictions().
+type Contradiction struct {
+	// KeywordLocation is the json pointer of the contradicting keyword,
+	// relative to the root schema.
+	KeywordLocation string
+
+	Message string
+
+	// Unsatisfiable is true if the schema rejects every value. Keywords
+	// that only apply to one type can contradict each other while values
+	// of other types are still valid, unless the "type" of the schema
+	// allows only the type that the keywords apply to.
+	Unsatisfiable bool
+}
+
+func (c Contradiction) String() string {
+	return "#" + c.KeywordLocation + ": " + c.Message
+}
+
+// scannedSchema is a schema that was scanned in the compilation of a root
+// schema, and its json pointer in the root schema.
+type scannedSchema struct {
+	path   string
+	schema *JsonSchema
+}
+
+// Contradictions returns the contradictions of the schemas in the root
+// schema, sorted by their locations. Treating them as errors is left to
+// the caller, since a schema may contradict itself on purpose, for example
+// to retire a property.
+func (rs *RootJsonSchema) Contradictions() []Contradiction {
+	return rs.contradictions
+}
+
+// findContradictions returns the contradictions of the scanned schemas of a
+// root schema.
+func findContradictions(rootSchema *RootJsonSchema, scanned []scannedSchema) []Contradiction {
+	// The values of "enum", "const" and "required" are validated against
+	// the other keywords of their schema. References that cannot be
+	// resolved yet fail with errors that are not SchemaValidationErrors,
+	// and are ignored.
+	ctx := newValidationContext(NewValidator(rootSchema), 0)
+
+	var contradictions []Contradiction
+	for _, s := range scanned {
+		contradictions = append(contradictions, s.schema.contradictions(s.path, rootSchema.id(), ctx)...)
+	}
+
+	sort.SliceStable(contradictions, func(i, j int) bool {
+		return contradictions[i].KeywordLocation < contradictions[j].KeywordLocation
+	})
+
+	return contradictions
+}
+
+// contradictions returns the contradictions of the keywords of the schema,
+// which is found at schemaPath.
+func (js *JsonSchema) contradictions(schemaPath string, rootSchemaId string, ctx *validationContext) []Contradiction {
+	var contradictions []Contradiction
+	add := func(keyword string, jsonType string, message string) {
+		contradictions = append(contradictions, Contradiction{
+			KeywordLocation: schemaPath + "/" + keyword,
+			Message:         message,
+			Unsatisfiable:   jsonType == "" || js.onlyAllows(jsonType),
+		})
+	}
+
+	limitPairs := []struct {
+		lower, upper           string
+		lowerLimit, upperLimit *int
+		jsonType               string
+	}{
+		{"minLength", "maxLength", (*int)(js.MinLength), (*int)(js.MaxLength), TYPE_STRING},
+		{"minItems", "maxItems", (*int)(js.MinItems), (*int)(js.MaxItems), TYPE_ARRAY},
+		{"minProperties", "maxProperties", (*int)(js.MinProperties), (*int)(js.MaxProperties), TYPE_OBJECT},
+	}
+	for _, pair := range limitPairs {
+		if pair.lowerLimit != nil && pair.upperLimit != nil && *pair.lowerLimit > *pair.upperLimit {
+			add(pair.lower, pair.jsonType, "\""+pair.lower+"\" ("+strconv.Itoa(*pair.lowerLimit)+") is greater than \""+
+				pair.upper+"\" ("+strconv.Itoa(*pair.upperLimit)+")")
+		}
+	}
+
+	if keyword, message, ok := js.emptyNumericRange(); ok {
+		add(keyword, TYPE_NUMBER, message)
+	}
+
+	if js.MaxProperties != nil && len(js.Required) > int(*js.MaxProperties) {
+		add("required", TYPE_OBJECT, strconv.Itoa(len(js.Required))+" properties are required, but \"maxProperties\" is "+
+			strconv.Itoa(int(*js.MaxProperties)))
+	}
+
+	if js.PropertyNames != nil {
+		for index, name := range js.Required {
+			if js.PropertyNames.rejects(name, rootSchemaId, ctx) {
+				add("required/"+strconv.Itoa(index), TYPE_OBJECT, "the required property \""+name+"\" is not allowed by \"propertyNames\"")
+			}
+		}
+	}
+
+	if js.Type != nil {
+		typeSchema := &JsonSchema{Type: js.Type}
+
+		if js.Const != nil {
+			value, err := jsonwalker.JsonPointer{}.Evaluate(json.RawMessage(*js.Const))
+			if err == nil && typeSchema.rejects(value, rootSchemaId, ctx) {
+				add("const", "", "the \"const\" value does not match the \"type\" of the schema")
+			}
+		}
+
+		if len(js.Enum) > 0 {
+			rejected := 0
+			for _, value := range js.Enum {
+				if typeSchema.rejects(value, rootSchemaId, ctx) {
+					rejected++
+				}
+			}
+			if rejected == len(js.Enum) {
+				add("enum", "", "none of the \"enum\" values match the \"type\" of the schema")
+			}
+		}
+	}
+
+	return contradictions
+}
+
+// emptyNumericRange returns the lower bound keyword of numeric bounds whose
+// range is empty, and a message that describes the bounds.
+func (js *JsonSchema) emptyNumericRange() (string, string, bool) {
+	// The lower bound is the greater of the bounds, and it is exclusive if
+	// the exclusive bound is the greater one, and likewise for the upper
+	// bound. In the draft-04 boolean form, "exclusiveMinimum" makes
+	// "minimum" exclusive.
+	lowerKeyword, lower, lowerExclusive := "", 0.0, false
+	if js.Minimum != nil {
+		lowerKeyword, lower = "minimum", float64(*js.Minimum)
+		lowerExclusive = js.ExclusiveMinimum != nil && js.ExclusiveMinimum.boolean != nil && *js.ExclusiveMinimum.boolean
+	}
+	if em := js.ExclusiveMinimum; em != nil && em.boolean == nil && (lowerKeyword == "" || em.limit >= lower) {
+		lowerKeyword, lower, lowerExclusive = "exclusiveMinimum", em.limit, true
+	}
+
+	upperKeyword, upper, upperExclusive := "", 0.0, false
+	if js.Maximum != nil {
+		upperKeyword, upper = "maximum", float64(*js.Maximum)
+		upperExclusive = js.ExclusiveMaximum != nil && js.ExclusiveMaximum.boolean != nil && *js.ExclusiveMaximum.boolean
+	}
+	if em := js.ExclusiveMaximum; em != nil && em.boolean == nil && (upperKeyword == "" || em.limit <= upper) {
+		upperKeyword, upper, upperExclusive = "exclusiveMaximum", em.limit, true
+	}
+
+	if lowerKeyword == "" || upperKeyword == "" {
+		return "", "", false
+	}
+
+	if lower > upper || (lower == upper && (lowerExclusive || upperExclusive)) {
+		return lowerKeyword, "no number is within \"" + lowerKeyword + "\" (" + formatNumber(lower) + ") and \"" +
+			upperKeyword + "\" (" + formatNumber(upper) + ")", true
+	}
+
+	return "", "", false
+}
+
+// onlyAllows returns true if the "type" of the schema allows only values of
+// the given type. Integers are numbers.
+func (js *JsonSchema) onlyAllows(jsonType string) bool {
+	if js.Type == nil {
+		return false
+	}
+
+	name, ok := js.Type.singleTypeName()
+	return ok && (name == jsonType || (jsonType == TYPE_NUMBER && name == TYPE_INTEGER))
+}
+
+// rejects returns true if the value is invalid against the schema. Values
+// whose validation fails for other reasons, like references that cannot be
+// resolved, are not rejected.
+func (js *JsonSchema) rejects(value interface{}, rootSchemaId string, ctx *validationContext) bool {
+	_, rejected := js.validateJsonData("", newJsonData(value), rootSchemaId, ctx).(SchemaValidationError)
+	return rejected
+}
+
+func formatNumber(limit float64) string {
+	return strconv.FormatFloat(limit, 'f', -1, 64)
+}
@@ -0,0 +1,48 @@
+package jsonvalidator
+
+import "testing"
+
+func TestContradictions(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/contradictions.json",
+		"properties": {
+			"code": {"type": "string", "minLength": 5, "maxLength": 3},
+			"tags": {"minItems": 2, "maxItems": 1},
+			"age": {"exclusiveMinimum": 10, "maximum": 10},
+			"level": {"type": "integer", "enum": ["low", "high"]},
+			"name": {"type": "string", "const": 1},
+			"options": {
+				"required": ["Color", "size"],
+				"propertyNames": {"$ref": "#/definitions/lowerCase"},
+				"maxProperties": 1
+			},
+			"valid": {"type": ["string", "null"], "enum": [null], "minimum": 1, "maximum": 1}
+		},
+		"definitions": {
+			"lowerCase": {"pattern": "^[a-z]+$"}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Contradiction{
+		{"/properties/age/exclusiveMinimum", `no number is within "exclusiveMinimum" (10) and "maximum" (10)`, false},
+		{"/properties/code/minLength", `"minLength" (5) is greater than "maxLength" (3)`, true},
+		{"/properties/level/enum", `none of the "enum" values match the "type" of the schema`, true},
+		{"/properties/name/const", `the "const" value does not match the "type" of the schema`, true},
+		{"/properties/options/required", `2 properties are required, but "maxProperties" is 1`, false},
+		{"/properties/options/required/0", `the required property "Color" is not allowed by "propertyNames"`, false},
+		{"/properties/tags/minItems", `"minItems" (2) is greater than "maxItems" (1)`, false},
+	}
+
+	contradictions := rootSchema.Contradictions()
+	if len(contradictions) != len(expected) {
+		t.Fatalf("expected %d contradictions, got %v", len(expected), contradictions)
+	}
+	for index, contradiction := range contradictions {
+		if contradiction != expected[index] {
+			t.Errorf("expected %v, got %v", expected[index], contradiction)
+		}
+	}
+}
@@ -0,0 +1,625 @@
+// Package crdschema converts json schemas to the structural schemas of
+// Kubernetes CustomResourceDefinitions, which are the "openAPIV3Schema" of
+// the versions of a resource.
+//
+// A structural schema gives every field a type, describes the structure of
+// the resource outside the logical junctors (allOf, anyOf, oneOf and not),
+// and prunes the fields that it does not describe unless they are marked
+// with "x-kubernetes-preserve-unknown-fields". The conversion inlines "$ref"
+// references, rewrites the keywords that have a Kubernetes equivalent, drops
+// the keywords that Kubernetes does not support, and reports every keyword
+// that was dropped or transformed.
+package crdschema
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/internal/schemautil"
+	"github.com/itayankri/gojsonvalidator/internal/strutil"
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+// ErrNotAnObject is returned when the root schema does not describe an
+// object, which every Kubernetes resource is.
+var ErrNotAnObject = errors.New("the schema of a resource must describe an object")
+
+// ChangeKind tells whether a keyword was dropped or transformed.
+type ChangeKind int
+
+const (
+	// Dropped keywords were left out of the structural schema.
+	Dropped ChangeKind = iota
+
+	// Transformed keywords were replaced by their Kubernetes equivalent.
+	Transformed
+)
+
+func (k ChangeKind) String() string {
+	if k == Transformed {
+		return "transformed"
+	}
+
+	return "dropped"
+}
+
+// Change describes a keyword of the source schema that could not be copied
+// to the structural schema as it is. Location is the json pointer of the
+// keyword in the source schema, following the "$ref" references that were
+// inlined, and Keyword is empty if the whole schema at Location changed.
+type Change struct {
+	Location string
+	Keyword  string
+	Kind     ChangeKind
+	Reason   string
+}
+
+// The keywords that are copied without changes, where the value of the
+// keywords in the first list is an array of schemas.
+var (
+	junctorKeywords = []string{"allOf", "anyOf", "oneOf"}
+	plainKeywords   = []string{
+		"title", "description", "default", "enum", "format", "multipleOf",
+		"maxLength", "minLength", "pattern", "maxItems", "minItems",
+		"maxProperties", "minProperties", "required",
+	}
+)
+
+// The keywords that logical junctors cannot have, because they describe the
+// structure of the resource.
+var structureKeywords = []string{"type", "description", "default", "additionalProperties"}
+
+// The reasons for dropping the keywords that Kubernetes does not support.
+var droppedKeywords = map[string]string{
+	"$schema":           "Kubernetes does not support the $schema keyword",
+	"$id":               "Kubernetes does not support schema identifiers",
+	"$comment":          "Kubernetes does not support comments",
+	"definitions":       "the referenced definitions are inlined",
+	"dependencies":      "Kubernetes does not support dependencies",
+	"patternProperties": "Kubernetes does not support pattern properties",
+	"additionalItems":   "Kubernetes does not support tuples",
+	"contains":          "Kubernetes does not support the contains keyword",
+	"propertyNames":     "Kubernetes does not support the propertyNames keyword",
+	"readOnly":          "Kubernetes does not support the readOnly keyword",
+	"writeOnly":         "Kubernetes does not support the writeOnly keyword",
+	"contentMediaType":  "Kubernetes does not support content keywords",
+	"contentEncoding":   "Kubernetes does not support content keywords",
+	"contentSchema":     "Kubernetes does not support content keywords",
+}
+
+const (
+	preserveUnknownFields = "x-kubernetes-preserve-unknown-fields"
+	intOrString           = "x-kubernetes-int-or-string"
+	listType              = "x-kubernetes-list-type"
+)
+
+// Export converts a root schema to a structural schema. It returns the
+// keywords that were dropped or transformed, in the order of their location.
+func Export(schema *jsonvalidator.RootJsonSchema) ([]byte, []Change, error) {
+	root, err := schemautil.Value(&schema.JsonSchema)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The root schema is being inlined while it is converted.
+	exporter := &exporter{
+		schema:     schema,
+		references: schemautil.References{"#": true},
+		changes:    map[string]Change{},
+	}
+	converted, err := exporter.convert(root, "", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if converted["type"] != "object" {
+		return nil, nil, ErrNotAnObject
+	}
+
+	bytes, err := json.Marshal(converted)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changes := make([]Change, 0, len(exporter.changes))
+	for _, change := range exporter.changes {
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Location < changes[j].Location
+	})
+
+	return bytes, changes, nil
+}
+
+type exporter struct {
+	schema *jsonvalidator.RootJsonSchema
+
+	// The changes by their location, so the keywords of a schema that is
+	// referenced more than once are reported once.
+	changes map[string]Change
+
+	// The references that are being inlined, which cannot be inlined again
+	// inside themselves.
+	references schemautil.References
+}
+
+func (e *exporter) change(location string, keyword string, kind ChangeKind, reason string) {
+	if keyword != "" {
+		location += "/" + jsonwalker.EscapeToken(keyword)
+	}
+
+	e.changes[location] = Change{
+		Location: location,
+		Keyword:  keyword,
+		Kind:     kind,
+		Reason:   reason,
+	}
+}
+
+// convert returns the structural form of a schema. The structure is nil
+// for the schemas that describe the structure of the resource, and for the
+// schemas inside logical junctors it is the source schema whose structure
+// they apply to.
+func (e *exporter) convert(schema interface{}, location string, structure map[string]interface{}) (map[string]interface{}, error) {
+	object, _ := schema.(map[string]interface{})
+	if rejectAll, _ := object["rejectAll"].(bool); rejectAll {
+		if structure != nil {
+			return map[string]interface{}{"not": map[string]interface{}{}}, nil
+		}
+
+		e.change(location, "", Dropped, "Kubernetes does not support schemas that reject every value")
+		return map[string]interface{}{preserveUnknownFields: true}, nil
+	}
+
+	// "$ref" overrides the other keywords of the schema.
+	if reference, ok := object["$ref"].(string); ok {
+		return e.inline(reference, location, structure)
+	}
+
+	converted := map[string]interface{}{}
+	for keyword, value := range object {
+		if reason, ok := droppedKeywords[keyword]; ok {
+			e.change(location, keyword, Dropped, reason)
+			continue
+		}
+
+		if structure != nil && (strutil.Contains(structureKeywords, keyword) || strings.HasPrefix(keyword, "x-kubernetes-")) {
+			e.change(location, keyword, Dropped, "logical junctors cannot describe the structure of the resource")
+			continue
+		}
+
+		keywordLocation := location + "/" + jsonwalker.EscapeToken(keyword)
+		switch {
+		case keyword == "type":
+			e.convertType(converted, value, location)
+		case keyword == "const":
+			converted["enum"] = []interface{}{value}
+			e.change(location, keyword, Transformed, "a constant is written as an enum with one value")
+		case keyword == "examples":
+			examples, _ := value.([]interface{})
+			if len(examples) == 0 || structure != nil {
+				e.change(location, keyword, Dropped, "Kubernetes does not support a list of examples")
+				break
+			}
+
+			converted["example"] = examples[0]
+			e.change(location, keyword, Transformed, "the first example is written as \"example\"")
+		case keyword == "minimum" || keyword == "maximum":
+			// The exclusive bounds are merged below.
+			if _, ok := converted[keyword]; !ok {
+				converted[keyword] = value
+			}
+		case keyword == "exclusiveMinimum" || keyword == "exclusiveMaximum":
+			// Kubernetes gives the exclusive bound as a flag of "minimum" and
+			// "maximum", like json schema draft 4.
+			bound := "minimum"
+			if keyword == "exclusiveMaximum" {
+				bound = "maximum"
+			}
+			if schemautil.IsStricter(value, object[bound], bound == "minimum") {
+				converted[bound] = value
+				converted[keyword] = true
+			} else {
+				converted[bound] = object[bound]
+			}
+			e.change(location, keyword, Transformed, "the exclusive bound is written as a flag of \""+bound+"\"")
+		case keyword == "uniqueItems":
+			// Converted below, after "items".
+		case keyword == "items":
+			if _, ok := value.([]interface{}); ok {
+				converted[keyword] = map[string]interface{}{preserveUnknownFields: true}
+				e.change(location, keyword, Transformed, "Kubernetes does not support tuples, the items are preserved without a schema")
+				break
+			}
+
+			items, err := e.convert(value, keywordLocation, substructure(structure, "items"))
+			if err != nil {
+				return nil, err
+			}
+			converted[keyword] = items
+		case keyword == "properties":
+			properties, err := e.convertProperties(value, keywordLocation, structure)
+			if err != nil {
+				return nil, err
+			}
+			converted[keyword] = properties
+		case keyword == "additionalProperties":
+			// Converted below, after "properties".
+		case strutil.Contains(junctorKeywords, keyword):
+			subSchemas, err := e.convertList(value, keywordLocation, junctorStructure(structure, object))
+			if err != nil {
+				return nil, err
+			}
+			converted[keyword] = subSchemas
+		case keyword == "not":
+			subSchema, err := e.convert(value, keywordLocation, junctorStructure(structure, object))
+			if err != nil {
+				return nil, err
+			}
+			converted[keyword] = subSchema
+		case keyword == "if" || keyword == "then" || keyword == "else":
+			// Converted below, after "allOf".
+		case strings.HasPrefix(keyword, "x-kubernetes-") || strutil.Contains(plainKeywords, keyword):
+			converted[keyword] = value
+		default:
+			e.change(location, keyword, Dropped, "Kubernetes does not support the keyword")
+		}
+	}
+
+	if _, ok := object["if"]; ok {
+		condition, err := e.convertCondition(object, location, junctorStructure(structure, object))
+		if err != nil {
+			return nil, err
+		}
+
+		allOf, _ := converted["allOf"].([]interface{})
+		converted["allOf"] = append(allOf, condition)
+	} else {
+		for _, keyword := range []string{"then", "else"} {
+			if _, ok := object[keyword]; ok {
+				e.change(location, keyword, Dropped, "the keyword has no effect without \"if\"")
+			}
+		}
+	}
+
+	if structure == nil {
+		err := e.convertStructure(converted, object, location)
+		if err != nil {
+			return nil, err
+		}
+	} else if object["uniqueItems"] == true {
+		e.change(location, "uniqueItems", Dropped, "Kubernetes does not support unique items in logical junctors")
+	}
+
+	return converted, nil
+}
+
+// convertStructure completes a converted schema that describes the
+// structure of the resource, so it is structural.
+func (e *exporter) convertStructure(converted map[string]interface{}, object map[string]interface{}, location string) error {
+	_, hasProperties := converted["properties"]
+	if additionalProperties, ok := object["additionalProperties"]; ok {
+		subSchema, _ := additionalProperties.(map[string]interface{})
+		switch {
+		case subSchema["rejectAll"] == true:
+			e.change(location, "additionalProperties", Dropped, "unknown fields are pruned instead of rejected")
+		case hasProperties:
+			e.change(location, "additionalProperties", Dropped, "Kubernetes does not support additional properties next to properties, unknown fields are pruned")
+		case len(subSchema) == 0:
+			converted[preserveUnknownFields] = true
+			e.change(location, "additionalProperties", Transformed, "any additional property is written as "+preserveUnknownFields)
+		default:
+			values, err := e.convert(subSchema, location+"/additionalProperties", nil)
+			if err != nil {
+				return err
+			}
+			converted["additionalProperties"] = values
+		}
+	}
+
+	// A schema without a type is given the type of its keywords, or the
+	// type of the logical junctors that accept integers and strings.
+	if _, ok := converted["type"]; !ok && converted[intOrString] == nil && converted[preserveUnknownFields] == nil {
+		switch {
+		case hasProperties || converted["additionalProperties"] != nil:
+			converted["type"] = "object"
+			e.change(location, "type", Transformed, "the type of a schema with properties is object")
+		case converted["items"] != nil:
+			converted["type"] = "array"
+			e.change(location, "type", Transformed, "the type of a schema with items is array")
+		case enumType(converted["enum"]) != "":
+			converted["type"] = enumType(converted["enum"])
+			e.change(location, "type", Transformed, "the type of a schema with an enum is the type of its values")
+		case isIntOrString(junctorTypes(object)):
+			converted[intOrString] = true
+			e.change(location, "type", Transformed, "integers and strings are written as "+intOrString)
+		default:
+			converted[preserveUnknownFields] = true
+			e.change(location, "type", Transformed, "a value of any type is written as "+preserveUnknownFields)
+		}
+	}
+
+	switch converted["type"] {
+	case "array":
+		items, ok := converted["items"].(map[string]interface{})
+		if !ok {
+			items = map[string]interface{}{preserveUnknownFields: true}
+			converted["items"] = items
+			e.change(location, "items", Transformed, "arrays without items preserve their items")
+		}
+
+		if object["uniqueItems"] != true {
+			break
+		}
+
+		// A set of scalar values is the Kubernetes equivalent of unique
+		// items.
+		switch items["type"] {
+		case "string", "integer", "number", "boolean":
+			converted[listType] = "set"
+			e.change(location, "uniqueItems", Transformed, "unique items are written as a list of type set")
+		default:
+			e.change(location, "uniqueItems", Dropped, "Kubernetes does not support unique items that are not scalars")
+		}
+	case "object":
+		if !hasProperties && converted["additionalProperties"] == nil && converted[preserveUnknownFields] == nil {
+			converted[preserveUnknownFields] = true
+			e.change(location, "type", Transformed, "objects without properties preserve their fields")
+		}
+	}
+
+	return nil
+}
+
+// convertType sets the type of a converted schema from a "type" keyword.
+func (e *exporter) convertType(converted map[string]interface{}, value interface{}, location string) {
+	var types []string
+	switch v := value.(type) {
+	case string:
+		types = []string{v}
+	case []interface{}:
+		for _, jsonType := range v {
+			if str, ok := jsonType.(string); ok {
+				types = append(types, str)
+			}
+		}
+	}
+
+	var reasons []string
+	if strutil.Contains(types, "null") {
+		converted["nullable"] = true
+		types = remove(types, "null")
+		reasons = append(reasons, "null is written as nullable")
+	}
+
+	// "number" covers "integer".
+	if strutil.Contains(types, "number") {
+		types = remove(types, "integer")
+	}
+
+	switch {
+	case len(types) == 1:
+		converted["type"] = types[0]
+	case isIntOrString(types):
+		converted[intOrString] = true
+		reasons = append(reasons, "integers and strings are written as "+intOrString)
+	default:
+		converted[preserveUnknownFields] = true
+		reasons = append(reasons, "values of several types are written as "+preserveUnknownFields)
+	}
+
+	if len(reasons) > 0 {
+		e.change(location, "type", Transformed, strings.Join(reasons, ", "))
+	}
+}
+
+// inline returns the converted schema that a reference points to.
+func (e *exporter) inline(reference string, location string, structure map[string]interface{}) (map[string]interface{}, error) {
+	converted, recursive, err := e.references.Inline(e.schema, reference, location, func(value interface{}, location string) (map[string]interface{}, error) {
+		return e.convert(value, location, structure)
+	})
+	if !recursive {
+		return converted, err
+	}
+
+	if structure != nil {
+		e.change(location, "$ref", Dropped, "Kubernetes does not support recursive schemas")
+		return map[string]interface{}{}, nil
+	}
+
+	e.change(location, "$ref", Transformed, "Kubernetes does not support recursive schemas, the fields are preserved without a schema")
+	return map[string]interface{}{
+		"type":                "object",
+		preserveUnknownFields: true,
+	}, nil
+}
+
+// convertProperties converts the properties of a schema. The properties of
+// the schemas inside logical junctors must be described by the structure.
+func (e *exporter) convertProperties(value interface{}, location string, structure map[string]interface{}) (map[string]interface{}, error) {
+	subSchemas, _ := value.(map[string]interface{})
+	properties, _ := structure["properties"].(map[string]interface{})
+
+	converted := make(map[string]interface{}, len(subSchemas))
+	for name, subSchema := range subSchemas {
+		var propertyStructure map[string]interface{}
+		if structure != nil {
+			property, ok := properties[name]
+			if !ok {
+				e.change(location, name, Dropped, "the property is not described outside the logical junctor")
+				continue
+			}
+
+			propertyStructure, _ = property.(map[string]interface{})
+			if propertyStructure == nil {
+				propertyStructure = map[string]interface{}{}
+			}
+		}
+
+		var err error
+		converted[name], err = e.convert(subSchema, location+"/"+jsonwalker.EscapeToken(name), propertyStructure)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return converted, nil
+}
+
+func (e *exporter) convertList(value interface{}, location string, structure map[string]interface{}) ([]interface{}, error) {
+	list, _ := value.([]interface{})
+	converted := make([]interface{}, len(list))
+	for index, subSchema := range list {
+		var err error
+		converted[index], err = e.convert(subSchema, location+"/"+strconv.Itoa(index), structure)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return converted, nil
+}
+
+// convertCondition returns the logical junctor equivalent of "if", "then"
+// and "else": either the condition and "then" hold, or the condition does
+// not hold and "else" does.
+func (e *exporter) convertCondition(object map[string]interface{}, location string, structure map[string]interface{}) (map[string]interface{}, error) {
+	condition, err := e.convert(object["if"], location+"/if", structure)
+	if err != nil {
+		return nil, err
+	}
+
+	whenTrue := []interface{}{condition}
+	if then, ok := object["then"]; ok {
+		converted, err := e.convert(then, location+"/then", structure)
+		if err != nil {
+			return nil, err
+		}
+		whenTrue = append(whenTrue, converted)
+	}
+
+	whenFalse := []interface{}{map[string]interface{}{"not": condition}}
+	if otherwise, ok := object["else"]; ok {
+		converted, err := e.convert(otherwise, location+"/else", structure)
+		if err != nil {
+			return nil, err
+		}
+		whenFalse = append(whenFalse, converted)
+	}
+
+	return map[string]interface{}{
+		"anyOf": []interface{}{
+			map[string]interface{}{"allOf": whenTrue},
+			map[string]interface{}{"allOf": whenFalse},
+		},
+	}, nil
+}
+
+// junctorStructure returns the structure of the logical junctors of a
+// schema, which is the schema itself unless it is inside a junctor too.
+func junctorStructure(structure map[string]interface{}, object map[string]interface{}) map[string]interface{} {
+	if structure != nil {
+		return structure
+	}
+
+	return object
+}
+
+// substructure returns the structure of a sub-schema, or nil if the schema
+// describes the structure itself.
+func substructure(structure map[string]interface{}, keyword string) map[string]interface{} {
+	if structure == nil {
+		return nil
+	}
+
+	subSchema, _ := structure[keyword].(map[string]interface{})
+	if subSchema == nil {
+		return map[string]interface{}{}
+	}
+
+	return subSchema
+}
+
+// junctorTypes returns the types that the "anyOf" or "oneOf" sub-schemas
+// of a schema accept, if each of them has one type.
+func junctorTypes(object map[string]interface{}) []string {
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		subSchemas, _ := object[keyword].([]interface{})
+		var types []string
+		for _, subSchema := range subSchemas {
+			subSchemaObject, _ := subSchema.(map[string]interface{})
+			jsonType, ok := subSchemaObject["type"].(string)
+			if !ok {
+				types = nil
+				break
+			}
+			types = append(types, jsonType)
+		}
+
+		if len(types) > 0 {
+			return types
+		}
+	}
+
+	return nil
+}
+
+// enumType returns the type of the values of an enum, or an empty string if
+// the values are not of the same type.
+func enumType(enum interface{}) string {
+	values, _ := enum.([]interface{})
+	var enumType string
+	for _, value := range values {
+		var valueType string
+		switch v := value.(type) {
+		case string:
+			valueType = "string"
+		case bool:
+			valueType = "boolean"
+		case json.Number:
+			valueType = "number"
+			if _, err := v.Int64(); err == nil {
+				valueType = "integer"
+			}
+		default:
+			return ""
+		}
+
+		if enumType != "" && enumType != valueType {
+			return ""
+		}
+		enumType = valueType
+	}
+
+	return enumType
+}
+
+func isIntOrString(types []string) bool {
+	if len(types) == 0 {
+		return false
+	}
+
+	for _, jsonType := range types {
+		if jsonType != "integer" && jsonType != "string" {
+			return false
+		}
+	}
+
+	return strutil.Contains(types, "integer") && strutil.Contains(types, "string")
+}
+
+func remove(list []string, value string) []string {
+	filtered := make([]string, 0, len(list))
+	for _, item := range list {
+		if item != value {
+			filtered = append(filtered, item)
+		}
+	}
+
+	return filtered
+}
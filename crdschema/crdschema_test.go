@@ -0,0 +1,140 @@
+package crdschema_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/crdschema"
+)
+
+const widgetSchema = `{
+	"$id": "http://example.com/crdschema/widget.json",
+	"$schema": "http://json-schema.org/draft-07/schema#",
+	"type": "object",
+	"required": ["spec"],
+	"properties": {
+		"spec": {
+			"type": "object",
+			"additionalProperties": false,
+			"properties": {
+				"replicas": {"type": "integer", "minimum": 0, "exclusiveMaximum": 100, "default": 1},
+				"port": {"oneOf": [{"type": "integer"}, {"type": "string"}]},
+				"image": {"type": ["string", "null"], "examples": ["nginx"]},
+				"mode": {"const": "fast"},
+				"tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true},
+				"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+				"config": {"type": "object"},
+				"owner": {"$ref": "#/definitions/owner"},
+				"parent": {"$ref": "#"},
+				"ports": {"type": "array", "items": [{"type": "integer"}]}
+			},
+			"anyOf": [
+				{"required": ["image"], "properties": {"image": {"type": "string", "minLength": 1}}},
+				{"required": ["unknown"], "properties": {"unknown": {}}}
+			]
+		},
+		"status": {"x-kubernetes-preserve-unknown-fields": true}
+	},
+	"definitions": {
+		"owner": {"properties": {"name": {"type": "string", "format": "hostname"}}}
+	}
+}`
+
+const widgetStructuralSchema = `{
+	"type": "object",
+	"required": ["spec"],
+	"properties": {
+		"spec": {
+			"type": "object",
+			"properties": {
+				"replicas": {"type": "integer", "minimum": 0, "maximum": 100, "exclusiveMaximum": true, "default": 1},
+				"port": {"x-kubernetes-int-or-string": true, "oneOf": [{}, {}]},
+				"image": {"type": "string", "nullable": true, "example": "nginx"},
+				"mode": {"type": "string", "enum": ["fast"]},
+				"tags": {"type": "array", "items": {"type": "string"}, "x-kubernetes-list-type": "set"},
+				"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+				"config": {"type": "object", "x-kubernetes-preserve-unknown-fields": true},
+				"owner": {"type": "object", "properties": {"name": {"type": "string", "format": "hostname"}}},
+				"parent": {"type": "object", "x-kubernetes-preserve-unknown-fields": true},
+				"ports": {"type": "array", "items": {"x-kubernetes-preserve-unknown-fields": true}}
+			},
+			"anyOf": [
+				{"required": ["image"], "properties": {"image": {"minLength": 1}}},
+				{"required": ["unknown"], "properties": {}}
+			]
+		},
+		"status": {"x-kubernetes-preserve-unknown-fields": true}
+	}
+}`
+
+func TestExport(t *testing.T) {
+	rootSchema, err := jsonvalidator.NewRootJsonSchema([]byte(widgetSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	structuralSchema, changes, err := crdschema.Export(rootSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var actual, expected interface{}
+	if err := json.Unmarshal(structuralSchema, &actual); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(widgetStructuralSchema), &expected); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected structural schema %s", structuralSchema)
+	}
+
+	expectedChanges := []struct {
+		location string
+		kind     crdschema.ChangeKind
+	}{
+		{"/$id", crdschema.Dropped},
+		{"/$schema", crdschema.Dropped},
+		{"/definitions", crdschema.Dropped},
+		{"/definitions/owner/type", crdschema.Transformed},
+		{"/properties/spec/additionalProperties", crdschema.Dropped},
+		{"/properties/spec/anyOf/0/properties/image/type", crdschema.Dropped},
+		{"/properties/spec/anyOf/1/properties/unknown", crdschema.Dropped},
+		{"/properties/spec/properties/config/type", crdschema.Transformed},
+		{"/properties/spec/properties/image/examples", crdschema.Transformed},
+		{"/properties/spec/properties/image/type", crdschema.Transformed},
+		{"/properties/spec/properties/mode/const", crdschema.Transformed},
+		{"/properties/spec/properties/mode/type", crdschema.Transformed},
+		{"/properties/spec/properties/parent/$ref", crdschema.Transformed},
+		{"/properties/spec/properties/port/oneOf/0/type", crdschema.Dropped},
+		{"/properties/spec/properties/port/oneOf/1/type", crdschema.Dropped},
+		{"/properties/spec/properties/port/type", crdschema.Transformed},
+		{"/properties/spec/properties/ports/items", crdschema.Transformed},
+		{"/properties/spec/properties/replicas/exclusiveMaximum", crdschema.Transformed},
+		{"/properties/spec/properties/tags/uniqueItems", crdschema.Transformed},
+	}
+
+	if len(changes) != len(expectedChanges) {
+		t.Fatalf("expected %d changes, got %v", len(expectedChanges), changes)
+	}
+	for index, change := range changes {
+		expected := expectedChanges[index]
+		if change.Location != expected.location || change.Kind != expected.kind {
+			t.Errorf("expected the change %s (%v), got %s (%v): %s", expected.location, expected.kind, change.Location, change.Kind, change.Reason)
+		}
+	}
+}
+
+func TestExportNotAnObject(t *testing.T) {
+	rootSchema, err := jsonvalidator.NewRootJsonSchema([]byte(`{"$id": "http://example.com/crdschema/string.json", "type": "string"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = crdschema.Export(rootSchema)
+	if err != crdschema.ErrNotAnObject {
+		t.Errorf("expected ErrNotAnObject, got %v", err)
+	}
+}
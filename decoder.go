@@ -0,0 +1,51 @@
+package jsonvalidator
+
+import (
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+// InstanceDecoder decodes the bytes of a validated document into the data
+// model of encoding/json: nil, bool, float64 (or json.Number), string,
+// []interface{} and map[string]interface{}. It lets a Validator use an
+// alternative json parser, or validate documents of another wire format.
+// If useNumber is true, numbers must be decoded as json.Number, since the
+// strict numeric mode needs their exact representation, and otherwise they
+// must be decoded as float64.
+type InstanceDecoder interface {
+	Decode(bytes []byte, useNumber bool) (interface{}, error)
+}
+
+// InstanceDecoderFunc is an adapter that allows the use of an ordinary
+// function as an InstanceDecoder.
+type InstanceDecoderFunc func(bytes []byte, useNumber bool) (interface{}, error)
+
+// Decode calls f(bytes, useNumber).
+func (f InstanceDecoderFunc) Decode(bytes []byte, useNumber bool) (interface{}, error) {
+	return f(bytes, useNumber)
+}
+
+// StandardDecoder is the InstanceDecoder that validators use by default,
+// which decodes json documents with encoding/json. Documents that start
+// with a byte order mark, or that are encoded in UTF-16 or UTF-32, are
+// transcoded to UTF-8 first.
+var StandardDecoder InstanceDecoder = InstanceDecoderFunc(func(bytes []byte, useNumber bool) (interface{}, error) {
+	bytes, err := toUTF8(bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if useNumber {
+		return jsonwalker.JsonPointer{}.EvaluateUseNumber(bytes)
+	}
+
+	return jsonwalker.JsonPointer{}.Evaluate(bytes)
+})
+
+// instanceDecoder returns the InstanceDecoder of the validator.
+func (v *Validator) instanceDecoder() InstanceDecoder {
+	if v.decoder == nil {
+		return StandardDecoder
+	}
+
+	return v.decoder
+}
@@ -0,0 +1,86 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// decodePairs decodes documents of "key=value" lines into json objects.
+func decodePairs(bytes []byte, useNumber bool) (interface{}, error) {
+	object := map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSpace(string(bytes)), "\n") {
+		pair := strings.SplitN(line, "=", 2)
+		if len(pair) != 2 {
+			return nil, errors.New("invalid line " + line)
+		}
+		object[pair[0]] = pair[1]
+	}
+
+	return object, nil
+}
+
+func TestInstanceDecoder(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"required": ["name"],
+		"properties": {"name": {"minLength": 2}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := NewValidator(rootSchema).Decoder(InstanceDecoderFunc(decodePairs))
+	testCases := []struct {
+		description string
+		data        string
+		valid       bool
+	}{
+		{"a valid document", "name=john\nage=30", true},
+		{"an invalid document", "name=j", false},
+		{"a document without a required property", "age=30", false},
+		{"a document that cannot be decoded", "name", false},
+	}
+
+	for _, testCase := range testCases {
+		err := validator.Validate([]byte(testCase.data))
+		if (err == nil) != testCase.valid {
+			t.Errorf("%s: expected valid=%t, got %v", testCase.description, testCase.valid, err)
+		}
+	}
+
+	err = validator.Decoder(nil).Validate([]byte(`{"name": "john"}`))
+	if err != nil {
+		t.Errorf("expected the standard decoder to be restored, got %v", err)
+	}
+}
+
+func TestInstanceDecoderUseNumber(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{"type": "integer"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var useNumbers []bool
+	decoder := InstanceDecoderFunc(func(bytes []byte, useNumber bool) (interface{}, error) {
+		useNumbers = append(useNumbers, useNumber)
+		return StandardDecoder.Decode(bytes, useNumber)
+	})
+
+	validator := NewValidator(rootSchema).Decoder(decoder)
+	if err := validator.Validate([]byte(`1`)); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := validator.StrictNumbers(true).Validate([]byte(`9007199254740993`)); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if len(useNumbers) != 2 || useNumbers[0] || !useNumbers[1] {
+		t.Errorf("expected the decoder to be called without and with useNumber, got %v", useNumbers)
+	}
+
+	value, err := StandardDecoder.Decode([]byte(`1.5`), true)
+	if _, ok := value.(json.Number); !ok || err != nil {
+		t.Errorf("expected a json.Number, got %#v (%v)", value, err)
+	}
+}
@@ -0,0 +1,212 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+// ApplyDefaults returns a copy of the json document in bytes in which the
+// default values of the schema are injected, like ValidateDefaults() of a
+// Validator of the schema injects them: every missing object property,
+// whose schema in "properties" has a "default" value, is set to that
+// default value, in the sub-schemas that apply to the document (including
+// "allOf" and the schemas that references point to). A document that is
+// not valid is returned with the defaults that were injected before its
+// first failure.
+func (rs *RootJsonSchema) ApplyDefaults(bytes []byte) ([]byte, error) {
+	augmented, err := NewValidator(rs).ValidateDefaults(bytes)
+	if _, ok := err.(SchemaValidationError); ok {
+		return augmented, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return augmented, nil
+}
+
+// UnmarshalWithDefaults validates the json document in data against the
+// schema with the defaults of the schema injected (see
+// Validator.ValidateDefaults()), and unmarshals the result into target,
+// like json.Unmarshal(). Optional fields that are missing from the document
+// get the default values of the schema, so they are not duplicated in Go
+// code. target is not changed if the validation fails.
+func UnmarshalWithDefaults(schema *RootJsonSchema, data []byte, target interface{}) error {
+	data, err := NewValidator(schema).ValidateDefaults(data)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, target)
+}
+
+// injectedDefault is a default value that was injected into the validated
+// document at the escaped json pointer path.
+type injectedDefault struct {
+	path  string
+	value json.RawMessage
+}
+
+// injectDefaults returns the value at jsonPath with the missing properties
+// of the schema's "properties", and the missing items at the end of the
+// schema's tuple, set to their "default" values. The value itself is not
+// changed, so only the keywords of the schema (and their sub-schemas) see
+// the defaults. The injected values are recorded for ValidateDefaults().
+func (js *JsonSchema) injectDefaults(jsonPath string, jsonData jsonData, ctx *validationContext) (jsonData, error) {
+	switch v := jsonData.value.(type) {
+	case map[string]interface{}:
+		var object map[string]interface{}
+		for _, key := range js.Properties.defaultKeys(v) {
+			value, err := ctx.decodeJSON(js.Properties[key].Default)
+			if err != nil {
+				return jsonData, err
+			}
+
+			if object == nil {
+				object = make(map[string]interface{}, len(v)+1)
+				for property, propertyValue := range v {
+					object[property] = propertyValue
+				}
+			}
+			object[key] = value
+			ctx.defaults = append(ctx.defaults, injectedDefault{jsonPath + "/" + jsonwalker.EscapeToken(key), json.RawMessage(js.Properties[key].Default)})
+		}
+
+		if object != nil {
+			return newJsonData(object), nil
+		}
+	case []interface{}:
+		if js.tuple == nil || js.tuple.schema != nil {
+			return jsonData, nil
+		}
+
+		// Only the items right after the end of the array are filled in,
+		// since an item cannot be missing in the middle of an array.
+		array := v
+		for index := len(v); index < len(js.tuple.schemas) && js.tuple.schemas[index].Default != nil; index++ {
+			value, err := ctx.decodeJSON(js.tuple.schemas[index].Default)
+			if err != nil {
+				return jsonData, err
+			}
+
+			if len(array) == len(v) {
+				array = append([]interface{}(nil), v...)
+			}
+			array = append(array, value)
+			ctx.defaults = append(ctx.defaults, injectedDefault{jsonPath + "/" + strconv.Itoa(index), json.RawMessage(js.tuple.schemas[index].Default)})
+		}
+
+		if len(array) > len(v) {
+			return newJsonData(array), nil
+		}
+	}
+
+	return jsonData, nil
+}
+
+// defaultKeys returns the sorted names of the properties that have a
+// "default" value and are missing from the object.
+func (p properties) defaultKeys(object map[string]interface{}) []string {
+	var keys []string
+	for key, schema := range p {
+		if _, ok := object[key]; !ok && schema != nil && schema.Default != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// decodeJSON decodes a json value that is not part of the validated
+// document, like a "default" value of the schema, as the validated documents
+// are decoded, so it is validated like a value of the document.
+func (ctx *validationContext) decodeJSON(bytes []byte) (interface{}, error) {
+	value, err := StandardDecoder.Decode(bytes, ctx.validator.strictNumbers)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.validator.strictNumbers {
+		value = canonicalizeNumbers(value)
+	}
+
+	return value, nil
+}
+
+// ValidateDefaults validates a json document like Validate() with the
+// injection of default values enabled (see Validator.InjectDefaults()), and
+// returns the document with the injected defaults, even if the document is
+// invalid. If transforms are enabled too, the transformed string values are
+// returned as well.
+func (v *Validator) ValidateDefaults(bytes []byte) ([]byte, error) {
+	ctx := newValidationContext(v, len(bytes))
+	ctx.injectDefaults = true
+
+	validationErr := v.validate(bytes, ctx)
+	if len(ctx.defaults) == 0 && len(ctx.transformed) == 0 {
+		return append([]byte(nil), bytes...), validationErr
+	}
+
+	document, err := v.instanceDecoder().Decode(bytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, injected := range ctx.defaults {
+		value, err := StandardDecoder.Decode(injected.value, true)
+		if err != nil {
+			return nil, err
+		}
+
+		document = insertValue(document, strings.Split(injected.path, "/")[1:], value)
+	}
+
+	for path, value := range ctx.transformed {
+		document = setValue(document, strings.Split(path, "/")[1:], value)
+	}
+
+	augmented, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+
+	return augmented, validationErr
+}
+
+// insertValue inserts the value at the escaped json pointer tokens of a
+// decoded json document, unless a value is already there, and returns the
+// document. An array item is inserted only right after the end of the
+// array.
+func insertValue(document interface{}, tokens []string, value interface{}) interface{} {
+	if len(tokens) == 0 {
+		return document
+	}
+
+	token := jsonwalker.UnescapeToken(tokens[0])
+	switch v := document.(type) {
+	case map[string]interface{}:
+		child, ok := v[token]
+		if len(tokens) == 1 && !ok {
+			v[token] = value
+		} else if ok {
+			v[token] = insertValue(child, tokens[1:], value)
+		}
+	case []interface{}:
+		index, err := strconv.Atoi(token)
+		if err != nil || index < 0 || index > len(v) {
+			return document
+		}
+
+		if len(tokens) == 1 && index == len(v) {
+			return append(v, value)
+		} else if index < len(v) {
+			v[index] = insertValue(v[index], tokens[1:], value)
+		}
+	}
+
+	return document
+}
@@ -0,0 +1,158 @@
+package jsonvalidator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalWithDefaults(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"properties": {
+			"host": {"type": "string"},
+			"port": {"type": "integer", "default": 8080},
+			"tls": {
+				"properties": {"enabled": {"type": "boolean", "default": true}},
+				"default": {}
+			}
+		},
+		"required": ["host"]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type config struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+		TLS  struct {
+			Enabled bool `json:"enabled"`
+		} `json:"tls"`
+	}
+
+	var actual config
+	err = UnmarshalWithDefaults(rootSchema, []byte(`{"host": "example.com"}`), &actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := config{Host: "example.com", Port: 8080}
+	expected.TLS.Enabled = true
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+
+	untouched := config{Port: 1}
+	err = UnmarshalWithDefaults(rootSchema, []byte(`{"port": 443}`), &untouched)
+	if _, ok := err.(SchemaValidationError); !ok {
+		t.Errorf("expected a SchemaValidationError, got %v", err)
+	}
+	if untouched.Port != 1 {
+		t.Errorf("expected the target not to change, got %+v", untouched)
+	}
+}
+
+func TestValidatorInjectDefaults(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/defaults/server.json",
+		"properties": {
+			"host": {"type": "string"},
+			"port": {"type": "integer", "minimum": 1, "default": 8080},
+			"tls": {
+				"required": ["enabled"],
+				"properties": {"enabled": {"type": "boolean", "default": true}},
+				"default": {}
+			},
+			"range": {
+				"items": [{"type": "integer", "default": 0}, {"type": "integer", "default": 100}]
+			}
+		},
+		"required": ["host", "port"],
+		"anyOf": [
+			{"properties": {"mode": {"default": "strict"}}, "required": ["strict"]},
+			{"properties": {"retries": {"default": 3}}}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		document string
+		expected string
+		valid    bool
+	}{
+		{
+			`{"host": "example.com"}`,
+			`{"host":"example.com","port":8080,"retries":3,"tls":{"enabled":true}}`,
+			true,
+		},
+		{
+			`{"host": "example.com", "port": 443, "tls": {"enabled": false}, "range": [5]}`,
+			`{"host":"example.com","port":443,"range":[5,100],"retries":3,"tls":{"enabled":false}}`,
+			true,
+		},
+		{`{"port": 0}`, "", false},
+	}
+
+	validator := NewValidator(rootSchema)
+	for _, testCase := range testCases {
+		augmented, err := validator.ValidateDefaults([]byte(testCase.document))
+		if valid := err == nil; valid != testCase.valid {
+			t.Errorf("ValidateDefaults(%s) returned %v, expected valid: %v", testCase.document, err, testCase.valid)
+		}
+		// The validation of an invalid document stops at its first
+		// failure, so only some of the defaults may have been injected.
+		if testCase.valid && string(augmented) != testCase.expected {
+			t.Errorf("ValidateDefaults(%s) returned %s, expected %s", testCase.document, augmented, testCase.expected)
+		}
+	}
+
+	// The missing required property is filled in only if defaults are
+	// applied.
+	document := []byte(`{"host": "example.com"}`)
+	if err := validator.Validate(document); err == nil {
+		t.Error("the missing required property was accepted without defaults")
+	}
+	if err := validator.InjectDefaults(true).Validate(document); err != nil {
+		t.Errorf("the default of the required property was not applied: %v", err)
+	}
+}
+
+func TestApplyDefaults(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/defaults/all-of.json",
+		"allOf": [{"properties": {"port": {"type": "integer", "default": 8080}}}],
+		"properties": {"tls": {"$ref": "#/definitions/tls", "default": {}}},
+		"definitions": {"tls": {"properties": {"enabled": {"default": true}}}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The static API injects the same defaults as a Validator.
+	expected := `{"port":8080,"tls":{"enabled":true}}`
+	augmented, err := NewValidator(rootSchema).ValidateDefaults([]byte(`{}`))
+	if err != nil || string(augmented) != expected {
+		t.Errorf("ValidateDefaults() returned %s, %v, expected %s", augmented, err, expected)
+	}
+	augmented, err = rootSchema.ApplyDefaults([]byte(`{}`))
+	if err != nil || string(augmented) != expected {
+		t.Errorf("ApplyDefaults() returned %s, %v, expected %s", augmented, err, expected)
+	}
+
+	var config struct {
+		Port int `json:"port"`
+		TLS  struct {
+			Enabled bool `json:"enabled"`
+		} `json:"tls"`
+	}
+	if err := UnmarshalWithDefaults(rootSchema, []byte(`{}`), &config); err != nil || config.Port != 8080 || !config.TLS.Enabled {
+		t.Errorf("UnmarshalWithDefaults() returned %+v, %v", config, err)
+	}
+
+	// The defaults of an invalid document are returned without an error.
+	augmented, err = rootSchema.ApplyDefaults([]byte(`{"port": "80"}`))
+	if err != nil || string(augmented) != `{"port":"80","tls":{"enabled":true}}` {
+		t.Errorf("ApplyDefaults() of an invalid document returned %s, %v", augmented, err)
+	}
+}
@@ -0,0 +1,294 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+// NoMatchingVersionError is returned by a Dispatcher when no schema is
+// registered for the version of a document, and there is no default
+// schema. It holds the version, which is empty if the document has none.
+type NoMatchingVersionError string
+
+func (e NoMatchingVersionError) Error() string {
+	if e == "" {
+		return "no schema is registered for documents without a version"
+	}
+
+	return fmt.Sprintf("no schema is registered for version %q", string(e))
+}
+
+// Dispatcher validates documents against the schema of their version,
+// which is read from a field of the documents themselves, like "apiVersion"
+// or "$schemaVersion", so a single endpoint can accept several versions of
+// a payload.
+// Schemas are registered with version constraints, which are either a
+// version that must be equal to the version of the document, or a list of
+// comparisons that are separated by spaces, like ">=1.2 <2". Versions are
+// compared by their dot-separated numeric components, after an optional "v"
+// prefix, so "v1.10" is greater than "1.9", and versions that are not
+// numeric, like "v1beta1", only match equal versions. A document is
+// validated against the schema of the first constraint that its version
+// matches, or against the default schema if none matches.
+// A Dispatcher is safe for concurrent use.
+type Dispatcher struct {
+	field jsonwalker.JsonPointer
+
+	mutex         sync.RWMutex
+	routes        []dispatchRoute
+	defaultSchema *RootJsonSchema
+}
+
+// dispatchRoute is a schema and the version constraint that selects it.
+type dispatchRoute struct {
+	constraint []versionComparison
+	schema     *RootJsonSchema
+}
+
+// versionComparison is a comparison of a version with the version of a
+// document, like ">=1.2".
+type versionComparison struct {
+	operator string
+	version  string
+}
+
+// The operators of version comparisons, where the longer operators come
+// first so they are not parsed as their prefixes.
+var versionOperators = []string{">=", "<=", "!=", ">", "<", "="}
+
+// NewDispatcher creates a Dispatcher that reads the versions of documents
+// from the value at the given json pointer, like "/apiVersion". The value
+// may be a string or a number.
+func NewDispatcher(field string) (*Dispatcher, error) {
+	pointer, err := jsonwalker.NewJsonPointer(field)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Dispatcher{field: pointer}, nil
+}
+
+// Register registers the schema of the versions that match a constraint.
+// The constraints are matched in the order they were registered.
+func (d *Dispatcher) Register(constraint string, schema *RootJsonSchema) error {
+	comparisons, err := parseVersionConstraint(constraint)
+	if err != nil {
+		return err
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.routes = append(d.routes, dispatchRoute{comparisons, schema})
+	return nil
+}
+
+// Default sets the schema of the documents whose versions do not match any
+// of the constraints, or that have no version. A nil schema removes the
+// default schema, and then such documents are rejected with a
+// NoMatchingVersionError.
+func (d *Dispatcher) Default(schema *RootJsonSchema) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.defaultSchema = schema
+}
+
+// Select returns the schema of the json document in bytes, and the version
+// of the document.
+func (d *Dispatcher) Select(bytes []byte) (*RootJsonSchema, string, error) {
+	version, found, err := d.version(bytes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	if found {
+		for _, route := range d.routes {
+			if matchesVersion(route.constraint, version) {
+				return route.schema, version, nil
+			}
+		}
+	}
+
+	if d.defaultSchema == nil {
+		return nil, version, NoMatchingVersionError(version)
+	}
+
+	return d.defaultSchema, version, nil
+}
+
+// Validate validates the json document in bytes against the schema of its
+// version.
+func (d *Dispatcher) Validate(bytes []byte) error {
+	schema, _, err := d.Select(bytes)
+	if err != nil {
+		return err
+	}
+
+	return NewValidator(schema).Validate(bytes)
+}
+
+// version returns the version of the document. found is false if the
+// document has no value at the field of the dispatcher.
+func (d *Dispatcher) version(bytes []byte) (version string, found bool, err error) {
+	value, err := jsonwalker.JsonPointer{}.EvaluateUseNumber(bytes)
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, token := range d.field {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false, nil
+		}
+
+		value, ok = object[token]
+		if !ok {
+			return "", false, nil
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, true, nil
+	case json.Number:
+		return v.String(), true, nil
+	default:
+		return "", false, errors.New("the version at " + d.field.String() + " must be a string or a number")
+	}
+}
+
+// parseVersionConstraint parses a version constraint into its comparisons.
+func parseVersionConstraint(constraint string) ([]versionComparison, error) {
+	fields := strings.Fields(constraint)
+	if len(fields) == 0 {
+		return nil, errors.New("empty version constraint")
+	}
+
+	comparisons := make([]versionComparison, len(fields))
+	for index, field := range fields {
+		comparison := versionComparison{"=", field}
+		for _, operator := range versionOperators {
+			if strings.HasPrefix(field, operator) {
+				comparison = versionComparison{operator, field[len(operator):]}
+				break
+			}
+		}
+
+		if comparison.version == "" {
+			return nil, errors.New("invalid version constraint " + strconv.Quote(constraint))
+		}
+		if comparison.operator != "=" && comparison.operator != "!=" {
+			if _, ok := parseVersion(comparison.version); !ok {
+				return nil, errors.New("the version " + strconv.Quote(comparison.version) + " of an ordered comparison must be numeric")
+			}
+		}
+		comparisons[index] = comparison
+	}
+
+	return comparisons, nil
+}
+
+// matchesVersion returns true if a version satisfies all the comparisons of
+// a constraint.
+func matchesVersion(comparisons []versionComparison, version string) bool {
+	for _, comparison := range comparisons {
+		order, ok := compareVersions(version, comparison.version)
+		if !ok {
+			// Versions that are not numeric are only equal or not equal.
+			switch comparison.operator {
+			case "=":
+				ok = version == comparison.version
+			case "!=":
+				ok = version != comparison.version
+			}
+			if !ok {
+				return false
+			}
+			continue
+		}
+
+		switch comparison.operator {
+		case "=":
+			ok = order == 0
+		case "!=":
+			ok = order != 0
+		case ">":
+			ok = order > 0
+		case ">=":
+			ok = order >= 0
+		case "<":
+			ok = order < 0
+		case "<=":
+			ok = order <= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compareVersions compares two numeric versions, and returns -1, 0 or 1 if
+// a is less than, equal to or greater than b. ok is false if any of the
+// versions is not numeric.
+func compareVersions(a string, b string) (order int, ok bool) {
+	x, ok := parseVersion(a)
+	if !ok {
+		return 0, false
+	}
+	y, ok := parseVersion(b)
+	if !ok {
+		return 0, false
+	}
+
+	for index := 0; index < len(x) || index < len(y); index++ {
+		var xComponent, yComponent int
+		if index < len(x) {
+			xComponent = x[index]
+		}
+		if index < len(y) {
+			yComponent = y[index]
+		}
+
+		if xComponent != yComponent {
+			if xComponent < yComponent {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+
+	return 0, true
+}
+
+// parseVersion returns the numeric components of a version with an optional
+// "v" prefix, like "v1.2".
+func parseVersion(version string) ([]int, bool) {
+	version = strings.TrimPrefix(strings.TrimPrefix(version, "v"), "V")
+	if version == "" {
+		return nil, false
+	}
+
+	parts := strings.Split(version, ".")
+	components := make([]int, len(parts))
+	for index, part := range parts {
+		component, err := strconv.Atoi(part)
+		if err != nil || component < 0 || part[0] == '+' {
+			return nil, false
+		}
+		components[index] = component
+	}
+
+	return components, true
+}
@@ -0,0 +1,108 @@
+package jsonvalidator
+
+import "testing"
+
+func TestDispatcher(t *testing.T) {
+	newSchema := func(schema string) *RootJsonSchema {
+		rootSchema, err := NewRootJsonSchema([]byte(schema))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rootSchema
+	}
+
+	v1 := newSchema(`{"required": ["name"]}`)
+	v2 := newSchema(`{"required": ["firstName", "lastName"]}`)
+	beta := newSchema(`{"required": ["displayName"]}`)
+	fallback := newSchema(`{"required": ["legacyName"]}`)
+
+	dispatcher, err := NewDispatcher("/apiVersion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for constraint, schema := range map[string]*RootJsonSchema{">=1 <2": v1, ">=v2.0 <3": v2, "v2beta1": beta} {
+		if err := dispatcher.Register(constraint, schema); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		document string
+		schema   *RootJsonSchema
+		version  string
+	}{
+		{`{"apiVersion": "1.4", "name": "a"}`, v1, "1.4"},
+		{`{"apiVersion": "v1", "name": "a"}`, v1, "v1"},
+		{`{"apiVersion": 1.9, "name": "a"}`, v1, "1.9"},
+		{`{"apiVersion": "2.10"}`, v2, "2.10"},
+		{`{"apiVersion": "v2beta1"}`, beta, "v2beta1"},
+		{`{"apiVersion": "3"}`, nil, "3"},
+		{`{}`, nil, ""},
+	}
+
+	for _, testCase := range testCases {
+		schema, version, err := dispatcher.Select([]byte(testCase.document))
+		if testCase.schema == nil {
+			if err != NoMatchingVersionError(testCase.version) {
+				t.Errorf("%s: expected a NoMatchingVersionError, got %v", testCase.document, err)
+			}
+			continue
+		}
+		if err != nil || schema != testCase.schema || version != testCase.version {
+			t.Errorf("%s: expected the schema of version %s, got %s (%v)", testCase.document, testCase.version, version, err)
+		}
+	}
+
+	dispatcher.Default(fallback)
+	if schema, _, err := dispatcher.Select([]byte(`{"apiVersion": "3"}`)); schema != fallback || err != nil {
+		t.Errorf("expected unmatched versions to use the default schema, got %v", err)
+	}
+	if err := dispatcher.Validate([]byte(`{"legacyName": "a"}`)); err != nil {
+		t.Errorf("expected documents without a version to use the default schema, got %v", err)
+	}
+	if err := dispatcher.Validate([]byte(`{"apiVersion": "2", "firstName": "a"}`)); err == nil {
+		t.Errorf("expected the document to be invalid against v2")
+	}
+	if err := dispatcher.Validate([]byte(`{"apiVersion": true}`)); err == nil {
+		t.Errorf("expected an error for a version that is not a string or a number")
+	}
+}
+
+func TestDispatcherConstraints(t *testing.T) {
+	dispatcher, err := NewDispatcher("/version")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, constraint := range []string{"", ">=", ">=beta", "<v1.x"} {
+		if err := dispatcher.Register(constraint, nil); err == nil {
+			t.Errorf("%q: expected an invalid constraint", constraint)
+		}
+	}
+
+	testCases := []struct {
+		constraint string
+		version    string
+		matches    bool
+	}{
+		{"1.2", "1.2.0", true},
+		{"1.2", "v1.2", true},
+		{"!=1.2", "1.3", true},
+		{">1.9", "1.10", true},
+		{"<=1", "1.0.1", false},
+		{">=1 <2", "2", false},
+		{"=v1beta1", "v1beta1", true},
+		{"v1beta1", "v1beta2", false},
+		{">=1", "v1beta1", false},
+	}
+
+	for _, testCase := range testCases {
+		comparisons, err := parseVersionConstraint(testCase.constraint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if matchesVersion(comparisons, testCase.version) != testCase.matches {
+			t.Errorf("%s %s: expected matches=%t", testCase.constraint, testCase.version, testCase.matches)
+		}
+	}
+}
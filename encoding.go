@@ -0,0 +1,58 @@
+package jsonvalidator
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+)
+
+// Buffers that grew beyond this capacity are not returned to the pool, so a
+// single huge document does not keep its memory alive.
+const maxPooledBufferCapacity = 64 * 1024
+
+// jsonEncoder is a reusable json encoder that writes into its own buffer.
+// jsonEncoders are pooled, since validation marshals the json values that
+// are compared with "enum" and "const" values.
+type jsonEncoder struct {
+	buffer  bytes.Buffer
+	encoder *json.Encoder
+}
+
+var encoderPool = sync.Pool{
+	New: func() interface{} {
+		e := &jsonEncoder{}
+		e.encoder = json.NewEncoder(&e.buffer)
+		return e
+	},
+}
+
+// getEncoder returns a jsonEncoder from the pool. The encoder must be
+// returned to the pool by calling release() when it is no longer used.
+func getEncoder() *jsonEncoder {
+	return encoderPool.Get().(*jsonEncoder)
+}
+
+// release returns the encoder to the pool. The bytes returned by encode()
+// must not be used after the encoder was released.
+func (e *jsonEncoder) release() {
+	if e.buffer.Cap() > maxPooledBufferCapacity {
+		return
+	}
+
+	e.buffer.Reset()
+	encoderPool.Put(e)
+}
+
+// encode returns the json encoding of value, the same as json.Marshal.
+// The returned bytes are only valid until the next call to encode() or
+// release().
+func (e *jsonEncoder) encode(value interface{}) ([]byte, error) {
+	e.buffer.Reset()
+	err := e.encoder.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unlike json.Marshal, json.Encoder terminates every value with a newline.
+	return bytes.TrimSuffix(e.buffer.Bytes(), []byte("\n")), nil
+}
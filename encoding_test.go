@@ -0,0 +1,35 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJsonEncoderMatchesMarshal(t *testing.T) {
+	values := []interface{}{
+		nil,
+		"<a & b>",
+		1.5,
+		[]interface{}{true, "x"},
+		map[string]interface{}{"b": 1.0, "a": []interface{}{}},
+	}
+
+	encoder := getEncoder()
+	defer encoder.release()
+
+	for _, value := range values {
+		expected, err := json.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encoded, err := encoder.encode(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(encoded) != string(expected) {
+			t.Errorf("expected %s, got %s", expected, encoded)
+		}
+	}
+}
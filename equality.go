@@ -0,0 +1,219 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+)
+
+// FNV-1a parameters, used to hash decoded json values.
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// Distinct seeds for the json types, so values of different types that have
+// the same representation (such as "1" and 1) hash differently.
+const (
+	hashSeedNull = iota + 1
+	hashSeedBoolean
+	hashSeedNumber
+	hashSeedString
+	hashSeedArray
+	hashSeedObject
+)
+
+// hashValue returns a hash of a decoded json value that is consistent with
+// equalValues(): equal values have equal hashes. The hash of an object does
+// not depend on the order of its properties.
+func hashValue(value interface{}) uint64 {
+	switch v := value.(type) {
+	case nil:
+		{
+			return hashUint64(fnvOffset64, hashSeedNull)
+		}
+	case bool:
+		{
+			if v {
+				return hashUint64(hashUint64(fnvOffset64, hashSeedBoolean), 1)
+			}
+
+			return hashUint64(fnvOffset64, hashSeedBoolean)
+		}
+	case float64:
+		{
+			// 0 and -0 are equal.
+			if v == 0 {
+				v = 0
+			}
+
+			return hashUint64(hashUint64(fnvOffset64, hashSeedNumber), math.Float64bits(v))
+		}
+	case json.Number:
+		{
+			// Numbers that were kept as json.Number by canonicalizeNumbers()
+			// may still have several representations, so they are hashed in
+			// a canonical form.
+			h := hashUint64(fnvOffset64, hashSeedNumber)
+			if f, _, err := big.ParseFloat(string(v), 10, 1024, big.ToNearestEven); err == nil {
+				return hashString(h, f.Text('p', 0))
+			}
+
+			return hashString(h, string(v))
+		}
+	case string:
+		{
+			return hashString(hashUint64(fnvOffset64, hashSeedString), v)
+		}
+	case []interface{}:
+		{
+			h := hashUint64(fnvOffset64, hashSeedArray)
+			for _, item := range v {
+				h = hashUint64(h, hashValue(item))
+			}
+
+			return h
+		}
+	case map[string]interface{}:
+		{
+			// The hashes of the properties are summed, which does not depend
+			// on the iteration order of the map.
+			var sum uint64
+			for key, item := range v {
+				sum += hashUint64(hashString(fnvOffset64, key), hashValue(item))
+			}
+
+			return hashUint64(hashUint64(fnvOffset64, hashSeedObject), sum)
+		}
+	default:
+		{
+			return fnvOffset64
+		}
+	}
+}
+
+func hashUint64(h uint64, value uint64) uint64 {
+	for index := 0; index < 8; index++ {
+		h ^= value & 0xff
+		h *= fnvPrime64
+		value >>= 8
+	}
+
+	return h
+}
+
+func hashString(h uint64, value string) uint64 {
+	for index := 0; index < len(value); index++ {
+		h ^= uint64(value[index])
+		h *= fnvPrime64
+	}
+
+	// Terminate the string, so consecutive strings are not ambiguous.
+	return hashUint64(h, uint64(len(value)))
+}
+
+// equalValues returns true if two decoded json values are equal according to
+// the json schema specification: numbers are equal if they are
+// mathematically equal, and objects are equal regardless of the order of
+// their properties.
+func equalValues(a interface{}, b interface{}) bool {
+	switch x := a.(type) {
+	case nil:
+		{
+			return b == nil
+		}
+	case bool:
+		{
+			y, ok := b.(bool)
+			return ok && x == y
+		}
+	case float64, json.Number:
+		{
+			return equalNumbers(a, b)
+		}
+	case string:
+		{
+			y, ok := b.(string)
+			return ok && x == y
+		}
+	case []interface{}:
+		{
+			y, ok := b.([]interface{})
+			if !ok || len(x) != len(y) {
+				return false
+			}
+
+			for index := range x {
+				if !equalValues(x[index], y[index]) {
+					return false
+				}
+			}
+
+			return true
+		}
+	case map[string]interface{}:
+		{
+			y, ok := b.(map[string]interface{})
+			if !ok || len(x) != len(y) {
+				return false
+			}
+
+			for key, item := range x {
+				other, ok := y[key]
+				if !ok || !equalValues(item, other) {
+					return false
+				}
+			}
+
+			return true
+		}
+	default:
+		{
+			return false
+		}
+	}
+}
+
+// equalNumbers returns true if a and b are mathematically equal numbers.
+func equalNumbers(a interface{}, b interface{}) bool {
+	x, ok := a.(float64)
+	y, ok2 := b.(float64)
+	if ok && ok2 {
+		return x == y
+	}
+
+	bigX, ok := bigNumber(a)
+	if !ok {
+		return false
+	}
+
+	bigY, ok := bigNumber(b)
+	if !ok {
+		return false
+	}
+
+	return bigX.Cmp(bigY) == 0
+}
+
+// bigNumber returns the exact value of a decoded json number.
+func bigNumber(value interface{}) (*big.Float, bool) {
+	switch v := value.(type) {
+	case float64:
+		{
+			if math.IsInf(v, 0) || math.IsNaN(v) {
+				return nil, false
+			}
+
+			return big.NewFloat(v), true
+		}
+	case json.Number:
+		{
+			f, _, err := big.ParseFloat(string(v), 10, 1024, big.ToNearestEven)
+			return f, err == nil
+		}
+	default:
+		{
+			return nil, false
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUniqueItems(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{"uniqueItems": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		description string
+		data        string
+		valid       bool
+	}{
+		{"unique numbers", `[1, 2, 3]`, true},
+		{"equal numbers with different representations", `[1, 1.0]`, false},
+		{"objects with properties in a different order", `[{"a": 1, "b": 2}, {"b": 2, "a": 1}]`, false},
+		{"objects with different values", `[{"a": 1, "b": 2}, {"a": 2, "b": 1}]`, true},
+		{"nested arrays", `[[1, [2]], [1, [2.0]]]`, false},
+		{"values of different types", `[1, "1", true, null, [1], {"1": 1}]`, true},
+		{"false and zero", `[false, 0]`, true},
+	}
+
+	validator := NewValidator(rootSchema)
+	for _, testCase := range testCases {
+		err := validator.Validate([]byte(testCase.data))
+		if (err == nil) != testCase.valid {
+			t.Errorf("%s: expected valid=%t, got %v", testCase.description, testCase.valid, err)
+		}
+	}
+}
+
+func TestUniqueItemsFalse(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{"uniqueItems": false}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewValidator(rootSchema).Validate([]byte(`[1, 1]`)); err != nil {
+		t.Errorf("expected equal items to be allowed, got %v", err)
+	}
+}
+
+func TestEqualValuesHashConsistency(t *testing.T) {
+	pairs := [][2]interface{}{
+		{0.0, math.Copysign(0, -1)},
+		{json.Number("1e400"), json.Number("10e399")},
+		{map[string]interface{}{"a": 1.0, "b": "x"}, map[string]interface{}{"b": "x", "a": 1.0}},
+	}
+
+	for _, pair := range pairs {
+		if !equalValues(pair[0], pair[1]) {
+			t.Errorf("expected %v and %v to be equal", pair[0], pair[1])
+		}
+		if hashValue(pair[0]) != hashValue(pair[1]) {
+			t.Errorf("expected %v and %v to have equal hashes", pair[0], pair[1])
+		}
+	}
+}
+
+func TestFindEqualItemsSorted(t *testing.T) {
+	testCases := []struct {
+		description string
+		array       []interface{}
+	}{
+		{"no equal items", []interface{}{1.0, 2.0, "1", true, nil}},
+		{"two equal items", []interface{}{1.0, 2.0, 3.0, 2.0}},
+		{"several equal pairs", []interface{}{"a", "b", "c", "b", "a", "c"}},
+		{"equal objects", []interface{}{
+			map[string]interface{}{"a": 1.0},
+			[]interface{}{1.0},
+			map[string]interface{}{"a": 1.0},
+		}},
+	}
+
+	for _, testCase := range testCases {
+		first, second, found := findEqualItems(testCase.array)
+		sortedFirst, sortedSecond, sortedFound := findEqualItemsSorted(testCase.array)
+		if first != sortedFirst || second != sortedSecond || found != sortedFound {
+			t.Errorf("%s: expected (%d, %d, %t), got (%d, %d, %t)", testCase.description,
+				first, second, found, sortedFirst, sortedSecond, sortedFound)
+		}
+	}
+}
+
+func TestUniqueItemsLargeArray(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{"uniqueItems": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := make([]string, 2*maxUniqueItemsMapSize)
+	for i := range items {
+		items[i] = strconv.Itoa(i)
+	}
+
+	validator := NewValidator(rootSchema)
+	if err := validator.Validate([]byte("[" + strings.Join(items, ",") + "]")); err != nil {
+		t.Errorf("expected the array to be valid, got %v", err)
+	}
+
+	items = append(items, "5")
+	err = validator.Validate([]byte("[" + strings.Join(items, ",") + "]"))
+	if err == nil || !strings.Contains(err.Error(), "indices: 5, 8192") {
+		t.Errorf("expected the items at indices 5 and 8192 to be reported, got %v", err)
+	}
+}
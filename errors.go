@@ -1,19 +1,140 @@
 package jsonvalidator
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The errors that the errors of JsonValidator match with errors.Is(), so
+// callers can tell failures apart without parsing their messages.
+var (
+	// ErrRefNotFound is matched by InvalidReferenceErrors, which are
+	// returned when a reference cannot be resolved.
+	ErrRefNotFound = errors.New("reference not found")
+
+	// ErrTypeMismatch is matched by the failures of "type" keywords.
+	ErrTypeMismatch = errors.New("type mismatch")
+
+	// ErrInvalidFormat is matched by the failures of "format" keywords.
+	ErrInvalidFormat = errors.New("invalid format")
+
+	// ErrRequiredProperty is matched by the failures of "required"
+	// keywords.
+	ErrRequiredProperty = errors.New("missing required property")
+
+	// ErrFalseSchema is matched by the failures of values that a "false"
+	// schema rejected.
+	ErrFalseSchema = errors.New("rejected by a false schema")
+
+	// ErrInvalidSchema is matched by SchemaCompilationError and
+	// SchemaCompilationErrors.
+	ErrInvalidSchema = errors.New("invalid schema")
+
+	// ErrUnsupportedDraft, ErrUnsupportedVocabulary, ErrUnsupportedScheme
+	// and ErrInvalidOutputFormat are matched by InvalidDraftError,
+	// UnsupportedVocabularyError, UnsupportedSchemeError and
+	// InvalidOutputFormatError.
+	ErrUnsupportedDraft      = errors.New("unsupported draft")
+	ErrUnsupportedVocabulary = errors.New("unsupported vocabulary")
+	ErrUnsupportedScheme     = errors.New("unsupported scheme")
+	ErrInvalidOutputFormat   = errors.New("invalid output format")
+)
+
+// The errors that the failures of keywords match, by the names of the
+// keywords.
+var keywordErrors = map[string]error{
+	"type":     ErrTypeMismatch,
+	"format":   ErrInvalidFormat,
+	"required": ErrRequiredProperty,
+}
 
 type KeywordValidationError struct {
 	keyword string
 	reason  string
+
+	// cause is the error that caused the failure, like the failure of a
+	// sub-schema, or the error of a format checker or an extension
+	// keyword.
+	cause error
+
+	// params holds the details of the failure that depend on the validated
+	// value, like the name of a missing required property.
+	params map[string]interface{}
+
+	// bestMatch is the error of the sub-schema that matched the value best,
+	// if the keyword failed because all of its sub-schemas failed.
+	bestMatch *SchemaValidationError
 }
 
 func (e KeywordValidationError) Error() string {
 	return fmt.Sprintf("\"" + e.keyword + "\" validation failed, reason: " + e.reason)
 }
 
+// Keyword returns the name of the keyword that failed.
+func (e KeywordValidationError) Keyword() string {
+	return e.keyword
+}
+
+// Unwrap returns the error that caused the failure, or nil.
+func (e KeywordValidationError) Unwrap() error {
+	return e.cause
+}
+
+// Is returns true if the target is the error that the failures of the
+// keyword match, like ErrTypeMismatch for "type".
+func (e KeywordValidationError) Is(target error) bool {
+	keywordError, ok := keywordErrors[e.keyword]
+	return ok && keywordError == target
+}
+
 type SchemaValidationError struct {
-	path string
-	err  string
+	path                    string
+	keyword                 string
+	keywordLocation         string
+	absoluteKeywordLocation string
+	err                     string
+	params                  map[string]interface{}
+	bestMatch               *SchemaValidationError
+
+	// cause is the KeywordValidationError that the failure was created
+	// from, or ErrFalseSchema.
+	cause error
+
+	// scopes are the schemas that the value and its parents were validated
+	// against when the failure was recorded, from the root schema down.
+	scopes []outputScope
+}
+
+// Path returns the json pointer of the value that failed in validation.
+func (e SchemaValidationError) Path() string {
+	return e.path
+}
+
+// Keyword returns the name of the keyword that the value failed to validate
+// against, or an empty string if the value was rejected by a "false" schema.
+func (e SchemaValidationError) Keyword() string {
+	return e.keyword
+}
+
+// KeywordLocation returns the json pointer of the failing keyword relative
+// to the root schema, following the path of the validation (including
+// "$ref" keywords), for example "/properties/age/minimum".
+func (e SchemaValidationError) KeywordLocation() string {
+	return e.keywordLocation
+}
+
+// AbsoluteKeywordLocation returns the absolute URI of the failing keyword,
+// after all the references on the way were resolved, for example
+// "http://example.com/person.json#/definitions/age/minimum".
+func (e SchemaValidationError) AbsoluteKeywordLocation() string {
+	return e.absoluteKeywordLocation
+}
+
+// Reason returns the description of the failure, without the path of the
+// value.
+func (e SchemaValidationError) Reason() string {
+	return e.err
 }
 
 func (e SchemaValidationError) Error() string {
@@ -30,25 +151,153 @@ func (e SchemaValidationError) Error() string {
 		e.err)
 }
 
+// Unwrap returns the KeywordValidationError of the failing keyword, or
+// ErrFalseSchema if the value was rejected by a "false" schema.
+func (e SchemaValidationError) Unwrap() error {
+	return e.cause
+}
+
 type SchemaCompilationError struct {
 	path string
 	err  string
+
+	// cause is the error that the compilation failed with, like the
+	// InvalidReferenceError of a reference that does not resolve, or nil.
+	cause error
 }
 
 func (e SchemaCompilationError) Error() string {
 	return fmt.Sprintf("schema compilation failed in path " + e.path + ": " + e.err)
 }
 
+// Path returns the json pointer of the schema location that failed to
+// compile.
+func (e SchemaCompilationError) Path() string {
+	return e.path
+}
+
+// Reason returns the description of the failure, without its path.
+func (e SchemaCompilationError) Reason() string {
+	return e.err
+}
+
+// Is returns true if the target is ErrInvalidSchema.
+func (e SchemaCompilationError) Is(target error) bool {
+	return target == ErrInvalidSchema
+}
+
+// Unwrap returns the error that the compilation failed with, or nil.
+func (e SchemaCompilationError) Unwrap() error {
+	return e.cause
+}
+
+// SchemaCompilationErrors holds all the errors that were found in the
+// compilation of a schema, sorted by their paths.
+type SchemaCompilationErrors []SchemaCompilationError
+
+func (e SchemaCompilationErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	messages := make([]string, len(e))
+	for index, compilationError := range e {
+		messages[index] = compilationError.Error()
+	}
+
+	return fmt.Sprintf("schema compilation failed with %d errors:\n%s", len(e), strings.Join(messages, "\n"))
+}
+
+// Is returns true if the target is ErrInvalidSchema, or if one of the
+// errors matches the target, like ErrRefNotFound for a reference that does
+// not resolve.
+func (e SchemaCompilationErrors) Is(target error) bool {
+	if target == ErrInvalidSchema {
+		return true
+	}
+
+	for _, compilationError := range e {
+		if errors.Is(compilationError, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first of the errors that matches the target, like
+// errors.As(), so a single SchemaCompilationError can be taken out of the
+// errors.
+func (e SchemaCompilationErrors) As(target interface{}) bool {
+	for _, compilationError := range e {
+		if errors.As(compilationError, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
 type InvalidDraftError string
 
 func (e InvalidDraftError) Error() string {
 	return fmt.Sprintf("draft " + string(e) + " is not supported by JsonValidator")
 }
 
+// Is returns true if the target is ErrUnsupportedDraft.
+func (e InvalidDraftError) Is(target error) bool {
+	return target == ErrUnsupportedDraft
+}
+
+// UnsupportedVocabularyError is returned when the meta-schema of a schema
+// requires a vocabulary that JsonValidator does not implement.
+type UnsupportedVocabularyError string
+
+func (e UnsupportedVocabularyError) Error() string {
+	return fmt.Sprintf("vocabulary " + string(e) + " is not supported by JsonValidator")
+}
+
+// Is returns true if the target is ErrUnsupportedVocabulary.
+func (e UnsupportedVocabularyError) Is(target error) bool {
+	return target == ErrUnsupportedVocabulary
+}
+
+// UnsupportedSchemeError is returned by a SchemeLoader for URIs of schemes
+// that it has no loader for.
+type UnsupportedSchemeError string
+
+func (e UnsupportedSchemeError) Error() string {
+	return fmt.Sprintf("no schema loader for the scheme \"" + string(e) + "\"")
+}
+
+// Is returns true if the target is ErrUnsupportedScheme.
+func (e UnsupportedSchemeError) Is(target error) bool {
+	return target == ErrUnsupportedScheme
+}
+
+type InvalidOutputFormatError string
+
+func (e InvalidOutputFormatError) Error() string {
+	return fmt.Sprintf("output format " + string(e) + " is not supported by JsonValidator")
+}
+
+// Is returns true if the target is ErrInvalidOutputFormat.
+func (e InvalidOutputFormatError) Is(target error) bool {
+	return target == ErrInvalidOutputFormat
+}
+
 type InvalidReferenceError struct {
 	schemaURI string
 	fragment  string
 	err       string
+
+	// cause is the error that the reference failed with, like the error
+	// of parsing its fragment, or nil.
+	cause error
+
+	// The fragments of the referenced root schema that are close to the
+	// fragment that was not found.
+	suggestions []string
 }
 
 func (e InvalidReferenceError) Error() string {
@@ -57,5 +306,27 @@ func (e InvalidReferenceError) Error() string {
 		fragment = e.fragment
 	}
 
-	return fmt.Sprintf(e.err + ": schema id - " + e.schemaURI + ", fragment - " + fragment)
+	message := e.err + ": schema id - " + e.schemaURI + ", fragment - " + fragment
+	if len(e.suggestions) > 0 {
+		message += " (did you mean #" + strings.Join(e.suggestions, " or #") + "?)"
+	}
+
+	return message
+}
+
+// Unwrap returns the error that the reference failed with, or nil.
+func (e InvalidReferenceError) Unwrap() error {
+	return e.cause
+}
+
+// Is returns true if the target is ErrRefNotFound.
+func (e InvalidReferenceError) Is(target error) bool {
+	return target == ErrRefNotFound
+}
+
+// Suggestions returns the json pointers of the sub-schemas of the referenced
+// root schema that the missing fragment is likely a typo of, the closest
+// first.
+func (e InvalidReferenceError) Suggestions() []string {
+	return append([]string(nil), e.suggestions...)
 }
@@ -0,0 +1,153 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/itayankri/gojsonvalidator/formatchecker"
+)
+
+// KeywordFunc validates a json value against the value of a custom keyword
+// in a schema. It returns nil if the value is valid, or an error that
+// describes why it is not.
+type KeywordFunc func(keywordValue json.RawMessage, value interface{}) error
+
+// Extension is a named pack of custom keywords and formats. Extension
+// packages register their extensions with RegisterExtension() in an init()
+// function, and validators opt in to them by name with
+// Validator.UseExtensions(), so importing a package does not change the
+// validation of the validators that do not use it.
+type Extension struct {
+	Name string
+
+	// Keywords are the custom keywords of the extension by their names,
+	// which must not be the names of the standard keywords.
+	Keywords map[string]KeywordFunc
+
+	// Formats are the checkers of the custom formats of the extension by
+	// their names. They take precedence over the formats of
+	// formatchecker.DefaultRegistry.
+	Formats map[string]func(string) error
+}
+
+var (
+	// extensionsMutex guards the registered extensions.
+	extensionsMutex sync.RWMutex
+
+	// The registered extensions by their names.
+	registeredExtensions = map[string]*Extension{}
+
+	// The names of the keywords of the registered extensions, whose values
+	// are kept when schemas are unmarshaled.
+	extensionKeywords = map[string]bool{}
+)
+
+// RegisterExtension registers an extension in the default extension
+// registry, so validators can use it by its name. Keywords of schemas that
+// were created before the extension was registered are ignored, so
+// extensions are registered in init() functions. It panics if the extension
+// has no name or if an extension with the same name is already registered.
+func RegisterExtension(extension Extension) {
+	extensionsMutex.Lock()
+	defer extensionsMutex.Unlock()
+
+	if extension.Name == "" {
+		panic("jsonvalidator: RegisterExtension with an empty name")
+	}
+	if _, ok := registeredExtensions[extension.Name]; ok {
+		panic("jsonvalidator: RegisterExtension called twice for extension " + extension.Name)
+	}
+
+	registeredExtensions[extension.Name] = &extension
+	for keyword := range extension.Keywords {
+		extensionKeywords[keyword] = true
+	}
+}
+
+// Extensions returns the sorted names of the registered extensions.
+func Extensions() []string {
+	extensionsMutex.RLock()
+	defer extensionsMutex.RUnlock()
+
+	names := make([]string, 0, len(registeredExtensions))
+	for name := range registeredExtensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// isExtensionKeyword returns true if the keyword belongs to a registered
+// extension.
+func isExtensionKeyword(keyword string) bool {
+	extensionsMutex.RLock()
+	defer extensionsMutex.RUnlock()
+
+	return extensionKeywords[keyword]
+}
+
+// UseExtensions enables the keywords and formats of the registered
+// extensions of the given names in the validator. When extensions define
+// the same keyword or format, the one of the latter extension is used. It
+// panics if one of the extensions is not registered.
+// It returns the receiver in order to allow chaining of options.
+func (v *Validator) UseExtensions(names ...string) *Validator {
+	extensionsMutex.RLock()
+	defer extensionsMutex.RUnlock()
+
+	v.extensionKeywords = map[string]KeywordFunc{}
+	v.extensionFormats = map[string]formatchecker.Checker{}
+	for _, name := range names {
+		extension, ok := registeredExtensions[name]
+		if !ok {
+			panic("jsonvalidator: unknown extension " + name + " (forgotten import?)")
+		}
+
+		for keyword, keywordFunc := range extension.Keywords {
+			v.extensionKeywords[keyword] = keywordFunc
+		}
+		for format, checker := range extension.Formats {
+			v.extensionFormats[format] = formatchecker.CheckerFunc(checker)
+		}
+	}
+
+	return v
+}
+
+// formatChecker returns the checker of a format, from the extensions of the
+// validator or from formatchecker.DefaultRegistry.
+func (v *Validator) formatChecker(name string) (formatchecker.Checker, bool) {
+	if checker, ok := v.extensionFormats[name]; ok {
+		return checker, true
+	}
+
+	return formatchecker.Get(name)
+}
+
+// validateExtensionKeywords validates a json value against the keywords of
+// the schema that belong to the extensions of the validator, in the order
+// of their names.
+func (js *JsonSchema) validateExtensionKeywords(jsonData jsonData, ctx *validationContext) error {
+	var keywords []string
+	for keyword := range js.extensions {
+		if _, ok := ctx.validator.extensionKeywords[keyword]; ok {
+			keywords = append(keywords, keyword)
+		}
+	}
+	sort.Strings(keywords)
+
+	for _, keyword := range keywords {
+		err := ctx.validator.extensionKeywords[keyword](js.extensions[keyword], jsonData.value)
+		if err != nil {
+			return KeywordValidationError{
+				keyword: keyword,
+				reason:  err.Error(),
+				cause:   err,
+			}
+		}
+	}
+
+	return nil
+}
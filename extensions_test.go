@@ -0,0 +1,104 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func init() {
+	RegisterExtension(Extension{
+		Name: "test-pack",
+		Keywords: map[string]KeywordFunc{
+			"divisibleBy": func(keywordValue json.RawMessage, value interface{}) error {
+				var divisor int
+				if err := json.Unmarshal(keywordValue, &divisor); err != nil {
+					return err
+				}
+
+				if number, ok := value.(float64); ok && int(number)%divisor != 0 {
+					return errors.New("value is not divisible by " + string(keywordValue))
+				}
+				return nil
+			},
+		},
+		Formats: map[string]func(string) error{
+			"upper-case": func(input string) error {
+				if strings.ToUpper(input) != input {
+					return errors.New("not upper case")
+				}
+				return nil
+			},
+		},
+	})
+}
+
+func TestExtensions(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"properties": {
+			"count": {"type": "integer", "divisibleBy": 3},
+			"code": {"type": "string", "format": "upper-case"}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		document string
+		valid    bool
+	}{
+		{`{"count": 9, "code": "ABC"}`, true},
+		{`{"count": 10}`, false},
+		{`{"code": "abc"}`, false},
+	}
+
+	extended := NewValidator(rootSchema).UseExtensions("test-pack")
+	plain := NewValidator(rootSchema)
+	for _, test := range tests {
+		if err := extended.Validate([]byte(test.document)); test.valid != (err == nil) {
+			t.Errorf("%s: expected valid=%t, got %v", test.document, test.valid, err)
+		}
+
+		// The extensions apply only to the validators that use them.
+		if err := plain.Validate([]byte(test.document)); err != nil {
+			t.Errorf("%s: expected the document to be valid without the extension, got %v", test.document, err)
+		}
+	}
+
+	err = extended.Validate([]byte(`{"count": 10}`))
+	if schemaValidationError, ok := err.(SchemaValidationError); !ok ||
+		schemaValidationError.KeywordLocation() != "/properties/count/divisibleBy" {
+		t.Errorf("expected a failure of the custom keyword, got %v", err)
+	}
+
+	if names := Extensions(); !reflect.DeepEqual(names, []string{"test-pack"}) {
+		t.Errorf("expected the registered extensions, got %v", names)
+	}
+}
+
+func TestRegisterExtensionTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic")
+		}
+	}()
+
+	RegisterExtension(Extension{Name: "test-pack"})
+}
+
+func TestUseUnknownExtension(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic")
+		}
+	}()
+
+	rootSchema, err := NewRootJsonSchema([]byte(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	NewValidator(rootSchema).UseExtensions("missing")
+}
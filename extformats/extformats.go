@@ -0,0 +1,195 @@
+// Package extformats provides checkers for formats that are not defined by
+// the json schema specification, but are commonly used in schemas.
+//
+// The checkers are not registered by default. They can be registered in bulk:
+//
+//	jsonvalidator.RegisterFormats(extformats.All())
+package extformats
+
+import (
+	"errors"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// Names of the formats provided by this package.
+const (
+	FORMAT_IBAN          = "iban"
+	FORMAT_ISBN          = "isbn"
+	FORMAT_CREDIT_CARD   = "credit-card"
+	FORMAT_MAC_ADDRESS   = "mac-address"
+	FORMAT_COUNTRY_CODE  = "country-code"
+	FORMAT_CURRENCY_CODE = "currency-code"
+)
+
+// All returns the checkers of this package keyed by their format names.
+func All() map[string]func(string) error {
+	return map[string]func(string) error{
+		FORMAT_IBAN:          IsValidIBAN,
+		FORMAT_ISBN:          IsValidISBN,
+		FORMAT_CREDIT_CARD:   IsValidCreditCard,
+		FORMAT_MAC_ADDRESS:   IsValidMACAddress,
+		FORMAT_COUNTRY_CODE:  IsValidCountryCode,
+		FORMAT_CURRENCY_CODE: IsValidCurrencyCode,
+	}
+}
+
+// The electronic form of an IBAN, whose length and check digits are
+// verified separately.
+var ibanPattern = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+
+// ISO 13616 International Bank Account Number, in its electronic form
+// (without spaces). The length is verified for the known IBAN countries and
+// the check digits are verified with the mod-97 algorithm.
+func IsValidIBAN(iban string) error {
+	if !ibanPattern.MatchString(iban) {
+		return errors.New("invalid iban " + iban)
+	}
+	if length, ok := ibanLengths[iban[:2]]; ok && len(iban) != length {
+		return errors.New("invalid iban length for country " + iban[:2])
+	}
+
+	// Move the first four characters to the end and replace each letter
+	// with two digits (A = 10, ..., Z = 35).
+	var digits strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' && r <= 'Z' {
+			digits.WriteString(big.NewInt(int64(r-'A') + 10).String())
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+
+	number, _ := new(big.Int).SetString(digits.String(), 10)
+	if new(big.Int).Mod(number, big.NewInt(97)).Int64() != 1 {
+		return errors.New("invalid iban check digits " + iban)
+	}
+	return nil
+}
+
+// ISO 2108 International Standard Book Number, either ISBN-10 or ISBN-13.
+// Hyphens and spaces between the groups are allowed.
+func IsValidISBN(isbn string) error {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(isbn)
+	switch len(normalized) {
+	case 10:
+		sum := 0
+		for index, r := range normalized {
+			var digit int
+			switch {
+			case r >= '0' && r <= '9':
+				digit = int(r - '0')
+			case (r == 'X' || r == 'x') && index == 9:
+				digit = 10
+			default:
+				return errors.New("invalid isbn-10 " + isbn)
+			}
+			sum += digit * (10 - index)
+		}
+		if sum%11 != 0 {
+			return errors.New("invalid isbn-10 check digit " + isbn)
+		}
+		return nil
+	case 13:
+		sum := 0
+		for index, r := range normalized {
+			if r < '0' || r > '9' {
+				return errors.New("invalid isbn-13 " + isbn)
+			}
+			weight := 1
+			if index%2 == 1 {
+				weight = 3
+			}
+			sum += int(r-'0') * weight
+		}
+		if sum%10 != 0 {
+			return errors.New("invalid isbn-13 check digit " + isbn)
+		}
+		return nil
+	default:
+		return errors.New("isbn must contain 10 or 13 digits " + isbn)
+	}
+}
+
+// A payment card number (ISO/IEC 7812) of 12 to 19 digits with a valid Luhn
+// check digit. Spaces and hyphens between the digit groups are allowed.
+func IsValidCreditCard(number string) error {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(number)
+	if len(normalized) < 12 || len(normalized) > 19 {
+		return errors.New("credit card number must contain 12 to 19 digits")
+	}
+
+	sum := 0
+	double := false
+	for index := len(normalized) - 1; index >= 0; index-- {
+		r := normalized[index]
+		if r < '0' || r > '9' {
+			return errors.New("credit card number must contain only digits")
+		}
+		digit := int(r - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	if sum%10 != 0 {
+		return errors.New("invalid credit card check digit")
+	}
+	return nil
+}
+
+// IEEE 802 MAC-48/EUI-48 or EUI-64 address, in one of the forms accepted by
+// net.ParseMAC ("01:23:45:67:89:ab", "01-23-45-67-89-ab" or
+// "0123.4567.89ab"). The address is parsed without the net package, which
+// is not available in all the builds of this module (see the TinyGo profile
+// of the formatchecker package).
+func IsValidMACAddress(mac string) error {
+	groupSize, separator := 2, byte(':')
+	switch {
+	case len(mac) > 2 && mac[2] == '-':
+		separator = '-'
+	case len(mac) > 4 && mac[4] == '.':
+		groupSize, separator = 4, '.'
+	}
+
+	// The number of groups is known from the length of the address, since
+	// every group is followed by a separator, except for the last one.
+	groups := (len(mac) + 1) / (groupSize + 1)
+	if (len(mac)+1)%(groupSize+1) != 0 || groups*groupSize/2 != 6 && groups*groupSize/2 != 8 {
+		return errors.New("invalid mac address " + mac)
+	}
+
+	for index := 0; index < len(mac); index++ {
+		isSeparator := (index+1)%(groupSize+1) == 0
+		if isSeparator && mac[index] != separator || !isSeparator && !isHexDigit(mac[index]) {
+			return errors.New("invalid mac address " + mac)
+		}
+	}
+
+	return nil
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// ISO 3166-1 alpha-2 country code, in upper case.
+func IsValidCountryCode(code string) error {
+	if !countryCodes[code] {
+		return errors.New("unknown ISO 3166-1 alpha-2 country code " + code)
+	}
+	return nil
+}
+
+// ISO 4217 alphabetic currency code, in upper case.
+func IsValidCurrencyCode(code string) error {
+	if !currencyCodes[code] {
+		return errors.New("unknown ISO 4217 currency code " + code)
+	}
+	return nil
+}
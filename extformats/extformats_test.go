@@ -0,0 +1,90 @@
+package extformats_test
+
+import (
+	"testing"
+
+	"github.com/itayankri/gojsonvalidator/extformats"
+)
+
+type test struct {
+	data        string
+	valid       bool
+	description string
+}
+
+func TestIsValidIBAN(t *testing.T) {
+	isValidFormat(t, []test{
+		{description: "a valid german iban", data: "DE89370400440532013000", valid: true},
+		{description: "a valid british iban", data: "GB82WEST12345698765432", valid: true},
+		{description: "wrong check digits", data: "DE88370400440532013000", valid: false},
+		{description: "wrong length for the country", data: "DE8937040044053201300", valid: false},
+		{description: "spaces are not allowed", data: "DE89 3704 0044 0532 0130 00", valid: false},
+	}, extformats.FORMAT_IBAN, extformats.IsValidIBAN)
+}
+
+func TestIsValidISBN(t *testing.T) {
+	isValidFormat(t, []test{
+		{description: "a valid isbn-10", data: "0-306-40615-2", valid: true},
+		{description: "a valid isbn-10 with X check digit", data: "080442957X", valid: true},
+		{description: "a valid isbn-13", data: "978-0-306-40615-7", valid: true},
+		{description: "wrong isbn-10 check digit", data: "0306406153", valid: false},
+		{description: "wrong isbn-13 check digit", data: "9780306406158", valid: false},
+		{description: "wrong number of digits", data: "12345", valid: false},
+	}, extformats.FORMAT_ISBN, extformats.IsValidISBN)
+}
+
+func TestIsValidCreditCard(t *testing.T) {
+	isValidFormat(t, []test{
+		{description: "a valid visa test number", data: "4111111111111111", valid: true},
+		{description: "a valid number with spaces", data: "4111 1111 1111 1111", valid: true},
+		{description: "wrong luhn check digit", data: "4111111111111112", valid: false},
+		{description: "too short", data: "41111111", valid: false},
+		{description: "non-digit characters", data: "4111a11111111111", valid: false},
+	}, extformats.FORMAT_CREDIT_CARD, extformats.IsValidCreditCard)
+}
+
+func TestIsValidMACAddress(t *testing.T) {
+	isValidFormat(t, []test{
+		{description: "colon separated", data: "01:23:45:67:89:ab", valid: true},
+		{description: "hyphen separated", data: "01-23-45-67-89-AB", valid: true},
+		{description: "an EUI-64 address", data: "01:23:45:67:89:ab:cd:ef", valid: true},
+		{description: "too few octets", data: "01:23:45:67:89", valid: false},
+		{description: "invalid hex digit", data: "01:23:45:67:89:zz", valid: false},
+		{description: "dot separated", data: "0123.4567.89ab", valid: true},
+		{description: "mixed separators", data: "01:23-45:67:89:ab", valid: false},
+	}, extformats.FORMAT_MAC_ADDRESS, extformats.IsValidMACAddress)
+}
+
+func TestIsValidCountryCode(t *testing.T) {
+	isValidFormat(t, []test{
+		{description: "an assigned code", data: "IL", valid: true},
+		{description: "lower case", data: "il", valid: false},
+		{description: "an unassigned code", data: "ZZ", valid: false},
+		{description: "an alpha-3 code", data: "ISR", valid: false},
+	}, extformats.FORMAT_COUNTRY_CODE, extformats.IsValidCountryCode)
+}
+
+func TestIsValidCurrencyCode(t *testing.T) {
+	isValidFormat(t, []test{
+		{description: "an active code", data: "EUR", valid: true},
+		{description: "lower case", data: "usd", valid: false},
+		{description: "an unknown code", data: "ABC", valid: false},
+	}, extformats.FORMAT_CURRENCY_CODE, extformats.IsValidCurrencyCode)
+}
+
+func TestAll(t *testing.T) {
+	if len(extformats.All()) != 6 {
+		t.Errorf("expected 6 checkers, got %d", len(extformats.All()))
+	}
+}
+
+func isValidFormat(t *testing.T, tests []test, formatType string, fn func(string) error) {
+	t.Logf("Given the need to test %s format", formatType)
+	for index, testCase := range tests {
+		valid := fn(testCase.data) == nil
+		if valid != testCase.valid {
+			t.Errorf("\tTest %d: %s => %s: should get valid = %t but got valid = %t",
+				index, testCase.data, testCase.description, testCase.valid, valid)
+		}
+	}
+}
@@ -1,74 +1,269 @@
 package formatchecker
 
 import (
+	"encoding/base64"
 	"errors"
-	"fmt"
-	"net"
-	"net/mail"
-	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
-	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The parts of the grammar of ISO 8601 durations (see IsValidDuration()).
+const (
+	durTime = `T(?:\d+H(?:\d+M(?:\d+S)?)?|\d+M(?:\d+S)?|\d+S)`
+	durDate = `(?:\d+D|\d+M(?:\d+D)?|\d+Y(?:\d+M(?:\d+D)?)?)(?:` + durTime + `)?`
+)
+
+// The patterns of the formats that are checked with regular expressions,
+// which are compiled once, rather than in every check.
+var (
+	datePattern           = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	timePattern           = regexp.MustCompile(`^(\d{2}):(\d{2}):(\d{2})(\.\d+)?([Zz]|([+-])(\d{2}):(\d{2}))$`)
+	hostnamePattern       = regexp.MustCompile(`^([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])(\.([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9\-]{0,61}[a-zA-Z0-9]))*$`)
+	hostnameLabelPattern  = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?$`)
+	schemePrefixPattern   = regexp.MustCompile(`^[^\:]+\:`)
+	varspecPattern        = regexp.MustCompile(`^(?:[A-Za-z0-9_]|%[0-9A-Fa-f]{2})(?:\.?(?:[A-Za-z0-9_]|%[0-9A-Fa-f]{2}))*(?::[1-9][0-9]{0,3}|\*)?$`)
+	unescapedTildaPattern = regexp.MustCompile(`\~[^01]`)
+	endingTildaPattern    = regexp.MustCompile(`\~$`)
+	semverPattern         = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+		`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+		`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+	durationPattern = regexp.MustCompile(`^P(?:` + durDate + `|` + durTime + `|\d+W)$`)
+	e164Pattern     = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
 )
 
 // from RFC 3339, section 5.6 [RFC3339]
 // https://tools.ietf.org/html/rfc3339#section-5.6
+// The grammar is checked directly rather than with time.Parse, since
+// time.Parse rejects leap seconds.
 func IsValidDateTime(dateTime string) error {
-	if _, err := time.Parse(time.RFC3339, dateTime); err != nil {
+	separator := strings.IndexAny(dateTime, "Tt")
+	if separator == -1 {
+		return errors.New("invalid date-time " + dateTime + ": missing 'T' separator")
+	}
+	if err := IsValidDate(dateTime[:separator]); err != nil {
 		return err
 	}
-	return nil
+	return IsValidTime(dateTime[separator+1:])
 }
 
 // RFC 3339, section 5.6 [RFC3339]
 // https://tools.ietf.org/html/rfc3339#section-5.6
 func IsValidDate(date string) error {
-	timeToAppend := "T00:00:00.0Z"
-	dateTime := fmt.Sprintf("%s%s", date, timeToAppend)
-	return IsValidDateTime(dateTime)
+	match := datePattern.FindStringSubmatch(date)
+	if match == nil {
+		return errors.New("invalid date " + date)
+	}
+	year, _ := strconv.Atoi(match[1])
+	month, _ := strconv.Atoi(match[2])
+	day, _ := strconv.Atoi(match[3])
+	if month < 1 || month > 12 {
+		return errors.New("invalid month in date " + date)
+	}
+	if day < 1 || day > daysInMonth(year, month) {
+		return errors.New("invalid day in date " + date)
+	}
+	return nil
 }
 
 // RFC 3339, section 5.6 [RFC3339]
 // https://tools.ietf.org/html/rfc3339#section-5.6
+// A leap second (second 60) is only valid when the time, converted to UTC
+// using its offset, is 23:59.
 func IsValidTime(time string) error {
-	dateToAppend := "1991-02-21"
-	dateTime := fmt.Sprintf("%sT%s", dateToAppend, time)
-	return IsValidDateTime(dateTime)
+	match := timePattern.FindStringSubmatch(time)
+	if match == nil {
+		return errors.New("invalid time " + time)
+	}
+	hour, _ := strconv.Atoi(match[1])
+	minute, _ := strconv.Atoi(match[2])
+	second, _ := strconv.Atoi(match[3])
+	if hour > 23 || minute > 59 || second > 60 {
+		return errors.New("time out of range " + time)
+	}
+
+	offsetMinutes := 0
+	if match[6] != "" {
+		offsetHour, _ := strconv.Atoi(match[7])
+		offsetMinute, _ := strconv.Atoi(match[8])
+		if offsetHour > 23 || offsetMinute > 59 {
+			return errors.New("time offset out of range " + time)
+		}
+		offsetMinutes = offsetHour*60 + offsetMinute
+		if match[6] == "-" {
+			offsetMinutes = -offsetMinutes
+		}
+	}
+
+	if second == 60 {
+		utcMinutes := ((hour*60+minute-offsetMinutes)%(24*60) + 24*60) % (24 * 60)
+		if utcMinutes != 23*60+59 {
+			return errors.New("leap second must be at 23:59 UTC " + time)
+		}
+	}
+	return nil
+}
+
+func daysInMonth(year int, month int) int {
+	switch month {
+	case 2:
+		if year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+			return 29
+		}
+		return 28
+	case 4, 6, 9, 11:
+		return 30
+	default:
+		return 31
+	}
 }
 
 // RFC 5322, section 3.4.1 [RFC5322].
 // https://tools.ietf.org/html/rfc5322#section-3.4.1
 func IsValidEmail(email string) error {
-	if _, err := mail.ParseAddress(email); err != nil {
-		return err
-	}
-	return nil
+	return parseMailAddress(email)
 }
 
-// RFC 6531 [RFC6531]
-// https://tools.ietf.org/html/rfc6531
+// RFC 6531, section 3.3 [RFC6531]
+// https://tools.ietf.org/html/rfc6531#section-3.3
+// The Mailbox of RFC 5321, section 4.1.2 [RFC5321], extended with UTF-8: the
+// local part is a dot-string or a quoted-string of at most 64 octets whose
+// characters may be any non-ASCII UTF-8 characters (except controls), and
+// the domain is an address literal, or a domain whose labels may be
+// U-labels, which are checked by their conversion to A-labels (RFC 5891,
+// section 5 [RFC5891]).
 func IsValidIdnEmail(idnEmail string) error {
-	if _, err := mail.ParseAddress(idnEmail); err != nil {
-		return err
+	if !utf8.ValidString(idnEmail) {
+		return errors.New("invalid idn-email: not valid UTF-8")
+	}
+
+	at := strings.LastIndexByte(idnEmail, '@')
+	if at == -1 {
+		return errors.New("invalid idn-email " + idnEmail + ": missing @")
 	}
+
+	local, domain := idnEmail[:at], idnEmail[at+1:]
+	if len(local) > 64 {
+		return errors.New("invalid idn-email " + idnEmail + ": local part is too long (more than 64 octets)")
+	}
+	if strings.HasPrefix(local, `"`) {
+		if !isUTF8QuotedString(local) {
+			return errors.New("invalid idn-email " + idnEmail + ": invalid quoted local part")
+		}
+	} else if !isUTF8DotString(local) {
+		return errors.New("invalid idn-email " + idnEmail + ": invalid local part")
+	}
+
+	if strings.HasPrefix(domain, "[") && strings.HasSuffix(domain, "]") {
+		literal := domain[1 : len(domain)-1]
+		if strings.HasPrefix(literal, "IPv6:") {
+			return IsValidIPv6(literal[len("IPv6:"):])
+		}
+		return IsValidIPv4(literal)
+	}
+
+	length := 0
+	for _, label := range splitIdnaLabels(domain) {
+		aLabel, err := toALabel(label)
+		if err != nil {
+			return errors.New("invalid idn-email " + idnEmail + ": " + err.Error())
+		}
+		length += len(aLabel) + 1
+	}
+	if length-1 > 253 {
+		return errors.New("invalid idn-email " + idnEmail + ": domain is too long (more than 253 octets)")
+	}
+
 	return nil
 }
 
+// isUTF8DotString returns true if s is a Dot-string of RFC 5321, section
+// 4.1.2, whose atoms may hold non-ASCII UTF-8 characters.
+func isUTF8DotString(s string) bool {
+	for _, atom := range strings.Split(s, ".") {
+		if atom == "" {
+			return false
+		}
+
+		for _, r := range atom {
+			isAtext := r >= 0x80 && !unicode.IsControl(r) ||
+				r < 0x80 && (isAlphaNumeric(byte(r)) || strings.ContainsRune("!#$%&'*+-/=?^_`{|}~", r))
+			if !isAtext {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isUTF8QuotedString returns true if s is a Quoted-string of RFC 5321,
+// section 4.1.2, which may hold non-ASCII UTF-8 characters.
+func isUTF8QuotedString(s string) bool {
+	if len(s) < 2 || s[len(s)-1] != '"' {
+		return false
+	}
+
+	runes := []rune(s[1 : len(s)-1])
+	for index := 0; index < len(runes); index++ {
+		switch r := runes[index]; {
+		case r == '\\':
+			index++
+			if index == len(runes) || runes[index] < 32 || runes[index] > 126 {
+				return false
+			}
+		case r == '"' || r < 32 || r == 127 || r >= 0x80 && unicode.IsControl(r):
+			return false
+		}
+	}
+
+	return true
+}
+
 // RFC 1034, section 3.1 [RFC1034]
 // https://tools.ietf.org/html/rfc1034#section-3.1
 func IsValidHostname(hostname string) error {
-	hostnamePattern := `^([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])(\.([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9\-]{0,61}[a-zA-Z0-9]))*$`
-	hostnamePatternCompiled := regexp.MustCompile(hostnamePattern)
 	if len(hostname) > 255 {
 		return errors.New("hostname is too long (more then 255 characters)")
 	}
-	if valid := hostnamePatternCompiled.MatchString(hostname); !valid {
+	if valid := hostnamePattern.MatchString(hostname); !valid {
 		return errors.New(hostname + "is not valid hostname")
 	}
 	return nil
 }
 
+// RFC 1123, section 2.1 [RFC1123] and RFC 5890, section 2.3.1 [RFC5890].
+// https://tools.ietf.org/html/rfc1123#section-2.1
+// https://tools.ietf.org/html/rfc5890#section-2.3.1
+// A stricter variant of IsValidHostname that matches the expectations of the
+// JSON-Schema-Test-Suite: the hostname is at most 253 characters long, it
+// has no empty labels (so no trailing dot either), every label is 1-63
+// letters, digits and hyphens that does not start or end with a hyphen, and
+// only punycode labels ("xn--") may contain hyphens in both the third and
+// fourth positions.
+func IsValidHostnameStrict(hostname string) error {
+	if len(hostname) == 0 {
+		return errors.New("hostname is empty")
+	}
+	if len(hostname) > 253 {
+		return errors.New("hostname is too long (more then 253 characters)")
+	}
+	for _, label := range strings.Split(hostname, ".") {
+		if len(label) == 0 {
+			return errors.New("hostname " + hostname + " contains an empty label")
+		}
+		if !hostnameLabelPattern.MatchString(label) {
+			return errors.New("hostname " + hostname + " contains an invalid label " + label)
+		}
+		if len(label) >= 4 && label[2:4] == "--" && !strings.EqualFold(label[:2], "xn") {
+			return errors.New("hostname label " + label + " has \"--\" in the third and fourth positions")
+		}
+	}
+	return nil
+}
+
 // RFC 1034 as for hostname, or
 // an internationalized hostname as defined by RFC 5890, section
 // 2.3.2.3 [RFC5890].
@@ -92,7 +287,7 @@ func IsValidIdnHostname(idnHostname string) error {
 	for _, r := range idnHostname {
 		s := string(r)
 		if disallowedIdnChars[s] {
-			return errors.New(fmt.Sprintf("invalid hostname: contains illegal character %#U", r))
+			return errors.New("invalid hostname: contains illegal character " + strconv.QuoteRuneToASCII(r))
 		}
 	}
 
@@ -101,11 +296,28 @@ func IsValidIdnHostname(idnHostname string) error {
 
 // RFC 2673, section 3.2 [RFC2673].
 // https://tools.ietf.org/html/rfc2673#section-3.2
+// Only the dotted-quad form is accepted: exactly four decimal octets in the
+// range 0-255, without leading zeros.
 func IsValidIPv4(ipv4 string) error {
-	parsed := net.ParseIP(ipv4)
-	hasDots := strings.Contains(ipv4, ".")
-	if parsed == nil || !hasDots {
-		return errors.New("invalid ipv4 address " + ipv4)
+	octets := strings.Split(ipv4, ".")
+	if len(octets) != 4 {
+		return errors.New("invalid ipv4 address " + ipv4 + ": expected four octets")
+	}
+	for _, octet := range octets {
+		if len(octet) == 0 || len(octet) > 3 {
+			return errors.New("invalid ipv4 address " + ipv4)
+		}
+		for _, r := range octet {
+			if r < '0' || r > '9' {
+				return errors.New("invalid ipv4 address " + ipv4)
+			}
+		}
+		if len(octet) > 1 && octet[0] == '0' {
+			return errors.New("invalid ipv4 address " + ipv4 + ": leading zeros are not allowed")
+		}
+		if value, _ := strconv.Atoi(octet); value > 255 {
+			return errors.New("invalid ipv4 address " + ipv4 + ": octet out of range")
+		}
 	}
 
 	return nil
@@ -114,9 +326,8 @@ func IsValidIPv4(ipv4 string) error {
 // RFC 4291, section 2.2 [RFC4291].
 // https://tools.ietf.org/html/rfc4291#section-2.2
 func IsValidIPv6(ipv6 string) error {
-	parsed := net.ParseIP(ipv6)
 	hasColons := strings.Contains(ipv6, ":")
-	if parsed == nil || !hasColons {
+	if !parseIP(ipv6) || !hasColons {
 		return errors.New("invalid ipv6 address " + ipv6)
 	}
 
@@ -126,13 +337,11 @@ func IsValidIPv6(ipv6 string) error {
 // RFC3986
 // https://tools.ietf.org/html/rfc3986
 func IsValidURI(uri string) error {
-	schemePrefix := `^[^\:]+\:`
-	schemePrefixPattern := regexp.MustCompile(schemePrefix)
-	if _, err := url.Parse(uri); err != nil {
+	if err := parseURIReference(uri); err != nil {
 		return err
 	}
 	if !schemePrefixPattern.MatchString(uri) {
-		return fmt.Errorf("uri missing scheme prefix")
+		return errors.New("uri missing scheme prefix")
 	}
 	return nil
 }
@@ -140,7 +349,7 @@ func IsValidURI(uri string) error {
 // RFC3986
 // https://tools.ietf.org/html/rfc3986
 func IsValidUriRef(uriRef string) error {
-	if _, err := url.Parse(uriRef); err != nil {
+	if err := parseURIReference(uriRef); err != nil {
 		return err
 	}
 	if strings.Contains(uriRef, "\\") {
@@ -168,26 +377,73 @@ func IsValidIriRef(iriRef string) error {
 // valid URI Template (of any level), according to [RFC6570]. Note
 // that URI Templates may be used for IRIs; there is no separate IRI
 // Template specification.
-// https://tools.ietf.org/html/rfc6570
+// https://tools.ietf.org/html/rfc6570#section-2
 func IsValidURITemplate(uriTemplate string) error {
-	//uriTemplatePattern := regexp.MustCompile(`\{[^\{\}\\]*\}`)
-	uriTemplatePattern := regexp.MustCompile(`{[^{}\\]*}`)
-	arbitraryValue := "tmp"
-	uriRef := uriTemplatePattern.ReplaceAllString(uriTemplate, arbitraryValue)
-	if strings.Contains(uriRef, "{") || strings.Contains(uriRef, "}") {
-		return errors.New("invalid uri template " + uriTemplate)
+	for index := 0; index < len(uriTemplate); {
+		switch c := uriTemplate[index]; {
+		case c == '{':
+			end := strings.IndexByte(uriTemplate[index:], '}')
+			if end == -1 {
+				return errors.New("invalid uri template " + uriTemplate + ": unclosed expression")
+			}
+			if err := isValidURITemplateExpression(uriTemplate[index+1 : index+end]); err != nil {
+				return errors.New("invalid uri template " + uriTemplate + ": " + err.Error())
+			}
+			index += end + 1
+		case c == '%':
+			if !isPctEncoded(uriTemplate[index:]) {
+				return errors.New("invalid uri template " + uriTemplate + ": invalid percent-encoding")
+			}
+			index += 3
+		case c >= 0x80:
+			// ucschar and iprivate are allowed in literals.
+			index++
+		case c <= 0x20 || c == 0x7F || strings.IndexByte("\"'<>\\^`|}", c) != -1:
+			return errors.New("invalid uri template " + uriTemplate + ": illegal character " + strconv.QuoteRune(rune(c)))
+		default:
+			index++
+		}
+	}
+	return nil
+}
+
+// isValidURITemplateExpression validates the content of a uri template
+// expression (without the braces):
+//
+//	expression    =  [ operator ] variable-list
+//	variable-list =  varspec *( "," varspec )
+//	varspec       =  varname [ modifier-level4 ]
+func isValidURITemplateExpression(expression string) error {
+	if len(expression) > 0 && strings.IndexByte("+#./;?&", expression[0]) != -1 {
+		expression = expression[1:]
+	} else if len(expression) > 0 && strings.IndexByte("=,!@|", expression[0]) != -1 {
+		return errors.New("reserved operator " + expression[:1])
 	}
-	return IsValidUriRef(uriRef)
+
+	for _, varspec := range strings.Split(expression, ",") {
+		if !varspecPattern.MatchString(varspec) {
+			return errors.New("invalid variable specification \"" + varspec + "\"")
+		}
+	}
+	return nil
+}
+
+// isPctEncoded returns true if s starts with a percent-encoded octet.
+func isPctEncoded(s string) bool {
+	return len(s) >= 3 && s[0] == '%' && isHexDigit(s[1]) && isHexDigit(s[2])
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isAlphaNumeric(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
 }
 
 // RFC 6901, section 5 [RFC6901].
 // https://tools.ietf.org/html/rfc6901#section-5
 func IsValidJSONPointer(jsonPointer string) error {
-	unescapedTilda := `\~[^01]`
-	endingTilda := `\~$`
-	unescaptedTildaPattern := regexp.MustCompile(unescapedTilda)
-	endingTildaPattern := regexp.MustCompile(endingTilda)
-
 	if len(jsonPointer) == 0 {
 		return nil
 	}
@@ -195,7 +451,7 @@ func IsValidJSONPointer(jsonPointer string) error {
 		return errors.New("non-empty references must begin with a '/' character: " + jsonPointer)
 	}
 	str := jsonPointer[1:]
-	if unescaptedTildaPattern.MatchString(str) {
+	if unescapedTildaPattern.MatchString(str) {
 		return errors.New("unescaped tilda error")
 	}
 	if endingTildaPattern.MatchString(str) {
@@ -221,6 +477,35 @@ func IsValidRelJSONPointer(relJSONPointer string) error {
 	return IsValidJSONPointer(str)
 }
 
+// RFC 6901, section 6 [RFC6901].
+// https://tools.ietf.org/html/rfc6901#section-6
+// A json pointer in a URI fragment starts with '#', and the characters that
+// are not allowed in a fragment are percent-encoded.
+func IsValidJSONPointerURIFragment(fragment string) error {
+	if !strings.HasPrefix(fragment, "#") {
+		return errors.New("json pointer uri fragment must begin with a '#' character: " + fragment)
+	}
+	str := fragment[1:]
+	// The fragment is decoded without the net/url package, which the
+	// TinyGo profile leaves out.
+	pointer := make([]byte, 0, len(str))
+	for i := 0; i < len(str); i++ {
+		if str[i] == '%' {
+			if !isPctEncoded(str[i:]) {
+				return errors.New("invalid percent-encoding in json pointer uri fragment " + fragment)
+			}
+			b, _ := strconv.ParseUint(str[i+1:i+3], 16, 8)
+			pointer = append(pointer, byte(b))
+			i += 2
+		} else if !isAlphaNumeric(str[i]) && !strings.ContainsRune("-._~!$&'()*+,;=:@/?", rune(str[i])) {
+			return errors.New("invalid character in json pointer uri fragment " + fragment)
+		} else {
+			pointer = append(pointer, str[i])
+		}
+	}
+	return IsValidJSONPointer(string(pointer))
+}
+
 // http://www.ecma-international.org/publications/files/ECMA-ST/Ecma-262.pdf
 // https://tools.ietf.org/html/rfc7159
 func IsValidRegex(regex string) error {
@@ -229,3 +514,80 @@ func IsValidRegex(regex string) error {
 	}
 	return nil
 }
+
+// Semantic Versioning 2.0.0
+// https://semver.org/spec/v2.0.0.html
+func IsValidSemver(version string) error {
+	if !semverPattern.MatchString(version) {
+		return errors.New("invalid semantic version " + version)
+	}
+	return nil
+}
+
+// RFC 4122, section 3 [RFC4122].
+// https://tools.ietf.org/html/rfc4122#section-3
+// A UUID consists of 32 hexadecimal digits in groups of 8, 4, 4, 4 and 12,
+// which are separated by hyphens. The digits are case-insensitive.
+func IsValidUUID(uuid string) error {
+	if len(uuid) != 36 {
+		return errors.New("invalid uuid " + uuid)
+	}
+	for i := 0; i < len(uuid); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if uuid[i] != '-' {
+				return errors.New("invalid uuid " + uuid)
+			}
+		default:
+			if !isHexDigit(uuid[i]) {
+				return errors.New("invalid uuid " + uuid)
+			}
+		}
+	}
+	return nil
+}
+
+// ISO 8601 durations, as specified in RFC 3339, appendix A [RFC3339].
+// https://tools.ietf.org/html/rfc3339#appendix-A
+// Weeks cannot be combined with other units, and a duration must have at
+// least one unit (so "P" and "PT" are invalid).
+func IsValidDuration(duration string) error {
+	if !durationPattern.MatchString(duration) {
+		return errors.New("invalid duration " + duration)
+	}
+	return nil
+}
+
+// ITU-T Recommendation E.164
+// https://www.itu.int/rec/T-REC-E.164
+// A phone number in E.164 form consists of a '+' sign followed by up to 15
+// digits, where the country code never starts with 0.
+func IsValidE164(phone string) error {
+	if !e164Pattern.MatchString(phone) {
+		return errors.New("invalid E.164 phone number " + phone)
+	}
+	return nil
+}
+
+// RFC 4648, section 4 [RFC4648].
+// https://tools.ietf.org/html/rfc4648#section-4
+func IsValidBase64(encoded string) error {
+	if _, err := base64.StdEncoding.Strict().DecodeString(encoded); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RFC 4648, section 5 [RFC4648].
+// https://tools.ietf.org/html/rfc4648#section-5
+// The padding is optional, since it is commonly omitted in URLs.
+func IsValidBase64URL(encoded string) error {
+	encoding := base64.URLEncoding
+	if !strings.HasSuffix(encoded, "=") {
+		encoding = base64.RawURLEncoding
+	}
+	if _, err := encoding.Strict().DecodeString(encoded); err != nil {
+		return err
+	}
+	return nil
+}
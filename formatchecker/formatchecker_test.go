@@ -1,6 +1,7 @@
 package formatchecker_test
 
 import (
+	"strings"
 	"testing"
 	"github.com/itayankri/gojsonvalidator/formatchecker"
 )
@@ -34,6 +35,14 @@ const (
 	FORMAT_JSON_POINTER          = "json-pointer"
 	FORMAT_RELATIVE_JSON_POINTER = "relative-json-pointer"
 	FORMAT_REGEX                 = "regex"
+	FORMAT_BASE64                = "base64"
+	FORMAT_BASE64_URL            = "base64url"
+	FORMAT_E164                  = "e164"
+	FORMAT_SEMVER                = "semver"
+	FORMAT_UUID                  = "uuid"
+	FORMAT_DURATION              = "duration"
+
+	FORMAT_JSON_POINTER_URI_FRAGMENT = "json-pointer-uri-fragment"
 )
 
 func TestIsValidDateTime(t *testing.T) {
@@ -120,6 +129,35 @@ func TestIsValidTime(t *testing.T) {
 	isValidFormat(t, testCases, FORMAT_TIME, formatchecker.IsValidTime)
 }
 
+func TestIsValidTimeEdgeCases(t *testing.T) {
+	testCases := []test{
+		{description: "a valid leap second", data: "23:59:60Z", valid: true},
+		{description: "a valid leap second with a positive offset", data: "01:29:60+01:30", valid: true},
+		{description: "a valid leap second with a negative offset", data: "15:59:60-08:00", valid: true},
+		{description: "a leap second at the wrong hour", data: "22:59:60Z", valid: false},
+		{description: "a leap second at the wrong minute", data: "23:58:60Z", valid: false},
+		{description: "lower case z", data: "12:00:00z", valid: true},
+		{description: "an invalid offset hour", data: "12:00:00+24:00", valid: false},
+		{description: "an invalid offset minute", data: "12:00:00+00:60", valid: false},
+		{description: "a missing offset", data: "12:00:00", valid: false},
+		{description: "non-ascii digits", data: "1২:00:00Z", valid: false},
+	}
+	isValidFormat(t, testCases, FORMAT_TIME, formatchecker.IsValidTime)
+}
+
+func TestIsValidDateTimeEdgeCases(t *testing.T) {
+	testCases := []test{
+		{description: "a valid leap second", data: "1998-12-31T23:59:60Z", valid: true},
+		{description: "a leap second at the wrong time", data: "1998-12-31T22:59:60Z", valid: false},
+		{description: "lower case t and z", data: "1963-06-19t08:30:06.283185z", valid: true},
+		{description: "a leap day", data: "2020-02-29T00:00:00Z", valid: true},
+		{description: "a leap day in a non-leap year", data: "2021-02-29T00:00:00Z", valid: false},
+		{description: "an invalid day in month", data: "2020-04-31T00:00:00Z", valid: false},
+		{description: "a missing separator", data: "2020-01-01 00:00:00Z", valid: false},
+	}
+	isValidFormat(t, testCases, FORMAT_DATE_TIME, formatchecker.IsValidDateTime)
+}
+
 func TestIsValidEmail(t *testing.T) {
 	testCases := []test{
 		{
@@ -147,6 +185,16 @@ func TestIsValidEmail(t *testing.T) {
 			data:        "",
 			valid:       false,
 		},
+		{
+			description: "a valid email address with a quoted local part",
+			data:        "\"john doe\"@example.com",
+			valid:       true,
+		},
+		{
+			description: "an invalid email address with consecutive dots",
+			data:        "john..doe@example.com",
+			valid:       false,
+		},
 	}
 	isValidFormat(t, testCases, FORMAT_EMAIL, formatchecker.IsValidEmail)
 }
@@ -173,6 +221,51 @@ func TestIsValidIdnEmail(t *testing.T) {
 			data:        "",
 			valid:       false,
 		},
+		{
+			description: "a valid idn email with a UTF-8 local part",
+			data:        "用户@例子.广告",
+			valid:       true,
+		},
+		{
+			description: "a valid idn email with a quoted local part",
+			data:        "\"jöhn doe\"@example.com",
+			valid:       true,
+		},
+		{
+			description: "a valid idn email with an A-label domain",
+			data:        "user@xn--4gbwdl.xn--wgbh1c",
+			valid:       true,
+		},
+		{
+			description: "a valid idn email with an address literal",
+			data:        "user@[IPv6:2001:db8::1]",
+			valid:       true,
+		},
+		{
+			description: "an idn email with an invalid A-label",
+			data:        "user@xn--a.com",
+			valid:       false,
+		},
+		{
+			description: "an idn email with a label that ends with a hyphen",
+			data:        "user@例子-.com",
+			valid:       false,
+		},
+		{
+			description: "an idn email with an empty label",
+			data:        "user@example..com",
+			valid:       false,
+		},
+		{
+			description: "an idn email with a control character in the local part",
+			data:        "jo\u0085hn@example.com",
+			valid:       false,
+		},
+		{
+			description: "an idn email with a local part longer than 64 octets",
+			data:        strings.Repeat("ü", 33) + "@example.com",
+			valid:       false,
+		},
 	}
 	isValidFormat(t, testCases, FORMAT_IDN_EMAIL, formatchecker.IsValidIdnEmail)
 }
@@ -210,6 +303,27 @@ func TestIsValidHostname(t *testing.T) {
 	isValidFormat(t, testCases, FORMAT_HOSTNAME, formatchecker.IsValidHostname)
 }
 
+func TestIsValidHostnameStrict(t *testing.T) {
+	testCases := []test{
+		{description: "a valid host name", data: "www.example.com", valid: true},
+		{description: "a single label", data: "hostname", valid: true},
+		{description: "a single label starting with a digit", data: "1host", valid: true},
+		{description: "a valid punycode label", data: "xn--4gbwdl.xn--wgbh1c", valid: true},
+		{description: "an empty string", data: "", valid: false},
+		{description: "a single dot", data: ".", valid: false},
+		{description: "a trailing dot", data: "example.com.", valid: false},
+		{description: "an empty label", data: "example..com", valid: false},
+		{description: "a label starting with a hyphen", data: "-hostname", valid: false},
+		{description: "a label ending with a hyphen", data: "hostname-", valid: false},
+		{description: "an underscore", data: "host_name", valid: false},
+		{description: "hyphens in the third and fourth positions", data: "XN--aa---o47jg78q", valid: true},
+		{description: "hyphens in the third and fourth positions without xn", data: "ab--cd.com", valid: false},
+		{description: "a label longer than 63 characters", data: strings.Repeat("a", 64) + ".com", valid: false},
+		{description: "a hostname longer than 253 characters", data: strings.Repeat(strings.Repeat("a", 63)+".", 4) + "com", valid: false},
+	}
+	isValidFormat(t, testCases, FORMAT_HOSTNAME, formatchecker.IsValidHostnameStrict)
+}
+
 func TestIsValidIdnHostname(t *testing.T) {
 	testCases := []test{
 		{
@@ -253,6 +367,36 @@ func TestIsValidIPv4(t *testing.T) {
 			data:        "127",
 			valid:       false,
 		},
+		{
+			description: "leading zeros are not allowed",
+			data:        "087.10.0.1",
+			valid:       false,
+		},
+		{
+			description: "a single zero octet is allowed",
+			data:        "0.0.0.0",
+			valid:       true,
+		},
+		{
+			description: "shorthand with three components",
+			data:        "127.0.1",
+			valid:       false,
+		},
+		{
+			description: "an empty component",
+			data:        "127..0.1",
+			valid:       false,
+		},
+		{
+			description: "a signed component",
+			data:        "+1.2.3.4",
+			valid:       false,
+		},
+		{
+			description: "an IPv4-mapped IPv6 address",
+			data:        "::ffff:192.168.0.1",
+			valid:       false,
+		},
 	}
 	isValidFormat(t, testCases, FORMAT_IPV4, formatchecker.IsValidIPv4)
 }
@@ -279,6 +423,26 @@ func TestIsValidIPv6(t *testing.T) {
 			data:        "::string",
 			valid:       false,
 		},
+		{
+			description: "a full IPv6 address",
+			data:        "1:2:3:4:5:6:7:8",
+			valid:       true,
+		},
+		{
+			description: "an IPv4-mapped IPv6 address",
+			data:        "::ffff:192.168.0.1",
+			valid:       true,
+		},
+		{
+			description: "two compressed parts",
+			data:        "1::2::3",
+			valid:       false,
+		},
+		{
+			description: "IPv6 with a zone",
+			data:        "fe80::1%eth0",
+			valid:       false,
+		},
 	}
 	isValidFormat(t, testCases, FORMAT_IPV6, formatchecker.IsValidIPv6)
 }
@@ -315,6 +479,16 @@ func TestIsValidURI(t *testing.T) {
 			data:        ":// houldfail",
 			valid:       false,
 		},
+		{
+			description: "an invalid URI with a non-numeric port",
+			data:        "http://example.com:8o/",
+			valid:       false,
+		},
+		{
+			description: "an invalid URI with a malformed percent-encoding",
+			data:        "http://example.com/%zz",
+			valid:       false,
+		},
 	}
 	isValidFormat(t, testCases, FORMAT_URI, formatchecker.IsValidURI)
 
@@ -426,6 +600,76 @@ func TestIsValidURITemplate(t *testing.T) {
 			data:        "http://example.com/dictionary/{term:1}/{term",
 			valid:       false,
 		},
+		{
+			description: "operators and multiple variables",
+			data:        "{+path}/here{?x,y}{&z}{#frag}{.ext}{/seg*}{;keys*}",
+			valid:       true,
+		},
+		{
+			description: "a dotted and percent-encoded variable name",
+			data:        "{a.b%20c}",
+			valid:       true,
+		},
+		{
+			description: "a prefix modifier of four digits",
+			data:        "{var:9999}",
+			valid:       true,
+		},
+		{
+			description: "a percent-encoded literal",
+			data:        "/a%20b/{x}",
+			valid:       true,
+		},
+		{
+			description: "a trailing comma in the variable list",
+			data:        "{a,}",
+			valid:       false,
+		},
+		{
+			description: "a non-numeric prefix",
+			data:        "{;x:abc}",
+			valid:       false,
+		},
+		{
+			description: "a prefix modifier starting with zero",
+			data:        "{x:0}",
+			valid:       false,
+		},
+		{
+			description: "a prefix modifier of five digits",
+			data:        "{x:10000}",
+			valid:       false,
+		},
+		{
+			description: "an empty expression",
+			data:        "{}",
+			valid:       false,
+		},
+		{
+			description: "a reserved operator",
+			data:        "{=x}",
+			valid:       false,
+		},
+		{
+			description: "nested braces",
+			data:        "{a{b}}",
+			valid:       false,
+		},
+		{
+			description: "an unopened expression",
+			data:        "/a}",
+			valid:       false,
+		},
+		{
+			description: "an invalid percent-encoding",
+			data:        "/a%2",
+			valid:       false,
+		},
+		{
+			description: "a space in a literal",
+			data:        "/a b/{x}",
+			valid:       false,
+		},
 	}
 	isValidFormat(t, testCases, FORMAT_URI_TEMPLATE, formatchecker.IsValidURITemplate)
 }
@@ -533,6 +777,273 @@ func TestIsValidRegex(t *testing.T) {
 	isValidFormat(t, testCases, FORMAT_REGEX, formatchecker.IsValidRegex)
 }
 
+func TestIsValidSemver(t *testing.T) {
+	testCases := []test{
+		{
+			description: "a valid version",
+			data:        "1.2.3",
+			valid:       true,
+		},
+		{
+			description: "a valid version with prerelease and build metadata",
+			data:        "1.0.0-alpha.1+build.5.sha-3f2a",
+			valid:       true,
+		},
+		{
+			description: "a valid version with a numeric prerelease",
+			data:        "1.0.0-0.3.7",
+			valid:       true,
+		},
+		{
+			description: "missing patch version",
+			data:        "1.2",
+			valid:       false,
+		},
+		{
+			description: "leading zero in major version",
+			data:        "01.2.3",
+			valid:       false,
+		},
+		{
+			description: "leading zero in numeric prerelease identifier",
+			data:        "1.2.3-01",
+			valid:       false,
+		},
+		{
+			description: "empty prerelease identifier",
+			data:        "1.2.3-alpha..1",
+			valid:       false,
+		},
+		{
+			description: "a 'v' prefix",
+			data:        "v1.2.3",
+			valid:       false,
+		},
+	}
+	isValidFormat(t, testCases, FORMAT_SEMVER, formatchecker.IsValidSemver)
+}
+
+func TestIsValidE164(t *testing.T) {
+	testCases := []test{
+		{
+			description: "a valid phone number",
+			data:        "+14155552671",
+			valid:       true,
+		},
+		{
+			description: "a valid phone number with 15 digits",
+			data:        "+442071838750123",
+			valid:       true,
+		},
+		{
+			description: "missing '+' prefix",
+			data:        "14155552671",
+			valid:       false,
+		},
+		{
+			description: "country code starting with 0",
+			data:        "+04155552671",
+			valid:       false,
+		},
+		{
+			description: "more than 15 digits",
+			data:        "+1415555267112345",
+			valid:       false,
+		},
+		{
+			description: "formatting characters",
+			data:        "+1 (415) 555-2671",
+			valid:       false,
+		},
+	}
+	isValidFormat(t, testCases, FORMAT_E164, formatchecker.IsValidE164)
+}
+
+func TestIsValidBase64(t *testing.T) {
+	testCases := []test{
+		{
+			description: "a valid base64 string",
+			data:        "aGVsbG8gd29ybGQ=",
+			valid:       true,
+		},
+		{
+			description: "an empty string",
+			data:        "",
+			valid:       true,
+		},
+		{
+			description: "missing padding",
+			data:        "aGVsbG8gd29ybGQ",
+			valid:       false,
+		},
+		{
+			description: "url-safe alphabet",
+			data:        "-_-_",
+			valid:       false,
+		},
+		{
+			description: "invalid characters",
+			data:        "aGVsbG8*d29ybGQ=",
+			valid:       false,
+		},
+	}
+	isValidFormat(t, testCases, FORMAT_BASE64, formatchecker.IsValidBase64)
+}
+
+func TestIsValidBase64URL(t *testing.T) {
+	testCases := []test{
+		{
+			description: "a valid padded base64url string",
+			data:        "PDw_Pz8-Pg==",
+			valid:       true,
+		},
+		{
+			description: "a valid unpadded base64url string",
+			data:        "PDw_Pz8-Pg",
+			valid:       true,
+		},
+		{
+			description: "standard alphabet",
+			data:        "PDw/Pz8+Pg==",
+			valid:       false,
+		},
+	}
+	isValidFormat(t, testCases, FORMAT_BASE64_URL, formatchecker.IsValidBase64URL)
+}
+
+func TestIsValidUUID(t *testing.T) {
+	testCases := []test{
+		{
+			description: "a valid uuid",
+			data:        "2eb8aa08-aa98-11ea-b4aa-73b441d16380",
+			valid:       true,
+		},
+		{
+			description: "upper case hexadecimal digits",
+			data:        "2EB8AA08-AA98-11EA-B4AA-73B441D16380",
+			valid:       true,
+		},
+		{
+			description: "hyphens in the wrong places",
+			data:        "2eb8aa08a-a98-11ea-b4aa-73b441d16380",
+			valid:       false,
+		},
+		{
+			description: "no hyphens",
+			data:        "2eb8aa08aa9811eab4aa73b441d16380",
+			valid:       false,
+		},
+		{
+			description: "a non-hexadecimal digit",
+			data:        "2eb8aa08-aa98-11ea-b4aa-73b441d1638g",
+			valid:       false,
+		},
+		{
+			description: "braces",
+			data:        "{2eb8aa08-aa98-11ea-b4aa-73b441d16380}",
+			valid:       false,
+		},
+	}
+	isValidFormat(t, testCases, FORMAT_UUID, formatchecker.IsValidUUID)
+}
+
+func TestIsValidDuration(t *testing.T) {
+	testCases := []test{
+		{
+			description: "a full duration",
+			data:        "P4Y1M2DT12H30M5S",
+			valid:       true,
+		},
+		{
+			description: "days only",
+			data:        "P1D",
+			valid:       true,
+		},
+		{
+			description: "a time only",
+			data:        "PT36H",
+			valid:       true,
+		},
+		{
+			description: "weeks",
+			data:        "P2W",
+			valid:       true,
+		},
+		{
+			description: "no units",
+			data:        "P",
+			valid:       false,
+		},
+		{
+			description: "a time without units",
+			data:        "P1DT",
+			valid:       false,
+		},
+		{
+			description: "units out of order",
+			data:        "P1D2Y",
+			valid:       false,
+		},
+		{
+			description: "weeks combined with days",
+			data:        "P1W1D",
+			valid:       false,
+		},
+		{
+			description: "no leading P",
+			data:        "1D",
+			valid:       false,
+		},
+	}
+	isValidFormat(t, testCases, FORMAT_DURATION, formatchecker.IsValidDuration)
+}
+
+func TestIsValidJSONPointerURIFragment(t *testing.T) {
+	testCases := []test{
+		{
+			description: "the whole document",
+			data:        "#",
+			valid:       true,
+		},
+		{
+			description: "a valid pointer",
+			data:        "#/definitions/address",
+			valid:       true,
+		},
+		{
+			description: "a percent-encoded character",
+			data:        "#/c%25d",
+			valid:       true,
+		},
+		{
+			description: "an escaped tilda",
+			data:        "#/a~1b",
+			valid:       true,
+		},
+		{
+			description: "no leading #",
+			data:        "/definitions/address",
+			valid:       false,
+		},
+		{
+			description: "a character that is not allowed in a fragment",
+			data:        "#/c d",
+			valid:       false,
+		},
+		{
+			description: "an invalid percent-encoding",
+			data:        "#/c%2",
+			valid:       false,
+		},
+		{
+			description: "an unescaped tilda",
+			data:        "#/a~2b",
+			valid:       false,
+		},
+	}
+	isValidFormat(t, testCases, FORMAT_JSON_POINTER_URI_FRAGMENT, formatchecker.IsValidJSONPointerURIFragment)
+}
+
 func isValidFormat(t *testing.T, tests []test, formatType string, fn format) {
 	t.Logf("Given the need to test %s format", formatType)
 	{
@@ -555,3 +1066,32 @@ func isValidFormat(t *testing.T, tests []test, formatType string, fn format) {
 		}
 	}
 }
+
+func TestRegistry(t *testing.T) {
+	registry := formatchecker.NewRegistry()
+	registry.Register(FORMAT_SEMVER, formatchecker.CheckerFunc(formatchecker.IsValidSemver))
+	registry.Register(FORMAT_E164, formatchecker.CheckerFunc(formatchecker.IsValidE164))
+
+	if names := registry.List(); len(names) != 2 || names[0] != FORMAT_E164 || names[1] != FORMAT_SEMVER {
+		t.Errorf("unexpected registered formats %v", names)
+	}
+
+	checker, ok := registry.Get(FORMAT_SEMVER)
+	if !ok {
+		t.Fatalf("expected %s to be registered", FORMAT_SEMVER)
+	}
+	if err := checker.Check("1.0.0"); err != nil {
+		t.Errorf("expected a valid semver, got %v", err)
+	}
+	if _, ok := registry.Get(FORMAT_DATE); ok {
+		t.Errorf("expected %s not to be registered", FORMAT_DATE)
+	}
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	for _, name := range []string{FORMAT_DATE_TIME, FORMAT_HOSTNAME, FORMAT_URI_TEMPLATE, FORMAT_BASE64_URL, FORMAT_UUID, FORMAT_DURATION, FORMAT_JSON_POINTER_URI_FRAGMENT} {
+		if _, ok := formatchecker.Get(name); !ok {
+			t.Errorf("expected %s to be registered by default", name)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package formatchecker
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The parameters of punycode, from RFC 3492, section 5 [RFC3492].
+// https://tools.ietf.org/html/rfc3492#section-5
+const (
+	punycodeBase        = 36
+	punycodeTMin        = 1
+	punycodeTMax        = 26
+	punycodeSkew        = 38
+	punycodeDamp        = 700
+	punycodeInitialBias = 72
+	punycodeInitialN    = 128
+	punycodeMaxInt      = 1<<31 - 1
+)
+
+// The prefix of the A-labels of internationalized domain names.
+const aceprefix = "xn--"
+
+// The dots that separate the labels of internationalized domain names, from
+// RFC 3490, section 3.1 [RFC3490].
+const idnaDots = ".。．｡"
+
+// splitIdnaLabels splits a domain into its labels, at any of the IDNA dots.
+func splitIdnaLabels(domain string) []string {
+	var labels []string
+	start := 0
+	for index, r := range domain {
+		if strings.ContainsRune(idnaDots, r) {
+			labels = append(labels, domain[start:index])
+			start = index + utf8.RuneLen(r)
+		}
+	}
+
+	return append(labels, domain[start:])
+}
+
+// toALabel returns the A-label of a domain label, which is the label itself
+// if it is ASCII, or the punycode of a U-label with the "xn--" prefix. The
+// label must follow the rules of RFC 5891, section 5.4 [RFC5891]: it is not
+// empty and is at most 63 octets long in its A-label form, it does not start
+// or end with a hyphen, it has no hyphens in both the third and the fourth
+// positions unless it is an A-label whose punycode is valid, and a U-label
+// does not start with a combining mark.
+func toALabel(label string) (string, error) {
+	if label == "" {
+		return "", errors.New("empty domain label")
+	}
+	if !utf8.ValidString(label) {
+		return "", errors.New("domain label " + label + " is not valid UTF-8")
+	}
+	if label[0] == '-' || label[len(label)-1] == '-' {
+		return "", errors.New("domain label " + label + " starts or ends with a hyphen")
+	}
+
+	aLabel := label
+	if isASCII(label) {
+		if !isLDHLabel(label) {
+			return "", errors.New("domain label " + label + " contains invalid characters")
+		}
+
+		if len(label) >= 4 && label[2:4] == "--" {
+			if !strings.EqualFold(label[:4], aceprefix) {
+				return "", errors.New("domain label " + label + " has \"--\" in the third and fourth positions")
+			}
+
+			uLabel, err := punycodeDecode(strings.ToLower(label[4:]))
+			if err != nil || isASCII(uLabel) {
+				return "", errors.New("domain label " + label + " is not a valid A-label")
+			}
+			if _, err := toALabel(uLabel); err != nil {
+				return "", err
+			}
+		}
+	} else {
+		first, _ := utf8.DecodeRuneInString(label)
+		if unicode.Is(unicode.M, first) {
+			return "", errors.New("domain label " + label + " starts with a combining mark")
+		}
+		if len(label) >= 4 && label[2:4] == "--" {
+			return "", errors.New("domain label " + label + " has \"--\" in the third and fourth positions")
+		}
+
+		for _, r := range label {
+			if r < 0x80 && r != '-' && !isAlphaNumeric(byte(r)) || unicode.IsControl(r) || unicode.IsSpace(r) {
+				return "", errors.New("domain label " + label + " contains invalid characters")
+			}
+		}
+		if err := IsValidIdnHostname(label); err != nil {
+			return "", err
+		}
+
+		encoded, err := punycodeEncode(label)
+		if err != nil {
+			return "", err
+		}
+		aLabel = aceprefix + encoded
+	}
+
+	if len(aLabel) > 63 {
+		return "", errors.New("domain label " + label + " is too long (more than 63 octets)")
+	}
+
+	return aLabel, nil
+}
+
+// isLDHLabel returns true if the label consists of letters, digits and
+// hyphens.
+func isLDHLabel(label string) bool {
+	for index := 0; index < len(label); index++ {
+		if !isAlphaNumeric(label[index]) && label[index] != '-' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isASCII(s string) bool {
+	for index := 0; index < len(s); index++ {
+		if s[index] >= 0x80 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// punycodeEncode returns the punycode of a string, from RFC 3492, section
+// 6.3 [RFC3492].
+// https://tools.ietf.org/html/rfc3492#section-6.3
+func punycodeEncode(input string) (string, error) {
+	runes := []rune(input)
+
+	var output strings.Builder
+	for _, r := range runes {
+		if r < 0x80 {
+			output.WriteRune(r)
+		}
+	}
+
+	basic := output.Len()
+	handled := basic
+	if basic > 0 {
+		output.WriteByte('-')
+	}
+
+	n, delta, bias := rune(punycodeInitialN), 0, punycodeInitialBias
+	for handled < len(runes) {
+		m := rune(unicode.MaxRune + 1)
+		for _, r := range runes {
+			if r >= n && r < m {
+				m = r
+			}
+		}
+
+		if int(m-n) > (punycodeMaxInt-delta)/(handled+1) {
+			return "", errors.New("punycode overflow")
+		}
+		delta += int(m-n) * (handled + 1)
+		n = m
+
+		for _, r := range runes {
+			if r < n {
+				delta++
+			}
+			if r != n {
+				continue
+			}
+
+			q := delta
+			for k := punycodeBase; ; k += punycodeBase {
+				t := punycodeThreshold(k, bias)
+				if q < t {
+					break
+				}
+				output.WriteByte(punycodeDigit(t + (q-t)%(punycodeBase-t)))
+				q = (q - t) / (punycodeBase - t)
+			}
+			output.WriteByte(punycodeDigit(q))
+
+			bias = punycodeAdapt(delta, handled+1, handled == basic)
+			delta = 0
+			handled++
+		}
+
+		delta++
+		n++
+	}
+
+	return output.String(), nil
+}
+
+// punycodeDecode returns the string of a punycode, from RFC 3492, section
+// 6.2 [RFC3492].
+// https://tools.ietf.org/html/rfc3492#section-6.2
+func punycodeDecode(input string) (string, error) {
+	var output []rune
+	position := 0
+	if index := strings.LastIndexByte(input, '-'); index >= 0 {
+		for _, r := range input[:index] {
+			if r >= 0x80 {
+				return "", errors.New("invalid punycode " + input)
+			}
+			output = append(output, r)
+		}
+		position = index + 1
+	}
+
+	n, i, bias := rune(punycodeInitialN), 0, punycodeInitialBias
+	for position < len(input) {
+		oldI, w := i, 1
+		for k := punycodeBase; ; k += punycodeBase {
+			if position == len(input) {
+				return "", errors.New("invalid punycode " + input)
+			}
+
+			digit, ok := punycodeValue(input[position])
+			position++
+			if !ok || digit > (punycodeMaxInt-i)/w {
+				return "", errors.New("invalid punycode " + input)
+			}
+			i += digit * w
+
+			t := punycodeThreshold(k, bias)
+			if digit < t {
+				break
+			}
+			w *= punycodeBase - t
+		}
+
+		length := len(output) + 1
+		bias = punycodeAdapt(i-oldI, length, oldI == 0)
+		n += rune(i / length)
+		i %= length
+		if n > unicode.MaxRune || (0xD800 <= n && n <= 0xDFFF) {
+			return "", errors.New("invalid punycode " + input)
+		}
+
+		output = append(output, 0)
+		copy(output[i+1:], output[i:])
+		output[i] = n
+		i++
+	}
+
+	return string(output), nil
+}
+
+func punycodeThreshold(k int, bias int) int {
+	switch {
+	case k <= bias:
+		return punycodeTMin
+	case k >= bias+punycodeTMax:
+		return punycodeTMax
+	default:
+		return k - bias
+	}
+}
+
+func punycodeAdapt(delta int, points int, first bool) int {
+	if first {
+		delta /= punycodeDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / points
+
+	k := 0
+	for delta > ((punycodeBase-punycodeTMin)*punycodeTMax)/2 {
+		delta /= punycodeBase - punycodeTMin
+		k += punycodeBase
+	}
+
+	return k + (punycodeBase-punycodeTMin+1)*delta/(delta+punycodeSkew)
+}
+
+func punycodeDigit(digit int) byte {
+	if digit < 26 {
+		return byte('a' + digit)
+	}
+
+	return byte('0' + digit - 26)
+}
+
+func punycodeValue(c byte) (int, bool) {
+	switch {
+	case 'a' <= c && c <= 'z':
+		return int(c - 'a'), true
+	case 'A' <= c && c <= 'Z':
+		return int(c - 'A'), true
+	case '0' <= c && c <= '9':
+		return int(c-'0') + 26, true
+	default:
+		return 0, false
+	}
+}
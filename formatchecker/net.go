@@ -0,0 +1,28 @@
+//go:build !tinygo && !jsonvalidator_tiny
+// +build !tinygo,!jsonvalidator_tiny
+
+package formatchecker
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+)
+
+// The checks that are based on the net packages of the standard library,
+// which are replaced by lighter implementations in the TinyGo profile (see
+// net_tiny.go).
+
+func parseMailAddress(address string) error {
+	_, err := mail.ParseAddress(address)
+	return err
+}
+
+func parseIP(ip string) bool {
+	return net.ParseIP(ip) != nil
+}
+
+func parseURIReference(uriRef string) error {
+	_, err := url.Parse(uriRef)
+	return err
+}
@@ -0,0 +1,312 @@
+//go:build tinygo || jsonvalidator_tiny
+// +build tinygo jsonvalidator_tiny
+
+package formatchecker
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// The TinyGo profile replaces the checks that are based on the net packages
+// of the standard library (see net.go), which are large and only partly
+// supported by TinyGo, with the implementations below. They accept the same
+// values as the standard library for the formats of this package, except
+// that email addresses must be given without a display name.
+
+// parseMailAddress accepts an RFC 5322 addr-spec, whose local part may also
+// hold UTF-8 characters as RFC 6531 allows.
+func parseMailAddress(address string) error {
+	at := strings.LastIndexByte(address, '@')
+	if at == -1 {
+		return errors.New("mail: missing @ in addr-spec")
+	}
+
+	local, domain := address[:at], address[at+1:]
+	if strings.HasPrefix(local, `"`) {
+		if !isQuotedString(local) {
+			return errors.New("mail: invalid quoted-string in addr-spec")
+		}
+	} else if !isDotAtom(local) {
+		return errors.New("mail: invalid local part in addr-spec")
+	}
+
+	if strings.HasPrefix(domain, "[") {
+		if !strings.HasSuffix(domain, "]") || strings.ContainsAny(domain[1:len(domain)-1], "[]\\") {
+			return errors.New("mail: invalid domain literal in addr-spec")
+		}
+	} else if !isDotAtom(domain) {
+		return errors.New("mail: invalid domain in addr-spec")
+	}
+
+	return nil
+}
+
+// isDotAtom returns true if s is a non-empty sequence of atoms that are
+// separated by single dots.
+func isDotAtom(s string) bool {
+	for _, atom := range strings.Split(s, ".") {
+		if atom == "" {
+			return false
+		}
+
+		for index := 0; index < len(atom); index++ {
+			c := atom[index]
+			isAtext := c >= 0x80 ||
+				'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+				strings.IndexByte("!#$%&'*+-/=?^_`{|}~", c) != -1
+			if !isAtext {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isQuotedString returns true if s is a quoted-string without folding white
+// space.
+func isQuotedString(s string) bool {
+	if len(s) < 2 || s[len(s)-1] != '"' {
+		return false
+	}
+
+	for index := 1; index < len(s)-1; index++ {
+		switch c := s[index]; {
+		case c == '\\':
+			index++
+			if index == len(s)-1 {
+				return false
+			}
+		case c == '"' || c < 0x20 && c != '\t' || c == 0x7F:
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseIP returns true if ip is an IPv4 address in its dotted-quad form, or
+// an IPv6 address in one of the text representations of RFC 4291, section
+// 2.2, without a zone.
+func parseIP(ip string) bool {
+	if !strings.Contains(ip, ":") {
+		return IsValidIPv4(ip) == nil
+	}
+
+	// The groups before and after the "::" (if any) are counted separately.
+	head, tail := ip, ""
+	compressed := false
+	if index := strings.Index(ip, "::"); index != -1 {
+		head, tail = ip[:index], ip[index+2:]
+		compressed = true
+	}
+
+	headGroups, ok := countIPv6Groups(head, !compressed)
+	if !ok {
+		return false
+	}
+
+	tailGroups, ok := countIPv6Groups(tail, true)
+	if !compressed || !ok {
+		return ok && headGroups == 8
+	}
+
+	// "::" stands for at least one group of zeros.
+	return headGroups+tailGroups <= 7
+}
+
+// countIPv6Groups returns the number of 16-bit groups in a part of an IPv6
+// address, where an IPv4 address counts as two groups and is allowed only at
+// the end of the address (if last is true).
+func countIPv6Groups(part string, last bool) (int, bool) {
+	if part == "" {
+		return 0, true
+	}
+
+	groups := strings.Split(part, ":")
+	for index, group := range groups {
+		if index == len(groups)-1 && last && strings.Contains(group, ".") {
+			return len(groups) + 1, IsValidIPv4(group) == nil
+		}
+
+		if len(group) == 0 || len(group) > 4 {
+			return 0, false
+		}
+
+		for _, c := range []byte(group) {
+			if !isHexDigit(c) {
+				return 0, false
+			}
+		}
+	}
+
+	return len(groups), true
+}
+
+// parseURIReference returns an error if uriRef cannot be parsed as a URI
+// reference. Like url.Parse, it checks the characters of the host, the
+// port and the percent-encoding of the path and the fragment, and does not
+// check the query.
+func parseURIReference(uriRef string) error {
+	for index := 0; index < len(uriRef); index++ {
+		if uriRef[index] < 0x20 || uriRef[index] == 0x7F {
+			return errors.New("invalid control character in URL")
+		}
+	}
+
+	rest := uriRef
+	if index := strings.IndexByte(rest, '#'); index != -1 {
+		if !isValidEscaping(rest[index+1:]) {
+			return errors.New("invalid URL escape in fragment")
+		}
+		rest = rest[:index]
+	}
+
+	if index := strings.IndexByte(rest, '?'); index != -1 {
+		rest = rest[:index]
+	}
+
+	scheme, hasScheme := uriScheme(rest)
+	if hasScheme {
+		if scheme == "" {
+			return errors.New("missing protocol scheme")
+		}
+
+		rest = rest[len(scheme)+1:]
+
+		// A URI whose hierarchical part does not start with a '/' is opaque,
+		// and its content is not checked.
+		if !strings.HasPrefix(rest, "/") {
+			return nil
+		}
+	} else {
+		segment := rest
+		if index := strings.IndexByte(segment, '/'); index != -1 {
+			segment = segment[:index]
+		}
+		if strings.Contains(segment, ":") {
+			return errors.New("first path segment in URL cannot contain colon")
+		}
+	}
+
+	if strings.HasPrefix(rest, "//") {
+		authority := rest[2:]
+		rest = ""
+		if index := strings.IndexByte(authority, '/'); index != -1 {
+			authority, rest = authority[:index], authority[index:]
+		}
+
+		if err := checkAuthority(authority); err != nil {
+			return err
+		}
+	}
+
+	if !isValidEscaping(rest) {
+		return errors.New("invalid URL escape in path")
+	}
+
+	return nil
+}
+
+// uriScheme returns the scheme of a URI reference. hasScheme is false if the
+// reference does not start with a scheme, which is the case for relative
+// references, and the scheme is empty if the reference starts with a colon.
+func uriScheme(uriRef string) (scheme string, hasScheme bool) {
+	for index := 0; index < len(uriRef); index++ {
+		switch c := uriRef[index]; {
+		case 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' || c == '+' || c == '-' || c == '.':
+			if index == 0 {
+				return "", false
+			}
+		case c == ':':
+			return uriRef[:index], true
+		default:
+			return "", false
+		}
+	}
+
+	return "", false
+}
+
+// checkAuthority checks the userinfo, the host and the port of the
+// authority component of a URI.
+func checkAuthority(authority string) error {
+	host := authority
+	if index := strings.LastIndexByte(authority, '@'); index != -1 {
+		userinfo := authority[:index]
+		host = authority[index+1:]
+		for index := 0; index < len(userinfo); index++ {
+			c := userinfo[index]
+			if !isAlphaNumeric(c) && strings.IndexByte("-._:~!$&'()*+,;=%@", c) == -1 {
+				return errors.New("invalid userinfo")
+			}
+		}
+	}
+
+	// An IP literal is checked only for its brackets.
+	if strings.HasPrefix(host, "[") {
+		end := strings.LastIndexByte(host, ']')
+		if end == -1 {
+			return errors.New("missing ']' in host")
+		}
+		return checkPort(host[end+1:])
+	}
+
+	if index := strings.LastIndexByte(host, ':'); index != -1 {
+		if err := checkPort(host[index:]); err != nil {
+			return err
+		}
+		host = host[:index]
+	}
+
+	if !isValidEscaping(host) {
+		return errors.New("invalid URL escape in host")
+	}
+
+	for index := 0; index < len(host); index++ {
+		c := host[index]
+		if c < 0x80 && !isAlphaNumeric(c) && strings.IndexByte("-._~!$&'()*+,;=:[]<>\"%", c) == -1 {
+			return errors.New("invalid character " + strconv.QuoteRune(rune(c)) + " in host name")
+		}
+	}
+
+	return nil
+}
+
+// checkPort checks that port is empty, or a colon that is followed by
+// decimal digits.
+func checkPort(port string) error {
+	if port == "" {
+		return nil
+	}
+
+	if port[0] != ':' {
+		return errors.New("invalid port " + port + " after host")
+	}
+
+	for index := 1; index < len(port); index++ {
+		if port[index] < '0' || port[index] > '9' {
+			return errors.New("invalid port " + port + " after host")
+		}
+	}
+
+	return nil
+}
+
+// isValidEscaping returns true if every '%' in s starts a percent-encoded
+// byte.
+func isValidEscaping(s string) bool {
+	for index := 0; index < len(s); index++ {
+		if s[index] == '%' {
+			if !isPctEncoded(s[index:]) {
+				return false
+			}
+			index += 2
+		}
+	}
+
+	return true
+}
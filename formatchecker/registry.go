@@ -0,0 +1,116 @@
+package formatchecker
+
+import (
+	"sort"
+	"sync"
+)
+
+// Checker validates that a string conforms to a format. Check returns nil if
+// the string is valid, or an error that describes why it is not.
+type Checker interface {
+	Check(input string) error
+}
+
+// CheckerFunc is an adapter that allows the use of an ordinary function as a
+// Checker.
+type CheckerFunc func(string) error
+
+// Check calls f(input).
+func (f CheckerFunc) Check(input string) error {
+	return f(input)
+}
+
+// Registry is a set of format checkers keyed by their format names. It is
+// safe for concurrent use.
+type Registry struct {
+	mu       sync.RWMutex
+	checkers map[string]Checker
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{checkers: map[string]Checker{}}
+}
+
+// Register adds a checker for the given format name, replacing the checker
+// that was previously registered under that name, if any.
+func (r *Registry) Register(name string, checker Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers[name] = checker
+}
+
+// Get returns the checker registered for the given format name.
+func (r *Registry) Get(name string) (Checker, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	checker, ok := r.checkers[name]
+	return checker, ok
+}
+
+// List returns the sorted names of the registered formats.
+func (r *Registry) List() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.checkers))
+	for name := range r.checkers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultRegistry contains the checkers of all the formats supported by this
+// package. It is the registry that is consulted by the "format" keyword.
+var DefaultRegistry = newDefaultRegistry()
+
+func newDefaultRegistry() *Registry {
+	registry := NewRegistry()
+	for name, checker := range map[string]func(string) error{
+		"date-time":                 IsValidDateTime,
+		"date":                      IsValidDate,
+		"time":                      IsValidTime,
+		"email":                     IsValidEmail,
+		"idn-email":                 IsValidIdnEmail,
+		"hostname":                  IsValidHostname,
+		"idn-hostname":              IsValidIdnHostname,
+		"ipv4":                      IsValidIPv4,
+		"ipv6":                      IsValidIPv6,
+		"uri":                       IsValidURI,
+		"uri-reference":             IsValidUriRef,
+		"iri":                       IsValidIri,
+		"iri-reference":             IsValidIriRef,
+		"uri-template":              IsValidURITemplate,
+		"json-pointer":              IsValidJSONPointer,
+		"relative-json-pointer":     IsValidRelJSONPointer,
+		"json-pointer-uri-fragment": IsValidJSONPointerURIFragment,
+		"regex":                     IsValidRegex,
+		"semver":                    IsValidSemver,
+		"phone":                     IsValidE164,
+		"e164":                      IsValidE164,
+		"base64":                    IsValidBase64,
+		"base64url":                 IsValidBase64URL,
+		"uuid":                      IsValidUUID,
+		"duration":                  IsValidDuration,
+	} {
+		registry.Register(name, CheckerFunc(checker))
+	}
+	return registry
+}
+
+// Register adds a checker for the given format name to the DefaultRegistry.
+func Register(name string, checker Checker) {
+	DefaultRegistry.Register(name, checker)
+}
+
+// Get returns the checker registered for the given format name in the
+// DefaultRegistry.
+func Get(name string) (Checker, bool) {
+	return DefaultRegistry.Get(name)
+}
+
+// List returns the sorted names of the formats registered in the
+// DefaultRegistry.
+func List() []string {
+	return DefaultRegistry.List()
+}
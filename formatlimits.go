@@ -0,0 +1,181 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// The layouts of the formats whose values can be compared by the ajv
+// "formatMinimum", "formatMaximum", "formatExclusiveMinimum" and
+// "formatExclusiveMaximum" keywords. Times may omit their offset, in which
+// case they are in UTC.
+var formatLimitLayouts = map[string][]string{
+	FORMAT_DATE_TIME: {time.RFC3339Nano},
+	FORMAT_DATE:      {"2006-01-02"},
+	FORMAT_TIME:      {"15:04:05.999999999Z07:00", "15:04:05.999999999"},
+}
+
+// formatLimit holds the value of a format limit keyword, which is a value of
+// the sibling "format" keyword, and the parsed limit.
+type formatLimit struct {
+	limit         string
+	parsed        time.Time
+	siblingFormat *format
+}
+
+// parseFormatValue parses a value of a comparable format.
+func parseFormatValue(formatName string, value string) (time.Time, bool) {
+	for _, layout := range formatLimitLayouts[formatName] {
+		parsed, err := time.Parse(layout, strings.ToUpper(value))
+		if err == nil {
+			return parsed, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// compile parses the limit according to the sibling "format" keyword, which
+// must be one of the comparable formats.
+func (fl *formatLimit) compile(schemaPath string, keyword string) error {
+	if fl.siblingFormat == nil || formatLimitLayouts[string(*fl.siblingFormat)] == nil {
+		return SchemaCompilationError{
+			path: schemaPath + "/" + keyword,
+			err:  "\"" + keyword + "\" requires a \"format\" of date-time, date or time",
+		}
+	}
+
+	parsed, ok := parseFormatValue(string(*fl.siblingFormat), fl.limit)
+	if !ok {
+		return SchemaCompilationError{
+			path: schemaPath + "/" + keyword,
+			err:  "\"" + fl.limit + "\" is not a valid " + string(*fl.siblingFormat),
+		}
+	}
+
+	fl.parsed = parsed
+	return nil
+}
+
+// compare compares a value of the validated document to the limit. It
+// returns false if the value is not a string of the format, which is left to
+// the "format" keyword, or if the format is disabled.
+func (fl *formatLimit) compare(jsonData jsonData, ctx *validationContext) (int, bool) {
+	v, ok := jsonData.value.(string)
+	if !ok || fl.siblingFormat == nil || ctx.validator.disabledFormats[string(*fl.siblingFormat)] {
+		return 0, false
+	}
+
+	parsed, ok := parseFormatValue(string(*fl.siblingFormat), v)
+	if !ok {
+		return 0, false
+	}
+
+	switch {
+	case parsed.Before(fl.parsed):
+		return -1, true
+	case parsed.After(fl.parsed):
+		return 1, true
+	}
+	return 0, true
+}
+
+func (fl *formatLimit) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &fl.limit)
+}
+
+func (fl *formatLimit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fl.limit)
+}
+
+type formatMinimum struct {
+	formatLimit
+}
+
+func (fm *formatMinimum) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	if comparison, ok := fm.compare(jsonData, ctx); ok && comparison < 0 {
+		return KeywordValidationError{
+			keyword: "formatMinimum",
+			reason:  "inspected value is before " + fm.limit,
+			params:  map[string]interface{}{"limit": fm.limit},
+		}
+	}
+
+	return nil
+}
+
+type formatMaximum struct {
+	formatLimit
+}
+
+func (fm *formatMaximum) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	if comparison, ok := fm.compare(jsonData, ctx); ok && comparison > 0 {
+		return KeywordValidationError{
+			keyword: "formatMaximum",
+			reason:  "inspected value is after " + fm.limit,
+			params:  map[string]interface{}{"limit": fm.limit},
+		}
+	}
+
+	return nil
+}
+
+type formatExclusiveMinimum struct {
+	formatLimit
+}
+
+func (fem *formatExclusiveMinimum) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	if comparison, ok := fem.compare(jsonData, ctx); ok && comparison <= 0 {
+		return KeywordValidationError{
+			keyword: "formatExclusiveMinimum",
+			reason:  "inspected value is not after " + fem.limit,
+			params:  map[string]interface{}{"limit": fem.limit},
+		}
+	}
+
+	return nil
+}
+
+type formatExclusiveMaximum struct {
+	formatLimit
+}
+
+func (fem *formatExclusiveMaximum) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	if comparison, ok := fem.compare(jsonData, ctx); ok && comparison >= 0 {
+		return KeywordValidationError{
+			keyword: "formatExclusiveMaximum",
+			reason:  "inspected value is not before " + fem.limit,
+			params:  map[string]interface{}{"limit": fem.limit},
+		}
+	}
+
+	return nil
+}
+
+// compileFormatLimits connects the format limit keywords of the schema to
+// its "format" keyword and parses their limits.
+func (js *JsonSchema) compileFormatLimits(schemaPath string) error {
+	var keywords []string
+	var limits []*formatLimit
+	if js.FormatMinimum != nil {
+		keywords, limits = append(keywords, "formatMinimum"), append(limits, &js.FormatMinimum.formatLimit)
+	}
+	if js.FormatMaximum != nil {
+		keywords, limits = append(keywords, "formatMaximum"), append(limits, &js.FormatMaximum.formatLimit)
+	}
+	if js.FormatExclusiveMinimum != nil {
+		keywords, limits = append(keywords, "formatExclusiveMinimum"), append(limits, &js.FormatExclusiveMinimum.formatLimit)
+	}
+	if js.FormatExclusiveMaximum != nil {
+		keywords, limits = append(keywords, "formatExclusiveMaximum"), append(limits, &js.FormatExclusiveMaximum.formatLimit)
+	}
+
+	var errs SchemaCompilationErrors
+	for index, limit := range limits {
+		limit.siblingFormat = js.Format
+		errs = errs.add(limit.compile(schemaPath, keywords[index]))
+	}
+
+	return errs.err()
+}
@@ -0,0 +1,60 @@
+package jsonvalidator
+
+import "testing"
+
+func TestFormatLimits(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"properties": {
+			"date": {"format": "date", "formatMinimum": "2020-01-01", "formatExclusiveMaximum": "2021-01-01"},
+			"timestamp": {"format": "date-time", "formatExclusiveMinimum": "2020-01-01T00:00:00Z"},
+			"opening": {"format": "time", "formatMinimum": "08:00:00Z", "formatMaximum": "18:00:00Z"}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		document string
+		valid    bool
+	}{
+		{`{"date": "2020-01-01"}`, true},
+		{`{"date": "2019-12-31"}`, false},
+		{`{"date": "2020-12-31"}`, true},
+		{`{"date": "2021-01-01"}`, false},
+		{`{"timestamp": "2020-01-01T00:00:00Z"}`, false},
+		{`{"timestamp": "2020-01-01T02:00:00.5+02:00"}`, true},
+		{`{"timestamp": "2020-01-01T00:00:01z"}`, true},
+		{`{"opening": "18:00:00Z"}`, true},
+		{`{"opening": "19:00:00+02:00"}`, true},
+		{`{"opening": "07:59:59Z"}`, false},
+		{`{"date": 20200101}`, true},
+	}
+
+	for _, testCase := range testCases {
+		err := NewValidator(rootSchema).Validate([]byte(testCase.document))
+		if testCase.valid != (err == nil) {
+			t.Errorf("%s: expected valid=%v, got %v", testCase.document, testCase.valid, err)
+		}
+	}
+
+	err = NewValidator(rootSchema).DisableFormats("date").Validate([]byte(`{"date": "2019-12-31"}`))
+	if err != nil {
+		t.Errorf("expected the limits of a disabled format to be ignored, got %v", err)
+	}
+}
+
+func TestFormatLimitsCompilation(t *testing.T) {
+	testCases := []string{
+		`{"formatMinimum": "2020-01-01"}`,
+		`{"format": "email", "formatMinimum": "a@example.com"}`,
+		`{"format": "date", "formatMaximum": "2020-13-01"}`,
+	}
+
+	for _, schema := range testCases {
+		_, err := NewRootJsonSchema([]byte(schema))
+		if _, ok := err.(SchemaCompilationErrors); !ok {
+			t.Errorf("%s: expected SchemaCompilationErrors, got %v", schema, err)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package jsonvalidator
+
+import "github.com/itayankri/gojsonvalidator/formatchecker"
+
+// RegisterFormat registers a checker for the "format" keyword in
+// formatchecker.DefaultRegistry. Strings whose schema declares the given
+// format name are valid if the checker returns nil. Registering a built-in
+// format name replaces the built-in checker.
+func RegisterFormat(name string, checker func(string) error) {
+	formatchecker.Register(name, formatchecker.CheckerFunc(checker))
+}
+
+// RegisterFormats registers a set of format checkers at once, keyed by their
+// format names.
+func RegisterFormats(checkers map[string]func(string) error) {
+	for name, checker := range checkers {
+		RegisterFormat(name, checker)
+	}
+}
@@ -0,0 +1,41 @@
+package jsonvalidator
+
+import (
+	"testing"
+
+	"github.com/itayankri/gojsonvalidator/extformats"
+)
+
+func TestRegisterFormats(t *testing.T) {
+	RegisterFormats(extformats.All())
+
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"properties": {
+			"card": {"format": "credit-card"},
+			"currency": {"format": "currency-code"}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		description string
+		data        string
+		valid       bool
+	}{
+		{"a valid card number", `{"card": "4111111111111111"}`, true},
+		{"an invalid card number", `{"card": "4111111111111112"}`, false},
+		{"a valid currency code", `{"currency": "ILS"}`, true},
+		{"an invalid currency code", `{"currency": "XYZ"}`, false},
+		{"non-strings are ignored", `{"currency": 1}`, true},
+	}
+
+	validator := NewValidator(rootSchema)
+	for _, testCase := range testCases {
+		err := validator.Validate([]byte(testCase.data))
+		if (err == nil) != testCase.valid {
+			t.Errorf("%s: expected valid=%t, got %v", testCase.description, testCase.valid, err)
+		}
+	}
+}
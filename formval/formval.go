@@ -0,0 +1,183 @@
+// Package formval validates query strings and form data against a json
+// schema, so the same schema can describe the json body of a request and its
+// query parameters.
+//
+// The fields are converted to a json object whose properties are the field
+// names. The "type" keyword of the schema of every property decides how its
+// values are parsed:
+//   - "integer" and "number" values are parsed as json numbers,
+//   - "boolean" values must be "true" or "false",
+//   - "null" values must be empty or "null",
+//   - "object" values must be a json object,
+//   - any other value is kept as a string.
+//
+// A field with several values, or whose schema is of type "array", becomes an
+// array, and its items are parsed according to the schema of the items.
+// Fields without a schema, and values that do not match any of the types of
+// their schema, are kept as strings, so the validator reports them.
+package formval
+
+import (
+	"encoding/json"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/internal/strutil"
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+// The maximal number of bytes of a multipart form that ValidateRequest()
+// keeps in memory, like the default of net/http.
+const defaultMaxMemory = 32 << 20
+
+// ToJson converts query parameters or form fields to a json object according
+// to the schema.
+func ToJson(values url.Values, schema *jsonvalidator.RootJsonSchema) ([]byte, error) {
+	object := make(map[string]interface{}, len(values))
+	for name, fieldValues := range values {
+		if len(fieldValues) == 0 {
+			continue
+		}
+
+		value, err := convertField(name, fieldValues, schema)
+		if err != nil {
+			return nil, err
+		}
+
+		object[name] = value
+	}
+
+	return json.Marshal(object)
+}
+
+// Validate converts query parameters or form fields to a json object and
+// validates it against the schema.
+func Validate(values url.Values, schema *jsonvalidator.RootJsonSchema) error {
+	bytes, err := ToJson(values, schema)
+	if err != nil {
+		return err
+	}
+
+	return jsonvalidator.NewValidator(schema).Validate(bytes)
+}
+
+// ValidateMultipart validates the fields of a multipart form against the
+// schema. Every uploaded file is represented by its file name, so "required"
+// and the other keywords also apply to file fields.
+func ValidateMultipart(form *multipart.Form, schema *jsonvalidator.RootJsonSchema) error {
+	values := make(url.Values, len(form.Value)+len(form.File))
+	for name, fieldValues := range form.Value {
+		values[name] = append(values[name], fieldValues...)
+	}
+
+	for name, files := range form.File {
+		for _, file := range files {
+			values.Add(name, file.Filename)
+		}
+	}
+
+	return Validate(values, schema)
+}
+
+// ValidateRequest validates the query parameters and the form fields of an
+// http request against the schema. The form is parsed as a multipart form if
+// the request has a multipart body.
+func ValidateRequest(r *http.Request, schema *jsonvalidator.RootJsonSchema) error {
+	err := r.ParseMultipartForm(defaultMaxMemory)
+	if err == http.ErrNotMultipart {
+		err = r.ParseForm()
+	}
+	if err != nil {
+		return err
+	}
+
+	if r.MultipartForm == nil {
+		return Validate(r.Form, schema)
+	}
+
+	// r.Form holds the query parameters and the values of the multipart form,
+	// to which the files are added.
+	return ValidateMultipart(&multipart.Form{Value: r.Form, File: r.MultipartForm.File}, schema)
+}
+
+// convertField returns the json value of a field with the given values.
+func convertField(name string, values []string, schema *jsonvalidator.RootJsonSchema) (interface{}, error) {
+	pointer := "/" + jsonwalker.EscapeToken(name)
+	types, err := schema.TypesAt(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) == 1 && !strutil.Contains(types, "array") {
+		return convertValue(values[0], types), nil
+	}
+
+	array := make([]interface{}, len(values))
+	for index, value := range values {
+		itemTypes, err := schema.TypesAt(pointer + "/" + strconv.Itoa(index))
+		if err != nil {
+			return nil, err
+		}
+
+		array[index] = convertValue(value, itemTypes)
+	}
+
+	return array, nil
+}
+
+// convertValue parses a value according to the first of the given types it
+// matches. A string is preferred last, so "1" is a number if the schema allows
+// both numbers and strings.
+func convertValue(value string, types []string) interface{} {
+	for _, jsonType := range []string{"integer", "number", "boolean", "null", "object"} {
+		if !strutil.Contains(types, jsonType) {
+			continue
+		}
+
+		switch jsonType {
+		case "integer", "number":
+			if number, ok := parseNumber(value); ok {
+				if jsonType == "number" {
+					return number
+				}
+
+				if float, err := number.Float64(); err == nil && float == math.Trunc(float) {
+					return number
+				}
+			}
+		case "boolean":
+			if value == "true" || value == "false" {
+				return value == "true"
+			}
+		case "null":
+			if value == "" || value == "null" {
+				return nil
+			}
+		case "object":
+			var object map[string]interface{}
+			if json.Unmarshal([]byte(value), &object) == nil && object != nil {
+				return object
+			}
+		}
+	}
+
+	return value
+}
+
+// parseNumber returns the value as a json number, if it is one.
+func parseNumber(value string) (json.Number, bool) {
+	if value == "" || value[0] != '-' && (value[0] < '0' || value[0] > '9') {
+		return "", false
+	}
+
+	var number json.Number
+	if json.Unmarshal([]byte(value), &number) != nil {
+		return "", false
+	}
+
+	return number, true
+}
@@ -0,0 +1,104 @@
+package formval_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/formval"
+)
+
+const searchSchema = `{
+	"type": "object",
+	"required": ["q"],
+	"properties": {
+		"q": {"type": "string", "minLength": 1},
+		"page": {"type": "integer", "minimum": 1},
+		"ratio": {"type": ["number", "null"]},
+		"exact": {"type": "boolean"},
+		"tags": {"type": "array", "items": {"type": "integer"}, "maxItems": 3},
+		"filter": {"type": "object", "required": ["field"]},
+		"attachment": {"type": "string", "pattern": "\\.pdf$"}
+	}
+}`
+
+func newSchema(t *testing.T) *jsonvalidator.RootJsonSchema {
+	schema, err := jsonvalidator.NewRootJsonSchema([]byte(searchSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return schema
+}
+
+func TestToJson(t *testing.T) {
+	values, _ := url.ParseQuery(`q=10&page=2&ratio=&exact=true&tags=5&filter={"field":"name"}&other=1`)
+
+	bytes, err := formval.ToJson(values, newSchema(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"exact":true,"filter":{"field":"name"},"other":"1","page":2,"q":"10","ratio":null,"tags":[5]}`
+	if string(bytes) != expected {
+		t.Errorf("expected %s, got %s", expected, bytes)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	schema := newSchema(t)
+
+	tests := []struct {
+		query string
+		valid bool
+	}{
+		{"q=shoes", true},
+		{"q=shoes&page=3&ratio=0.5&exact=false&tags=1&tags=2", true},
+		{"page=3", false},
+		{"q=shoes&page=0", false},
+		{"q=shoes&page=1.5", false},
+		{"q=shoes&page=first", false},
+		{"q=shoes&exact=yes", false},
+		{"q=shoes&tags=a", false},
+		{"q=shoes&tags=1&tags=2&tags=3&tags=4", false},
+		{"q=shoes&q=boots", false},
+		{`q=shoes&filter={}`, false},
+	}
+
+	for _, test := range tests {
+		values, _ := url.ParseQuery(test.query)
+		err := formval.Validate(values, schema)
+		if test.valid && err != nil {
+			t.Errorf("%s: unexpected error %v", test.query, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%s: expected an error", test.query)
+		}
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	schema := newSchema(t)
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	writer.WriteField("page", "2")
+	file, _ := writer.CreateFormFile("attachment", "report.txt")
+	file.Write([]byte("content"))
+	writer.Close()
+
+	request := httptest.NewRequest("POST", "/search?q=reports", body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+
+	err := formval.ValidateRequest(request, schema)
+	if validationErr, ok := err.(jsonvalidator.SchemaValidationError); !ok || validationErr.Path() != "/attachment" {
+		t.Errorf("expected the attachment to be invalid, got %v", err)
+	}
+
+	request = httptest.NewRequest("GET", "/search?q=reports&page=2", nil)
+	if err := formval.ValidateRequest(request, schema); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -2,4 +2,8 @@ module github.com/itayankri/gojsonvalidator
 
 go 1.13
 
-require github.com/pkg/errors v0.9.1
+require (
+	github.com/pkg/errors v0.9.1
+	google.golang.org/protobuf v1.31.0
+	gopkg.in/yaml.v3 v3.0.1
+)
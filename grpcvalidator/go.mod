@@ -0,0 +1,20 @@
+module github.com/itayankri/gojsonvalidator/grpcvalidator
+
+go 1.17
+
+require (
+	github.com/itayankri/gojsonvalidator v0.0.0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230525234030-28d5490b6b19
+	google.golang.org/grpc v1.57.0
+	google.golang.org/protobuf v1.31.0
+)
+
+require (
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	golang.org/x/net v0.9.0 // indirect
+	golang.org/x/sys v0.7.0 // indirect
+	golang.org/x/text v0.9.0 // indirect
+)
+
+replace github.com/itayankri/gojsonvalidator => ../
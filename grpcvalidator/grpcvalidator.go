@@ -0,0 +1,152 @@
+// Package grpcvalidator validates the request messages of gRPC methods
+// against json schemas, in their protojson encoding, which is the json that
+// clients of a JSON transcoding gateway send. Schemas are registered by the
+// full method name of the methods, like "/shop.v1.Orders/CreateOrder", and
+// messages of methods without a schema are not validated.
+//
+// The interceptors validate the requests of a gRPC server, and return an
+// InvalidArgument status with a google.rpc.BadRequest detail that lists the
+// violations. The package is a module of its own, so that only its users
+// depend on the google.golang.org/grpc module.
+//
+// The fields of the violations are the paths of the failing values in the
+// protojson encoding, like "lines[0].sku", and the paths of the values of
+// the message itself are empty.
+package grpcvalidator
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Violation is a value of a request message that failed in validation.
+// Field is the path of the value, and Description describes the failure.
+type Violation struct {
+	Field       string
+	Description string
+}
+
+// ValidationError is returned when a request message does not match the
+// schema of its method.
+type ValidationError struct {
+	Method     string
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	descriptions := make([]string, len(e.Violations))
+	for index, violation := range e.Violations {
+		descriptions[index] = violation.Description
+		if violation.Field != "" {
+			descriptions[index] = violation.Field + ": " + violation.Description
+		}
+	}
+
+	return "invalid request of " + e.Method + ": " + strings.Join(descriptions, "; ")
+}
+
+// Validator holds the schemas of the request messages of gRPC methods. A
+// Validator is safe for concurrent use.
+type Validator struct {
+	mutex      sync.RWMutex
+	validators map[string]*jsonvalidator.Validator
+}
+
+// New creates a Validator without schemas.
+func New() *Validator {
+	return &Validator{validators: map[string]*jsonvalidator.Validator{}}
+}
+
+// Register sets the schema of the request messages of a method, by its full
+// method name.
+func (v *Validator) Register(fullMethod string, schema *jsonvalidator.RootJsonSchema) {
+	v.RegisterValidator(fullMethod, jsonvalidator.NewValidator(schema))
+}
+
+// RegisterValidator sets the jsonvalidator.Validator that validates the
+// request messages of a method, for methods whose validation needs options.
+func (v *Validator) RegisterValidator(fullMethod string, validator *jsonvalidator.Validator) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	v.validators[fullMethod] = validator
+}
+
+// Validate validates a request message of a method against its schema. It
+// returns nil if the message is valid or the method has no schema, and a
+// *ValidationError if the message is invalid.
+func (v *Validator) Validate(fullMethod string, message proto.Message) error {
+	v.mutex.RLock()
+	validator, ok := v.validators[fullMethod]
+	v.mutex.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	bytes, err := protojson.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	result, err := validator.ValidateResult(bytes)
+	if err != nil {
+		return err
+	}
+	if result.Valid() {
+		return nil
+	}
+
+	var document interface{}
+	err = json.Unmarshal(bytes, &document)
+	if err != nil {
+		return err
+	}
+
+	validationError := &ValidationError{Method: fullMethod}
+	for _, resultError := range result.Errors() {
+		validationError.Violations = append(validationError.Violations, Violation{
+			Field:       fieldPath(document, resultError.InstanceLocation),
+			Description: resultError.Message,
+		})
+	}
+
+	return validationError
+}
+
+// fieldPath converts the json pointer of a value of the document to a path
+// of field names and array indexes, like "lines[0].sku".
+func fieldPath(document interface{}, instanceLocation string) string {
+	if instanceLocation == "" {
+		return ""
+	}
+
+	var path strings.Builder
+	value := document
+	for _, token := range strings.Split(instanceLocation[1:], "/") {
+		token = jsonwalker.UnescapeToken(token)
+		switch v := value.(type) {
+		case []interface{}:
+			path.WriteString("[" + token + "]")
+			index, _ := strconv.Atoi(token)
+			value = nil
+			if index < len(v) {
+				value = v[index]
+			}
+		case map[string]interface{}:
+			if path.Len() > 0 {
+				path.WriteByte('.')
+			}
+			path.WriteString(token)
+			value = v[token]
+		}
+	}
+
+	return path.String()
+}
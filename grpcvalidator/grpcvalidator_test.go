@@ -0,0 +1,95 @@
+package grpcvalidator_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/grpcvalidator"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const createOrderMethod = "/shop.v1.Orders/CreateOrder"
+
+const orderSchema = `{
+	"type": "object",
+	"required": ["orderId"],
+	"properties": {
+		"orderId": {"type": "string", "minLength": 3},
+		"lines": {
+			"type": "array",
+			"items": {"type": "object", "properties": {"quantity": {"minimum": 1}}}
+		}
+	}
+}`
+
+func newValidator(t *testing.T) *grpcvalidator.Validator {
+	schema, err := jsonvalidator.NewRootJsonSchema([]byte(orderSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := grpcvalidator.New()
+	validator.Register(createOrderMethod, schema)
+	return validator
+}
+
+func newMessage(t *testing.T, fields map[string]interface{}) *structpb.Struct {
+	message, err := structpb.NewStruct(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return message
+}
+
+func TestValidate(t *testing.T) {
+	validator := newValidator(t)
+
+	valid := newMessage(t, map[string]interface{}{
+		"orderId": "o-1",
+		"lines":   []interface{}{map[string]interface{}{"quantity": 2}},
+	})
+	if err := validator.Validate(createOrderMethod, valid); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	invalid := newMessage(t, map[string]interface{}{
+		"orderId": "o-2",
+		"lines":   []interface{}{map[string]interface{}{"quantity": 1}, map[string]interface{}{"quantity": 0}},
+	})
+	err := validator.Validate(createOrderMethod, invalid)
+
+	var validationError *grpcvalidator.ValidationError
+	if !errors.As(err, &validationError) {
+		t.Fatalf("expected a *ValidationError, got %v", err)
+	}
+
+	var fields []string
+	for _, violation := range validationError.Violations {
+		fields = append(fields, violation.Field)
+	}
+	expected := []string{"lines[1].quantity"}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected the violations of %v, got %v", expected, validationError.Violations)
+	}
+	if validationError.Method != createOrderMethod {
+		t.Errorf("expected the method %s, got %s", createOrderMethod, validationError.Method)
+	}
+
+	missing := newMessage(t, map[string]interface{}{})
+	err = validator.Validate(createOrderMethod, missing)
+	if !errors.As(err, &validationError) || validationError.Violations[0].Field != "" {
+		t.Errorf("expected a violation of the message itself, got %v", err)
+	}
+}
+
+func TestValidateUnregisteredMethod(t *testing.T) {
+	validator := newValidator(t)
+
+	err := validator.Validate("/shop.v1.Orders/GetOrder", newMessage(t, map[string]interface{}{}))
+	if err != nil {
+		t.Errorf("expected messages of methods without a schema to be valid, got %v", err)
+	}
+}
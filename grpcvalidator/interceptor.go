@@ -0,0 +1,83 @@
+package grpcvalidator
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// UnaryServerInterceptor returns an interceptor that validates the requests
+// of unary methods before their handlers are called.
+func (v *Validator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		err := v.validateRequest(info.FullMethod, request)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, request)
+	}
+}
+
+// StreamServerInterceptor returns an interceptor that validates every
+// message that the handlers of streaming methods receive.
+func (v *Validator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(server interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(server, &validatingStream{stream, v, info.FullMethod})
+	}
+}
+
+// validatingStream validates the messages that it receives.
+type validatingStream struct {
+	grpc.ServerStream
+	validator  *Validator
+	fullMethod string
+}
+
+func (s *validatingStream) RecvMsg(message interface{}) error {
+	err := s.ServerStream.RecvMsg(message)
+	if err != nil {
+		return err
+	}
+
+	return s.validator.validateRequest(s.fullMethod, message)
+}
+
+// validateRequest validates a request and returns the error as a status.
+// Requests that are not protobuf messages are not validated.
+func (v *Validator) validateRequest(fullMethod string, request interface{}) error {
+	message, ok := request.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	err := v.Validate(fullMethod, message)
+	if err == nil {
+		return nil
+	}
+
+	var validationError *ValidationError
+	if !errors.As(err, &validationError) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	for _, violation := range validationError.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       violation.Field,
+			Description: violation.Description,
+		})
+	}
+
+	st, detailsErr := status.New(codes.InvalidArgument, err.Error()).WithDetails(badRequest)
+	if detailsErr != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return st.Err()
+}
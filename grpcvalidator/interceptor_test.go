@@ -0,0 +1,82 @@
+package grpcvalidator_test
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := newValidator(t).UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: createOrderMethod}
+
+	called := false
+	handler := func(ctx context.Context, request interface{}) (interface{}, error) {
+		called = true
+		return request, nil
+	}
+
+	_, err := interceptor(context.Background(), newMessage(t, map[string]interface{}{"orderId": "o-1"}), info, handler)
+	if err != nil || !called {
+		t.Errorf("expected a valid request to reach the handler, got %v", err)
+	}
+
+	called = false
+	_, err = interceptor(context.Background(), newMessage(t, map[string]interface{}{"orderId": "o"}), info, handler)
+	if called {
+		t.Error("expected an invalid request not to reach the handler")
+	}
+
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("expected an InvalidArgument status, got %v", err)
+	}
+	if len(st.Details()) != 1 {
+		t.Fatalf("expected a BadRequest detail, got %v", st.Details())
+	}
+
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	if !ok || len(badRequest.FieldViolations) != 1 || badRequest.FieldViolations[0].Field != "orderId" {
+		t.Errorf("expected a violation of orderId, got %v", st.Details()[0])
+	}
+}
+
+// recvStream is a server stream that receives a single message.
+type recvStream struct {
+	grpc.ServerStream
+	message *structpb.Struct
+}
+
+func (s *recvStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *recvStream) RecvMsg(message interface{}) error {
+	message.(*structpb.Struct).Fields = s.message.Fields
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := newValidator(t).StreamServerInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: createOrderMethod, IsClientStream: true}
+
+	handler := func(server interface{}, stream grpc.ServerStream) error {
+		return stream.RecvMsg(&structpb.Struct{})
+	}
+
+	stream := &recvStream{message: newMessage(t, map[string]interface{}{"orderId": "o-1"})}
+	if err := interceptor(nil, stream, info, handler); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	stream = &recvStream{message: newMessage(t, map[string]interface{}{})}
+	err := interceptor(nil, stream, info, handler)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected an InvalidArgument status, got %v", err)
+	}
+}
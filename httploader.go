@@ -0,0 +1,71 @@
+//go:build !js && !tinygo && !jsonvalidator_tiny
+// +build !js,!tinygo,!jsonvalidator_tiny
+
+package jsonvalidator
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+func init() {
+	DefaultLoader["http"] = HTTPLoader{}
+	DefaultLoader["https"] = HTTPLoader{}
+}
+
+// The timeout of the requests of an HTTPLoader without a client, and the
+// maximal size of the documents of an HTTPLoader without MaxSize.
+const (
+	defaultLoadTimeout = 30 * time.Second
+	defaultMaxLoadSize = 10 << 20
+)
+
+// HTTPLoader is a SchemaLoader that fetches "http" and "https" URIs with a
+// GET request. Responses with a status other than 200, and documents that
+// are larger than the maximal size, are errors.
+type HTTPLoader struct {
+	// The client that sends the requests, or a client with a timeout of 30
+	// seconds if it is nil.
+	Client *http.Client
+
+	// The maximal size of a document in bytes, or 10 MiB if it is 0.
+	MaxSize int64
+}
+
+// Load fetches the URI.
+func (l HTTPLoader) Load(uri string) ([]byte, error) {
+	client := l.Client
+	if client == nil {
+		client = &http.Client{Timeout: defaultLoadTimeout}
+	}
+
+	response, err := client.Get(uri)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s failed with status %s", uri, response.Status)
+	}
+
+	maxSize := l.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxLoadSize
+	}
+
+	// One more byte than the maximal size is read, to tell a document of
+	// the maximal size from a larger one.
+	bytes, err := ioutil.ReadAll(io.LimitReader(response.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(bytes)) > maxSize {
+		return nil, fmt.Errorf("fetching %s failed: the document is larger than %d bytes", uri, maxSize)
+	}
+
+	return bytes, nil
+}
@@ -0,0 +1,118 @@
+//go:build !js && !tinygo && !jsonvalidator_tiny
+// +build !js,!tinygo,!jsonvalidator_tiny
+
+package jsonvalidator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestSchemaLoader(t *testing.T) {
+	documents := map[string]string{
+		"/common.json": `{
+			"definitions": {
+				"address": {
+					"required": ["city"],
+					"properties": {"city": {"$ref": "types.json#/definitions/name"}}
+				}
+			}
+		}`,
+		"/types.json": `{"definitions": {"name": {"type": "string", "minLength": 1}}}`,
+	}
+
+	var mutex sync.Mutex
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests[r.URL.Path]++
+		mutex.Unlock()
+
+		document, ok := documents[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(document))
+	}))
+	defer server.Close()
+
+	SetSchemaLoader(DefaultLoader)
+	defer SetSchemaLoader(nil)
+
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/loader/person.json",
+		"properties": {
+			"home": {"$ref": "` + server.URL + `/common.json#/definitions/address"},
+			"work": {"$ref": "` + server.URL + `/common.json#/definitions/address"},
+			"id": {"$ref": "urn:example:loader:id"}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every document is fetched once, and references of unsupported schemes
+	// are left to be resolved when documents are validated.
+	for path, count := range requests {
+		if count != 1 {
+			t.Errorf("%s was fetched %d times", path, count)
+		}
+	}
+	if len(requests) != 2 {
+		t.Errorf("fetched %v, expected the 2 referenced documents", requests)
+	}
+
+	validator := NewValidator(rootSchema)
+	testCases := []struct {
+		document string
+		valid    bool
+	}{
+		{`{"home": {"city": "Haifa"}}`, true},
+		{`{"work": {"city": "Haifa"}}`, true},
+		{`{"home": {}}`, false},
+		{`{"work": {"city": ""}}`, false},
+	}
+	for _, testCase := range testCases {
+		err := validator.Validate([]byte(testCase.document))
+		if valid := err == nil; valid != testCase.valid {
+			t.Errorf("Validate(%s) returned %v, expected valid: %v", testCase.document, err, testCase.valid)
+		}
+	}
+
+	_, err = NewRootJsonSchema([]byte(`{"$ref": "` + server.URL + `/missing.json"}`))
+	if err == nil {
+		t.Error("a reference to a missing document compiled")
+	}
+}
+
+func TestHTTPLoaderLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"type": "string"}`))
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		loader HTTPLoader
+		path   string
+		valid  bool
+	}{
+		{HTTPLoader{}, "/schema.json", true},
+		{HTTPLoader{MaxSize: 18}, "/schema.json", true},
+		{HTTPLoader{MaxSize: 17}, "/schema.json", false},
+		{HTTPLoader{}, "/missing.json", false},
+	}
+
+	for _, testCase := range testCases {
+		_, err := testCase.loader.Load(server.URL + testCase.path)
+		if valid := err == nil; valid != testCase.valid {
+			t.Errorf("Load(%s) with MaxSize %d returned %v, expected success: %v", testCase.path, testCase.loader.MaxSize, err, testCase.valid)
+		}
+	}
+}
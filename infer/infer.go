@@ -0,0 +1,423 @@
+// Package infer infers a json schema from sample json documents.
+//
+// The samples are merged into a single schema: the types that were seen at
+// the same location are combined (integers are widened to numbers), object
+// properties that are absent from some of the samples are left out of
+// "required", and strings with only a few distinct values become an "enum".
+// Every such decision is reported with the samples that caused it, so the
+// inferred schema can be reviewed before it is used.
+package infer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+const draft07 = "http://json-schema.org/draft-07/schema#"
+
+// The default maximal number of distinct values of a string enum.
+const defaultEnumLimit = 5
+
+// Decision describes a choice that was made while merging the samples.
+// Location is the json pointer of the schema in the inferred schema, Keyword
+// is the keyword that the decision is about, and Samples holds the indexes
+// of the samples that caused it, in the order they were added.
+type Decision struct {
+	Location string
+	Keyword  string
+	Reason   string
+	Samples  []int
+}
+
+// SampleError is returned when a sample is not a valid json document. Index
+// is the index of the sample.
+type SampleError struct {
+	Index int
+	err   error
+}
+
+func (e SampleError) Error() string {
+	return fmt.Sprintf("sample %d: %s", e.Index, e.err.Error())
+}
+
+// Inferrer merges sample documents into a schema.
+type Inferrer struct {
+	root      *node
+	samples   int
+	enumLimit int
+}
+
+// New creates an Inferrer without samples.
+func New() *Inferrer {
+	return &Inferrer{
+		root:      newNode(),
+		enumLimit: defaultEnumLimit,
+	}
+}
+
+// EnumLimit sets the maximal number of distinct values of a string that is
+// inferred as an enum. A string becomes an enum only if each of its values
+// was seen at least twice on average, so a few samples do not produce an
+// enum of everything they contain. A limit of 0 disables enums.
+func (in *Inferrer) EnumLimit(limit int) *Inferrer {
+	in.enumLimit = limit
+	return in
+}
+
+// Add adds a sample document. The index of the sample, which is used in the
+// decisions, is the number of samples that were added before it.
+func (in *Inferrer) Add(sample []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(sample))
+	decoder.UseNumber()
+
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err == nil {
+		// A sample must hold a single document.
+		if _, err = decoder.Token(); err == io.EOF {
+			err = nil
+		} else if err == nil {
+			err = fmt.Errorf("unexpected data after the json document")
+		}
+	}
+	if err != nil {
+		return SampleError{Index: in.samples, err: err}
+	}
+
+	in.root.observe(value, in.samples, in.enumLimit)
+	in.samples++
+	return nil
+}
+
+// Samples returns the number of samples that were added.
+func (in *Inferrer) Samples() int {
+	return in.samples
+}
+
+// Schema returns the inferred draft-07 schema and the decisions that were
+// made while merging the samples.
+func (in *Inferrer) Schema() ([]byte, []Decision, error) {
+	var decisions []Decision
+	schema := in.root.build("", in.enumLimit, &decisions)
+	schema["$schema"] = draft07
+
+	bytes, err := json.Marshal(schema)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return bytes, decisions, nil
+}
+
+// Infer infers a schema from the given samples.
+func Infer(samples ...[]byte) ([]byte, []Decision, error) {
+	inferrer := New()
+	for _, sample := range samples {
+		if err := inferrer.Add(sample); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return inferrer.Schema()
+}
+
+// node holds what was seen at a single location of the samples.
+type node struct {
+	// The samples in which each json type was seen.
+	types map[string][]int
+
+	// The distinct values of the strings, which is nil once there are more of
+	// them than the enum limit, and the number of strings that were seen.
+	strings     map[string]bool
+	stringCount int
+
+	// The samples in which an object was seen, and its properties.
+	objectSamples []int
+	properties    map[string]*property
+
+	// The merged items of the arrays.
+	items *node
+}
+
+type property struct {
+	node *node
+
+	// The samples with an object that did not have the property.
+	missing []int
+}
+
+func newNode() *node {
+	return &node{
+		types:      map[string][]int{},
+		strings:    map[string]bool{},
+		properties: map[string]*property{},
+	}
+}
+
+// addSample adds a sample to a list of samples that is ordered by index.
+// Samples are observed in order, so the list never has to be searched.
+func addSample(samples []int, sample int) []int {
+	if len(samples) > 0 && samples[len(samples)-1] == sample {
+		return samples
+	}
+
+	return append(samples, sample)
+}
+
+func (n *node) observe(value interface{}, sample int, enumLimit int) {
+	switch v := value.(type) {
+	case nil:
+		n.addType("null", sample)
+	case bool:
+		n.addType("boolean", sample)
+	case json.Number:
+		if isInteger(v) {
+			n.addType("integer", sample)
+		} else {
+			n.addType("number", sample)
+		}
+	case string:
+		n.addType("string", sample)
+		n.stringCount++
+		if n.strings != nil {
+			n.strings[v] = true
+			if len(n.strings) > enumLimit {
+				n.strings = nil
+			}
+		}
+	case []interface{}:
+		n.addType("array", sample)
+		if n.items == nil {
+			n.items = newNode()
+		}
+		for _, item := range v {
+			n.items.observe(item, sample, enumLimit)
+		}
+	case map[string]interface{}:
+		n.addType("object", sample)
+		for name, p := range n.properties {
+			if _, ok := v[name]; !ok {
+				p.missing = addSample(p.missing, sample)
+			}
+		}
+
+		for name, propertyValue := range v {
+			p, ok := n.properties[name]
+			if !ok {
+				// A new property was missing from all the objects before it.
+				p = &property{
+					node:    newNode(),
+					missing: append([]int(nil), n.objectSamples...),
+				}
+				n.properties[name] = p
+			}
+			p.node.observe(propertyValue, sample, enumLimit)
+		}
+
+		n.objectSamples = addSample(n.objectSamples, sample)
+	}
+}
+
+func (n *node) addType(jsonType string, sample int) {
+	n.types[jsonType] = addSample(n.types[jsonType], sample)
+}
+
+// isInteger returns true if number has no fractional part, like the "type"
+// keyword checks integers.
+func isInteger(number json.Number) bool {
+	if _, err := number.Int64(); err == nil {
+		return true
+	}
+
+	value, err := number.Float64()
+	return err == nil && value == math.Trunc(value) && !math.IsInf(value, 0)
+}
+
+// build returns the schema of the node, and appends its decisions and the
+// decisions of its sub-schemas.
+func (n *node) build(location string, enumLimit int, decisions *[]Decision) map[string]interface{} {
+	schema := map[string]interface{}{}
+
+	types := n.buildTypes(location, decisions)
+	switch len(types) {
+	case 0:
+		// Nothing was seen at this location, e.g. the items of empty arrays,
+		// so anything is allowed.
+		return schema
+	case 1:
+		schema["type"] = types[0]
+	default:
+		schema["type"] = types
+	}
+
+	if enum := n.buildEnum(types, enumLimit); enum != nil {
+		schema["enum"] = enum
+		*decisions = append(*decisions, Decision{
+			Location: location,
+			Keyword:  "enum",
+			Reason:   fmt.Sprintf("%d distinct values in %d strings", len(n.strings), n.stringCount),
+			Samples:  n.types["string"],
+		})
+	}
+
+	if n.items != nil {
+		items := n.items.build(location+"/items", enumLimit, decisions)
+		if len(items) > 0 {
+			schema["items"] = items
+		}
+	}
+
+	if len(n.types["object"]) > 0 {
+		names := make([]string, 0, len(n.properties))
+		for name := range n.properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		properties := map[string]interface{}{}
+		required := []string{}
+		for _, name := range names {
+			p := n.properties[name]
+			propertyLocation := location + "/properties/" + jsonwalker.EscapeToken(name)
+			properties[name] = p.node.build(propertyLocation, enumLimit, decisions)
+			if len(p.missing) == 0 {
+				required = append(required, name)
+				continue
+			}
+
+			*decisions = append(*decisions, Decision{
+				Location: propertyLocation,
+				Keyword:  "required",
+				Reason:   "optional, since it is absent from " + describeSamples(p.missing),
+				Samples:  p.missing,
+			})
+		}
+
+		schema["properties"] = properties
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+	}
+
+	return schema
+}
+
+// buildTypes returns the sorted json types of the node, where integers are
+// widened to numbers if both were seen.
+func (n *node) buildTypes(location string, decisions *[]Decision) []string {
+	types := make([]string, 0, len(n.types))
+	for jsonType := range n.types {
+		types = append(types, jsonType)
+	}
+	sort.Strings(types)
+
+	if len(n.types["integer"]) > 0 && len(n.types["number"]) > 0 {
+		for index, jsonType := range types {
+			if jsonType == "integer" {
+				types = append(types[:index], types[index+1:]...)
+				break
+			}
+		}
+
+		*decisions = append(*decisions, Decision{
+			Location: location,
+			Keyword:  "type",
+			Reason: fmt.Sprintf("widened integer to number, since integers are found in %s and numbers in %s",
+				describeSamples(n.types["integer"]), describeSamples(n.types["number"])),
+			Samples: mergeSamples(n.types["integer"], n.types["number"]),
+		})
+	}
+
+	if len(types) > 1 {
+		descriptions := make([]string, len(types))
+		var samples []int
+		for index, jsonType := range types {
+			typeSamples := n.types[jsonType]
+			if jsonType == "number" {
+				typeSamples = mergeSamples(typeSamples, n.types["integer"])
+			}
+
+			descriptions[index] = jsonType + " in " + describeSamples(typeSamples)
+			samples = mergeSamples(samples, typeSamples)
+		}
+
+		*decisions = append(*decisions, Decision{
+			Location: location,
+			Keyword:  "type",
+			Reason:   "allowed several types, since there is " + strings.Join(descriptions, ", "),
+			Samples:  samples,
+		})
+	}
+
+	return types
+}
+
+// buildEnum returns the enum of the node's strings, or nil if the strings
+// are not an enum. Strings form an enum if they are the only non-null values
+// at the location, and there are few of them compared to the number of times
+// they were seen.
+func (n *node) buildEnum(types []string, enumLimit int) []interface{} {
+	if n.strings == nil || len(n.strings) == 0 || len(n.strings) > enumLimit || n.stringCount < 2*len(n.strings) {
+		return nil
+	}
+
+	for _, jsonType := range types {
+		if jsonType != "string" && jsonType != "null" {
+			return nil
+		}
+	}
+
+	values := make([]string, 0, len(n.strings))
+	for value := range n.strings {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	enum := make([]interface{}, 0, len(values)+1)
+	for _, value := range values {
+		enum = append(enum, value)
+	}
+	if len(n.types["null"]) > 0 {
+		enum = append(enum, nil)
+	}
+
+	return enum
+}
+
+// mergeSamples returns the sorted union of two sorted lists of samples.
+func mergeSamples(a, b []int) []int {
+	merged := make([]int, 0, len(a)+len(b))
+	for len(a) > 0 || len(b) > 0 {
+		switch {
+		case len(b) == 0 || len(a) > 0 && a[0] < b[0]:
+			merged, a = append(merged, a[0]), a[1:]
+		case len(a) == 0 || b[0] < a[0]:
+			merged, b = append(merged, b[0]), b[1:]
+		default:
+			merged, a, b = append(merged, a[0]), a[1:], b[1:]
+		}
+	}
+
+	return merged
+}
+
+func describeSamples(samples []int) string {
+	indexes := make([]string, len(samples))
+	for index, sample := range samples {
+		indexes[index] = strconv.Itoa(sample)
+	}
+
+	if len(samples) == 1 {
+		return "sample " + indexes[0]
+	}
+
+	return "samples " + strings.Join(indexes, ", ")
+}
@@ -0,0 +1,113 @@
+package infer_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/infer"
+)
+
+var samples = []string{
+	`{"id": 1, "status": "new", "price": 10, "tags": ["a"], "customer": {"name": "x"}}`,
+	`{"id": 2, "status": "paid", "price": 12.5, "tags": [], "note": null, "customer": {"name": "y", "vip": true}}`,
+	`{"id": 3, "status": "new", "price": 7, "tags": ["b", "c"], "customer": {"name": "w"}}`,
+	`{"id": 4, "status": "paid", "price": 3, "note": "call first", "customer": {"name": "z"}}`,
+}
+
+func TestInfer(t *testing.T) {
+	inferrer := infer.New()
+	for _, sample := range samples {
+		if err := inferrer.Add([]byte(sample)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	schema, decisions, err := inferrer.Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var actual map[string]interface{}
+	if err := json.Unmarshal(schema, &actual); err != nil {
+		t.Fatal(err)
+	}
+
+	var expected map[string]interface{}
+	json.Unmarshal([]byte(`{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type": "object",
+		"required": ["customer", "id", "price", "status"],
+		"properties": {
+			"id": {"type": "integer"},
+			"status": {"type": "string", "enum": ["new", "paid"]},
+			"price": {"type": "number"},
+			"note": {"type": ["null", "string"]},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"customer": {
+				"type": "object",
+				"required": ["name"],
+				"properties": {
+					"name": {"type": "string"},
+					"vip": {"type": "boolean"}
+				}
+			}
+		}
+	}`), &expected)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected schema %s", schema)
+	}
+
+	expectedDecisions := map[string][]int{
+		"/properties/customer/properties/vip required": {0, 2, 3},
+		"/properties/note required":                    {0, 2},
+		"/properties/note type":                        {1, 3},
+		"/properties/price type":                       {0, 1, 2, 3},
+		"/properties/status enum":                      {0, 1, 2, 3},
+		"/properties/tags required":                    {3},
+	}
+	if len(decisions) != len(expectedDecisions) {
+		t.Errorf("expected %d decisions, got %v", len(expectedDecisions), decisions)
+	}
+	for _, decision := range decisions {
+		samples, ok := expectedDecisions[decision.Location+" "+decision.Keyword]
+		if !ok || !reflect.DeepEqual(decision.Samples, samples) {
+			t.Errorf("unexpected decision %+v", decision)
+		}
+	}
+
+	// The samples are valid against the inferred schema.
+	rootSchema, err := jsonvalidator.NewRootJsonSchema(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for index, sample := range samples {
+		if err := jsonvalidator.NewValidator(rootSchema).Validate([]byte(sample)); err != nil {
+			t.Errorf("sample %d: %v", index, err)
+		}
+	}
+}
+
+func TestInferEnumLimit(t *testing.T) {
+	inferrer := infer.New().EnumLimit(0)
+	for _, sample := range []string{`"a"`, `"a"`, `"a"`} {
+		inferrer.Add([]byte(sample))
+	}
+
+	schema, decisions, err := inferrer.Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(schema) != `{"$schema":"http://json-schema.org/draft-07/schema#","type":"string"}` || len(decisions) != 0 {
+		t.Errorf("unexpected schema %s and decisions %v", schema, decisions)
+	}
+}
+
+func TestInferInvalidSample(t *testing.T) {
+	_, _, err := infer.Infer([]byte(`{}`), []byte(`{"a": 1} {}`))
+	if sampleErr, ok := err.(infer.SampleError); !ok || sampleErr.Index != 1 {
+		t.Errorf("expected a SampleError for sample 1, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+// Package schemautil holds the helpers that the packages which work with the
+// json form of schemas share, like the exporters (mongoschema, crdschema),
+// the generators (codegen, schematest) and schemadiff.
+package schemautil
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/itayankri/gojsonvalidator"
+)
+
+// Value returns the json form of a schema, with numbers kept as
+// json.Number.
+func Value(schema *jsonvalidator.JsonSchema) (interface{}, error) {
+	encoded, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+
+	var value interface{}
+	err = decoder.Decode(&value)
+	return value, err
+}
+
+// Map returns the json form of a schema object, with numbers decoded as
+// float64.
+func Map(schema *jsonvalidator.JsonSchema) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var object map[string]interface{}
+	err = json.Unmarshal(encoded, &object)
+	return object, err
+}
+
+// References are the references of a root schema that are being inlined,
+// which cannot be inlined again inside themselves.
+type References map[string]bool
+
+// Inline converts the json form of the schema that a reference of the root
+// schema points to with convert. The keywords of the referenced schema are
+// reported at their location in the root schema, if the reference is a json
+// pointer into it, and at the location of the reference otherwise. It
+// returns recursive == true, without calling convert, if the reference is
+// already being inlined.
+func (r References) Inline(rootSchema *jsonvalidator.RootJsonSchema, reference string, location string, convert func(value interface{}, location string) (map[string]interface{}, error)) (converted map[string]interface{}, recursive bool, err error) {
+	if r[reference] {
+		return nil, true, nil
+	}
+
+	resolved, err := rootSchema.Resolve(reference)
+	if err != nil {
+		return nil, false, err
+	}
+
+	value, err := Value(resolved)
+	if err != nil {
+		return nil, false, err
+	}
+
+	r[reference] = true
+	defer delete(r, reference)
+
+	if strings.HasPrefix(reference, "#") {
+		location = strings.TrimPrefix(reference, "#")
+	}
+
+	converted, err = convert(value, location)
+	return converted, false, err
+}
+
+// IsStricter returns true if the exclusive bound is at least as strict as
+// the inclusive bound, which is nil if there is none.
+func IsStricter(exclusive interface{}, inclusive interface{}, lower bool) bool {
+	exclusiveNumber, ok := exclusive.(json.Number)
+	if !ok {
+		return false
+	}
+
+	inclusiveNumber, ok := inclusive.(json.Number)
+	if !ok {
+		return true
+	}
+
+	exclusiveValue, _ := exclusiveNumber.Float64()
+	inclusiveValue, _ := inclusiveNumber.Float64()
+	if lower {
+		return exclusiveValue >= inclusiveValue
+	}
+
+	return exclusiveValue <= inclusiveValue
+}
+
+// StringList returns a keyword value that is a string or an array of
+// strings as a list.
+func StringList(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				list = append(list, str)
+			}
+		}
+		return list
+	}
+
+	return nil
+}
+
+// Number returns the value of a numeric keyword, if it is a json.Number.
+func Number(value interface{}) (float64, bool) {
+	n, ok := value.(json.Number)
+	if !ok {
+		return 0, false
+	}
+
+	float, err := n.Float64()
+	return float, err == nil
+}
+
+// ContainsValue returns true if the list has an item that is deeply equal
+// to the value.
+func ContainsValue(list []interface{}, value interface{}) bool {
+	for _, item := range list {
+		if reflect.DeepEqual(item, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsObjectSchema returns true if the json form of a schema describes
+// objects: its "type" is "object" (or lists it), or it has "properties"
+// without a "type".
+func IsObjectSchema(schema map[string]interface{}) bool {
+	for _, jsonType := range StringList(schema["type"]) {
+		if jsonType == "object" {
+			return true
+		}
+	}
+
+	_, hasProperties := schema["properties"]
+	return schema["type"] == nil && hasProperties
+}
@@ -0,0 +1,99 @@
+// Package strutil holds the string helpers that the packages of the module
+// share.
+package strutil
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Contains returns true if the list holds the value.
+func Contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Words splits a name into its words, at characters that are not letters or
+// digits and at the upper-case letters of camel case names.
+func Words(name string) []string {
+	var result []string
+	var word []rune
+	var previous rune
+	for _, r := range name {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			if len(word) > 0 {
+				result = append(result, string(word))
+			}
+			word = nil
+		case unicode.IsUpper(r) && (unicode.IsLower(previous) || unicode.IsDigit(previous)) && len(word) > 0:
+			result = append(result, string(word))
+			word = []rune{r}
+		default:
+			word = append(word, r)
+		}
+		previous = r
+	}
+	if len(word) > 0 {
+		result = append(result, string(word))
+	}
+
+	return result
+}
+
+// CamelCase joins the words of a name (see Words()) in CamelCase. A name
+// that would be empty or start with a digit gets the prefix, so it is a
+// valid identifier.
+func CamelCase(name string, prefix string) string {
+	var builder strings.Builder
+	for _, word := range Words(name) {
+		runes := []rune(word)
+		builder.WriteRune(unicode.ToUpper(runes[0]))
+		builder.WriteString(string(runes[1:]))
+	}
+
+	if builder.Len() == 0 || unicode.IsDigit(rune(builder.String()[0])) {
+		return prefix + builder.String()
+	}
+
+	return builder.String()
+}
+
+// EditDistance returns the Levenshtein distance between two strings.
+func EditDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,47 @@
+// Package yamlutil converts decoded yaml documents to the values that json
+// documents are decoded into.
+package yamlutil
+
+import "fmt"
+
+// ConvertValue replaces the maps in a decoded yaml value with maps that
+// have string keys, which is what a json object is decoded into.
+func ConvertValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			converted, err := ConvertValue(item)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = converted
+		}
+		return v, nil
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			stringKey, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("yaml mapping key %v is not a string", key)
+			}
+
+			converted, err := ConvertValue(item)
+			if err != nil {
+				return nil, err
+			}
+			object[stringKey] = converted
+		}
+		return object, nil
+	case []interface{}:
+		for index, item := range v {
+			converted, err := ConvertValue(item)
+			if err != nil {
+				return nil, err
+			}
+			v[index] = converted
+		}
+		return v, nil
+	}
+
+	return value, nil
+}
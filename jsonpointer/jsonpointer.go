@@ -1,7 +1,10 @@
 package jsonwalker
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
 	"strconv"
 	"strings"
 )
@@ -13,9 +16,27 @@ type JsonPointer []string
 
 // NewJsonPointer is a function that create a JsonPointer according
 // to a specific json pointer of type string.
+// The path may be given either in its plain string representation
+// ("/foo/0") or in its URI fragment identifier representation
+// ("#/foo/0"), in which case the path is percent-decoded first.
+// Escaped tokens ("~0" and "~1") are decoded according to RFC 6901.
 // It returns a JsonPointerSyntaxError if the string does not have
 // a '/' prefix.
 func NewJsonPointer(path string) (JsonPointer, error) {
+	// If path is in the URI fragment representation, remove the '#'
+	// prefix and percent-decode the rest of it.
+	if len(path) > 0 && path[0] == '#' {
+		decoded, ok := percentDecode(path[1:])
+		if !ok {
+			return nil, JsonPointerSyntaxError{
+				"invalid percent-encoding in URI fragment",
+				path,
+			}
+		}
+
+		path = decoded
+	}
+
 	// If path equals to "", return an empty-reference JsonPointer.
 	if len(path) == 0 || path == "/" {
 		return JsonPointer{}, nil
@@ -32,10 +53,92 @@ func NewJsonPointer(path string) (JsonPointer, error) {
 	// Split path by '/' in order to get a []string of json tokens
 	tokens := strings.Split(path, "/")
 
-	// Convert the []string to JonPointer and omit the first string
-	// in the slice because when the delimiter is the first character
-	// in a string, Split return "" in the slice's first cell.
-	return JsonPointer(tokens[1:]), nil
+	// Omit the first string in the slice because when the delimiter is
+	// the first character in a string, Split return "" in the slice's
+	// first cell.
+	tokens = tokens[1:]
+
+	// Decode the escaped characters in each token.
+	for index, token := range tokens {
+		tokens[index] = UnescapeToken(token)
+	}
+
+	return JsonPointer(tokens), nil
+}
+
+// percentDecode decodes the percent-encoded bytes of a URI fragment, like
+// url.PathUnescape (which is not used in order to keep the net packages out
+// of the module's core). It returns false if a '%' is not followed by two
+// hexadecimal digits.
+func percentDecode(s string) (string, bool) {
+	if strings.IndexByte(s, '%') == -1 {
+		return s, true
+	}
+
+	decoded := make([]byte, 0, len(s))
+	for index := 0; index < len(s); index++ {
+		if s[index] != '%' {
+			decoded = append(decoded, s[index])
+			continue
+		}
+
+		if index+2 >= len(s) {
+			return "", false
+		}
+
+		high, highOk := hexValue(s[index+1])
+		low, lowOk := hexValue(s[index+2])
+		if !highOk || !lowOk {
+			return "", false
+		}
+
+		decoded = append(decoded, high<<4|low)
+		index += 2
+	}
+
+	return string(decoded), true
+}
+
+func hexValue(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+
+	return 0, false
+}
+
+// EscapeToken encodes the characters '~' and '/' of a json token so it
+// can be safely used as a part of a json pointer.
+func EscapeToken(token string) string {
+	token = strings.Replace(token, "~", "~0", -1)
+	return strings.Replace(token, "/", "~1", -1)
+}
+
+// UnescapeToken decodes the escaped characters of a json token that is a
+// part of a json pointer. The order of the replacements matters, "~01"
+// must be decoded to "~1" and not to "/".
+func UnescapeToken(token string) string {
+	token = strings.Replace(token, "~1", "/", -1)
+	return strings.Replace(token, "~0", "~", -1)
+}
+
+// String returns the json pointer in its plain string representation.
+func (jp JsonPointer) String() string {
+	if len(jp) == 0 {
+		return ""
+	}
+
+	tokens := make([]string, len(jp))
+	for index, token := range jp {
+		tokens[index] = EscapeToken(token)
+	}
+
+	return "/" + strings.Join(tokens, "/")
 }
 
 // Evaluate is a receiver function that searches for the JsonPointer's data
@@ -49,6 +152,34 @@ func (jp JsonPointer) Evaluate(jsonData json.RawMessage) (interface{}, error) {
 		return nil, err
 	}
 
+	return jp.evaluate(data)
+}
+
+// EvaluateUseNumber is like Evaluate, but json numbers are decoded into
+// json.Number values instead of float64, so their original representation
+// is preserved.
+func (jp JsonPointer) EvaluateUseNumber(jsonData json.RawMessage) (interface{}, error) {
+	var data interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	err := decoder.Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Like json.Unmarshal, reject data that contains more than one json value.
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("invalid data after top-level json value")
+	}
+
+	return jp.evaluate(data)
+}
+
+// evaluate searches for the JsonPointer's data in a decoded json value.
+func (jp JsonPointer) evaluate(data interface{}) (interface{}, error) {
+	var err error
+
 	// If the JsonPointer is an empty reference, return the whole data.
 	if len(jp) == 0 {
 		return data, nil
@@ -60,7 +191,7 @@ func (jp JsonPointer) Evaluate(jsonData json.RawMessage) (interface{}, error) {
 		data, err = evaluateToken(token, data)
 		if err != nil {
 			return nil, InvalidJsonPointerError{
-				"/" + strings.Join(jp, "/"),
+				jp.String(),
 				err.Error(),
 			}
 		}
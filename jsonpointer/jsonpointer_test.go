@@ -0,0 +1,83 @@
+package jsonwalker_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+func TestNewJsonPointer(t *testing.T) {
+	testCases := []struct {
+		description string
+		path        string
+		tokens      jsonwalker.JsonPointer
+		valid       bool
+	}{
+		{
+			description: "an empty reference",
+			path:        "",
+			tokens:      jsonwalker.JsonPointer{},
+			valid:       true,
+		},
+		{
+			description: "a plain json pointer",
+			path:        "/foo/0",
+			tokens:      jsonwalker.JsonPointer{"foo", "0"},
+			valid:       true,
+		},
+		{
+			description: "a plain json pointer with escaped tokens",
+			path:        "/a~1b/m~0n",
+			tokens:      jsonwalker.JsonPointer{"a/b", "m~n"},
+			valid:       true,
+		},
+		{
+			description: "an empty uri fragment",
+			path:        "#",
+			tokens:      jsonwalker.JsonPointer{},
+			valid:       true,
+		},
+		{
+			description: "a percent-encoded uri fragment",
+			path:        "#/foo/%20bar",
+			tokens:      jsonwalker.JsonPointer{"foo", " bar"},
+			valid:       true,
+		},
+		{
+			description: "a uri fragment with percent-encoded escaped tokens",
+			path:        "#/c%25d/a~1b",
+			tokens:      jsonwalker.JsonPointer{"c%d", "a/b"},
+			valid:       true,
+		},
+		{
+			description: "a uri fragment with an invalid percent-encoding",
+			path:        "#/foo/%zz",
+			valid:       false,
+		},
+		{
+			description: "a json pointer without a '/' prefix",
+			path:        "foo",
+			valid:       false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		pointer, err := jsonwalker.NewJsonPointer(testCase.path)
+		if (err == nil) != testCase.valid {
+			t.Errorf("%s: unexpected error result for \"%s\": %v", testCase.description, testCase.path, err)
+			continue
+		}
+
+		if testCase.valid && !reflect.DeepEqual(pointer, testCase.tokens) {
+			t.Errorf("%s: expected %q, got %q", testCase.description, testCase.tokens, pointer)
+		}
+	}
+}
+
+func TestJsonPointerString(t *testing.T) {
+	pointer := jsonwalker.JsonPointer{"a/b", "m~n", "0"}
+	if pointer.String() != "/a~1b/m~0n/0" {
+		t.Errorf("unexpected json pointer string %s", pointer.String())
+	}
+}
@@ -2,9 +2,11 @@ package jsonvalidator
 
 import (
 	"encoding/json"
-	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
+
 	"github.com/itayankri/gojsonvalidator/jsonpointer"
 	"github.com/pkg/errors"
 )
@@ -20,6 +22,15 @@ const (
 	TYPE_NULL    = "null"
 )
 
+// Supported values for "$schema" field
+const (
+	DRAFT_04 = "http://json-schema.org/draft-04/schema#"
+	DRAFT_06 = "http://json-schema.org/draft-06/schema#"
+	DRAFT_07 = "http://json-schema.org/draft-07/schema#"
+
+	DRAFT_2020_12 = "https://json-schema.org/draft/2020-12/schema"
+)
+
 // Valid values for "contentEncoding" field
 const (
 	ENCODING_7BIT             = "7bit"
@@ -29,8 +40,10 @@ const (
 	ENCODING_BASE64           = "base64"
 )
 
+// jsonData holds a json value that is validated. The document is decoded
+// once, and the values of its properties and items are passed down to the
+// sub-schemas as they are, without encoding or decoding them again.
 type jsonData struct {
-	raw   json.RawMessage
 	value interface{}
 }
 
@@ -55,6 +68,27 @@ type JsonSchema struct {
 	// It declares a base URI against which $ref URIs are resolved.
 	Id *id `json:"$id,omitempty"`
 
+	// The $anchor keyword (since draft 2020-12) names the schema, so it
+	// can be referenced by a plain name fragment, like "#address", rather
+	// than by a json pointer. A $dynamicAnchor names the schema like an
+	// $anchor, and also marks it as a target of "$dynamicRef".
+	Anchor        *anchor `json:"$anchor,omitempty"`
+	DynamicAnchor *anchor `json:"$dynamicAnchor,omitempty"`
+
+	// The value of $dynamicRef (since draft 2020-12) is a reference like
+	// "$ref". If it points to a $dynamicAnchor, it is resolved to the
+	// outermost schema of the dynamic scope of the validation that has a
+	// $dynamicAnchor with the same name, so recursive schemas can be
+	// extended.
+	DynamicRef *dynamicRef `json:"$dynamicRef,omitempty"`
+
+	// The $vocabulary keyword (since draft 2020-12) declares the
+	// vocabularies of a meta-schema, which the schemas that use the
+	// meta-schema in their "$schema" field are validated with. A
+	// vocabulary that maps to true is required, and the schemas are not
+	// compiled if it is unknown.
+	Vocabulary vocabulary `json:"$vocabulary,omitempty"`
+
 	// The $comment keyword is strictly intended for adding comments
 	// to the JSON schema source. Its value must always be a string.
 	Comment *comment `json:"$comment,omitempty"`
@@ -95,6 +129,9 @@ type JsonSchema struct {
 	// object MUST be a valid JSON Schema.
 	Definitions definitions `json:"definitions,omitempty"`
 
+	// The "$defs" keyword is the draft 2020-12 name of "definitions".
+	Defs definitions `json:"$defs,omitempty"`
+
 	// The value of "properties" MUST be an object. Each value of this object
 	// MUST be a valid JSON Schema.
 	// This keyword determines how child instances validate for objects, and
@@ -120,6 +157,10 @@ type JsonSchema struct {
 	// array is the name of a property in the instance.
 	Required required `json:"required,omitempty"`
 
+	// The ajv-keywords "patternRequired" keyword requires an object to have
+	// a property that matches each of the listed regular expressions.
+	PatternRequired *patternRequired `json:"patternRequired,omitempty"`
+
 	// The value of "propertyNames" MUST be a valid JSON Schema.
 	// If the instance is an object, this keyword validates if every property
 	// name in the instance validates against the provided schema. Note the
@@ -138,7 +179,7 @@ type JsonSchema struct {
 	// MUST be a string, and MUST be unique. If the dependency key is a
 	// property in the instance, each of the items in the dependency value
 	// must be a property that exists in the instance.
-	Dependencies dependencies `json:"dependencies,omitempty"`
+	Dependencies *dependencies `json:"dependencies,omitempty"`
 
 	// The value of "patternProperties" MUST be an object. Each property name
 	// of this object SHOULD be a valid regular expression, according to the
@@ -162,7 +203,15 @@ type JsonSchema struct {
 	// If "items" is an array of schemas, validation succeeds if each element
 	// of the instance validates against the schema at the same position,
 	// if any.
-	Items items `json:"items,omitempty"`
+	Items *items `json:"items,omitempty"`
+
+	// The value of "prefixItems" (since draft 2020-12) MUST be a non-empty
+	// array of valid JSON Schemas.
+	// Validation succeeds if each element of the instance validates against
+	// the schema at the same position, if any. In draft 2020-12, "items"
+	// MUST be a single schema, which applies to the elements that follow
+	// the schemas of "prefixItems", and replaces "additionalItems".
+	PrefixItems prefixItems `json:"prefixItems,omitempty"`
 
 	// The value of this keyword MUST be a valid JSON Schema.
 	// An array instance is valid against "contains" if at least one of its
@@ -183,17 +232,55 @@ type JsonSchema struct {
 	// elements.
 	AdditionalItems *additionalItems `json:"additionalItems,omitempty"`
 
+	// tuple is the compiled form of "items" and "additionalItems" (or of
+	// "prefixItems" and "items" in draft 2020-12), which are validated
+	// together by it.
+	tuple *tupleItems
+
+	// refWithSiblings is true if "$ref" is validated like the other
+	// keywords of the schema, as it is since draft 2020-12, rather than
+	// replacing them.
+	refWithSiblings bool
+
+	// propertyPattern is the compiled pattern of a sub-schema of
+	// "patternProperties", which the names of the properties that it
+	// validates match.
+	propertyPattern *regexp.Regexp
+
+	// keywords are the keywords of the schema in the order of their
+	// validation, which orderKeywords() sets when the schema is compiled.
+	keywords []keywordValidator
+
 	// array limitations
 	MinItems    *minItems    `json:"minItems,omitempty"`
 	MaxItems    *maxItems    `json:"maxItems,omitempty"`
 	UniqueItems *uniqueItems `json:"uniqueItems,omitempty"`
 
+	// The ajv-keywords "uniqueItemProperties" keyword requires the object
+	// items of an array to have distinct values of the listed properties.
+	UniqueItemProperties *uniqueItemProperties `json:"uniqueItemProperties,omitempty"`
+
 	// string limitations
 	MinLength *minLength `json:"minLength,omitempty"`
 	MaxLength *maxLength `json:"maxLength,omitempty"`
 	Pattern   *pattern   `json:"pattern,omitempty"`
 	Format    *format    `json:"format,omitempty"`
 
+	// The ajv "formatMinimum", "formatMaximum", "formatExclusiveMinimum"
+	// and "formatExclusiveMaximum" keywords limit the values of the
+	// date-time, date and time formats, which are compared as the instants
+	// or the days they describe.
+	FormatMinimum          *formatMinimum          `json:"formatMinimum,omitempty"`
+	FormatMaximum          *formatMaximum          `json:"formatMaximum,omitempty"`
+	FormatExclusiveMinimum *formatExclusiveMinimum `json:"formatExclusiveMinimum,omitempty"`
+	FormatExclusiveMaximum *formatExclusiveMaximum `json:"formatExclusiveMaximum,omitempty"`
+
+	// The ajv "transform" keyword lists operations, like "trim" and
+	// "toLowerCase", that normalize string values before the other
+	// keywords validate them. It is applied only if the validator enables
+	// transforms.
+	Transform transform `json:"transform,omitempty"`
+
 	// integer/number limitations
 	MultipleOf       *multipleOf       `json:"multipleOf,omitempty"`
 	Minimum          *minimum          `json:"minimum,omitempty"`
@@ -213,6 +300,10 @@ type JsonSchema struct {
 	// the contents.
 	ContentEncoding *contentEncoding `json:"contentEncoding,omitempty"`
 
+	// The contentSchema keyword describes the contents of a string, which
+	// are decoded with the sibling "contentEncoding" and "contentMediaType".
+	ContentSchema *contentSchema `json:"contentSchema,omitempty"`
+
 	// Must be valid against any of the sub-schemas.
 	AnyOf anyOf `json:"anyOf,omitempty"`
 
@@ -244,6 +335,17 @@ type JsonSchema struct {
 	// the document (or the resource it represents), but it will not be
 	// included in any updated or newly created version of the instance.
 	WriteOnly *writeOnly `json:"writeOnly,omitempty"`
+
+	// If "deprecated" (since draft 2019-09) has a value of boolean true, it
+	// indicates that applications should refrain from using the instance,
+	// which may be removed in the future.
+	Deprecated *deprecated `json:"deprecated,omitempty"`
+
+	// The keywords that start with "x-" are not json schema keywords, but
+	// extensions that are kept for the tools that read the schema. The
+	// keywords of the registered extensions (see RegisterExtension()) are
+	// kept here too.
+	extensions map[string]json.RawMessage
 }
 
 // tempJsonSchema is an internal type that created because of the need of
@@ -266,16 +368,26 @@ type tempJsonSchema JsonSchema
 func NewJsonSchema(bytes []byte) (*JsonSchema, error) {
 	var schema *JsonSchema
 
+	// Schemas with a byte order mark, or in UTF-16 or UTF-32, are transcoded
+	// to UTF-8.
+	bytes, err := toUTF8(bytes)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if the string s is a valid json.
-	err := json.Unmarshal(bytes, &schema)
+	err = json.Unmarshal(bytes, &schema)
 	if err != nil {
 		return nil, err
 	}
 
-	err = schema.scanSchema("", "")
+	draft, err := resolveDraft(schema, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = schema.scanSchema("", newCompilationContext(nil, nil, "", draft))
 	if err != nil {
-		fmt.Println("[JsonSchema DEBUG] connectRelatedKeywords() " +
-			"failed: " + err.Error())
 		return nil, err
 	}
 
@@ -286,261 +398,156 @@ func NewJsonSchema(bytes []byte) (*JsonSchema, error) {
 // keywords of the schema (as mentioned in the description of NewJsonSchema()).
 // The function scans the schema in and it's sub-schemas and perform the
 // required connections.
-func (js *JsonSchema) scanSchema(schemaPath string, rootSchemaID string) error {
+func (js *JsonSchema) scanSchema(schemaPath string, ctx *compilationContext) error {
+	js.internPropertyNames(ctx)
 	js.connectRelatedKeywords()
-	js.mapSubSchema(schemaPath, rootSchemaID)
+	js.mapSubSchema(schemaPath, ctx)
+	ctx.scanned = append(ctx.scanned, scannedSchema{schemaPath, js})
+
+	// The compilation goes on after an error, so all the errors of the
+	// schema are reported together.
+	var errs SchemaCompilationErrors
+
+	// Verify that the schema does not use keywords (or forms of keywords)
+	// that belong to a different draft.
+	errs = errs.add(js.checkDraftKeywords(schemaPath, ctx))
+
+	errs = errs.add(ctx.addAnchors(schemaPath, js))
+
+	errs = errs.add(js.compileFormatLimits(schemaPath))
+
+	if js.Transform != nil {
+		errs = errs.add(js.Transform.compile(schemaPath, js))
+	}
 
 	// Connect sub-schemas in "properties" field.
 	for key := range js.Properties {
-		err := js.Properties[key].scanSchema(schemaPath+"/properties/"+key, rootSchemaID)
-		if err != nil {
-			return err
-		}
+		errs = errs.add(js.Properties[key].scanSchema(schemaPath+"/properties/"+jsonwalker.EscapeToken(key), ctx))
 	}
 
 	// Connect sub-schema in "additionalProperties" field.
 	if js.AdditionalProperties != nil {
-		err := js.AdditionalProperties.scanSchema(schemaPath+"/additionalProperties", rootSchemaID)
-		if err != nil {
-			return err
-		}
+		errs = errs.add(js.AdditionalProperties.scanSchema(schemaPath+"/additionalProperties", ctx))
 	}
 
 	// Connect sub-schema in "propertyNames" field.
 	if js.PropertyNames != nil {
-		err := js.PropertyNames.scanSchema(schemaPath+"/propertyNames", rootSchemaID)
-		if err != nil {
-			return err
-		}
+		errs = errs.add(js.PropertyNames.scanSchema(schemaPath+"/propertyNames", ctx))
 	}
 
-	// Connect sub-schemas in "dependencies" field.
-	for key, value := range js.Dependencies {
-		// Check if the dependency is a json schema or an array of properties.
-		if v, ok := value.(map[string]interface{}); ok {
-			subSchema := new(JsonSchema)
-			// Marshal the dependency in order to Unmarshal it into JsonSchema struct.
-			rawDependency, err := json.Marshal(v)
-			if err != nil {
-				return SchemaCompilationError{
-					schemaPath + "/dependencies" + key,
-					err.Error(),
-				}
-			}
+	// Compile the "dependencies" field and connect its sub-schemas.
+	if js.Dependencies != nil {
+		errs = errs.add(js.Dependencies.compile(schemaPath+"/dependencies", ctx))
+	}
 
-			// Create a new JsonSchema instance.
-			err = json.Unmarshal(rawDependency, subSchema)
-			if err != nil {
-				return SchemaCompilationError{
-					schemaPath,
-					err.Error(),
-				}
-			}
+	// Compile the "type" field.
+	if js.Type != nil {
+		errs = errs.add(js.Type.compile(schemaPath))
+	}
 
-			err = subSchema.scanSchema(schemaPath+"/dependencies"+key, rootSchemaID)
-			if err != nil {
-				return err
-			}
+	// Compile the "pattern" field.
+	if js.Pattern != nil {
+		errs = errs.add(js.Pattern.compile(schemaPath))
+	}
 
-			// Save the new JsonSchema as the dependency itself.
-			js.Dependencies[key] = subSchema
-		}
+	// Compile the patterns of the "patternRequired" field.
+	if js.PatternRequired != nil {
+		errs = errs.add(js.PatternRequired.compile(schemaPath))
 	}
 
-	// Connect sub-schemas in "patternProperties" field.
+	// Connect sub-schemas in "patternProperties" field, and compile their
+	// patterns.
 	for key := range js.PatternProperties {
-		err := js.PatternProperties[key].scanSchema(schemaPath+"/patternProperties/"+key, rootSchemaID)
-		if err != nil {
-			return err
-		}
+		subSchemaPath := schemaPath + "/patternProperties/" + jsonwalker.EscapeToken(key)
+		errs = errs.add(js.PatternProperties[key].compilePropertyPattern(key, subSchemaPath))
+		errs = errs.add(js.PatternProperties[key].scanSchema(subSchemaPath, ctx))
 	}
 
 	// Connect sub-schemas in "definitions" field.
 	for key := range js.Definitions {
-		err := js.Definitions[key].scanSchema(schemaPath+"/definitions/"+key, rootSchemaID)
-		if err != nil {
-			return err
-		}
+		errs = errs.add(js.Definitions[key].scanSchema(schemaPath+"/definitions/"+jsonwalker.EscapeToken(key), ctx))
 	}
 
-	// Connect sub-schemas in "items" field.
-	if js.Items != nil {
-		var items interface{}
-
-		// Unmarshal the item to an empty interface variable in order
-		// to check if the "items" is a single schema of a list of schemas.
-		err := json.Unmarshal(js.Items, &items)
-		if err != nil {
-			return SchemaCompilationError{
-				schemaPath,
-				err.Error(),
-			}
-		}
-
-		// Check the type of "items"
-		switch v := items.(type) {
-		// In this case, "items" is an object which means its a single schema.
-		case map[string]interface{}, bool:
-			{
-				// Marshal the dependency in order to Unmarshal it into JsonSchema struct.
-				rawSubSchema, err := json.Marshal(v)
-				if err != nil {
-					return SchemaCompilationError{
-						schemaPath + "/items",
-						err.Error(),
-					}
-				}
-
-				subSchema := new(JsonSchema)
-
-				// Create a new JsonSchema instance.
-				err = json.Unmarshal(rawSubSchema, subSchema)
-				if err != nil {
-					return SchemaCompilationError{
-						schemaPath + "/items",
-						err.Error(),
-					}
-				}
-
-				err = subSchema.scanSchema(schemaPath+"/items", rootSchemaID)
-				if err != nil {
-					return err
-				}
-
-				js.Items, err = json.Marshal(subSchema)
-				if err != nil {
-					return SchemaCompilationError{
-						schemaPath + "/items",
-						err.Error(),
-					}
-				}
-			}
-		// In this case "items" hold an array of schemas.
-		case []interface{}:
-			{
-				// Iterate over each schema in "items".
-				for index, value := range v {
-					// Marshal the dependency in order to Unmarshal it into JsonSchema struct.
-					rawSubSchema, err := json.Marshal(value)
-					if err != nil {
-						return SchemaCompilationError{
-							schemaPath + "/items" + strconv.Itoa(index),
-							err.Error(),
-						}
-					}
-
-					subSchema := new(JsonSchema)
-
-					// Create a new JsonSchema instance.
-					err = json.Unmarshal(rawSubSchema, subSchema)
-					if err != nil {
-						return SchemaCompilationError{
-							path: schemaPath + "/items" + strconv.Itoa(index),
-							err:  "",
-						}
-					}
-
-					err = subSchema.scanSchema(schemaPath+"/items"+strconv.Itoa(index), rootSchemaID)
-					if err != nil {
-						return nil
-					}
-
-					// Save the sub-schema in "items" array.
-					v[index] = subSchema
-				}
+	// Connect sub-schemas in "$defs" field.
+	for key := range js.Defs {
+		errs = errs.add(js.Defs[key].scanSchema(schemaPath+"/$defs/"+jsonwalker.EscapeToken(key), ctx))
+	}
 
-				// Marshal "items" back to a json.RawMessage and store it in the parent schema.
-				js.Items, err = json.Marshal(v)
-				if err != nil {
-					return SchemaCompilationError{
-						schemaPath + "/items",
-						err.Error(),
-					}
-				}
-			}
-		}
+	// Connect sub-schemas in "prefixItems" and "items" fields, or in
+	// "items" field.
+	if js.PrefixItems != nil {
+		errs = errs.add(js.PrefixItems.compile(schemaPath, js, ctx))
+	} else if js.Items != nil {
+		errs = errs.add(js.Items.compile(schemaPath+"/items", js, ctx))
 	}
 
 	// Connect sub-schema in "additionalItems" field.
 	if js.AdditionalItems != nil {
-		err := js.AdditionalItems.scanSchema(schemaPath+"/additionalItems", rootSchemaID)
-		if err != nil {
-			return err
-		}
+		errs = errs.add(js.AdditionalItems.scanSchema(schemaPath+"/additionalItems", ctx))
 	}
 
 	// Connect sub-schema in "contains" field.
 	if js.Contains != nil {
-		err := js.Contains.scanSchema(schemaPath+"/contains", rootSchemaID)
-		if err != nil {
-			return err
-		}
+		errs = errs.add(js.Contains.scanSchema(schemaPath+"/contains", ctx))
 	}
 
 	// Connect sub-schemas in "anyOf" field.
 	for index := range js.AnyOf {
-		err := js.AnyOf[index].scanSchema(schemaPath+"/anyOf/"+strconv.Itoa(index), rootSchemaID)
-		if err != nil {
-			return err
-		}
+		errs = errs.add(js.AnyOf[index].scanSchema(schemaPath+"/anyOf/"+strconv.Itoa(index), ctx))
 	}
 
 	// Connect sub-schemas in "allOf" field.
 	for index := range js.AllOf {
-		err := js.AllOf[index].scanSchema(schemaPath+"/allOf/"+strconv.Itoa(index), rootSchemaID)
-		if err != nil {
-			return err
-		}
+		errs = errs.add(js.AllOf[index].scanSchema(schemaPath+"/allOf/"+strconv.Itoa(index), ctx))
 	}
 
 	// Connect sub-schemas in "oneOf" field.
 	for index := range js.OneOf {
-		err := js.OneOf[index].scanSchema(schemaPath+"/oneOf/"+strconv.Itoa(index), rootSchemaID)
-		if err != nil {
-			return err
-		}
+		errs = errs.add(js.OneOf[index].scanSchema(schemaPath+"/oneOf/"+strconv.Itoa(index), ctx))
 	}
 
 	// Connect sub-schema in "not" field.
 	if js.Not != nil {
-		err := js.Not.scanSchema(schemaPath+"/not", rootSchemaID)
-		if err != nil {
-			return err
-		}
+		errs = errs.add(js.Not.scanSchema(schemaPath+"/not", ctx))
+	}
+
+	// Connect sub-schema in "contentSchema" field.
+	if js.ContentSchema != nil {
+		errs = errs.add(js.ContentSchema.scanSchema(schemaPath+"/contentSchema", ctx))
 	}
 
 	// Connect sub-schema in "if" field.
 	if js.If != nil {
-		err := js.If.scanSchema(schemaPath+"/if", rootSchemaID)
-		if err != nil {
-			return err
-		}
+		errs = errs.add(js.If.scanSchema(schemaPath+"/if", ctx))
 
 		// Connect sub-schema in "then" field.
 		if js.Then != nil {
-			err := js.Then.scanSchema(schemaPath+"/then", rootSchemaID)
-			if err != nil {
-				return err
-			}
+			errs = errs.add(js.Then.scanSchema(schemaPath+"/then", ctx))
 		}
 
 		// Connect sub-schema in "else" field.
 		if js.Else != nil {
-			err := js.Else.scanSchema(schemaPath+"/else", rootSchemaID)
-			if err != nil {
-				return err
-			}
+			errs = errs.add(js.Else.scanSchema(schemaPath+"/else", ctx))
 		}
 	}
 
-	return nil
+	// Since draft 2020-12, "$ref" is validated like the other keywords of
+	// the schema.
+	js.refWithSiblings = js.Ref != nil && ctx.draft == DRAFT_2020_12
+
+	js.orderKeywords()
+
+	return errs.err()
 }
 
 // connectRelatedKeywords is a receiver function that initialized references
 // between keywordValidators that depend on each other:
 // Schema.AdditionalProperties 	---> 	Schema.Properties
 // Schema.AdditionalProperties 	---> 	Schema.PatternProperties
-// JsonSchema.AdditionalItems 	---> 	JsonSchema.Items
+// JsonSchema.ExclusiveMinimum 	---> 	JsonSchema.Minimum
+// JsonSchema.ExclusiveMaximum 	---> 	JsonSchema.Maximum
+// JsonSchema.ContentMediaType	---> 	JsonSchema.ContentEncoding
+// JsonSchema.ContentSchema		---> 	JsonSchema.ContentMediaType
 // JsonSchema.If 				---> 	JsonSchema.Then
 // JsonSchema.IF 				---> 	JsonSchema.Else
 func (js *JsonSchema) connectRelatedKeywords() {
@@ -559,13 +566,28 @@ func (js *JsonSchema) connectRelatedKeywords() {
 		}
 	}
 
-	// Connect sub-schema in "additionalItems" field.
-	if js.AdditionalItems != nil {
-		// If "items" field exists in the schema, save the keywordValidator's
-		// address in "AdditionalItems".
-		if js.Items != nil {
-			js.AdditionalItems.siblingItems = &js.Items
-		}
+	// Connect "exclusiveMinimum" field to "minimum" field, which is required
+	// by the draft-04 boolean form of "exclusiveMinimum".
+	if js.ExclusiveMinimum != nil && js.Minimum != nil {
+		js.ExclusiveMinimum.siblingMinimum = js.Minimum
+	}
+
+	// Connect "exclusiveMaximum" field to "maximum" field, which is required
+	// by the draft-04 boolean form of "exclusiveMaximum".
+	if js.ExclusiveMaximum != nil && js.Maximum != nil {
+		js.ExclusiveMaximum.siblingMaximum = js.Maximum
+	}
+
+	// Connect "contentMediaType" field to "contentEncoding" field, which
+	// encodes the contents that the media type describes.
+	if js.ContentMediaType != nil && js.ContentEncoding != nil {
+		js.ContentMediaType.siblingEncoding = js.ContentEncoding
+	}
+
+	// Connect "contentSchema" field to "contentMediaType" field, which
+	// describes how the contents are decoded.
+	if js.ContentSchema != nil && js.ContentMediaType != nil {
+		js.ContentSchema.siblingMediaType = js.ContentMediaType
 	}
 
 	// Connect sub-schema in "if" field.
@@ -588,103 +610,218 @@ func (js *JsonSchema) connectRelatedKeywords() {
 	}
 }
 
-func (js *JsonSchema) mapSubSchema(schemaPath, rootSchemaID string) {
-	// If the schema path is not an empty string (means we are not in the root schema),
-	// and the rootSchemaID is not an empty string (means the root schema contains
-	// the "$id" field), map the current sub schema into the subSchemaMap of the rootSchema.
-	if schemaPath != "" && rootSchemaID != "" {
-		// If the rootSchema exists in the pool, add the sub schema to it.
-		// Else, TODO: decide what to do.
-		if rs, ok := rootSchemaPool[rootSchemaID]; ok && rs != nil {
-			// If the root schema does not contain the sub schema already, add it to the
-			// subSchemaMap.
-			// Else, TODO: decide what to do.
-			if _, ok := rs.subSchemaMap[schemaPath]; !ok {
-				rs.subSchemaMap[schemaPath] = js
+// checkDraftKeywords returns the SchemaCompilationErrors of the keyword forms
+// of the schema that are not supported by the draft of the root schema.
+func (js *JsonSchema) checkDraftKeywords(schemaPath string, ctx *compilationContext) error {
+	var errs SchemaCompilationErrors
+
+	// The boolean forms of "exclusiveMinimum" and "exclusiveMaximum" were
+	// replaced by numeric forms after draft-04.
+	if ctx.draft != DRAFT_04 {
+		if js.ExclusiveMinimum != nil && js.ExclusiveMinimum.boolean != nil {
+			errs = append(errs, SchemaCompilationError{
+				path: schemaPath + "/exclusiveMinimum",
+				err:  "a boolean \"exclusiveMinimum\" is only supported in draft-04 schemas",
+			})
+		}
+
+		if js.ExclusiveMaximum != nil && js.ExclusiveMaximum.boolean != nil {
+			errs = append(errs, SchemaCompilationError{
+				path: schemaPath + "/exclusiveMaximum",
+				err:  "a boolean \"exclusiveMaximum\" is only supported in draft-04 schemas",
+			})
+		}
+	}
+
+	if ctx.draft == DRAFT_2020_12 {
+		// "prefixItems" replaced the array form of "items", and the single
+		// schema of "items" replaced "additionalItems".
+		if js.Items != nil && js.Items.isArray() {
+			errs = append(errs, SchemaCompilationError{
+				path: schemaPath + "/items",
+				err:  "\"items\" must be a single schema in draft 2020-12 schemas, use \"prefixItems\" for an array of schemas",
+			})
+		}
+
+		if js.AdditionalItems != nil {
+			errs = append(errs, SchemaCompilationError{
+				path: schemaPath + "/additionalItems",
+				err:  "\"additionalItems\" is replaced by \"items\" in draft 2020-12 schemas",
+			})
+		}
+	} else {
+		keywords := []struct {
+			name    string
+			present bool
+		}{
+			{"$anchor", js.Anchor != nil},
+			{"$dynamicAnchor", js.DynamicAnchor != nil},
+			{"$dynamicRef", js.DynamicRef != nil},
+			{"$vocabulary", js.Vocabulary != nil},
+			{"prefixItems", js.PrefixItems != nil},
+		}
+
+		for _, keyword := range keywords {
+			if keyword.present {
+				errs = append(errs, SchemaCompilationError{
+					path: schemaPath + "/" + keyword.name,
+					err:  "\"" + keyword.name + "\" is only supported in draft 2020-12 schemas",
+				})
 			}
 		}
 	}
+
+	return errs.err()
 }
 
-// validateJsonData is a function that gets a byte array of data and validates
-// it against the schema that encoded in the receiver's field.
-func (js *JsonSchema) validateJsonData(jsonPath string, bytes []byte, rootSchemaId string) error {
+func (js *JsonSchema) mapSubSchema(schemaPath string, ctx *compilationContext) {
+	// If the schema path is not an empty string (means we are not in the root schema),
+	// map the current sub schema into the subSchemaMap of the rootSchema.
+	// The sub-schemas are mapped into the root schema that is compiled, and
+	// never into a registered schema with the same "$id", which may be
+	// validating documents concurrently.
+	if schemaPath != "" && ctx.rootSchema != nil {
+		// If the root schema does not contain the sub schema already, add it to the
+		// subSchemaMap.
+		if _, ok := ctx.rootSchema.subSchemaMap[schemaPath]; !ok {
+			ctx.rootSchema.subSchemaMap[schemaPath] = js
+		}
+	}
+}
+
+// newJsonData creates a json data container for a decoded json value.
+func newJsonData(value interface{}) jsonData {
+	return jsonData{value}
+}
+
+// validateJsonData is a function that gets a json value and validates it
+// against the schema that encoded in the receiver's field. jsonPath is the
+// absolute json pointer of the value in the validated document, and it is
+// only used to report where a validation failed.
+func (js *JsonSchema) validateJsonData(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	// Keep track of the schemas that the values are validated against, so
+	// the output can be nested in them.
+	if ctx.collectsOutput {
+		ctx.enterScope(jsonPath)
+		defer ctx.leaveScope()
+	}
+
 	// If RejectAll field exists and true, reject the value.
 	if js.RejectAll {
-		return SchemaValidationError{
-			jsonPath,
-			"json schema \"false\" drops everything",
+		location := ctx.location()
+		err := SchemaValidationError{
+			path:                    jsonPath,
+			keywordLocation:         location.keywordLocation,
+			absoluteKeywordLocation: location.absoluteKeywordLocation,
+			err:                     "json schema \"false\" drops everything",
+			cause:                   ErrFalseSchema,
 		}
+		ctx.recordError(err)
+		return err
 	}
 
 	// If the schema contains the $ref field, validate the data against the
 	// referenced schema (and by the way ignore all the keywords of the current
 	// schema).
-	if js.Ref != nil {
-		return js.Ref.validateByRef(jsonPath, bytes, rootSchemaId)
+	if js.Ref != nil && !js.refWithSiblings {
+		return js.Ref.validateByRef(jsonPath, jsonData, rootSchemaId, ctx)
 	}
 
-	// Calculate the relative path in order to evaluate the data
-	jsonTokens := strings.Split(jsonPath, "/")
-	relativeJsonPath := "/" + jsonTokens[len(jsonTokens)-1]
-
-	// Create a new JsonPointer.
-	jsonPointer, err := jsonwalker.NewJsonPointer(relativeJsonPath)
-	if err != nil {
-		fmt.Println("[JsonSchema DEBUG] validateJsonData() " +
-			"failed while trying to create JsonPointer " + jsonPath)
-		return errors.Wrap(err, "JsonPointer creation failed")
-	}
+	// Keep track of the nesting level of the validated schemas, so keywords
+	// can tell whether they belong to the root schema.
+	ctx.depth++
+	defer func() {
+		ctx.depth--
+	}()
 
-	// Get the piece of json that the current schema describes.
-	value, err := jsonPointer.Evaluate(bytes)
+	// Report the visited node to the validation context, which may abort
+	// the validation (for example, when a progress hook enforces a limit).
+	err := ctx.visit(jsonData.value)
 	if err != nil {
-		fmt.Println("[JsonSchema DEBUG] validateJsonData() " +
-			"failed while trying to evaluate a JsonPointer " + jsonPath)
-		return errors.Wrap(err, "JsonPointer evaluation failed")
+		return err
 	}
 
-	// Marshal the evaluated value to a byte array.
-	newBytes, err := json.Marshal(value)
-	if err != nil {
-		return errors.Wrap(err, "data marshaling after JsonPointer evaluation failed")
+	if js.Transform != nil {
+		jsonData, err = js.transformValue(jsonPath, jsonData, ctx)
+		if err != nil {
+			return err
+		}
 	}
 
-	// Create a new json data container
-	jsonData := jsonData{
-		newBytes,
-		value,
+	if ctx.injectDefaults {
+		jsonData, err = js.injectDefaults(jsonPath, jsonData, ctx)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Get a slice of all of JsonSchema's field in order to iterate them
-	// and call each of their validate() functions.
-	keywordValidators := getNonNilKeywordsSlice(js)
+	// and call each of their validate() functions. Compiled schemas keep
+	// their keywords in the order of their cost.
+	keywordValidators := js.keywordValidators()
+
+	// firstErr holds the first failure when the output of the validation
+	// is collected, in which case the rest of the keywords are validated
+	// too.
+	var firstErr error
 
 	// Iterate over the keywords.
 	for _, keyword := range keywordValidators {
 		// Validate the value that we extracted from the jsonData at each
 		// keyword.
-		err := keyword.validate(jsonPath, jsonData, rootSchemaId)
+		output := ctx.outputMark()
+		var err error
+		if ctx.profiling() {
+			start := time.Now()
+			err = keyword.validate(jsonPath, jsonData, rootSchemaId, ctx)
+			ctx.profileKeyword(keywordName(keyword), time.Since(start))
+		} else {
+			err = keyword.validate(jsonPath, jsonData, rootSchemaId, ctx)
+		}
 		if err != nil {
 			// If the error is a SchemaValidationError, it means it came from
 			// a deeper call to this function, so we do not touch the error.
-			if schemaValidationError, ok := err.(SchemaValidationError); ok {
-				return schemaValidationError
+			// If the error is a KeywordValidationError, create a new
+			// SchemaValidationError.
+			if _, ok := err.(SchemaValidationError); !ok {
+				err = ctx.schemaValidationError(jsonPath, err)
 			}
 
-			// If the error is a KeywordValidationError, create a new
-			// SchemaValidationError and return it.
-			if keywordValidationError, ok := err.(KeywordValidationError); ok {
-				return SchemaValidationError{
-					jsonPath,
-					keywordValidationError.Error(),
-				}
+			// The failure is recorded, unless the keyword's sub-schemas
+			// already recorded the failures it consists of.
+			if len(ctx.errors) == output.errors {
+				ctx.recordError(err)
 			}
 
-			return err
+			if !ctx.continuesAfter(err) {
+				return err
+			}
+
+			if firstErr == nil {
+				firstErr = err
+			}
 		}
 	}
 
+	if len(ctx.validator.extensionKeywords) > 0 && len(js.extensions) > 0 {
+		err := js.validateExtensionKeywords(jsonData, ctx)
+		if err != nil {
+			err = ctx.schemaValidationError(jsonPath, err)
+			ctx.recordError(err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if firstErr != nil {
+		return firstErr
+	}
+
+	if ctx.collectsOutput {
+		ctx.recordAnnotations(jsonPath, js)
+	}
+
 	return nil
 }
 
@@ -721,6 +858,34 @@ func getNonNilKeywordsSlice(js *JsonSchema) []keywordValidator {
 		slice = append(slice, js.Format)
 	}
 
+	if js.ContentEncoding != nil {
+		slice = append(slice, js.ContentEncoding)
+	}
+
+	if js.ContentMediaType != nil {
+		slice = append(slice, js.ContentMediaType)
+	}
+
+	if js.ContentSchema != nil {
+		slice = append(slice, js.ContentSchema)
+	}
+
+	if js.FormatMinimum != nil {
+		slice = append(slice, js.FormatMinimum)
+	}
+
+	if js.FormatMaximum != nil {
+		slice = append(slice, js.FormatMaximum)
+	}
+
+	if js.FormatExclusiveMinimum != nil {
+		slice = append(slice, js.FormatExclusiveMinimum)
+	}
+
+	if js.FormatExclusiveMaximum != nil {
+		slice = append(slice, js.FormatExclusiveMaximum)
+	}
+
 	if js.MultipleOf != nil {
 		slice = append(slice, js.MultipleOf)
 	}
@@ -745,6 +910,10 @@ func getNonNilKeywordsSlice(js *JsonSchema) []keywordValidator {
 		slice = append(slice, js.Required)
 	}
 
+	if js.PatternRequired != nil {
+		slice = append(slice, js.PatternRequired)
+	}
+
 	if js.PropertyNames != nil {
 		slice = append(slice, js.PropertyNames)
 	}
@@ -773,18 +942,14 @@ func getNonNilKeywordsSlice(js *JsonSchema) []keywordValidator {
 		slice = append(slice, js.MaxProperties)
 	}
 
-	if js.Items != nil {
-		slice = append(slice, js.Items)
+	if js.tuple != nil {
+		slice = append(slice, js.tuple)
 	}
 
 	if js.Contains != nil {
 		slice = append(slice, js.Contains)
 	}
 
-	if js.AdditionalItems != nil {
-		slice = append(slice, js.AdditionalItems)
-	}
-
 	if js.MinItems != nil {
 		slice = append(slice, js.MinItems)
 	}
@@ -797,6 +962,10 @@ func getNonNilKeywordsSlice(js *JsonSchema) []keywordValidator {
 		slice = append(slice, js.UniqueItems)
 	}
 
+	if js.UniqueItemProperties != nil {
+		slice = append(slice, js.UniqueItemProperties)
+	}
+
 	if js.AnyOf != nil {
 		slice = append(slice, js.AnyOf)
 	}
@@ -817,10 +986,46 @@ func getNonNilKeywordsSlice(js *JsonSchema) []keywordValidator {
 		slice = append(slice, js.If)
 	}
 
+	if js.Ref != nil && js.refWithSiblings {
+		slice = append(slice, js.Ref)
+	}
+
+	if js.DynamicRef != nil {
+		slice = append(slice, js.DynamicRef)
+	}
+
 	// Return the map.
 	return slice
 }
 
+// Extension returns the raw value of an extension keyword of the schema,
+// which is a keyword that starts with "x-" or a keyword of a registered
+// extension, or nil if the schema does not have it.
+func (js *JsonSchema) Extension(keyword string) json.RawMessage {
+	return js.extensions[keyword]
+}
+
+// MarshalJSON encodes the schema with its extension keywords, so a schema
+// that is marshaled and unmarshaled again keeps them.
+func (js *JsonSchema) MarshalJSON() ([]byte, error) {
+	bytes, err := json.Marshal((*tempJsonSchema)(js))
+	if err != nil || len(js.extensions) == 0 {
+		return bytes, err
+	}
+
+	var object map[string]json.RawMessage
+	err = json.Unmarshal(bytes, &object)
+	if err != nil {
+		return nil, err
+	}
+
+	for keyword, keywordValue := range js.extensions {
+		object[keyword] = keywordValue
+	}
+
+	return json.Marshal(object)
+}
+
 func (js *JsonSchema) UnmarshalJSON(bytes []byte) error {
 	// First, unmarshal the raw data into empty interface variable
 	// in order to figure out its type.
@@ -849,6 +1054,24 @@ func (js *JsonSchema) UnmarshalJSON(bytes []byte) error {
 			// Convert the temporary type to JsonSchema and assign its address
 			// to the receiver.
 			*js = JsonSchema(*tempSchema)
+
+			// Keep the extension keywords and the keywords of the
+			// registered extensions, which the temporary type does not
+			// have fields for.
+			for keyword, keywordValue := range schema {
+				if !strings.HasPrefix(keyword, "x-") && !isExtensionKeyword(keyword) {
+					continue
+				}
+
+				if js.extensions == nil {
+					js.extensions = make(map[string]json.RawMessage)
+				}
+
+				js.extensions[keyword], err = json.Marshal(keywordValue)
+				if err != nil {
+					return err
+				}
+			}
 		}
 	case bool:
 		{
@@ -1 +1,755 @@
 package jsonvalidator
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+func TestDraft04ExclusiveLimits(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$schema": "http://json-schema.org/draft-04/schema#",
+		"properties": {
+			"a": {"minimum": 5, "exclusiveMinimum": true},
+			"b": {"maximum": 5, "exclusiveMaximum": false}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		description string
+		data        string
+		valid       bool
+	}{
+		{"a value above the exclusive minimum", `{"a": 6}`, true},
+		{"a value equal to the exclusive minimum", `{"a": 5}`, false},
+		{"a value equal to the inclusive maximum", `{"b": 5}`, true},
+		{"a value above the inclusive maximum", `{"b": 6}`, false},
+	}
+
+	validator := NewValidator(rootSchema)
+	for _, testCase := range testCases {
+		err := validator.Validate([]byte(testCase.data))
+		if (err == nil) != testCase.valid {
+			t.Errorf("%s: expected valid=%t, got %v", testCase.description, testCase.valid, err)
+		}
+	}
+}
+
+func TestBooleanExclusiveLimitOutsideDraft04(t *testing.T) {
+	_, err := NewJsonSchema([]byte(`{"minimum": 5, "exclusiveMinimum": true}`))
+	if _, ok := err.(SchemaCompilationErrors); !ok {
+		t.Errorf("expected SchemaCompilationErrors, got %v", err)
+	}
+}
+
+func TestUnsupportedDraft(t *testing.T) {
+	supported := []string{
+		"http://json-schema.org/draft-04/schema#",
+		"https://json-schema.org/draft-06/schema",
+		"http://json-schema.org/draft-07/schema",
+		"https://json-schema.org/draft/2020-12/schema",
+	}
+	for _, uri := range supported {
+		if _, err := NewRootJsonSchema([]byte(`{"$schema": "` + uri + `"}`)); err != nil {
+			t.Errorf("%s: unexpected error %v", uri, err)
+		}
+	}
+
+	unsupported := []string{
+		"http://json-schema.org/draft-03/schema#",
+		"https://json-schema.org/draft/2019-09/schema",
+		"http://example.com/custom-dialect.json",
+	}
+	for _, uri := range unsupported {
+		_, err := NewRootJsonSchema([]byte(`{"$schema": "` + uri + `"}`))
+		if err != InvalidDraftError(uri) {
+			t.Errorf("%s: expected an InvalidDraftError, got %v", uri, err)
+		}
+
+		_, err = NewJsonSchema([]byte(`{"$schema": "` + uri + `"}`))
+		if err != InvalidDraftError(uri) {
+			t.Errorf("%s: expected an InvalidDraftError from NewJsonSchema, got %v", uri, err)
+		}
+	}
+}
+
+func TestDraft202012PrefixItems(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"prefixItems": [{"type": "string"}, {"type": "integer"}],
+		"items": {"type": "boolean"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		data     string
+		expected string
+	}{
+		{`["a", 1, true, false]`, ""},
+		{`["a"]`, ""},
+		{`[]`, ""},
+		{`[1]`, "/prefixItems/0/type"},
+		{`["a", 1, "b"]`, "/items/type"},
+	}
+
+	for _, testCase := range testCases {
+		err := NewValidator(rootSchema).Validate([]byte(testCase.data))
+		if testCase.expected == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", testCase.data, err)
+			}
+			continue
+		}
+
+		schemaValidationError, ok := err.(SchemaValidationError)
+		if !ok || schemaValidationError.KeywordLocation() != testCase.expected {
+			t.Errorf("%s: expected an error at %s, got %v", testCase.data, testCase.expected, err)
+		}
+	}
+}
+
+func TestDraft202012Keywords(t *testing.T) {
+	testCases := []struct {
+		description string
+		schema      string
+		expected    []string
+	}{
+		{
+			"an array of schemas in items",
+			`{"$schema": "https://json-schema.org/draft/2020-12/schema", "items": [{}], "additionalItems": false}`,
+			[]string{"/additionalItems", "/items"},
+		},
+		{
+			"draft 2020-12 keywords in a draft-07 schema",
+			`{"prefixItems": [{}], "$defs": {"a": {"$anchor": "a", "$dynamicRef": "#a"}}}`,
+			[]string{"/$defs/a/$anchor", "/$defs/a/$dynamicRef", "/prefixItems"},
+		},
+		{
+			"invalid and duplicate anchors",
+			`{"$schema": "https://json-schema.org/draft/2020-12/schema",
+			  "$defs": {"a": {"$anchor": "1a"}, "b": {"$anchor": "b"}, "c": {"$dynamicAnchor": "b"}}}`,
+			[]string{"/$defs/a/$anchor", "/$defs/c/$dynamicAnchor"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		_, err := NewRootJsonSchema([]byte(testCase.schema))
+		errs, ok := err.(SchemaCompilationErrors)
+		if !ok {
+			t.Errorf("%s: expected SchemaCompilationErrors, got %v", testCase.description, err)
+			continue
+		}
+
+		var paths []string
+		for _, compilationError := range errs {
+			paths = append(paths, compilationError.Path())
+		}
+		if !reflect.DeepEqual(paths, testCase.expected) {
+			t.Errorf("%s: expected errors at %v, got %v", testCase.description, testCase.expected, errs)
+		}
+	}
+}
+
+func TestDraft202012Ref(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id": "http://example.com/draft2020/ref.json",
+		"$ref": "#positive",
+		"maximum": 10,
+		"$defs": {"positive": {"$anchor": "positive", "minimum": 0}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		data     string
+		expected string
+	}{
+		{`5`, ""},
+		{`-1`, "http://example.com/draft2020/ref.json#/$defs/positive/minimum"},
+		{`11`, "http://example.com/draft2020/ref.json#/maximum"},
+	}
+
+	for _, testCase := range testCases {
+		err := NewValidator(rootSchema).Validate([]byte(testCase.data))
+		if testCase.expected == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", testCase.data, err)
+			}
+			continue
+		}
+
+		schemaValidationError, ok := err.(SchemaValidationError)
+		if !ok || schemaValidationError.AbsoluteKeywordLocation() != testCase.expected {
+			t.Errorf("%s: expected an error at %s, got %v", testCase.data, testCase.expected, err)
+		}
+	}
+}
+
+func TestDynamicRef(t *testing.T) {
+	tree, err := NewRootJsonSchema([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id": "http://example.com/draft2020/tree",
+		"$dynamicAnchor": "node",
+		"type": "object",
+		"properties": {
+			"data": true,
+			"children": {"type": "array", "items": {"$dynamicRef": "#node"}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	integerTree, err := NewRootJsonSchema([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id": "http://example.com/draft2020/integer-tree",
+		"$dynamicAnchor": "node",
+		"$ref": "tree",
+		"properties": {"data": {"type": "integer"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document := []byte(`{"data": 1, "children": [{"data": 2}, {"children": [{"data": "three"}]}]}`)
+
+	if err := NewValidator(tree).Validate(document); err != nil {
+		t.Errorf("expected the document to be a valid tree, got %v", err)
+	}
+
+	err = NewValidator(integerTree).Validate(document)
+	schemaValidationError, ok := err.(SchemaValidationError)
+	if !ok || schemaValidationError.Path() != "/children/1/children/0/data" {
+		t.Errorf("expected the nested string data to fail, got %v", err)
+	}
+}
+
+func TestVocabulary(t *testing.T) {
+	_, err := AddResource("http://example.com/draft2020/meta", []byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$vocabulary": {
+			"https://json-schema.org/draft/2020-12/vocab/core": true,
+			"https://json-schema.org/draft/2020-12/vocab/applicator": true,
+			"http://example.com/vocab/optional": false
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$schema": "http://example.com/draft2020/meta",
+		"prefixItems": [{"type": "string"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewValidator(rootSchema).Validate([]byte(`[1]`)); err == nil {
+		t.Error("expected the schema to be compiled as a draft 2020-12 schema")
+	}
+
+	_, err = AddResource("http://example.com/draft2020/unknown-meta", []byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$vocabulary": {"http://example.com/vocab/required": true}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewRootJsonSchema([]byte(`{"$schema": "http://example.com/draft2020/unknown-meta"}`))
+	if err != UnsupportedVocabularyError("http://example.com/vocab/required") {
+		t.Errorf("expected an UnsupportedVocabularyError, got %v", err)
+	}
+}
+
+func TestNewRootJsonSchemaForDraft(t *testing.T) {
+	schema := []byte(`{"$schema": "http://json-schema.org/draft-07/schema#", "prefixItems": [{"type": "string"}]}`)
+
+	if _, err := NewRootJsonSchema(schema); err == nil {
+		t.Error("expected \"prefixItems\" to fail the compilation of a draft-07 schema")
+	}
+
+	rootSchema, err := NewRootJsonSchemaForDraft(DRAFT_2020_12, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewValidator(rootSchema).Validate([]byte(`[1]`)); err == nil {
+		t.Error("expected the schema to be compiled as a draft 2020-12 schema")
+	}
+
+	if _, err := NewRootJsonSchemaForDraft("draft-3", []byte(`{}`)); err != InvalidDraftError("draft-3") {
+		t.Errorf("expected an InvalidDraftError, got %v", err)
+	}
+}
+
+func TestItemsCompilationError(t *testing.T) {
+	_, err := NewJsonSchema([]byte(`{"items": 5}`))
+	if _, ok := err.(SchemaCompilationErrors); !ok {
+		t.Errorf("expected SchemaCompilationErrors, got %v", err)
+	}
+}
+
+func TestAllCompilationErrors(t *testing.T) {
+	_, err := NewRootJsonSchema([]byte(`{
+		"properties": {
+			"a": {"items": 5},
+			"b": {"transform": ["unknown"]}
+		},
+		"dependencies": {"c": 5},
+		"items": [{}, {"minimum": 1, "exclusiveMinimum": true}]
+	}`))
+
+	errs, ok := err.(SchemaCompilationErrors)
+	if !ok {
+		t.Fatalf("expected SchemaCompilationErrors, got %v", err)
+	}
+
+	expected := []string{
+		"/dependencies/c",
+		"/items/1/exclusiveMinimum",
+		"/properties/a/items",
+		"/properties/b/transform/0",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), errs)
+	}
+	for index, path := range expected {
+		if errs[index].Path() != path {
+			t.Errorf("error %d: expected path %s, got %s", index, path, errs[index].Path())
+		}
+	}
+}
+
+func TestPatternCompilationErrors(t *testing.T) {
+	_, err := NewRootJsonSchema([]byte(`{
+		"properties": {"a": {"pattern": "^(a"}},
+		"patternProperties": {"^x-[": {}, "^y-": {"pattern": "[z-a]"}}
+	}`))
+
+	errs, ok := err.(SchemaCompilationErrors)
+	if !ok {
+		t.Fatalf("expected SchemaCompilationErrors, got %v", err)
+	}
+
+	expected := []string{
+		"/patternProperties/^x-[",
+		"/patternProperties/^y-/pattern",
+		"/properties/a/pattern",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), errs)
+	}
+	for index, path := range expected {
+		if errs[index].Path() != path {
+			t.Errorf("error %d: expected path %s, got %s", index, path, errs[index].Path())
+		}
+	}
+}
+
+func TestTypeAndItemsCompilation(t *testing.T) {
+	_, err := NewRootJsonSchema([]byte(`{
+		"properties": {"a": {"type": 5}, "b": {"type": ["string", 1]}},
+		"items": [{"type": "string"}, 5]
+	}`))
+
+	errs, ok := err.(SchemaCompilationErrors)
+	if !ok {
+		t.Fatalf("expected SchemaCompilationErrors, got %v", err)
+	}
+
+	expected := []string{
+		"/items/1",
+		"/properties/a/type",
+		"/properties/b/type",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), errs)
+	}
+	for index, path := range expected {
+		if errs[index].Path() != path {
+			t.Errorf("error %d: expected path %s, got %s", index, path, errs[index].Path())
+		}
+	}
+
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"items": [{"type": ["string", "null"]}, {"type": "integer"}],
+		"additionalItems": {"type": "boolean"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bytes, err := json.Marshal(rootSchema.Items)
+	if err != nil || string(bytes) != `[{"type":["string","null"]},{"type":"integer"}]` {
+		t.Errorf("\"items\" marshaled to %s, %v", bytes, err)
+	}
+
+	testCases := []struct {
+		document string
+		valid    bool
+	}{
+		{`["a", 1, true]`, true},
+		{`[null, 1]`, true},
+		{`[1, 1]`, false},
+		{`["a", 1.5]`, false},
+		{`["a", 1, "b"]`, false},
+	}
+	for _, testCase := range testCases {
+		err := rootSchema.Validate([]byte(testCase.document))
+		if valid := err == nil; valid != testCase.valid {
+			t.Errorf("Validate(%s) returned %v, expected valid: %v", testCase.document, err, testCase.valid)
+		}
+	}
+}
+
+func TestKeywordOrder(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"oneOf": [{"pattern": "^a"}, {"pattern": "^b"}],
+		"pattern": "^[ab]",
+		"format": "email",
+		"required": ["name"],
+		"type": "object"
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, keyword := range rootSchema.keywords {
+		names = append(names, keywordName(keyword))
+	}
+	expected := []string{"type", "required", "pattern", "format", "oneOf"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected the keywords in the order %v, got %v", expected, names)
+	}
+
+	// The cheapest failing keyword is reported.
+	err = NewValidator(rootSchema).Validate([]byte(`"c"`))
+	if schemaValidationError, ok := err.(SchemaValidationError); !ok || schemaValidationError.Keyword() != "type" {
+		t.Errorf("expected a failure of \"type\", got %v", err)
+	}
+}
+
+func TestErrorInstancePath(t *testing.T) {
+	testCases := []struct {
+		description string
+		schema      string
+		data        string
+		path        string
+	}{
+		{
+			"a property inside allOf",
+			`{"properties": {"a": {"allOf": [{"properties": {"b": {"type": "string"}}}]}}}`,
+			`{"a": {"b": 1}}`,
+			"/a/b",
+		},
+		{
+			"not inside a property",
+			`{"properties": {"a": {"not": {"type": "string"}}}}`,
+			`{"a": "x"}`,
+			"/a",
+		},
+		{
+			"then inside a property",
+			`{"properties": {"a": {"if": {"type": "object"}, "then": {"required": ["x"]}}}}`,
+			`{"a": {}}`,
+			"/a",
+		},
+		{
+			"additionalProperties with an escaped property name",
+			`{"additionalProperties": {"properties": {"c": {"minimum": 5}}}}`,
+			`{"x/y": {"c": 1}}`,
+			"/x~1y/c",
+		},
+		{
+			"a schema dependency",
+			`{"properties": {"o": {"dependencies": {"a": {"properties": {"b": {"type": "integer"}}}}}}}`,
+			`{"o": {"a": 1, "b": "s"}}`,
+			"/o/b",
+		},
+		{
+			"anyOf inside items",
+			`{"items": {"anyOf": [{"type": "string"}]}}`,
+			`["a", 1]`,
+			"/1",
+		},
+		{
+			"additionalItems after the schemas of items",
+			`{"items": [{}, {}], "additionalItems": {"type": "string"}}`,
+			`[1, 2, "a", 3]`,
+			"/3",
+		},
+		{
+			"additionalProperties inside items",
+			`{"items": {"properties": {"a": {}}, "additionalProperties": false}}`,
+			`[{"a": 1}, {"b": 1}]`,
+			"/1/b",
+		},
+		{
+			"a reference inside a property",
+			`{
+				"$id": "http://example.com/instance-path.json",
+				"definitions": {"positive": {"minimum": 0}},
+				"properties": {"n": {"$ref": "#/definitions/positive"}}
+			}`,
+			`{"n": -1}`,
+			"/n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		rootSchema, err := NewRootJsonSchema([]byte(testCase.schema))
+		if err != nil {
+			t.Fatalf("%s: %v", testCase.description, err)
+		}
+
+		err = NewValidator(rootSchema).Validate([]byte(testCase.data))
+		schemaValidationError, ok := err.(SchemaValidationError)
+		if !ok {
+			t.Errorf("%s: expected a SchemaValidationError, got %v", testCase.description, err)
+			continue
+		}
+
+		if schemaValidationError.Path() != testCase.path {
+			t.Errorf("%s: expected path %q, got %q", testCase.description, testCase.path, schemaValidationError.Path())
+		}
+	}
+}
+
+func TestErrorsIsAs(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"properties": {
+			"a": {"allOf": [{"type": "string"}]},
+			"b": {"format": "email"},
+			"c": false,
+			"d": {"$ref": "#/definitions/missing"},
+			"e": {"required": ["f"]}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		document string
+		target   error
+	}{
+		{`{"a": 1}`, ErrTypeMismatch},
+		{`{"b": "no"}`, ErrInvalidFormat},
+		{`{"c": 1}`, ErrFalseSchema},
+		{`{"d": 1}`, ErrRefNotFound},
+		{`{"e": {}}`, ErrRequiredProperty},
+	}
+	for _, testCase := range testCases {
+		err := rootSchema.Validate([]byte(testCase.document))
+		if !errors.Is(err, testCase.target) {
+			t.Errorf("Validate(%s) returned %v, expected it to match %v", testCase.document, err, testCase.target)
+		}
+		if errors.Is(err, ErrInvalidSchema) {
+			t.Errorf("Validate(%s) returned %v, which matches %v", testCase.document, err, ErrInvalidSchema)
+		}
+	}
+
+	// The failure of the nested "type" keyword is part of the chain of the
+	// failure of "allOf".
+	err = rootSchema.Validate([]byte(`{"a": 1}`))
+	var schemaValidationError SchemaValidationError
+	if !errors.As(err, &schemaValidationError) || schemaValidationError.Path() != "/a" {
+		t.Errorf("expected a SchemaValidationError at /a, got %v", err)
+	}
+	var keywordValidationError KeywordValidationError
+	if !errors.As(err, &keywordValidationError) || keywordValidationError.Keyword() != "allOf" {
+		t.Errorf("expected the KeywordValidationError of \"allOf\", got %v", err)
+	}
+
+	_, err = NewRootJsonSchema([]byte(`{"properties": {"a": {"pattern": "("}, "b": {"type": 1}}}`))
+	var compilationError SchemaCompilationError
+	if !errors.Is(err, ErrInvalidSchema) || !errors.As(err, &compilationError) || compilationError.Path() != "/properties/a/pattern" {
+		t.Errorf("expected SchemaCompilationErrors that match %v, got %v", ErrInvalidSchema, err)
+	}
+
+	_, err = NewRootJsonSchemaForDraft("draft-01", []byte(`{}`))
+	if !errors.Is(err, ErrUnsupportedDraft) {
+		t.Errorf("expected an error that matches %v, got %v", ErrUnsupportedDraft, err)
+	}
+
+	// The compilation errors of references keep the InvalidReferenceError.
+	_, err = NewRegistry().Compile([]byte(`{"properties": {"a": {"$ref": "#/definitions/missing"}}}`))
+	if !errors.Is(err, ErrInvalidSchema) || !errors.Is(err, ErrRefNotFound) {
+		t.Errorf("expected an error that matches %v and %v, got %v", ErrInvalidSchema, ErrRefNotFound, err)
+	}
+}
+
+func TestErrorKeywordLocation(t *testing.T) {
+	testCases := []struct {
+		description             string
+		schema                  string
+		data                    string
+		keywordLocation         string
+		absoluteKeywordLocation string
+	}{
+		{
+			"a keyword of a property",
+			`{"$id": "http://example.com/location/properties.json", "properties": {"age": {"minimum": 0}}}`,
+			`{"age": -1}`,
+			"/properties/age/minimum",
+			"http://example.com/location/properties.json#/properties/age/minimum",
+		},
+		{
+			"a keyword behind a reference",
+			`{
+				"$id": "http://example.com/location/ref.json",
+				"definitions": {"age": {"minimum": 0}},
+				"properties": {"age": {"$ref": "#/definitions/age"}}
+			}`,
+			`{"age": -1}`,
+			"/properties/age/$ref/minimum",
+			"http://example.com/location/ref.json#/definitions/age/minimum",
+		},
+		{
+			"a keyword inside allOf and items",
+			`{"$id": "http://example.com/location/allof.json", "allOf": [{}, {"items": [{"type": "string"}]}]}`,
+			`[1]`,
+			"/allOf/1/items/0/type",
+			"http://example.com/location/allof.json#/allOf/1/items/0/type",
+		},
+		{
+			"a keyword of additionalItems",
+			`{"$id": "http://example.com/location/additionalitems.json", "items": [{}], "additionalItems": {"type": "string"}}`,
+			`[1, "a", 2]`,
+			"/additionalItems/type",
+			"http://example.com/location/additionalitems.json#/additionalItems/type",
+		},
+		{
+			"a false schema",
+			`{"$id": "http://example.com/location/false.json", "additionalProperties": false}`,
+			`{"a": 1}`,
+			"/additionalProperties",
+			"http://example.com/location/false.json#/additionalProperties",
+		},
+		{
+			"a keyword of a then schema",
+			`{"$id": "http://example.com/location/then.json", "if": {"type": "object"}, "then": {"maxProperties": 0}}`,
+			`{"a": 1}`,
+			"/then/maxProperties",
+			"http://example.com/location/then.json#/then/maxProperties",
+		},
+	}
+
+	for _, testCase := range testCases {
+		rootSchema, err := NewRootJsonSchema([]byte(testCase.schema))
+		if err != nil {
+			t.Fatalf("%s: %v", testCase.description, err)
+		}
+
+		err = NewValidator(rootSchema).Validate([]byte(testCase.data))
+		schemaValidationError, ok := err.(SchemaValidationError)
+		if !ok {
+			t.Errorf("%s: expected a SchemaValidationError, got %v", testCase.description, err)
+			continue
+		}
+
+		if schemaValidationError.KeywordLocation() != testCase.keywordLocation {
+			t.Errorf("%s: expected keyword location %q, got %q",
+				testCase.description, testCase.keywordLocation, schemaValidationError.KeywordLocation())
+		}
+		if schemaValidationError.AbsoluteKeywordLocation() != testCase.absoluteKeywordLocation {
+			t.Errorf("%s: expected absolute keyword location %q, got %q",
+				testCase.description, testCase.absoluteKeywordLocation, schemaValidationError.AbsoluteKeywordLocation())
+		}
+	}
+}
+
+func TestInternPropertyNames(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"properties": {
+			"name": {"type": "string"},
+			"child": {"properties": {"name": {"type": "string"}}, "required": ["name", "name"]}
+		},
+		"required": ["name"]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	child := rootSchema.Properties["child"]
+	if len(child.Required) != 1 {
+		t.Fatalf("expected duplicate required names to be dropped, got %v", child.Required)
+	}
+
+	names := []string{rootSchema.Required[0], child.Required[0]}
+	for key := range child.Properties {
+		names = append(names, key)
+	}
+
+	for _, name := range names[1:] {
+		if stringData(name) != stringData(names[0]) {
+			t.Errorf("expected all the occurrences of %q to share the same string", name)
+		}
+	}
+}
+
+func stringData(s string) uintptr {
+	return (*reflect.StringHeader)(unsafe.Pointer(&s)).Data
+}
+
+func TestDependencies(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"dependencies": {
+			"a": ["b", "c", "b"],
+			"d": {"required": ["e"]},
+			"f": true,
+			"g": false
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if names := rootSchema.Dependencies.properties["a"]; len(names) != 2 {
+		t.Errorf("expected duplicate names to be dropped, got %v", names)
+	}
+
+	testCases := []struct {
+		description string
+		data        string
+		valid       bool
+	}{
+		{"no dependency applies", `{"b": 1, "e": 1}`, true},
+		{"property dependency satisfied", `{"a": 1, "b": 1, "c": 1}`, true},
+		{"property dependency not satisfied", `{"a": 1, "b": 1}`, false},
+		{"schema dependency satisfied", `{"d": 1, "e": 1}`, true},
+		{"schema dependency not satisfied", `{"d": 1}`, false},
+		{"true schema dependency", `{"f": 1}`, true},
+		{"false schema dependency", `{"g": 1}`, false},
+		{"not an object", `"a"`, true},
+	}
+
+	validator := NewValidator(rootSchema)
+	for _, testCase := range testCases {
+		err := validator.Validate([]byte(testCase.data))
+		if (err == nil) != testCase.valid {
+			t.Errorf("%s: expected valid=%t, got %v", testCase.description, testCase.valid, err)
+		}
+	}
+}
+
+func TestDependenciesCompilationError(t *testing.T) {
+	for _, schema := range []string{
+		`{"dependencies": {"a": ["b", 1]}}`,
+		`{"dependencies": {"a": 1}}`,
+	} {
+		if _, err := NewRootJsonSchema([]byte(schema)); err == nil {
+			t.Errorf("expected %s to fail compilation", schema)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// Package kafkavalidator validates the keys and the values of Kafka records
+// against json schemas, for consumers that route invalid records to a
+// dead-letter topic. It does not depend on a Kafka client: the records of
+// any client are converted to Records, which hold the fields that the
+// validation needs.
+//
+// The schemas of the keys and the values are selected by the topic of the
+// record. Payloads in the wire format of the Confluent schema registry, a
+// zero magic byte followed by a 4-byte big-endian schema ID and the json
+// document, are validated against the schema that is registered under
+// their ID instead, which allows topics with several schemas. Empty
+// payloads, like the values of tombstones, are not validated.
+package kafkavalidator
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/itayankri/gojsonvalidator"
+)
+
+// The magic byte that starts the payloads of the wire format of the
+// Confluent schema registry.
+const magicByte = 0
+
+// ErrMalformedPayload is returned when a payload starts with the magic byte
+// but is too short to hold a schema ID.
+var ErrMalformedPayload = errors.New("kafkavalidator: the payload is too short for a schema ID")
+
+// UnknownSchemaIDError is returned when the schema ID of a payload is not
+// registered.
+type UnknownSchemaIDError uint32
+
+func (e UnknownSchemaIDError) Error() string {
+	return fmt.Sprintf("kafkavalidator: schema ID %d is not registered", uint32(e))
+}
+
+// Record is a Kafka record. Partition and Offset are not used in the
+// validation, and are kept so results identify their records.
+type Record struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       []byte
+	Value     []byte
+}
+
+// Result is the result of the validation of a record. KeyErr and ValueErr
+// are the errors of the key and the value, or nil if they are valid or have
+// no schema.
+type Result struct {
+	Record   Record
+	KeyErr   error
+	ValueErr error
+}
+
+// Valid returns true if both the key and the value of the record are valid.
+func (r Result) Valid() bool {
+	return r.KeyErr == nil && r.ValueErr == nil
+}
+
+// Err returns the error of the record, which is the error of its value, or
+// the error of its key if the value is valid, or nil if the record is valid.
+// It is a convenient reason for the header of a dead-letter record.
+func (r Result) Err() error {
+	if r.ValueErr != nil {
+		return fmt.Errorf("invalid value: %w", r.ValueErr)
+	}
+	if r.KeyErr != nil {
+		return fmt.Errorf("invalid key: %w", r.KeyErr)
+	}
+
+	return nil
+}
+
+// topicSchemas holds the validators of the keys and the values of a topic,
+// which are nil if the keys or the values have no schema.
+type topicSchemas struct {
+	key   *jsonvalidator.Validator
+	value *jsonvalidator.Validator
+}
+
+// Validator holds the schemas of topics and the schemas of schema IDs. A
+// Validator is safe for concurrent use.
+type Validator struct {
+	mutex     sync.RWMutex
+	topics    map[string]topicSchemas
+	schemaIDs map[uint32]*jsonvalidator.Validator
+}
+
+// New creates a Validator without schemas.
+func New() *Validator {
+	return &Validator{
+		topics:    map[string]topicSchemas{},
+		schemaIDs: map[uint32]*jsonvalidator.Validator{},
+	}
+}
+
+// RegisterTopic sets the schemas of the keys and the values of a topic. A
+// nil schema disables the validation of the keys or the values of the
+// topic, unless they hold a schema ID.
+func (v *Validator) RegisterTopic(topic string, key *jsonvalidator.RootJsonSchema, value *jsonvalidator.RootJsonSchema) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	v.topics[topic] = topicSchemas{newValidator(key), newValidator(value)}
+}
+
+// RegisterSchemaID sets the schema of the payloads that hold a schema ID.
+func (v *Validator) RegisterSchemaID(id uint32, schema *jsonvalidator.RootJsonSchema) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	v.schemaIDs[id] = jsonvalidator.NewValidator(schema)
+}
+
+func newValidator(schema *jsonvalidator.RootJsonSchema) *jsonvalidator.Validator {
+	if schema == nil {
+		return nil
+	}
+
+	return jsonvalidator.NewValidator(schema)
+}
+
+// ValidateRecord validates the key and the value of a record.
+func (v *Validator) ValidateRecord(record Record) Result {
+	v.mutex.RLock()
+	schemas := v.topics[record.Topic]
+	v.mutex.RUnlock()
+
+	return Result{
+		Record:   record,
+		KeyErr:   v.validatePayload(record.Key, schemas.key),
+		ValueErr: v.validatePayload(record.Value, schemas.value),
+	}
+}
+
+// ValidateRecords validates a batch of records, and returns their results
+// in the order of the records.
+func (v *Validator) ValidateRecords(records []Record) []Result {
+	results := make([]Result, len(records))
+	for index, record := range records {
+		results[index] = v.ValidateRecord(record)
+	}
+
+	return results
+}
+
+// Split splits the results of a batch into the records that are valid and
+// the results of the records that are invalid, which are usually sent to a
+// dead-letter topic.
+func Split(results []Result) (valid []Record, invalid []Result) {
+	for _, result := range results {
+		if result.Valid() {
+			valid = append(valid, result.Record)
+		} else {
+			invalid = append(invalid, result)
+		}
+	}
+
+	return valid, invalid
+}
+
+// validatePayload validates a key or a value against the schema of its
+// schema ID if it has one, and against the schema of its topic otherwise.
+func (v *Validator) validatePayload(payload []byte, topicValidator *jsonvalidator.Validator) error {
+	if len(payload) == 0 {
+		return nil
+	}
+
+	validator := topicValidator
+	if payload[0] == magicByte {
+		if len(payload) < 5 {
+			return ErrMalformedPayload
+		}
+
+		id := binary.BigEndian.Uint32(payload[1:5])
+		payload = payload[5:]
+
+		var ok bool
+		v.mutex.RLock()
+		validator, ok = v.schemaIDs[id]
+		v.mutex.RUnlock()
+		if !ok {
+			return UnknownSchemaIDError(id)
+		}
+	}
+
+	if validator == nil {
+		return nil
+	}
+
+	return validator.Validate(payload)
+}
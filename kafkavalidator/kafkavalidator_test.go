@@ -0,0 +1,141 @@
+package kafkavalidator_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/kafkavalidator"
+)
+
+func newSchema(t *testing.T, schema string) *jsonvalidator.RootJsonSchema {
+	rootSchema, err := jsonvalidator.NewRootJsonSchema([]byte(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return rootSchema
+}
+
+// framed returns a payload in the wire format of the schema registry.
+func framed(id uint32, document string) []byte {
+	payload := make([]byte, 5, 5+len(document))
+	binary.BigEndian.PutUint32(payload[1:], id)
+	return append(payload, document...)
+}
+
+func newValidator(t *testing.T) *kafkavalidator.Validator {
+	validator := kafkavalidator.New()
+	validator.RegisterTopic(
+		"orders",
+		newSchema(t, `{"type": "string", "pattern": "^o-"}`),
+		newSchema(t, `{"type": "object", "required": ["orderId"]}`),
+	)
+	validator.RegisterTopic("events", nil, newSchema(t, `{"type": "object"}`))
+	validator.RegisterSchemaID(7, newSchema(t, `{"type": "object", "required": ["eventId"]}`))
+	return validator
+}
+
+func TestValidateRecord(t *testing.T) {
+	validator := newValidator(t)
+
+	testCases := []struct {
+		description string
+		record      kafkavalidator.Record
+		keyValid    bool
+		valueValid  bool
+	}{
+		{
+			description: "a valid record",
+			record:      kafkavalidator.Record{Topic: "orders", Key: []byte(`"o-1"`), Value: []byte(`{"orderId": "o-1"}`)},
+			keyValid:    true,
+			valueValid:  true,
+		},
+		{
+			description: "a record with an invalid key",
+			record:      kafkavalidator.Record{Topic: "orders", Key: []byte(`"x-1"`), Value: []byte(`{"orderId": "x-1"}`)},
+			keyValid:    false,
+			valueValid:  true,
+		},
+		{
+			description: "a record with an invalid value",
+			record:      kafkavalidator.Record{Topic: "orders", Key: []byte(`"o-1"`), Value: []byte(`{}`)},
+			keyValid:    true,
+			valueValid:  false,
+		},
+		{
+			description: "a tombstone",
+			record:      kafkavalidator.Record{Topic: "orders", Key: []byte(`"o-1"`)},
+			keyValid:    true,
+			valueValid:  true,
+		},
+		{
+			description: "a record of a topic without a key schema",
+			record:      kafkavalidator.Record{Topic: "events", Key: []byte(`anything`), Value: []byte(`{}`)},
+			keyValid:    true,
+			valueValid:  true,
+		},
+		{
+			description: "a record of a topic without schemas",
+			record:      kafkavalidator.Record{Topic: "logs", Value: []byte(`[1, 2]`)},
+			keyValid:    true,
+			valueValid:  true,
+		},
+		{
+			description: "a valid value with a schema ID",
+			record:      kafkavalidator.Record{Topic: "events", Value: framed(7, `{"eventId": 1}`)},
+			keyValid:    true,
+			valueValid:  true,
+		},
+		{
+			description: "an invalid value with a schema ID",
+			record:      kafkavalidator.Record{Topic: "events", Value: framed(7, `{}`)},
+			keyValid:    true,
+			valueValid:  false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		result := validator.ValidateRecord(testCase.record)
+		if (result.KeyErr == nil) != testCase.keyValid || (result.ValueErr == nil) != testCase.valueValid {
+			t.Errorf("%s: expected key valid=%t and value valid=%t, got %v and %v",
+				testCase.description, testCase.keyValid, testCase.valueValid, result.KeyErr, result.ValueErr)
+		}
+		if result.Valid() != (result.Err() == nil) {
+			t.Errorf("%s: expected Err() to be nil only for valid records, got %v", testCase.description, result.Err())
+		}
+	}
+}
+
+func TestSchemaIDErrors(t *testing.T) {
+	validator := newValidator(t)
+
+	result := validator.ValidateRecord(kafkavalidator.Record{Topic: "events", Value: framed(8, `{}`)})
+	if !errors.Is(result.Err(), kafkavalidator.UnknownSchemaIDError(8)) {
+		t.Errorf("expected an UnknownSchemaIDError, got %v", result.Err())
+	}
+
+	result = validator.ValidateRecord(kafkavalidator.Record{Topic: "events", Value: []byte{0, 0, 7}})
+	if !errors.Is(result.Err(), kafkavalidator.ErrMalformedPayload) {
+		t.Errorf("expected ErrMalformedPayload, got %v", result.Err())
+	}
+}
+
+func TestSplit(t *testing.T) {
+	validator := newValidator(t)
+
+	records := []kafkavalidator.Record{
+		{Topic: "orders", Offset: 1, Value: []byte(`{"orderId": "o-1"}`)},
+		{Topic: "orders", Offset: 2, Value: []byte(`{}`)},
+		{Topic: "orders", Offset: 3, Value: []byte(`{"orderId": "o-3"}`)},
+	}
+
+	valid, invalid := kafkavalidator.Split(validator.ValidateRecords(records))
+	if len(valid) != 2 || valid[0].Offset != 1 || valid[1].Offset != 3 {
+		t.Errorf("expected the records at offsets 1 and 3 to be valid, got %v", valid)
+	}
+	if len(invalid) != 1 || invalid[0].Record.Offset != 2 || invalid[0].ValueErr == nil {
+		t.Errorf("expected the record at offset 2 to be invalid, got %v", invalid)
+	}
+}
@@ -1,21 +1,25 @@
 package jsonvalidator
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 
 	"math"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/itayankri/gojsonvalidator/formatchecker"
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
 )
 
 /*
 Implemented keywordValidators:
 > enum: 					V
 > _const: 					V
-> _type: 					V ***
+> _type: 					V
 > minLength: 				V
 > maxLength: 				V
 > pattern: 					V
@@ -33,7 +37,7 @@ Implemented keywordValidators:
 > patternProperties: 		V
 > minProperties: 			V
 > maxProperties: 			V
-> items: 					V ***
+> items: 					V
 > contains: 				V
 > additionalItems: 			V
 > minItems: 				V
@@ -47,34 +51,113 @@ Implemented keywordValidators:
 > _then: 					V
 > _else: 					V
 
-*** These keywords are being un-marshaled in their validate() function.
-	We need to find a way to do that on startup and not on runtime.
-
 */
 
 // Valid values for "format" fields
 const (
-	FORMAT_DATE_TIME             = "date-time"
-	FORMAT_TIME                  = "time"
-	FORMAT_DATE                  = "date"
-	FORMAT_EMAIL                 = "email"
-	FORMAT_IDN_EMAIL             = "idn-email"
-	FORMAT_HOSTNAME              = "hostname"
-	FORMAT_IDN_HOSTNAME          = "idn-hostname"
-	FORMAT_IPV4                  = "ipv4"
-	FORMAT_IPV6                  = "ipv6"
-	FORMAT_URI                   = "uri"
-	FORMAT_URI_REFERENCE         = "uri-reference"
-	FORMAT_IRI                   = "iri"
-	FORMAT_IRI_REFERENCE         = "iri-reference"
-	FORMAT_URI_TEMPLATE          = "uri-template"
-	FORMAT_JSON_POINTER          = "json-pointer"
-	FORMAT_RELATIVE_JSON_POINTER = "relative-json-pointer"
-	FORMAT_REGEX                 = "regex"
+	FORMAT_DATE_TIME                 = "date-time"
+	FORMAT_TIME                      = "time"
+	FORMAT_DATE                      = "date"
+	FORMAT_EMAIL                     = "email"
+	FORMAT_IDN_EMAIL                 = "idn-email"
+	FORMAT_HOSTNAME                  = "hostname"
+	FORMAT_IDN_HOSTNAME              = "idn-hostname"
+	FORMAT_IPV4                      = "ipv4"
+	FORMAT_IPV6                      = "ipv6"
+	FORMAT_URI                       = "uri"
+	FORMAT_URI_REFERENCE             = "uri-reference"
+	FORMAT_IRI                       = "iri"
+	FORMAT_IRI_REFERENCE             = "iri-reference"
+	FORMAT_URI_TEMPLATE              = "uri-template"
+	FORMAT_JSON_POINTER              = "json-pointer"
+	FORMAT_RELATIVE_JSON_POINTER     = "relative-json-pointer"
+	FORMAT_JSON_POINTER_URI_FRAGMENT = "json-pointer-uri-fragment"
+	FORMAT_REGEX                     = "regex"
+	FORMAT_SEMVER                    = "semver"
+	FORMAT_PHONE                     = "phone"
+	FORMAT_E164                      = "e164"
+	FORMAT_BASE64                    = "base64"
+	FORMAT_BASE64_URL                = "base64url"
+	FORMAT_UUID                      = "uuid"
+	FORMAT_DURATION                  = "duration"
 )
 
 type keywordValidator interface {
-	validate(string, jsonData, string) error
+	validate(string, jsonData, string, *validationContext) error
+}
+
+// validateChild validates a property or an item (identified by token) of the
+// json value at jsonPath against the receiver schema.
+func (js *JsonSchema) validateChild(jsonPath string, token string, value interface{}, rootSchemaId string, ctx *validationContext) error {
+	// Primitive values are validated without building a json data container
+	// when the schema allows it, and when the validation does not need the
+	// details that the fast path skips (see usesFastPath()). The path of the
+	// value is built only if the validation fails.
+	if isPrimitive(value) && ctx.usesFastPath() && js.hasPrimitiveFastPath() {
+		err := js.validatePrimitive(value, ctx)
+		if err != nil {
+			return ctx.schemaValidationError(jsonPath+"/"+jsonwalker.EscapeToken(token), err)
+		}
+
+		return nil
+	}
+
+	return js.validateJsonData(jsonPath+"/"+jsonwalker.EscapeToken(token), newJsonData(value), rootSchemaId, ctx)
+}
+
+// validateItem validates the item at the given index of the json array at
+// jsonPath against the receiver schema.
+func (js *JsonSchema) validateItem(jsonPath string, index int, value interface{}, rootSchemaId string, ctx *validationContext) error {
+	// Like in validateChild(), the token of the item is formatted only if
+	// the validation of a primitive item fails.
+	if isPrimitive(value) && ctx.usesFastPath() && js.hasPrimitiveFastPath() {
+		err := js.validatePrimitive(value, ctx)
+		if err != nil {
+			return ctx.schemaValidationError(jsonPath+"/"+strconv.Itoa(index), err)
+		}
+
+		return nil
+	}
+
+	return js.validateChild(jsonPath, strconv.Itoa(index), value, rootSchemaId, ctx)
+}
+
+// subSchemaError creates the error of a keyword whose sub-schema failed in
+// validation. If the sub-schema reported a SchemaValidationError, its
+// instance path, keyword and keyword locations are kept, so the error points
+// to the value that actually failed rather than to the value that holds the
+// keyword.
+func subSchemaError(keyword string, reason string, err error) error {
+	keywordValidationError := KeywordValidationError{
+		keyword: keyword,
+		reason:  reason + err.Error(),
+		cause:   err,
+	}
+
+	if schemaValidationError, ok := err.(SchemaValidationError); ok {
+		return SchemaValidationError{
+			path:                    schemaValidationError.path,
+			keyword:                 schemaValidationError.keyword,
+			keywordLocation:         schemaValidationError.keywordLocation,
+			absoluteKeywordLocation: schemaValidationError.absoluteKeywordLocation,
+			err:                     keywordValidationError.Error(),
+			params:                  schemaValidationError.params,
+			bestMatch:               schemaValidationError.bestMatch,
+			cause:                   keywordValidationError,
+			scopes:                  schemaValidationError.scopes,
+		}
+	}
+
+	return keywordValidationError
+}
+
+// wrapErrors applies subSchemaError() to the failures that were recorded
+// since mark, so the failures of a keyword's sub-schema that are collected
+// for a Result describe the keyword like the failure that it returns.
+func (ctx *validationContext) wrapErrors(mark outputMark, keyword string, reason string) {
+	for index := mark.errors; index < len(ctx.errors); index++ {
+		ctx.errors[index] = subSchemaError(keyword, reason, ctx.errors[index]).(SchemaValidationError)
+	}
 }
 
 /*****************/
@@ -83,50 +166,202 @@ type keywordValidator interface {
 
 type ref string
 
-func (r ref) validateByRef(jsonPath string, jsonData []byte, rootSchemaID string) error {
-	splittedRef := strings.Split(string(r), "#")
-	schemaURI := splittedRef[0]
-	fragment := splittedRef[1]
-
-	// If the schemaURI is empty string it means that the reference points to a schema
-	// in the local schema (for example #/definitions/x), so we want to use the rootSchemaID
-	// in order to get the current root-schema from the rootSchemaPool.
-	if schemaURI == "" {
-		schemaURI = rootSchemaID
-	}
-
-	// If the root-schema exists in the rootSchemaPool, validate the data according to the
-	// fragment.
-	// Else, return an error
-	if rootSchema, ok := rootSchemaPool[schemaURI]; ok {
-		// If the fragment is an empty fragment, validate the data against the root-schema.
-		// Else, validate the data against the sub-schema that the fragment points to.
-		if fragment != "" {
-			// If the referenced sub-schema exists, validate the data against it.
-			// Else, return an error
-			if subSchema, ok := rootSchema.subSchemaMap[fragment]; ok {
-				return subSchema.validateJsonData(jsonPath, jsonData, rootSchemaID)
-			} else {
-				return InvalidReferenceError{
-					schemaURI: schemaURI,
-					fragment:  fragment,
-					err:       "could not find fragment in the referenced root schema",
+func (r ref) validateByRef(jsonPath string, jsonData jsonData, rootSchemaID string, ctx *validationContext) error {
+	return r.validateReference("$ref", jsonPath, jsonData, rootSchemaID, ctx)
+}
+
+// validate validates the value against the referenced schema when "$ref" is
+// validated like the other keywords of its schema, as it is since draft
+// 2020-12.
+func (r *ref) validate(jsonPath string, jsonData jsonData, rootSchemaID string, ctx *validationContext) error {
+	return r.validateByRef(jsonPath, jsonData, rootSchemaID, ctx)
+}
+
+// validateReference validates the value against the schema that the
+// reference points to, where keyword is the keyword that holds the
+// reference.
+func (r ref) validateReference(keyword string, jsonPath string, jsonData jsonData, rootSchemaID string, ctx *validationContext) error {
+	schema, err := r.resolveIn(rootSchemaID, ctx.validator.registries)
+	if err != nil {
+		return err
+	}
+
+	// The keyword location goes through the keyword of the reference, while
+	// the absolute location continues from the referenced schema.
+	absoluteURI := r.absoluteURIIn(rootSchemaID, ctx.validator.registries)
+	defer ctx.leaveReference(ctx.enterReference(keyword, absoluteURI))
+
+	// The references of the referenced schema are resolved against the root
+	// schema that holds it.
+	referencedRootSchemaID := absoluteURI[:strings.Index(absoluteURI, "#")]
+
+	return schema.validateJsonData(jsonPath, jsonData, referencedRootSchemaID, ctx)
+}
+
+// absoluteURIIn returns the absolute URI of the schema that the reference
+// points to, where the root schemas are looked up in the given registries.
+func (r ref) absoluteURIIn(rootSchemaID string, registries registries) string {
+	splittedRef := strings.SplitN(string(r), "#", 2)
+	schemaURI := r.schemaURIIn(splittedRef[0], rootSchemaID, registries)
+
+	if len(splittedRef) > 1 {
+		// An anchor is replaced by the location of the schema that has it.
+		fragment := splittedRef[1]
+		if isAnchorFragment(fragment) {
+			if rootSchema, ok := registries.lookup(schemaURI); ok {
+				if anchor, ok := rootSchema.anchors[fragment]; ok {
+					fragment = anchor.pointer
 				}
 			}
-		} else {
-			return rootSchema.validateJsonData(jsonPath, jsonData, rootSchemaID)
 		}
-	} else {
-		return InvalidReferenceError{
+
+		return schemaURI + "#" + fragment
+	}
+
+	return schemaURI + "#"
+}
+
+// isAnchorFragment returns true if the fragment of a reference is the name
+// of an anchor, rather than a json pointer.
+func isAnchorFragment(fragment string) bool {
+	return fragment != "" && fragment[0] != '/'
+}
+
+// schemaURIIn returns the URI of the root schema that the reference points
+// to, given the URI part of the reference, where the root schemas are looked
+// up in the given registries. Relative URIs are resolved against the URI of
+// the root schema that holds the reference. Schemas that were registered
+// under a relative "$id" are still found by it.
+func (r ref) schemaURIIn(uri string, rootSchemaID string, registries registries) string {
+	resolved := resolveURI(rootSchemaID, uri)
+	if _, ok := registries.lookup(resolved); !ok && uri != "" {
+		if _, ok := registries.lookup(uri); ok {
+			return uri
+		}
+	}
+
+	return resolved
+}
+
+// resolveIn returns the schema that the reference points to, where the
+// root schemas are looked up in the given registries before the
+// DefaultRegistry.
+func (r ref) resolveIn(rootSchemaID string, registries registries) (*JsonSchema, error) {
+	splittedRef := strings.SplitN(string(r), "#", 2)
+	schemaURI := splittedRef[0]
+
+	// The fragment is a json pointer in its URI fragment representation,
+	// so it is parsed (and percent-decoded) into a JsonPointer and converted
+	// back to the plain representation that subSchemaMap is keyed by. A
+	// fragment that is the name of an anchor is looked up once the root
+	// schema is found.
+	var fragment, anchorName string
+	if len(splittedRef) > 1 && isAnchorFragment(splittedRef[1]) {
+		anchorName = splittedRef[1]
+	} else if len(splittedRef) > 1 {
+		pointer, err := jsonwalker.NewJsonPointer("#" + splittedRef[1])
+		if err != nil {
+			return nil, InvalidReferenceError{
+				schemaURI: schemaURI,
+				fragment:  splittedRef[1],
+				err:       err.Error(),
+				cause:     err,
+			}
+		}
+
+		fragment = pointer.String()
+	}
+
+	// Relative references (and the empty reference of the local schema, for
+	// example #/definitions/x) are resolved against the rootSchemaID in order
+	// to get the referenced root-schema from the registries.
+	schemaURI = r.schemaURIIn(schemaURI, rootSchemaID, registries)
+
+	// If the root-schema does not exist in the registries or in the
+	// DefaultRegistry, return an error.
+	rootSchema, ok := registries.lookup(schemaURI)
+	if !ok {
+		return nil, InvalidReferenceError{
 			schemaURI: schemaURI,
 			fragment:  fragment,
 			err:       "could not find the referenced root schema",
 		}
 	}
+
+	if anchorName != "" {
+		anchor, ok := rootSchema.anchors[anchorName]
+		if !ok {
+			return nil, InvalidReferenceError{
+				schemaURI:   schemaURI,
+				fragment:    anchorName,
+				err:         "could not find anchor in the referenced root schema",
+				suggestions: suggest(anchorName, rootSchema.SubSchemaPointers()),
+			}
+		}
+
+		fragment = anchor.pointer
+	}
+
+	// If the fragment is an empty fragment, the reference points to the root-schema.
+	if fragment == "" {
+		return &rootSchema.JsonSchema, nil
+	}
+
+	// If the referenced sub-schema was not mapped when the root schema was
+	// scanned, look for it in the schema document. If it does not exist
+	// there either, return an error.
+	subSchema, ok := rootSchema.subSchemaMap[fragment]
+	if !ok {
+		var err error
+		subSchema, err = rootSchema.evaluateFragment(fragment)
+		if err != nil {
+			return nil, InvalidReferenceError{
+				schemaURI:   schemaURI,
+				fragment:    fragment,
+				err:         "could not find fragment in the referenced root schema",
+				cause:       err,
+				suggestions: suggest(fragment, rootSchema.SubSchemaPointers()),
+			}
+		}
+	}
+
+	return subSchema, nil
+}
+
+// dynamicRef is the "$dynamicRef" keyword of draft 2020-12.
+type dynamicRef string
+
+func (d *dynamicRef) validate(jsonPath string, jsonData jsonData, rootSchemaID string, ctx *validationContext) error {
+	reference := ref(*d)
+	registries := ctx.validator.registries
+
+	// A reference to a "$dynamicAnchor" is resolved to the outermost schema
+	// resource of the dynamic scope (the root schema, followed by the
+	// schemas that the references of the validation path point to) that
+	// has a "$dynamicAnchor" with the same name. Other references are
+	// resolved like "$ref".
+	splittedRef := strings.SplitN(string(*d), "#", 2)
+	if len(splittedRef) > 1 && isAnchorFragment(splittedRef[1]) {
+		name := splittedRef[1]
+		schemaURI := reference.schemaURIIn(splittedRef[0], rootSchemaID, registries)
+		if rootSchema, ok := registries.lookup(schemaURI); ok && rootSchema.anchors[name].dynamic {
+			for _, base := range ctx.schemaBases {
+				resource := base.uri[:strings.Index(base.uri, "#")]
+				if scope, ok := registries.lookup(resource); ok && scope.anchors[name].dynamic {
+					reference, rootSchemaID = ref("#"+name), resource
+					break
+				}
+			}
+		}
+	}
+
+	return reference.validateReference("$dynamicRef", jsonPath, jsonData, rootSchemaID, ctx)
 }
 
 type schema string
 type id string
+type anchor string
+type vocabulary map[string]bool
 type comment string
 type title string
 type description string
@@ -139,69 +374,65 @@ func (d *_default) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+func (d _default) MarshalJSON() ([]byte, error) {
+	return []byte(d), nil
+}
+
 /**********************/
 /** Generic Keywords **/
 /**********************/
 
-type _type json.RawMessage
-
-func (t *_type) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
-	var data interface{}
-
-	// First we need to unmarshal the json data.
-	err := json.Unmarshal(*t, &data)
-	if err != nil {
-		return err
-	}
+// _type holds the json types that the "type" keyword allows, which are
+// parsed once when the schema is unmarshaled, so values are validated
+// without unmarshaling the keyword again.
+type _type struct {
+	// The keyword as it appears in the schema.
+	raw json.RawMessage
+
+	// The type names of the keyword, and whether the keyword is an array
+	// of type names rather than a single type name. names is nil if the
+	// keyword is neither a string nor an array of strings, which
+	// compile() reports.
+	names []string
+	array bool
+}
 
+func (t *_type) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
 	// The "type" field in json schema can be represented by two different values:
 	// - string - the inspected value can be only one json type.
 	// - array - the inspected value can be a variety of json types.
-	// - default - the schema is incorrect.
-	switch typeFromSchema := data.(type) {
-	case []interface{}:
-		{
-			// If we arrived this loop, it means "type" is an array of types.
-			// We need to go over the existing types and perform
-			// "json type assertion" of jsonData and the current json type.
-			for _, typeFromList := range typeFromSchema {
-				// A json type must be represented by a string.
-				if v, ok := typeFromList.(string); ok {
-					// Perform the "json type assertion"
-					err := assertJsonType(v, jsonData.value)
-
-					// If the assertion succeeded, return true
-					if err == nil {
-						return nil
-					}
-				} else {
-					return KeywordValidationError{
-						"type",
-						"\"type\" field in schema must be string or array of strings",
-					}
-				}
-			}
+	if !t.array {
+		// In this case, there is only one valid type, so we
+		// perform "json type assertion" of the json type and jsonData.
+		return assertJsonType(t.names[0], jsonData.value)
+	}
 
-			// JsonTypeMismatchError
-			return KeywordValidationError{
-				"type",
-				"inspected value does not match any of the valid types in the schema",
-			}
-		}
-	case string:
-		{
-			// In this case, there is only one valid type, so we
-			// perform "json type assertion" of the json type and jsonData.
-			return assertJsonType(typeFromSchema, jsonData.value)
+	// "type" is an array of types, so we perform "json type assertion" of
+	// jsonData and every type, until one of them succeeds.
+	for _, name := range t.names {
+		if assertJsonType(name, jsonData.value) == nil {
+			return nil
 		}
-	default:
-		{
-			return KeywordValidationError{
-				"type",
-				"\"type\" field in schema must be string or array of strings",
-			}
+	}
+
+	// JsonTypeMismatchError
+	return KeywordValidationError{
+		keyword: "type",
+		reason:  "inspected value does not match any of the valid types in the schema",
+	}
+}
+
+// compile returns a SchemaCompilationError if the keyword is not a string
+// or an array of strings.
+func (t *_type) compile(schemaPath string) error {
+	if t.names == nil {
+		return SchemaCompilationError{
+			path: schemaPath + "/type",
+			err:  "\"type\" field in schema must be string or array of strings",
 		}
 	}
+
+	return nil
 }
 
 // assertJsonType is a function that gets a jsonType and some jsonData and
@@ -215,8 +446,8 @@ func assertJsonType(jsonType string, jsonData interface{}) error {
 				return nil
 			} else {
 				return KeywordValidationError{
-					"type",
-					"inspected value expected to be a json object",
+					keyword: "type",
+					reason:  "inspected value expected to be a json object",
 				}
 			}
 		}
@@ -226,8 +457,8 @@ func assertJsonType(jsonType string, jsonData interface{}) error {
 				return nil
 			} else {
 				return KeywordValidationError{
-					"type",
-					"inspected value expected to be a json array",
+					keyword: "type",
+					reason:  "inspected value expected to be a json array",
 				}
 			}
 		}
@@ -237,30 +468,30 @@ func assertJsonType(jsonType string, jsonData interface{}) error {
 				return nil
 			} else {
 				return KeywordValidationError{
-					"type",
-					"inspected value expected to be a json string",
+					keyword: "type",
+					reason:  "inspected value expected to be a json string",
 				}
 			}
 		}
 	case TYPE_INTEGER:
 		{
-			if value, ok := jsonData.(float64); ok && value == float64(int(value)) {
+			if isInteger(jsonData) {
 				return nil
 			} else {
 				return KeywordValidationError{
-					"type",
-					"inspected value expected to be a json integer",
+					keyword: "type",
+					reason:  "inspected value expected to be a json integer",
 				}
 			}
 		}
 	case TYPE_NUMBER:
 		{
-			if _, ok := jsonData.(float64); ok {
+			if _, ok := numberValue(jsonData); ok {
 				return nil
 			} else {
 				return KeywordValidationError{
-					"type",
-					"inspected value expected to be a json number",
+					keyword: "type",
+					reason:  "inspected value expected to be a json number",
 				}
 			}
 		}
@@ -270,8 +501,8 @@ func assertJsonType(jsonType string, jsonData interface{}) error {
 				return nil
 			} else {
 				return KeywordValidationError{
-					"type",
-					"inspected value expected to be a json boolean",
+					keyword: "type",
+					reason:  "inspected value expected to be a json boolean",
 				}
 			}
 		}
@@ -281,68 +512,153 @@ func assertJsonType(jsonType string, jsonData interface{}) error {
 				return nil
 			} else {
 				return KeywordValidationError{
-					"type",
-					"inspected value expected to be a json null",
+					keyword: "type",
+					reason:  "inspected value expected to be a json null",
 				}
 			}
 		}
 	default:
 		{
 			return KeywordValidationError{
-				"type",
-				"invalid json type " + jsonType,
+				keyword: "type",
+				reason:  "invalid json type " + jsonType,
 			}
 		}
 	}
 }
 
 func (t *_type) UnmarshalJSON(data []byte) error {
-	*t = data
+	t.raw = append(json.RawMessage(nil), data...)
+
+	var name string
+	if json.Unmarshal(data, &name) == nil {
+		t.names = []string{name}
+		return nil
+	}
+
+	var names []string
+	if json.Unmarshal(data, &names) == nil && names != nil {
+		t.names = names
+		t.array = true
+	}
+
 	return nil
 }
 
 func (t *_type) MarshalJSON() ([]byte, error) {
-	return []byte(*t), nil
+	return []byte(t.raw), nil
 }
 
 type enum []interface{}
 
-func (e enum) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
+func (e enum) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	if ctx.validator.comparesDecoded() {
+		for _, item := range e {
+			if ctx.validator.valuesEqual(item, jsonData.value) {
+				return nil
+			}
+		}
+
+		return e.validationError(jsonData)
+	}
+
+	// The value is marshaled once, and compared with the marshaled items,
+	// so the comparison does not require type assertions.
+	valueEncoder := getEncoder()
+	defer valueEncoder.release()
+
+	rawValue, err := valueEncoder.encode(jsonData.value)
+	if err != nil {
+		return err
+	}
+
+	encoder := getEncoder()
+	defer encoder.release()
+
 	// Iterate over the items in "enum" array.
 	for _, item := range e {
 		// Marshal the item from "enum" array back comparable value that does
 		// not require type assertion.
-		rawEnumItem, err := json.Marshal(item)
+		rawEnumItem, err := encoder.encode(item)
 		if err != nil {
 			return nil
 		}
 
-		// Convert both of the byte arrays to string for more convenient
-		// comparison. If they are equal, the data is valid against "enum".
-		if string(rawEnumItem) == string(jsonData.raw) {
+		// If the byte arrays are equal, the data is valid against "enum".
+		if bytes.Equal(rawEnumItem, rawValue) {
 			return nil
 		}
 	}
 
 	// If we arrived here it means that the inspected value is not equal
 	// to any of the values in "enum".
-	return KeywordValidationError{
-		"enum",
-		"inspected value does not match any of the items in \"enum\" array",
+	return e.validationError(jsonData)
+}
+
+// validationError returns the error of a value that does not match any of
+// the items. A string value is likely a typo of the string items that are
+// close to it, so they are suggested in the reason and in the params.
+func (e enum) validationError(jsonData jsonData) error {
+	err := KeywordValidationError{
+		keyword: "enum",
+		reason:  "inspected value does not match any of the items in \"enum\" array",
+	}
+
+	value, ok := jsonData.value.(string)
+	if !ok {
+		return err
+	}
+
+	var items []string
+	for _, item := range e {
+		if item, ok := item.(string); ok {
+			items = append(items, item)
+		}
+	}
+
+	suggestions := suggest(value, items)
+	if len(suggestions) == 0 {
+		return err
+	}
+
+	quoted := make([]string, len(suggestions))
+	for index, suggestion := range suggestions {
+		quoted[index] = strconv.Quote(suggestion)
 	}
+
+	err.reason += ", did you mean " + strings.Join(quoted, " or ") + "?"
+	err.params = map[string]interface{}{"suggestions": suggestions}
+	return err
 }
 
 type _const json.RawMessage
 
-func (c *_const) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
-	// Convert both of the byte arrays to string for more convenient
-	// comparison. If they are equal, the data is valid against "const".
-	if string(*c) == string(jsonData.raw) {
+func (c *_const) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	// With a custom comparator or a numeric tolerance the values are
+	// compared decoded. Otherwise the value is marshaled and both of the
+	// byte arrays are converted to string for more convenient comparison.
+	// If they are equal, the data is valid against "const".
+	var equal bool
+	if ctx.validator.comparesDecoded() {
+		value, err := jsonwalker.JsonPointer{}.Evaluate(json.RawMessage(*c))
+		equal = err == nil && ctx.validator.valuesEqual(value, jsonData.value)
+	} else {
+		encoder := getEncoder()
+		defer encoder.release()
+
+		rawValue, err := encoder.encode(jsonData.value)
+		if err != nil {
+			return err
+		}
+		equal = string(*c) == string(rawValue)
+	}
+
+	if equal {
 		return nil
 	} else {
 		return KeywordValidationError{
-			"const",
-			"inspected value not equal to \"" + string(*c) + "\"",
+			keyword: "const",
+			reason:  "inspected value not equal to \"" + string(*c) + "\"",
 		}
 	}
 }
@@ -368,13 +684,17 @@ func (c *_const) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+func (c *_const) MarshalJSON() ([]byte, error) {
+	return []byte(*c), nil
+}
+
 /*********************/
 /** String Keywords **/
 /*********************/
 
 type minLength int
 
-func (ml *minLength) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
+func (ml *minLength) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
 	// If jsonData is a string, validate its length,
 	// else, return a KeywordValidationError
 	if v, ok := jsonData.value.(string); ok {
@@ -382,8 +702,8 @@ func (ml *minLength) validate(jsonPath string, jsonData jsonData, rootSchemaId s
 			return nil
 		} else {
 			return KeywordValidationError{
-				"minLength",
-				"inspected string is less than " + strconv.Itoa(int(*ml)),
+				keyword: "minLength",
+				reason:  "inspected string is less than " + strconv.Itoa(int(*ml)),
 			}
 		}
 	}
@@ -393,7 +713,7 @@ func (ml *minLength) validate(jsonPath string, jsonData jsonData, rootSchemaId s
 
 type maxLength int
 
-func (ml *maxLength) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
+func (ml *maxLength) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
 	// If jsonData is a string, validate its length,
 	// else, return a KeywordValidationError
 	if v, ok := jsonData.value.(string); ok {
@@ -401,8 +721,8 @@ func (ml *maxLength) validate(jsonPath string, jsonData jsonData, rootSchemaId s
 			return nil
 		} else {
 			return KeywordValidationError{
-				"maxLength",
-				"inspected string is greater than " + strconv.Itoa(int(*ml)),
+				keyword: "maxLength",
+				reason:  "inspected string is greater than " + strconv.Itoa(int(*ml)),
 			}
 		}
 	}
@@ -410,28 +730,22 @@ func (ml *maxLength) validate(jsonPath string, jsonData jsonData, rootSchemaId s
 	return nil
 }
 
-type pattern string
+// pattern holds the "pattern" keyword and its regular expression, which is
+// compiled when the schema is scanned.
+type pattern struct {
+	source string
+	regexp *regexp.Regexp
+}
 
-func (p *pattern) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
-	// If jsonData is a string, validate its length,
-	// else, return a KeywordValidationError
+func (p *pattern) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	// If jsonData is a string, validate that it matches the pattern.
 	if v, ok := jsonData.value.(string); ok {
-		match, err := regexp.MatchString(string(*p), v)
-
-		// The pattern or the value is not in the right format (string)
-		if err != nil {
-			return KeywordValidationError{
-				"pattern",
-				err.Error(),
-			}
-		}
-
-		if match {
+		if p.regexp.MatchString(v) {
 			return nil
 		} else {
 			return KeywordValidationError{
-				"pattern",
-				"value " + v + " does not match to pattern" + string(*p),
+				keyword: "pattern",
+				reason:  "value " + v + " does not match to pattern" + p.source,
 			}
 		}
 	}
@@ -439,133 +753,65 @@ func (p *pattern) validate(jsonPath string, jsonData jsonData, rootSchemaId stri
 	return nil
 }
 
+// compile compiles the regular expression of the pattern.
+func (p *pattern) compile(schemaPath string) error {
+	var err error
+	p.regexp, err = regexp.Compile(p.source)
+	if err != nil {
+		return SchemaCompilationError{
+			path:  schemaPath + "/pattern",
+			err:   "invalid regular expression: " + err.Error(),
+			cause: err,
+		}
+	}
+
+	return nil
+}
+
+func (p *pattern) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &p.source)
+}
+
+func (p *pattern) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.source)
+}
+
 type format string
 
-func (f *format) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
+func (f *format) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	// Disabled formats are annotations, which are recorded with the other
+	// annotations of the schema.
+	if ctx.validator.disabledFormats[string(*f)] {
+		return nil
+	}
+
+	checker, known := ctx.validator.formatChecker(string(*f))
+	if !known && ctx.validator.strictFormats {
+		return KeywordValidationError{
+			keyword: "format",
+			reason:  "unknown format \"" + string(*f) + "\"",
+		}
+	}
+
 	if v, ok := jsonData.value.(string); ok {
-		switch string(*f) {
-		case FORMAT_DATE_TIME:
-			if err := formatchecker.IsValidDateTime(v); err != nil {
-				return KeywordValidationError{
-					"format",
-					"date-time incorrectly formatted " + err.Error(),
-				}
-			}
-		case FORMAT_DATE:
-			if err := formatchecker.IsValidDate(v); err != nil {
-				return KeywordValidationError{
-					"format",
-					"date incorrectly formatted: " + err.Error(),
-				}
-			}
-		case FORMAT_TIME:
-			if err := formatchecker.IsValidTime(v); err != nil {
-				return KeywordValidationError{
-					"format",
-					"time incorrectly formatted: " + err.Error(),
-				}
-			}
-		case FORMAT_EMAIL:
-			if err := formatchecker.IsValidEmail(v); err != nil {
-				return KeywordValidationError{
-					"format",
-					"email incorrectly formatted: " + err.Error(),
-				}
-			}
-		case FORMAT_IDN_EMAIL:
-			if err := formatchecker.IsValidIdnEmail(v); err != nil {
-				return KeywordValidationError{
-					"format",
-					"idn-email incorrectly formatted: " + err.Error(),
-				}
-			}
-		case FORMAT_HOSTNAME:
-			if err := formatchecker.IsValidHostname(v); err != nil {
-				return KeywordValidationError{
-					"format",
-					"hostname incorrectly formatted: " + err.Error(),
-				}
-			}
-		case FORMAT_IDN_HOSTNAME:
-			if err := formatchecker.IsValidIdnHostname(v); err != nil {
-				return KeywordValidationError{
-					"format",
-					"idn-hostname incorrectly formatted: " + err.Error(),
-				}
-			}
-		case FORMAT_IPV4:
-			if err := formatchecker.IsValidIPv4(v); err != nil {
-				return KeywordValidationError{
-					"format",
-					"ipv4 incorrectly formatted: " + err.Error(),
-				}
-			}
-		case FORMAT_IPV6:
-			if err := formatchecker.IsValidIPv6(v); err != nil {
-				return KeywordValidationError{
-					"format",
-					"ipv6 incorrectly formatted: " + err.Error(),
-				}
-			}
-		case FORMAT_URI:
-			if err := formatchecker.IsValidURI(v); err != nil {
-				return KeywordValidationError{
-					"format",
-					"uri incorrectly formatted: " + err.Error(),
-				}
-			}
-		case FORMAT_URI_REFERENCE:
-			if err := formatchecker.IsValidUriRef(v); err != nil {
-				return KeywordValidationError{
-					"format",
-					"uri-reference incorrectly formatted: " + err.Error(),
-				}
-			}
-		case FORMAT_IRI:
-			if err := formatchecker.IsValidIri(v); err != nil {
-				return KeywordValidationError{
-					"format",
-					"iri incorrectly formatted: " + err.Error(),
-				}
-			}
-		case FORMAT_IRI_REFERENCE:
-			if err := formatchecker.IsValidIriRef(v); err != nil {
-				return KeywordValidationError{
-					"format",
-					"iri-reference incorrectly formatted: " + err.Error(),
-				}
-			}
-		case FORMAT_URI_TEMPLATE:
-			if err := formatchecker.IsValidURITemplate(v); err != nil {
-				return KeywordValidationError{
-					"format",
-					"uri-template incorrectly formatted: " + err.Error(),
-				}
-			}
-		case FORMAT_JSON_POINTER:
-			if err := formatchecker.IsValidJSONPointer(v); err != nil {
-				return KeywordValidationError{
-					"format",
-					"json-pointer incorrectly formatted: " + err.Error(),
-				}
-			}
-		case FORMAT_RELATIVE_JSON_POINTER:
-			if err := formatchecker.IsValidRelJSONPointer(v); err != nil {
-				return KeywordValidationError{
-					"format",
-					"relative-json-pointer incorrectly formatted: " + err.Error(),
-				}
-			}
-		case FORMAT_REGEX:
-			if err := formatchecker.IsValidRegex(v); err != nil {
-				return KeywordValidationError{
-					"format",
-					"regex incorrectly formatted: " + err.Error(),
-				}
-			}
-		default:
+		// Unknown formats are ignored, as the specification allows, but
+		// they are reported as warnings.
+		if !known {
+			ctx.warn(jsonPath, "format", "unknown format \""+string(*f)+"\" is ignored")
 			return nil
 		}
+
+		if string(*f) == FORMAT_HOSTNAME && ctx.validator.strictHostnames {
+			checker = formatchecker.CheckerFunc(formatchecker.IsValidHostnameStrict)
+		}
+
+		if err := checker.Check(v); err != nil {
+			return KeywordValidationError{
+				keyword: "format",
+				reason:  string(*f) + " incorrectly formatted: " + err.Error(),
+				cause:   err,
+			}
+		}
 	}
 
 	return nil
@@ -577,15 +823,19 @@ func (f *format) validate(jsonPath string, jsonData jsonData, rootSchemaId strin
 
 type multipleOf float64
 
-func (mo *multipleOf) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
-	// If jsonData is float64, validate it. Else, return KeywordValidationError
-	if v, ok := jsonData.value.(float64); ok {
-		if math.Mod(v, float64(*mo)) == 0 {
+func (mo *multipleOf) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	// If jsonData is a number, validate it. Else, return KeywordValidationError
+	if v, ok := numberValue(jsonData.value); ok {
+		// With a numeric tolerance, a remainder within the tolerance of 0
+		// or of the divisor is a rounding error.
+		remainder := math.Abs(math.Mod(v, float64(*mo)))
+		tolerance := ctx.validator.numericTolerance
+		if remainder == 0 || (tolerance > 0 && (remainder <= tolerance || math.Abs(float64(*mo))-remainder <= tolerance)) {
 			return nil
 		} else {
 			return KeywordValidationError{
-				"multipleOf",
-				"inspected value is not a multiple of " + strconv.FormatFloat(float64(*mo),
+				keyword: "multipleOf",
+				reason: "inspected value is not a multiple of " + strconv.FormatFloat(float64(*mo),
 					'f',
 					6,
 					64),
@@ -598,15 +848,15 @@ func (mo *multipleOf) validate(jsonPath string, jsonData jsonData, rootSchemaId
 
 type minimum float64
 
-func (m *minimum) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
-	// If jsonData is float64, validate it. Else, return KeywordValidationError
-	if v, ok := jsonData.value.(float64); ok {
+func (m *minimum) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	// If jsonData is a number, validate it. Else, return KeywordValidationError
+	if v, ok := numberValue(jsonData.value); ok {
 		if v >= float64(*m) {
 			return nil
 		} else {
 			return KeywordValidationError{
-				"minimum",
-				"inspected value is less than " + strconv.FormatFloat(float64(*m),
+				keyword: "minimum",
+				reason: "inspected value is less than " + strconv.FormatFloat(float64(*m),
 					'f',
 					6,
 					64),
@@ -619,15 +869,15 @@ func (m *minimum) validate(jsonPath string, jsonData jsonData, rootSchemaId stri
 
 type maximum float64
 
-func (m *maximum) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
-	// If jsonData is float64, validate it. Else, return KeywordValidationError
-	if v, ok := jsonData.value.(float64); ok {
+func (m *maximum) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	// If jsonData is a number, validate it. Else, return KeywordValidationError
+	if v, ok := numberValue(jsonData.value); ok {
 		if v <= float64(*m) {
 			return nil
 		} else {
 			return KeywordValidationError{
-				"maximum",
-				"inspected value is greater than " + strconv.FormatFloat(float64(*m),
+				keyword: "maximum",
+				reason: "inspected value is greater than " + strconv.FormatFloat(float64(*m),
 					'f',
 					6,
 					64),
@@ -638,38 +888,37 @@ func (m *maximum) validate(jsonPath string, jsonData jsonData, rootSchemaId stri
 	return nil
 }
 
-type exclusiveMinimum float64
+// exclusiveMinimum holds either the numeric form of the keyword, or the
+// draft-04 boolean form that turns the sibling "minimum" into an exclusive
+// limit.
+type exclusiveMinimum struct {
+	limit          float64
+	boolean        *bool
+	siblingMinimum *minimum
+}
+
+func (em *exclusiveMinimum) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	limit := em.limit
 
-func (em *exclusiveMinimum) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
-	// If jsonData is float64, validate it. Else, return KeywordValidationError
-	if v, ok := jsonData.value.(float64); ok {
-		if v > float64(*em) {
+	// In the boolean form, the limit is the value of the sibling "minimum"
+	// field, and the keyword has no effect if it is false or if "minimum"
+	// does not exist.
+	if em.boolean != nil {
+		if !*em.boolean || em.siblingMinimum == nil {
 			return nil
-		} else {
-			return KeywordValidationError{
-				"exclusiveMinimum",
-				"inspected value is not greater than " + strconv.FormatFloat(float64(*em),
-					'f',
-					6,
-					64),
-			}
 		}
-	}
-
-	return nil
-}
 
-type exclusiveMaximum float64
+		limit = float64(*em.siblingMinimum)
+	}
 
-func (em *exclusiveMaximum) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
-	// If jsonData is float64, validate it. Else, return KeywordValidationError
-	if v, ok := jsonData.value.(float64); ok {
-		if v < float64(*em) {
+	// If jsonData is a number, validate it. Else, return KeywordValidationError
+	if v, ok := numberValue(jsonData.value); ok {
+		if v > limit {
 			return nil
 		} else {
 			return KeywordValidationError{
-				"exclusiveMaximum",
-				"inspected value is not less than " + strconv.FormatFloat(float64(*em),
+				keyword: "exclusiveMinimum",
+				reason: "inspected value is not greater than " + strconv.FormatFloat(limit,
 					'f',
 					6,
 					64),
@@ -680,31 +929,136 @@ func (em *exclusiveMaximum) validate(jsonPath string, jsonData jsonData, rootSch
 	return nil
 }
 
-/*********************/
+func (em *exclusiveMinimum) UnmarshalJSON(data []byte) error {
+	return unmarshalExclusiveLimit(data, &em.limit, &em.boolean)
+}
+
+func (em *exclusiveMinimum) MarshalJSON() ([]byte, error) {
+	return marshalExclusiveLimit(em.limit, em.boolean)
+}
+
+// exclusiveMaximum holds either the numeric form of the keyword, or the
+// draft-04 boolean form that turns the sibling "maximum" into an exclusive
+// limit.
+type exclusiveMaximum struct {
+	limit          float64
+	boolean        *bool
+	siblingMaximum *maximum
+}
+
+func (em *exclusiveMaximum) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	limit := em.limit
+
+	// In the boolean form, the limit is the value of the sibling "maximum"
+	// field, and the keyword has no effect if it is false or if "maximum"
+	// does not exist.
+	if em.boolean != nil {
+		if !*em.boolean || em.siblingMaximum == nil {
+			return nil
+		}
+
+		limit = float64(*em.siblingMaximum)
+	}
+
+	// If jsonData is a number, validate it. Else, return KeywordValidationError
+	if v, ok := numberValue(jsonData.value); ok {
+		if v < limit {
+			return nil
+		} else {
+			return KeywordValidationError{
+				keyword: "exclusiveMaximum",
+				reason: "inspected value is not less than " + strconv.FormatFloat(limit,
+					'f',
+					6,
+					64),
+			}
+		}
+	}
+
+	return nil
+}
+
+func (em *exclusiveMaximum) UnmarshalJSON(data []byte) error {
+	return unmarshalExclusiveLimit(data, &em.limit, &em.boolean)
+}
+
+func (em *exclusiveMaximum) MarshalJSON() ([]byte, error) {
+	return marshalExclusiveLimit(em.limit, em.boolean)
+}
+
+// unmarshalExclusiveLimit unmarshals the value of "exclusiveMinimum" or
+// "exclusiveMaximum", which is a number, or a boolean in draft-04 schemas.
+func unmarshalExclusiveLimit(data []byte, limit *float64, boolean **bool) error {
+	var value interface{}
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case float64:
+		{
+			*limit = v
+		}
+	case bool:
+		{
+			*boolean = &v
+		}
+	default:
+		{
+			return errors.New("value of an exclusive limit keyword must be a number or a boolean")
+		}
+	}
+
+	return nil
+}
+
+// marshalExclusiveLimit marshals the value of "exclusiveMinimum" or
+// "exclusiveMaximum" back to its original form.
+func marshalExclusiveLimit(limit float64, boolean *bool) ([]byte, error) {
+	if boolean != nil {
+		return json.Marshal(*boolean)
+	}
+
+	return json.Marshal(limit)
+}
+
+/*********************/
 /** Object Keywords **/
 /*********************/
 
 type properties map[string]*JsonSchema
 
-func (p properties) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
+func (p properties) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	var firstErr error
+
 	// First, we need to verify that jsonData is a json object
 	if object, ok := jsonData.value.(map[string]interface{}); ok {
 		// For each "property" validate it according to its JsonSchema.
 		for key, value := range p {
 			// Before we try to validate the data against the schema,
 			// we make sure that the data actually contains the property.
-			if _, ok := object[key]; ok {
-				err := value.validateJsonData(jsonPath+"/"+key, jsonData.raw, rootSchemaId)
+			if property, ok := object[key]; ok {
+				mark := ctx.enterSchema("properties", key)
+				err := value.validateChild(jsonPath, key, property, rootSchemaId, ctx)
+				ctx.leaveSchema(mark)
 				if err != nil {
-					return err
+					if !ctx.continuesAfter(err) {
+						return err
+					}
+
+					if firstErr == nil {
+						firstErr = err
+					}
 				}
 			}
 		}
 	}
 
 	// If we arrived here, the validation of all the properties
-	// succeeded.
-	return nil
+	// succeeded, or the failures are collected and firstErr holds the
+	// first one.
+	return firstErr
 }
 
 type additionalProperties struct {
@@ -713,7 +1067,9 @@ type additionalProperties struct {
 	siblingPatternProperties *patternProperties
 }
 
-func (ap *additionalProperties) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
+func (ap *additionalProperties) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	var firstErr error
+
 	// First we need to verify that jsonData is a json object.
 	if object, isObject := jsonData.value.(map[string]interface{}); isObject {
 		// Iterate over the properties of the inspected object.
@@ -730,207 +1086,307 @@ func (ap *additionalProperties) validate(jsonPath string, jsonData jsonData, roo
 
 			// Check if the property validated against a schema in 'patternProperties' field
 			if (*ap).siblingPatternProperties != nil {
-				// Iterate over the patterns in "patternProperties" field.
-				for pattern := range *ap.siblingPatternProperties {
-					// Check if the inspected property matches to the pattern.
-					match, err := regexp.MatchString(pattern, property)
-
-					// The pattern or the value is not in the right format (string)
-					if err != nil {
-						return KeywordValidationError{
-							"additionalProperties",
-							err.Error(),
-						}
-					}
-
-					// If there is no match, validate the value of the property against
-					// the given schema in "additionalProperties" field.
-					if match {
+				// Iterate over the patterns in "patternProperties" field. If
+				// none of them matches, validate the value of the property
+				// against the given schema in "additionalProperties" field.
+				for _, subSchema := range *ap.siblingPatternProperties {
+					if subSchema.propertyPattern.MatchString(property) {
 						validatedByPatternProperties = true
+						break
 					}
 				}
 			}
 
 			if !validatedByProperties && !validatedByPatternProperties {
-				err := (*ap).validateJsonData(jsonPath+"/"+property, jsonData.raw, rootSchemaId)
-
-				// If the validation fails, return an error.
+				output := ctx.outputMark()
+				mark := ctx.enterSchema("additionalProperties")
+				err := (*ap).validateChild(jsonPath, property, object[property], rootSchemaId, ctx)
+				ctx.leaveSchema(mark)
+
+				// If the validation fails, return an error. A property that
+				// is rejected is likely a typo of the declared properties
+				// that are close to it, so they are suggested.
 				if err != nil {
-					return KeywordValidationError{
-						"additionalProperties",
-						"property \"" +
-							property +
-							"\" failed in validation: \n" + err.Error(),
+					if !ctx.continuesAfter(err) {
+						return ap.propertyError(property, object, err)
+					}
+
+					if firstErr == nil {
+						firstErr = ap.propertyError(property, object, err)
+					}
+
+					for index := output.errors; index < len(ctx.errors); index++ {
+						ctx.errors[index] = ap.propertyError(property, object, ctx.errors[index]).(SchemaValidationError)
 					}
 				}
 			}
 		}
 	}
 
-	// If we arrived here, none of the properties failed in validation,
-	// and we return true.
-	return nil
+	// If we arrived here, none of the properties failed in validation, or
+	// the failures are collected and firstErr holds the first one.
+	return firstErr
+}
+
+// propertyError returns the error of a property that failed in validation
+// against the schema of "additionalProperties", with suggestions of the
+// declared properties that it is likely a typo of.
+func (ap *additionalProperties) propertyError(property string, object map[string]interface{}, err error) error {
+	suggestions := ap.suggestProperties(property, object)
+	reason := "property \"" + property + "\" failed in validation: \n"
+	if len(suggestions) > 0 {
+		reason = "property \"" + property + "\" failed in validation, did you mean \"" +
+			strings.Join(suggestions, "\" or \"") + "\"?: \n"
+	}
+
+	err = subSchemaError("additionalProperties", reason, err)
+	if schemaValidationError, ok := err.(SchemaValidationError); ok && len(suggestions) > 0 &&
+		schemaValidationError.params == nil {
+		schemaValidationError.params = map[string]interface{}{"suggestions": suggestions}
+		return schemaValidationError
+	}
+
+	return err
+}
+
+// suggestProperties returns the properties of the sibling "properties" that
+// are close to a rejected property, and are missing from the object.
+func (ap *additionalProperties) suggestProperties(property string, object map[string]interface{}) []string {
+	if ap.siblingProperties == nil {
+		return nil
+	}
+
+	var candidates []string
+	for name := range *ap.siblingProperties {
+		if _, ok := object[name]; !ok {
+			candidates = append(candidates, name)
+		}
+	}
+
+	return suggest(property, candidates)
 }
 
 type required []string
 
-func (r required) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
+func (r required) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	var firstErr error
+
 	// First, we must verify that jsonData is a json object.
 	if object, ok := jsonData.value.(map[string]interface{}); ok {
 		// For each property in the required list, check if it exists.
+		// When the failures are collected, every missing property is
+		// recorded as a failure of its own.
 		for _, property := range r {
 			if object[property] == nil {
-				return KeywordValidationError{
-					"required",
-					"Missing required property - " + property,
+				err := KeywordValidationError{
+					keyword: "required",
+					reason:  "Missing required property - " + property,
+					params:  map[string]interface{}{"missingProperty": property},
+				}
+				if !ctx.collectsOutput {
+					return err
+				}
+
+				ctx.recordError(ctx.schemaValidationError(jsonPath, err))
+				if firstErr == nil {
+					firstErr = err
 				}
 			}
 		}
 	}
 
-	// Is we arrived here, all the properties exist.
-	return nil
+	// Is we arrived here, all the properties exist, or the failures are
+	// collected and firstErr holds the first one.
+	return firstErr
 }
 
 type propertyNames struct {
 	JsonSchema
 }
 
-func (pn *propertyNames) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
+func (pn *propertyNames) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	var firstErr error
+
 	// First, we need to verify that jsonData is a json object
 	if object, ok := jsonData.value.(map[string]interface{}); ok {
 		// Iterate over the object's properties.
 		for property := range object {
-			// Validate the property name against the schema stored in "propertyNames" field
-			err := pn.validateJsonData("", []byte("\""+property+"\""), rootSchemaId)
-
-			// If the property name could be validated against the scheme return an error
+			// Validate the property name against the schema stored in "propertyNames" field.
+			// The property name is not a value in the document, so failures are
+			// reported at the path of the object.
+			output := ctx.outputMark()
+			mark := ctx.enterSchema("propertyNames")
+			err := pn.validateJsonData(jsonPath, newJsonData(property), rootSchemaId, ctx)
+			ctx.leaveSchema(mark)
+
+			// If the property name could be validated against the scheme return an error.
+			// The failures of the name itself are replaced by the failure
+			// of this keyword, which tells which name failed.
 			if err != nil {
-				return KeywordValidationError{
-					"propertyNames",
-					"property name \"" + property + "\" failed in validation: " + err.Error(),
+				ctx.discardOutput(output)
+
+				err = KeywordValidationError{
+					keyword: "propertyNames",
+					reason:  "property name \"" + property + "\" failed in validation: " + err.Error(),
+					params:  map[string]interface{}{"propertyName": property},
+					cause:   err,
+				}
+				if !ctx.collectsOutput {
+					return err
+				}
+
+				ctx.recordError(ctx.schemaValidationError(jsonPath, err))
+				if firstErr == nil {
+					firstErr = err
 				}
 			}
 		}
 	}
 
 	// If we arrived here it means that all the property names validated successfully against
-	// the schema stored in "propertyNames".
-	return nil
+	// the schema stored in "propertyNames", or the failures are collected and firstErr holds
+	// the first one.
+	return firstErr
+}
+
+// dependencies holds the "dependencies" keyword. The raw dependencies are
+// unmarshaled from the schema, and compiled by scanSchema() into sub-schema
+// dependencies and property dependencies, which are used for validation.
+type dependencies struct {
+	raw map[string]json.RawMessage
+
+	// The sub-schemas that the whole instance must be valid against if it
+	// contains the key property.
+	schemas map[string]*JsonSchema
+
+	// The names of the properties that the instance must contain if it
+	// contains the key property.
+	properties map[string][]string
 }
 
-type dependencies map[string]interface{}
+func (d *dependencies) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &d.raw)
+}
+
+func (d *dependencies) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.raw)
+}
 
-func (d dependencies) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
+func (d *dependencies) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
 	// First we need to verify that jsonData is a json object.
-	if object, ok := jsonData.value.(map[string]interface{}); ok {
+	object, ok := jsonData.value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
 
-		// Iterate over the dependencies object from the schema.
-		for propertyName, dependency := range d {
-			// A dependency may be a json array (consist of strings) of a json
-			// object which is a json schema that the inspected value need to
-			// validated against.
-			switch v := dependency.(type) {
-
-			// In this case the dependency is a sub-schema.
-			case *JsonSchema:
-				{
-					// Check if the propertyName (which is the key in the "dependencies" object)
-					// is present in the data. If it is, validate the whole instance against the
-					// sub-schema.
-					if _, ok := object[propertyName]; ok {
-						// Validate the whole data against the given sub-schema.
-						err := v.validateJsonData("", jsonData.raw, rootSchemaId)
-						if err != nil {
-							return KeywordValidationError{
-								"dependencies",
-								"inspected value failed in validation against sub-schema given in \"" +
-									propertyName +
-									"\" dependency: " +
-									err.Error(),
-							}
-						}
-					}
+	var firstErr error
+
+	// Iterate over the property dependencies. If the instance contains the
+	// property of the dependency, it must contain all the properties in the
+	// dependency array.
+	for propertyName, requiredProperties := range d.properties {
+		if _, ok := object[propertyName]; !ok {
+			continue
+		}
+
+		for _, requiredProperty := range requiredProperties {
+			if _, ok := object[requiredProperty]; !ok {
+				err := KeywordValidationError{
+					keyword: "dependencies",
+					reason: "missing property \"" +
+						requiredProperty +
+						"\" although it is required according to \"" +
+						propertyName +
+						"\" dependency",
+					params: map[string]interface{}{
+						"property":        propertyName,
+						"missingProperty": requiredProperty,
+						"depsCount":       len(requiredProperties),
+						"deps":            strings.Join(requiredProperties, ", "),
+					},
 				}
-			// In this case the dependency is a list of required property names.
-			case []interface{}:
-				{
-					// Iterate over the items in the dependency array.
-					for index, value := range v {
-						// Verify that the value is actually a string.
-						// If not, return an error
-						if requiredProperty, ok := value.(string); ok {
-							// Check if the required property name is missing. If it is,
-							// return an error.
-							if _, ok := object[requiredProperty]; !ok {
-								return KeywordValidationError{
-									"dependencies",
-									"missing property \"" +
-										requiredProperty +
-										"\" although it is required according to \"" +
-										propertyName +
-										"\" dependency",
-								}
-							}
-						} else {
-							return KeywordValidationError{
-								"dependencies",
-								"all items in dependency array must be strings, item at position " +
-									strconv.Itoa(index) +
-									" is not a string",
-							}
-						}
-					}
+				if !ctx.collectsOutput {
+					return err
 				}
-			default:
-				{
-					return KeywordValidationError{
-						"dependencies",
-						"dependency value must be a json object or a json array",
-					}
+
+				ctx.recordError(ctx.schemaValidationError(jsonPath, err))
+				if firstErr == nil {
+					firstErr = err
 				}
 			}
 		}
 	}
 
-	// If we arrived here it means that all the validations succeeded.
-	return nil
+	// Iterate over the schema dependencies. If the instance contains the
+	// property of the dependency, validate the whole instance against the
+	// sub-schema.
+	for propertyName, subSchema := range d.schemas {
+		if _, ok := object[propertyName]; !ok {
+			continue
+		}
+
+		output := ctx.outputMark()
+		mark := ctx.enterSchema("dependencies", propertyName)
+		err := subSchema.validateJsonData(jsonPath, jsonData, rootSchemaId, ctx)
+		ctx.leaveSchema(mark)
+		if err != nil {
+			reason := "inspected value failed in validation against sub-schema given in \"" +
+				propertyName +
+				"\" dependency: "
+			err = subSchemaError("dependencies", reason, err)
+			if !ctx.continuesAfter(err) {
+				return err
+			}
+
+			ctx.wrapErrors(output, "dependencies", reason)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	// If we arrived here it means that all the validations succeeded, or
+	// that the failures are collected and firstErr holds the first one.
+	return firstErr
 }
 
+// patternProperties holds the sub-schemas of the "patternProperties" keyword
+// by their patterns. The regular expression of every pattern is compiled
+// into the propertyPattern of its sub-schema when the schema is scanned.
 type patternProperties map[string]*JsonSchema
 
-func (pp patternProperties) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
+func (pp patternProperties) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	var firstErr error
+
 	// First we need to verify that jsonData is a json object.
 	if object, ok := jsonData.value.(map[string]interface{}); ok {
 		// Iterate over the given patterns.
 		for pattern, subSchema := range pp {
 			// Iterate over the properties in the inspected value.
 			for property := range object {
-				// Check if the property matches to the pattern.
-				match, err := regexp.MatchString(pattern, property)
-
-				// The pattern or the value is not in the right format (string)
-				if err != nil {
-					return KeywordValidationError{
-						"patternProperties",
-						err.Error(),
-					}
-				}
-
-				// If there is a match, validate the value of the property against
-				// the given schema.
-				if match {
-					err := subSchema.validateJsonData(jsonPath+"/"+property, jsonData.raw, rootSchemaId)
+				// If the property matches the pattern, validate its value
+				// against the given schema.
+				if subSchema.propertyPattern.MatchString(property) {
+					output := ctx.outputMark()
+					mark := ctx.enterSchema("patternProperties", pattern)
+					err := subSchema.validateChild(jsonPath, property, object[property], rootSchemaId, ctx)
+					ctx.leaveSchema(mark)
 
 					// If the validation fails, return an error.
 					if err != nil {
-						return KeywordValidationError{
-							"patternProperties",
-							"property \"" +
-								property +
-								"\" that matches the pattern \"" +
-								pattern +
-								"\" failed in validation: \n" + err.Error(),
+						reason := "property \"" +
+							property +
+							"\" that matches the pattern \"" +
+							pattern +
+							"\" failed in validation: \n"
+						err = subSchemaError("patternProperties", reason, err)
+						if !ctx.continuesAfter(err) {
+							return err
+						}
+
+						ctx.wrapErrors(output, "patternProperties", reason)
+						if firstErr == nil {
+							firstErr = err
 						}
 					}
 				}
@@ -939,13 +1395,14 @@ func (pp patternProperties) validate(jsonPath string, jsonData jsonData, rootSch
 	}
 
 	// If we arrived here it means that none of the properties failed in
-	// validation against any of the given schemas.
-	return nil
+	// validation against any of the given schemas, or that the failures are
+	// collected and firstErr holds the first one.
+	return firstErr
 }
 
 type minProperties int
 
-func (mp *minProperties) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
+func (mp *minProperties) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
 	// First, we must verify that jsonData is a json object.
 	// If it is not a json object, we return an error.
 	if v, ok := jsonData.value.(map[string]interface{}); ok {
@@ -956,8 +1413,8 @@ func (mp *minProperties) validate(jsonPath string, jsonData jsonData, rootSchema
 			return nil
 		} else {
 			return KeywordValidationError{
-				"minProperties",
-				"inspected value must contains at least " + strconv.Itoa(int(*mp)) + " properties",
+				keyword: "minProperties",
+				reason:  "inspected value must contains at least " + strconv.Itoa(int(*mp)) + " properties",
 			}
 		}
 	}
@@ -967,7 +1424,7 @@ func (mp *minProperties) validate(jsonPath string, jsonData jsonData, rootSchema
 
 type maxProperties int
 
-func (mp *maxProperties) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
+func (mp *maxProperties) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
 	// First, we must verify that jsonData is a json object.
 	// If it is not a json object, we return an error.
 	if v, ok := jsonData.value.(map[string]interface{}); ok {
@@ -978,8 +1435,8 @@ func (mp *maxProperties) validate(jsonPath string, jsonData jsonData, rootSchema
 			return nil
 		} else {
 			return KeywordValidationError{
-				"minProperties",
-				"inspected value may contains at most " +
+				keyword: "maxProperties",
+				reason: "inspected value may contains at most " +
 					strconv.Itoa(int(*mp)) +
 					" properties",
 			}
@@ -993,247 +1450,344 @@ func (mp *maxProperties) validate(jsonPath string, jsonData jsonData, rootSchema
 /** Array Keywords **/
 /********************/
 
-type items json.RawMessage
+// items holds the schema, or the array of schemas, of the "items" keyword,
+// which are unmarshaled once with the schema and compiled into a tupleItems.
+type items struct {
+	// The keyword as it appears in the schema.
+	raw json.RawMessage
 
-func (i items) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
-	// First, we need to verify that json Data is an array
-	if array, ok := jsonData.value.([]interface{}); ok {
-		var data interface{}
+	// The schema of the keyword, or its schemas if it is an array. Both
+	// are nil if the keyword is neither, which compile() reports.
+	schema  *JsonSchema
+	schemas []*JsonSchema
 
-		// Unmarshal the value in items in order to figure out if it is a
-		// json object or json array
-		err := json.Unmarshal(i, &data)
-		if err != nil {
-			return err
-		}
+	// The errors of the schemas that could not be unmarshaled, by their
+	// json pointers relative to the keyword, which compile() reports.
+	errs map[string]error
+}
 
-		// Handle the value in items according to its json type.
-		switch itemsField := data.(type) {
-		// If jsonData is a json object, which means that is holds a single schema,
-		// we validate the all the items in the inspected array against the given
-		// schema.
-		case map[string]interface{}:
-			{
-				// This is the JsonSchema instance that should hold the schema in
-				// "items" field.
-				var schema JsonSchema
+type prefixItems []*JsonSchema
+
+// tupleItems is the compiled form of "items" and "additionalItems". "items"
+// is either a single schema that every item is validated against, or an
+// array of schemas that the items at the same positions are validated
+// against, in which case the rest of the items are validated against
+// "additionalItems", if the schema has it.
+type tupleItems struct {
+	schema     *JsonSchema
+	schemas    []*JsonSchema
+	additional *JsonSchema
+
+	// prefix is true if schemas are the schemas of "prefixItems", and
+	// additional is the schema of "items", as in draft 2020-12.
+	prefix bool
+}
 
-				// Unmarshal the rawSchema into the JsonSchema struct.
-				err = json.Unmarshal(i, &schema)
-				if err != nil {
-					return err
-				}
+// keywords returns the names of the keywords that the schemas and the
+// additional schema of the tuple come from.
+func (t *tupleItems) keywords() (string, string) {
+	if t.prefix {
+		return "prefixItems", "items"
+	}
 
-				// Iterate over the items in the inspected array and validate each
-				// item against the schema in "items" field.
-				for index := 0; index < len(array); index++ {
-					err := schema.validateJsonData(jsonPath+"/"+strconv.Itoa(index), jsonData.raw, rootSchemaId)
-					if err != nil {
-						return err
-					}
-				}
-			}
-		// If jsonData is a json array, which means that is holds multiple json schema objects,
-		// we validate each item in the inspected array against the schema at the same position.
-		case []interface{}:
-			{
-				if len(itemsField) > len(array) {
-					return KeywordValidationError{
-						"items",
-						"when \"items\" field contains a list of Json Schema objects, the " +
-							"inspected array must contain at least the same amount of items",
-					}
-				}
+	return "items", "additionalItems"
+}
 
-				// Iterate over the schemas in "items" field.
-				for index, schemaFromItems := range itemsField {
-					// Marshal the current schema in "items" field in order to Unmarshal it
-					// into JsonSchema instance.
-					rawSchema, err := json.Marshal(schemaFromItems)
-					if err != nil {
-						return err
-					}
+func (t *tupleItems) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	// First, we need to verify that json Data is an array
+	array, ok := jsonData.value.([]interface{})
+	if !ok {
+		return nil
+	}
 
-					// This is the JsonSchema instance that should hold the current
-					// working schema.
-					var schema JsonSchema
+	err := t.validateLength(len(array))
+	if err != nil {
+		return err
+	}
 
-					// Unmarshal the rawSchema into the JsonSchema struct.
-					err = json.Unmarshal(rawSchema, &schema)
-					if err != nil {
-						return err
-					}
+	// firstErr holds the first item failure when the items are reported
+	// one by one to the validator.
+	var firstErr error
+
+	// Every item is validated against its schema, and the errors refer to
+	// the index of the item in the inspected array.
+	for index := 0; index < len(array); index++ {
+		applies, err := t.validateIndex(jsonPath, index, array[index], rootSchemaId, ctx)
+		if !applies {
+			// The items that follow the schemas of "items" are valid
+			// without "additionalItems" (or the schemas of "prefixItems"
+			// without "items").
+			return firstErr
+		}
 
-					// Validate the item against the schema at the same position.
-					err = schema.validateJsonData(jsonPath+"/"+strconv.Itoa(index), jsonData.raw, rootSchemaId)
-					if err != nil {
-						return err
-					}
-				}
+		// If the validator reports the items of the top-level array,
+		// report the result and keep validating the rest of the items.
+		if ctx.reportsItems() {
+			ctx.reportItem(index, err)
+			if err != nil && firstErr == nil {
+				firstErr = err
 			}
-		// The default case indicates that the value in items field is not a json schema or
-		// a list of json schema.
-		default:
-			{
-				return KeywordValidationError{
-					"items",
-					"\"items\" field value in schema must be a valid Json Schema or an array of Json Schema",
-				}
+
+			continue
+		}
+
+		if err != nil {
+			if !ctx.continuesAfter(err) {
+				return err
+			}
+
+			if firstErr == nil {
+				firstErr = err
 			}
 		}
 	}
 
 	// If we arrived here it means that all the items in the inspected array
-	// validated successfully against the given schema.
-	return nil
+	// validated successfully, or that the items were reported one by one or
+	// the failures are collected, and firstErr holds the first failure.
+	return firstErr
 }
 
-func (i *items) UnmarshalJSON(data []byte) error {
-	*i = data
+// validateLength checks that an array has an item for each of the schemas of
+// "items". Unlike the schemas of "items", the schemas of "prefixItems" do not
+// require the array to have an item for each of them.
+func (t *tupleItems) validateLength(length int) error {
+	if t.schemas != nil && !t.prefix && len(t.schemas) > length {
+		return KeywordValidationError{
+			keyword: "items",
+			reason: "when \"items\" field contains a list of Json Schema objects, the " +
+				"inspected array must contain at least the same amount of items",
+		}
+	}
+
 	return nil
 }
 
-type additionalItems struct {
-	JsonSchema
-	siblingItems *items
+// validateIndex validates the item at the given index of the array at
+// jsonPath against its schema. applies is false if no schema applies to the
+// item, in which case none applies to the items that follow it either.
+func (t *tupleItems) validateIndex(jsonPath string, index int, item interface{}, rootSchemaId string, ctx *validationContext) (applies bool, err error) {
+	schemasKeyword, additionalKeyword := t.keywords()
+
+	switch {
+	case t.schema != nil:
+		mark := ctx.enterSchema("items")
+		err = t.schema.validateItem(jsonPath, index, item, rootSchemaId, ctx)
+		ctx.leaveSchema(mark)
+	case index < len(t.schemas):
+		mark := ctx.enterSchema(schemasKeyword, strconv.Itoa(index))
+		err = t.schemas[index].validateItem(jsonPath, index, item, rootSchemaId, ctx)
+		ctx.leaveSchema(mark)
+	case t.additional != nil:
+		output := ctx.outputMark()
+		mark := ctx.enterSchema(additionalKeyword)
+		err = t.additional.validateItem(jsonPath, index, item, rootSchemaId, ctx)
+		ctx.leaveSchema(mark)
+		if err != nil {
+			reason := "item at position " + strconv.Itoa(index) + " failed in validation: "
+			err = subSchemaError(additionalKeyword, reason, err)
+			ctx.wrapErrors(output, additionalKeyword, reason)
+		}
+	default:
+		return false, nil
+	}
+
+	return true, err
 }
 
-func (ai *additionalItems) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
-	// Unmarshal the sibling field "items" in order to check it's json type.
-	var siblingItems interface{}
-	err := json.Unmarshal(*ai.siblingItems, &siblingItems)
-	if err != nil {
-		return err
+// schemaAt returns the schema of "items" or "additionalItems" that the item
+// at the given index is validated against, or nil if there is none.
+func (t *tupleItems) schemaAt(index int) *JsonSchema {
+	switch {
+	case t.schema != nil:
+		return t.schema
+	case index < len(t.schemas):
+		return t.schemas[index]
+	default:
+		return t.additional
 	}
+}
 
-	// If "items" is a json array, "additionalItems" needs to verify the items
-	// that the schema in "items" field did not validate.
-	if itemsArray, ok := siblingItems.([]interface{}); ok {
-		// Check if jsonData is a json array.
-		if array, ok := jsonData.value.([]interface{}); ok {
-			// Iterate over the inspected array from the position that items stopped
-			// validating.
-			for index := range array[len(itemsArray):] {
-				// Validate the inspected item against the schema given in "additionalItems".
-				err := ai.validateJsonData(jsonPath+"/"+strconv.Itoa(index), jsonData.raw, rootSchemaId)
-				if err != nil {
-					return KeywordValidationError{
-						"additionalItems",
-						"item at position " +
-							strconv.Itoa(index) +
-							" failed in validation: " +
-							err.Error(),
-					}
-				}
-			}
+func (i *items) UnmarshalJSON(data []byte) error {
+	i.raw = append(json.RawMessage(nil), data...)
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil
+	}
+
+	switch trimmed[0] {
+	case '[':
+		var rawSchemas []json.RawMessage
+		err := json.Unmarshal(data, &rawSchemas)
+		if err != nil {
+			return err
+		}
 
-			// If we arrived here it means that no item failed in validation.
+		i.schemas = make([]*JsonSchema, len(rawSchemas))
+		for index, rawSchema := range rawSchemas {
+			subSchema := new(JsonSchema)
+			err = json.Unmarshal(rawSchema, subSchema)
+			if err != nil {
+				i.addError("/"+strconv.Itoa(index), err)
+				continue
+			}
+			i.schemas[index] = subSchema
+		}
+	case '{', 't', 'f':
+		subSchema := new(JsonSchema)
+		err := json.Unmarshal(data, subSchema)
+		if err != nil {
+			i.addError("", err)
 			return nil
 		}
+		i.schema = subSchema
 	}
 
-	// If "items" field is not an array of json schema, additionalItems
-	// is meaningless so we return true.
 	return nil
 }
 
+func (i *items) addError(pointer string, err error) {
+	if i.errs == nil {
+		i.errs = make(map[string]error)
+	}
+	i.errs[pointer] = err
+}
+
+func (i *items) MarshalJSON() ([]byte, error) {
+	return []byte(i.raw), nil
+}
+
+// additionalItems is validated by the tupleItems of its sibling "items".
+type additionalItems struct {
+	JsonSchema
+}
+
 type contains struct {
 	JsonSchema
 }
 
-func (c *contains) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
+func (c *contains) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
 	// First, we need to verify that jsonData is a json array.
 	if array, ok := jsonData.value.([]interface{}); ok {
+		// When the output of the validation is collected, every item is
+		// validated in order to record the indices of all the matching
+		// items as an annotation. Otherwise the first match is enough.
+		var matched []int
+
 		// Go over all the items in the array in order to inspect them.
-		for index := range array {
+		for index, item := range array {
 			// If the item is valid against the given schema, which means that
 			// the array contains the required value.
-			err := (*c).validateJsonData(jsonPath+"/"+strconv.Itoa(index), jsonData.raw, rootSchemaId)
+			output := ctx.outputMark()
+			mark := ctx.enterSchema("contains")
+			err := (*c).validateItem(jsonPath, index, item, rootSchemaId, ctx)
+			ctx.leaveSchema(mark)
 			if err == nil {
-				return nil
+				if !ctx.collectsOutput {
+					return nil
+				}
+
+				matched = append(matched, index)
+				continue
 			}
+			ctx.discardOutput(output)
+		}
+
+		if len(matched) > 0 {
+			ctx.recordAnnotation(jsonPath, "contains", matched)
+			return nil
 		}
 	}
 
 	// If we arrived here it means that we could not validate any of the array's
 	// items against the given schema.
 	return KeywordValidationError{
-		"contains",
-		"could validate any of the inspected array's items against the given schema",
+		keyword: "contains",
+		reason:  "could validate any of the inspected array's items against the given schema",
 	}
 }
 
 type minItems int
 
-func (mi *minItems) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
+func (mi *minItems) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
 	// First, we need to verify that jsonData is an array.
 	if v, ok := jsonData.value.([]interface{}); ok {
-		// Check that the number of items in the array is equal to
-		// or greater than minItems.
-		if len(v) >= int(*mi) {
-			return nil
-		} else {
-			return KeywordValidationError{
-				"minItems",
-				"inspected array must contain at least " + strconv.Itoa(int(*mi)) + " items",
-			}
-		}
+		return mi.validateLength(len(v))
 	}
 
 	return nil
 }
 
+// validateLength checks that the number of items in an array is equal to or
+// greater than minItems.
+func (mi *minItems) validateLength(length int) error {
+	if length >= int(*mi) {
+		return nil
+	}
+
+	return KeywordValidationError{
+		keyword: "minItems",
+		reason:  "inspected array must contain at least " + strconv.Itoa(int(*mi)) + " items",
+	}
+}
+
 type maxItems int
 
-func (mi *maxItems) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
+func (mi *maxItems) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
 	// First, we need to verify that jsonData is an array.
 	if v, ok := jsonData.value.([]interface{}); ok {
-		// Check that the number of items in the array is equal to
-		// or less than maxItems.
-		if len(v) <= int(*mi) {
-			return nil
-		} else {
-			return KeywordValidationError{
-				"maxItems",
-				"inspected array must contain at most " + strconv.Itoa(int(*mi)) + " items",
-			}
-		}
+		return mi.validateLength(len(v))
 	}
 
 	return nil
 }
 
+// validateLength checks that the number of items in an array is equal to or
+// less than maxItems.
+func (mi *maxItems) validateLength(length int) error {
+	if length <= int(*mi) {
+		return nil
+	}
+
+	return KeywordValidationError{
+		keyword: "maxItems",
+		reason:  "inspected array must contain at most " + strconv.Itoa(int(*mi)) + " items",
+	}
+}
+
 type uniqueItems bool
 
-func (ui *uniqueItems) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
+func (ui *uniqueItems) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	// "uniqueItems": false does not restrict the array.
+	if !*ui {
+		return nil
+	}
+
 	// First, we need to verify that jsonData is an array.
 	if array, ok := jsonData.value.([]interface{}); ok {
-		// Create a map that will help us to check if we already met the
-		// item by using the map's hashing mechanism.
-		uniqueSet := make(map[string]int)
+		// If the validator limits the check, only the first items of the
+		// array are compared.
+		if limit := ctx.validator.uniqueItemsLimit; limit > 0 && len(array) > limit {
+			array = array[:limit]
+		}
 
-		// Iterate over the items in the inspected array.
-		for index, item := range array {
-			// Marshal the item back to hash-able value, because maps (json object)
-			// and slices (json arrays) are not a hash-able values.
-			rawItem, err := json.Marshal(item)
-			if err != nil {
-				return err
-			}
+		var first, second int
+		var found bool
+		if len(array) > maxUniqueItemsMapSize {
+			first, second, found = findEqualItemsSorted(array)
+		} else {
+			first, second, found = findEqualItems(array)
+		}
 
-			// If ok is true it means that the value exists in the map, which means
-			// we already met it in one of the previous iterations.
-			// Else, insert the item into the map as key, and the index as value.
-			if v, ok := uniqueSet[string(rawItem)]; ok {
-				return KeywordValidationError{
-					"uniqueItems",
-					"the inspected array contains two equal items at indices: " +
-						strconv.Itoa(v) +
-						", " +
-						strconv.Itoa(index),
-				}
-			} else {
-				uniqueSet[string(rawItem)] = index
+		if found {
+			return KeywordValidationError{
+				keyword: "uniqueItems",
+				reason: "the inspected array contains two equal items at indices: " +
+					strconv.Itoa(first) +
+					", " +
+					strconv.Itoa(second),
+				params: map[string]interface{}{"i": first, "j": second},
 			}
 		}
 
@@ -1245,12 +1799,87 @@ func (ui *uniqueItems) validate(jsonPath string, jsonData jsonData, rootSchemaId
 	return nil
 }
 
-/********************/
-/** Other Keywords **/
-/********************/
+// Arrays with more items than this are checked by findEqualItemsSorted(),
+// which uses less memory per item than findEqualItems().
+const maxUniqueItemsMapSize = 4096
+
+// findEqualItems returns the indices of the first item of the array that is
+// equal to a previous item, and of that previous item.
+func findEqualItems(array []interface{}) (int, int, bool) {
+	// Group the indices of the items by the hashes of the items. Items
+	// with equal hashes are compared to each other, since different
+	// values may have the same hash.
+	indicesByHash := make(map[uint64][]int, len(array))
+
+	// Iterate over the items in the inspected array.
+	for index, item := range array {
+		hash := hashValue(item)
+
+		// If one of the previous items with the same hash is equal to
+		// the item, it means we already met it in one of the previous
+		// iterations.
+		for _, previous := range indicesByHash[hash] {
+			if equalValues(array[previous], item) {
+				return previous, index, true
+			}
+		}
+
+		indicesByHash[hash] = append(indicesByHash[hash], index)
+	}
+
+	return 0, 0, false
+}
+
+// hashedItem is the hash of an array item and the index of the item.
+type hashedItem struct {
+	hash  uint64
+	index int
+}
+
+// findEqualItemsSorted is like findEqualItems(), but it sorts the hashes of
+// the items instead of building a map, so it uses a fixed amount of memory
+// per item, which matters for arrays with hundreds of thousands of items.
+func findEqualItemsSorted(array []interface{}) (int, int, bool) {
+	items := make([]hashedItem, len(array))
+	for index, item := range array {
+		items[index] = hashedItem{hashValue(item), index}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].hash != items[j].hash {
+			return items[i].hash < items[j].hash
+		}
+
+		return items[i].index < items[j].index
+	})
+
+	// Items with equal hashes are adjacent. Like findEqualItems(), report
+	// the equal pair whose second item comes first in the array.
+	first, second, found := 0, 0, false
+	for start := 0; start < len(items); {
+		end := start + 1
+		for end < len(items) && items[end].hash == items[start].hash {
+			end++
+		}
 
-type contentMediaType string
-type contentEncoding string
+		for i := start; i < end; i++ {
+			for j := start; j < i; j++ {
+				if found && items[i].index >= second {
+					break
+				}
+
+				if equalValues(array[items[j].index], array[items[i].index]) {
+					first, second, found = items[j].index, items[i].index, true
+					break
+				}
+			}
+		}
+
+		start = end
+	}
+
+	return first, second, found
+}
 
 /**************************/
 /** Conditional Keywords **/
@@ -1258,70 +1887,97 @@ type contentEncoding string
 
 type anyOf []*JsonSchema
 
-func (af anyOf) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
-	// Validate jsonData.raw against each of the schemas until on of them succeeds.
-	for _, schema := range af {
-		err := schema.validateJsonData("", jsonData.raw, rootSchemaId)
+func (af anyOf) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	// Validate jsonData against each of the schemas until on of them succeeds.
+	errs := make([]error, 0, len(af))
+	for index, schema := range af {
+		output := ctx.outputMark()
+		mark := ctx.enterSchema("anyOf", strconv.Itoa(index))
+		err := schema.validateJsonData(jsonPath, jsonData, rootSchemaId, ctx)
+		ctx.leaveSchema(mark)
 		if err == nil {
 			return nil
 		}
+		ctx.discardOutput(output)
+		errs = append(errs, err)
 	}
 
 	// If we arrived here, the validation of jsonData failed against all schemas.
-	return KeywordValidationError{
-		"anyOf",
-		"inspected value could not be validated against any of the given schemas",
-	}
+	return noMatchError("anyOf", errs)
 }
 
 type allOf []*JsonSchema
 
-func (af allOf) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
-	// Validate jsonData.raw against each of the schemas.
+func (af allOf) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	var firstErr error
+
+	// Validate jsonData against each of the schemas.
 	// If one of them fails, return error.
-	for _, schema := range af {
-		err := schema.validateJsonData("", jsonData.raw, rootSchemaId)
+	for index, schema := range af {
+		output := ctx.outputMark()
+		mark := ctx.enterSchema("allOf", strconv.Itoa(index))
+		err := schema.validateJsonData(jsonPath, jsonData, rootSchemaId, ctx)
+		ctx.leaveSchema(mark)
 		if err != nil {
-			return KeywordValidationError{
-				"allOf",
-				"inspected value could not be validated against all of the given schemas",
+			reason := "inspected value could not be validated against all of the given schemas: "
+			err = subSchemaError("allOf", reason, err)
+			if !ctx.continuesAfter(err) {
+				return err
+			}
+
+			ctx.wrapErrors(output, "allOf", reason)
+			if firstErr == nil {
+				firstErr = err
 			}
 		}
 	}
 
 	// If we arrived here, the validation of jsonData succeeded against all
-	// given schemas.
-	return nil
+	// given schemas, or the failures are collected and firstErr holds the
+	// first one.
+	return firstErr
 }
 
 type oneOf []*JsonSchema
 
-func (of oneOf) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
+func (of oneOf) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
 	var oneValidationAlreadySucceeded bool
-
-	// Validate jsonData.raw against each of the schemas until on of them succeeds.
-	for _, schema := range of {
-		err := schema.validateJsonData("", jsonData.raw, rootSchemaId)
-		if err == nil {
+	var succeededIndex int
+	firstOutput := ctx.outputMark()
+	errs := make([]error, 0, len(of))
+
+	// Validate jsonData against each of the schemas until on of them succeeds.
+	for index, schema := range of {
+		output := ctx.outputMark()
+		mark := ctx.enterSchema("oneOf", strconv.Itoa(index))
+		err := schema.validateJsonData(jsonPath, jsonData, rootSchemaId, ctx)
+		ctx.leaveSchema(mark)
+		if err != nil {
+			ctx.discardOutput(output)
+			errs = append(errs, err)
+		} else {
 			if oneValidationAlreadySucceeded {
+				ctx.discardOutput(firstOutput)
 				return KeywordValidationError{
-					"oneOf",
-					"inspected data is valid against more than one given schema",
+					keyword: "oneOf",
+					reason:  "inspected data is valid against more than one given schema",
+					params: map[string]interface{}{
+						"passingSchemas": []int{succeededIndex, index},
+					},
 				}
 			} else {
 				oneValidationAlreadySucceeded = true
+				succeededIndex = index
 			}
 		}
 	}
 
 	if oneValidationAlreadySucceeded {
+		ctx.recordBranch(firstOutput, jsonPath, "oneOf", "oneOf", strconv.Itoa(succeededIndex))
 		return nil
 	} else {
 		// If we arrived here, the validation of jsonData failed against all schemas.
-		return KeywordValidationError{
-			"oneOf",
-			"inspected value could not be validated against any of the given schemas",
-		}
+		return noMatchError("oneOf", errs)
 	}
 }
 
@@ -1329,14 +1985,18 @@ type not struct {
 	JsonSchema
 }
 
-func (n *not) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
-	err := (*n).validateJsonData(jsonPath, jsonData.raw, rootSchemaId)
+func (n *not) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	output := ctx.outputMark()
+	mark := ctx.enterSchema("not")
+	err := (*n).validateJsonData(jsonPath, jsonData, rootSchemaId, ctx)
+	ctx.leaveSchema(mark)
+	ctx.discardOutput(output)
 	if err != nil {
 		return nil
 	} else {
 		return KeywordValidationError{
-			"not",
-			"inspected value did not fail on validation against the schema defined by this keyword",
+			keyword: "not",
+			reason:  "inspected value did not fail on validation against the schema defined by this keyword",
 		}
 	}
 }
@@ -1347,20 +2007,28 @@ type _if struct {
 	siblingElse *_else
 }
 
-func (i *_if) validate(jsonPath string, jsonData jsonData, rootSchemaId string) error {
+func (i *_if) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
 	// Validate the data against the given schema in "if".
-	err := (*i).validateJsonData("", jsonData.raw, rootSchemaId)
+	output := ctx.outputMark()
+	mark := ctx.enterSchema("if")
+	err := (*i).validateJsonData(jsonPath, jsonData, rootSchemaId, ctx)
+	ctx.leaveSchema(mark)
 
 	// If the validation succeeded, validate the data against the given schema
 	// in "then".
 	// Else, validate the data against the given schema in "else".
 	if err == nil {
+		ctx.recordBranch(output, jsonPath, "if", "then")
 		if (*i).siblingThen != nil {
-			return (*i).siblingThen.validateJsonData(jsonPath, jsonData.raw, rootSchemaId)
+			defer ctx.leaveSchema(ctx.enterSchema("then"))
+			return (*i).siblingThen.validateJsonData(jsonPath, jsonData, rootSchemaId, ctx)
 		}
 	} else {
+		ctx.discardOutput(output)
+		ctx.recordBranch(output, jsonPath, "if", "else")
 		if (*i).siblingElse != nil {
-			return (*i).siblingElse.validateJsonData(jsonPath, jsonData.raw, rootSchemaId)
+			defer ctx.leaveSchema(ctx.enterSchema("else"))
+			return (*i).siblingElse.validateJsonData(jsonPath, jsonData, rootSchemaId, ctx)
 		}
 	}
 
@@ -1381,3 +2049,4 @@ type _else struct {
 
 type readOnly bool
 type writeOnly bool
+type deprecated bool
@@ -0,0 +1,81 @@
+//go:build !js
+// +build !js
+
+package jsonvalidator
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// LoadRootJsonSchema creates a RootJsonSchema from the schema document in
+// the file at the given path. A schema without "$id" is identified by the
+// "file://" URI of the file, so its relative references (for example
+// "common.json#/definitions/address") are resolved against the location of
+// the file. The files of the schemas that it references are loaded too,
+// unless a schema with their URI was already created.
+func LoadRootJsonSchema(path string) (*RootJsonSchema, error) {
+	absolutePath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return loadRootJsonSchema(fileURI(absolutePath))
+}
+
+func loadRootJsonSchema(uri string) (*RootJsonSchema, error) {
+	bytes, err := FileLoader{}.Load(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	rootSchema, err := newRootJsonSchema(DefaultRegistry, bytes, uri, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Load the files that the references of the schema point to, whether or
+	// not a schema loader was set.
+	err = loadReferences(rootSchema, SchemeLoader{"file": FileLoader{}})
+	if err != nil {
+		return nil, err
+	}
+
+	return rootSchema, nil
+}
+
+func init() {
+	DefaultLoader["file"] = FileLoader{}
+}
+
+// FileLoader is a SchemaLoader that reads "file://" URIs from the file
+// system.
+type FileLoader struct{}
+
+// Load reads the file of the URI.
+func (FileLoader) Load(uri string) ([]byte, error) {
+	return ioutil.ReadFile(filePath(uri))
+}
+
+// fileURI returns the "file://" URI of an absolute path.
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// A Windows path that starts with a drive letter.
+		path = "/" + path
+	}
+
+	return "file://" + path
+}
+
+// filePath returns the path of a "file://" URI.
+func filePath(uri string) string {
+	path := strings.TrimPrefix(uri, "file://")
+	if len(path) > 2 && path[2] == ':' {
+		// A Windows path that starts with a drive letter.
+		path = path[1:]
+	}
+
+	return filepath.FromSlash(path)
+}
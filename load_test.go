@@ -0,0 +1,76 @@
+//go:build !js
+// +build !js
+
+package jsonvalidator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadRootJsonSchema(t *testing.T) {
+	directory, err := ioutil.TempDir("", "jsonvalidator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	files := map[string]string{
+		"person.json": `{
+			"properties": {
+				"address": {"$ref": "common/address.json#/definitions/address"},
+				"friend": {"$ref": "#"}
+			}
+		}`,
+		"common/address.json": `{
+			"definitions": {
+				"address": {
+					"required": ["city"],
+					"properties": {"city": {"$ref": "../types.json#/definitions/name"}}
+				}
+			}
+		}`,
+		"types.json": `{"definitions": {"name": {"type": "string", "minLength": 1}}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(directory, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rootSchema, err := LoadRootJsonSchema(filepath.Join(directory, "person.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := NewValidator(rootSchema)
+	testCases := []struct {
+		document string
+		valid    bool
+	}{
+		{`{"address": {"city": "Haifa"}}`, true},
+		{`{"friend": {"address": {"city": "Haifa"}}}`, true},
+		{`{"address": {}}`, false},
+		{`{"address": {"city": ""}}`, false},
+		{`{"friend": {"address": {"city": 1}}}`, false},
+	}
+
+	for _, testCase := range testCases {
+		err := validator.Validate([]byte(testCase.document))
+		if testCase.valid && err != nil {
+			t.Errorf("%s: unexpected error %v", testCase.document, err)
+		} else if !testCase.valid && err == nil {
+			t.Errorf("%s: expected an error", testCase.document)
+		}
+	}
+
+	if _, err := LoadRootJsonSchema(filepath.Join(directory, "missing.json")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,126 @@
+package jsonvalidator
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SchemaLoader loads the schema documents that references point to, by the
+// absolute URIs of the documents (without fragments).
+type SchemaLoader interface {
+	Load(uri string) ([]byte, error)
+}
+
+// SchemaLoaderFunc is a function that implements SchemaLoader.
+type SchemaLoaderFunc func(uri string) ([]byte, error)
+
+// Load calls the function.
+func (f SchemaLoaderFunc) Load(uri string) ([]byte, error) {
+	return f(uri)
+}
+
+// SchemeLoader is a SchemaLoader that loads every URI with the loader of its
+// scheme, like "https". It returns an UnsupportedSchemeError for URIs of
+// other schemes.
+type SchemeLoader map[string]SchemaLoader
+
+// Load loads the URI with the loader of its scheme.
+func (l SchemeLoader) Load(uri string) ([]byte, error) {
+	scheme := strings.ToLower(strings.SplitN(uri, ":", 2)[0])
+	loader, ok := l[scheme]
+	if !ok || !hasScheme(uri) {
+		return nil, UnsupportedSchemeError(scheme)
+	}
+
+	return loader.Load(uri)
+}
+
+// DefaultLoader loads "http" and "https" URIs with an HTTPLoader, and "file"
+// URIs with a FileLoader, on platforms that have them (see httploader.go and
+// load.go).
+var DefaultLoader = SchemeLoader{}
+
+// SetSchemaLoader sets the loader of the schemas that the "$ref" keywords of
+// the schemas of the DefaultRegistry point to, like
+// Registry.SetSchemaLoader().
+func SetSchemaLoader(loader SchemaLoader) {
+	DefaultRegistry.SetSchemaLoader(loader)
+}
+
+// SetSchemaLoader sets the loader of the schemas that "$ref" keywords point
+// to. When a root schema is compiled in the registry, the documents of the
+// schemas that its references point to are loaded with the loader, compiled
+// and registered in the registry under their URIs (recursively), unless
+// schemas were already registered under the URIs. For example,
+// SetSchemaLoader(DefaultLoader) fetches "http" and "https" references. A
+// loader that returns an UnsupportedSchemeError leaves the reference to be
+// resolved when documents are validated, and other errors fail the
+// compilation. References are not loaded if the loader is nil, which is the
+// default, and the loaders of other registries are never used.
+func (r *Registry) SetSchemaLoader(loader SchemaLoader) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.loader = loader
+}
+
+// schemaLoader returns the loader that SetSchemaLoader() set.
+func (r *Registry) schemaLoader() SchemaLoader {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.loader
+}
+
+// loadReferences loads the schemas that the references of the root schema
+// point to with the loader, and the schemas that their references point to,
+// unless they were already registered. The loaded schemas are compiled in
+// the registry of the root schema. The root schema is already registered,
+// so references back to it are not loaded again.
+func loadReferences(rootSchema *RootJsonSchema, loader SchemaLoader) error {
+	for _, reference := range rootSchema.refs() {
+		uri := resourceURI(ref(reference).schemaURIIn(strings.SplitN(reference, "#", 2)[0], rootSchema.id(), rootSchema.registries()))
+		if _, ok := rootSchema.registries().lookup(uri); ok || !hasScheme(uri) {
+			continue
+		}
+
+		bytes, err := loader.Load(uri)
+		if _, ok := err.(UnsupportedSchemeError); ok {
+			continue
+		}
+		if err != nil {
+			return errors.Wrap(err, "loading the schema of reference "+reference+" failed")
+		}
+
+		referencedSchema, err := newRootJsonSchema(rootSchema.registry, bytes, uri, "")
+		if err != nil {
+			return errors.Wrap(err, "compiling the schema of reference "+reference+" failed")
+		}
+
+		// A schema with an "$id" was registered under its "$id", and it is
+		// registered under the URI that it was loaded from too.
+		rootSchema.registry.registerIfAbsent(uri, referencedSchema)
+
+		err = loadReferences(referencedSchema, loader)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// refs returns the values of the "$ref" keywords of the sub-schemas of the
+// root schema. Strings under "$ref" keys in the values of keywords that are
+// not schemas, like "const" and "default", are not references.
+func (rs *RootJsonSchema) refs() []string {
+	var refs []string
+	for _, s := range rs.references {
+		if s.schema.Ref != nil {
+			refs = append(refs, string(*s.schema.Ref))
+		}
+	}
+
+	return refs
+}
@@ -0,0 +1,72 @@
+package jsonvalidator
+
+import "testing"
+
+func TestSchemaLoaderFunc(t *testing.T) {
+	var loaded []string
+	SetSchemaLoader(SchemaLoaderFunc(func(uri string) ([]byte, error) {
+		loaded = append(loaded, uri)
+		return []byte(`{"type": "integer"}`), nil
+	}))
+	defer SetSchemaLoader(nil)
+
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/loader/func.json",
+		"items": {"$ref": "integer.json"},
+		"default": [{"$ref": "default.json"}],
+		"examples": [{"$ref": "example.json"}],
+		"x-extension": {"$ref": "extension.json"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(loaded) != 1 || loaded[0] != "http://example.com/loader/integer.json" {
+		t.Errorf("loaded %v, expected only the relative reference of \"items\" resolved against the $id", loaded)
+	}
+
+	if err := NewValidator(rootSchema).Validate([]byte(`[1, "2"]`)); err == nil {
+		t.Error("the loaded schema was not applied")
+	}
+}
+
+func TestRegistrySchemaLoader(t *testing.T) {
+	var loaded []string
+	registry := NewRegistry()
+	registry.SetSchemaLoader(SchemaLoaderFunc(func(uri string) ([]byte, error) {
+		loaded = append(loaded, uri)
+		return []byte(`{"type": "integer"}`), nil
+	}))
+
+	// The loader of a registry is not used by other registries.
+	_, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/loader/default.json",
+		"items": {"$ref": "default-integer.json"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 0 {
+		t.Errorf("loaded %v with the loader of another registry", loaded)
+	}
+
+	rootSchema, err := registry.AddSchema("http://example.com/loader/registry/schema.json", []byte(`{
+		"items": {"$ref": "integer.json"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 1 || loaded[0] != "http://example.com/loader/registry/integer.json" {
+		t.Errorf("loaded %v, expected the reference of the registry's schema", loaded)
+	}
+	if _, ok := registry.Get("http://example.com/loader/registry/integer.json"); !ok {
+		t.Error("expected the loaded schema to be registered in the registry")
+	}
+	if _, ok := DefaultRegistry.Get("http://example.com/loader/registry/integer.json"); ok {
+		t.Error("expected the loaded schema not to be registered in the DefaultRegistry")
+	}
+
+	if err := NewValidator(rootSchema).Validate([]byte(`[1, "2"]`)); err == nil {
+		t.Error("the loaded schema was not applied")
+	}
+}
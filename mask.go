@@ -0,0 +1,175 @@
+package jsonvalidator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+)
+
+// SensitiveKeyword is the extension keyword that marks the values of a
+// schema as sensitive, so Mask() replaces them. Values of schemas with a
+// true "writeOnly" are sensitive too.
+const SensitiveKeyword = "x-sensitive"
+
+// Masker returns the replacement of a sensitive json value.
+type Masker func(value interface{}) interface{}
+
+// Redact is a Masker that replaces sensitive values with "***".
+func Redact(value interface{}) interface{} {
+	return "***"
+}
+
+// Hash is a Masker that replaces sensitive values with a prefix of the
+// sha256 hash of their json encoding, like "sha256:2c26b46b68ff", so equal
+// values can still be correlated in the logs without revealing them.
+func Hash(value interface{}) interface{} {
+	bytes, _ := json.Marshal(value)
+	sum := sha256.Sum256(bytes)
+	return "sha256:" + hex.EncodeToString(sum[:6])
+}
+
+// Mask returns a copy of the json document in bytes in which the values of
+// the schemas that are marked as sensitive (by SensitiveKeyword or by
+// "writeOnly") are replaced by masker, or by Redact if masker is nil, so the
+// document can be logged. Sub-schemas are looked up like in SchemaAt(), and
+// the sub-schemas of "allOf", "anyOf", "oneOf", "then" and "else" apply to
+// the values too, whether or not the values are valid against them, so a
+// value is masked if any of them marks it as sensitive.
+func (rs *RootJsonSchema) Mask(bytes []byte, masker Masker) ([]byte, error) {
+	if masker == nil {
+		masker = Redact
+	}
+
+	bytes, err := toUTF8(bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	err = json.Unmarshal(bytes, &value)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err = rs.JsonSchema.mask(value, rs.id(), rs.registries(), masker)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(value)
+}
+
+// mask is a recursive function that returns the value with its sensitive
+// parts replaced by masker, where references are resolved in the
+// registries.
+func (js *JsonSchema) mask(value interface{}, rootSchemaID string, registries registries, masker Masker) (interface{}, error) {
+	schemas, err := js.applicableSchemas(rootSchemaID, registries, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range schemas {
+		if s.schema.isSensitive() {
+			return masker(value), nil
+		}
+	}
+
+	// The children of the value are masked by the schemas that describe
+	// them, one schema after the other.
+	maskChild := func(token string, child interface{}) (interface{}, error) {
+		for _, s := range schemas {
+			schema, err := s.schema.childSchema(token)
+			if err != nil {
+				return nil, err
+			}
+
+			if schema != nil {
+				child, err = schema.mask(child, s.rootSchemaID, registries, masker)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		return child, nil
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key], err = maskChild(key, child)
+			if err != nil {
+				return nil, err
+			}
+		}
+	case []interface{}:
+		for index, child := range v {
+			v[index], err = maskChild(strconv.Itoa(index), child)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return value, nil
+}
+
+// isSensitive returns true if the values of the schema are sensitive.
+func (js *JsonSchema) isSensitive() bool {
+	if js.WriteOnly != nil && bool(*js.WriteOnly) {
+		return true
+	}
+
+	var sensitive bool
+	return json.Unmarshal(js.extensions[SensitiveKeyword], &sensitive) == nil && sensitive
+}
+
+// resolvedSchema is a schema after its references were followed, and the id
+// of the root schema that holds it.
+type resolvedSchema struct {
+	schema       *JsonSchema
+	rootSchemaID string
+}
+
+// applicableSchemas returns the schema and the sub-schemas of its "allOf",
+// "anyOf", "oneOf", "then" and "else" keywords (recursively), after their
+// references were followed in the registries. visited holds the schemas that
+// were already returned, so schemas that reference themselves are returned
+// once.
+func (js *JsonSchema) applicableSchemas(rootSchemaID string, registries registries, visited map[*JsonSchema]bool) ([]resolvedSchema, error) {
+	schema, rootSchemaID, err := js.followRefs(rootSchemaID, registries)
+	if err != nil {
+		return nil, err
+	}
+
+	if visited == nil {
+		visited = map[*JsonSchema]bool{}
+	}
+	if visited[schema] {
+		return nil, nil
+	}
+	visited[schema] = true
+
+	subSchemas := make([]*JsonSchema, 0, len(schema.AllOf)+len(schema.AnyOf)+len(schema.OneOf)+2)
+	subSchemas = append(subSchemas, schema.AllOf...)
+	subSchemas = append(subSchemas, schema.AnyOf...)
+	subSchemas = append(subSchemas, schema.OneOf...)
+	if schema.Then != nil {
+		subSchemas = append(subSchemas, &schema.Then.JsonSchema)
+	}
+	if schema.Else != nil {
+		subSchemas = append(subSchemas, &schema.Else.JsonSchema)
+	}
+
+	schemas := []resolvedSchema{{schema, rootSchemaID}}
+	for _, subSchema := range subSchemas {
+		applicable, err := subSchema.applicableSchemas(rootSchemaID, registries, visited)
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, applicable...)
+	}
+
+	return schemas, nil
+}
@@ -0,0 +1,64 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMask(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/mask.json",
+		"definitions": {"secret": {"type": "string", "x-sensitive": true}},
+		"properties": {
+			"name": {"type": "string"},
+			"password": {"type": "string", "writeOnly": true},
+			"token": {"$ref": "#/definitions/secret"},
+			"cards": {"items": {"properties": {"number": {"x-sensitive": true}}}},
+			"contact": {"allOf": [{"properties": {"phone": {"x-sensitive": true}}}]}
+		},
+		"additionalProperties": {"x-sensitive": false}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document := []byte(`{
+		"name": "Alice",
+		"password": "hunter2",
+		"token": "abc",
+		"cards": [{"number": 4111, "brand": "visa"}],
+		"contact": {"phone": "555-0100", "email": "alice@example.com"},
+		"note": "hello"
+	}`)
+
+	masked, err := rootSchema.Mask(document, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var actual, expected interface{}
+	json.Unmarshal(masked, &actual)
+	json.Unmarshal([]byte(`{
+		"name": "Alice",
+		"password": "***",
+		"token": "***",
+		"cards": [{"number": "***", "brand": "visa"}],
+		"contact": {"phone": "***", "email": "alice@example.com"},
+		"note": "hello"
+	}`), &expected)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %s, got %s", expected, masked)
+	}
+
+	hashed, err := rootSchema.Mask([]byte(`{"password": "hunter2", "token": "hunter2"}`), Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var values map[string]string
+	json.Unmarshal(hashed, &values)
+	if values["password"] != values["token"] || values["password"] == "hunter2" || len(values["password"]) != len("sha256:")+12 {
+		t.Errorf("expected equal values to have equal hashes, got %s", hashed)
+	}
+}
@@ -0,0 +1,301 @@
+package mongoschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/internal/schemautil"
+	"github.com/itayankri/gojsonvalidator/internal/strutil"
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+// UnsupportedKeywordError is returned when a $jsonSchema document holds a
+// keyword that MongoDB does not accept, or a keyword value that it does not
+// accept, such as an unknown bson type. Location is the json pointer of the
+// keyword in the document.
+type UnsupportedKeywordError struct {
+	Location string
+	Keyword  string
+	reason   string
+}
+
+func (e UnsupportedKeywordError) Error() string {
+	return fmt.Sprintf("unsupported keyword %q at %s: %s", e.Keyword, e.Location, e.reason)
+}
+
+// The json types of the bson types that have a plain json representation.
+var jsonTypes = map[string]string{
+	"object": "object",
+	"array":  "array",
+	"string": "string",
+	"int":    "integer",
+	"long":   "integer",
+	"double": "number",
+	"bool":   "boolean",
+	"null":   "null",
+}
+
+// The keys of the objects that represent the other bson types in MongoDB
+// Extended JSON.
+var extendedJsonKeys = map[string]string{
+	"objectId":            "$oid",
+	"date":                "$date",
+	"decimal":             "$numberDecimal",
+	"binData":             "$binary",
+	"timestamp":           "$timestamp",
+	"regex":               "$regularExpression",
+	"javascript":          "$code",
+	"javascriptWithScope": "$code",
+	"symbol":              "$symbol",
+	"dbPointer":           "$dbPointer",
+	"undefined":           "$undefined",
+	"minKey":              "$minKey",
+	"maxKey":              "$maxKey",
+}
+
+// The schemas of the Extended JSON keys whose value is a string.
+var extendedJsonValues = map[string]interface{}{
+	"$oid":           map[string]interface{}{"type": "string", "pattern": "^[0-9a-fA-F]{24}$"},
+	"$numberDecimal": map[string]interface{}{"type": "string"},
+	"$symbol":        map[string]interface{}{"type": "string"},
+}
+
+// ToJsonSchema converts a $jsonSchema document to a draft 7 json schema. The
+// document may also be a collection validator of the form
+// {"$jsonSchema": <document>}.
+//
+// Values of bson types without a json equivalent (like objectId and date)
+// are expected in their relaxed MongoDB Extended JSON representation, for
+// example {"$oid": "5f1d7a2e9d3b2a0012345678"}, and a "number" is an int,
+// a long, a double or a decimal. Draft 4 exclusive bounds are converted to
+// the numeric exclusive bounds of draft 7. Keywords that MongoDB does not
+// support, like "$ref" and "format", are rejected with an
+// UnsupportedKeywordError, so the schema enforces what the database does.
+func ToJsonSchema(document []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+
+	location := ""
+	if validator, ok := value.(map[string]interface{}); ok {
+		if schema, ok := validator["$jsonSchema"]; ok && len(validator) == 1 {
+			value, location = schema, "/$jsonSchema"
+		}
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the $jsonSchema document is not an object")
+	}
+
+	schema, err := importSchema(object, location)
+	if err != nil {
+		return nil, err
+	}
+	schema["$schema"] = draft07
+
+	return json.Marshal(schema)
+}
+
+// Import converts a $jsonSchema document to a root schema, as ToJsonSchema()
+// describes.
+func Import(document []byte) (*jsonvalidator.RootJsonSchema, error) {
+	schema, err := ToJsonSchema(document)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonvalidator.NewRootJsonSchema(schema)
+}
+
+const draft07 = "http://json-schema.org/draft-07/schema#"
+
+// importSchema returns the draft 7 form of a $jsonSchema schema.
+func importSchema(value interface{}, location string) (map[string]interface{}, error) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the schema at %q is not an object", location)
+	}
+
+	schema := map[string]interface{}{}
+	for keyword, keywordValue := range object {
+		keywordLocation := location + "/" + jsonwalker.EscapeToken(keyword)
+		unsupported := func(reason string) error {
+			return UnsupportedKeywordError{Location: keywordLocation, Keyword: keyword, reason: reason}
+		}
+
+		switch {
+		case keyword == "bsonType":
+			if _, ok := object["type"]; ok {
+				return nil, unsupported("\"bsonType\" and \"type\" cannot be used together")
+			}
+
+			types, err := importBsonType(keywordValue)
+			if err != nil {
+				return nil, unsupported(err.Error())
+			}
+
+			if typeList, ok := types["type"]; ok && len(types) == 1 {
+				schema["type"] = typeList
+			} else {
+				allOf, _ := schema["allOf"].([]interface{})
+				schema["allOf"] = append(allOf, types)
+			}
+		case keyword == "type":
+			for _, jsonType := range schemautil.StringList(keywordValue) {
+				if jsonType == "integer" {
+					return nil, unsupported("use the \"int\" or \"long\" bson types instead of \"integer\"")
+				}
+			}
+			schema[keyword] = keywordValue
+		case keyword == "minimum" || keyword == "maximum":
+			exclusive := "exclusiveMinimum"
+			if keyword == "maximum" {
+				exclusive = "exclusiveMaximum"
+			}
+
+			if isExclusive, _ := object[exclusive].(bool); isExclusive {
+				schema[exclusive] = keywordValue
+			} else {
+				schema[keyword] = keywordValue
+			}
+		case keyword == "exclusiveMinimum" || keyword == "exclusiveMaximum":
+			if _, ok := keywordValue.(bool); !ok {
+				return nil, unsupported("the exclusive bounds of $jsonSchema are flags of \"minimum\" and \"maximum\"")
+			}
+		case keyword == "items":
+			if list, ok := keywordValue.([]interface{}); ok {
+				items, err := importList(list, keywordLocation)
+				if err != nil {
+					return nil, err
+				}
+				schema[keyword] = items
+				break
+			}
+			fallthrough
+		case strutil.Contains(schemaKeywords, keyword) || keyword == "additionalProperties":
+			if flag, ok := keywordValue.(bool); ok && keyword != "not" {
+				schema[keyword] = flag
+				break
+			}
+
+			subSchema, err := importSchema(keywordValue, keywordLocation)
+			if err != nil {
+				return nil, err
+			}
+			schema[keyword] = subSchema
+		case strutil.Contains(schemaListKeyword, keyword):
+			list, _ := keywordValue.([]interface{})
+			subSchemas, err := importList(list, keywordLocation)
+			if err != nil {
+				return nil, err
+			}
+
+			if keyword == "allOf" {
+				allOf, _ := schema["allOf"].([]interface{})
+				schema["allOf"] = append(subSchemas, allOf...)
+			} else {
+				schema[keyword] = subSchemas
+			}
+		case strutil.Contains(schemaMapKeywords, keyword):
+			subSchemas, _ := keywordValue.(map[string]interface{})
+			converted := make(map[string]interface{}, len(subSchemas))
+			for name, subSchema := range subSchemas {
+				var err error
+				converted[name], err = importSchema(subSchema, keywordLocation+"/"+jsonwalker.EscapeToken(name))
+				if err != nil {
+					return nil, err
+				}
+			}
+			schema[keyword] = converted
+		case keyword == "dependencies":
+			dependencies, _ := keywordValue.(map[string]interface{})
+			converted := make(map[string]interface{}, len(dependencies))
+			for name, dependency := range dependencies {
+				if properties, ok := dependency.([]interface{}); ok {
+					converted[name] = properties
+					continue
+				}
+
+				var err error
+				converted[name], err = importSchema(dependency, keywordLocation+"/"+jsonwalker.EscapeToken(name))
+				if err != nil {
+					return nil, err
+				}
+			}
+			schema[keyword] = converted
+		case strutil.Contains(plainKeywords, keyword):
+			schema[keyword] = keywordValue
+		default:
+			return nil, unsupported("MongoDB does not support the keyword")
+		}
+	}
+
+	return schema, nil
+}
+
+func importList(list []interface{}, location string) ([]interface{}, error) {
+	converted := make([]interface{}, len(list))
+	for index, subSchema := range list {
+		var err error
+		converted[index], err = importSchema(subSchema, location+"/"+strconv.Itoa(index))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return converted, nil
+}
+
+// importBsonType returns a schema that accepts the json representations of
+// the given bson types.
+func importBsonType(value interface{}) (map[string]interface{}, error) {
+	var types []interface{}
+	var alternatives []interface{}
+	for _, bsonType := range schemautil.StringList(value) {
+		if bsonType == "number" {
+			types = append(types, "number")
+			bsonType = "decimal"
+		}
+
+		if jsonType, ok := jsonTypes[bsonType]; ok {
+			types = append(types, jsonType)
+			continue
+		}
+
+		key, ok := extendedJsonKeys[bsonType]
+		if !ok {
+			return nil, fmt.Errorf("unknown bson type %q", bsonType)
+		}
+
+		keySchema, ok := extendedJsonValues[key]
+		if !ok {
+			keySchema = map[string]interface{}{}
+		}
+		alternatives = append(alternatives, map[string]interface{}{
+			"type":       "object",
+			"required":   []interface{}{key},
+			"properties": map[string]interface{}{key: keySchema},
+		})
+	}
+
+	if len(types) > 0 {
+		alternatives = append(alternatives, map[string]interface{}{"type": types})
+	}
+	if len(alternatives) == 0 {
+		return nil, fmt.Errorf("\"bsonType\" must be a string or an array of strings")
+	}
+	if len(alternatives) == 1 {
+		return alternatives[0].(map[string]interface{}), nil
+	}
+
+	return map[string]interface{}{"anyOf": alternatives}, nil
+}
@@ -0,0 +1,84 @@
+package mongoschema_test
+
+import (
+	"testing"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/mongoschema"
+)
+
+const userValidator = `{"$jsonSchema": {
+	"bsonType": "object",
+	"required": ["_id", "name", "age"],
+	"additionalProperties": false,
+	"properties": {
+		"_id": {"bsonType": "objectId"},
+		"name": {"bsonType": "string", "minLength": 1},
+		"age": {"bsonType": ["int", "long"], "minimum": 0, "maximum": 150, "exclusiveMaximum": true},
+		"score": {"bsonType": ["number", "null"], "minimum": 5, "exclusiveMinimum": true},
+		"created": {"bsonType": "date"},
+		"tags": {"bsonType": "array", "items": {"type": "string"}},
+		"role": {"enum": ["admin", "user"]}
+	}
+}}`
+
+func TestImport(t *testing.T) {
+	rootSchema, err := mongoschema.Import([]byte(userValidator))
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator := jsonvalidator.NewValidator(rootSchema)
+
+	tests := []struct {
+		json  string
+		valid bool
+	}{
+		{`{"_id": {"$oid": "5f1d7a2e9d3b2a0012345678"}, "name": "a", "age": 30}`, true},
+		{`{"_id": {"$oid": "5f1d7a2e9d3b2a0012345678"}, "name": "a", "age": 0, "score": 5.5, "role": "user"}`, true},
+		{`{"_id": {"$oid": "5f1d7a2e9d3b2a0012345678"}, "name": "a", "age": 1, "score": {"$numberDecimal": "7.25"}}`, true},
+		{`{"_id": {"$oid": "5f1d7a2e9d3b2a0012345678"}, "name": "a", "age": 1, "created": {"$date": "2024-01-02T03:04:05Z"}}`, true},
+		{`{"_id": {"$oid": "5f1d7a2e9d3b2a0012345678"}, "name": "a", "age": 1, "tags": ["x"]}`, true},
+		{`{"_id": "5f1d7a2e9d3b2a0012345678", "name": "a", "age": 1}`, false},
+		{`{"_id": {"$oid": "xyz"}, "name": "a", "age": 1}`, false},
+		{`{"name": "a", "age": 1}`, false},
+		{`{"_id": {"$oid": "5f1d7a2e9d3b2a0012345678"}, "name": "a", "age": 150}`, false},
+		{`{"_id": {"$oid": "5f1d7a2e9d3b2a0012345678"}, "name": "a", "age": 1.5}`, false},
+		{`{"_id": {"$oid": "5f1d7a2e9d3b2a0012345678"}, "name": "a", "age": 1, "score": 5}`, false},
+		{`{"_id": {"$oid": "5f1d7a2e9d3b2a0012345678"}, "name": "a", "age": 1, "created": "2024-01-02"}`, false},
+		{`{"_id": {"$oid": "5f1d7a2e9d3b2a0012345678"}, "name": "a", "age": 1, "tags": [1]}`, false},
+		{`{"_id": {"$oid": "5f1d7a2e9d3b2a0012345678"}, "name": "a", "age": 1, "nickname": "b"}`, false},
+	}
+
+	for _, test := range tests {
+		err := validator.Validate([]byte(test.json))
+		if test.valid && err != nil {
+			t.Errorf("%s: unexpected error %v", test.json, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%s: expected an error", test.json)
+		}
+	}
+}
+
+func TestImportUnsupported(t *testing.T) {
+	tests := []struct {
+		document string
+		location string
+	}{
+		{`{"properties": {"a": {"$ref": "#/definitions/a"}}}`, "/properties/a/$ref"},
+		{`{"$jsonSchema": {"properties": {"a": {"type": "string", "format": "email"}}}}`, "/$jsonSchema/properties/a/format"},
+		{`{"type": "integer"}`, "/type"},
+		{`{"items": [{"bsonType": "uuid"}]}`, "/items/0/bsonType"},
+		{`{"bsonType": "object", "type": "object"}`, "/bsonType"},
+		{`{"minimum": 1, "exclusiveMinimum": 1}`, "/exclusiveMinimum"},
+	}
+
+	for _, test := range tests {
+		_, err := mongoschema.ToJsonSchema([]byte(test.document))
+		unsupported, ok := err.(mongoschema.UnsupportedKeywordError)
+		if !ok {
+			t.Errorf("%s: expected an UnsupportedKeywordError, got %v", test.document, err)
+		} else if unsupported.Location != test.location {
+			t.Errorf("%s: expected the location %s, got %s", test.document, test.location, unsupported.Location)
+		}
+	}
+}
@@ -0,0 +1,390 @@
+// Package mongoschema converts json schemas to the $jsonSchema dialect of
+// MongoDB collection validators, so the validators can be generated from
+// the same schemas that validate the documents elsewhere.
+//
+// MongoDB implements a subset of json schema draft 4 with the "bsonType"
+// keyword. The conversion maps json types to bson types, inlines "$ref"
+// references, rewrites the keywords of later drafts that have a draft 4
+// equivalent and drops the keywords that MongoDB does not support, reporting
+// every dropped keyword. Import does the inverse, so documents can be
+// validated before they are written with the rules that the database
+// enforces.
+package mongoschema
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/internal/schemautil"
+	"github.com/itayankri/gojsonvalidator/internal/strutil"
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+// DroppedKeyword describes a keyword that has no equivalent in $jsonSchema
+// and was left out of the converted schema. Location is the json pointer of
+// the keyword in the source schema, following the "$ref" references that
+// were inlined.
+type DroppedKeyword struct {
+	Location string
+	Keyword  string
+	Reason   string
+}
+
+// The bson types of the json types. Integers may be stored as 32-bit or
+// 64-bit integers, and "number" stands for all the numeric bson types.
+var bsonTypes = map[string][]string{
+	"object":  {"object"},
+	"array":   {"array"},
+	"string":  {"string"},
+	"integer": {"int", "long"},
+	"number":  {"number"},
+	"boolean": {"bool"},
+	"null":    {"null"},
+}
+
+// The keywords that are copied without changes, where the value of the
+// keywords in the first list is a schema, in the second list an array of
+// schemas and in the third list a map of schemas.
+var (
+	schemaKeywords    = []string{"not", "additionalItems"}
+	schemaListKeyword = []string{"allOf", "anyOf", "oneOf"}
+	schemaMapKeywords = []string{"properties", "patternProperties"}
+	plainKeywords     = []string{
+		"title", "description", "enum", "multipleOf", "maxLength", "minLength",
+		"pattern", "maxItems", "minItems", "uniqueItems", "maxProperties",
+		"minProperties", "required",
+	}
+)
+
+// The reasons for dropping the keywords that MongoDB does not support.
+var droppedKeywords = map[string]string{
+	"$schema":          "MongoDB does not support the $schema keyword",
+	"$id":              "MongoDB does not support schema identifiers",
+	"$comment":         "MongoDB does not support comments",
+	"definitions":      "the referenced definitions are inlined",
+	"default":          "MongoDB does not support default values",
+	"examples":         "MongoDB does not support examples",
+	"format":           "MongoDB does not support formats",
+	"contains":         "MongoDB does not support the contains keyword",
+	"propertyNames":    "MongoDB does not support the propertyNames keyword",
+	"readOnly":         "MongoDB does not support the readOnly keyword",
+	"writeOnly":        "MongoDB does not support the writeOnly keyword",
+	"contentMediaType": "MongoDB does not support content keywords",
+	"contentEncoding":  "MongoDB does not support content keywords",
+	"contentSchema":    "MongoDB does not support content keywords",
+}
+
+// Export converts a root schema to a $jsonSchema document, which is used as
+// the validator of a collection in the form {"$jsonSchema": <document>}.
+// It returns the keywords that were dropped, in the order of their location.
+func Export(schema *jsonvalidator.RootJsonSchema) ([]byte, []DroppedKeyword, error) {
+	root, err := schemautil.Value(&schema.JsonSchema)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The root schema is being inlined while it is converted.
+	exporter := &exporter{
+		schema:     schema,
+		references: schemautil.References{"#": true},
+		dropped:    map[string]DroppedKeyword{},
+	}
+	converted, err := exporter.convert(root, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bytes, err := json.Marshal(converted)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dropped := make([]DroppedKeyword, 0, len(exporter.dropped))
+	for _, keyword := range exporter.dropped {
+		dropped = append(dropped, keyword)
+	}
+	sort.Slice(dropped, func(i, j int) bool {
+		return dropped[i].Location < dropped[j].Location
+	})
+
+	return bytes, dropped, nil
+}
+
+type exporter struct {
+	schema *jsonvalidator.RootJsonSchema
+
+	// The dropped keywords by their location, so the keywords of a schema
+	// that is referenced more than once are reported once.
+	dropped map[string]DroppedKeyword
+
+	// The references that are being inlined, which cannot be inlined again
+	// inside themselves.
+	references schemautil.References
+}
+
+func (e *exporter) drop(location string, keyword string, reason string) {
+	location += "/" + jsonwalker.EscapeToken(keyword)
+	e.dropped[location] = DroppedKeyword{
+		Location: location,
+		Keyword:  keyword,
+		Reason:   reason,
+	}
+}
+
+// convert returns the $jsonSchema form of a schema.
+func (e *exporter) convert(schema interface{}, location string) (map[string]interface{}, error) {
+	object, ok := schema.(map[string]interface{})
+	if !ok {
+		// A boolean schema.
+		if schema == false {
+			return map[string]interface{}{"not": map[string]interface{}{}}, nil
+		}
+
+		return map[string]interface{}{}, nil
+	}
+
+	if rejectAll, _ := object["rejectAll"].(bool); rejectAll {
+		return map[string]interface{}{"not": map[string]interface{}{}}, nil
+	}
+
+	// "$ref" overrides the other keywords of the schema.
+	if reference, ok := object["$ref"].(string); ok {
+		return e.inline(reference, location)
+	}
+
+	converted := map[string]interface{}{}
+	for keyword, value := range object {
+		if reason, ok := droppedKeywords[keyword]; ok {
+			e.drop(location, keyword, reason)
+			continue
+		}
+
+		keywordLocation := location + "/" + jsonwalker.EscapeToken(keyword)
+		switch {
+		case keyword == "type":
+			converted["bsonType"] = convertType(value)
+		case keyword == "const":
+			converted["enum"] = []interface{}{value}
+		case keyword == "minimum" || keyword == "maximum":
+			// The exclusive bounds are merged below.
+			if _, ok := converted[keyword]; !ok {
+				converted[keyword] = value
+			}
+		case keyword == "exclusiveMinimum" || keyword == "exclusiveMaximum":
+			// Later drafts give the exclusive bound as a number, and draft 4
+			// as a flag of "minimum" and "maximum".
+			bound := "minimum"
+			if keyword == "exclusiveMaximum" {
+				bound = "maximum"
+			}
+			if schemautil.IsStricter(value, object[bound], bound == "minimum") {
+				converted[bound] = value
+				converted[keyword] = true
+			} else {
+				converted[bound] = object[bound]
+			}
+		case keyword == "items":
+			items, err := e.convertItems(value, keywordLocation)
+			if err != nil {
+				return nil, err
+			}
+			converted[keyword] = items
+		case keyword == "additionalProperties":
+			// A false schema is written as a rejectAll flag.
+			if subSchema, _ := value.(map[string]interface{}); subSchema["rejectAll"] == true {
+				converted[keyword] = false
+				break
+			}
+			fallthrough
+		case strutil.Contains(schemaKeywords, keyword):
+			subSchema, err := e.convert(value, keywordLocation)
+			if err != nil {
+				return nil, err
+			}
+			converted[keyword] = subSchema
+		case strutil.Contains(schemaListKeyword, keyword):
+			subSchemas, err := e.convertList(value, keywordLocation)
+			if err != nil {
+				return nil, err
+			}
+			converted[keyword] = subSchemas
+		case strutil.Contains(schemaMapKeywords, keyword):
+			subSchemas, err := e.convertMap(value, keywordLocation)
+			if err != nil {
+				return nil, err
+			}
+			converted[keyword] = subSchemas
+		case keyword == "dependencies":
+			dependencies, err := e.convertDependencies(value, keywordLocation)
+			if err != nil {
+				return nil, err
+			}
+			converted[keyword] = dependencies
+		case keyword == "if" || keyword == "then" || keyword == "else":
+			// Converted below, after "allOf".
+		case strutil.Contains(plainKeywords, keyword):
+			converted[keyword] = value
+		default:
+			e.drop(location, keyword, "the keyword is not a json schema keyword")
+		}
+	}
+
+	if _, ok := object["if"]; ok {
+		condition, err := e.convertCondition(object, location)
+		if err != nil {
+			return nil, err
+		}
+
+		allOf, _ := converted["allOf"].([]interface{})
+		converted["allOf"] = append(allOf, condition)
+	} else {
+		for _, keyword := range []string{"then", "else"} {
+			if _, ok := object[keyword]; ok {
+				e.drop(location, keyword, "the keyword has no effect without \"if\"")
+			}
+		}
+	}
+
+	return converted, nil
+}
+
+// inline returns the converted schema that a reference points to.
+func (e *exporter) inline(reference string, location string) (map[string]interface{}, error) {
+	converted, recursive, err := e.references.Inline(e.schema, reference, location, e.convert)
+	if recursive {
+		e.drop(location, "$ref", "MongoDB does not support recursive schemas")
+		return map[string]interface{}{}, nil
+	}
+
+	return converted, err
+}
+
+func (e *exporter) convertItems(value interface{}, location string) (interface{}, error) {
+	if _, ok := value.([]interface{}); ok {
+		return e.convertList(value, location)
+	}
+
+	return e.convert(value, location)
+}
+
+func (e *exporter) convertList(value interface{}, location string) ([]interface{}, error) {
+	list, _ := value.([]interface{})
+	converted := make([]interface{}, len(list))
+	for index, subSchema := range list {
+		var err error
+		converted[index], err = e.convert(subSchema, location+"/"+strconv.Itoa(index))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return converted, nil
+}
+
+func (e *exporter) convertMap(value interface{}, location string) (map[string]interface{}, error) {
+	subSchemas, _ := value.(map[string]interface{})
+	converted := make(map[string]interface{}, len(subSchemas))
+	for name, subSchema := range subSchemas {
+		var err error
+		converted[name], err = e.convert(subSchema, location+"/"+jsonwalker.EscapeToken(name))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return converted, nil
+}
+
+// convertDependencies converts the schema dependencies, and keeps the
+// property dependencies.
+func (e *exporter) convertDependencies(value interface{}, location string) (map[string]interface{}, error) {
+	dependencies, _ := value.(map[string]interface{})
+	converted := make(map[string]interface{}, len(dependencies))
+	for name, dependency := range dependencies {
+		if properties, ok := dependency.([]interface{}); ok {
+			converted[name] = properties
+			continue
+		}
+
+		var err error
+		converted[name], err = e.convert(dependency, location+"/"+jsonwalker.EscapeToken(name))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return converted, nil
+}
+
+// convertCondition returns the draft 4 equivalent of "if", "then" and
+// "else": either the condition and "then" hold, or the condition does not
+// hold and "else" does.
+func (e *exporter) convertCondition(object map[string]interface{}, location string) (map[string]interface{}, error) {
+	condition, err := e.convert(object["if"], location+"/if")
+	if err != nil {
+		return nil, err
+	}
+
+	whenTrue := []interface{}{condition}
+	if then, ok := object["then"]; ok {
+		converted, err := e.convert(then, location+"/then")
+		if err != nil {
+			return nil, err
+		}
+		whenTrue = append(whenTrue, converted)
+	}
+
+	whenFalse := []interface{}{map[string]interface{}{"not": condition}}
+	if otherwise, ok := object["else"]; ok {
+		converted, err := e.convert(otherwise, location+"/else")
+		if err != nil {
+			return nil, err
+		}
+		whenFalse = append(whenFalse, converted)
+	}
+
+	return map[string]interface{}{
+		"anyOf": []interface{}{
+			map[string]interface{}{"allOf": whenTrue},
+			map[string]interface{}{"allOf": whenFalse},
+		},
+	}, nil
+}
+
+// convertType returns the "bsonType" of a "type" keyword.
+func convertType(value interface{}) interface{} {
+	var types []string
+	switch v := value.(type) {
+	case string:
+		types = append(types, bsonTypes[v]...)
+	case []interface{}:
+		for _, jsonType := range v {
+			if str, ok := jsonType.(string); ok {
+				types = append(types, bsonTypes[str]...)
+			}
+		}
+	}
+
+	// "number" covers the integer types.
+	if strutil.Contains(types, "number") {
+		filtered := types[:0]
+		for _, bsonType := range types {
+			if bsonType != "int" && bsonType != "long" {
+				filtered = append(filtered, bsonType)
+			}
+		}
+		types = filtered
+	}
+
+	if len(types) == 1 {
+		return types[0]
+	}
+
+	list := make([]interface{}, len(types))
+	for index, bsonType := range types {
+		list[index] = bsonType
+	}
+	return list
+}
@@ -0,0 +1,98 @@
+package mongoschema_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/mongoschema"
+)
+
+const userSchema = `{
+	"$id": "http://example.com/mongoschema/user.json",
+	"type": "object",
+	"required": ["name", "age"],
+	"additionalProperties": false,
+	"properties": {
+		"name": {"type": "string", "minLength": 1, "default": "anonymous"},
+		"age": {"type": "integer", "minimum": 0, "exclusiveMaximum": 150},
+		"score": {"type": ["number", "integer", "null"], "exclusiveMinimum": 5, "minimum": 0},
+		"email": {"type": "string", "format": "email"},
+		"role": {"const": "admin"},
+		"address": {"$ref": "#/definitions/address"},
+		"tags": {"type": "array", "items": {"type": "string"}, "contains": {"const": "x"}},
+		"kind": {"type": "string"},
+		"manager": {"$ref": "#"}
+	},
+	"if": {"properties": {"kind": {"const": "company"}}},
+	"then": {"required": ["address"]},
+	"definitions": {
+		"address": {
+			"type": "object",
+			"properties": {"city": {"type": "string", "examples": ["Haifa"]}},
+			"additionalProperties": {"type": "string"}
+		}
+	}
+}`
+
+func TestExport(t *testing.T) {
+	rootSchema, err := jsonvalidator.NewRootJsonSchema([]byte(userSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bytes, dropped, err := mongoschema.Export(rootSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var actual, expected interface{}
+	json.Unmarshal(bytes, &actual)
+	json.Unmarshal([]byte(`{
+		"bsonType": "object",
+		"required": ["name", "age"],
+		"additionalProperties": false,
+		"properties": {
+			"name": {"bsonType": "string", "minLength": 1},
+			"age": {"bsonType": ["int", "long"], "minimum": 0, "maximum": 150, "exclusiveMaximum": true},
+			"score": {"bsonType": ["number", "null"], "minimum": 5, "exclusiveMinimum": true},
+			"email": {"bsonType": "string"},
+			"role": {"enum": ["admin"]},
+			"address": {
+				"bsonType": "object",
+				"properties": {"city": {"bsonType": "string"}},
+				"additionalProperties": {"bsonType": "string"}
+			},
+			"tags": {"bsonType": "array", "items": {"bsonType": "string"}},
+			"kind": {"bsonType": "string"},
+			"manager": {}
+		},
+		"allOf": [{
+			"anyOf": [
+				{"allOf": [{"properties": {"kind": {"enum": ["company"]}}}, {"required": ["address"]}]},
+				{"allOf": [{"not": {"properties": {"kind": {"enum": ["company"]}}}}]}
+			]
+		}]
+	}`), &expected)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected $jsonSchema %s", bytes)
+	}
+
+	expectedDropped := []string{
+		"/$id",
+		"/definitions",
+		"/definitions/address/properties/city/examples",
+		"/properties/email/format",
+		"/properties/manager/$ref",
+		"/properties/name/default",
+		"/properties/tags/contains",
+	}
+	locations := make([]string, len(dropped))
+	for index, keyword := range dropped {
+		locations[index] = keyword.Location
+	}
+	if !reflect.DeepEqual(locations, expectedDropped) {
+		t.Errorf("unexpected dropped keywords %+v", dropped)
+	}
+}
@@ -0,0 +1,107 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// numberValue returns the float64 value of a decoded json number, which is
+// either a float64 or (in strict numeric mode) a json.Number.
+// Numbers that overflow float64 are returned as +Inf or -Inf.
+func numberValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		{
+			return v, true
+		}
+	case json.Number:
+		{
+			// ParseFloat returns +/-Inf along with a range error for numbers
+			// that overflow float64, which still compare correctly.
+			f, err := strconv.ParseFloat(string(v), 64)
+			if err != nil && !math.IsInf(f, 0) {
+				return 0, false
+			}
+
+			return f, true
+		}
+	default:
+		{
+			return 0, false
+		}
+	}
+}
+
+// isInteger returns true if a decoded json value is a number with a zero
+// fractional part, regardless of its magnitude.
+func isInteger(value interface{}) bool {
+	switch v := value.(type) {
+	case float64:
+		{
+			return !math.IsInf(v, 0) && v == math.Trunc(v)
+		}
+	case json.Number:
+		{
+			// big.Float has a practically unlimited exponent range, so numbers
+			// like 1e309 are parsed exactly enough to check their fraction.
+			f, _, err := big.ParseFloat(string(v), 10, 1024, big.ToNearestEven)
+			if err != nil {
+				return false
+			}
+
+			return f.IsInt()
+		}
+	default:
+		{
+			return false
+		}
+	}
+}
+
+// canonicalizeNumbers replaces the json.Number values in a json value that
+// was decoded in strict numeric mode with float64 values, as long as the
+// conversion is exact. Numbers that cannot be represented exactly as float64
+// (because of their magnitude or precision) are kept as json.Number, so their
+// original representation is preserved.
+// This way, equal numbers such as 1 and 1.0 are encoded the same way when
+// values are compared by keywords like "enum", "const" and "uniqueItems".
+func canonicalizeNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		{
+			f, err := strconv.ParseFloat(string(v), 64)
+			if err != nil {
+				return v
+			}
+
+			exact, _, err := big.ParseFloat(string(v), 10, 1024, big.ToNearestEven)
+			if err != nil || exact.Cmp(big.NewFloat(f)) != 0 {
+				return v
+			}
+
+			return f
+		}
+	case map[string]interface{}:
+		{
+			for key, item := range v {
+				v[key] = canonicalizeNumbers(item)
+			}
+
+			return v
+		}
+	case []interface{}:
+		{
+			for index, item := range v {
+				v[index] = canonicalizeNumbers(item)
+			}
+
+			return v
+		}
+	default:
+		{
+			return v
+		}
+	}
+}
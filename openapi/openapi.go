@@ -0,0 +1,317 @@
+// Package openapi validates http requests and responses against the
+// operations of an OpenAPI 3.0 document.
+//
+// The document (json or yaml) is registered as a resource under the URI
+// that it is loaded with, in the registry that it is loaded in, so the
+// "$ref" references of its schemas (for
+// example "#/components/schemas/Pet") are resolved like the references of
+// any other schema, and its component schemas are compiled when it is
+// loaded. The schemas are converted from the OpenAPI dialect to draft 7:
+// "nullable" adds "null" to the "type" of a schema, and the boolean
+// "exclusiveMinimum" and "exclusiveMaximum" flags become numeric bounds.
+//
+// A request is matched to an operation by its method and path, and its
+// path, query, header and cookie parameters and its body are validated
+// against the schemas of the operation. The parameters are converted from
+// strings according to the types of their schemas, like formval does.
+// Bodies of json media types are validated against their schema, form
+// bodies are validated like parameters, and bodies of other media types are
+// only checked to have a documented content type.
+//
+// Paths are matched without the base paths of the "servers" of the
+// document, and references to other documents are not supported.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/internal/strutil"
+	"github.com/itayankri/gojsonvalidator/internal/yamlutil"
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrOperationNotFound is returned when no path of the document matches
+	// the path of a request.
+	ErrOperationNotFound = errors.New("no operation matches the path")
+
+	// ErrMethodNotAllowed is returned when a path of the document matches
+	// the path of a request, but it has no operation for its method.
+	ErrMethodNotAllowed = errors.New("the path has no operation for the method")
+
+	// ErrUnsupportedContentType is returned when the content type of a body
+	// is not one of the media types of the operation.
+	ErrUnsupportedContentType = errors.New("the content type is not supported by the operation")
+
+	// ErrMissingBody is returned when a request has no body, but the request
+	// body of the operation is required.
+	ErrMissingBody = errors.New("the request body is required")
+
+	// ErrUndocumentedStatus is returned when an operation has no response
+	// for the status of a response, and no default response.
+	ErrUndocumentedStatus = errors.New("the status is not documented by the operation")
+)
+
+// ValidationError is returned when a part of a request or a response does
+// not match its schema. In is "path", "query", "header", "cookie" or
+// "body", and Err is the error of the validator.
+type ValidationError struct {
+	In  string
+	Err error
+}
+
+func (e ValidationError) Error() string {
+	return "invalid " + e.In + ": " + e.Err.Error()
+}
+
+func (e ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Document is a loaded OpenAPI document.
+type Document struct {
+	registry   *jsonvalidator.Registry
+	uri        string
+	operations []*Operation
+	components map[string]*jsonvalidator.RootJsonSchema
+}
+
+// The http methods that the operations of a path item are keyed by.
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Load loads an OpenAPI 3.0 document in json or yaml, and registers it under
+// the given URI in the registry, where its schemas are compiled. A nil
+// registry is replaced by a new registry of the document's own, so the
+// schemas of different documents (like the documents of different tenants)
+// do not see each other.
+func Load(registry *jsonvalidator.Registry, uri string, document []byte) (*Document, error) {
+	if registry == nil {
+		registry = jsonvalidator.NewRegistry()
+	}
+
+	value, err := decode(document)
+	if err != nil {
+		return nil, err
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("the OpenAPI document is not an object")
+	}
+	if version, _ := object["openapi"].(string); !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q", version)
+	}
+
+	convertSchemas(object)
+	converted, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := registry.AddSchema(uri, converted)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &loader{
+		document: &Document{registry: registry, uri: uri, components: map[string]*jsonvalidator.RootJsonSchema{}},
+		root:     root,
+		bytes:    converted,
+	}
+
+	components, _ := object["components"].(map[string]interface{})
+	schemas, _ := components["schemas"].(map[string]interface{})
+	for name := range schemas {
+		l.document.components[name], err = l.schema("/components/schemas/" + jsonwalker.EscapeToken(name))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	paths, _ := object["paths"].(map[string]interface{})
+	for path, pathItem := range paths {
+		err = l.loadPath(path, pathItem)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The operations are sorted by their path and method, so paths are
+	// matched in the same order on every run.
+	sort.Slice(l.document.operations, func(i, j int) bool {
+		first, second := l.document.operations[i], l.document.operations[j]
+		if first.Path != second.Path {
+			return first.Path < second.Path
+		}
+		return first.Method < second.Method
+	})
+
+	return l.document, nil
+}
+
+// ComponentSchema returns the compiled schema of the component schema with
+// the given name, or nil if there is none.
+func (d *Document) ComponentSchema(name string) *jsonvalidator.RootJsonSchema {
+	return d.components[name]
+}
+
+// Operations returns the operations of the document, sorted by their path
+// and method.
+func (d *Document) Operations() []*Operation {
+	return append([]*Operation(nil), d.operations...)
+}
+
+// Operation returns the operation with the given operationId, or nil if
+// there is none.
+func (d *Document) Operation(operationID string) *Operation {
+	for _, operation := range d.operations {
+		if operation.ID == operationID {
+			return operation
+		}
+	}
+
+	return nil
+}
+
+// FindOperation returns the operation that handles requests with the given
+// method and path, which may have a query string. Paths without templates
+// are preferred over templated paths, as OpenAPI requires.
+func (d *Document) FindOperation(method string, path string) (*Operation, error) {
+	requestURL, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	segments := strings.Split(requestURL.EscapedPath(), "/")
+
+	var match *Operation
+	pathMatched := false
+	for _, operation := range d.operations {
+		if _, ok := operation.matchPath(segments); !ok {
+			continue
+		}
+
+		pathMatched = true
+		if operation.Method != strings.ToUpper(method) {
+			continue
+		}
+		if match == nil || operation.templates < match.templates {
+			match = operation
+		}
+	}
+
+	switch {
+	case match != nil:
+		return match, nil
+	case pathMatched:
+		return nil, ErrMethodNotAllowed
+	default:
+		return nil, ErrOperationNotFound
+	}
+}
+
+// ValidateRequest validates a request against the operation that handles
+// it. path is the path of the request with its query string.
+func (d *Document) ValidateRequest(method string, path string, headers http.Header, body []byte) error {
+	operation, err := d.FindOperation(method, path)
+	if err != nil {
+		return err
+	}
+
+	return operation.ValidateRequest(path, headers, body)
+}
+
+// ValidateResponse validates the response to a request against the
+// operation that handles the request.
+func (d *Document) ValidateResponse(method string, path string, status int, headers http.Header, body []byte) error {
+	operation, err := d.FindOperation(method, path)
+	if err != nil {
+		return err
+	}
+
+	return operation.ValidateResponse(status, headers, body)
+}
+
+// decode decodes a json or yaml document.
+func decode(document []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	var value interface{}
+	if decoder.Decode(&value) == nil {
+		return value, nil
+	}
+
+	err := yaml.Unmarshal(document, &value)
+	if err != nil {
+		return nil, err
+	}
+
+	return yamlutil.ConvertValue(value)
+}
+
+// The keywords whose values are instances rather than schemas, which are
+// not converted.
+var instanceKeywords = []string{"default", "enum", "example", "examples", "const"}
+
+// The keywords whose values are objects of schemas by their names.
+var namedSchemaKeywords = []string{"properties", "patternProperties", "schemas"}
+
+// The exclusive bounds by the inclusive bounds that they are flags of.
+var exclusiveBounds = map[string]string{
+	"minimum": "exclusiveMinimum",
+	"maximum": "exclusiveMaximum",
+}
+
+// convertSchemas converts the schemas in a value of the document from the
+// OpenAPI dialect to draft 7. Only schemas have a "nullable" flag or
+// boolean exclusive bounds, so every object of the document is converted.
+func convertSchemas(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if nullable, _ := v["nullable"].(bool); nullable {
+			if jsonType, ok := v["type"].(string); ok {
+				v["type"] = []interface{}{jsonType, "null"}
+			}
+		}
+		if _, ok := v["nullable"].(bool); ok {
+			delete(v, "nullable")
+		}
+
+		for bound, exclusive := range exclusiveBounds {
+			if flag, ok := v[exclusive].(bool); ok {
+				delete(v, exclusive)
+				if limit, ok := v[bound]; ok && flag {
+					v[exclusive] = limit
+					delete(v, bound)
+				}
+			}
+		}
+
+		for key, item := range v {
+			switch {
+			case strutil.Contains(namedSchemaKeywords, key):
+				// The keys of these objects are names, so a property
+				// named "default" is a schema too.
+				schemas, _ := item.(map[string]interface{})
+				for _, schema := range schemas {
+					convertSchemas(schema)
+				}
+			case !strutil.Contains(instanceKeywords, key):
+				convertSchemas(item)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			convertSchemas(item)
+		}
+	}
+}
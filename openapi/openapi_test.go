@@ -0,0 +1,281 @@
+package openapi_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/openapi"
+)
+
+const petstore = `
+openapi: 3.0.3
+info:
+  title: Petstore
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      parameters:
+        - name: limit
+          in: query
+          schema: {type: integer, minimum: 1, maximum: 100, exclusiveMaximum: true}
+        - name: tags
+          in: query
+          explode: false
+          schema: {type: array, items: {type: string}, maxItems: 2}
+        - $ref: '#/components/parameters/RequestID'
+      responses:
+        '200':
+          description: The pets.
+          headers:
+            X-Total:
+              required: true
+              schema: {type: integer}
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/Pet'}
+        default:
+          $ref: '#/components/responses/Error'
+    post:
+      operationId: createPet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/NewPet'}
+          application/x-www-form-urlencoded:
+            schema: {$ref: '#/components/schemas/NewPet'}
+      responses:
+        '201':
+          description: The created pet.
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Pet'}
+  /pets/mine:
+    get:
+      operationId: listMyPets
+      responses:
+        '204':
+          description: No pets.
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        schema: {type: integer}
+    get:
+      operationId: showPet
+      responses:
+        2XX:
+          description: The pet.
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Pet'}
+components:
+  parameters:
+    RequestID:
+      name: X-Request-ID
+      in: header
+      required: true
+      schema: {type: string, pattern: '^[0-9a-f-]{36}$'}
+  responses:
+    Error:
+      description: An error.
+      content:
+        application/json:
+          schema:
+            type: object
+            required: [message]
+            properties:
+              message: {type: string}
+  schemas:
+    NewPet:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string, minLength: 1}
+        tag: {type: string, nullable: true}
+    Pet:
+      allOf:
+        - $ref: '#/components/schemas/NewPet'
+        - type: object
+          required: [id]
+          properties:
+            id: {type: integer, format: int64}
+`
+
+const requestID = "b3a1c1d2-8c4e-4f5a-9e7b-2d1f0a6c3e4b"
+
+func loadPetstore(t *testing.T) *openapi.Document {
+	document, err := openapi.Load(nil, "http://example.com/openapi/petstore.yaml", []byte(petstore))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return document
+}
+
+func TestLoad(t *testing.T) {
+	document := loadPetstore(t)
+
+	var ids []string
+	for _, operation := range document.Operations() {
+		ids = append(ids, operation.Method+" "+operation.Path+" "+operation.ID)
+	}
+
+	expected := []string{
+		"GET /pets listPets",
+		"POST /pets createPet",
+		"GET /pets/mine listMyPets",
+		"GET /pets/{petId} showPet",
+	}
+	if len(ids) != len(expected) {
+		t.Fatalf("expected operations %v, got %v", expected, ids)
+	}
+	for index := range expected {
+		if ids[index] != expected[index] {
+			t.Errorf("expected operations %v, got %v", expected, ids)
+			break
+		}
+	}
+
+	if document.Operation("showPet") == nil || document.Operation("missing") != nil {
+		t.Error("expected operations to be found by their operationId")
+	}
+
+	pet := document.ComponentSchema("Pet")
+	if pet == nil {
+		t.Fatal("expected the Pet component schema")
+	}
+	if err := jsonvalidator.NewValidator(pet).Validate([]byte(`{"id": 1, "name": "Rex", "tag": null}`)); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if _, err := openapi.Load(nil, "http://example.com/openapi/swagger.json", []byte(`{"swagger": "2.0"}`)); err == nil {
+		t.Error("expected an error for a swagger document")
+	}
+}
+
+func TestLoadRegistry(t *testing.T) {
+	const uri = "http://example.com/openapi/tenant.yaml"
+
+	registry := jsonvalidator.NewRegistry()
+	document, err := openapi.Load(registry, uri, []byte(petstore))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := registry.Get(uri); !ok {
+		t.Error("expected the document to be registered in the registry")
+	}
+	if _, ok := jsonvalidator.DefaultRegistry.Get(uri); ok {
+		t.Error("expected the document not to be registered in the DefaultRegistry")
+	}
+
+	// Documents of other registries may have the same URI.
+	other, err := openapi.Load(jsonvalidator.NewRegistry(), uri, []byte(strings.Replace(petstore, "required: [name]", "required: []", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := jsonvalidator.NewValidator(other.ComponentSchema("Pet")).Validate([]byte(`{"id": 1}`)); err != nil {
+		t.Errorf("expected the Pet schema of the other document, got %v", err)
+	}
+	if err := jsonvalidator.NewValidator(document.ComponentSchema("Pet")).Validate([]byte(`{"id": 1}`)); err == nil {
+		t.Error("expected the Pet schema of the first document to require a name")
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	document := loadPetstore(t)
+
+	testCases := []struct {
+		method      string
+		path        string
+		headers     map[string]string
+		body        string
+		expectedErr error
+		in          string
+	}{
+		{"GET", "/pets?limit=10&tags=a,b", map[string]string{"X-Request-ID": requestID}, "", nil, ""},
+		{"GET", "/pets?limit=100", map[string]string{"X-Request-ID": requestID}, "", nil, "query"},
+		{"GET", "/pets?tags=a,b,c", map[string]string{"X-Request-ID": requestID}, "", nil, "query"},
+		{"GET", "/pets", nil, "", nil, "header"},
+		{"GET", "/pets", map[string]string{"x-request-id": "1"}, "", nil, "header"},
+		{"GET", "/pets/mine", nil, "", nil, ""},
+		{"GET", "/pets/12", nil, "", nil, ""},
+		{"GET", "/pets/rex", nil, "", nil, "path"},
+		{"POST", "/pets", map[string]string{"Content-Type": "application/json"}, `{"name": "Rex", "tag": null}`, nil, ""},
+		{"POST", "/pets", map[string]string{"Content-Type": "application/json; charset=utf-8"}, `{"name": ""}`, nil, "body"},
+		{"POST", "/pets", map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, `name=Rex&tag=dog`, nil, ""},
+		{"POST", "/pets", map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, `tag=dog`, nil, "body"},
+		{"POST", "/pets", map[string]string{"Content-Type": "text/plain"}, `Rex`, openapi.ErrUnsupportedContentType, ""},
+		{"POST", "/pets", nil, ``, openapi.ErrMissingBody, ""},
+		{"DELETE", "/pets", nil, ``, openapi.ErrMethodNotAllowed, ""},
+		{"GET", "/owners", nil, ``, openapi.ErrOperationNotFound, ""},
+	}
+
+	for _, testCase := range testCases {
+		headers := http.Header{}
+		for name, value := range testCase.headers {
+			headers.Set(name, value)
+		}
+
+		err := document.ValidateRequest(testCase.method, testCase.path, headers, []byte(testCase.body))
+		checkError(t, testCase.method+" "+testCase.path, err, testCase.expectedErr, testCase.in)
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	document := loadPetstore(t)
+	jsonHeaders := http.Header{"Content-Type": {"application/json"}, "X-Total": {"1"}}
+
+	testCases := []struct {
+		method      string
+		path        string
+		status      int
+		headers     http.Header
+		body        string
+		expectedErr error
+		in          string
+	}{
+		{"GET", "/pets", 200, jsonHeaders, `[{"id": 1, "name": "Rex"}]`, nil, ""},
+		{"GET", "/pets", 200, jsonHeaders, `[{"name": "Rex"}]`, nil, "body"},
+		{"GET", "/pets", 200, http.Header{"Content-Type": {"application/json"}}, `[]`, nil, "header"},
+		{"GET", "/pets", 500, jsonHeaders, `{"message": "failed"}`, nil, ""},
+		{"GET", "/pets", 500, jsonHeaders, `{}`, nil, "body"},
+		{"GET", "/pets/1", 203, jsonHeaders, `{"id": 1, "name": "Rex"}`, nil, ""},
+		{"GET", "/pets/1", 404, jsonHeaders, ``, openapi.ErrUndocumentedStatus, ""},
+		{"GET", "/pets/mine", 204, nil, ``, nil, ""},
+		{"POST", "/pets", 201, http.Header{"Content-Type": {"text/html"}}, `<p>Rex</p>`, openapi.ErrUnsupportedContentType, ""},
+	}
+
+	for _, testCase := range testCases {
+		err := document.ValidateResponse(testCase.method, testCase.path, testCase.status, testCase.headers, []byte(testCase.body))
+		checkError(t, testCase.method+" "+testCase.path, err, testCase.expectedErr, testCase.in)
+	}
+}
+
+// checkError checks that err is expectedErr, or a ValidationError of the
+// location in if in is not empty.
+func checkError(t *testing.T, name string, err error, expectedErr error, in string) {
+	t.Helper()
+
+	if in == "" {
+		if err != expectedErr {
+			t.Errorf("%s: expected error %v, got %v", name, expectedErr, err)
+		}
+		return
+	}
+
+	var validationError openapi.ValidationError
+	if !errors.As(err, &validationError) {
+		t.Errorf("%s: expected a validation error of the %s, got %v", name, in, err)
+	} else if validationError.In != in {
+		t.Errorf("%s: expected a validation error of the %s, got %v", name, in, err)
+	}
+}
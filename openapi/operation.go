@@ -0,0 +1,610 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/formval"
+	"github.com/itayankri/gojsonvalidator/internal/strutil"
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+// Operation is an operation of an OpenAPI document, which handles the
+// requests with its method to its path.
+type Operation struct {
+	// ID is the operationId of the operation, which may be empty.
+	ID string
+
+	// Method is the http method of the operation, in upper case.
+	Method string
+
+	// Path is the path of the operation, which may have templates like
+	// "/pets/{petId}".
+	Path string
+
+	segments   []string
+	templates  int
+	parameters map[string]*parameters
+	body       content
+	required   bool
+	responses  map[string]*response
+}
+
+// parameters are the parameters of an operation in one location ("path",
+// "query", "header" or "cookie"), or the headers of a response. They are
+// validated as an object whose properties are the parameters.
+type parameters struct {
+	names  []string
+	schema *jsonvalidator.RootJsonSchema
+
+	// The separators of the parameters whose arrays are serialized as a
+	// single value, by the parameter names.
+	separators map[string]string
+}
+
+// content is the schemas of a body by its media types. A media type without
+// a schema has a nil schema.
+type content map[string]*jsonvalidator.RootJsonSchema
+
+type response struct {
+	headers *parameters
+	body    content
+}
+
+// The separators of the array values of the parameter styles.
+var styleSeparators = map[string]string{
+	"simple":         ",",
+	"form":           ",",
+	"spaceDelimited": " ",
+	"pipeDelimited":  "|",
+}
+
+// ValidateRequest validates a request to the operation. path is the path of
+// the request with its query string, and a body without a content type is
+// expected in the media type of the operation if it has exactly one.
+func (o *Operation) ValidateRequest(path string, headers http.Header, body []byte) error {
+	requestURL, err := url.Parse(path)
+	if err != nil {
+		return err
+	}
+
+	pathValues, ok := o.matchPath(strings.Split(requestURL.EscapedPath(), "/"))
+	if !ok {
+		return ErrOperationNotFound
+	}
+
+	query, err := url.ParseQuery(requestURL.RawQuery)
+	if err != nil {
+		return err
+	}
+
+	cookies := url.Values{}
+	for _, cookie := range (&http.Request{Header: headers}).Cookies() {
+		cookies.Add(cookie.Name, cookie.Value)
+	}
+
+	locations := []struct {
+		in     string
+		values func(name string) []string
+	}{
+		{"path", func(name string) []string { return pathValues[name] }},
+		{"query", func(name string) []string { return query[name] }},
+		{"header", func(name string) []string { return headers[http.CanonicalHeaderKey(name)] }},
+		{"cookie", func(name string) []string { return cookies[name] }},
+	}
+
+	for _, location := range locations {
+		err = o.parameters[location.in].validate(location.in, location.values)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if o.required {
+			return ErrMissingBody
+		}
+		return nil
+	}
+
+	return o.body.validate(headers, body)
+}
+
+// ValidateResponse validates a response of the operation. The response is
+// looked up by the status, then by its range (like "2XX"), and then the
+// default response is used.
+func (o *Operation) ValidateResponse(status int, headers http.Header, body []byte) error {
+	code := strconv.Itoa(status)
+	response, ok := o.responses[code]
+	if !ok {
+		response, ok = o.responses[code[:1]+"XX"]
+	}
+	if !ok {
+		response, ok = o.responses["default"]
+	}
+	if !ok {
+		return ErrUndocumentedStatus
+	}
+
+	err := response.headers.validate("header", func(name string) []string {
+		return headers[http.CanonicalHeaderKey(name)]
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	return response.body.validate(headers, body)
+}
+
+// matchPath returns the values of the templates of the operation path in a
+// request path, which is split into segments, if the paths match.
+func (o *Operation) matchPath(segments []string) (url.Values, bool) {
+	if len(segments) != len(o.segments) {
+		return nil, false
+	}
+
+	values := url.Values{}
+	for index, segment := range o.segments {
+		start, end := strings.Index(segment, "{"), strings.LastIndex(segment, "}")
+		if start < 0 || end < start {
+			if segment != segments[index] {
+				return nil, false
+			}
+			continue
+		}
+
+		// The template may have a prefix and a suffix, like "{id}.json".
+		prefix, suffix := segment[:start], segment[end+1:]
+		requestSegment := segments[index]
+		if len(requestSegment) < len(prefix)+len(suffix) ||
+			!strings.HasPrefix(requestSegment, prefix) || !strings.HasSuffix(requestSegment, suffix) {
+			return nil, false
+		}
+
+		value, err := url.PathUnescape(requestSegment[len(prefix) : len(requestSegment)-len(suffix)])
+		if err != nil {
+			return nil, false
+		}
+		values.Set(segment[start+1:end], value)
+	}
+
+	return values, true
+}
+
+// validate validates the values of the parameters in the location in.
+// values returns the values of a parameter by its name.
+func (p *parameters) validate(in string, values func(name string) []string) error {
+	if p == nil {
+		return nil
+	}
+
+	fields := url.Values{}
+	for _, name := range p.names {
+		fieldValues := values(name)
+		separator, ok := p.separators[name]
+		if ok && len(fieldValues) == 1 && fieldValues[0] != "" {
+			fieldValues = strings.Split(fieldValues[0], separator)
+		}
+		if len(fieldValues) > 0 {
+			fields[name] = fieldValues
+		}
+	}
+
+	err := formval.Validate(fields, p.schema)
+	if err != nil {
+		return ValidationError{In: in, Err: err}
+	}
+
+	return nil
+}
+
+// validate validates a body according to the content type in the headers.
+func (c content) validate(headers http.Header, body []byte) error {
+	if c == nil {
+		return nil
+	}
+
+	mediaType := headers.Get("Content-Type")
+	if mediaType == "" && len(c) == 1 {
+		for onlyMediaType := range c {
+			mediaType = onlyMediaType
+		}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return ErrUnsupportedContentType
+	}
+
+	schema, ok := c.lookup(mediaType)
+	if !ok {
+		return ErrUnsupportedContentType
+	}
+	if schema == nil {
+		return nil
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		err = jsonvalidator.NewValidator(schema).Validate(body)
+	case mediaType == "application/x-www-form-urlencoded":
+		var values url.Values
+		values, err = url.ParseQuery(string(body))
+		if err == nil {
+			err = formval.Validate(values, schema)
+		}
+	}
+
+	if err != nil {
+		return ValidationError{In: "body", Err: err}
+	}
+
+	return nil
+}
+
+// lookup returns the schema of a media type, which is matched exactly, then
+// by a range like "application/*", and then by "*/*".
+func (c content) lookup(mediaType string) (*jsonvalidator.RootJsonSchema, bool) {
+	ranges := []string{mediaType}
+	if index := strings.Index(mediaType, "/"); index >= 0 {
+		ranges = append(ranges, mediaType[:index]+"/*")
+	}
+	ranges = append(ranges, "*/*")
+
+	for _, mediaRange := range ranges {
+		if schema, ok := c[mediaRange]; ok {
+			return schema, true
+		}
+	}
+
+	return nil, false
+}
+
+// loader loads the operations of a document.
+type loader struct {
+	document *Document
+	root     *jsonvalidator.RootJsonSchema
+	bytes    json.RawMessage
+}
+
+// loadPath loads the operations of a path item.
+func (l *loader) loadPath(path string, value interface{}) error {
+	location := "/paths/" + jsonwalker.EscapeToken(path)
+	pathItem, location, err := l.object(value, location)
+	if err != nil {
+		return err
+	}
+
+	pathParameters, _ := pathItem["parameters"].([]interface{})
+	for _, method := range methods {
+		operationValue, ok := pathItem[method]
+		if !ok {
+			continue
+		}
+
+		operation, err := l.loadOperation(path, method, operationValue, location+"/"+method, pathParameters, location+"/parameters")
+		if err != nil {
+			return err
+		}
+		l.document.operations = append(l.document.operations, operation)
+	}
+
+	return nil
+}
+
+// loadOperation loads an operation and the parameters of its path item.
+func (l *loader) loadOperation(path string, method string, value interface{}, location string, pathParameters []interface{}, pathParametersLocation string) (*Operation, error) {
+	object, location, err := l.object(value, location)
+	if err != nil {
+		return nil, err
+	}
+
+	operation := &Operation{
+		Method:     strings.ToUpper(method),
+		Path:       path,
+		segments:   strings.Split(path, "/"),
+		parameters: map[string]*parameters{},
+		responses:  map[string]*response{},
+	}
+	operation.ID, _ = object["operationId"].(string)
+	for _, segment := range operation.segments {
+		if strings.Contains(segment, "{") {
+			operation.templates++
+		}
+	}
+
+	// The parameters of the operation override the parameters of the path
+	// item with the same name and location.
+	type parameter struct {
+		object   map[string]interface{}
+		location string
+	}
+	byKey := map[string]parameter{}
+	operationParameters, _ := object["parameters"].([]interface{})
+	for _, list := range []struct {
+		values   []interface{}
+		location string
+	}{{pathParameters, pathParametersLocation}, {operationParameters, location + "/parameters"}} {
+		for index, parameterValue := range list.values {
+			parameterObject, parameterLocation, err := l.object(parameterValue, list.location+"/"+strconv.Itoa(index))
+			if err != nil {
+				return nil, err
+			}
+
+			name, _ := parameterObject["name"].(string)
+			in, _ := parameterObject["in"].(string)
+			byKey[in+"/"+name] = parameter{parameterObject, parameterLocation}
+		}
+	}
+
+	grouped := map[string]map[string]parameter{}
+	for _, p := range byKey {
+		in, _ := p.object["in"].(string)
+		name, _ := p.object["name"].(string)
+		if grouped[in] == nil {
+			grouped[in] = map[string]parameter{}
+		}
+		grouped[in][name] = p
+	}
+
+	for in, group := range grouped {
+		objects := make(map[string]map[string]interface{}, len(group))
+		locations := make(map[string]string, len(group))
+		for name, p := range group {
+			objects[name], locations[name] = p.object, p.location
+		}
+
+		operation.parameters[in], err = l.parameters(in, objects, locations)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if requestBody, ok := object["requestBody"]; ok {
+		bodyObject, bodyLocation, err := l.object(requestBody, location+"/requestBody")
+		if err != nil {
+			return nil, err
+		}
+
+		operation.required, _ = bodyObject["required"].(bool)
+		operation.body, err = l.content(bodyObject, bodyLocation)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	responses, _ := object["responses"].(map[string]interface{})
+	for code, responseValue := range responses {
+		responseObject, responseLocation, err := l.object(responseValue, location+"/responses/"+jsonwalker.EscapeToken(code))
+		if err != nil {
+			return nil, err
+		}
+
+		response := &response{}
+		response.body, err = l.content(responseObject, responseLocation)
+		if err != nil {
+			return nil, err
+		}
+
+		headers, _ := responseObject["headers"].(map[string]interface{})
+		objects := map[string]map[string]interface{}{}
+		locations := map[string]string{}
+		for name, headerValue := range headers {
+			// The content type of a response is described by its content.
+			if strings.EqualFold(name, "Content-Type") {
+				continue
+			}
+
+			objects[name], locations[name], err = l.object(headerValue, responseLocation+"/headers/"+jsonwalker.EscapeToken(name))
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(objects) > 0 {
+			response.headers, err = l.parameters("header", objects, locations)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// The ranges of statuses may be written in lower case, like "2xx".
+		if code != "default" {
+			code = strings.ToUpper(code)
+		}
+		operation.responses[code] = response
+	}
+
+	return operation, nil
+}
+
+// parameters compiles the parameters of a location, which are given by
+// their names with their locations in the document.
+func (l *loader) parameters(in string, objects map[string]map[string]interface{}, locations map[string]string) (*parameters, error) {
+	names := make([]string, 0, len(objects))
+	properties := map[string]interface{}{}
+	var required []string
+	separators := map[string]string{}
+	for name, object := range objects {
+		names = append(names, name)
+
+		schemaLocation, err := l.schemaLocation(object, locations[name])
+		if err != nil {
+			return nil, err
+		}
+		if schemaLocation != "" {
+			properties[name] = map[string]interface{}{"$ref": l.reference(schemaLocation)}
+		}
+
+		if isRequired, _ := object["required"].(bool); isRequired || in == "path" {
+			required = append(required, name)
+		}
+
+		// Path and header parameters are of the "simple" style, and query
+		// and cookie parameters are of the "form" style, whose arrays are
+		// repeated parameters unless they are not exploded.
+		style, ok := object["style"].(string)
+		if !ok {
+			style = "simple"
+			if in == "query" || in == "cookie" {
+				style = "form"
+			}
+		}
+		explode, ok := object["explode"].(bool)
+		if !ok {
+			explode = style == "form"
+		}
+		if separator, ok := styleSeparators[style]; ok && (!explode || style != "form") {
+			separators[name] = separator
+		}
+	}
+	sort.Strings(names)
+	sort.Strings(required)
+
+	object := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		object["required"] = required
+	}
+
+	schema, err := l.compile(object)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the parameters of array types are split.
+	for name := range separators {
+		types, err := schema.TypesAt("/" + jsonwalker.EscapeToken(name))
+		if err != nil {
+			return nil, err
+		}
+		if !strutil.Contains(types, "array") {
+			delete(separators, name)
+		}
+	}
+
+	return &parameters{names: names, schema: schema, separators: separators}, nil
+}
+
+// schemaLocation returns the location of the schema of a parameter or a
+// header, which is either its "schema" or the schema of its only media
+// type, or an empty location if it has no schema.
+func (l *loader) schemaLocation(object map[string]interface{}, location string) (string, error) {
+	if _, ok := object["schema"]; ok {
+		return location + "/schema", nil
+	}
+
+	mediaTypes, _ := object["content"].(map[string]interface{})
+	for mediaType, mediaTypeValue := range mediaTypes {
+		mediaTypeObject, _ := mediaTypeValue.(map[string]interface{})
+		if _, ok := mediaTypeObject["schema"]; ok {
+			return location + "/content/" + jsonwalker.EscapeToken(mediaType) + "/schema", nil
+		}
+	}
+
+	return "", nil
+}
+
+// content compiles the schemas of the media types of a request body or a
+// response, or returns nil if it has no content.
+func (l *loader) content(object map[string]interface{}, location string) (content, error) {
+	mediaTypes, ok := object["content"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	schemas := content{}
+	for mediaType, mediaTypeValue := range mediaTypes {
+		mediaTypeObject, _ := mediaTypeValue.(map[string]interface{})
+		key := strings.ToLower(mediaType)
+		if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+			key = parsed
+		}
+
+		schemas[key] = nil
+		if _, ok := mediaTypeObject["schema"]; !ok {
+			continue
+		}
+
+		var err error
+		schemas[key], err = l.schema(location + "/content/" + jsonwalker.EscapeToken(mediaType) + "/schema")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return schemas, nil
+}
+
+// object returns an object of the document and its location, following its
+// "$ref" references.
+func (l *loader) object(value interface{}, location string) (map[string]interface{}, string, error) {
+	visited := map[string]bool{}
+	for {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, "", fmt.Errorf("the value at %q is not an object", location)
+		}
+
+		reference, ok := object["$ref"].(string)
+		if !ok {
+			return object, location, nil
+		}
+		if !strings.HasPrefix(reference, "#") {
+			return nil, "", fmt.Errorf("the reference %q at %q is not supported", reference, location)
+		}
+		if visited[reference] {
+			return nil, "", fmt.Errorf("the reference %q at %q is circular", reference, location)
+		}
+		visited[reference] = true
+
+		pointer, err := jsonwalker.NewJsonPointer(reference)
+		if err != nil {
+			return nil, "", err
+		}
+		value, err = pointer.EvaluateUseNumber(l.bytes)
+		if err != nil {
+			return nil, "", err
+		}
+		location = pointer.String()
+	}
+}
+
+// schema compiles the schema at a location of the document.
+func (l *loader) schema(location string) (*jsonvalidator.RootJsonSchema, error) {
+	// The schema is compiled in the document, which surfaces its errors
+	// when the document is loaded, and it is validated through a root
+	// schema that references it.
+	_, err := l.root.Resolve("#" + location)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.compile(map[string]interface{}{"$ref": l.reference(location)})
+}
+
+// reference returns the absolute reference to a location of the document.
+func (l *loader) reference(location string) string {
+	return l.document.uri + "#" + location
+}
+
+// compile compiles a root schema that is not registered, in the registry of
+// the document.
+func (l *loader) compile(schema map[string]interface{}) (*jsonvalidator.RootJsonSchema, error) {
+	bytes, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.document.registry.Compile(bytes)
+}
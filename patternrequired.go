@@ -0,0 +1,70 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+)
+
+// patternRequired holds the ajv-keywords "patternRequired" keyword, which
+// requires an object to have at least one property whose name matches each
+// of the listed patterns. The patterns are compiled when the schema is
+// scanned.
+type patternRequired struct {
+	patterns []string
+	regexps  []*regexp.Regexp
+}
+
+func (pr *patternRequired) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	object, ok := jsonData.value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	for index, re := range pr.regexps {
+		matched := false
+		for property := range object {
+			if re.MatchString(property) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return KeywordValidationError{
+				keyword: "patternRequired",
+				reason:  "no property matches the pattern \"" + pr.patterns[index] + "\"",
+				params:  map[string]interface{}{"missingPattern": pr.patterns[index]},
+			}
+		}
+	}
+
+	return nil
+}
+
+// compile compiles the regular expressions of the patterns.
+func (pr *patternRequired) compile(schemaPath string) error {
+	var errs SchemaCompilationErrors
+	pr.regexps = make([]*regexp.Regexp, len(pr.patterns))
+	for index, pattern := range pr.patterns {
+		var err error
+		pr.regexps[index], err = regexp.Compile(pattern)
+		if err != nil {
+			errs = append(errs, SchemaCompilationError{
+				path:  schemaPath + "/patternRequired/" + strconv.Itoa(index),
+				err:   "invalid regular expression: " + err.Error(),
+				cause: err,
+			})
+		}
+	}
+
+	return errs.err()
+}
+
+func (pr *patternRequired) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &pr.patterns)
+}
+
+func (pr *patternRequired) MarshalJSON() ([]byte, error) {
+	return json.Marshal(pr.patterns)
+}
@@ -0,0 +1,39 @@
+package jsonvalidator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPatternRequired(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{"patternRequired": ["^x-", "^content-"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		document string
+		reason   string
+	}{
+		{`{"x-request-id": "1", "content-type": "text/plain"}`, ""},
+		{`{"x-request-id": "1"}`, `no property matches the pattern "^content-"`},
+		{`{}`, `no property matches the pattern "^x-"`},
+		{`["x-request-id"]`, ""},
+	}
+
+	for _, testCase := range testCases {
+		err := NewValidator(rootSchema).Validate([]byte(testCase.document))
+		if testCase.reason == "" && err != nil {
+			t.Errorf("%s: expected the document to be valid, got %v", testCase.document, err)
+		}
+		if testCase.reason != "" && (err == nil || !strings.Contains(err.Error(), testCase.reason)) {
+			t.Errorf("%s: expected %q, got %v", testCase.document, testCase.reason, err)
+		}
+	}
+
+	_, err = NewRootJsonSchema([]byte(`{"patternRequired": ["^x-", "("], "properties": {"a": {"pattern": "["}}}`))
+	errs, ok := err.(SchemaCompilationErrors)
+	if !ok || len(errs) != 2 || errs[0].Path() != "/patternRequired/1" || errs[1].Path() != "/properties/a/pattern" {
+		t.Errorf("expected the invalid patterns to fail at their paths, got %v", err)
+	}
+}
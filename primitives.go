@@ -0,0 +1,240 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+)
+
+// isPrimitive returns true if a decoded json value is not an object or an
+// array.
+func isPrimitive(value interface{}) bool {
+	switch value.(type) {
+	case nil, bool, float64, string, json.Number:
+		{
+			return true
+		}
+	default:
+		{
+			return false
+		}
+	}
+}
+
+// hasPrimitiveFastPath returns true if a primitive json value can be
+// validated against the schema by validatePrimitive(), which is the case
+// when the only keywords of the schema that apply to primitive values are
+// "type" (with a single type name), "minLength", "maxLength", "multipleOf",
+// "minimum", "maximum", "exclusiveMinimum" and "exclusiveMaximum".
+// Object and array keywords are ignored, since they do not apply to
+// primitive values.
+func (js *JsonSchema) hasPrimitiveFastPath() bool {
+	if js.RejectAll || js.Ref != nil || js.DynamicRef != nil {
+		return false
+	}
+
+	if js.Type != nil {
+		if _, ok := js.Type.singleTypeName(); !ok {
+			return false
+		}
+	}
+
+	return js.Const == nil &&
+		js.Enum == nil &&
+		js.Pattern == nil &&
+		js.Format == nil &&
+		js.ContentEncoding == nil &&
+		js.ContentMediaType == nil &&
+		js.ContentSchema == nil &&
+		js.Transform == nil &&
+		js.AnyOf == nil &&
+		js.AllOf == nil &&
+		js.OneOf == nil &&
+		js.Not == nil &&
+		js.If == nil
+}
+
+// validatePrimitive validates a primitive json value against a schema for
+// which hasPrimitiveFastPath() is true. Unlike validateJsonData(), it does
+// not marshal the value or build a slice of the schema's keywords, so a
+// valid value is validated without allocations.
+// It returns the KeywordValidationError of the first failing keyword.
+func (js *JsonSchema) validatePrimitive(value interface{}, ctx *validationContext) error {
+	err := ctx.visit(value)
+	if err != nil {
+		return err
+	}
+
+	if js.Type != nil {
+		typeName, _ := js.Type.singleTypeName()
+		err := assertJsonType(typeName, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The keywords are validated in the same order as in validateJsonData().
+	data := jsonData{value}
+	if js.MinLength != nil {
+		if err := js.MinLength.validate("", data, "", ctx); err != nil {
+			return err
+		}
+	}
+
+	if js.MaxLength != nil {
+		if err := js.MaxLength.validate("", data, "", ctx); err != nil {
+			return err
+		}
+	}
+
+	if js.MultipleOf != nil {
+		if err := js.MultipleOf.validate("", data, "", ctx); err != nil {
+			return err
+		}
+	}
+
+	if js.Minimum != nil {
+		if err := js.Minimum.validate("", data, "", ctx); err != nil {
+			return err
+		}
+	}
+
+	if js.Maximum != nil {
+		if err := js.Maximum.validate("", data, "", ctx); err != nil {
+			return err
+		}
+	}
+
+	if js.ExclusiveMinimum != nil {
+		if err := js.ExclusiveMinimum.validate("", data, "", ctx); err != nil {
+			return err
+		}
+	}
+
+	if js.ExclusiveMaximum != nil {
+		if err := js.ExclusiveMaximum.validate("", data, "", ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// The valid json types, in the form that singleTypeName() returns them.
+var jsonTypes = [...]string{
+	TYPE_OBJECT,
+	TYPE_ARRAY,
+	TYPE_STRING,
+	TYPE_NUMBER,
+	TYPE_INTEGER,
+	TYPE_BOOLEAN,
+	TYPE_NULL,
+}
+
+// singleTypeName returns the type name of a "type" keyword that holds a
+// single valid type name.
+func (t *_type) singleTypeName() (string, bool) {
+	if t.array || len(t.names) != 1 {
+		return "", false
+	}
+
+	for _, jsonType := range jsonTypes {
+		if t.names[0] == jsonType {
+			return jsonType, true
+		}
+	}
+
+	return "", false
+}
+
+// encodedSize returns the size of the json encoding of a decoded json value,
+// as reported to the validation context, without encoding it. Like in
+// primitiveSize(), escaped characters in strings are counted as a single
+// byte.
+func encodedSize(value interface{}) int {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		{
+			// The braces, and the colons and commas between the properties.
+			size := 2 + 2*len(v)
+			if len(v) > 0 {
+				size--
+			}
+
+			for key, property := range v {
+				size += primitiveSize(key) + encodedSize(property)
+			}
+
+			return size
+		}
+	case []interface{}:
+		{
+			// The brackets, and the commas between the items.
+			size := 2 + len(v)
+			if len(v) > 0 {
+				size--
+			}
+
+			for _, item := range v {
+				size += encodedSize(item)
+			}
+
+			return size
+		}
+	default:
+		{
+			return primitiveSize(value)
+		}
+	}
+}
+
+// primitiveSize returns the size of the json encoding of a primitive value,
+// as reported to the validation context. Escaped characters in strings are
+// counted as a single byte.
+func primitiveSize(value interface{}) int {
+	switch v := value.(type) {
+	case nil:
+		{
+			return len("null")
+		}
+	case bool:
+		{
+			if v {
+				return len("true")
+			}
+
+			return len("false")
+		}
+	case string:
+		{
+			return len(v) + 2
+		}
+	case json.Number:
+		{
+			return len(v)
+		}
+	case float64:
+		{
+			// Like encoding/json, use the exponent format only for very
+			// small and very large numbers.
+			format := byte('f')
+			if abs := math.Abs(v); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+				format = 'e'
+			}
+
+			var buffer [32]byte
+			encoded := strconv.AppendFloat(buffer[:0], v, format, -1, 64)
+
+			// encoding/json also converts e-09 to e-9.
+			if n := len(encoded); format == 'e' && n >= 4 && encoded[n-4] == 'e' && encoded[n-3] == '-' && encoded[n-2] == '0' {
+				return n - 1
+			}
+
+			return len(encoded)
+		}
+	default:
+		{
+			return 0
+		}
+	}
+}
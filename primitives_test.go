@@ -0,0 +1,65 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPrimitiveFastPathAllocations(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"properties": {
+			"n": {"type": "integer", "minimum": 0, "exclusiveMaximum": 10, "multipleOf": 1},
+			"s": {"type": "string", "minLength": 1, "maxLength": 5}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := newValidationContext(NewValidator(rootSchema), 0)
+	var number, str interface{} = 5.0, "abc"
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := rootSchema.Properties["n"].validateChild("", "n", number, "", ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := rootSchema.Properties["s"].validateChild("", "s", str, "", ctx); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
+
+func TestPrimitiveFastPathErrors(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{"items": {"type": "integer", "maximum": 10}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document := "[" + strings.Repeat("1, ", 149) + "11]"
+	err = NewValidator(rootSchema).Validate([]byte(document))
+	schemaValidationError, ok := err.(SchemaValidationError)
+	if !ok {
+		t.Fatalf("expected a SchemaValidationError, got %v", err)
+	}
+
+	if schemaValidationError.Path() != "/149" || schemaValidationError.KeywordLocation() != "/items/maximum" {
+		t.Errorf("unexpected error location %q %q", schemaValidationError.Path(), schemaValidationError.KeywordLocation())
+	}
+}
+
+func TestPrimitiveSize(t *testing.T) {
+	for _, value := range []interface{}{nil, true, false, "abc", 0.0, -1.5, 1e21, 1e-7, 123456789.0, json.Number("1e400")} {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if size := primitiveSize(value); size != len(encoded) {
+			t.Errorf("%v: expected size %d, got %d", value, len(encoded), size)
+		}
+	}
+}
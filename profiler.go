@@ -0,0 +1,218 @@
+package jsonvalidator
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// KeywordProfile holds the accumulated cost of a keyword.
+// Duration is inclusive, which means that the time spent in the keyword's
+// sub-schemas (for example in the alternatives of "oneOf") is also counted
+// in the keyword's own Duration.
+type KeywordProfile struct {
+	// Keyword is the name of the keyword, for example "pattern".
+	Keyword string
+
+	// KeywordLocation is the location of the keyword, relative to the root
+	// schema and following the path of the validation. It is empty in the
+	// profiles that are returned by Profiler.KeywordProfiles().
+	KeywordLocation string
+
+	// Calls is the number of times the keyword was evaluated.
+	Calls int
+
+	// Duration is the total time spent in the evaluations of the keyword.
+	Duration time.Duration
+}
+
+// Profiler accumulates the time spent in each keyword across validations.
+// A Profiler is registered with Validator.Profile(), and may be shared by
+// several validators. It is safe for concurrent use.
+type Profiler struct {
+	mu       sync.Mutex
+	profiles map[string]*KeywordProfile
+}
+
+// NewProfiler returns an empty Profiler.
+func NewProfiler() *Profiler {
+	return &Profiler{profiles: map[string]*KeywordProfile{}}
+}
+
+// Profiles returns the profile of every keyword location that was evaluated,
+// sorted from the most to the least expensive.
+func (p *Profiler) Profiles() []KeywordProfile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	profiles := make([]KeywordProfile, 0, len(p.profiles))
+	for _, profile := range p.profiles {
+		profiles = append(profiles, *profile)
+	}
+
+	sortProfiles(profiles)
+	return profiles
+}
+
+// KeywordProfiles returns the profiles of the keywords, where the profiles of
+// all the locations of a keyword are summed up, sorted from the most to the
+// least expensive.
+func (p *Profiler) KeywordProfiles() []KeywordProfile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	byKeyword := map[string]*KeywordProfile{}
+	for _, profile := range p.profiles {
+		sum, ok := byKeyword[profile.Keyword]
+		if !ok {
+			sum = &KeywordProfile{Keyword: profile.Keyword}
+			byKeyword[profile.Keyword] = sum
+		}
+
+		sum.Calls += profile.Calls
+		sum.Duration += profile.Duration
+	}
+
+	profiles := make([]KeywordProfile, 0, len(byKeyword))
+	for _, profile := range byKeyword {
+		profiles = append(profiles, *profile)
+	}
+
+	sortProfiles(profiles)
+	return profiles
+}
+
+// Reset drops all the profiles that were accumulated so far.
+func (p *Profiler) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.profiles = map[string]*KeywordProfile{}
+}
+
+// merge adds the profiles of a single validation to the profiler.
+func (p *Profiler) merge(profiles map[string]*KeywordProfile) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for location, profile := range profiles {
+		total, ok := p.profiles[location]
+		if !ok {
+			total = &KeywordProfile{
+				Keyword:         profile.Keyword,
+				KeywordLocation: profile.KeywordLocation,
+			}
+			p.profiles[location] = total
+		}
+
+		total.Calls += profile.Calls
+		total.Duration += profile.Duration
+	}
+}
+
+func sortProfiles(profiles []KeywordProfile) {
+	sort.Slice(profiles, func(i, j int) bool {
+		if profiles[i].Duration != profiles[j].Duration {
+			return profiles[i].Duration > profiles[j].Duration
+		}
+
+		if profiles[i].KeywordLocation != profiles[j].KeywordLocation {
+			return profiles[i].KeywordLocation < profiles[j].KeywordLocation
+		}
+
+		return profiles[i].Keyword < profiles[j].Keyword
+	})
+}
+
+// keywordName returns the name of the keyword that a keywordValidator
+// implements.
+func keywordName(keyword keywordValidator) string {
+	switch v := keyword.(type) {
+	case *_type:
+		return "type"
+	case *_const:
+		return "const"
+	case enum:
+		return "enum"
+	case *minLength:
+		return "minLength"
+	case *maxLength:
+		return "maxLength"
+	case *pattern:
+		return "pattern"
+	case *format:
+		return "format"
+	case *contentEncoding:
+		return "contentEncoding"
+	case *contentMediaType:
+		return "contentMediaType"
+	case *contentSchema:
+		return "contentSchema"
+	case *formatMinimum:
+		return "formatMinimum"
+	case *formatMaximum:
+		return "formatMaximum"
+	case *formatExclusiveMinimum:
+		return "formatExclusiveMinimum"
+	case *formatExclusiveMaximum:
+		return "formatExclusiveMaximum"
+	case *multipleOf:
+		return "multipleOf"
+	case *minimum:
+		return "minimum"
+	case *maximum:
+		return "maximum"
+	case *exclusiveMinimum:
+		return "exclusiveMinimum"
+	case *exclusiveMaximum:
+		return "exclusiveMaximum"
+	case required:
+		return "required"
+	case *patternRequired:
+		return "patternRequired"
+	case *propertyNames:
+		return "propertyNames"
+	case properties:
+		return "properties"
+	case *additionalProperties:
+		return "additionalProperties"
+	case patternProperties:
+		return "patternProperties"
+	case *dependencies:
+		return "dependencies"
+	case *minProperties:
+		return "minProperties"
+	case *maxProperties:
+		return "maxProperties"
+	case *tupleItems:
+		if v.prefix {
+			return "prefixItems"
+		}
+		return "items"
+	case *contains:
+		return "contains"
+	case *minItems:
+		return "minItems"
+	case *maxItems:
+		return "maxItems"
+	case *uniqueItems:
+		return "uniqueItems"
+	case *uniqueItemProperties:
+		return "uniqueItemProperties"
+	case anyOf:
+		return "anyOf"
+	case allOf:
+		return "allOf"
+	case oneOf:
+		return "oneOf"
+	case *not:
+		return "not"
+	case *_if:
+		return "if"
+	case *ref:
+		return "$ref"
+	case *dynamicRef:
+		return "$dynamicRef"
+	}
+
+	return "unknown"
+}
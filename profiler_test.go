@@ -0,0 +1,62 @@
+package jsonvalidator
+
+import (
+	"testing"
+)
+
+func TestProfiler(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"properties": {
+			"name": {"type": "string", "pattern": "^[a-z]+$"},
+			"tags": {"items": {"type": "string"}}
+		},
+		"required": ["name"]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profiler := NewProfiler()
+	validator := NewValidator(rootSchema).Profile(profiler)
+	for i := 0; i < 2; i++ {
+		err := validator.Validate([]byte(`{"name": "abc", "tags": ["a", "b", "c"]}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	calls := map[string]int{}
+	for _, profile := range profiler.Profiles() {
+		calls[profile.KeywordLocation] = profile.Calls
+	}
+
+	expected := map[string]int{
+		"/required":                   2,
+		"/properties":                 2,
+		"/properties/name/type":       2,
+		"/properties/name/pattern":    2,
+		"/properties/tags/items":      2,
+		"/properties/tags/items/type": 6,
+	}
+	for location, count := range expected {
+		if calls[location] != count {
+			t.Errorf("%s: expected %d calls, got %d", location, count, calls[location])
+		}
+	}
+
+	keywordCalls := map[string]int{}
+	for _, profile := range profiler.KeywordProfiles() {
+		if profile.KeywordLocation != "" {
+			t.Errorf("expected keyword profiles without locations, got %q", profile.KeywordLocation)
+		}
+		keywordCalls[profile.Keyword] = profile.Calls
+	}
+	if keywordCalls["type"] != 8 {
+		t.Errorf("expected 8 calls of \"type\", got %d", keywordCalls["type"])
+	}
+
+	profiler.Reset()
+	if profiles := profiler.Profiles(); len(profiles) != 0 {
+		t.Errorf("expected no profiles after reset, got %v", profiles)
+	}
+}
@@ -0,0 +1,696 @@
+package protoschema
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/internal/schemautil"
+	"github.com/itayankri/gojsonvalidator/internal/strutil"
+)
+
+// ErrNotAnObject is returned by GenerateProto() if the root schema does not
+// describe an object, which is the only kind of value a message can be.
+var ErrNotAnObject = errors.New("protoschema: the root schema does not describe an object")
+
+// GenerateProto generates a proto3 file with a message of the given name that
+// describes the objects of a root schema, for moving schema-first designs to
+// protobuf. The generated file is a starting point, since a json schema can
+// express constraints that protobuf cannot:
+//   - properties become fields, numbered in the order of their names, with
+//     snake_case names and a json_name option if protojson would not map the
+//     field back to the property name,
+//   - objects with properties become nested messages, and "$ref" references
+//     to them become top-level messages named after the last token of the
+//     reference,
+//   - objects without properties become maps if "additionalProperties" is a
+//     schema, and google.protobuf.Struct otherwise,
+//   - string enums become proto enums, whose value names are the upper-cased
+//     enum values prefixed by the enum name, after an UNSPECIFIED zero value,
+//   - a property whose schema has a "oneOf" becomes a oneof with a field for
+//     every alternative, and a "oneOf" of an object whose alternatives only
+//     list "required" properties puts those properties in a oneof,
+//   - nullable scalars become optional fields, and values of several types,
+//     tuples and arrays of arrays become google.protobuf.Value and ListValue,
+//   - date-time strings become google.protobuf.Timestamp, and base64 strings
+//     become bytes.
+//
+// The validation keywords that have no equivalent, like "minimum" and
+// "pattern", are dropped, and "required" properties are marked by comments.
+func GenerateProto(schema *jsonvalidator.RootJsonSchema, packageName string, messageName string) ([]byte, error) {
+	root, err := schemautil.Map(&schema.JsonSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	generation := &protoGeneration{
+		schema:  schema,
+		names:   map[string]bool{},
+		refs:    map[string]string{},
+		imports: map[string]bool{},
+	}
+
+	root = generation.mergeAllOf(root)
+	if !schemautil.IsObjectSchema(root) {
+		return nil, ErrNotAnObject
+	}
+
+	message := &protoMessage{name: generation.topLevelName(messageName)}
+	generation.messages = append(generation.messages, message)
+	generation.refs["#"] = message.name
+	err = generation.buildMessage(message, root)
+	if err != nil {
+		return nil, err
+	}
+
+	return generation.render(packageName), nil
+}
+
+// protoGeneration holds the state of a single GenerateProto() call.
+type protoGeneration struct {
+	schema *jsonvalidator.RootJsonSchema
+
+	// The top-level messages, in the order they were created, and the names
+	// that are taken at the top level.
+	messages []*protoMessage
+	names    map[string]bool
+
+	// The names of the messages that were generated for references.
+	refs map[string]string
+
+	// The imported proto files of the well-known types.
+	imports map[string]bool
+}
+
+type protoMessage struct {
+	name     string
+	comment  string
+	fields   []*protoField
+	messages []*protoMessage
+	enums    []*protoEnum
+	names    map[string]bool
+}
+
+type protoField struct {
+	comment  []string
+	label    string
+	typeName string
+	name     string
+	jsonName string
+	number   int
+	oneof    string
+}
+
+type protoEnum struct {
+	name   string
+	values []protoEnumValue
+}
+
+type protoEnumValue struct {
+	name     string
+	original string
+}
+
+// The proto types of the well-known types that are used for values that a
+// proto type cannot describe.
+const (
+	protoValue     = "google.protobuf.Value"
+	protoListValue = "google.protobuf.ListValue"
+	protoStruct    = "google.protobuf.Struct"
+	protoTimestamp = "google.protobuf.Timestamp"
+)
+
+var wellKnownImports = map[string]string{
+	protoValue:     "google/protobuf/struct.proto",
+	protoListValue: "google/protobuf/struct.proto",
+	protoStruct:    "google/protobuf/struct.proto",
+	protoTimestamp: "google/protobuf/timestamp.proto",
+}
+
+// buildMessage adds the fields of an object schema to a message.
+func (g *protoGeneration) buildMessage(message *protoMessage, schema map[string]interface{}) error {
+	message.comment, _ = schema["description"].(string)
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	required := map[string]bool{}
+	for _, name := range schemautil.StringList(schema["required"]) {
+		required[name] = true
+	}
+
+	exclusive := exclusiveProperties(schema)
+	for index, name := range names {
+		field, err := g.buildField(message, name, properties[name])
+		if err != nil {
+			return err
+		}
+
+		field.number = index + 1
+		if required[name] {
+			field.comment = append(field.comment, "Required.")
+		}
+		if field.oneof == "" && exclusive[name] {
+			field.oneof = "choice"
+			field.label = ""
+		}
+
+		message.fields = append(message.fields, field)
+	}
+
+	return nil
+}
+
+// buildField returns the field of a property.
+func (g *protoGeneration) buildField(message *protoMessage, property string, schema interface{}) (*protoField, error) {
+	field := &protoField{name: fieldName(property)}
+	if defaultJsonName(field.name) != property {
+		field.jsonName = property
+	}
+
+	object, ok := schema.(map[string]interface{})
+	if ok {
+		if description, ok := object["description"].(string); ok {
+			field.comment = strings.Split(description, "\n")
+		}
+
+		// A property with alternatives becomes a oneof.
+		if alternatives, ok := object["oneOf"].([]interface{}); ok && len(alternatives) > 1 {
+			return g.buildOneof(message, property, field, alternatives)
+		}
+	}
+
+	label, typeName, err := g.fieldType(message, camelCase(property), schema)
+	if err != nil {
+		return nil, err
+	}
+
+	field.label, field.typeName = label, typeName
+	return field, nil
+}
+
+// buildOneof returns the field of the first alternative of a property with a
+// "oneOf", and adds the fields of the other alternatives to the message. The
+// fields of the other alternatives are numbered after the other fields.
+func (g *protoGeneration) buildOneof(message *protoMessage, property string, field *protoField, alternatives []interface{}) (*protoField, error) {
+	var first *protoField
+	used := map[string]bool{}
+	for index, alternative := range alternatives {
+		suffix := strconv.Itoa(index + 1)
+		if object, ok := alternative.(map[string]interface{}); ok {
+			if title, ok := object["title"].(string); ok && title != "" {
+				suffix = fieldName(title)
+			} else if jsonType, ok := object["type"].(string); ok {
+				suffix = jsonType
+			}
+		}
+		for used[suffix] {
+			suffix += "_" + strconv.Itoa(index+1)
+		}
+		used[suffix] = true
+
+		label, typeName, err := g.fieldType(message, camelCase(property)+camelCase(suffix), alternative)
+		if err != nil {
+			return nil, err
+		}
+
+		// The fields of a oneof can be neither repeated nor maps.
+		if label == "repeated" {
+			typeName = g.wellKnown(protoListValue)
+		} else if strings.HasPrefix(typeName, "map<") {
+			typeName = g.wellKnown(protoStruct)
+		}
+
+		member := &protoField{
+			name:     field.name + "_" + suffix,
+			typeName: typeName,
+			oneof:    field.name,
+		}
+		if first == nil {
+			member.comment = field.comment
+			first = member
+		} else {
+			message.fields = append(message.fields, member)
+		}
+	}
+
+	return first, nil
+}
+
+// fieldType returns the label and the proto type of a value. name is the
+// name of a nested message or enum, if one has to be created for the value.
+func (g *protoGeneration) fieldType(message *protoMessage, name string, schema interface{}) (string, string, error) {
+	object, ok := schema.(map[string]interface{})
+	if !ok {
+		return "", g.wellKnown(protoValue), nil
+	}
+
+	if reference, ok := object["$ref"].(string); ok {
+		return g.referenceType(message, name, reference)
+	}
+
+	object = g.mergeAllOf(object)
+
+	if values, ok := object["enum"].([]interface{}); ok {
+		return g.enumType(message, name, values, object)
+	}
+
+	if _, ok := object["const"].(string); ok {
+		return "", "string", nil
+	}
+
+	types := schemautil.StringList(object["type"])
+	nullable := false
+	for index := 0; index < len(types); index++ {
+		if types[index] == "null" {
+			types = append(types[:index], types[index+1:]...)
+			nullable = true
+			index--
+		}
+	}
+
+	optional := ""
+	if nullable {
+		optional = "optional"
+	}
+
+	if len(types) == 0 && schemautil.IsObjectSchema(object) {
+		types = []string{"object"}
+	}
+	if len(types) != 1 {
+		return "", g.wellKnown(protoValue), nil
+	}
+
+	switch types[0] {
+	case "string":
+		if format, _ := object["format"].(string); format == "date-time" {
+			return "", g.wellKnown(protoTimestamp), nil
+		}
+		if encoding, _ := object["contentEncoding"].(string); encoding == "base64" {
+			return optional, "bytes", nil
+		}
+		return optional, "string", nil
+	case "integer":
+		minimum, hasMinimum := object["minimum"].(float64)
+		maximum, hasMaximum := object["maximum"].(float64)
+		if hasMinimum && hasMaximum && minimum >= math.MinInt32 && maximum <= math.MaxInt32 {
+			return optional, "int32", nil
+		}
+		return optional, "int64", nil
+	case "number":
+		return optional, "double", nil
+	case "boolean":
+		return optional, "bool", nil
+	case "array":
+		items, ok := object["items"]
+		if _, isTuple := items.([]interface{}); !ok || isTuple {
+			return "", g.wellKnown(protoListValue), nil
+		}
+
+		label, typeName, err := g.fieldType(message, name+"Item", items)
+		if err != nil {
+			return "", "", err
+		}
+		if label == "repeated" || strings.HasPrefix(typeName, "map<") {
+			return "", g.wellKnown(protoListValue), nil
+		}
+		return "repeated", typeName, nil
+	case "object":
+		if _, ok := object["properties"].(map[string]interface{}); ok {
+			nested := &protoMessage{name: message.nestedName(name)}
+			message.messages = append(message.messages, nested)
+			return "", nested.name, g.buildMessage(nested, object)
+		}
+
+		if values, ok := object["additionalProperties"].(map[string]interface{}); ok {
+			label, typeName, err := g.fieldType(message, name+"Value", values)
+			if err != nil {
+				return "", "", err
+			}
+			if label == "repeated" || strings.HasPrefix(typeName, "map<") {
+				typeName = g.wellKnown(protoValue)
+			}
+			return "", "map<string, " + typeName + ">", nil
+		}
+
+		return "", g.wellKnown(protoStruct), nil
+	}
+
+	return "", g.wellKnown(protoValue), nil
+}
+
+// referenceType returns the type of a "$ref". References to objects become
+// top-level messages, which are generated once for every reference.
+func (g *protoGeneration) referenceType(message *protoMessage, name string, reference string) (string, string, error) {
+	if messageName, ok := g.refs[reference]; ok {
+		return "", messageName, nil
+	}
+
+	resolved, err := g.schema.Resolve(reference)
+	if err != nil {
+		return "", "", err
+	}
+
+	object, err := schemautil.Map(resolved)
+	if err != nil {
+		return "", "", err
+	}
+
+	object = g.mergeAllOf(object)
+	if _, ok := object["properties"].(map[string]interface{}); !ok {
+		return g.fieldType(message, name, object)
+	}
+
+	// The message is registered before it is built, so recursive references
+	// refer to it.
+	tokens := strings.Split(reference, "/")
+	referenced := &protoMessage{name: g.topLevelName(camelCase(tokens[len(tokens)-1]))}
+	g.refs[reference] = referenced.name
+	g.messages = append(g.messages, referenced)
+
+	return "", referenced.name, g.buildMessage(referenced, object)
+}
+
+// enumType returns the type of an "enum". Enums of strings become proto
+// enums, and other enums are described by the type of their values.
+func (g *protoGeneration) enumType(message *protoMessage, name string, values []interface{}, schema map[string]interface{}) (string, string, error) {
+	var strs []string
+	for _, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			if value == nil && len(values) > 1 {
+				continue
+			}
+
+			return "", g.wellKnown(protoValue), nil
+		}
+		strs = append(strs, str)
+	}
+
+	enum := &protoEnum{name: message.nestedName(name)}
+	prefix := constantName(enum.name) + "_"
+	used := map[string]bool{prefix + "UNSPECIFIED": true}
+	enum.values = append(enum.values, protoEnumValue{name: prefix + "UNSPECIFIED"})
+	for _, value := range strs {
+		valueName := prefix + constantName(value)
+		if constantName(value) == "" {
+			valueName = prefix + "EMPTY"
+		}
+		for suffix := 2; used[valueName]; suffix++ {
+			valueName = prefix + constantName(value) + "_" + strconv.Itoa(suffix)
+		}
+		used[valueName] = true
+
+		enum.values = append(enum.values, protoEnumValue{name: valueName, original: value})
+	}
+	message.enums = append(message.enums, enum)
+
+	if len(strs) < len(values) {
+		return "optional", enum.name, nil
+	}
+
+	return "", enum.name, nil
+}
+
+// wellKnown returns a well-known type, whose proto file is imported.
+func (g *protoGeneration) wellKnown(typeName string) string {
+	g.imports[wellKnownImports[typeName]] = true
+	return typeName
+}
+
+// mergeAllOf returns the schema with the properties and the required
+// properties of its "allOf" sub-schemas added to it.
+func (g *protoGeneration) mergeAllOf(schema map[string]interface{}) map[string]interface{} {
+	subSchemas, ok := schema["allOf"].([]interface{})
+	if !ok {
+		return schema
+	}
+
+	merged := make(map[string]interface{}, len(schema))
+	for keyword, value := range schema {
+		merged[keyword] = value
+	}
+	delete(merged, "allOf")
+
+	properties := map[string]interface{}{}
+	if own, ok := schema["properties"].(map[string]interface{}); ok {
+		for name, property := range own {
+			properties[name] = property
+		}
+	}
+	required := schemautil.StringList(schema["required"])
+
+	for _, subSchema := range subSchemas {
+		object, ok := subSchema.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if reference, ok := object["$ref"].(string); ok {
+			if resolved, err := g.schema.Resolve(reference); err == nil {
+				object, _ = schemautil.Map(resolved)
+			}
+		}
+
+		object = g.mergeAllOf(object)
+		if own, ok := object["properties"].(map[string]interface{}); ok {
+			for name, property := range own {
+				properties[name] = property
+			}
+		}
+		required = append(required, schemautil.StringList(object["required"])...)
+		if _, ok := merged["type"]; !ok && object["type"] != nil {
+			merged["type"] = object["type"]
+		}
+	}
+
+	if len(properties) > 0 {
+		merged["properties"] = properties
+	}
+	if len(required) > 0 {
+		requiredList := make([]interface{}, len(required))
+		for index, name := range required {
+			requiredList[index] = name
+		}
+		merged["required"] = requiredList
+	}
+
+	return merged
+}
+
+// topLevelName returns a name for a top-level message that is not taken yet.
+func (g *protoGeneration) topLevelName(name string) string {
+	unique := name
+	for suffix := 2; g.names[unique]; suffix++ {
+		unique = name + strconv.Itoa(suffix)
+	}
+	g.names[unique] = true
+
+	return unique
+}
+
+// nestedName returns a name for a nested message or enum that is not taken
+// yet in the message.
+func (m *protoMessage) nestedName(name string) string {
+	if m.names == nil {
+		m.names = map[string]bool{}
+	}
+
+	unique := name
+	for suffix := 2; m.names[unique]; suffix++ {
+		unique = name + strconv.Itoa(suffix)
+	}
+	m.names[unique] = true
+
+	return unique
+}
+
+// render writes the proto file.
+func (g *protoGeneration) render(packageName string) []byte {
+	var builder strings.Builder
+	builder.WriteString("syntax = \"proto3\";\n")
+	if packageName != "" {
+		builder.WriteString("\npackage " + packageName + ";\n")
+	}
+
+	imports := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	if len(imports) > 0 {
+		builder.WriteString("\n")
+	}
+	for _, path := range imports {
+		builder.WriteString("import \"" + path + "\";\n")
+	}
+
+	for _, message := range g.messages {
+		builder.WriteString("\n")
+		message.render(&builder, "")
+	}
+
+	return []byte(builder.String())
+}
+
+func (m *protoMessage) render(builder *strings.Builder, indent string) {
+	writeComment(builder, indent, strings.Split(m.comment, "\n"))
+	builder.WriteString(indent + "message " + m.name + " {\n")
+
+	inner := indent + "  "
+	for _, enum := range m.enums {
+		builder.WriteString(inner + "enum " + enum.name + " {\n")
+		for number, value := range enum.values {
+			builder.WriteString(fmt.Sprintf("%s  %s = %d;", inner, value.name, number))
+			if value.original != "" {
+				builder.WriteString(" // " + strconv.Quote(value.original))
+			}
+			builder.WriteString("\n")
+		}
+		builder.WriteString(inner + "}\n\n")
+	}
+
+	for _, nested := range m.messages {
+		nested.render(builder, inner)
+		builder.WriteString("\n")
+	}
+
+	// The fields of a oneof that have no number yet are numbered after the
+	// other fields, and all of them are rendered in the block of the oneof.
+	next := 0
+	for _, field := range m.fields {
+		if field.number > next {
+			next = field.number
+		}
+	}
+
+	rendered := map[string]bool{}
+	for _, field := range m.fields {
+		if field.oneof == "" {
+			writeComment(builder, inner, field.comment)
+			builder.WriteString(inner + field.declaration() + "\n")
+			continue
+		}
+
+		if rendered[field.oneof] {
+			continue
+		}
+		rendered[field.oneof] = true
+
+		var members []*protoField
+		for _, member := range m.fields {
+			if member.oneof != field.oneof {
+				continue
+			}
+
+			if member.number == 0 {
+				next++
+				member.number = next
+			}
+			members = append(members, member)
+		}
+		sort.Slice(members, func(i, j int) bool {
+			return members[i].number < members[j].number
+		})
+
+		builder.WriteString(inner + "oneof " + field.oneof + " {\n")
+		for _, member := range members {
+			writeComment(builder, inner+"  ", member.comment)
+			builder.WriteString(inner + "  " + member.declaration() + "\n")
+		}
+		builder.WriteString(inner + "}\n")
+	}
+
+	builder.WriteString(indent + "}\n")
+}
+
+func (f *protoField) declaration() string {
+	declaration := f.typeName + " " + f.name + " = " + strconv.Itoa(f.number)
+	if f.label != "" {
+		declaration = f.label + " " + declaration
+	}
+	if f.jsonName != "" {
+		declaration += " [json_name = " + strconv.Quote(f.jsonName) + "]"
+	}
+
+	return declaration + ";"
+}
+
+func writeComment(builder *strings.Builder, indent string, lines []string) {
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			builder.WriteString(indent + "// " + line + "\n")
+		}
+	}
+}
+
+// exclusiveProperties returns the properties that a "oneOf" of an object
+// makes mutually exclusive, which is the case if every alternative only
+// lists "required" properties.
+func exclusiveProperties(schema map[string]interface{}) map[string]bool {
+	alternatives, ok := schema["oneOf"].([]interface{})
+	if !ok || len(alternatives) < 2 {
+		return nil
+	}
+
+	exclusive := map[string]bool{}
+	for _, alternative := range alternatives {
+		object, ok := alternative.(map[string]interface{})
+		if !ok || len(object) != 1 || len(schemautil.StringList(object["required"])) != 1 {
+			return nil
+		}
+
+		exclusive[schemautil.StringList(object["required"])[0]] = true
+	}
+
+	return exclusive
+}
+
+// fieldName returns the snake_case field name of a property.
+func fieldName(property string) string {
+	name := strings.ToLower(strings.Join(strutil.Words(property), "_"))
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "field_" + name
+	}
+
+	return name
+}
+
+// camelCase returns the CamelCase name of a message or an enum.
+func camelCase(name string) string {
+	return strutil.CamelCase(name, "Message")
+}
+
+// constantName returns the UPPER_SNAKE_CASE name of an enum value.
+func constantName(name string) string {
+	return strings.ToUpper(strings.Join(strutil.Words(name), "_"))
+}
+
+// defaultJsonName returns the json name that protoc gives a field, which is
+// the field name in lower camel case.
+func defaultJsonName(field string) string {
+	var builder strings.Builder
+	upper := false
+	for _, r := range field {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		builder.WriteRune(r)
+	}
+
+	return builder.String()
+}
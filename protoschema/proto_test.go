@@ -0,0 +1,120 @@
+package protoschema_test
+
+import (
+	"testing"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/protoschema"
+)
+
+const orderSchema = `{
+	"$id": "http://example.com/protoschema/order.json",
+	"description": "An order of the shop.",
+	"type": "object",
+	"required": ["orderId", "lines"],
+	"properties": {
+		"orderId": {"type": "string", "description": "The id of the order."},
+		"lines": {"type": "array", "items": {"$ref": "#/definitions/line"}},
+		"status": {"enum": ["new", "paid", "in-transit"]},
+		"total": {"type": "number"},
+		"count": {"type": "integer", "minimum": 0, "maximum": 1000},
+		"note": {"type": ["string", "null"]},
+		"created_at": {"type": "string", "format": "date-time"},
+		"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+		"metadata": {"type": "object"},
+		"grid": {"type": "array", "items": {"type": "array", "items": {"type": "integer"}}},
+		"shipping": {"type": "object", "properties": {"address": {"type": "string"}, "express": {"type": "boolean"}}},
+		"discount": {"oneOf": [{"type": "number"}, {"title": "coupon", "type": "string"}]},
+		"card": {"type": "string"},
+		"iban": {"type": "string"},
+		"parent": {"$ref": "#"}
+	},
+	"oneOf": [{"required": ["card"]}, {"required": ["iban"]}],
+	"definitions": {
+		"line": {
+			"type": "object",
+			"properties": {"sku": {"type": "string"}, "quantity": {"type": "integer"}, "signature": {"type": "string", "contentEncoding": "base64"}}
+		}
+	}
+}`
+
+const orderProto = `syntax = "proto3";
+
+package shop;
+
+import "google/protobuf/struct.proto";
+import "google/protobuf/timestamp.proto";
+
+// An order of the shop.
+message Order {
+  enum Status {
+    STATUS_UNSPECIFIED = 0;
+    STATUS_NEW = 1; // "new"
+    STATUS_PAID = 2; // "paid"
+    STATUS_IN_TRANSIT = 3; // "in-transit"
+  }
+
+  message Shipping {
+    string address = 1;
+    bool express = 2;
+  }
+
+  oneof choice {
+    string card = 1;
+    string iban = 6;
+  }
+  int32 count = 2;
+  google.protobuf.Timestamp created_at = 3 [json_name = "created_at"];
+  oneof discount {
+    double discount_number = 4;
+    string discount_coupon = 16;
+  }
+  google.protobuf.ListValue grid = 5;
+  map<string, string> labels = 7;
+  // Required.
+  repeated Line lines = 8;
+  google.protobuf.Struct metadata = 9;
+  optional string note = 10;
+  // The id of the order.
+  // Required.
+  string order_id = 11;
+  Order parent = 12;
+  Shipping shipping = 13;
+  Status status = 14;
+  double total = 15;
+}
+
+message Line {
+  int64 quantity = 1;
+  bytes signature = 2;
+  string sku = 3;
+}
+`
+
+func TestGenerateProto(t *testing.T) {
+	rootSchema, err := jsonvalidator.NewRootJsonSchema([]byte(orderSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proto, err := protoschema.GenerateProto(rootSchema, "shop", "Order")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(proto) != orderProto {
+		t.Errorf("unexpected proto file:\n%s", proto)
+	}
+}
+
+func TestGenerateProtoNotAnObject(t *testing.T) {
+	rootSchema, err := jsonvalidator.NewRootJsonSchema([]byte(`{"$id": "http://example.com/protoschema/string.json", "type": "string"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = protoschema.GenerateProto(rootSchema, "shop", "Name")
+	if err != protoschema.ErrNotAnObject {
+		t.Errorf("expected ErrNotAnObject, got %v", err)
+	}
+}
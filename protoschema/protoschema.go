@@ -0,0 +1,398 @@
+// Package protoschema converts protobuf message descriptors to json schemas
+// that describe the json encoding of the messages, following the protojson
+// mapping, so services that transcode json to protobuf (like gRPC-gateway)
+// can validate json payloads before they are transcoded.
+//
+// The schemas accept what protojson accepts when it unmarshals a message,
+// with the exception of unknown fields, which are rejected:
+//   - 64-bit integers are numbers or strings of digits,
+//   - floating point numbers may also be "NaN", "Infinity" or "-Infinity",
+//   - enums are value names or numbers,
+//   - bytes are base64 strings,
+//   - every field may be null, which stands for its default value,
+//   - well-known types have their special json representation, for example
+//     google.protobuf.Timestamp is an RFC 3339 date-time string.
+package protoschema
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const draft07 = "http://json-schema.org/draft-07/schema#"
+
+// Converter converts message descriptors to json schemas.
+type Converter struct {
+	useProtoNames bool
+	id            string
+}
+
+// New creates a Converter that names properties by the json names of the
+// fields, like protojson does by default.
+func New() *Converter {
+	return &Converter{}
+}
+
+// UseProtoNames names properties by the field names of the proto file
+// instead of their json names, like protojson.MarshalOptions.UseProtoNames.
+func (c *Converter) UseProtoNames(useProtoNames bool) *Converter {
+	c.useProtoNames = useProtoNames
+	return c
+}
+
+// Id sets the "$id" of the converted schemas. By default it is
+// "urn:protobuf:" followed by the full name of the message, so schemas of
+// different messages can be used side by side.
+func (c *Converter) Id(id string) *Converter {
+	c.id = id
+	return c
+}
+
+// Convert returns the json schema of a message. The messages that the
+// message refers to (except the well-known types) are placed in the
+// "definitions" of the schema, under their full names.
+func (c *Converter) Convert(message protoreflect.MessageDescriptor) ([]byte, error) {
+	conversion := &conversion{
+		converter:   c,
+		root:        message,
+		definitions: map[string]interface{}{},
+	}
+
+	schema := conversion.messageSchema(message)
+	schema["$schema"] = draft07
+	schema["$id"] = c.id
+	if c.id == "" {
+		schema["$id"] = "urn:protobuf:" + string(message.FullName())
+	}
+	if len(conversion.definitions) > 0 {
+		schema["definitions"] = conversion.definitions
+	}
+
+	return json.Marshal(schema)
+}
+
+// Convert returns the json schema of a message with the default options.
+func Convert(message protoreflect.MessageDescriptor) ([]byte, error) {
+	return New().Convert(message)
+}
+
+// conversion holds the state of a single conversion.
+type conversion struct {
+	converter   *Converter
+	root        protoreflect.MessageDescriptor
+	definitions map[string]interface{}
+}
+
+// messageSchema returns the schema of the fields of a message.
+func (c *conversion) messageSchema(message protoreflect.MessageDescriptor) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": false,
+	}
+	if comment := leadingComment(message); comment != "" {
+		schema["description"] = comment
+	}
+
+	properties := map[string]interface{}{}
+	var required []string
+	fields := message.Fields()
+	for index := 0; index < fields.Len(); index++ {
+		field := fields.Get(index)
+		name := c.propertyName(field)
+
+		property := c.fieldSchema(field)
+		if comment := leadingComment(field); comment != "" {
+			property = map[string]interface{}{
+				"description": comment,
+				"allOf":       []interface{}{property},
+			}
+		}
+		properties[name] = property
+
+		if field.Cardinality() == protoreflect.Required {
+			required = append(required, name)
+		}
+	}
+	schema["properties"] = properties
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	// At most one field of a oneof may be set.
+	dependencies := map[string]interface{}{}
+	oneofs := message.Oneofs()
+	for index := 0; index < oneofs.Len(); index++ {
+		oneof := oneofs.Get(index)
+		// The synthetic oneofs of proto3 optional fields hold a single field.
+		if oneof.IsSynthetic() || oneof.Fields().Len() < 2 {
+			continue
+		}
+
+		for first := 0; first < oneof.Fields().Len(); first++ {
+			var others []interface{}
+			for second := 0; second < oneof.Fields().Len(); second++ {
+				if second != first {
+					others = append(others, map[string]interface{}{
+						"required": []string{c.propertyName(oneof.Fields().Get(second))},
+					})
+				}
+			}
+
+			dependencies[c.propertyName(oneof.Fields().Get(first))] = map[string]interface{}{
+				"not": map[string]interface{}{"anyOf": others},
+			}
+		}
+	}
+	if len(dependencies) > 0 {
+		schema["dependencies"] = dependencies
+	}
+
+	return schema
+}
+
+func (c *conversion) propertyName(field protoreflect.FieldDescriptor) string {
+	if c.converter.useProtoNames {
+		return string(field.Name())
+	}
+
+	return field.JSONName()
+}
+
+// fieldSchema returns the schema of a field, including repeated and map
+// fields, which may also be null.
+func (c *conversion) fieldSchema(field protoreflect.FieldDescriptor) interface{} {
+	switch {
+	case field.IsMap():
+		object := map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": c.valueSchema(field.MapValue()),
+		}
+		if keys := mapKeySchema(field.MapKey()); keys != nil {
+			object["propertyNames"] = keys
+		}
+		return nullable(object)
+	case field.IsList():
+		return nullable(map[string]interface{}{
+			"type":  "array",
+			"items": c.valueSchema(field),
+		})
+	}
+
+	// A null google.protobuf.Value is a NullValue, and is allowed anyway.
+	return nullable(c.valueSchema(field))
+}
+
+// valueSchema returns the schema of a single value of a field.
+func (c *conversion) valueSchema(field protoreflect.FieldDescriptor) map[string]interface{} {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]interface{}{"type": "boolean"}
+	case protoreflect.StringKind:
+		return map[string]interface{}{"type": "string"}
+	case protoreflect.BytesKind:
+		return bytesSchema()
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return integerSchema(math.MinInt32, math.MaxInt32)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return integerSchema(0, math.MaxUint32)
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return int64Schema(true)
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return int64Schema(false)
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return floatSchema()
+	case protoreflect.EnumKind:
+		return enumSchema(field.Enum())
+	default:
+		return c.messageReference(field.Message())
+	}
+}
+
+// messageReference returns the schema of a message field, which is a
+// reference to the definition of the message, or the json representation of
+// a well-known type.
+func (c *conversion) messageReference(message protoreflect.MessageDescriptor) map[string]interface{} {
+	if schema := wellKnownSchema(message); schema != nil {
+		return schema
+	}
+
+	if message.FullName() == c.root.FullName() {
+		return map[string]interface{}{"$ref": "#"}
+	}
+
+	name := string(message.FullName())
+	if _, ok := c.definitions[name]; !ok {
+		// The definition is registered before it is built, so recursive
+		// messages refer to it instead of being built again.
+		c.definitions[name] = nil
+		c.definitions[name] = c.messageSchema(message)
+	}
+
+	return map[string]interface{}{"$ref": "#/definitions/" + name}
+}
+
+// nullable returns a schema that also accepts null.
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	if _, ok := schema["enum"]; !ok {
+		switch jsonType := schema["type"].(type) {
+		case string:
+			if jsonType != "null" {
+				schema["type"] = []interface{}{jsonType, "null"}
+			}
+			return schema
+		case []interface{}:
+			schema["type"] = append(jsonType, "null")
+			return schema
+		}
+	}
+
+	if len(schema) == 0 {
+		return schema
+	}
+
+	return map[string]interface{}{
+		"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}},
+	}
+}
+
+func integerSchema(minimum, maximum int64) map[string]interface{} {
+	return map[string]interface{}{
+		"type":    "integer",
+		"minimum": minimum,
+		"maximum": maximum,
+	}
+}
+
+// int64Schema returns the schema of a 64-bit integer, which protojson writes
+// as a string, and reads from a string or a number.
+func int64Schema(signed bool) map[string]interface{} {
+	if signed {
+		return map[string]interface{}{
+			"type":    []interface{}{"integer", "string"},
+			"pattern": "^-?[0-9]+$",
+			"minimum": int64(math.MinInt64),
+			"maximum": int64(math.MaxInt64),
+		}
+	}
+
+	return map[string]interface{}{
+		"type":    []interface{}{"integer", "string"},
+		"pattern": "^[0-9]+$",
+		"minimum": 0,
+		"maximum": uint64(math.MaxUint64),
+	}
+}
+
+func floatSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"anyOf": []interface{}{
+			map[string]interface{}{"type": "number"},
+			map[string]interface{}{"enum": []interface{}{"NaN", "Infinity", "-Infinity"}},
+		},
+	}
+}
+
+// bytesSchema returns the schema of bytes, which protojson reads from
+// standard or URL-safe base64, with or without padding.
+func bytesSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":            "string",
+		"contentEncoding": "base64",
+		"pattern":         "^[A-Za-z0-9+/_-]*=*$",
+	}
+}
+
+func enumSchema(enum protoreflect.EnumDescriptor) map[string]interface{} {
+	// google.protobuf.NullValue is represented by null.
+	if enum.FullName() == "google.protobuf.NullValue" {
+		return map[string]interface{}{"type": "null"}
+	}
+
+	values := enum.Values()
+	names := make([]interface{}, values.Len())
+	for index := range names {
+		names[index] = string(values.Get(index).Name())
+	}
+
+	return map[string]interface{}{
+		"anyOf": []interface{}{
+			map[string]interface{}{"type": "string", "enum": names},
+			integerSchema(math.MinInt32, math.MaxInt32),
+		},
+	}
+}
+
+// mapKeySchema returns the schema of the keys of a map field, which are
+// always strings in json, or nil if any string is a valid key.
+func mapKeySchema(key protoreflect.FieldDescriptor) map[string]interface{} {
+	switch key.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]interface{}{"enum": []interface{}{"true", "false"}}
+	case protoreflect.StringKind:
+		return nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return map[string]interface{}{"pattern": "^[0-9]+$"}
+	default:
+		return map[string]interface{}{"pattern": "^-?[0-9]+$"}
+	}
+}
+
+// wellKnownSchema returns the schema of the json representation of a
+// well-known type, or nil if the message is not one.
+func wellKnownSchema(message protoreflect.MessageDescriptor) map[string]interface{} {
+	name := string(message.FullName())
+	if !strings.HasPrefix(name, "google.protobuf.") {
+		return nil
+	}
+
+	switch strings.TrimPrefix(name, "google.protobuf.") {
+	case "Timestamp":
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case "Duration":
+		return map[string]interface{}{"type": "string", "pattern": `^-?[0-9]+(\.[0-9]{1,9})?s$`}
+	case "FieldMask":
+		return map[string]interface{}{"type": "string"}
+	case "Struct":
+		return map[string]interface{}{"type": "object"}
+	case "ListValue":
+		return map[string]interface{}{"type": "array"}
+	case "Value":
+		return map[string]interface{}{}
+	case "Empty":
+		return map[string]interface{}{"type": "object", "additionalProperties": false}
+	case "Any":
+		return map[string]interface{}{
+			"type":       "object",
+			"required":   []string{"@type"},
+			"properties": map[string]interface{}{"@type": map[string]interface{}{"type": "string"}},
+		}
+	case "BoolValue":
+		return map[string]interface{}{"type": "boolean"}
+	case "StringValue":
+		return map[string]interface{}{"type": "string"}
+	case "BytesValue":
+		return bytesSchema()
+	case "Int32Value":
+		return integerSchema(math.MinInt32, math.MaxInt32)
+	case "UInt32Value":
+		return integerSchema(0, math.MaxUint32)
+	case "Int64Value":
+		return int64Schema(true)
+	case "UInt64Value":
+		return int64Schema(false)
+	case "FloatValue", "DoubleValue":
+		return floatSchema()
+	}
+
+	return nil
+}
+
+// leadingComment returns the comment before the declaration of a descriptor
+// in its proto file, if the descriptor was built with source information.
+func leadingComment(descriptor protoreflect.Descriptor) string {
+	location := descriptor.ParentFile().SourceLocations().ByDescriptor(descriptor)
+	return strings.TrimSpace(location.LeadingComments)
+}
@@ -0,0 +1,207 @@
+package protoschema_test
+
+import (
+	"testing"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/protoschema"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	_ "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// orderFile describes the following proto file:
+//
+//	syntax = "proto3";
+//	package shop;
+//	import "google/protobuf/timestamp.proto";
+//
+//	message Order {
+//	  enum Status { NEW = 0; PAID = 1; }
+//	  message Item { string sku = 1; uint32 quantity = 2; }
+//	  string order_id = 1;
+//	  int64 amount = 2;
+//	  repeated Item items = 3;
+//	  Status status = 4;
+//	  map<string, int32> counts = 5;
+//	  google.protobuf.Timestamp created = 6;
+//	  oneof payment { string card = 7; string iban = 8; }
+//	  Order parent = 9;
+//	  double rate = 10;
+//	  bytes signature = 11;
+//	}
+func orderFile() *descriptorpb.FileDescriptorProto {
+	field := func(name string, number int32, fieldType descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		descriptor := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Type:   fieldType.Enum(),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+		if typeName != "" {
+			descriptor.TypeName = proto.String(typeName)
+		}
+		return descriptor
+	}
+
+	orderId := field("order_id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, "")
+	items := field("items", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".shop.Order.Item")
+	items.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	counts := field("counts", 5, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".shop.Order.CountsEntry")
+	counts.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	card := field("card", 7, descriptorpb.FieldDescriptorProto_TYPE_STRING, "")
+	card.OneofIndex = proto.Int32(0)
+	iban := field("iban", 8, descriptorpb.FieldDescriptorProto_TYPE_STRING, "")
+	iban.OneofIndex = proto.Int32(0)
+
+	return &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("shop/order.proto"),
+		Package:    proto.String("shop"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/timestamp.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Order"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				orderId,
+				field("amount", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+				items,
+				field("status", 4, descriptorpb.FieldDescriptorProto_TYPE_ENUM, ".shop.Order.Status"),
+				counts,
+				field("created", 6, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Timestamp"),
+				card,
+				iban,
+				field("parent", 9, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".shop.Order"),
+				field("rate", 10, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, ""),
+				field("signature", 11, descriptorpb.FieldDescriptorProto_TYPE_BYTES, ""),
+			},
+			NestedType: []*descriptorpb.DescriptorProto{
+				{
+					Name: proto.String("Item"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						field("sku", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+						field("quantity", 2, descriptorpb.FieldDescriptorProto_TYPE_UINT32, ""),
+					},
+				},
+				{
+					Name: proto.String("CountsEntry"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						field("key", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+						field("value", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32, ""),
+					},
+					Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+				},
+			},
+			EnumType: []*descriptorpb.EnumDescriptorProto{{
+				Name: proto.String("Status"),
+				Value: []*descriptorpb.EnumValueDescriptorProto{
+					{Name: proto.String("NEW"), Number: proto.Int32(0)},
+					{Name: proto.String("PAID"), Number: proto.Int32(1)},
+				},
+			}},
+			OneofDecl: []*descriptorpb.OneofDescriptorProto{{Name: proto.String("payment")}},
+		}},
+	}
+}
+
+func orderDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	file, err := protodesc.NewFile(orderFile(), protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return file.Messages().ByName("Order")
+}
+
+func TestConvert(t *testing.T) {
+	descriptor := orderDescriptor(t)
+
+	schema, err := protoschema.Convert(descriptor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootSchema, err := jsonvalidator.NewRootJsonSchema(schema)
+	if err != nil {
+		t.Fatalf("%v: %s", err, schema)
+	}
+	validator := jsonvalidator.NewValidator(rootSchema)
+
+	// A message that protojson writes is valid.
+	message := dynamicpb.NewMessage(descriptor)
+	fields := descriptor.Fields()
+	message.Set(fields.ByName("order_id"), protoreflect.ValueOfString("o-1"))
+	message.Set(fields.ByName("amount"), protoreflect.ValueOfInt64(1<<40))
+	message.Set(fields.ByName("status"), protoreflect.ValueOfEnum(1))
+	message.Set(fields.ByName("card"), protoreflect.ValueOfString("4111"))
+	message.Set(fields.ByName("rate"), protoreflect.ValueOfFloat64(0.25))
+	message.Set(fields.ByName("signature"), protoreflect.ValueOfBytes([]byte{0xfb, 0xff}))
+	item := message.Mutable(fields.ByName("items")).List().NewElement()
+	item.Message().Set(item.Message().Descriptor().Fields().ByName("quantity"), protoreflect.ValueOfUint32(3))
+	message.Mutable(fields.ByName("items")).List().Append(item)
+	message.Mutable(fields.ByName("counts")).Map().Set(protoreflect.ValueOfString("a").MapKey(), protoreflect.ValueOfInt32(-2))
+	parent := message.Mutable(fields.ByName("parent")).Message()
+	parent.Set(fields.ByName("rate"), protoreflect.ValueOfFloat64(1))
+
+	bytes, err := protojson.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validator.Validate(bytes); err != nil {
+		t.Errorf("%s: unexpected error %v", bytes, err)
+	}
+
+	tests := []struct {
+		json  string
+		valid bool
+	}{
+		{`{}`, true},
+		{`{"orderId": null, "items": null, "status": null}`, true},
+		{`{"amount": 12, "status": 7, "rate": "NaN", "created": "2024-01-02T03:04:05Z"}`, true},
+		{`{"amount": "-12", "items": [{"sku": "a", "quantity": 1}], "counts": {"b": 1}}`, true},
+		{`{"parent": {"parent": {"orderId": "x"}}}`, true},
+		{`{"order_id": "x"}`, false},
+		{`{"amount": "12a"}`, false},
+		{`{"amount": 1.5}`, false},
+		{`{"status": "SHIPPED"}`, false},
+		{`{"items": [{"quantity": -1}]}`, false},
+		{`{"counts": {"b": 2147483648}}`, false},
+		{`{"created": "yesterday"}`, false},
+		{`{"card": "4111", "iban": "DE00"}`, false},
+		{`{"rate": "fast"}`, false},
+		{`{"parent": {"unknown": 1}}`, false},
+	}
+
+	for _, test := range tests {
+		err := validator.Validate([]byte(test.json))
+		if test.valid && err != nil {
+			t.Errorf("%s: unexpected error %v", test.json, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%s: expected an error", test.json)
+		}
+	}
+}
+
+func TestConvertUseProtoNames(t *testing.T) {
+	schema, err := protoschema.New().UseProtoNames(true).Id("http://example.com/order.json").Convert(orderDescriptor(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootSchema, err := jsonvalidator.NewRootJsonSchema(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := jsonvalidator.NewValidator(rootSchema)
+	if err := validator.Validate([]byte(`{"order_id": "x"}`)); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := validator.Validate([]byte(`{"orderId": "x"}`)); err == nil {
+		t.Error("expected the json name to be rejected")
+	}
+}
@@ -0,0 +1,408 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry is a set of root schemas that references can point to, by the
+// URIs that they are registered under. A root schema is compiled in a
+// registry, which owns it: the schema is registered there under its "$id",
+// and its references are resolved in the registry before the
+// DefaultRegistry, so schemas of different registries (like the schemas of
+// different tenants) may have the same "$id". Validators also consult their
+// registries (see Validator.Registries()) before the registry of their
+// schema, so a registry can override some of the schemas that a shared base
+// references, like the leaf schemas that a tenant customizes. A Registry is
+// safe for concurrent use.
+type Registry struct {
+	mutex   sync.RWMutex
+	schemas map[string]*RootJsonSchema
+
+	// generation is incremented whenever the schemas of the registry
+	// change, so the results of validations that were cached before are
+	// not reused (see Validator.Cache()).
+	generation uint64
+
+	// The loader of the schemas that references point to, or nil if they
+	// are not loaded (see SetSchemaLoader()).
+	loader SchemaLoader
+
+	// compilations deduplicates the concurrent compilations of
+	// GetOrCompile().
+	compilations *compileGroup
+}
+
+// DefaultRegistry is the registry of the package-level functions, like
+// NewRootJsonSchema(), AddResource() and LoadRootJsonSchema(). References
+// that are not found in the registry of a schema are resolved in it.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		schemas:      map[string]*RootJsonSchema{},
+		compilations: &compileGroup{calls: map[string]*compileCall{}},
+	}
+}
+
+// AddSchema creates a RootJsonSchema from the schema document in bytes in
+// the registry, and registers it under the given URI, and under its "$id"
+// if it has one and no other schema of the registry has it. A schema
+// without "$id" resolves its own relative references against the URI. A
+// schema that was registered under the same URI before is replaced.
+func (r *Registry) AddSchema(uri string, bytes []byte) (*RootJsonSchema, error) {
+	// The URI of a schema resource has no fragment.
+	uri = resourceURI(uri)
+
+	previous, _ := r.Get(uri)
+	return r.replace(previous, []string{uri}, bytes, uri, "")
+}
+
+// RemoveSchema removes the schema that is registered under the given URI
+// from the registry, under all the URIs that it is registered under.
+// References to it are not resolved in the registry anymore.
+func (r *Registry) RemoveSchema(uri string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	rootSchema, ok := r.schemas[resourceURI(uri)]
+	if !ok {
+		return
+	}
+
+	for schemaURI, registered := range r.schemas {
+		if registered == rootSchema {
+			delete(r.schemas, schemaURI)
+		}
+	}
+	r.generation++
+}
+
+// Compile creates a RootJsonSchema from the schema document in bytes in the
+// registry, like AddSchema() without a URI, and resolves its references up
+// front with RootJsonSchema.Compile(). A schema whose references fail to
+// resolve is not left in the registry.
+func (r *Registry) Compile(bytes []byte) (*RootJsonSchema, error) {
+	rootSchema, err := newRootJsonSchema(r, bytes, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	err = rootSchema.Compile()
+	if err != nil {
+		if registered, _ := r.Get(rootSchema.id()); registered == rootSchema {
+			r.unregister(rootSchema.id())
+		}
+		return nil, err
+	}
+
+	return rootSchema, nil
+}
+
+// Validate validates the json document in bytes against the schema that is
+// registered under the given URI, with the default options of a Validator.
+// It returns an InvalidReferenceError if no schema is registered under the
+// URI.
+func (r *Registry) Validate(uri string, bytes []byte) error {
+	rootSchema, ok := r.Get(uri)
+	if !ok {
+		return InvalidReferenceError{
+			schemaURI: resourceURI(uri),
+			err:       "could not find the root schema in the registry",
+		}
+	}
+
+	return NewValidator(rootSchema).Validate(bytes)
+}
+
+// Register registers a root schema in the registry under the given URI.
+func (r *Registry) Register(uri string, rootSchema *RootJsonSchema) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.schemas[resourceURI(uri)] = rootSchema
+	r.generation++
+}
+
+// Get returns the root schema that is registered in the registry under the
+// given URI.
+func (r *Registry) Get(uri string) (*RootJsonSchema, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	rootSchema, ok := r.schemas[resourceURI(uri)]
+	return rootSchema, ok
+}
+
+// Schemas returns the root schemas of the registry by the URIs that they
+// are registered under. A schema may be registered under more than one URI.
+func (r *Registry) Schemas() map[string]*RootJsonSchema {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	schemas := make(map[string]*RootJsonSchema, len(r.schemas))
+	for uri, rootSchema := range r.schemas {
+		if uri != "" {
+			schemas[uri] = rootSchema
+		}
+	}
+
+	return schemas
+}
+
+// replace creates a RootJsonSchema that replaces a schema of the registry
+// (which may be nil), and registers it under the given URIs. The schema is
+// compiled in a scratch copy of the registry without the replaced schema,
+// so it takes the "$id" of the replaced schema, and it is moved to the
+// registry with the schemas that its compilation registered in one step.
+// Concurrent lookups find either the replaced schema or the new one, and
+// the registry is left unchanged if the schema fails to compile.
+func (r *Registry) replace(replaced *RootJsonSchema, uris []string, bytes []byte, retrievalURI string, draft string) (*RootJsonSchema, error) {
+	rootSchema, scratch, err := r.compileAside(replaced, bytes, retrievalURI, draft)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, uri := range uris {
+		scratch.schemas[resourceURI(uri)] = rootSchema
+	}
+	r.moveLocked(scratch)
+
+	return rootSchema, nil
+}
+
+// compileAside creates a RootJsonSchema in a scratch copy of the registry,
+// without the given schema (which may be nil). The registry is not changed
+// until the schema is moved to it with moveLocked().
+func (r *Registry) compileAside(without *RootJsonSchema, bytes []byte, retrievalURI string, draft string) (*RootJsonSchema, *Registry, error) {
+	scratch := NewRegistry()
+	r.mutex.RLock()
+	scratch.loader = r.loader
+	for uri, rootSchema := range r.schemas {
+		if rootSchema != without {
+			scratch.schemas[uri] = rootSchema
+		}
+	}
+	r.mutex.RUnlock()
+
+	rootSchema, err := newRootJsonSchema(scratch, bytes, retrievalURI, draft)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rootSchema, scratch, nil
+}
+
+// moveLocked registers the schemas that were compiled in a scratch registry
+// of compileAside() in the registry, under the URIs that they are
+// registered under in the scratch registry. The caller holds the lock of the
+// registry.
+func (r *Registry) moveLocked(scratch *Registry) {
+	var compiled []*RootJsonSchema
+	for uri, registered := range scratch.schemas {
+		if registered.registry == scratch {
+			r.schemas[uri] = registered
+			compiled = append(compiled, registered)
+		}
+	}
+
+	for _, registered := range compiled {
+		registered.registry = r
+	}
+	r.generation++
+}
+
+// registerIfAbsent registers a root schema under the given URI, unless
+// another schema is registered under it. It returns true if the schema was
+// registered.
+func (r *Registry) registerIfAbsent(uri string, rootSchema *RootJsonSchema) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	uri = resourceURI(uri)
+	if _, ok := r.schemas[uri]; ok {
+		return false
+	}
+
+	r.schemas[uri] = rootSchema
+	r.generation++
+	return true
+}
+
+// unregister removes the registration of the given URI.
+func (r *Registry) unregister(uri string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.schemas, resourceURI(uri))
+	r.generation++
+}
+
+// urisOf returns the URIs that a root schema is registered under.
+func (r *Registry) urisOf(rootSchema *RootJsonSchema) []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var uris []string
+	for uri, registered := range r.schemas {
+		if registered == rootSchema {
+			uris = append(uris, uri)
+		}
+	}
+
+	return uris
+}
+
+// The version of the format of registry snapshots.
+const snapshotVersion = 1
+
+// registrySnapshot is the json form of a registry snapshot.
+type registrySnapshot struct {
+	Version int                    `json:"version"`
+	Schemas []registrySnapshotItem `json:"schemas"`
+}
+
+// registrySnapshotItem is a root schema of a registry snapshot, with the
+// URIs that it is registered under and the URI that it was retrieved from.
+type registrySnapshotItem struct {
+	URIs         []string        `json:"uris"`
+	RetrievalURI string          `json:"retrievalURI,omitempty"`
+	Schema       json.RawMessage `json:"schema"`
+}
+
+// Snapshot serializes the root schemas of the registry, with the URIs that
+// they are registered under, so the registry can be persisted or shipped to
+// another process and recreated by Restore(). The snapshot is deterministic:
+// the schemas are sorted by their first URI, and their URIs are sorted.
+func (r *Registry) Snapshot() ([]byte, error) {
+	r.mutex.RLock()
+	uris := map[*RootJsonSchema][]string{}
+	for uri, rootSchema := range r.schemas {
+		uris[rootSchema] = append(uris[rootSchema], uri)
+	}
+	r.mutex.RUnlock()
+
+	snapshot := registrySnapshot{Version: snapshotVersion, Schemas: []registrySnapshotItem{}}
+	for rootSchema, schemaURIs := range uris {
+		sort.Strings(schemaURIs)
+		snapshot.Schemas = append(snapshot.Schemas, registrySnapshotItem{
+			URIs:         schemaURIs,
+			RetrievalURI: rootSchema.retrievalURI,
+			Schema:       rootSchema.Document(),
+		})
+	}
+	sort.Slice(snapshot.Schemas, func(i, j int) bool {
+		return snapshot.Schemas[i].URIs[0] < snapshot.Schemas[j].URIs[0]
+	})
+
+	return json.Marshal(snapshot)
+}
+
+// Restore replaces the root schemas of the registry with the schemas of a
+// snapshot that Snapshot() created. The schemas are compiled again, and the
+// registry is left unchanged if any of them fails to compile.
+func (r *Registry) Restore(bytes []byte) error {
+	var snapshot registrySnapshot
+	err := json.Unmarshal(bytes, &snapshot)
+	if err != nil {
+		return err
+	}
+	if snapshot.Version != snapshotVersion {
+		return errors.New("unsupported registry snapshot version " + strconv.Itoa(snapshot.Version))
+	}
+
+	// The schemas are compiled in a registry of their own, and they are
+	// moved to the receiver once all of them compiled.
+	restored := NewRegistry()
+	restored.loader = r.schemaLoader()
+	schemas := map[string]*RootJsonSchema{}
+	for _, item := range snapshot.Schemas {
+		rootSchema, err := newRootJsonSchema(restored, item.Schema, item.RetrievalURI, "")
+		if err != nil {
+			return err
+		}
+
+		for _, uri := range item.URIs {
+			schemas[resourceURI(uri)] = rootSchema
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, rootSchema := range schemas {
+		rootSchema.registry = r
+	}
+	r.schemas = schemas
+	r.generation++
+	return nil
+}
+
+// registries are the registries that references are resolved in, in
+// priority order, which are consulted before the DefaultRegistry, and the
+// root schema that the references are resolved from. A root schema without
+// "$id" is not registered, so the references without a schema URI in it
+// (like "#/definitions/x") point to the root schema itself.
+type registries struct {
+	root *RootJsonSchema
+	list []*Registry
+}
+
+// lookup returns the root schema that is registered under the URI in the
+// first registry that has one, or in the DefaultRegistry. The empty URI is
+// the URI of the root schema if it has no "$id".
+func (rs registries) lookup(uri string) (*RootJsonSchema, bool) {
+	if resourceURI(uri) == "" && rs.root != nil && rs.root.id() == "" {
+		return rs.root, true
+	}
+
+	for _, registry := range rs.list {
+		if registry == nil {
+			continue
+		}
+		if rootSchema, ok := registry.Get(uri); ok {
+			return rootSchema, true
+		}
+	}
+
+	return DefaultRegistry.Get(uri)
+}
+
+// generation returns a number that changes whenever the schemas of any of
+// the registries, or of the DefaultRegistry, change. The generations of the
+// registries only grow, so their sum never repeats.
+func (rs registries) generation() uint64 {
+	generation := DefaultRegistry.currentGeneration()
+	for _, registry := range rs.list {
+		if registry != nil {
+			generation += registry.currentGeneration()
+		}
+	}
+
+	return generation
+}
+
+// currentGeneration returns the generation of the registry.
+func (r *Registry) currentGeneration() uint64 {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.generation
+}
+
+// resourceURI returns the URI of a schema resource, which has no fragment.
+func resourceURI(uri string) string {
+	if index := strings.Index(uri, "#"); index >= 0 {
+		return uri[:index]
+	}
+
+	return uri
+}
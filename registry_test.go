@@ -0,0 +1,325 @@
+package jsonvalidator
+
+import "testing"
+
+func TestValidatorRegistries(t *testing.T) {
+	base := NewRegistry()
+	_, err := base.AddSchema("http://example.com/registry/leaf.json", []byte(`{"type": "string"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = base.AddSchema("http://example.com/registry/common.json", []byte(`{
+		"definitions": {"name": {"$ref": "leaf.json"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tenant := NewRegistry()
+	_, err = tenant.AddSchema("http://example.com/registry/leaf.json", []byte(`{"type": "string", "maxLength": 3}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/registry/root.json",
+		"properties": {"name": {"$ref": "common.json#/definitions/name"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := RegisteredSchemas()["http://example.com/registry/leaf.json"]; ok {
+		t.Errorf("expected the schemas of the registries not to be registered globally")
+	}
+
+	tests := []struct {
+		registries []*Registry
+		document   string
+		valid      bool
+	}{
+		{[]*Registry{base}, `{"name": "Alice"}`, true},
+		{[]*Registry{base}, `{"name": 1}`, false},
+		{[]*Registry{tenant, base}, `{"name": "Bob"}`, true},
+		{[]*Registry{tenant, base}, `{"name": "Alice"}`, false},
+		{[]*Registry{base, tenant}, `{"name": "Alice"}`, true},
+	}
+
+	for index, test := range tests {
+		err := NewValidator(rootSchema).Registries(test.registries...).Validate([]byte(test.document))
+		if test.valid != (err == nil) {
+			t.Errorf("%d: %s: expected valid=%t, got %v", index, test.document, test.valid, err)
+		}
+	}
+
+	// Without the registries the references cannot be resolved.
+	err = NewValidator(rootSchema).Validate([]byte(`{"name": "Alice"}`))
+	if _, ok := err.(InvalidReferenceError); !ok {
+		t.Errorf("expected an InvalidReferenceError, got %v", err)
+	}
+}
+
+func TestRegistrySnapshot(t *testing.T) {
+	registry := NewRegistry()
+	_, err := registry.AddSchema("http://example.com/snapshot/leaf.json", []byte(`{"type": "string", "maxLength": 3}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = registry.AddSchema("http://example.com/snapshot/common.json", []byte(`{
+		"$id": "http://example.com/snapshot/common-v1.json",
+		"definitions": {"name": {"$ref": "leaf.json"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := registry.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := NewRegistry()
+	err = restored.Restore(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, uri := range []string{
+		"http://example.com/snapshot/leaf.json",
+		"http://example.com/snapshot/common.json",
+		"http://example.com/snapshot/common-v1.json",
+	} {
+		if _, ok := restored.Get(uri); !ok {
+			t.Errorf("expected %s to be restored", uri)
+		}
+	}
+
+	common, _ := restored.Get("http://example.com/snapshot/common.json")
+	if other, _ := restored.Get("http://example.com/snapshot/common-v1.json"); other != common {
+		t.Errorf("expected the URIs of a schema to share the restored schema")
+	}
+
+	rootSchema, err := NewRootJsonSchema([]byte(`{"$ref": "http://example.com/snapshot/common.json#/definitions/name"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator := NewValidator(rootSchema).Registries(restored)
+	if err := validator.Validate([]byte(`"Bob"`)); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := validator.Validate([]byte(`"Alice"`)); err == nil {
+		t.Errorf("expected the restored leaf schema to reject a long name")
+	}
+
+	again, err := restored.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(snapshot) {
+		t.Errorf("expected the snapshot of the restored registry to equal the original snapshot:\n%s\n%s", snapshot, again)
+	}
+}
+
+func TestRegistryRestoreFailure(t *testing.T) {
+	registry := NewRegistry()
+	_, err := registry.AddSchema("http://example.com/restore/leaf.json", []byte(`{"type": "string"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := []string{
+		`{"version": 2, "schemas": []}`,
+		`{"version": 1, "schemas": [{"uris": ["http://example.com/restore/bad.json"], "schema": {"items": 5}}]}`,
+		`[]`,
+	}
+	for _, snapshot := range invalid {
+		if err := registry.Restore([]byte(snapshot)); err == nil {
+			t.Errorf("%s: expected an error", snapshot)
+		}
+	}
+
+	if _, ok := registry.Get("http://example.com/restore/leaf.json"); !ok {
+		t.Errorf("expected a failed restore to leave the registry unchanged")
+	}
+}
+
+func TestRegistryAddSchemaReplace(t *testing.T) {
+	const uri = "http://example.com/replace/alias.json"
+	const id = "http://example.com/replace/leaf.json"
+
+	registry := NewRegistry()
+	_, err := registry.AddSchema(uri, []byte(`{"$id": "`+id+`", "type": "string"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	common, err := registry.AddSchema("http://example.com/replace/common.json", []byte(`{"$ref": "`+uri+`"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The references to the replaced schema resolve while it is replaced.
+	validator := NewValidator(common)
+	done := make(chan struct{})
+	failed := make(chan error, 1)
+	go func() {
+		defer close(failed)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if err := validator.Validate([]byte(`"a"`)); err != nil {
+				failed <- err
+				return
+			}
+		}
+	}()
+
+	var replacement *RootJsonSchema
+	for i := 0; i < 100; i++ {
+		replacement, err = registry.AddSchema(uri, []byte(`{"$id": "`+id+`", "type": "string", "maxLength": 3}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(done)
+	if err := <-failed; err != nil {
+		t.Errorf("unexpected error while the schema was replaced: %v", err)
+	}
+
+	// The replacement takes the "$id" of the replaced schema.
+	for _, registeredURI := range []string{uri, id} {
+		if registered, _ := registry.Get(registeredURI); registered != replacement {
+			t.Errorf("expected the replacement to be registered under %s", registeredURI)
+		}
+	}
+
+	// A replacement that fails to compile leaves the registry unchanged.
+	if _, err := registry.AddSchema(uri, []byte(`{"$id": "`+id+`", "items": 5}`)); err == nil {
+		t.Error("expected an error for an invalid schema")
+	}
+	for _, registeredURI := range []string{uri, id} {
+		if registered, _ := registry.Get(registeredURI); registered != replacement {
+			t.Errorf("expected a failed replacement to leave the schema of %s", registeredURI)
+		}
+	}
+}
+
+func TestRegistrySchemas(t *testing.T) {
+	schemas := map[string]string{
+		"a": `{"type": "string"}`,
+		"b": `{"type": "integer"}`,
+	}
+
+	tenants := map[string]*Registry{}
+	for tenant, leaf := range schemas {
+		registry := NewRegistry()
+		_, err := registry.AddSchema("http://example.com/tenant/leaf.json", []byte(leaf))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The root schemas of both tenants have the same "$id".
+		_, err = registry.Compile([]byte(`{
+			"$id": "http://example.com/tenant/root.json",
+			"properties": {"value": {"$ref": "leaf.json"}}
+		}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tenants[tenant] = registry
+	}
+
+	if _, ok := RegisteredSchemas()["http://example.com/tenant/root.json"]; ok {
+		t.Errorf("expected the schemas of the registries not to be registered globally")
+	}
+
+	tests := []struct {
+		tenant   string
+		document string
+		valid    bool
+	}{
+		{"a", `{"value": "x"}`, true},
+		{"a", `{"value": 1}`, false},
+		{"b", `{"value": 1}`, true},
+		{"b", `{"value": "x"}`, false},
+	}
+	for _, test := range tests {
+		err := tenants[test.tenant].Validate("http://example.com/tenant/root.json", []byte(test.document))
+		if test.valid != (err == nil) {
+			t.Errorf("%s: %s: expected valid=%t, got %v", test.tenant, test.document, test.valid, err)
+		}
+	}
+
+	// A schema that is removed is not resolved anymore.
+	tenants["a"].RemoveSchema("http://example.com/tenant/leaf.json")
+	if _, ok := tenants["a"].Get("http://example.com/tenant/leaf.json"); ok {
+		t.Errorf("expected the removed schema not to be registered")
+	}
+	err := tenants["a"].Validate("http://example.com/tenant/root.json", []byte(`{"value": "x"}`))
+	if _, ok := err.(InvalidReferenceError); !ok {
+		t.Errorf("expected an InvalidReferenceError, got %v", err)
+	}
+
+	err = tenants["a"].Validate("http://example.com/tenant/missing.json", []byte(`{}`))
+	if _, ok := err.(InvalidReferenceError); !ok {
+		t.Errorf("expected an InvalidReferenceError for a missing schema, got %v", err)
+	}
+
+	// References that do not resolve in the registry fail the compilation.
+	_, err = tenants["a"].Compile([]byte(`{
+		"$id": "http://example.com/tenant/broken.json",
+		"$ref": "leaf.json"
+	}`))
+	if _, ok := err.(SchemaCompilationErrors); !ok {
+		t.Errorf("expected SchemaCompilationErrors, got %v", err)
+	}
+	if _, ok := tenants["a"].Get("http://example.com/tenant/broken.json"); ok {
+		t.Errorf("expected the schema that failed to compile not to be registered")
+	}
+}
+
+func TestSchemasWithoutID(t *testing.T) {
+	first, err := NewRootJsonSchema([]byte(`{
+		"definitions": {"a": {"type": "string"}},
+		"properties": {"x": {"$ref": "#/definitions/a"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := NewRootJsonSchema([]byte(`{
+		"definitions": {"a": {"type": "integer"}},
+		"properties": {"x": {"$ref": "#/definitions/a"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := DefaultRegistry.Get(""); ok {
+		t.Errorf("expected the schemas without \"$id\" not to be registered")
+	}
+
+	tests := []struct {
+		rootSchema *RootJsonSchema
+		document   string
+		valid      bool
+	}{
+		{first, `{"x": "a"}`, true},
+		{first, `{"x": 1}`, false},
+		{second, `{"x": 1}`, true},
+		{second, `{"x": "a"}`, false},
+	}
+	for index, test := range tests {
+		err := test.rootSchema.Validate([]byte(test.document))
+		if test.valid != (err == nil) {
+			t.Errorf("%d: %s: expected valid=%t, got %v", index, test.document, test.valid, err)
+		}
+	}
+
+	// Each schema resolves its references in itself.
+	if schema, err := second.Resolve("#/definitions/a"); err != nil || schema.Type == nil || string(schema.Type.raw) != `"integer"` {
+		t.Errorf("expected the reference to resolve in the second schema, got %v", err)
+	}
+}
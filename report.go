@@ -0,0 +1,163 @@
+package jsonvalidator
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+// The ANSI escape codes of the colorized reports.
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+)
+
+// ReportOptions are the options of Result.Report().
+type ReportOptions struct {
+	// Color colorizes the report with ANSI escape codes, for terminals.
+	Color bool
+
+	// Indent is the indentation of every level of the report, which is two
+	// spaces if it is empty.
+	Indent string
+}
+
+// reportNode is a value of the validated document in the tree of a report.
+type reportNode struct {
+	token    string
+	title    string
+	errors   []ValidationError
+	children map[string]*reportNode
+}
+
+// Report formats the errors of the result as a human-readable tree, for
+// CLIs and logs. The failures are grouped by the values that failed, which
+// are nested like in the document, and the values are labeled with the
+// titles of the schemas that they failed against, when the schemas have
+// titles. For example:
+//
+//	1 error
+//	/
+//	  address
+//	    city (City)
+//	      - minLength: inspected string is less than 2
+func (r *Result) Report(options ReportOptions) string {
+	if options.Indent == "" {
+		options.Indent = "  "
+	}
+
+	var builder strings.Builder
+	if r.Valid() {
+		builder.WriteString(colorize("valid", ansiGreen, options.Color) + "\n")
+		return builder.String()
+	}
+
+	summary := strconv.Itoa(len(r.errors)) + " errors"
+	if len(r.errors) == 1 {
+		summary = "1 error"
+	}
+	builder.WriteString(colorize(summary, ansiRed, options.Color) + "\n")
+
+	root := &reportNode{token: "/"}
+	for _, validationError := range r.errors {
+		node := root
+		if validationError.InstanceLocation != "" {
+			for _, token := range strings.Split(validationError.InstanceLocation[1:], "/") {
+				node = node.child(jsonwalker.UnescapeToken(token))
+			}
+		}
+
+		node.errors = append(node.errors, validationError)
+		if node.title == "" {
+			node.title = r.schemaTitle(validationError)
+		}
+	}
+
+	root.write(&builder, 0, options)
+	return builder.String()
+}
+
+// child returns the child node of a token, which is created if it does not
+// exist.
+func (n *reportNode) child(token string) *reportNode {
+	if n.children == nil {
+		n.children = map[string]*reportNode{}
+	}
+
+	child, ok := n.children[token]
+	if !ok {
+		child = &reportNode{token: token}
+		n.children[token] = child
+	}
+
+	return child
+}
+
+// write writes the node, its errors and its children at the given depth.
+// The children are written in the order of their tokens, where array
+// indexes are ordered numerically.
+func (n *reportNode) write(builder *strings.Builder, depth int, options ReportOptions) {
+	label := n.token
+	if n.title != "" {
+		label += " (" + n.title + ")"
+	}
+	builder.WriteString(strings.Repeat(options.Indent, depth) + colorize(label, ansiBold, options.Color) + "\n")
+
+	for _, validationError := range n.errors {
+		keyword := validationError.Keyword
+		if keyword == "" {
+			keyword = "false schema"
+		}
+
+		// The keyword is already the label of the failure, so it is not
+		// repeated in the message.
+		message := strings.TrimPrefix(validationError.Message, "\""+validationError.Keyword+"\" validation failed, reason: ")
+		builder.WriteString(strings.Repeat(options.Indent, depth+1) + "- " +
+			colorize(keyword, ansiRed, options.Color) + ": " + message + "\n")
+	}
+
+	tokens := make([]string, 0, len(n.children))
+	for token := range n.children {
+		tokens = append(tokens, token)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		x, xErr := strconv.Atoi(tokens[i])
+		y, yErr := strconv.Atoi(tokens[j])
+		if xErr == nil && yErr == nil {
+			return x < y
+		}
+		return tokens[i] < tokens[j]
+	})
+
+	for _, token := range tokens {
+		n.children[token].write(builder, depth+1, options)
+	}
+}
+
+// schemaTitle returns the title of the schema that holds the failing keyword
+// of an error, or an empty string if it has none.
+func (r *Result) schemaTitle(validationError ValidationError) string {
+	location := validationError.AbsoluteKeywordLocation
+	if index := strings.LastIndex(location, "/"); index >= 0 && validationError.Keyword != "" {
+		location = location[:index]
+	}
+
+	schema, err := r.schema.Resolve(location)
+	if err != nil || schema == nil || schema.Title == nil {
+		return ""
+	}
+
+	return string(*schema.Title)
+}
+
+func colorize(text string, color string, enabled bool) string {
+	if !enabled {
+		return text
+	}
+
+	return color + text + ansiReset
+}
@@ -0,0 +1,71 @@
+package jsonvalidator
+
+import "testing"
+
+func TestReport(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/report/person.json",
+		"title": "Person",
+		"type": "object",
+		"properties": {
+			"address": {
+				"properties": {
+					"city": {"title": "City", "type": "string", "minLength": 2}
+				}
+			},
+			"tags": {"items": {"type": "string"}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := NewValidator(rootSchema)
+	testCases := []struct {
+		document string
+		options  ReportOptions
+		expected string
+	}{
+		{
+			`{"address": {"city": "A"}}`,
+			ReportOptions{},
+			"1 error\n" +
+				"/\n" +
+				"  address\n" +
+				"    city (City)\n" +
+				"      - minLength: inspected string is less than 2\n",
+		},
+		{
+			`{"tags": ["a", 1]}`,
+			ReportOptions{Indent: "\t"},
+			"1 error\n" +
+				"/\n" +
+				"\ttags\n" +
+				"\t\t1\n" +
+				"\t\t\t- type: inspected value expected to be a json string\n",
+		},
+		{
+			`5`,
+			ReportOptions{Color: true},
+			"\x1b[31m1 error\x1b[0m\n" +
+				"\x1b[1m/ (Person)\x1b[0m\n" +
+				"  - \x1b[31mtype\x1b[0m: inspected value expected to be a json object\n",
+		},
+		{
+			`{}`,
+			ReportOptions{Color: true},
+			"\x1b[32mvalid\x1b[0m\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		result, err := validator.ValidateResult([]byte(testCase.document))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if report := result.Report(testCase.options); report != testCase.expected {
+			t.Errorf("%s: expected the report\n%q, got\n%q", testCase.document, testCase.expected, report)
+		}
+	}
+}
@@ -0,0 +1,543 @@
+package jsonvalidator
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// The output formats of Result.OutputJSON(). OUTPUT_FLAG, OUTPUT_BASIC,
+// OUTPUT_DETAILED and OUTPUT_VERBOSE are defined in the "Output Formatting"
+// section of the json schema specification, and OUTPUT_AJV is the error
+// format of ajv, the json schema validator of Node.js.
+const (
+	OUTPUT_FLAG     = "flag"
+	OUTPUT_BASIC    = "basic"
+	OUTPUT_DETAILED = "detailed"
+	OUTPUT_VERBOSE  = "verbose"
+	OUTPUT_AJV      = "ajv"
+)
+
+// ValidationError describes a validation failure of a json value.
+type ValidationError struct {
+	// InstanceLocation is the json pointer of the value that failed in
+	// validation.
+	InstanceLocation string
+
+	// KeywordLocation and AbsoluteKeywordLocation are the locations of the
+	// failing keyword, as returned by SchemaValidationError.KeywordLocation()
+	// and SchemaValidationError.AbsoluteKeywordLocation().
+	KeywordLocation         string
+	AbsoluteKeywordLocation string
+
+	// Keyword is the name of the failing keyword, or an empty string if the
+	// value was rejected by a "false" schema.
+	Keyword string
+
+	// Message describes the failure.
+	Message string
+
+	// params holds the details of the failure that depend on the validated
+	// value, as reported by the failing keyword.
+	params map[string]interface{}
+
+	// scopes are the schemas that the value and its parents were validated
+	// against, which nest the error in the hierarchical output formats.
+	scopes []outputScope
+}
+
+// Warning describes a problem that did not fail the validation, for example
+// a "format" keyword with an unknown format.
+type Warning struct {
+	InstanceLocation string
+	KeywordLocation  string
+	Keyword          string
+	Message          string
+}
+
+// Annotation is the value of an annotation keyword ("title", "description",
+// "default", "examples", "readOnly", "writeOnly" or "deprecated", and
+// "format" if the format is disabled by Validator.DisableFormats()) of a
+// schema that a json value is valid against. "contains" is annotated with
+// the indices of the array items that matched its schema.
+type Annotation struct {
+	InstanceLocation        string
+	KeywordLocation         string
+	AbsoluteKeywordLocation string
+	Keyword                 string
+
+	// Value is the value of the keyword in the schema. The value of
+	// "default" is given as a json.RawMessage, and the value of "contains"
+	// is the []int of the matching indices.
+	Value interface{}
+
+	// scopes are the schemas that the value and its parents were validated
+	// against, like ValidationError.scopes.
+	scopes []outputScope
+}
+
+// InstanceAnnotations are the annotations that applied to a single value of
+// a document, merged from all the schemas that the value is valid against.
+// When more than one schema annotates the value with the same keyword, the
+// schema that is the closest to the root schema wins, and the boolean
+// keywords are true if any of the schemas sets them.
+type InstanceAnnotations struct {
+	Title       string
+	Description string
+	Default     json.RawMessage
+	Examples    []interface{}
+	ReadOnly    bool
+	WriteOnly   bool
+	Deprecated  bool
+
+	// The depths of the keyword locations of the annotations that were
+	// merged, by their keywords.
+	depths map[string]int
+}
+
+// merge merges an annotation of the value into the annotations.
+func (a *InstanceAnnotations) merge(annotation Annotation) {
+	switch annotation.Keyword {
+	case "readOnly":
+		a.ReadOnly = a.ReadOnly || annotation.Value.(bool)
+		return
+	case "writeOnly":
+		a.WriteOnly = a.WriteOnly || annotation.Value.(bool)
+		return
+	case "deprecated":
+		a.Deprecated = a.Deprecated || annotation.Value.(bool)
+		return
+	}
+
+	depth := strings.Count(annotation.KeywordLocation, "/")
+	if merged, ok := a.depths[annotation.Keyword]; ok && merged <= depth {
+		return
+	}
+
+	switch annotation.Keyword {
+	case "title":
+		a.Title = annotation.Value.(string)
+	case "description":
+		a.Description = annotation.Value.(string)
+	case "default":
+		a.Default = annotation.Value.(json.RawMessage)
+	case "examples":
+		a.Examples = annotation.Value.([]interface{})
+	default:
+		return
+	}
+
+	if a.depths == nil {
+		a.depths = map[string]int{}
+	}
+	a.depths[annotation.Keyword] = depth
+}
+
+// Result is the outcome of Validator.ValidateResult().
+type Result struct {
+	schema      *RootJsonSchema
+	errors      []ValidationError
+	warnings    []Warning
+	annotations []Annotation
+	branches    []Branch
+}
+
+// ValidateResult validates the json document in bytes against the validator's
+// schema, like Validate(), and returns the result of the validation with the
+// annotations that were collected on the way. Unlike Validate(), the
+// validation does not stop at the first failure, so the result holds all
+// the failures of the document, and they can be reported in one pass.
+// It returns an error only if the document could not be validated, for
+// example because it is not a valid json document, or because the
+// validation was aborted.
+func (v *Validator) ValidateResult(bytes []byte) (*Result, error) {
+	ctx := newValidationContext(v, len(bytes))
+	ctx.collectsOutput = true
+
+	result := &Result{schema: v.schema}
+
+	err := v.validate(bytes, ctx)
+	if err != nil {
+		if _, ok := err.(SchemaValidationError); !ok {
+			return nil, err
+		}
+
+		// The properties of objects are validated in no particular order,
+		// so the failures are sorted by the locations of their values.
+		sort.SliceStable(ctx.errors, func(i, j int) bool {
+			return ctx.errors[i].path < ctx.errors[j].path
+		})
+
+		for _, schemaValidationError := range ctx.errors {
+			result.errors = append(result.errors, ValidationError{
+				InstanceLocation:        schemaValidationError.path,
+				KeywordLocation:         schemaValidationError.keywordLocation,
+				AbsoluteKeywordLocation: schemaValidationError.absoluteKeywordLocation,
+				Keyword:                 schemaValidationError.keyword,
+				Message:                 schemaValidationError.err,
+				params:                  schemaValidationError.params,
+				scopes:                  schemaValidationError.scopes,
+			})
+		}
+	} else {
+		// Annotations are dropped when the validation fails, as the
+		// specification requires.
+		result.annotations = ctx.annotations
+	}
+
+	result.warnings = ctx.warnings
+	result.branches = ctx.branches
+	return result, nil
+}
+
+// ValidateOutput validates the json document in bytes like ValidateResult(),
+// and returns the result in the output format that was set with
+// OutputFormat(), or in OUTPUT_BASIC if none was set, like
+// Result.OutputJSON().
+func (v *Validator) ValidateOutput(bytes []byte) ([]byte, error) {
+	result, err := v.ValidateResult(bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	format := v.outputFormat
+	if format == "" {
+		format = OUTPUT_BASIC
+	}
+
+	return result.OutputJSON(format)
+}
+
+// Valid returns true if the document is valid against the schema.
+func (r *Result) Valid() bool {
+	return len(r.errors) == 0
+}
+
+// Errors returns all the validation failures of the document, ordered by
+// the json pointers of the values that failed.
+func (r *Result) Errors() []ValidationError {
+	return r.errors
+}
+
+// Warnings returns the problems that were found during the validation but did
+// not fail it.
+func (r *Result) Warnings() []Warning {
+	return r.warnings
+}
+
+// Annotations returns the annotations of the schemas that the values of a
+// valid document are valid against. It returns nil for an invalid document.
+func (r *Result) Annotations() []Annotation {
+	return r.annotations
+}
+
+// AnnotationsAt returns the annotations of the value at the given json
+// pointer of a valid document, in the order that they were collected.
+func (r *Result) AnnotationsAt(instanceLocation string) []Annotation {
+	var annotations []Annotation
+	for _, annotation := range r.annotations {
+		if annotation.InstanceLocation == instanceLocation {
+			annotations = append(annotations, annotation)
+		}
+	}
+
+	return annotations
+}
+
+// InstanceAnnotations returns the merged annotations of the values of a
+// valid document, by the json pointers of the values, for tools like form
+// generators that describe every value by the schemas that apply to it.
+// Values that no schema annotated are not listed. It returns nil for an
+// invalid document.
+func (r *Result) InstanceAnnotations() map[string]InstanceAnnotations {
+	if len(r.annotations) == 0 {
+		return nil
+	}
+
+	merged := map[string]*InstanceAnnotations{}
+	for _, annotation := range r.annotations {
+		instanceAnnotations, ok := merged[annotation.InstanceLocation]
+		if !ok {
+			instanceAnnotations = &InstanceAnnotations{}
+			merged[annotation.InstanceLocation] = instanceAnnotations
+		}
+		instanceAnnotations.merge(annotation)
+	}
+
+	annotations := make(map[string]InstanceAnnotations, len(merged))
+	for instanceLocation, instanceAnnotations := range merged {
+		instanceAnnotations.depths = nil
+		annotations[instanceLocation] = *instanceAnnotations
+	}
+
+	return annotations
+}
+
+// Branches returns the conditional branches that were applied to the values
+// of the document, as reported to a BranchFunc.
+func (r *Result) Branches() []Branch {
+	return r.branches
+}
+
+// outputUnit is a single error or annotation of the "basic" output format.
+type outputUnit struct {
+	KeywordLocation         string      `json:"keywordLocation"`
+	AbsoluteKeywordLocation string      `json:"absoluteKeywordLocation,omitempty"`
+	InstanceLocation        string      `json:"instanceLocation"`
+	Error                   string      `json:"error,omitempty"`
+	Annotation              interface{} `json:"annotation,omitempty"`
+}
+
+// output is the root of the "flag" and "basic" output formats.
+type output struct {
+	Valid       bool         `json:"valid"`
+	Errors      []outputUnit `json:"errors,omitempty"`
+	Annotations []outputUnit `json:"annotations,omitempty"`
+}
+
+// OutputJSON returns the result in one of the output formats: OUTPUT_FLAG,
+// which holds only the validation result, OUTPUT_BASIC, which also holds a
+// flat list of the errors of an invalid document or the annotations of a
+// valid one, OUTPUT_DETAILED and OUTPUT_VERBOSE, which nest the errors or
+// the annotations in the schemas that produced them (see outputTree()), or
+// OUTPUT_AJV, which is an array of the errors in the shape of ajv's errors
+// (empty for a valid document), so the errors can be handled by the same
+// code as the errors of Node.js services.
+// It returns an InvalidOutputFormatError if the format is not supported.
+func (r *Result) OutputJSON(format string) ([]byte, error) {
+	out := output{Valid: r.Valid()}
+
+	switch format {
+	case OUTPUT_DETAILED, OUTPUT_VERBOSE:
+		return json.Marshal(r.outputTree(format == OUTPUT_DETAILED))
+	case OUTPUT_AJV:
+		errors, err := r.ajvErrors()
+		if err != nil {
+			return nil, err
+		}
+
+		// The comparisons of ajv's messages are written as they are, like
+		// JSON.stringify() writes them.
+		var buffer bytes.Buffer
+		encoder := json.NewEncoder(&buffer)
+		encoder.SetEscapeHTML(false)
+		err = encoder.Encode(errors)
+		return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), err
+	case OUTPUT_FLAG:
+	case OUTPUT_BASIC:
+		for _, err := range r.errors {
+			out.Errors = append(out.Errors, outputUnit{
+				KeywordLocation:         err.KeywordLocation,
+				AbsoluteKeywordLocation: err.AbsoluteKeywordLocation,
+				InstanceLocation:        err.InstanceLocation,
+				Error:                   err.Message,
+			})
+		}
+
+		for _, annotation := range r.annotations {
+			out.Annotations = append(out.Annotations, outputUnit{
+				KeywordLocation:         annotation.KeywordLocation,
+				AbsoluteKeywordLocation: annotation.AbsoluteKeywordLocation,
+				InstanceLocation:        annotation.InstanceLocation,
+				Annotation:              annotation.Value,
+			})
+		}
+	default:
+		return nil, InvalidOutputFormatError(format)
+	}
+
+	return json.Marshal(out)
+}
+
+// outputNode is a node of the "detailed" and "verbose" output formats: a
+// schema that was applied to a value, or an error or an annotation of one
+// of its keywords.
+type outputNode struct {
+	Valid                   bool          `json:"valid"`
+	KeywordLocation         string        `json:"keywordLocation"`
+	AbsoluteKeywordLocation string        `json:"absoluteKeywordLocation,omitempty"`
+	InstanceLocation        string        `json:"instanceLocation"`
+	Error                   string        `json:"error,omitempty"`
+	Annotation              interface{}   `json:"annotation,omitempty"`
+	Errors                  []*outputNode `json:"errors,omitempty"`
+	Annotations             []*outputNode `json:"annotations,omitempty"`
+
+	// scopes are the nodes of the schemas under the node, which are in
+	// Errors or in Annotations too.
+	scopes map[outputScope]*outputNode
+}
+
+// outputTree returns the errors of an invalid document, or the annotations
+// of a valid one, nested in the schemas that the values were validated
+// against when they were produced, from the root schema down, following
+// the path of the validation (including "$ref" hops). Schemas that produced
+// neither errors nor annotations are not listed. If condensed is true, as
+// in the "detailed" format, schemas that hold a single node are replaced by
+// the node.
+func (r *Result) outputTree(condensed bool) *outputNode {
+	root := &outputNode{
+		Valid:                   r.Valid(),
+		AbsoluteKeywordLocation: r.schema.id() + "#",
+	}
+
+	for _, err := range r.errors {
+		scope := root.scopeOf(err.scopes)
+
+		// A "false" schema fails by itself, rather than by one of its
+		// keywords.
+		if err.KeywordLocation == scope.KeywordLocation && err.InstanceLocation == scope.InstanceLocation {
+			scope.Error = err.Message
+			continue
+		}
+
+		scope.add(&outputNode{
+			KeywordLocation:         err.KeywordLocation,
+			AbsoluteKeywordLocation: err.AbsoluteKeywordLocation,
+			InstanceLocation:        err.InstanceLocation,
+			Error:                   err.Message,
+		})
+	}
+
+	for _, annotation := range r.annotations {
+		root.scopeOf(annotation.scopes).add(&outputNode{
+			Valid:                   true,
+			KeywordLocation:         annotation.KeywordLocation,
+			AbsoluteKeywordLocation: annotation.AbsoluteKeywordLocation,
+			InstanceLocation:        annotation.InstanceLocation,
+			Annotation:              annotation.Value,
+		})
+	}
+
+	if condensed {
+		root.condense()
+	}
+
+	return root
+}
+
+// scopeOf returns the node of the innermost of the scopes under the node,
+// and creates the nodes of the scopes that do not exist yet. The scope of
+// the root schema is the node itself.
+func (n *outputNode) scopeOf(scopes []outputScope) *outputNode {
+	node := n
+	for _, scope := range scopes {
+		if scope.keywordLocation == "" && scope.instanceLocation == "" {
+			continue
+		}
+
+		child, ok := node.scopes[scope]
+		if !ok {
+			child = &outputNode{
+				Valid:                   n.Valid,
+				KeywordLocation:         scope.keywordLocation,
+				AbsoluteKeywordLocation: scope.absoluteKeywordLocation,
+				InstanceLocation:        scope.instanceLocation,
+			}
+			if node.scopes == nil {
+				node.scopes = map[outputScope]*outputNode{}
+			}
+			node.scopes[scope] = child
+			node.add(child)
+		}
+		node = child
+	}
+
+	return node
+}
+
+// add adds a child node to the errors of the node if it is invalid, or to
+// its annotations if it is valid.
+func (n *outputNode) add(child *outputNode) {
+	if child.Valid {
+		n.Annotations = append(n.Annotations, child)
+	} else {
+		n.Errors = append(n.Errors, child)
+	}
+}
+
+// condense replaces the children of the node that hold a single node, and
+// have no error or annotation of their own, with that node.
+func (n *outputNode) condense() {
+	for _, children := range [][]*outputNode{n.Errors, n.Annotations} {
+		for index, child := range children {
+			child.condense()
+			if child.Error == "" && child.Annotation == nil && len(child.Errors)+len(child.Annotations) == 1 {
+				children[index] = append(child.Errors, child.Annotations...)[0]
+			}
+		}
+	}
+}
+
+// recordAnnotations records the annotation keywords of a schema that the
+// value at jsonPath is valid against.
+func (ctx *validationContext) recordAnnotations(jsonPath string, js *JsonSchema) {
+	if js.Title != nil {
+		ctx.recordAnnotation(jsonPath, "title", string(*js.Title))
+	}
+
+	if js.Description != nil {
+		ctx.recordAnnotation(jsonPath, "description", string(*js.Description))
+	}
+
+	if js.Default != nil {
+		ctx.recordAnnotation(jsonPath, "default", json.RawMessage(js.Default))
+	}
+
+	if js.Examples != nil {
+		ctx.recordAnnotation(jsonPath, "examples", []interface{}(js.Examples))
+	}
+
+	if js.ReadOnly != nil {
+		ctx.recordAnnotation(jsonPath, "readOnly", bool(*js.ReadOnly))
+	}
+
+	if js.WriteOnly != nil {
+		ctx.recordAnnotation(jsonPath, "writeOnly", bool(*js.WriteOnly))
+	}
+
+	if js.Deprecated != nil {
+		ctx.recordAnnotation(jsonPath, "deprecated", bool(*js.Deprecated))
+	}
+
+	if js.Format != nil && ctx.validator.disabledFormats[string(*js.Format)] {
+		ctx.recordAnnotation(jsonPath, "format", string(*js.Format))
+	}
+
+	// The content keywords are annotations unless they are asserted in
+	// strict content mode.
+	if js.ContentEncoding != nil && !ctx.validator.strictContent {
+		ctx.recordAnnotation(jsonPath, "contentEncoding", string(*js.ContentEncoding))
+	}
+
+	if js.ContentMediaType != nil && !ctx.validator.strictContent {
+		ctx.recordAnnotation(jsonPath, "contentMediaType", js.ContentMediaType.mediaType)
+	}
+}
+
+func (ctx *validationContext) recordAnnotation(jsonPath, keyword string, value interface{}) {
+	location := ctx.location(keyword)
+	ctx.annotations = append(ctx.annotations, Annotation{
+		InstanceLocation:        jsonPath,
+		KeywordLocation:         location.keywordLocation,
+		AbsoluteKeywordLocation: location.absoluteKeywordLocation,
+		Keyword:                 keyword,
+		Value:                   value,
+		scopes:                  ctx.outputScopes(),
+	})
+}
+
+// warn records a warning about the given keyword of the currently validated
+// schema, if the output of the validation is collected.
+func (ctx *validationContext) warn(jsonPath, keyword, message string) {
+	if !ctx.collectsOutput {
+		return
+	}
+
+	ctx.warnings = append(ctx.warnings, Warning{
+		InstanceLocation: jsonPath,
+		KeywordLocation:  ctx.location(keyword).keywordLocation,
+		Keyword:          keyword,
+		Message:          message,
+	})
+}
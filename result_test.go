@@ -0,0 +1,462 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestValidateResult(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/result.json",
+		"title": "person",
+		"properties": {
+			"name": {"type": "string", "description": "the full name"},
+			"email": {"type": "string", "format": "no-such-format"},
+			"age": {"anyOf": [{"type": "string", "title": "text"}, {"type": "integer", "minimum": 0, "title": "number"}]}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := NewValidator(rootSchema)
+
+	result, err := validator.ValidateResult([]byte(`{"name": "Bob", "email": "bob", "age": 30}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Valid() || len(result.Errors()) != 0 {
+		t.Errorf("expected a valid result, got %v", result.Errors())
+	}
+
+	annotations := map[string]interface{}{}
+	for _, annotation := range result.Annotations() {
+		annotations[annotation.InstanceLocation+" "+annotation.KeywordLocation] = annotation.Value
+	}
+	expected := map[string]interface{}{
+		" /title":                            "person",
+		"/name /properties/name/description": "the full name",
+		"/age /properties/age/anyOf/1/title": "number",
+	}
+	if len(annotations) != len(expected) {
+		t.Errorf("expected %d annotations, got %v", len(expected), annotations)
+	}
+	for key, value := range expected {
+		if annotations[key] != value {
+			t.Errorf("%s: expected annotation %v, got %v", key, value, annotations[key])
+		}
+	}
+
+	warnings := result.Warnings()
+	if len(warnings) != 1 || warnings[0].InstanceLocation != "/email" || warnings[0].KeywordLocation != "/properties/email/format" {
+		t.Errorf("expected a warning about the unknown format, got %v", warnings)
+	}
+
+	result, err = validator.ValidateResult([]byte(`{"name": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Valid() || result.Annotations() != nil {
+		t.Errorf("expected an invalid result without annotations, got %v", result.Annotations())
+	}
+
+	errors := result.Errors()
+	if len(errors) != 1 || errors[0].InstanceLocation != "/name" || errors[0].Keyword != "type" ||
+		errors[0].AbsoluteKeywordLocation != "http://example.com/result.json#/properties/name/type" {
+		t.Errorf("unexpected errors %v", errors)
+	}
+
+	if _, err := validator.ValidateResult([]byte(`{`)); err == nil {
+		t.Error("expected an error for an invalid json document")
+	}
+}
+
+func TestValidateResultAllErrors(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"type": "object",
+		"required": ["id", "name", "email", "phone"],
+		"additionalProperties": false,
+		"properties": {
+			"id": {"type": "integer"},
+			"name": {"type": "string", "minLength": 2, "pattern": "^[a-z]"},
+			"tags": {"items": {"type": "string"}},
+			"kind": {"anyOf": [{"const": "a"}, {"const": "b"}]},
+			"email": {"not": {"maxLength": 3}},
+			"phone": {"type": "string"}
+		},
+		"allOf": [{"properties": {"id": {"minimum": 1}}}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := NewValidator(rootSchema)
+
+	result, err := validator.ValidateResult([]byte(`{"id": 0, "name": "B", "tags": ["a", 2, 3], "kind": "a", "emial": "x"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var actual []string
+	for _, err := range result.Errors() {
+		actual = append(actual, err.InstanceLocation+" "+err.KeywordLocation)
+	}
+	expected := []string{
+		" /required",
+		" /required",
+		"/id /allOf/0/properties/id/minimum",
+		"/emial /additionalProperties",
+		"/name /properties/name/minLength",
+		"/name /properties/name/pattern",
+		"/tags/1 /properties/tags/items/type",
+		"/tags/2 /properties/tags/items/type",
+	}
+	sort.Strings(actual)
+	sort.Strings(expected)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected the errors %q, got %q", expected, actual)
+	}
+
+	for _, err := range result.Errors() {
+		if err.InstanceLocation == "/emial" && !strings.Contains(err.Message, `did you mean "email"`) {
+			t.Errorf("expected a suggestion for the additional property, got %q", err.Message)
+		}
+	}
+
+	// The failing branches of "anyOf" and "not" are not failures of the
+	// document.
+	result, err = validator.ValidateResult([]byte(`{"id": 1, "name": "bob", "email": "bob@example.com", "phone": "1", "kind": "b"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Valid() {
+		t.Errorf("expected a valid result, got %v", result.Errors())
+	}
+
+	// Validate() still stops at the first failure.
+	if err := validator.Validate([]byte(`{"id": 0, "name": 1}`)); err == nil {
+		t.Error("expected a validation error")
+	}
+}
+
+func TestContainsAnnotation(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{"contains": {"type": "integer", "title": "number"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewValidator(rootSchema).ValidateResult([]byte(`["a", 1, "b", 2]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var matched interface{}
+	titles := 0
+	for _, annotation := range result.Annotations() {
+		switch annotation.Keyword {
+		case "contains":
+			matched = annotation.Value
+		case "title":
+			titles++
+		}
+	}
+	if !reflect.DeepEqual(matched, []int{1, 3}) {
+		t.Errorf("expected the matching indices [1 3], got %v", matched)
+	}
+	if titles != 2 {
+		t.Errorf("expected the annotations of the 2 matching items, got %d", titles)
+	}
+}
+
+func TestResultInstanceAnnotations(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id": "http://example.com/result/order.json",
+		"title": "order",
+		"properties": {
+			"id": {"$ref": "#/$defs/id", "title": "order id", "readOnly": true},
+			"note": {"description": "a note", "default": "", "examples": ["rush"], "deprecated": true},
+			"token": {"writeOnly": true, "minLength": 2}
+		},
+		"$defs": {"id": {"title": "identifier", "description": "an id", "type": "string"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := NewValidator(rootSchema)
+	result, err := validator.ValidateResult([]byte(`{"id": "a", "note": "x", "token": "secret"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The title of the referencing schema is closer to the root schema than
+	// the title of the referenced one.
+	expected := map[string]InstanceAnnotations{
+		"":       {Title: "order"},
+		"/id":    {Title: "order id", Description: "an id", ReadOnly: true},
+		"/note":  {Description: "a note", Default: json.RawMessage(`""`), Examples: []interface{}{"rush"}, Deprecated: true},
+		"/token": {WriteOnly: true},
+	}
+	if annotations := result.InstanceAnnotations(); !reflect.DeepEqual(annotations, expected) {
+		t.Errorf("expected the annotations %+v, got %+v", expected, annotations)
+	}
+
+	var keywords []string
+	for _, annotation := range result.AnnotationsAt("/id") {
+		keywords = append(keywords, annotation.Keyword)
+	}
+	sort.Strings(keywords)
+	if !reflect.DeepEqual(keywords, []string{"description", "readOnly", "title", "title"}) {
+		t.Errorf("unexpected annotations of /id: %v", keywords)
+	}
+
+	result, err = validator.ValidateResult([]byte(`{"token": "s"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if annotations := result.InstanceAnnotations(); annotations != nil {
+		t.Errorf("expected no annotations for an invalid document, got %+v", annotations)
+	}
+}
+
+func TestResultOutputJSON(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{"properties": {"a": {"title": "a", "minimum": 2}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := NewValidator(rootSchema)
+	valid, _ := validator.ValidateResult([]byte(`{"a": 3}`))
+	invalid, _ := validator.ValidateResult([]byte(`{"a": 1}`))
+
+	testCases := []struct {
+		result   *Result
+		format   string
+		expected string
+	}{
+		{valid, OUTPUT_FLAG, `{"valid":true}`},
+		{invalid, OUTPUT_FLAG, `{"valid":false}`},
+		{
+			valid,
+			OUTPUT_BASIC,
+			`{"valid":true,"annotations":[{"keywordLocation":"/properties/a/title",` +
+				`"absoluteKeywordLocation":"#/properties/a/title","instanceLocation":"/a","annotation":"a"}]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		output, err := testCase.result.OutputJSON(testCase.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(output) != testCase.expected {
+			t.Errorf("expected %s, got %s", testCase.expected, output)
+		}
+	}
+
+	output, err := invalid.OutputJSON(OUTPUT_BASIC)
+	if err != nil || !strings.Contains(string(output), `"instanceLocation":"/a"`) ||
+		!strings.Contains(string(output), `"keywordLocation":"/properties/a/minimum"`) {
+		t.Errorf("unexpected basic output %s, %v", output, err)
+	}
+
+	if _, err := valid.OutputJSON("compact"); err == nil {
+		t.Error("expected an error for an unsupported output format")
+	}
+}
+
+// outputLocations returns the keyword and instance locations of the nodes of
+// a "detailed" or "verbose" output, indented by their depth.
+func outputLocations(node map[string]interface{}, indent string) []string {
+	locations := []string{indent + node["keywordLocation"].(string) + " " + node["instanceLocation"].(string)}
+	for _, key := range []string{"errors", "annotations"} {
+		children, _ := node[key].([]interface{})
+		for _, child := range children {
+			locations = append(locations, outputLocations(child.(map[string]interface{}), indent+"  ")...)
+		}
+	}
+
+	return locations
+}
+
+func TestValidateOutputHierarchical(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id": "https://example.com/output/polygon",
+		"$defs": {
+			"point": {
+				"properties": {"x": {"type": "number"}, "y": {"type": "number"}},
+				"additionalProperties": false,
+				"required": ["x", "y"]
+			}
+		},
+		"title": "polygon",
+		"items": {"$ref": "#/$defs/point"},
+		"minItems": 3
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := `[{"x": 2.5, "y": 1.3}, {"x": 1, "z": 6.7}]`
+	testCases := []struct {
+		format    string
+		document  string
+		locations []string
+	}{
+		{
+			OUTPUT_DETAILED,
+			invalid,
+			[]string{
+				" ",
+				"  /minItems ",
+				"  /items/$ref /1",
+				"    /items/$ref/required /1",
+				"    /items/$ref/additionalProperties /1/z",
+			},
+		},
+		{
+			OUTPUT_VERBOSE,
+			invalid,
+			[]string{
+				" ",
+				"  /minItems ",
+				"  /items /1",
+				"    /items/$ref /1",
+				"      /items/$ref/required /1",
+				"      /items/$ref/additionalProperties /1/z",
+			},
+		},
+		{
+			OUTPUT_VERBOSE,
+			`[{"x": 1, "y": 2}, {"x": 3, "y": 4}, {"x": 5, "y": 6}]`,
+			[]string{
+				" ",
+				"  /title ",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		output, err := NewValidator(rootSchema).OutputFormat(testCase.format).ValidateOutput([]byte(testCase.document))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var root map[string]interface{}
+		err = json.Unmarshal(output, &root)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		locations := outputLocations(root, "")
+		if !reflect.DeepEqual(locations, testCase.locations) {
+			t.Errorf("%s output of %s: expected the nodes\n%s\ngot\n%s", testCase.format, testCase.document,
+				strings.Join(testCase.locations, "\n"), strings.Join(locations, "\n"))
+		}
+		if root["absoluteKeywordLocation"] != "https://example.com/output/polygon#" {
+			t.Errorf("%s output of %s: unexpected root %s", testCase.format, testCase.document, output)
+		}
+	}
+
+	// The output format of a validator is "basic" by default.
+	output, err := NewValidator(rootSchema).ValidateOutput([]byte(invalid))
+	if err != nil || !strings.HasPrefix(string(output), `{"valid":false,"errors":[`) {
+		t.Errorf("unexpected default output %s, %v", output, err)
+	}
+}
+
+func TestResultOutputJSONAjv(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/ajv.json",
+		"type": "object",
+		"required": ["id"],
+		"additionalProperties": false,
+		"properties": {
+			"id": {"type": ["integer", "string"]},
+			"age": {"$ref": "#/definitions/age"},
+			"name": {"maxLength": 3},
+			"tags": {"uniqueItems": true},
+			"role": {"enum": ["admin", "user"]},
+			"code": {"oneOf": [{"type": "string"}, {"minLength": 1}]}
+		},
+		"dependencies": {"name": ["age", "role"]},
+		"definitions": {"age": {"minimum": 0, "exclusiveMaximum": 150}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := NewValidator(rootSchema)
+	testCases := []struct {
+		document string
+		expected string
+	}{
+		{`{"id": 1}`, `[]`},
+		{
+			`{}`,
+			`[{"instancePath":"","schemaPath":"#/required","keyword":"required",` +
+				`"params":{"missingProperty":"id"},"message":"must have required property 'id'"}]`,
+		},
+		{
+			`{"id": true}`,
+			`[{"instancePath":"/id","schemaPath":"#/properties/id/type","keyword":"type",` +
+				`"params":{"type":["integer","string"]},"message":"must be integer,string"}]`,
+		},
+		{
+			`{"id": 1, "age": 150}`,
+			`[{"instancePath":"/age","schemaPath":"#/definitions/age/exclusiveMaximum","keyword":"exclusiveMaximum",` +
+				`"params":{"comparison":"<","limit":150},"message":"must be < 150"}]`,
+		},
+		{
+			`{"id": 1, "name": "Alice", "age": 1, "role": "user"}`,
+			`[{"instancePath":"/name","schemaPath":"#/properties/name/maxLength","keyword":"maxLength",` +
+				`"params":{"limit":3},"message":"must NOT have more than 3 characters"}]`,
+		},
+		{
+			`{"id": 1, "tags": [1, 2, 1]}`,
+			`[{"instancePath":"/tags","schemaPath":"#/properties/tags/uniqueItems","keyword":"uniqueItems",` +
+				`"params":{"i":0,"j":2},"message":"must NOT have duplicate items (items ## 2 and 0 are identical)"}]`,
+		},
+		{
+			`{"id": 1, "role": "guest"}`,
+			`[{"instancePath":"/role","schemaPath":"#/properties/role/enum","keyword":"enum",` +
+				`"params":{"allowedValues":["admin","user"]},"message":"must be equal to one of the allowed values"}]`,
+		},
+		{
+			`{"id": 1, "code": "x"}`,
+			`[{"instancePath":"/code","schemaPath":"#/properties/code/oneOf","keyword":"oneOf",` +
+				`"params":{"passingSchemas":[0,1]},"message":"must match exactly one schema in oneOf"}]`,
+		},
+		{
+			`{"id": 1, "name": "Bob", "age": 1}`,
+			`[{"instancePath":"","schemaPath":"#/dependencies","keyword":"dependencies",` +
+				`"params":{"deps":"age, role","depsCount":2,"missingProperty":"role","property":"name"},` +
+				`"message":"must have properties age, role when property name is present"}]`,
+		},
+		{
+			`{"id": 1, "nickname": "Bobby"}`,
+			`[{"instancePath":"","schemaPath":"#/additionalProperties","keyword":"additionalProperties",` +
+				`"params":{"additionalProperty":"nickname"},"message":"must NOT have additional properties"}]`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		result, err := validator.ValidateResult([]byte(testCase.document))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		output, err := result.OutputJSON(OUTPUT_AJV)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(output) != testCase.expected {
+			t.Errorf("%s: expected %s, got %s", testCase.document, testCase.expected, output)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package jsonvalidator
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/itayankri/gojsonvalidator/internal/strutil"
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+// RewriteFunc rewrites a sub-schema of a schema document in place. location
+// is the json pointer of the sub-schema in the document, and schema is the
+// decoded sub-schema (numbers are json.Numbers), whose keywords may be
+// changed, added or removed. Boolean schemas are not passed to it.
+type RewriteFunc func(location string, schema map[string]interface{}) error
+
+// The keywords whose values are schemas, lists of schemas or objects of
+// schemas.
+var (
+	schemaKeywords = []string{
+		"additionalItems", "items", "contains", "additionalProperties",
+		"propertyNames", "if", "then", "else", "not", "contentSchema",
+	}
+	schemaListKeywords = []string{"items", "prefixItems", "allOf", "anyOf", "oneOf"}
+	schemaMapKeywords  = []string{"definitions", "$defs", "properties", "patternProperties", "dependencies"}
+)
+
+// Rewrite calls rewrite with every sub-schema of the root schema's document,
+// parents before their sub-schemas (so the sub-schemas that rewrite adds are
+// rewritten too), and compiles the rewritten document into a new root
+// schema. For example, a rewrite can add "additionalProperties": false to
+// every object schema, or a company-wide "pattern" to every "id" property.
+// The rewritten schema replaces the receiver under all the URIs that the
+// receiver is registered under, so references (including the references of
+// other schemas) resolve into the rewritten schema. The receiver is not
+// changed, and it stays registered if the rewritten document fails to
+// compile, or if rewrite returns an error.
+func (rs *RootJsonSchema) Rewrite(rewrite RewriteFunc) (*RootJsonSchema, error) {
+	decoder := json.NewDecoder(bytes.NewReader(rs.document))
+	decoder.UseNumber()
+
+	var document interface{}
+	err := decoder.Decode(&document)
+	if err != nil {
+		return nil, err
+	}
+
+	err = rewriteSchemas(document, "", rewrite)
+	if err != nil {
+		return nil, err
+	}
+
+	rewritten, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+
+	// The rewritten schema is compiled without the receiver, so it is
+	// registered under its "$id" instead, and it replaces the receiver
+	// under all its URIs at once.
+	return rs.registry.replace(rs, rs.registry.urisOf(rs), rewritten, rs.retrievalURI, rs.draft)
+}
+
+// rewriteSchemas is a recursive function that calls rewrite with every
+// schema object in a schema value and its location, starting with the value
+// itself.
+func rewriteSchemas(value interface{}, location string, rewrite RewriteFunc) error {
+	schema, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	err := rewrite(location, schema)
+	if err != nil {
+		return err
+	}
+
+	keywords := make([]string, 0, len(schema))
+	for keyword := range schema {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	for _, keyword := range keywords {
+		keywordValue := schema[keyword]
+		keywordLocation := location + "/" + jsonwalker.EscapeToken(keyword)
+
+		list, isList := keywordValue.([]interface{})
+		switch {
+		case isList && strutil.Contains(schemaListKeywords, keyword):
+			for index, subSchema := range list {
+				err = rewriteSchemas(subSchema, keywordLocation+"/"+strconv.Itoa(index), rewrite)
+				if err != nil {
+					return err
+				}
+			}
+		case strutil.Contains(schemaKeywords, keyword):
+			err = rewriteSchemas(keywordValue, keywordLocation, rewrite)
+			if err != nil {
+				return err
+			}
+		case strutil.Contains(schemaMapKeywords, keyword):
+			subSchemas, _ := keywordValue.(map[string]interface{})
+			names := make([]string, 0, len(subSchemas))
+			for name := range subSchemas {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				err = rewriteSchemas(subSchemas[name], keywordLocation+"/"+jsonwalker.EscapeToken(name), rewrite)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package jsonvalidator
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRewrite(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/rewrite.json",
+		"definitions": {"id": {"type": "string"}},
+		"properties": {
+			"id": {"$ref": "#/definitions/id"},
+			"owner": {"type": "object", "properties": {"id": {"$ref": "#/definitions/id"}}}
+		},
+		"type": "object"
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var locations []string
+	rewritten, err := rootSchema.Rewrite(func(location string, schema map[string]interface{}) error {
+		locations = append(locations, location)
+		if schema["type"] == "object" {
+			schema["additionalProperties"] = false
+		}
+		if location == "/definitions/id" {
+			schema["pattern"] = "^[0-9a-f]{8}$"
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"",
+		"/definitions/id",
+		"/properties/id",
+		"/properties/owner",
+		"/properties/owner/properties/id",
+	}
+	if !reflect.DeepEqual(locations, expected) {
+		t.Errorf("expected the locations %v, got %v", expected, locations)
+	}
+
+	tests := []struct {
+		document string
+		valid    bool
+	}{
+		{`{"id": "0123abcd", "owner": {"id": "89abcdef"}}`, true},
+		{`{"id": "0123abcd", "extra": 1}`, false},
+		{`{"owner": {"id": "0123abcd", "extra": 1}}`, false},
+		{`{"owner": {"id": "not-an-id"}}`, false},
+	}
+	for _, test := range tests {
+		err := NewValidator(rewritten).Validate([]byte(test.document))
+		if test.valid != (err == nil) {
+			t.Errorf("%s: expected valid=%t, got %v", test.document, test.valid, err)
+		}
+	}
+
+	// References resolve into the rewritten schema.
+	if RegisteredSchemas()["http://example.com/rewrite.json"] != rewritten {
+		t.Errorf("expected the rewritten schema to replace the original schema")
+	}
+
+	// A failing rewrite keeps the registered schema.
+	failed := errors.New("failed")
+	if _, err := rewritten.Rewrite(func(string, map[string]interface{}) error { return failed }); err != failed {
+		t.Errorf("expected the error of the rewrite, got %v", err)
+	}
+	_, err = rewritten.Rewrite(func(location string, schema map[string]interface{}) error {
+		schema["minLength"] = "three"
+		return nil
+	})
+	if err == nil {
+		t.Errorf("expected a compilation error")
+	}
+	if RegisteredSchemas()["http://example.com/rewrite.json"] != rewritten {
+		t.Errorf("expected the schema to stay registered after a failed rewrite")
+	}
+}
+
+func TestRewriteConcurrentReferences(t *testing.T) {
+	registry := NewRegistry()
+	rootSchema, err := registry.AddSchema("http://example.com/rewrite/leaf.json", []byte(`{"type": "string"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	common, err := registry.AddSchema("http://example.com/rewrite/common.json", []byte(`{"$ref": "leaf.json"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The references to the rewritten schema resolve while it is
+	// compiled.
+	validator := NewValidator(common)
+	done := make(chan struct{})
+	failed := make(chan error, 1)
+	go func() {
+		defer close(failed)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if err := validator.Validate([]byte(`"a"`)); err != nil {
+				failed <- err
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		rootSchema, err = rootSchema.Rewrite(func(location string, schema map[string]interface{}) error {
+			schema["maxLength"] = 3
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(done)
+	if err := <-failed; err != nil {
+		t.Errorf("unexpected error while the schema was rewritten: %v", err)
+	}
+}
+
+func TestRewriteDraft2020Keywords(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id": "http://example.com/rewrite-2020.json",
+		"$defs": {"name": {"type": "string"}},
+		"prefixItems": [{"$ref": "#/$defs/name"}, {"type": "integer"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var locations []string
+	rewritten, err := rootSchema.Rewrite(func(location string, schema map[string]interface{}) error {
+		locations = append(locations, location)
+		if schema["type"] == "string" {
+			schema["maxLength"] = 3
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"", "/$defs/name", "/prefixItems/0", "/prefixItems/1"}
+	if !reflect.DeepEqual(locations, expected) {
+		t.Errorf("expected the locations %v, got %v", expected, locations)
+	}
+
+	if err := rewritten.Validate([]byte(`["Bob", 1]`)); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := rewritten.Validate([]byte(`["Alice", 1]`)); err == nil {
+		t.Errorf("expected the rewritten \"$defs\" schema to reject a long name")
+	}
+}
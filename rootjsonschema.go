@@ -2,69 +2,321 @@ package jsonvalidator
 
 import (
 	"encoding/json"
-	"fmt"
-)
+	"io"
+	"sort"
+	"sync"
 
-// This is a package-level dictionary that contains all the reference-able
-// root schema instances.
-var rootSchemaPool = map[string]*RootJsonSchema{}
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+	"github.com/pkg/errors"
+)
 
 // RootJsonSchema is struct that contains a JsonSchema embedded into it
 // (and therefore inherits all JsonSchema's methods) and a map of json path and
 // a pointer to JsonSchema instance called subSchemaMap.
 // subSchemaMap holds a record for each sub-schema that the root-schema contains.
+// A RootJsonSchema is not changed once it is compiled (apart from the
+// fragments that are compiled on demand, which are guarded by a mutex), so
+// it may be used by any number of goroutines at the same time, while other
+// schemas are compiled and registered.
 type RootJsonSchema struct {
 	JsonSchema
 	subSchemaMap map[string]*JsonSchema
+
+	// The URI that the schema was retrieved from, which identifies the
+	// schema if it has no "$id".
+	retrievalURI string
+
+	// The schema document, in which the fragments of references that were
+	// not mapped to sub-schemas are evaluated, and the sub-schemas that
+	// were found this way by their fragments.
+	document  json.RawMessage
+	fragments map[string]*JsonSchema
+
+	// The contradictions of the sub-schemas, which are found when the root
+	// schema is compiled.
+	contradictions []Contradiction
+
+	// The draft that the root schema was compiled with, and the locations
+	// of the sub-schemas that have anchors, by the names of the anchors.
+	draft   string
+	anchors map[string]schemaAnchor
+
+	// The sub-schemas that have references, which Compile() resolves.
+	references []scannedSchema
+
+	// The registry that the root schema was compiled in, where its
+	// references are resolved.
+	registry *Registry
 }
 
+// fragmentsMutex guards the fragments of all the root schemas, which are
+// added while documents are validated.
+var fragmentsMutex sync.Mutex
+
 // NewJsonSchema creates a new RootJsonSchema instance, Unmarshals the byte array
 // into the instance, and returns a pointer to the instance.
 func NewRootJsonSchema(bytes []byte) (*RootJsonSchema, error) {
-	var rootSchemaId string
+	return newRootJsonSchema(DefaultRegistry, bytes, "", "")
+}
+
+// NewRootJsonSchemaForDraft is like NewRootJsonSchema(), but the schema is
+// compiled with the given draft (one of the DRAFT_* constants), whatever
+// its "$schema" field declares. It returns an InvalidDraftError if the draft
+// is not supported.
+func NewRootJsonSchemaForDraft(draft string, bytes []byte) (*RootJsonSchema, error) {
+	return newRootJsonSchema(DefaultRegistry, bytes, "", draft)
+}
+
+// Compile creates a RootJsonSchema in the DefaultRegistry, and resolves its
+// references up front, like Registry.Compile().
+func Compile(bytes []byte) (*RootJsonSchema, error) {
+	return DefaultRegistry.Compile(bytes)
+}
+
+// AddResource creates a RootJsonSchema from the schema document in bytes and
+// registers it under the given URI, so other schemas can reference it (for
+// example "http://example.com/common.json#/definitions/address") even if it
+// has no "$id". A schema without "$id" also resolves its own relative
+// references against the URI. A schema that was registered under the same
+// URI before is replaced.
+func AddResource(uri string, bytes []byte) (*RootJsonSchema, error) {
+	return DefaultRegistry.AddSchema(uri, bytes)
+}
+
+// RegisteredSchemas returns the root schemas of the DefaultRegistry, which
+// references can point to, by the URIs that they are registered under:
+// their "$id", the URI that they were loaded from, or the URI that they were
+// added under with AddResource(). A schema may be registered under more
+// than one URI.
+func RegisteredSchemas() map[string]*RootJsonSchema {
+	return DefaultRegistry.Schemas()
+}
+
+// newRootJsonSchema creates a RootJsonSchema in the registry, that was
+// retrieved from the given URI, or from an unknown location if the URI is
+// empty. The schema is compiled with the given draft, or with the draft that
+// it declares if the draft is empty.
+func newRootJsonSchema(registry *Registry, bytes []byte, retrievalURI string, draft string) (*RootJsonSchema, error) {
 	var rootSchema *RootJsonSchema
 
+	// Schemas with a byte order mark, or in UTF-16 or UTF-32, are transcoded
+	// to UTF-8.
+	bytes, err := toUTF8(bytes)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if the string s is a valid json.
-	err := json.Unmarshal(bytes, &rootSchema)
+	err = json.Unmarshal(bytes, &rootSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	draft, err = resolveDraft(&rootSchema.JsonSchema, draft, registry)
 	if err != nil {
 		return nil, err
 	}
 
 	// Allocate space for the map in memory.
 	rootSchema.subSchemaMap = make(map[string]*JsonSchema)
+	rootSchema.retrievalURI = retrievalURI
+	rootSchema.document = append(json.RawMessage(nil), bytes...)
+	rootSchema.draft = draft
+	rootSchema.registry = registry
+
+	// Add the rootSchema to the registry under its $id, or under the URI
+	// that it was retrieved from if it has no $id. A schema that has
+	// neither is not registered, and its references without a schema URI
+	// are resolved in the schema itself (see registries.lookup()).
+	rootSchemaId := rootSchema.id()
+	registered := rootSchemaId != "" && registry.registerIfAbsent(rootSchemaId, rootSchema)
+
+	ctx := newCompilationContext(registry, rootSchema, rootSchemaId, draft)
+	err = rootSchema.scanSchema("", ctx)
+	if err != nil {
+		if registered {
+			registry.unregister(rootSchemaId)
+		}
+		return nil, err
+	}
+
+	rootSchema.anchors = ctx.anchors
 
-	// If the field $id in the rootSchema exists, add the rootSchema to the
-	// rootSchemaPool
-	if rootSchema.Id != nil {
-		rootSchemaId = string(*rootSchema.Id)
+	for _, s := range ctx.scanned {
+		if s.schema.Ref != nil || s.schema.DynamicRef != nil {
+			rootSchema.references = append(rootSchema.references, s)
+		}
 	}
-	//else {
-	//	fmt.Println("[RootJsonSchema DEBUG] created a RootJsonSchema instance with no $id")
-	//}
 
-	if _, ok := rootSchemaPool[rootSchemaId]; !ok {
-		rootSchemaPool[rootSchemaId] = rootSchema
+	rootSchema.contradictions = findContradictions(rootSchema, ctx.scanned)
+
+	// The schemas that the references point to are loaded once the root
+	// schema is registered, so references back to it are not loaded.
+	if loader := registry.schemaLoader(); loader != nil {
+		err = loadReferences(rootSchema, loader)
+		if err != nil {
+			if registered {
+				registry.unregister(rootSchemaId)
+			}
+			return nil, err
+		}
+	}
+
+	return rootSchema, nil
+}
+
+// SubSchemaPointers returns the fragments of the locations in the root
+// schema that can be referenced by "$ref": the sorted json pointers (in
+// their plain string representation) of the sub-schemas, starting with the
+// empty pointer of the root schema itself, followed by the sorted names of
+// the "$anchor" and "$dynamicAnchor" anchors (which "#name" references).
+func (rs *RootJsonSchema) SubSchemaPointers() []string {
+	pointers := make([]string, 0, len(rs.subSchemaMap))
+	for pointer := range rs.subSchemaMap {
+		pointers = append(pointers, pointer)
 	}
+	sort.Strings(pointers)
 
-	err = rootSchema.scanSchema("", rootSchemaId)
+	names := make([]string, 0, len(rs.anchors))
+	for name := range rs.anchors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return append(append([]string{""}, pointers...), names...)
+}
+
+// Resolve returns the schema that a "$ref" value points to, where references
+// without a schema URI (for example "#/definitions/x") are resolved against
+// the root schema. It returns an InvalidReferenceError if the referenced
+// schema does not exist.
+func (rs *RootJsonSchema) Resolve(reference string) (*JsonSchema, error) {
+	return ref(reference).resolveIn(rs.id(), rs.registries())
+}
+
+// Compile resolves all the references of the root schema (in "$ref", and
+// the schemas that "$dynamicRef" points to statically) against the schemas
+// that were created so far, so broken references are found when the schema
+// is loaded rather than when documents are validated. It also finds cycles
+// of references that validate a value against the same schema again
+// without advancing in it, whose validation would never end. It returns
+// SchemaCompilationErrors that list every unresolved or cyclic reference at
+// the json pointer of its keyword, or nil. Schemas that reference each
+// other are compiled once all of them were created.
+func (rs *RootJsonSchema) Compile() error {
+	return checkReferences(rs.references, rs.id(), rs.registries())
+}
+
+// Validate validates the json document in bytes against the root schema with
+// the default options of a Validator. It returns nil if the document is
+// valid, and a SchemaValidationError that describes the first failure if it
+// is not. Other errors are returned if the document is not a valid json
+// document. Use a Validator to set options, or Validator.ValidateResult() to
+// get all the failures of the document.
+func (rs *RootJsonSchema) Validate(bytes []byte) error {
+	return NewValidator(rs).Validate(bytes)
+}
+
+// ValidateInterface validates a Go value against the root schema, like
+// Validator.ValidateInterface().
+func (rs *RootJsonSchema) ValidateInterface(value interface{}) error {
+	return NewValidator(rs).ValidateInterface(value)
+}
+
+// ValidateReader validates the json document that is read from r against the
+// root schema, like Validator.ValidateReader().
+func (rs *RootJsonSchema) ValidateReader(r io.Reader) error {
+	return NewValidator(rs).ValidateReader(r)
+}
+
+// Document returns the json document that the root schema was created from.
+func (rs *RootJsonSchema) Document() []byte {
+	return append([]byte(nil), rs.document...)
+}
+
+// evaluateFragment returns the sub-schema at a json pointer of the root
+// schema that was not mapped when the schema was scanned (for example a
+// schema under an unknown keyword), by evaluating the pointer in the schema
+// document. The sub-schemas that are found are compiled once and cached.
+func (rs *RootJsonSchema) evaluateFragment(fragment string) (*JsonSchema, error) {
+	fragmentsMutex.Lock()
+	defer fragmentsMutex.Unlock()
+
+	if schema, ok := rs.fragments[fragment]; ok {
+		return schema, nil
+	}
+
+	pointer, err := jsonwalker.NewJsonPointer(fragment)
 	if err != nil {
-		fmt.Println("[RootJsonSchema DEBUG] scanSchema() " +
-			"failed: " + err.Error())
 		return nil, err
 	}
 
-	return rootSchema, nil
+	value, err := pointer.EvaluateUseNumber(rs.document)
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema *JsonSchema
+	err = json.Unmarshal(bytes, &schema)
+	if err != nil {
+		return nil, err
+	}
+	if schema == nil {
+		return nil, errors.New("the fragment does not point to a schema")
+	}
+
+	// The sub-schemas of the compiled schema are not mapped, because the
+	// subSchemaMap may be read concurrently.
+	err = schema.scanSchema(fragment, newCompilationContext(rs.registry, nil, "", rs.draft))
+	if err != nil {
+		return nil, err
+	}
+
+	if rs.fragments == nil {
+		rs.fragments = make(map[string]*JsonSchema)
+	}
+	rs.fragments[fragment] = schema
+
+	return schema, nil
+}
+
+// registries returns the registries that the references of the root schema
+// are resolved in.
+func (rs *RootJsonSchema) registries() registries {
+	return registries{rs, []*Registry{rs.registry}}
 }
 
-// validate calls RootJsonSchema.validateJsonData() with an empty jsonPath
-// (represents root), and the root-schema id if exists.
-func (rs *RootJsonSchema) validateBytes(bytes []byte) error {
-	var id string
+// id returns the URI that identifies the root schema, which is its "$id", or
+// the URI that it was retrieved from if it has no "$id". Relative references
+// in the schema are resolved against this URI.
+func (rs *RootJsonSchema) id() string {
 	if rs.Id != nil {
-		id = string(*rs.Id)
-	} else {
-		id = ""
+		return string(*rs.Id)
+	}
+
+	return rs.retrievalURI
+}
+
+// validateBytes decodes the json document in bytes and calls
+// RootJsonSchema.validateJsonData() with an empty jsonPath (represents root),
+// and the root-schema id if exists.
+func (rs *RootJsonSchema) validateBytes(bytes []byte, ctx *validationContext) error {
+	id := rs.id()
+
+	// In strict numeric mode, numbers that cannot be represented exactly as
+	// float64 keep their original representation.
+	value, err := ctx.validator.instanceDecoder().Decode(bytes, ctx.validator.strictNumbers)
+	if err != nil {
+		return errors.Wrap(err, "json data decoding failed")
+	}
+	if ctx.validator.strictNumbers {
+		value = canonicalizeNumbers(value)
 	}
 
-	return rs.validateJsonData("", bytes, id)
+	return rs.validateJsonData("", newJsonData(value), id, ctx)
 }
@@ -1 +1,312 @@
 package jsonvalidator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSubSchemaPointers(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/pointers.json",
+		"definitions": {"a/b": {"type": "string"}},
+		"properties": {"name": {"$ref": "#/definitions/a~1b"}},
+		"not": {"type": "null"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"", "/definitions/a~1b", "/not", "/properties/name"}
+	if pointers := rootSchema.SubSchemaPointers(); !reflect.DeepEqual(pointers, expected) {
+		t.Errorf("expected pointers %v, got %v", expected, pointers)
+	}
+
+	// The sub-schemas of a schema without "$id" are listed too, with the
+	// names of the anchors.
+	rootSchema, err = NewRootJsonSchema([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$defs": {
+			"item": {"$anchor": "item", "type": "string"},
+			"node": {"$dynamicAnchor": "node", "items": {"$ref": "#item"}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = []string{"", "/$defs/item", "/$defs/node", "/$defs/node/items", "item", "node"}
+	if pointers := rootSchema.SubSchemaPointers(); !reflect.DeepEqual(pointers, expected) {
+		t.Errorf("expected pointers %v, got %v", expected, pointers)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/resolve.json",
+		"definitions": {"name": {"type": "string"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema, err := rootSchema.Resolve("#/definitions/name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema.Type == nil {
+		t.Errorf("expected the referenced schema to have a type")
+	}
+
+	if _, err := rootSchema.Resolve("#/definitions/missing"); err == nil {
+		t.Errorf("expected an error for a missing fragment")
+	}
+	if _, err := rootSchema.Resolve("http://example.com/missing.json#"); err == nil {
+		t.Errorf("expected an error for a missing root schema")
+	}
+}
+
+func TestResolveSuggestions(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/suggestions.json",
+		"definitions": {
+			"address": {"type": "object"},
+			"addresses": {"type": "array"},
+			"name": {"type": "string"}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = rootSchema.Resolve("#/definitions/adress")
+	referenceError, ok := err.(InvalidReferenceError)
+	if !ok {
+		t.Fatalf("expected an InvalidReferenceError, got %v", err)
+	}
+
+	expected := []string{"/definitions/address", "/definitions/addresses"}
+	if suggestions := referenceError.Suggestions(); !reflect.DeepEqual(suggestions, expected) {
+		t.Errorf("expected suggestions %v, got %v", expected, suggestions)
+	}
+	if message := err.Error(); !strings.HasSuffix(message, "(did you mean #/definitions/address or #/definitions/addresses?)") {
+		t.Errorf("expected the suggestions in the message, got %q", message)
+	}
+
+	_, err = rootSchema.Resolve("#/definitions/unrelated")
+	if suggestions := err.(InvalidReferenceError).Suggestions(); len(suggestions) != 0 {
+		t.Errorf("expected no suggestions, got %v", suggestions)
+	}
+}
+
+func TestResolveSuggestionsWithoutID(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"definitions": {
+			"address": {"type": "object"},
+			"name": {"$anchor": "name", "type": "string"}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		reference string
+		expected  []string
+	}{
+		{"#/definitons/address", []string{"/definitions/address"}},
+		{"#nmae", []string{"name"}},
+	}
+
+	for _, testCase := range testCases {
+		_, err := rootSchema.Resolve(testCase.reference)
+		referenceError, ok := err.(InvalidReferenceError)
+		if !ok {
+			t.Errorf("%s: expected an InvalidReferenceError, got %v", testCase.reference, err)
+			continue
+		}
+		if suggestions := referenceError.Suggestions(); !reflect.DeepEqual(suggestions, testCase.expected) {
+			t.Errorf("%s: expected suggestions %v, got %v", testCase.reference, testCase.expected, suggestions)
+		}
+	}
+}
+
+func TestAddResource(t *testing.T) {
+	_, err := AddResource("http://example.com/resources/types.json", []byte(`{
+		"definitions": {"name": {"type": "string", "minLength": 1}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = AddResource("http://example.com/resources/address.json#", []byte(`{
+		"required": ["city"],
+		"properties": {"city": {"$ref": "types.json#/definitions/name"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/resources/person.json",
+		"properties": {
+			"name": {"$ref": "types.json#/definitions/name"},
+			"address": {"$ref": "address.json"}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := NewValidator(rootSchema)
+	testCases := []struct {
+		document string
+		valid    bool
+	}{
+		{`{"name": "Bob", "address": {"city": "Haifa"}}`, true},
+		{`{"name": ""}`, false},
+		{`{"address": {}}`, false},
+		{`{"address": {"city": 1}}`, false},
+	}
+
+	for _, testCase := range testCases {
+		err := validator.Validate([]byte(testCase.document))
+		if testCase.valid && err != nil {
+			t.Errorf("%s: unexpected error %v", testCase.document, err)
+		} else if !testCase.valid && err == nil {
+			t.Errorf("%s: expected an error", testCase.document)
+		}
+	}
+
+	// A resource that is added again replaces the previous one.
+	_, err = AddResource("http://example.com/resources/types.json", []byte(`{
+		"definitions": {"name": {"type": "string", "minLength": 5}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validator.Validate([]byte(`{"name": "Bob"}`)); err == nil {
+		t.Error("expected the replaced resource to be used")
+	}
+
+	if _, err := AddResource("http://example.com/resources/invalid.json", []byte(`{`)); err == nil {
+		t.Error("expected an error for an invalid schema")
+	}
+}
+
+func TestResolveUnmappedFragments(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/unmapped.json",
+		"$defs": {"name": {"type": "string", "maxLength": 3}},
+		"x-shared": [{"type": "integer"}],
+		"properties": {
+			"name": {"$ref": "#/$defs/name"},
+			"count": {"$ref": "#/x-shared/0"},
+			"price": {"$ref": "#/x-shared/0/type"}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := NewValidator(rootSchema)
+	testCases := []struct {
+		document string
+		valid    bool
+	}{
+		{`{"name": "Bob", "count": 1}`, true},
+		{`{"name": "Alice"}`, false},
+		{`{"count": "1"}`, false},
+		{`{"price": 1}`, false},
+	}
+
+	for _, testCase := range testCases {
+		err := validator.Validate([]byte(testCase.document))
+		if testCase.valid && err != nil {
+			t.Errorf("%s: unexpected error %v", testCase.document, err)
+		} else if !testCase.valid && err == nil {
+			t.Errorf("%s: expected an error", testCase.document)
+		}
+	}
+
+	if _, err := rootSchema.Resolve("#/$defs/missing"); err == nil {
+		t.Errorf("expected an error for a missing fragment")
+	}
+	if _, err := rootSchema.Resolve("#/x-shared/0/type"); err == nil {
+		t.Errorf("expected an error for a fragment that is not a schema")
+	}
+}
+
+func TestCompile(t *testing.T) {
+	testCases := []struct {
+		schema    string
+		locations []string
+	}{
+		{`{
+			"$id": "http://example.com/compile/valid.json",
+			"definitions": {"node": {"properties": {"next": {"$ref": "#/definitions/node"}}}},
+			"allOf": [{"$ref": "#/definitions/node"}]
+		}`, nil},
+		{`{
+			"$id": "http://example.com/compile/unresolved.json",
+			"properties": {
+				"a": {"$ref": "#/definitions/missing"},
+				"b": {"$ref": "http://example.com/compile/missing.json"}
+			}
+		}`, []string{"/properties/a/$ref", "/properties/b/$ref"}},
+		{`{
+			"$id": "http://example.com/compile/self.json",
+			"$ref": "#"
+		}`, []string{"/$ref"}},
+		{`{
+			"$id": "http://example.com/compile/cycle.json",
+			"definitions": {
+				"a": {"$ref": "#/definitions/b"},
+				"b": {"anyOf": [{"type": "null"}, {"not": {"$ref": "#/definitions/a"}}]},
+				"c": {"$ref": "#/definitions/a"}
+			}
+		}`, []string{"/definitions/a/$ref", "/definitions/b/anyOf/1/not/$ref"}},
+		{`{
+			"definitions": {
+				"a": {"$ref": "#/definitions/b"},
+				"b": {"$ref": "#/definitions/a"}
+			},
+			"properties": {"x": {"$ref": "#/definitions/a"}}
+		}`, []string{"/definitions/a/$ref", "/definitions/b/$ref"}},
+		{`{
+			"definitions": {"a": {"$ref": "#/definitions/a"}},
+			"properties": {"x": {"$ref": "#/definitions/a"}}
+		}`, []string{"/definitions/a/$ref"}},
+		{`{
+			"definitions": {"a~b": {"allOf": [{"$ref": "#/definitions/a~0b"}]}}
+		}`, []string{"/definitions/a~0b/allOf/0/$ref"}},
+	}
+
+	for _, testCase := range testCases {
+		// Every schema is compiled in a registry of its own, so the
+		// schemas without "$id" do not resolve their references in each
+		// other.
+		_, err := NewRegistry().Compile([]byte(testCase.schema))
+		if testCase.locations == nil {
+			if err != nil {
+				t.Errorf("Compile(%s) returned %v", testCase.schema, err)
+			}
+			continue
+		}
+
+		errs, ok := err.(SchemaCompilationErrors)
+		if !ok {
+			t.Errorf("Compile(%s) returned %v, expected SchemaCompilationErrors", testCase.schema, err)
+			continue
+		}
+
+		locations := make([]string, len(errs))
+		for index, compilationError := range errs {
+			locations[index] = compilationError.Path()
+		}
+		if !reflect.DeepEqual(locations, testCase.locations) {
+			t.Errorf("Compile(%s) failed in %v, expected %v", testCase.schema, locations, testCase.locations)
+		}
+	}
+}
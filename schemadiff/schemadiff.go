@@ -0,0 +1,620 @@
+// Package schemadiff finds the semantic differences between two versions of
+// a json schema, and tells which of them are breaking.
+//
+// A change is breaking if the new schema may reject documents that the old
+// schema accepted, so documents that were written against the old schema
+// (or by producers that still use it) may fail validation. Changes that
+// only relax the schema, and changes of annotations like "description",
+// are not breaking.
+//
+// The schemas are compared keyword by keyword. A property that only one of
+// the schemas names is compared with the schema that the other one applies
+// to it through "patternProperties" or "additionalProperties", so adding a
+// property to a schema that allowed any other property is breaking if it
+// restricts the property. Changes that cannot be compared precisely, like
+// a changed "$ref" or a changed "oneOf", are breaking.
+package schemadiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/internal/schemautil"
+	"github.com/itayankri/gojsonvalidator/internal/strutil"
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+// Change is a difference between two schemas. Location is the json pointer
+// of the changed keyword (or schema) in the new schema, or in the old
+// schema if the new schema does not have it.
+type Change struct {
+	Location string
+	Breaking bool
+	Message  string
+}
+
+func (c Change) String() string {
+	compatibility := "non-breaking"
+	if c.Breaking {
+		compatibility = "breaking"
+	}
+
+	return fmt.Sprintf("#%s: %s: %s", c.Location, compatibility, c.Message)
+}
+
+// HasBreaking returns true if one of the changes is breaking.
+func HasBreaking(changes []Change) bool {
+	for _, change := range changes {
+		if change.Breaking {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Diff returns the changes between two schema documents, sorted by their
+// location.
+func Diff(oldDocument []byte, newDocument []byte) ([]Change, error) {
+	oldValue, err := decode(oldDocument)
+	if err != nil {
+		return nil, err
+	}
+
+	newValue, err := decode(newDocument)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &differ{}
+	d.diff(oldValue, newValue, "")
+
+	sort.SliceStable(d.changes, func(i, j int) bool {
+		return d.changes[i].Location < d.changes[j].Location
+	})
+	return d.changes, nil
+}
+
+// DiffSchemas returns the changes between two root schemas, as Diff()
+// does for the documents that they were created from.
+func DiffSchemas(oldSchema *jsonvalidator.RootJsonSchema, newSchema *jsonvalidator.RootJsonSchema) ([]Change, error) {
+	return Diff(oldSchema.Document(), newSchema.Document())
+}
+
+// differ collects the changes between schemas.
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) add(location string, breaking bool, format string, args ...interface{}) {
+	d.changes = append(d.changes, Change{
+		Location: location,
+		Breaking: breaking,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// The keywords that only annotate the schema, whose changes are not
+// breaking.
+var annotationKeywords = []string{"title", "description", "default", "examples", "$comment", "readOnly", "writeOnly", "deprecated"}
+
+// The keywords whose values are compared by equality, which are breaking
+// when they are added or changed.
+var equalityKeywords = []string{"$schema", "$id", "id", "$ref", "const", "pattern", "format", "contentMediaType", "contentEncoding"}
+
+// The lower and upper limits. Raising a lower limit and lowering an upper
+// limit are breaking.
+var (
+	lowerLimits = []string{"minimum", "exclusiveMinimum", "minLength", "minItems", "minProperties"}
+	upperLimits = []string{"maximum", "exclusiveMaximum", "maxLength", "maxItems", "maxProperties"}
+)
+
+// The keywords that the differ compares, other than the annotations and the
+// keywords that are compared by equality and by their limits.
+var comparedKeywords = []string{
+	"type", "enum", "required", "multipleOf", "uniqueItems", "properties",
+	"patternProperties", "additionalProperties", "items", "additionalItems",
+	"contains", "propertyNames", "dependencies", "allOf", "anyOf", "oneOf",
+	"not", "if", "then", "else", "definitions",
+}
+
+// diff compares two schema values at a location.
+func (d *differ) diff(oldValue interface{}, newValue interface{}, location string) {
+	if reflect.DeepEqual(oldValue, newValue) {
+		return
+	}
+
+	oldFlag, oldIsFlag := oldValue.(bool)
+	newFlag, newIsFlag := newValue.(bool)
+	switch {
+	case oldIsFlag && !oldFlag:
+		if !newIsFlag || newFlag {
+			d.add(location, false, "the schema accepts values that it rejected")
+		}
+		return
+	case newIsFlag && !newFlag:
+		d.add(location, true, "the schema rejects all values")
+		return
+	}
+
+	oldSchema, _ := oldValue.(map[string]interface{})
+	newSchema, _ := newValue.(map[string]interface{})
+
+	for _, keyword := range sortedKeys(oldSchema, newSchema) {
+		keywordLocation := location + "/" + jsonwalker.EscapeToken(keyword)
+		oldKeyword, inOld := oldSchema[keyword]
+		newKeyword, inNew := newSchema[keyword]
+
+		switch {
+		case strutil.Contains(annotationKeywords, keyword) || !isKnown(keyword):
+			if !reflect.DeepEqual(oldKeyword, newKeyword) {
+				d.add(keywordLocation, false, "%q changed", keyword)
+			}
+		case strutil.Contains(equalityKeywords, keyword):
+			d.diffEquality(keyword, oldKeyword, inOld, newKeyword, inNew, keywordLocation)
+		case strutil.Contains(lowerLimits, keyword) || strutil.Contains(upperLimits, keyword):
+			d.diffLimit(keyword, oldKeyword, inOld, newKeyword, inNew, keywordLocation)
+		}
+	}
+
+	d.diffTypes(oldSchema, newSchema, location)
+	d.diffEnum(oldSchema, newSchema, location)
+	d.diffRequired(oldSchema, newSchema, location)
+	d.diffMultipleOf(oldSchema, newSchema, location)
+	d.diffUniqueItems(oldSchema, newSchema, location)
+	d.diffProperties(oldSchema, newSchema, location)
+	d.diffItems(oldSchema, newSchema, location)
+	d.diffSubSchemas(oldSchema, newSchema, location)
+	d.diffJunctors(oldSchema, newSchema, location)
+	d.diffDependencies(oldSchema, newSchema, location)
+}
+
+// diffEquality compares a keyword whose values are compared by equality.
+func (d *differ) diffEquality(keyword string, oldValue interface{}, inOld bool, newValue interface{}, inNew bool, location string) {
+	switch {
+	case inOld && !inNew:
+		d.add(location, false, "%q was removed", keyword)
+	case !inOld && inNew:
+		d.add(location, true, "%q was added", keyword)
+	case !reflect.DeepEqual(oldValue, newValue):
+		d.add(location, true, "%q changed from %s to %s", keyword, encode(oldValue), encode(newValue))
+	}
+}
+
+// diffLimit compares a lower or an upper limit.
+func (d *differ) diffLimit(keyword string, oldValue interface{}, inOld bool, newValue interface{}, inNew bool, location string) {
+	oldLimit, oldOk := schemautil.Number(oldValue)
+	newLimit, newOk := schemautil.Number(newValue)
+	lower := strutil.Contains(lowerLimits, keyword)
+
+	switch {
+	case inOld && !inNew:
+		d.add(location, false, "%q was removed", keyword)
+	case !inOld && inNew:
+		d.add(location, true, "%q was added", keyword)
+	case !oldOk || !newOk:
+		// The boolean exclusive limits of draft 4.
+		if !reflect.DeepEqual(oldValue, newValue) {
+			d.add(location, newValue == true, "%q changed from %s to %s", keyword, encode(oldValue), encode(newValue))
+		}
+	case oldLimit != newLimit:
+		d.add(location, (newLimit > oldLimit) == lower, "%q changed from %s to %s", keyword, encode(oldValue), encode(newValue))
+	}
+}
+
+// diffTypes compares the "type" keywords. A type that is no longer allowed
+// is breaking, and "integer" is allowed by "number".
+func (d *differ) diffTypes(oldSchema map[string]interface{}, newSchema map[string]interface{}, location string) {
+	_, inOld := oldSchema["type"]
+	_, inNew := newSchema["type"]
+	location += "/type"
+
+	switch {
+	case inOld && !inNew:
+		d.add(location, false, "\"type\" was removed")
+		return
+	case !inOld && inNew:
+		d.add(location, true, "\"type\" was added")
+		return
+	}
+
+	oldTypes := schemautil.StringList(oldSchema["type"])
+	newTypes := schemautil.StringList(newSchema["type"])
+	for _, jsonType := range oldTypes {
+		if !strutil.Contains(newTypes, jsonType) && !(jsonType == "integer" && strutil.Contains(newTypes, "number")) {
+			d.add(location, true, "the type %q is no longer allowed", jsonType)
+		}
+	}
+	for _, jsonType := range newTypes {
+		if !strutil.Contains(oldTypes, jsonType) && !(jsonType == "integer" && strutil.Contains(oldTypes, "number")) {
+			d.add(location, false, "the type %q is allowed", jsonType)
+		}
+	}
+}
+
+// diffEnum compares the "enum" keywords.
+func (d *differ) diffEnum(oldSchema map[string]interface{}, newSchema map[string]interface{}, location string) {
+	oldValues, inOld := oldSchema["enum"].([]interface{})
+	newValues, inNew := newSchema["enum"].([]interface{})
+	location += "/enum"
+
+	switch {
+	case inOld && !inNew:
+		d.add(location, false, "\"enum\" was removed")
+	case !inOld && inNew:
+		d.add(location, true, "\"enum\" was added")
+	case inOld && inNew:
+		for _, value := range oldValues {
+			if !schemautil.ContainsValue(newValues, value) {
+				d.add(location, true, "the value %s is no longer allowed", encode(value))
+			}
+		}
+		for _, value := range newValues {
+			if !schemautil.ContainsValue(oldValues, value) {
+				d.add(location, false, "the value %s is allowed", encode(value))
+			}
+		}
+	}
+}
+
+// diffRequired compares the "required" keywords.
+func (d *differ) diffRequired(oldSchema map[string]interface{}, newSchema map[string]interface{}, location string) {
+	oldRequired, _ := oldSchema["required"].([]interface{})
+	newRequired, _ := newSchema["required"].([]interface{})
+	location += "/required"
+
+	for _, name := range newRequired {
+		if !schemautil.ContainsValue(oldRequired, name) {
+			d.add(location, true, "the property %s is required", encode(name))
+		}
+	}
+	for _, name := range oldRequired {
+		if !schemautil.ContainsValue(newRequired, name) {
+			d.add(location, false, "the property %s is no longer required", encode(name))
+		}
+	}
+}
+
+// diffMultipleOf compares the "multipleOf" keywords. A new divisor that
+// divides the old one is not breaking.
+func (d *differ) diffMultipleOf(oldSchema map[string]interface{}, newSchema map[string]interface{}, location string) {
+	oldValue, inOld := oldSchema["multipleOf"]
+	newValue, inNew := newSchema["multipleOf"]
+	location += "/multipleOf"
+
+	switch {
+	case inOld && !inNew:
+		d.add(location, false, "\"multipleOf\" was removed")
+	case !inOld && inNew:
+		d.add(location, true, "\"multipleOf\" was added")
+	case inOld && inNew && !reflect.DeepEqual(oldValue, newValue):
+		oldDivisor, _ := schemautil.Number(oldValue)
+		newDivisor, _ := schemautil.Number(newValue)
+		quotient := oldDivisor / newDivisor
+		d.add(location, newDivisor == 0 || quotient != math.Trunc(quotient), "\"multipleOf\" changed from %s to %s", encode(oldValue), encode(newValue))
+	}
+}
+
+// diffUniqueItems compares the "uniqueItems" keywords.
+func (d *differ) diffUniqueItems(oldSchema map[string]interface{}, newSchema map[string]interface{}, location string) {
+	oldUnique, _ := oldSchema["uniqueItems"].(bool)
+	newUnique, _ := newSchema["uniqueItems"].(bool)
+	if oldUnique != newUnique {
+		d.add(location+"/uniqueItems", newUnique, "\"uniqueItems\" changed from %t to %t", oldUnique, newUnique)
+	}
+}
+
+// diffProperties compares the schemas of the properties, including the
+// properties that only one of the schemas names, and the schemas of the
+// other properties.
+func (d *differ) diffProperties(oldSchema map[string]interface{}, newSchema map[string]interface{}, location string) {
+	oldProperties, _ := oldSchema["properties"].(map[string]interface{})
+	newProperties, _ := newSchema["properties"].(map[string]interface{})
+	for _, name := range sortedKeys(oldProperties, newProperties) {
+		propertyLocation := location + "/properties/" + jsonwalker.EscapeToken(name)
+		_, inOld := oldProperties[name]
+		_, inNew := newProperties[name]
+		switch {
+		case !inOld:
+			d.add(propertyLocation, false, "the property %q was added", name)
+		case !inNew:
+			d.add(propertyLocation, false, "the property %q was removed", name)
+		}
+
+		d.diff(propertySchema(oldSchema, name), propertySchema(newSchema, name), propertyLocation)
+	}
+
+	oldPatterns, _ := oldSchema["patternProperties"].(map[string]interface{})
+	newPatterns, _ := newSchema["patternProperties"].(map[string]interface{})
+	for _, pattern := range sortedKeys(oldPatterns, newPatterns) {
+		oldPattern, inOld := oldPatterns[pattern]
+		newPattern, inNew := newPatterns[pattern]
+		if !inOld {
+			oldPattern = additionalSchema(oldSchema, "additionalProperties")
+		}
+		if !inNew {
+			newPattern = additionalSchema(newSchema, "additionalProperties")
+		}
+
+		d.diff(oldPattern, newPattern, location+"/patternProperties/"+jsonwalker.EscapeToken(pattern))
+	}
+
+	d.diff(additionalSchema(oldSchema, "additionalProperties"), additionalSchema(newSchema, "additionalProperties"), location+"/additionalProperties")
+}
+
+// diffItems compares the schemas of the items by their index, and the
+// schemas of the items after the tuples.
+func (d *differ) diffItems(oldSchema map[string]interface{}, newSchema map[string]interface{}, location string) {
+	oldTuple, oldIsTuple := oldSchema["items"].([]interface{})
+	newTuple, newIsTuple := newSchema["items"].([]interface{})
+
+	for index := 0; index < len(oldTuple) || index < len(newTuple); index++ {
+		d.diff(itemSchema(oldSchema, index), itemSchema(newSchema, index), location+"/items/"+strconv.Itoa(index))
+	}
+
+	restLocation := location + "/items"
+	if newIsTuple || (oldIsTuple && !hasKey(newSchema, "items")) {
+		restLocation = location + "/additionalItems"
+	}
+	d.diff(restSchema(oldSchema), restSchema(newSchema), restLocation)
+}
+
+// The keywords whose sub-schemas restrict the schema more as they restrict
+// more, and which are absent as if they were the true schema.
+var monotonicKeywords = []string{"propertyNames", "then", "else"}
+
+// diffSubSchemas compares the keywords that have a single sub-schema.
+func (d *differ) diffSubSchemas(oldSchema map[string]interface{}, newSchema map[string]interface{}, location string) {
+	for _, keyword := range monotonicKeywords {
+		oldValue, inOld := oldSchema[keyword]
+		newValue, inNew := newSchema[keyword]
+		if inOld || inNew {
+			d.diff(orTrue(oldValue, inOld), orTrue(newValue, inNew), location+"/"+keyword)
+		}
+	}
+
+	// "contains" requires a matching item, so adding it is breaking even if
+	// its schema is true.
+	oldContains, inOld := oldSchema["contains"]
+	newContains, inNew := newSchema["contains"]
+	switch {
+	case inOld && !inNew:
+		d.add(location+"/contains", false, "\"contains\" was removed")
+	case !inOld && inNew:
+		d.add(location+"/contains", true, "\"contains\" was added")
+	case inOld && inNew:
+		d.diff(oldContains, newContains, location+"/contains")
+	}
+
+	// A more restrictive "not" schema relaxes the schema, so the changes of
+	// the sub-schema are inverted. "if" decides which of "then" and "else"
+	// applies, so any change of it is breaking.
+	d.diffNested(oldSchema, newSchema, "not", location, func(change *Change) { change.Breaking = !change.Breaking })
+	d.diffNested(oldSchema, newSchema, "if", location, func(change *Change) { change.Breaking = true })
+
+	oldDefinitions, _ := oldSchema["definitions"].(map[string]interface{})
+	newDefinitions, _ := newSchema["definitions"].(map[string]interface{})
+	for _, name := range sortedKeys(oldDefinitions, newDefinitions) {
+		definitionLocation := location + "/definitions/" + jsonwalker.EscapeToken(name)
+		oldDefinition, inOld := oldDefinitions[name]
+		newDefinition, inNew := newDefinitions[name]
+		switch {
+		case !inOld:
+			d.add(definitionLocation, false, "the definition %q was added", name)
+		case !inNew:
+			d.add(definitionLocation, true, "the definition %q was removed, and other schemas may reference it", name)
+		default:
+			d.diff(oldDefinition, newDefinition, definitionLocation)
+		}
+	}
+}
+
+// diffNested compares the sub-schemas of a keyword with a separate differ,
+// and adjusts the changes that it finds before they are added.
+func (d *differ) diffNested(oldSchema map[string]interface{}, newSchema map[string]interface{}, keyword string, location string, adjust func(change *Change)) {
+	oldValue, inOld := oldSchema[keyword]
+	newValue, inNew := newSchema[keyword]
+	location += "/" + keyword
+
+	switch {
+	case inOld && !inNew:
+		d.add(location, false, "%q was removed", keyword)
+	case !inOld && inNew:
+		d.add(location, true, "%q was added", keyword)
+	case inOld && inNew:
+		nested := &differ{}
+		nested.diff(oldValue, newValue, location)
+		for _, change := range nested.changes {
+			adjust(&change)
+			d.changes = append(d.changes, change)
+		}
+	}
+}
+
+// diffJunctors compares the sub-schemas of "allOf", "anyOf" and "oneOf" by
+// their index.
+func (d *differ) diffJunctors(oldSchema map[string]interface{}, newSchema map[string]interface{}, location string) {
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		oldSchemas, inOld := oldSchema[keyword].([]interface{})
+		newSchemas, inNew := newSchema[keyword].([]interface{})
+		keywordLocation := location + "/" + keyword
+
+		switch {
+		case inOld && !inNew:
+			d.add(keywordLocation, false, "%q was removed", keyword)
+			continue
+		case !inOld && inNew:
+			d.add(keywordLocation, true, "%q was added", keyword)
+			continue
+		}
+
+		nested := &differ{}
+		for index := 0; index < len(oldSchemas) || index < len(newSchemas); index++ {
+			indexLocation := keywordLocation + "/" + strconv.Itoa(index)
+			switch {
+			case index >= len(oldSchemas):
+				// A schema that is added to "anyOf" accepts more values.
+				nested.add(indexLocation, keyword != "anyOf", "a schema was added to %q", keyword)
+			case index >= len(newSchemas):
+				nested.add(indexLocation, keyword != "allOf", "a schema was removed from %q", keyword)
+			default:
+				nested.diff(oldSchemas[index], newSchemas[index], indexLocation)
+			}
+		}
+
+		// A value must match exactly one schema of "oneOf", so relaxing one
+		// of them may make values match two.
+		for _, change := range nested.changes {
+			change.Breaking = change.Breaking || keyword == "oneOf"
+			d.changes = append(d.changes, change)
+		}
+	}
+}
+
+// diffDependencies compares the "dependencies" keywords. Property
+// dependencies are compared as sets, and schema dependencies as schemas.
+func (d *differ) diffDependencies(oldSchema map[string]interface{}, newSchema map[string]interface{}, location string) {
+	oldDependencies, _ := oldSchema["dependencies"].(map[string]interface{})
+	newDependencies, _ := newSchema["dependencies"].(map[string]interface{})
+	for _, name := range sortedKeys(oldDependencies, newDependencies) {
+		dependencyLocation := location + "/dependencies/" + jsonwalker.EscapeToken(name)
+		oldDependency, inOld := oldDependencies[name]
+		newDependency, inNew := newDependencies[name]
+		oldProperties, oldIsList := oldDependency.([]interface{})
+		newProperties, newIsList := newDependency.([]interface{})
+
+		switch {
+		case !inNew:
+			d.add(dependencyLocation, false, "the dependency of %q was removed", name)
+		case oldIsList && newIsList || !inOld && newIsList:
+			for _, property := range newProperties {
+				if !schemautil.ContainsValue(oldProperties, property) {
+					d.add(dependencyLocation, true, "the property %s is required with %q", encode(property), name)
+				}
+			}
+			for _, property := range oldProperties {
+				if !schemautil.ContainsValue(newProperties, property) {
+					d.add(dependencyLocation, false, "the property %s is no longer required with %q", encode(property), name)
+				}
+			}
+		case oldIsList || newIsList:
+			d.add(dependencyLocation, true, "the dependency of %q changed from %s to %s", name, encode(oldDependency), encode(newDependency))
+		default:
+			d.diff(orTrue(oldDependency, inOld), newDependency, dependencyLocation)
+		}
+	}
+}
+
+// propertySchema returns the schema that an object schema applies to a
+// property: its schema in "properties", or else the schemas of the patterns
+// that match it, or else "additionalProperties".
+func propertySchema(schema map[string]interface{}, name string) interface{} {
+	properties, _ := schema["properties"].(map[string]interface{})
+	if propertySchema, ok := properties[name]; ok {
+		return propertySchema
+	}
+
+	var matching []interface{}
+	patterns, _ := schema["patternProperties"].(map[string]interface{})
+	for _, pattern := range sortedKeys(patterns, nil) {
+		if re, err := regexp.Compile(pattern); err == nil && re.MatchString(name) {
+			matching = append(matching, patterns[pattern])
+		}
+	}
+
+	switch len(matching) {
+	case 0:
+		return additionalSchema(schema, "additionalProperties")
+	case 1:
+		return matching[0]
+	}
+	return map[string]interface{}{"allOf": matching}
+}
+
+// itemSchema returns the schema that an array schema applies to the item
+// at an index.
+func itemSchema(schema map[string]interface{}, index int) interface{} {
+	tuple, ok := schema["items"].([]interface{})
+	if !ok {
+		return restSchema(schema)
+	}
+	if index < len(tuple) {
+		return tuple[index]
+	}
+
+	return additionalSchema(schema, "additionalItems")
+}
+
+// restSchema returns the schema that an array schema applies to the items
+// after its tuple, or to all of its items if it has no tuple.
+func restSchema(schema map[string]interface{}) interface{} {
+	if _, ok := schema["items"].([]interface{}); ok {
+		return additionalSchema(schema, "additionalItems")
+	}
+
+	return additionalSchema(schema, "items")
+}
+
+// additionalSchema returns the value of a keyword whose absence is the true
+// schema.
+func additionalSchema(schema map[string]interface{}, keyword string) interface{} {
+	value, ok := schema[keyword]
+	return orTrue(value, ok)
+}
+
+func orTrue(value interface{}, ok bool) interface{} {
+	if !ok {
+		return true
+	}
+
+	return value
+}
+
+// isKnown returns true if the differ compares the keyword.
+func isKnown(keyword string) bool {
+	return strutil.Contains(annotationKeywords, keyword) || strutil.Contains(equalityKeywords, keyword) ||
+		strutil.Contains(lowerLimits, keyword) || strutil.Contains(upperLimits, keyword) || strutil.Contains(comparedKeywords, keyword)
+}
+
+// sortedKeys returns the keys of two objects, sorted.
+func sortedKeys(first map[string]interface{}, second map[string]interface{}) []string {
+	keys := make([]string, 0, len(first)+len(second))
+	for key := range first {
+		keys = append(keys, key)
+	}
+	for key := range second {
+		if _, ok := first[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func hasKey(schema map[string]interface{}, key string) bool {
+	_, ok := schema[key]
+	return ok
+}
+
+func decode(document []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	var value interface{}
+	err := decoder.Decode(&value)
+	return value, err
+}
+
+// encode returns the json representation of a value for the messages.
+func encode(value interface{}) string {
+	bytes, _ := json.Marshal(value)
+	return string(bytes)
+}
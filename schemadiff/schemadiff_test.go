@@ -0,0 +1,116 @@
+package schemadiff_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/itayankri/gojsonvalidator/schemadiff"
+)
+
+func TestDiff(t *testing.T) {
+	oldSchema := `{
+		"title": "order",
+		"type": "object",
+		"required": ["id", "note"],
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"note": {"type": "string", "maxLength": 100},
+			"status": {"enum": ["new", "paid"]},
+			"items": {"type": "array", "items": {"type": "string"}},
+			"price": {"type": "number", "multipleOf": 0.5},
+			"legacy": {"type": "string"}
+		},
+		"additionalProperties": false,
+		"anyOf": [{"required": ["id"]}]
+	}`
+	newSchema := `{
+		"title": "an order",
+		"type": "object",
+		"required": ["id", "customer"],
+		"properties": {
+			"id": {"type": "number", "minimum": 0},
+			"note": {"type": "string", "maxLength": 50},
+			"status": {"enum": ["new", "shipped"]},
+			"items": {"type": "array", "items": {"type": "string"}, "uniqueItems": true},
+			"price": {"type": "number", "multipleOf": 0.25},
+			"customer": {"type": "string"}
+		},
+		"additionalProperties": false,
+		"anyOf": [{"required": ["id"]}, {"required": ["customer"]}]
+	}`
+
+	changes, err := schemadiff.Diff([]byte(oldSchema), []byte(newSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`#/anyOf/1: non-breaking: a schema was added to "anyOf"`,
+		`#/properties/customer: non-breaking: the property "customer" was added`,
+		`#/properties/customer: non-breaking: the schema accepts values that it rejected`,
+		`#/properties/id/minimum: non-breaking: "minimum" changed from 1 to 0`,
+		`#/properties/id/type: non-breaking: the type "number" is allowed`,
+		`#/properties/items/uniqueItems: breaking: "uniqueItems" changed from false to true`,
+		`#/properties/legacy: non-breaking: the property "legacy" was removed`,
+		`#/properties/legacy: breaking: the schema rejects all values`,
+		`#/properties/note/maxLength: breaking: "maxLength" changed from 100 to 50`,
+		`#/properties/price/multipleOf: non-breaking: "multipleOf" changed from 0.5 to 0.25`,
+		`#/properties/status/enum: breaking: the value "paid" is no longer allowed`,
+		`#/properties/status/enum: non-breaking: the value "shipped" is allowed`,
+		`#/required: breaking: the property "customer" is required`,
+		`#/required: non-breaking: the property "note" is no longer required`,
+		`#/title: non-breaking: "title" changed`,
+	}
+
+	actual := make([]string, len(changes))
+	for index, change := range changes {
+		actual[index] = change.String()
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected changes:\n%v\ngot:\n%v", expected, actual)
+	}
+	if !schemadiff.HasBreaking(changes) {
+		t.Error("expected breaking changes")
+	}
+}
+
+func TestDiffBreaking(t *testing.T) {
+	testCases := []struct {
+		old      string
+		new      string
+		breaking bool
+	}{
+		{`{}`, `{}`, false},
+		{`{"description": "a"}`, `{"description": "b"}`, false},
+		{`{"type": "string"}`, `{"type": ["string", "null"]}`, false},
+		{`{"type": "number"}`, `{"type": "integer"}`, true},
+		{`{}`, `{"type": "string"}`, true},
+		{`{"properties": {"a": {"type": "string"}}}`, `{"properties": {"a": {"type": "string"}, "b": {"type": "string"}}}`, true},
+		{`{"additionalProperties": false}`, `{"additionalProperties": {"type": "string"}}`, false},
+		{`{"patternProperties": {"^x-": {"type": "string"}}}`, `{"properties": {"x-a": {"type": "string"}}, "patternProperties": {"^x-": {"type": "string"}}}`, false},
+		{`{"items": [{"type": "string"}]}`, `{"items": [{"type": "string"}], "additionalItems": false}`, true},
+		{`{"items": {"type": "string"}}`, `{"items": [{"type": "string"}], "additionalItems": {"type": "string"}}`, false},
+		{`{"not": {"type": "string"}}`, `{"not": {"type": "string", "minLength": 1}}`, false},
+		{`{"not": {"type": "string", "minLength": 1}}`, `{"not": {"type": "string"}}`, true},
+		{`{"oneOf": [{"type": "string"}, {"type": "number"}]}`, `{"oneOf": [{"type": "string"}, {}]}`, true},
+		{`{"allOf": [{"type": "string"}]}`, `{"allOf": [{"type": "string"}, {"minLength": 1}]}`, true},
+		{`{"dependencies": {"a": ["b"]}}`, `{"dependencies": {"a": ["b", "c"]}}`, true},
+		{`{"dependencies": {"a": ["b", "c"]}}`, `{"dependencies": {"a": ["b"]}}`, false},
+		{`{"$ref": "#/definitions/a"}`, `{"$ref": "#/definitions/b"}`, true},
+		{`{"multipleOf": 2}`, `{"multipleOf": 3}`, true},
+		{`{"maximum": 10}`, `{"maximum": 20}`, false},
+		{`true`, `false`, true},
+		{`false`, `{"type": "string"}`, false},
+	}
+
+	for _, testCase := range testCases {
+		changes, err := schemadiff.Diff([]byte(testCase.old), []byte(testCase.new))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if breaking := schemadiff.HasBreaking(changes); breaking != testCase.breaking {
+			t.Errorf("%s -> %s: expected breaking %t, got %v", testCase.old, testCase.new, testCase.breaking, changes)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package schemalint
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/itayankri/gojsonvalidator/internal/schemautil"
+)
+
+// The pairs of lower and upper limits whose range is empty if the lower
+// limit is above the upper limit.
+var limitPairs = [][2]string{
+	{"minLength", "maxLength"},
+	{"minItems", "maxItems"},
+	{"minProperties", "maxProperties"},
+}
+
+// contradictions returns the findings of the keywords of a schema that no
+// value can satisfy together. Keywords that only apply to one type can
+// contradict each other without making the schema unsatisfiable, because
+// values of other types ignore them, so they are only warnings.
+func contradictions(schema map[string]interface{}, location string) []Finding {
+	var findings []Finding
+	warn := func(keyword string, format string, args ...interface{}) {
+		findings = append(findings, Finding{
+			Location: location + "/" + keyword,
+			Severity: Warning,
+			Check:    CheckContradiction,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	findings = append(findings, numericRange(schema, location)...)
+
+	for _, pair := range limitPairs {
+		lower, lowerOk := schemautil.Number(schema[pair[0]])
+		upper, upperOk := schemautil.Number(schema[pair[1]])
+		if lowerOk && upperOk && lower > upper {
+			warn(pair[0], "%q (%v) is greater than %q (%v)", pair[0], schema[pair[0]], pair[1], schema[pair[1]])
+		}
+	}
+
+	required, _ := schema["required"].([]interface{})
+	if maxProperties, ok := schemautil.Number(schema["maxProperties"]); ok && float64(len(required)) > maxProperties {
+		warn("required", "%d properties are required, but \"maxProperties\" is %v", len(required), schema["maxProperties"])
+	}
+
+	if additionalProperties, ok := schema["additionalProperties"].(bool); ok && !additionalProperties {
+		properties, _ := schema["properties"].(map[string]interface{})
+		patternProperties, _ := schema["patternProperties"].(map[string]interface{})
+		for index, name := range required {
+			name, ok := name.(string)
+			if !ok || properties[name] != nil || matchesPattern(name, patternProperties) {
+				continue
+			}
+
+			warn(fmt.Sprintf("required/%d", index), "the required property %q is not allowed by \"additionalProperties\"", name)
+		}
+	}
+
+	types := schemautil.StringList(schema["type"])
+	if types != nil {
+		if value, ok := schema["const"]; ok && !matchesTypes(value, types) {
+			warn("const", "the \"const\" value does not match the \"type\" of the schema")
+		}
+
+		if values, ok := schema["enum"].([]interface{}); ok && len(values) > 0 {
+			matched := false
+			for _, value := range values {
+				matched = matched || matchesTypes(value, types)
+			}
+			if !matched {
+				warn("enum", "none of the \"enum\" values match the \"type\" of the schema")
+			}
+		}
+	}
+
+	return findings
+}
+
+// numericRange returns the finding of numeric bounds whose range is empty.
+func numericRange(schema map[string]interface{}, location string) []Finding {
+	// The lower bound is the greater of the bounds, and it is exclusive if
+	// the exclusive bound is the greater one, and likewise for the upper
+	// bound.
+	lowerKeyword, lower, lowerExclusive := "", 0.0, false
+	for _, keyword := range []string{"minimum", "exclusiveMinimum"} {
+		if bound, ok := schemautil.Number(schema[keyword]); ok && (lowerKeyword == "" || bound >= lower) {
+			lowerKeyword, lower, lowerExclusive = keyword, bound, keyword == "exclusiveMinimum"
+		}
+	}
+
+	upperKeyword, upper, upperExclusive := "", 0.0, false
+	for _, keyword := range []string{"maximum", "exclusiveMaximum"} {
+		if bound, ok := schemautil.Number(schema[keyword]); ok && (upperKeyword == "" || bound <= upper) {
+			upperKeyword, upper, upperExclusive = keyword, bound, keyword == "exclusiveMaximum"
+		}
+	}
+
+	if lowerKeyword == "" || upperKeyword == "" {
+		return nil
+	}
+
+	if lower > upper || (lower == upper && (lowerExclusive || upperExclusive)) {
+		return []Finding{{
+			Location: location + "/" + lowerKeyword,
+			Severity: Warning,
+			Check:    CheckContradiction,
+			Message:  fmt.Sprintf("no number is within %q (%v) and %q (%v)", lowerKeyword, schema[lowerKeyword], upperKeyword, schema[upperKeyword]),
+		}}
+	}
+
+	return nil
+}
+
+// matchesTypes returns true if a json value is of one of the types.
+func matchesTypes(value interface{}, types []string) bool {
+	for _, jsonType := range types {
+		switch v := value.(type) {
+		case nil:
+			if jsonType == "null" {
+				return true
+			}
+		case bool:
+			if jsonType == "boolean" {
+				return true
+			}
+		case string:
+			if jsonType == "string" {
+				return true
+			}
+		case json.Number:
+			float, err := v.Float64()
+			if jsonType == "number" || (jsonType == "integer" && err == nil && float == float64(int64(float))) {
+				return true
+			}
+		case []interface{}:
+			if jsonType == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if jsonType == "object" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matchesPattern returns true if a property name matches one of the
+// patterns of "patternProperties". Invalid patterns are left to the
+// meta-schema check.
+func matchesPattern(name string, patternProperties map[string]interface{}) bool {
+	for pattern := range patternProperties {
+		re, err := regexp.Compile(pattern)
+		if err != nil || re.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,288 @@
+// Package schemalint finds problems in json schemas that the validator
+// accepts silently, and reports each of them at the json pointer of the
+// keyword or the schema in the document.
+//
+// The checks are:
+//   - meta-schema: the schema is validated against the draft 7 meta-schema
+//     (schemas of draft 4 are not checked),
+//   - unknown-keyword: keywords that no draft defines, which are usually
+//     typos, are errors (extension keywords that start with "x-" are not),
+//   - unresolved-ref: "$ref" values that do not resolve to a schema,
+//   - contradiction: keywords that no value can satisfy together, like a
+//     "minimum" above the "maximum", are warnings.
+package schemalint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/internal/strutil"
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+// Severity tells whether a finding is an error or a warning.
+type Severity int
+
+const (
+	// Error is the severity of findings that make the schema wrong.
+	Error Severity = iota
+
+	// Warning is the severity of findings that are probably mistakes.
+	Warning
+)
+
+func (s Severity) String() string {
+	if s == Warning {
+		return "warning"
+	}
+
+	return "error"
+}
+
+// The names of the checks.
+const (
+	CheckMetaSchema     = "meta-schema"
+	CheckUnknownKeyword = "unknown-keyword"
+	CheckUnresolvedRef  = "unresolved-ref"
+	CheckContradiction  = "contradiction"
+)
+
+// Finding is a problem in a schema. Location is the json pointer of the
+// keyword or the schema that has the problem, and Check is the name of the
+// check that found it.
+type Finding struct {
+	Location string
+	Severity Severity
+	Check    string
+	Message  string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("#%s: %s: %s [%s]", f.Location, f.Severity, f.Message, f.Check)
+}
+
+// The keywords of drafts 4, 6, 7 and 2020-12, and the extension keywords
+// that the validator supports.
+var keywords = []string{
+	"$schema", "$id", "id", "$ref", "$comment", "title", "description",
+	"default", "readOnly", "writeOnly", "deprecated", "examples", "multipleOf", "maximum",
+	"exclusiveMaximum", "minimum", "exclusiveMinimum", "maxLength",
+	"minLength", "pattern", "additionalItems", "items", "maxItems",
+	"minItems", "uniqueItems", "contains", "maxProperties", "minProperties",
+	"required", "additionalProperties", "definitions", "properties",
+	"patternProperties", "dependencies", "propertyNames", "const", "enum",
+	"type", "format", "contentMediaType", "contentEncoding", "contentSchema", "if", "then",
+	"else", "allOf", "anyOf", "oneOf", "not", "$anchor", "$dynamicAnchor",
+	"$dynamicRef", "$vocabulary", "$defs", "prefixItems",
+}
+
+// The keywords whose values are schemas, lists of schemas or objects of
+// schemas.
+var (
+	schemaKeywords = []string{
+		"additionalItems", "items", "contains", "additionalProperties",
+		"propertyNames", "if", "then", "else", "not", "contentSchema",
+	}
+	schemaListKeywords = []string{"items", "prefixItems", "allOf", "anyOf", "oneOf"}
+	schemaMapKeywords  = []string{"definitions", "$defs", "properties", "patternProperties", "dependencies"}
+)
+
+// The uri under which the meta-schema is registered.
+const metaSchemaURI = "http://json-schema.org/draft-07/schema"
+
+var (
+	metaSchema     *jsonvalidator.RootJsonSchema
+	metaSchemaErr  error
+	metaSchemaOnce sync.Once
+)
+
+// Lint checks a root schema. The references of the schema are resolved like
+// references in validation, so the schemas that it references must be
+// created before.
+func Lint(rootSchema *jsonvalidator.RootJsonSchema) ([]Finding, error) {
+	document := rootSchema.Document()
+	findings, err := LintDocument(document)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := decode(document)
+	if err != nil {
+		return nil, err
+	}
+
+	walkSchemas(value, "", func(schema map[string]interface{}, location string) {
+		reference, ok := schema["$ref"].(string)
+		if !ok {
+			return
+		}
+
+		_, err := rootSchema.Resolve(reference)
+		if err != nil {
+			findings = append(findings, Finding{
+				Location: location + "/$ref",
+				Severity: Error,
+				Check:    CheckUnresolvedRef,
+				Message:  fmt.Sprintf("the reference %q does not resolve to a schema", reference),
+			})
+		}
+	})
+
+	sortFindings(findings)
+	return findings, nil
+}
+
+// LintDocument checks a schema document, without the unresolved-ref check,
+// which needs a compiled schema. It is useful for schemas that cannot be
+// compiled.
+func LintDocument(document []byte) ([]Finding, error) {
+	value, err := decode(document)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	finding, err := validateMetaSchema(value, document)
+	if err != nil {
+		return nil, err
+	}
+	if finding != nil {
+		findings = append(findings, *finding)
+	}
+
+	walkSchemas(value, "", func(schema map[string]interface{}, location string) {
+		findings = append(findings, unknownKeywords(schema, location)...)
+		findings = append(findings, contradictions(schema, location)...)
+	})
+
+	sortFindings(findings)
+	return findings, nil
+}
+
+// validateMetaSchema validates the schema against the draft 7 meta-schema,
+// unless it is a draft 4 schema, and returns the finding of its best
+// matching error.
+func validateMetaSchema(value interface{}, document []byte) (*Finding, error) {
+	if schema, ok := value.(map[string]interface{}); ok && schema["$schema"] == jsonvalidator.DRAFT_04 {
+		return nil, nil
+	}
+
+	metaSchemaOnce.Do(func() {
+		metaSchema, metaSchemaErr = jsonvalidator.AddResource(metaSchemaURI, []byte(draft07MetaSchema))
+	})
+	if metaSchemaErr != nil {
+		return nil, metaSchemaErr
+	}
+
+	err := jsonvalidator.NewValidator(metaSchema).Validate(document)
+	if err == nil {
+		return nil, nil
+	}
+
+	schemaValidationError, ok := jsonvalidator.BestMatch(err).(jsonvalidator.SchemaValidationError)
+	if !ok {
+		return nil, err
+	}
+
+	return &Finding{
+		Location: schemaValidationError.Path(),
+		Severity: Error,
+		Check:    CheckMetaSchema,
+		Message:  schemaValidationError.Reason(),
+	}, nil
+}
+
+// unknownKeywords returns the findings of the unknown keywords of a schema.
+func unknownKeywords(schema map[string]interface{}, location string) []Finding {
+	var findings []Finding
+	for keyword := range schema {
+		if strutil.Contains(keywords, keyword) || strings.HasPrefix(keyword, "x-") {
+			continue
+		}
+
+		message := fmt.Sprintf("unknown keyword %q", keyword)
+		if suggestion := closestKeyword(keyword); suggestion != "" {
+			message += fmt.Sprintf(", did you mean %q?", suggestion)
+		}
+
+		findings = append(findings, Finding{
+			Location: location + "/" + jsonwalker.EscapeToken(keyword),
+			Severity: Error,
+			Check:    CheckUnknownKeyword,
+			Message:  message,
+		})
+	}
+
+	return findings
+}
+
+// closestKeyword returns the keyword that is at most two edits away from
+// the given unknown keyword, or an empty string if there is none.
+func closestKeyword(unknown string) string {
+	closest, closestDistance := "", 3
+	for _, keyword := range keywords {
+		distance := strutil.EditDistance(strings.ToLower(unknown), strings.ToLower(keyword))
+		if distance < closestDistance {
+			closest, closestDistance = keyword, distance
+		}
+	}
+
+	return closest
+}
+
+// walkSchemas calls visit with every schema object in a schema value and
+// its location, starting with the value itself.
+func walkSchemas(value interface{}, location string, visit func(schema map[string]interface{}, location string)) {
+	schema, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+	visit(schema, location)
+
+	for keyword, keywordValue := range schema {
+		keywordLocation := location + "/" + jsonwalker.EscapeToken(keyword)
+		switch {
+		case strutil.Contains(schemaListKeywords, keyword) && isList(keywordValue):
+			for index, subSchema := range keywordValue.([]interface{}) {
+				walkSchemas(subSchema, keywordLocation+"/"+strconv.Itoa(index), visit)
+			}
+		case strutil.Contains(schemaKeywords, keyword):
+			walkSchemas(keywordValue, keywordLocation, visit)
+		case strutil.Contains(schemaMapKeywords, keyword):
+			subSchemas, _ := keywordValue.(map[string]interface{})
+			for name, subSchema := range subSchemas {
+				walkSchemas(subSchema, keywordLocation+"/"+jsonwalker.EscapeToken(name), visit)
+			}
+		}
+	}
+}
+
+func decode(document []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	var value interface{}
+	err := decoder.Decode(&value)
+	return value, err
+}
+
+// sortFindings sorts findings by their location, then by their check.
+func sortFindings(findings []Finding) {
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Location != findings[j].Location {
+			return findings[i].Location < findings[j].Location
+		}
+		return findings[i].Check < findings[j].Check
+	})
+}
+
+func isList(value interface{}) bool {
+	_, ok := value.([]interface{})
+	return ok
+}
@@ -0,0 +1,85 @@
+package schemalint_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/schemalint"
+)
+
+func TestLint(t *testing.T) {
+	rootSchema, err := jsonvalidator.NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/schemalint/order.json",
+		"type": "object",
+		"required": ["id", "note"],
+		"additionalProperties": false,
+		"properties": {
+			"id": {"type": "integer", "minimum": 10, "exclusiveMaximum": 10},
+			"code": {"type": "string", "minLength": 5, "maxLength": 2, "maxLenght": 3},
+			"status": {"type": "string", "enum": [1, 2]},
+			"customer": {"$ref": "#/definitions/customer"},
+			"items": {"type": "array", "items": [{"$ref": "#/definitions/item"}], "x-order": 1}
+		},
+		"definitions": {
+			"item": {"type": "object"}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := schemalint.Lint(rootSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`#/properties/code/maxLenght: error: unknown keyword "maxLenght", did you mean "maxLength"? [unknown-keyword]`,
+		`#/properties/code/minLength: warning: "minLength" (5) is greater than "maxLength" (2) [contradiction]`,
+		`#/properties/customer/$ref: error: the reference "#/definitions/customer" does not resolve to a schema [unresolved-ref]`,
+		`#/properties/id/minimum: warning: no number is within "minimum" (10) and "exclusiveMaximum" (10) [contradiction]`,
+		`#/properties/status/enum: warning: none of the "enum" values match the "type" of the schema [contradiction]`,
+		`#/required/1: warning: the required property "note" is not allowed by "additionalProperties" [contradiction]`,
+	}
+
+	actual := make([]string, len(findings))
+	for index, finding := range findings {
+		actual[index] = finding.String()
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected findings:\n%v\ngot:\n%v", expected, actual)
+	}
+}
+
+func TestLintDocumentMetaSchema(t *testing.T) {
+	testCases := []struct {
+		document string
+		location string
+	}{
+		{`{"properties": {"age": {"type": "int"}}}`, "/properties/age/type"},
+		{`{"required": "name"}`, "/required"},
+		{`{"items": [{"minLength": -1}]}`, "/items/0/minLength"},
+		{`{"$schema": "http://json-schema.org/draft-04/schema#", "minimum": 1, "exclusiveMinimum": true}`, ""},
+	}
+
+	for _, testCase := range testCases {
+		findings, err := schemalint.LintDocument([]byte(testCase.document))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var locations []string
+		for _, finding := range findings {
+			if finding.Check == schemalint.CheckMetaSchema {
+				locations = append(locations, finding.Location)
+			}
+		}
+
+		if testCase.location == "" && len(locations) > 0 {
+			t.Errorf("%s: unexpected meta-schema findings at %v", testCase.document, locations)
+		} else if testCase.location != "" && !reflect.DeepEqual(locations, []string{testCase.location}) {
+			t.Errorf("%s: expected a meta-schema finding at %s, got %v", testCase.document, testCase.location, locations)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package schemaserver serves the registered root schemas over HTTP, so
+// other services and editors can resolve the same "$ref" URIs that the
+// validator resolves from the running service.
+//
+// Every schema that is registered under an http or https URI (its "$id",
+// or the URI that it was added under with jsonvalidator.AddResource()) is
+// served at the path of that URI, as the document that it was created from.
+// The package is separate from jsonvalidator to keep net/http out of
+// programs that only validate.
+package schemaserver
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/itayankri/gojsonvalidator"
+)
+
+// ContentType is the media type of the served schemas.
+const ContentType = "application/schema+json"
+
+// Handler returns an http.Handler that serves the schemas of the
+// jsonvalidator.DefaultRegistry. A request is matched by its path, and when
+// schemas of several hosts share the path, by its host too. Schemas are
+// looked up on every request, so schemas that are registered later are
+// served as well.
+func Handler() http.Handler {
+	return HandlerFor(jsonvalidator.DefaultRegistry)
+}
+
+// HandlerFor returns an http.Handler that serves the schemas of the
+// registry, like Handler().
+func HandlerFor(registry *jsonvalidator.Registry) http.Handler {
+	return handler{registry}
+}
+
+type handler struct {
+	registry *jsonvalidator.Registry
+}
+
+func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	rootSchema := lookup(h.registry, r.Host, r.URL.Path)
+	if rootSchema == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	document := rootSchema.Document()
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(document)))
+	w.Write(document)
+}
+
+// lookup returns the schema that is registered in the registry under an
+// http or https URI with the given path, preferring a URI with the given
+// host, or nil if there is none.
+func lookup(registry *jsonvalidator.Registry, host string, path string) *jsonvalidator.RootJsonSchema {
+	schemas := registry.Schemas()
+
+	// The URIs are sorted, so the same schema is served on every request.
+	uris := make([]string, 0, len(schemas))
+	for uri := range schemas {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+
+	var match *jsonvalidator.RootJsonSchema
+	for _, uri := range uris {
+		parsed, err := url.Parse(uri)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			continue
+		}
+
+		schemaPath := parsed.Path
+		if schemaPath == "" {
+			schemaPath = "/"
+		}
+		if schemaPath != path {
+			continue
+		}
+
+		if parsed.Host == host {
+			return schemas[uri]
+		}
+		if match == nil {
+			match = schemas[uri]
+		}
+	}
+
+	return match
+}
@@ -0,0 +1,102 @@
+package schemaserver_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/schemaserver"
+)
+
+func TestHandler(t *testing.T) {
+	const address = `{"type": "object", "properties": {"city": {"type": "string"}}}`
+	const person = `{
+		"$id": "https://schemas.example.com/server/person.json#",
+		"properties": {"address": {"$ref": "address.json"}}
+	}`
+
+	_, err := jsonvalidator.AddResource("https://schemas.example.com/server/address.json", []byte(address))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = jsonvalidator.NewRootJsonSchema([]byte(person))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(schemaserver.Handler())
+	defer server.Close()
+
+	testCases := []struct {
+		method string
+		path   string
+		status int
+		body   string
+	}{
+		{http.MethodGet, "/server/address.json", http.StatusOK, address},
+		{http.MethodGet, "/server/person.json", http.StatusOK, person},
+		{http.MethodHead, "/server/person.json", http.StatusOK, ""},
+		{http.MethodGet, "/server/missing.json", http.StatusNotFound, "404 page not found\n"},
+		{http.MethodPost, "/server/person.json", http.StatusMethodNotAllowed, "Method Not Allowed\n"},
+	}
+
+	for _, testCase := range testCases {
+		request, err := http.NewRequest(testCase.method, server.URL+testCase.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if response.StatusCode != testCase.status {
+			t.Errorf("%s %s: expected status %d, got %d", testCase.method, testCase.path, testCase.status, response.StatusCode)
+		}
+		if string(body) != testCase.body {
+			t.Errorf("%s %s: expected body %q, got %q", testCase.method, testCase.path, testCase.body, body)
+		}
+		if contentType := response.Header.Get("Content-Type"); testCase.status == http.StatusOK && contentType != schemaserver.ContentType {
+			t.Errorf("%s %s: expected content type %q, got %q", testCase.method, testCase.path, schemaserver.ContentType, contentType)
+		}
+	}
+}
+
+func TestHandlerFor(t *testing.T) {
+	const uri = "https://schemas.example.com/registry/item.json"
+	const item = `{"type": "string"}`
+
+	registry := jsonvalidator.NewRegistry()
+	_, err := registry.AddSchema(uri, []byte(item))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		handler http.Handler
+		status  int
+	}{
+		{schemaserver.HandlerFor(registry), http.StatusOK},
+		{schemaserver.Handler(), http.StatusNotFound},
+	}
+
+	for _, testCase := range testCases {
+		recorder := httptest.NewRecorder()
+		testCase.handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, uri, nil))
+
+		if recorder.Code != testCase.status {
+			t.Errorf("expected status %d, got %d", testCase.status, recorder.Code)
+		}
+		if testCase.status == http.StatusOK && recorder.Body.String() != item {
+			t.Errorf("expected body %q, got %q", item, recorder.Body.String())
+		}
+	}
+}
@@ -0,0 +1,610 @@
+// Package schematest generates json values that are valid against a json
+// schema, for property-based testing of code that consumes schema
+// conforming data.
+//
+// A Generator can be plugged into testing/quick:
+//
+//	generator := schematest.New(rootSchema)
+//	property := func(value interface{}) bool {
+//		return process(value) == nil
+//	}
+//	err := quick.Check(property, &quick.Config{Values: generator.Values(property)})
+//
+// or used with Check, which also shrinks a failing value to a smaller one
+// before reporting it.
+package schematest
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/internal/schemautil"
+)
+
+// The number of generated candidates that Generate() tries before it gives
+// up on a schema.
+const maxAttempts = 100
+
+// The nesting level of schemas below which optional properties and array
+// items are no longer generated, so recursive schemas produce finite values.
+const maxDepth = 8
+
+// ErrNoInstance is returned by Generator.Generate() if it could not generate
+// a value that is valid against the schema, which happens for unsatisfiable
+// schemas and for schemas whose constraints are too tight for random
+// generation.
+var ErrNoInstance = errors.New("schematest: could not generate a valid instance")
+
+// Generator generates json values that are valid against a root schema, and
+// shrinks them to smaller valid values.
+// Values are represented like encoding/json decodes them into an empty
+// interface: nil, bool, float64, string, []interface{} and
+// map[string]interface{}.
+type Generator struct {
+	schema    *jsonvalidator.RootJsonSchema
+	validator *jsonvalidator.Validator
+}
+
+// New creates a Generator for the given root schema.
+func New(schema *jsonvalidator.RootJsonSchema) *Generator {
+	return &Generator{
+		schema:    schema,
+		validator: jsonvalidator.NewValidator(schema),
+	}
+}
+
+// Generate returns a random value that is valid against the schema. size
+// bounds the length of the generated strings and arrays and the number of
+// optional properties, like the size argument of quick.Generator.
+// It returns ErrNoInstance if it could not generate a valid value.
+func (g *Generator) Generate(random *rand.Rand, size int) (interface{}, error) {
+	root, err := schemautil.Map(&g.schema.JsonSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		state := &generation{generator: g, random: random, size: size}
+		value, err := state.generate(root, 0)
+		if err == nil && g.Valid(value) {
+			return value, nil
+		}
+	}
+
+	return nil, ErrNoInstance
+}
+
+// Valid returns true if value is valid against the schema.
+func (g *Generator) Valid(value interface{}) bool {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+
+	return g.validator.Validate(bytes) == nil
+}
+
+// Values returns a function that can be used as the Values field of a
+// quick.Config for the tested function f. It fills every argument of f with
+// a generated value, where arguments of types other than interface{},
+// json.RawMessage and []byte are unmarshalled from the json encoding of the
+// value. The returned function panics if a value could not be generated.
+func (g *Generator) Values(f interface{}) func([]reflect.Value, *rand.Rand) {
+	function := reflect.TypeOf(f)
+	return func(args []reflect.Value, random *rand.Rand) {
+		for index := range args {
+			value, err := g.Generate(random, 10)
+			if err != nil {
+				panic(err)
+			}
+
+			arg, err := convert(value, function.In(index))
+			if err != nil {
+				panic(err)
+			}
+
+			args[index] = arg
+		}
+	}
+}
+
+// Check generates count values and passes each of them to property. If
+// property returns false for a value, the value is shrunk to the smallest
+// valid value for which property still returns false, and the failure is
+// reported through t with the seed of the random generator.
+func Check(t testing.TB, g *Generator, count int, property func(value interface{}) bool) {
+	t.Helper()
+
+	seed := time.Now().UnixNano()
+	random := rand.New(rand.NewSource(seed))
+	for iteration := 0; iteration < count; iteration++ {
+		value, err := g.Generate(random, 1+iteration%20)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+
+		if !property(value) {
+			value = g.shrinkFailure(value, property)
+			bytes, _ := json.Marshal(value)
+			t.Fatalf("seed %d: property failed for %s", seed, bytes)
+		}
+	}
+}
+
+// shrinkFailure repeatedly replaces value by the first of its shrunk values
+// for which property fails, until none of them fails.
+func (g *Generator) shrinkFailure(value interface{}, property func(value interface{}) bool) interface{} {
+	for shrunk := true; shrunk; {
+		shrunk = false
+		for _, candidate := range g.Shrink(value) {
+			if !property(candidate) {
+				value, shrunk = candidate, true
+				break
+			}
+		}
+	}
+
+	return value
+}
+
+// generation holds the state of a single generated value.
+type generation struct {
+	generator *Generator
+	random    *rand.Rand
+	size      int
+}
+
+// generate returns a random value for a schema in its json form, which is not
+// necessarily valid against the schema. The candidates are validated by
+// Generate().
+func (state *generation) generate(schema interface{}, depth int) (interface{}, error) {
+	object, ok := schema.(map[string]interface{})
+	if !ok {
+		// A boolean schema.
+		if schema == false {
+			return nil, ErrNoInstance
+		}
+
+		return state.generateAny(depth), nil
+	}
+
+	if rejectAll, _ := object["rejectAll"].(bool); rejectAll {
+		return nil, ErrNoInstance
+	}
+
+	// "$ref" overrides the other keywords of the schema.
+	if reference, ok := object["$ref"].(string); ok {
+		resolved, err := state.generator.schema.Resolve(reference)
+		if err != nil {
+			return nil, err
+		}
+
+		object, err = schemautil.Map(resolved)
+		if err != nil {
+			return nil, err
+		}
+
+		return state.generate(object, depth+1)
+	}
+
+	if value, ok := object["const"]; ok {
+		return value, nil
+	}
+
+	if values, ok := object["enum"].([]interface{}); ok && len(values) > 0 {
+		return values[state.random.Intn(len(values))], nil
+	}
+
+	// The examples of a schema are valid values, which are preferred now and
+	// then.
+	if examples, ok := object["examples"].([]interface{}); ok && len(examples) > 0 && state.random.Intn(4) == 0 {
+		return examples[state.random.Intn(len(examples))], nil
+	}
+
+	// The sub-schemas of "allOf" and one of the sub-schemas of "anyOf" and
+	// "oneOf" are merged into the schema, so the generated value is likely to
+	// be valid against all of them.
+	if subSchemas, ok := object["allOf"].([]interface{}); ok {
+		for _, subSchema := range subSchemas {
+			object = merge(object, subSchema)
+		}
+		delete(object, "allOf")
+	}
+
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		if subSchemas, ok := object[keyword].([]interface{}); ok && len(subSchemas) > 0 {
+			object = merge(object, subSchemas[state.random.Intn(len(subSchemas))])
+			delete(object, keyword)
+			return state.generate(object, depth)
+		}
+	}
+
+	switch state.chooseType(object) {
+	case "null":
+		return nil, nil
+	case "boolean":
+		return state.random.Intn(2) == 0, nil
+	case "integer":
+		return state.generateNumber(object, true), nil
+	case "number":
+		return state.generateNumber(object, false), nil
+	case "string":
+		return state.generateString(object), nil
+	case "array":
+		return state.generateArray(object, depth)
+	case "object":
+		return state.generateObject(object, depth)
+	}
+
+	return state.generateAny(depth), nil
+}
+
+// chooseType returns one of the types that the schema allows. If the schema
+// does not have a "type" keyword, the type is guessed from its other
+// keywords.
+func (state *generation) chooseType(object map[string]interface{}) string {
+	switch types := object["type"].(type) {
+	case string:
+		return types
+	case []interface{}:
+		if len(types) > 0 {
+			name, _ := types[state.random.Intn(len(types))].(string)
+			return name
+		}
+	}
+
+	for _, guess := range []struct {
+		name     string
+		keywords []string
+	}{
+		{"object", []string{"properties", "required", "additionalProperties", "patternProperties", "minProperties", "maxProperties", "propertyNames", "dependencies"}},
+		{"array", []string{"items", "additionalItems", "minItems", "maxItems", "uniqueItems", "contains"}},
+		{"string", []string{"minLength", "maxLength", "pattern", "format"}},
+		{"number", []string{"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf"}},
+	} {
+		for _, keyword := range guess.keywords {
+			if _, ok := object[keyword]; ok {
+				return guess.name
+			}
+		}
+	}
+
+	return ""
+}
+
+// generateAny returns a random value of a random type.
+func (state *generation) generateAny(depth int) interface{} {
+	limit := 6
+	if depth >= maxDepth {
+		limit = 4
+	}
+
+	switch state.random.Intn(limit) {
+	case 0:
+		return nil
+	case 1:
+		return state.random.Intn(2) == 0
+	case 2:
+		return float64(state.random.Intn(2*state.size+1) - state.size)
+	case 3:
+		return randomString(state.random, state.random.Intn(state.size+1))
+	case 4:
+		return []interface{}{state.generateAny(depth + 1)}
+	default:
+		return map[string]interface{}{randomString(state.random, 3): state.generateAny(depth + 1)}
+	}
+}
+
+// generateNumber returns a random number within the bounds of the schema.
+func (state *generation) generateNumber(object map[string]interface{}, integer bool) interface{} {
+	low, high := -float64(state.size)*10, float64(state.size)*10
+	minimum, hasMinimum := object["minimum"].(float64)
+	maximum, hasMaximum := object["maximum"].(float64)
+
+	// "exclusiveMinimum" and "exclusiveMaximum" are numbers since draft-06,
+	// and booleans that modify "minimum" and "maximum" in draft-04.
+	exclusiveMinimum, exclusive := object["exclusiveMinimum"].(float64)
+	if isExclusive, _ := object["exclusiveMinimum"].(bool); isExclusive && hasMinimum {
+		exclusiveMinimum, exclusive = minimum, true
+	}
+	exclusiveMaximum, exclusiveMax := object["exclusiveMaximum"].(float64)
+	if isExclusive, _ := object["exclusiveMaximum"].(bool); isExclusive && hasMaximum {
+		exclusiveMaximum, exclusiveMax = maximum, true
+	}
+
+	if hasMinimum {
+		low = minimum
+	}
+	if exclusive {
+		low = math.Max(low, exclusiveMinimum)
+	}
+	if hasMaximum {
+		high = maximum
+	}
+	if exclusiveMax {
+		high = math.Min(high, exclusiveMaximum)
+	}
+	if !hasMaximum && !exclusiveMax && high < low {
+		high = low + float64(state.size)*10
+	}
+	if !hasMinimum && !exclusive && low > high {
+		low = high - float64(state.size)*10
+	}
+
+	// A "multipleOf" number is generated as a multiple of its factor.
+	step := 1.0
+	if factor, ok := object["multipleOf"].(float64); ok && factor > 0 {
+		step = factor
+		if integer && factor != math.Trunc(factor) {
+			step = factor * float64(state.random.Intn(10)+1)
+		}
+	} else if !integer {
+		// Exclusive bounds are not hit exactly by a random fraction.
+		return low + state.random.Float64()*(high-low)
+	}
+
+	first, last := math.Ceil(low/step), math.Floor(high/step)
+	if last < first {
+		return first * step
+	}
+
+	return (first + math.Floor(state.random.Float64()*(last-first+1))) * step
+}
+
+// generateString returns a random string for the "pattern", the "format" or
+// the length bounds of the schema.
+func (state *generation) generateString(object map[string]interface{}) interface{} {
+	if pattern, ok := object["pattern"].(string); ok {
+		if value, ok := generatePattern(state.random, pattern, state.size); ok {
+			return value
+		}
+	}
+
+	if format, ok := object["format"].(string); ok {
+		if generator, ok := formatGenerators[format]; ok {
+			return generator(state.random)
+		}
+	}
+
+	minLength, maxLength := bounds(object, "minLength", "maxLength", state.size)
+	return randomString(state.random, minLength+state.random.Intn(maxLength-minLength+1))
+}
+
+// generateArray returns an array whose items are generated from "items",
+// "additionalItems" and "contains".
+func (state *generation) generateArray(object map[string]interface{}, depth int) (interface{}, error) {
+	minItems, maxItems := bounds(object, "minItems", "maxItems", state.size)
+	if depth >= maxDepth {
+		maxItems = minItems
+	}
+
+	tuple, isTuple := object["items"].([]interface{})
+	length := minItems + state.random.Intn(maxItems-minItems+1)
+	if isTuple && length > len(tuple) {
+		if additionalItems, ok := object["additionalItems"]; ok && !allowsAny(additionalItems) && len(tuple) >= minItems {
+			length = len(tuple)
+		}
+	}
+
+	array := make([]interface{}, 0, length)
+	unique, _ := object["uniqueItems"].(bool)
+	for index := 0; index < length; index++ {
+		var schema interface{} = true
+		switch {
+		case isTuple && index < len(tuple):
+			schema = tuple[index]
+		case isTuple:
+			if additionalItems, ok := object["additionalItems"]; ok {
+				schema = additionalItems
+			}
+		default:
+			if items, ok := object["items"]; ok {
+				schema = items
+			}
+		}
+
+		// Unique items are generated again until they differ from the
+		// previous items.
+		var item interface{}
+		var err error
+		for attempt := 0; attempt < maxAttempts; attempt++ {
+			item, err = state.generate(schema, depth+1)
+			if err != nil || !unique || !schemautil.ContainsValue(array, item) {
+				break
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		array = append(array, item)
+	}
+
+	// One of the items is replaced by a value for "contains".
+	if contains, ok := object["contains"]; ok {
+		item, err := state.generate(contains, depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(array) == 0 || isTuple {
+			array = append(array, item)
+		} else {
+			array[state.random.Intn(len(array))] = item
+		}
+	}
+
+	return array, nil
+}
+
+// generateObject returns an object with the required properties of the
+// schema and a random selection of its other properties.
+func (state *generation) generateObject(object map[string]interface{}, depth int) (interface{}, error) {
+	result := map[string]interface{}{}
+	properties, _ := object["properties"].(map[string]interface{})
+
+	required := map[string]bool{}
+	if names, ok := object["required"].([]interface{}); ok {
+		for _, name := range names {
+			if name, ok := name.(string); ok {
+				required[name] = true
+			}
+		}
+	}
+
+	// The properties are visited in a stable order, so a seeded random
+	// generator always generates the same values.
+	names := make([]string, 0, len(properties)+len(required))
+	for name := range properties {
+		names = append(names, name)
+	}
+	for name := range required {
+		if _, ok := properties[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !required[name] && (depth >= maxDepth || state.random.Intn(2) == 0) {
+			continue
+		}
+
+		var schema interface{} = true
+		if propertySchema, ok := properties[name]; ok {
+			schema = propertySchema
+		} else if additionalProperties, ok := object["additionalProperties"]; ok {
+			schema = additionalProperties
+		}
+
+		value, err := state.generate(schema, depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		result[name] = value
+	}
+
+	// Additional properties are added until the object has enough of them.
+	minProperties, _ := object["minProperties"].(float64)
+	additionalProperties, hasAdditional := object["additionalProperties"]
+	if !hasAdditional {
+		additionalProperties = true
+	}
+	for index := 0; len(result) < int(minProperties) && index < maxAttempts; index++ {
+		name := "p" + strconv.Itoa(index)
+		if _, ok := result[name]; ok {
+			continue
+		}
+
+		value, err := state.generate(additionalProperties, depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		result[name] = value
+	}
+
+	return result, nil
+}
+
+// merge returns a copy of object with the keywords of schema added to it.
+// The properties and the required properties of both schemas are combined,
+// and other keywords of schema replace those of object.
+func merge(object map[string]interface{}, schema interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(object))
+	for keyword, value := range object {
+		merged[keyword] = value
+	}
+
+	other, ok := schema.(map[string]interface{})
+	if !ok {
+		if schema == false {
+			merged["rejectAll"] = true
+		}
+		return merged
+	}
+
+	for keyword, value := range other {
+		switch keyword {
+		case "properties":
+			properties := map[string]interface{}{}
+			if existing, ok := merged["properties"].(map[string]interface{}); ok {
+				for name, propertySchema := range existing {
+					properties[name] = propertySchema
+				}
+			}
+			if added, ok := value.(map[string]interface{}); ok {
+				for name, propertySchema := range added {
+					properties[name] = propertySchema
+				}
+			}
+			merged["properties"] = properties
+		case "required":
+			existing, _ := merged["required"].([]interface{})
+			added, _ := value.([]interface{})
+			merged["required"] = append(append([]interface{}{}, existing...), added...)
+		default:
+			merged[keyword] = value
+		}
+	}
+
+	return merged
+}
+
+// bounds returns the lower and upper bounds of a length, as given by the
+// schema keywords minimum and maximum. A missing upper bound is size
+// elements above the lower bound.
+func bounds(object map[string]interface{}, minimum, maximum string, size int) (int, int) {
+	low, _ := object[minimum].(float64)
+	high, ok := object[maximum].(float64)
+	if !ok || high > low+float64(size) {
+		high = low + float64(size)
+	}
+	if high < low {
+		high = low
+	}
+
+	return int(low), int(high)
+}
+
+// allowsAny returns true if schema is the "true" schema or an empty one.
+func allowsAny(schema interface{}) bool {
+	if object, ok := schema.(map[string]interface{}); ok {
+		return len(object) == 0
+	}
+
+	return schema == true
+}
+
+// convert converts a generated value into a reflect.Value of the given type.
+func convert(value interface{}, target reflect.Type) (reflect.Value, error) {
+	if target.Kind() == reflect.Interface && reflect.TypeOf(value) != nil && reflect.TypeOf(value).Implements(target) {
+		return reflect.ValueOf(value), nil
+	}
+
+	if target.Kind() == reflect.Interface && value == nil {
+		return reflect.Zero(target), nil
+	}
+
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	if target == reflect.TypeOf(json.RawMessage{}) || target == reflect.TypeOf([]byte{}) {
+		return reflect.ValueOf(bytes).Convert(target), nil
+	}
+
+	converted := reflect.New(target)
+	err = json.Unmarshal(bytes, converted.Interface())
+	return converted.Elem(), err
+}
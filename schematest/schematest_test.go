@@ -0,0 +1,159 @@
+package schematest_test
+
+import (
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	"github.com/itayankri/gojsonvalidator"
+	"github.com/itayankri/gojsonvalidator/schematest"
+)
+
+const orderSchema = `{
+	"$id": "http://example.com/schematest/order.json",
+	"type": "object",
+	"required": ["id", "customer", "items"],
+	"properties": {
+		"id": {"type": "string", "pattern": "^ORD-[0-9]{4}$"},
+		"customer": {"$ref": "#/definitions/customer"},
+		"items": {
+			"type": "array",
+			"minItems": 1,
+			"maxItems": 5,
+			"uniqueItems": true,
+			"items": {
+				"type": "object",
+				"required": ["sku", "quantity"],
+				"properties": {
+					"sku": {"type": "string", "minLength": 3, "maxLength": 8},
+					"quantity": {"type": "integer", "minimum": 1, "exclusiveMaximum": 100},
+					"price": {"type": "number", "multipleOf": 0.5, "minimum": 0}
+				}
+			}
+		},
+		"status": {"enum": ["new", "paid", "shipped"]},
+		"payment": {
+			"oneOf": [
+				{"type": "object", "required": ["card"], "properties": {"card": {"type": "string", "pattern": "^[0-9]{16}$"}}, "additionalProperties": false},
+				{"type": "object", "required": ["iban"], "properties": {"iban": {"type": "string", "minLength": 15}}, "additionalProperties": false}
+			]
+		},
+		"created": {"type": "string", "format": "date-time"}
+	},
+	"definitions": {
+		"customer": {
+			"type": "object",
+			"required": ["email"],
+			"properties": {
+				"email": {"type": "string", "format": "email"},
+				"tags": {"type": "array", "items": {"type": "string"}}
+			}
+		}
+	}
+}`
+
+func newGenerator(t *testing.T, schema string) *schematest.Generator {
+	rootSchema, err := jsonvalidator.NewRootJsonSchema([]byte(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return schematest.New(rootSchema)
+}
+
+func TestGenerate(t *testing.T) {
+	generator := newGenerator(t, orderSchema)
+
+	random := rand.New(rand.NewSource(1))
+	for size := 0; size < 200; size++ {
+		value, err := generator.Generate(random, size%10)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+
+		if !generator.Valid(value) {
+			t.Fatalf("size %d: generated an invalid value %v", size, value)
+		}
+	}
+}
+
+func TestGenerateUnsatisfiable(t *testing.T) {
+	generator := newGenerator(t, `{"type": "integer", "minimum": 3, "maximum": 2}`)
+
+	_, err := generator.Generate(rand.New(rand.NewSource(1)), 5)
+	if err != schematest.ErrNoInstance {
+		t.Errorf("expected ErrNoInstance, got %v", err)
+	}
+}
+
+func TestValues(t *testing.T) {
+	generator := newGenerator(t, orderSchema)
+
+	type order struct {
+		ID    string `json:"id"`
+		Items []struct {
+			Quantity int `json:"quantity"`
+		} `json:"items"`
+	}
+
+	property := func(o order) bool {
+		if len(o.ID) != 8 || len(o.Items) == 0 {
+			return false
+		}
+
+		for _, item := range o.Items {
+			if item.Quantity < 1 || item.Quantity >= 100 {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	err := quick.Check(property, &quick.Config{MaxCount: 50, Values: generator.Values(property)})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestShrink(t *testing.T) {
+	generator := newGenerator(t, `{
+		"type": "object",
+		"required": ["name"],
+		"properties": {
+			"name": {"type": "string", "minLength": 2},
+			"tags": {"type": "array", "items": {"type": "integer", "minimum": -5}}
+		}
+	}`)
+
+	value := map[string]interface{}{
+		"name": "abcdef",
+		"tags": []interface{}{4.0, -3.0},
+	}
+
+	candidates := generator.Shrink(value)
+	if len(candidates) == 0 {
+		t.Fatal("expected the value to be shrunk")
+	}
+
+	for _, candidate := range candidates {
+		if !generator.Valid(candidate) {
+			t.Errorf("expected only valid candidates, got %v", candidate)
+		}
+
+		// The name is required, and may not be shorter than 2 characters.
+		name, ok := candidate.(map[string]interface{})["name"].(string)
+		if !ok || len(name) < 2 {
+			t.Errorf("expected the name to be kept valid, got %v", candidate)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	generator := newGenerator(t, orderSchema)
+
+	schematest.Check(t, generator, 100, func(value interface{}) bool {
+		items := value.(map[string]interface{})["items"].([]interface{})
+		return len(items) >= 1 && len(items) <= 5
+	})
+}
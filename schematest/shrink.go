@@ -0,0 +1,89 @@
+package schematest
+
+import (
+	"math"
+	"sort"
+)
+
+// Shrink returns smaller variants of a value that are still valid against the
+// schema, from the smallest to the largest change: properties and array
+// items are removed, strings are shortened, numbers are moved towards zero
+// and nested values are shrunk.
+func (g *Generator) Shrink(value interface{}) []interface{} {
+	var valid []interface{}
+	for _, candidate := range shrinkValue(value) {
+		if g.Valid(candidate) {
+			valid = append(valid, candidate)
+		}
+	}
+
+	return valid
+}
+
+// shrinkValue returns the smaller variants of a value, whether they are valid
+// or not.
+func shrinkValue(value interface{}) []interface{} {
+	var candidates []interface{}
+
+	switch v := value.(type) {
+	case bool:
+		if v {
+			candidates = append(candidates, false)
+		}
+	case float64:
+		for _, smaller := range []float64{0, math.Trunc(v / 2), math.Trunc(v)} {
+			if math.Abs(smaller) < math.Abs(v) {
+				candidates = append(candidates, smaller)
+			}
+		}
+	case string:
+		if len(v) > 0 {
+			candidates = append(candidates, "", v[:len(v)/2], v[:len(v)-1])
+		}
+	case []interface{}:
+		for index := range v {
+			candidates = append(candidates, append(append([]interface{}{}, v[:index]...), v[index+1:]...))
+		}
+
+		for index, item := range v {
+			for _, shrunk := range shrinkValue(item) {
+				array := append([]interface{}{}, v...)
+				array[index] = shrunk
+				candidates = append(candidates, array)
+			}
+		}
+	case map[string]interface{}:
+		// The properties are visited in a stable order, so the smallest
+		// failing value does not depend on the order of map iteration.
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			object := copyObject(v)
+			delete(object, name)
+			candidates = append(candidates, object)
+		}
+
+		for _, name := range names {
+			for _, shrunk := range shrinkValue(v[name]) {
+				object := copyObject(v)
+				object[name] = shrunk
+				candidates = append(candidates, object)
+			}
+		}
+	}
+
+	return candidates
+}
+
+func copyObject(object map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(object))
+	for name, value := range object {
+		copied[name] = value
+	}
+
+	return copied
+}
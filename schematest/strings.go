@@ -0,0 +1,209 @@
+package schematest
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"math/rand"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const letters = "abcdefghijklmnopqrstuvwxyz"
+
+// randomString returns a string of lower case letters of the given length.
+func randomString(random *rand.Rand, length int) string {
+	bytes := make([]byte, length)
+	for index := range bytes {
+		bytes[index] = letters[random.Intn(len(letters))]
+	}
+
+	return string(bytes)
+}
+
+// generatePattern returns a random string that matches the regular
+// expression pattern. Repetitions are limited to size repeats beyond their
+// minimum. It returns false if the pattern cannot be parsed.
+func generatePattern(random *rand.Rand, pattern string, size int) (string, bool) {
+	regexp, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+
+	var builder strings.Builder
+	if !writePattern(&builder, random, regexp.Simplify(), size) {
+		return "", false
+	}
+
+	return builder.String(), true
+}
+
+func writePattern(builder *strings.Builder, random *rand.Rand, regexp *syntax.Regexp, size int) bool {
+	switch regexp.Op {
+	case syntax.OpNoMatch:
+		return false
+	case syntax.OpLiteral:
+		builder.WriteString(string(regexp.Rune))
+	case syntax.OpCharClass:
+		if len(regexp.Rune) == 0 {
+			return false
+		}
+
+		// Pick a range of the class, and a rune near the start of the range,
+		// which keeps negated classes printable.
+		pair := random.Intn(len(regexp.Rune) / 2)
+		low, high := regexp.Rune[2*pair], regexp.Rune[2*pair+1]
+		if high-low > 94 {
+			high = low + 94
+		}
+
+		r := low + rune(random.Intn(int(high-low)+1))
+		if !utf8.ValidRune(r) {
+			r = low
+		}
+		builder.WriteRune(r)
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		builder.WriteByte(letters[random.Intn(len(letters))])
+	case syntax.OpCapture:
+		return writePattern(builder, random, regexp.Sub[0], size)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		minimum, maximum := 0, size
+		switch regexp.Op {
+		case syntax.OpPlus:
+			minimum, maximum = 1, size+1
+		case syntax.OpQuest:
+			maximum = 1
+		case syntax.OpRepeat:
+			minimum, maximum = regexp.Min, regexp.Max
+			if maximum == -1 || maximum > minimum+size {
+				maximum = minimum + size
+			}
+		}
+
+		for count := minimum + random.Intn(maximum-minimum+1); count > 0; count-- {
+			if !writePattern(builder, random, regexp.Sub[0], size) {
+				return false
+			}
+		}
+	case syntax.OpConcat:
+		for _, sub := range regexp.Sub {
+			if !writePattern(builder, random, sub, size) {
+				return false
+			}
+		}
+	case syntax.OpAlternate:
+		return writePattern(builder, random, regexp.Sub[random.Intn(len(regexp.Sub))], size)
+	}
+
+	// Empty matches, anchors and word boundaries do not add characters.
+	return true
+}
+
+// formatGenerators generate random strings of the formats that are defined by
+// the json schema specification.
+var formatGenerators = map[string]func(random *rand.Rand) string{
+	"date-time": func(random *rand.Rand) string {
+		return randomDate(random) + "T" + randomTime(random)
+	},
+	"date": randomDate,
+	"time": randomTime,
+	"email": func(random *rand.Rand) string {
+		return randomString(random, 1+random.Intn(8)) + "@example.com"
+	},
+	"idn-email": func(random *rand.Rand) string {
+		return randomString(random, 1+random.Intn(8)) + "@example.com"
+	},
+	"hostname":     randomHostname,
+	"idn-hostname": randomHostname,
+	"ipv4": func(random *rand.Rand) string {
+		octets := make([]string, 4)
+		for index := range octets {
+			octets[index] = strconv.Itoa(random.Intn(256))
+		}
+		return strings.Join(octets, ".")
+	},
+	"ipv6": func(random *rand.Rand) string {
+		groups := make([]string, 8)
+		for index := range groups {
+			groups[index] = strconv.FormatInt(int64(random.Intn(0x10000)), 16)
+		}
+		return strings.Join(groups, ":")
+	},
+	"uri": func(random *rand.Rand) string {
+		return "https://" + randomHostname(random) + "/" + randomString(random, random.Intn(8))
+	},
+	"uri-reference": func(random *rand.Rand) string {
+		return "/" + randomString(random, random.Intn(8))
+	},
+	"iri": func(random *rand.Rand) string {
+		return "https://" + randomHostname(random) + "/" + randomString(random, random.Intn(8))
+	},
+	"iri-reference": func(random *rand.Rand) string {
+		return "/" + randomString(random, random.Intn(8))
+	},
+	"uri-template": func(random *rand.Rand) string {
+		return "/" + randomString(random, 1+random.Intn(5)) + "/{" + randomString(random, 1+random.Intn(5)) + "}"
+	},
+	"json-pointer": func(random *rand.Rand) string {
+		return "/" + randomString(random, random.Intn(5))
+	},
+	"relative-json-pointer": func(random *rand.Rand) string {
+		return strconv.Itoa(random.Intn(3)) + "/" + randomString(random, random.Intn(5))
+	},
+	"json-pointer-uri-fragment": func(random *rand.Rand) string {
+		return "#/" + randomString(random, random.Intn(5))
+	},
+	"regex": func(random *rand.Rand) string {
+		return "^" + randomString(random, random.Intn(5)) + "$"
+	},
+	"semver": func(random *rand.Rand) string {
+		return strconv.Itoa(random.Intn(10)) + "." + strconv.Itoa(random.Intn(10)) + "." + strconv.Itoa(random.Intn(10))
+	},
+	"phone": randomE164,
+	"e164":  randomE164,
+	"base64": func(random *rand.Rand) string {
+		return base64.StdEncoding.EncodeToString([]byte(randomString(random, random.Intn(12))))
+	},
+	"base64url": func(random *rand.Rand) string {
+		return base64.URLEncoding.EncodeToString([]byte(randomString(random, random.Intn(12))))
+	},
+	"uuid": func(random *rand.Rand) string {
+		digits := make([]byte, 16)
+		random.Read(digits)
+		encoded := hex.EncodeToString(digits)
+		return encoded[:8] + "-" + encoded[8:12] + "-" + encoded[12:16] + "-" + encoded[16:20] + "-" + encoded[20:]
+	},
+	"duration": func(random *rand.Rand) string {
+		return "P" + strconv.Itoa(random.Intn(30)) + "DT" + strconv.Itoa(random.Intn(24)) + "H"
+	},
+}
+
+func randomDate(random *rand.Rand) string {
+	return strconv.Itoa(1970+random.Intn(100)) + "-" + pad(1+random.Intn(12)) + "-" + pad(1+random.Intn(28))
+}
+
+func randomTime(random *rand.Rand) string {
+	return pad(random.Intn(24)) + ":" + pad(random.Intn(60)) + ":" + pad(random.Intn(60)) + "Z"
+}
+
+func randomHostname(random *rand.Rand) string {
+	return randomString(random, 1+random.Intn(10)) + ".example.com"
+}
+
+func randomE164(random *rand.Rand) string {
+	number := "+" + strconv.Itoa(1+random.Intn(9))
+	for length := 1 + random.Intn(12); length > 0; length-- {
+		number += strconv.Itoa(random.Intn(10))
+	}
+	return number
+}
+
+// pad formats a number of two digits.
+func pad(number int) string {
+	if number < 10 {
+		return "0" + strconv.Itoa(number)
+	}
+
+	return strconv.Itoa(number)
+}
@@ -0,0 +1,115 @@
+package jsonvalidator
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// GetOrCompile returns the schema that is registered under uri in the
+// DefaultRegistry, or loads its document with load and registers it there,
+// like Registry.GetOrCompile().
+func GetOrCompile(uri string, load func() ([]byte, error)) (*RootJsonSchema, error) {
+	return DefaultRegistry.GetOrCompile(uri, load)
+}
+
+// GetOrCompile returns the schema that is registered under uri in the
+// registry, or loads its document with load and registers it with
+// AddSchema(). Concurrent calls for the same uri, like the requests that
+// arrive on a cold start, share a single load and compilation and all of
+// them get its result, so the schema is compiled once. Failures are not
+// remembered, so a later call tries again.
+func (r *Registry) GetOrCompile(uri string, load func() ([]byte, error)) (*RootJsonSchema, error) {
+	uri = resourceURI(uri)
+
+	return r.compilations.do(uri, func() (*RootJsonSchema, error) {
+		rootSchema, ok := r.Get(uri)
+		if ok && rootSchema != nil {
+			return rootSchema, nil
+		}
+
+		bytes, err := load()
+		if err != nil {
+			return nil, err
+		}
+
+		// The schemas of different URIs are compiled concurrently, and a
+		// schema that was registered under the URI meanwhile (like the
+		// schema of a Rewrite()) is returned instead.
+		rootSchema, scratch, err := r.compileAside(nil, bytes, uri, "")
+		if err != nil {
+			return nil, err
+		}
+
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		if registered, ok := r.schemas[uri]; ok && registered != nil {
+			return registered, nil
+		}
+		scratch.schemas[uri] = rootSchema
+		r.moveLocked(scratch)
+
+		return rootSchema, nil
+	})
+}
+
+// compileGroup runs a single compilation for every key at a time, whose
+// result is shared by the callers that wait for it.
+type compileGroup struct {
+	mutex sync.Mutex
+	calls map[string]*compileCall
+}
+
+// compileCall is a compilation that is running. waiters is the number of
+// the callers that wait for its result, and it is guarded by the mutex of
+// the group.
+type compileCall struct {
+	done    sync.WaitGroup
+	waiters int
+	schema  *RootJsonSchema
+	err     error
+}
+
+func (g *compileGroup) do(key string, compile func() (*RootJsonSchema, error)) (*RootJsonSchema, error) {
+	g.mutex.Lock()
+	if call, ok := g.calls[key]; ok {
+		call.waiters++
+		g.mutex.Unlock()
+		call.done.Wait()
+		return call.schema, call.err
+	}
+
+	call := &compileCall{}
+	call.done.Add(1)
+	g.calls[key] = call
+	g.mutex.Unlock()
+
+	// The waiters are released even if the compilation panics, in which
+	// case they get an error and the panic goes on in the caller.
+	defer func() {
+		g.mutex.Lock()
+		delete(g.calls, key)
+		g.mutex.Unlock()
+
+		call.done.Done()
+	}()
+
+	call.err = errors.New("the compilation of " + key + " panicked")
+	call.schema, call.err = compile()
+
+	return call.schema, call.err
+}
+
+// waiters returns the number of the callers that wait for the running
+// compilation of the key.
+func (g *compileGroup) waiters(key string) int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if call, ok := g.calls[key]; ok {
+		return call.waiters
+	}
+
+	return 0
+}
@@ -0,0 +1,138 @@
+package jsonvalidator
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrCompile(t *testing.T) {
+	uri := "http://example.com/singleflight/order.json"
+	registry := NewRegistry()
+
+	// The load is held until all the other callers wait for it.
+	var loads int32
+	release := make(chan struct{})
+	load := func() ([]byte, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return []byte(`{"properties": {"id": {"type": "integer"}}}`), nil
+	}
+
+	var wg sync.WaitGroup
+	schemas := make([]*RootJsonSchema, 20)
+	for index := range schemas {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+
+			var err error
+			schemas[index], err = registry.GetOrCompile(uri, load)
+			if err != nil {
+				t.Error(err)
+			}
+		}(index)
+	}
+	waitForWaiters(registry, uri, len(schemas)-1)
+	close(release)
+	wg.Wait()
+
+	if loads != 1 {
+		t.Errorf("expected the schema to be loaded once, got %d loads", loads)
+	}
+	for _, schema := range schemas {
+		if schema == nil || schema != schemas[0] {
+			t.Fatalf("expected all the callers to get the same schema")
+		}
+	}
+
+	// The registered schema is returned without loading it again.
+	schema, err := registry.GetOrCompile(uri+"#/properties/id", load)
+	if err != nil || schema != schemas[0] || loads != 1 {
+		t.Errorf("expected the registered schema, got %v, %v after %d loads", schema, err, loads)
+	}
+
+	// Failures are not remembered.
+	failed := errors.New("unavailable")
+	failingURI := "http://example.com/singleflight/failing.json"
+	if _, err := registry.GetOrCompile(failingURI, func() ([]byte, error) { return nil, failed }); err != failed {
+		t.Errorf("expected the load error, got %v", err)
+	}
+	if _, err := registry.GetOrCompile(failingURI, load); err != nil {
+		t.Errorf("expected the second call to compile the schema, got %v", err)
+	}
+}
+
+func TestGetOrCompilePanic(t *testing.T) {
+	uri := "http://example.com/singleflight/panic.json"
+	registry := NewRegistry()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	panicked := make(chan interface{})
+	go func() {
+		defer func() {
+			panicked <- recover()
+		}()
+
+		registry.GetOrCompile(uri, func() ([]byte, error) {
+			close(started)
+			<-release
+			panic("load failed")
+		})
+	}()
+
+	// A caller that waits for the panicking compilation gets an error.
+	<-started
+	waiter := make(chan error)
+	go func() {
+		_, err := registry.GetOrCompile(uri, func() ([]byte, error) {
+			return []byte(`{"type": "string"}`), nil
+		})
+		waiter <- err
+	}()
+	waitForWaiters(registry, uri, 1)
+	close(release)
+
+	if recovered := <-panicked; recovered != "load failed" {
+		t.Errorf("expected the panic to reach the caller, got %v", recovered)
+	}
+	select {
+	case err := <-waiter:
+		if err == nil {
+			t.Errorf("expected the waiter to get an error")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the waiter was not released")
+	}
+
+	// The panic is not remembered.
+	if _, err := registry.GetOrCompile(uri, func() ([]byte, error) { return []byte(`{"type": "string"}`), nil }); err != nil {
+		t.Errorf("expected the schema to compile, got %v", err)
+	}
+}
+
+func TestGetOrCompileDefaultRegistry(t *testing.T) {
+	uri := "http://example.com/singleflight/default.json"
+	defer DefaultRegistry.RemoveSchema(uri)
+
+	rootSchema, err := GetOrCompile(uri, func() ([]byte, error) { return []byte(`{"type": "string"}`), nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if registered, _ := DefaultRegistry.Get(uri); registered != rootSchema {
+		t.Errorf("expected the schema to be registered in the DefaultRegistry")
+	}
+}
+
+// waitForWaiters returns once the given number of callers wait for the
+// running compilation of the URI.
+func waitForWaiters(registry *Registry, uri string, waiters int) {
+	for registry.compilations.waiters(uri) < waiters {
+		runtime.Gosched()
+	}
+}
@@ -0,0 +1,253 @@
+package jsonvalidator
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// ValidateReader validates the json document that is read from r against the
+// validator's schema, like Validate(), without reading the whole document
+// into memory first. The document is decoded as it is read, and if it is an
+// array whose root schema only constrains its items and their number (with
+// "type", "items", "prefixItems", "additionalItems", "minItems" and
+// "maxItems"), its items are decoded and validated one at a time, so large
+// arrays are never held in memory as a whole. The items of such an array are
+// reported to the ItemFunc as they are read, even if the array turns out to
+// fail "minItems" or "maxItems". Documents that are not encoded in UTF-8,
+// and validators with a ResultCache or a custom InstanceDecoder, read the
+// whole document and validate it with Validate().
+func (v *Validator) ValidateReader(r io.Reader) error {
+	reader := bufio.NewReader(r)
+
+	// The UTF-8 byte order mark is skipped, and documents in other
+	// encodings are transcoded by Validate().
+	if prefix, _ := reader.Peek(len(utf8BOM)); string(prefix) == string(utf8BOM) {
+		reader.Discard(len(utf8BOM))
+	}
+	prefix, _ := reader.Peek(4)
+	if v.cache != nil || v.decoder != nil || !isPlainUTF8(prefix) {
+		bytes, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+
+		return v.Validate(bytes)
+	}
+
+	ctx := newValidationContext(v, 0)
+	return v.finish(v.schema.validateReader(reader, ctx), ctx)
+}
+
+// isPlainUTF8 returns true if the first bytes of a json document show that
+// it is encoded in UTF-8, without a byte order mark. Documents in UTF-16 and
+// UTF-32 start with a byte order mark or with a null byte.
+func isPlainUTF8(prefix []byte) bool {
+	for index, b := range prefix {
+		if b == 0 || (index == 0 && (b == 0xFE || b == 0xFF)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateReader decodes the json document that is read from reader, and
+// validates it against the root schema. Top-level arrays are streamed if
+// the root schema allows it (see streamsItems()).
+func (rs *RootJsonSchema) validateReader(reader *bufio.Reader, ctx *validationContext) error {
+	decoder := json.NewDecoder(reader)
+	if ctx.validator.strictNumbers {
+		decoder.UseNumber()
+	}
+
+	var err error
+	if startsArray(reader) && rs.streamsItems(ctx) {
+		err = rs.validateItemsStream(decoder, rs.id(), ctx)
+	} else {
+		var value interface{}
+		if decodeErr := decoder.Decode(&value); decodeErr != nil {
+			return errors.Wrap(decodeErr, "json data decoding failed")
+		}
+		if ctx.validator.strictNumbers {
+			value = canonicalizeNumbers(value)
+		}
+
+		err = rs.validateJsonData("", newJsonData(value), rs.id(), ctx)
+	}
+
+	// Like json.Unmarshal(), a document that cannot be decoded, or that has
+	// data after its top-level value, fails even if the failures of the
+	// values that were read were found already.
+	if decodeErr, ok := err.(decodingError); ok {
+		ctx.branches = nil
+		return decodeErr.err
+	}
+	if _, tokenErr := decoder.Token(); tokenErr != io.EOF {
+		if tokenErr == nil {
+			tokenErr = errors.New("invalid character after top-level value")
+		}
+		ctx.branches = nil
+		return errors.Wrap(tokenErr, "json data decoding failed")
+	}
+
+	return err
+}
+
+// decodingError is an error of decoding a streamed document, which takes
+// precedence over the failures that were found before it.
+type decodingError struct {
+	err error
+}
+
+func (e decodingError) Error() string {
+	return e.err.Error()
+}
+
+// startsArray skips the whitespace at the start of reader, and returns true
+// if the value that follows it is an array.
+func startsArray(reader *bufio.Reader) bool {
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return false
+		}
+
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+
+		reader.UnreadByte()
+		return b == '['
+	}
+}
+
+// streamsItems returns true if the root schema only constrains the items of
+// an array and their number, so the items of a top-level array can be
+// validated one at a time. The schema must allow arrays, so the failure of
+// "type" does not depend on the items.
+func (rs *RootJsonSchema) streamsItems(ctx *validationContext) bool {
+	if rs.RejectAll || rs.Ref != nil || rs.Transform != nil || len(rs.extensions) > 0 ||
+		ctx.profiling() || ctx.collectsOutput || ctx.injectDefaults {
+		return false
+	}
+
+	for _, keyword := range rs.keywordValidators() {
+		switch keyword.(type) {
+		case *minItems, *maxItems, *tupleItems:
+		case *_type:
+			if rs.Type.validate("", newJsonData([]interface{}{}), "", ctx) != nil {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateItemsStream validates a top-level array, whose items are decoded
+// from decoder one at a time, against the root schema, for which
+// streamsItems() is true. The failures are reported like validateJsonData()
+// reports them: the failures of "minItems", "maxItems" and of the length of
+// "items" come before the failures of the items, so the items are counted
+// to the end of the array even after an item failed.
+func (rs *RootJsonSchema) validateItemsStream(decoder *json.Decoder, rootSchemaId string, ctx *validationContext) error {
+	ctx.depth++
+	defer func() {
+		ctx.depth--
+	}()
+
+	err := ctx.visit([]interface{}{})
+	if err != nil {
+		return err
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return decodingError{errors.Wrap(err, "json data decoding failed")}
+	}
+
+	// firstErr holds the first item failure. Once an item failed, the
+	// rest of the items are only counted, unless they are reported to the
+	// validator's ItemFunc.
+	var firstErr error
+	applies := rs.tuple != nil
+	length := 0
+	for ; decoder.More(); length++ {
+		if !applies || (firstErr != nil && !ctx.reportsItems()) {
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return decodingError{errors.Wrap(err, "json data decoding failed")}
+			}
+			continue
+		}
+
+		var item interface{}
+		if err := decoder.Decode(&item); err != nil {
+			return decodingError{errors.Wrap(err, "json data decoding failed")}
+		}
+		if ctx.validator.strictNumbers {
+			item = canonicalizeNumbers(item)
+		}
+
+		var err error
+		applies, err = rs.tuple.validateIndex("", length, item, rootSchemaId, ctx)
+		if !applies {
+			continue
+		}
+		if ctx.abortErr != nil {
+			return ctx.abortErr
+		}
+
+		if ctx.reportsItems() {
+			ctx.reportItem(length, err)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return decodingError{errors.Wrap(err, "json data decoding failed")}
+	}
+
+	// The keywords are checked in the order of validateJsonData().
+	for _, keyword := range rs.keywordValidators() {
+		var err error
+		switch v := keyword.(type) {
+		case *minItems:
+			err = v.validateLength(length)
+		case *maxItems:
+			err = v.validateLength(length)
+		case *tupleItems:
+			err = v.validateLength(length)
+			if err == nil {
+				err = firstErr
+			}
+		}
+
+		if err != nil {
+			if _, ok := err.(SchemaValidationError); !ok {
+				err = ctx.schemaValidationError("", err)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// keywordValidators returns the keywords of the schema in the order that
+// validateJsonData() validates them.
+func (js *JsonSchema) keywordValidators() []keywordValidator {
+	if js.keywords == nil {
+		return getNonNilKeywordsSlice(js)
+	}
+
+	return js.keywords
+}
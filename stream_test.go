@@ -0,0 +1,81 @@
+package jsonvalidator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateReader(t *testing.T) {
+	schemas := []string{
+		// Streamed.
+		`{"$id": "http://example.com/stream/items.json", "type": "array", "minItems": 2, "maxItems": 4, "items": {"type": "integer", "minimum": 0}}`,
+		`{"$id": "http://example.com/stream/tuple.json", "items": [{"type": "string"}, {"type": "integer"}], "additionalItems": {"type": "boolean"}}`,
+		`{"$id": "http://example.com/stream/count.json", "type": ["array", "object"], "maxItems": 3}`,
+		// Decoded as a whole.
+		`{"$id": "http://example.com/stream/unique.json", "uniqueItems": true, "items": {"type": "integer"}}`,
+		`{"$id": "http://example.com/stream/object.json", "type": "object", "required": ["id"]}`,
+	}
+	documents := []string{
+		`[1, 2, 3]`,
+		`[1]`,
+		`[1, 2, 3, 4, 5]`,
+		`[1, -2, 3]`,
+		`[1, -2]`,
+		`["a", 1, true, false]`,
+		`["a", 1, "b"]`,
+		`["a"]`,
+		`[1, 1]`,
+		` {"id": 1} `,
+		`{}`,
+		`"text"`,
+		`[1, 2`,
+		`[1, 2] [3]`,
+		`[1, 2] x`,
+		"\xef\xbb\xbf[1, 2]",
+	}
+
+	for _, schema := range schemas {
+		rootSchema, err := NewRootJsonSchema([]byte(schema))
+		if err != nil {
+			t.Fatal(err)
+		}
+		validator := NewValidator(rootSchema)
+
+		for _, document := range documents {
+			expected := validator.Validate([]byte(document))
+			actual := validator.ValidateReader(strings.NewReader(document))
+			if (expected == nil) != (actual == nil) {
+				t.Errorf("%s: ValidateReader(%s) returned %v, Validate() returned %v", schema, document, actual, expected)
+				continue
+			}
+			if expected, ok := expected.(SchemaValidationError); ok && expected.Error() != actual.Error() {
+				t.Errorf("%s: ValidateReader(%s) returned %v, Validate() returned %v", schema, document, actual, expected)
+			}
+		}
+	}
+}
+
+func TestValidateReaderItems(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/stream/reported.json",
+		"items": {"type": "integer"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var failed []int
+	validator := NewValidator(rootSchema).OnItem(func(index int, err error) {
+		if err != nil {
+			failed = append(failed, index)
+		}
+	})
+
+	err = validator.ValidateReader(strings.NewReader(`[1, "2", 3, 4.5, 5]`))
+	if validationErr, ok := err.(SchemaValidationError); !ok || validationErr.Path() != "/1" {
+		t.Errorf("expected the failure of the first invalid item, got %v", err)
+	}
+	if len(failed) != 2 || failed[0] != 1 || failed[1] != 3 {
+		t.Errorf("expected items 1 and 3 to be reported as invalid, got %v", failed)
+	}
+}
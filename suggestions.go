@@ -0,0 +1,89 @@
+package jsonvalidator
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/itayankri/gojsonvalidator/internal/strutil"
+)
+
+// The number of suggestions that are offered for a misspelled value.
+const maxSuggestions = 3
+
+// suggest returns the candidates that are close enough to the given value to
+// be a likely typo of it, the closest first. Candidates are close if they
+// are at most a quarter of the value's length (but at least two) edits away,
+// and ties are broken by the length of the prefix that they share with the
+// value, so siblings of a misspelled json pointer come first.
+func suggest(value string, candidates []string) []string {
+	maxDistance := len(value) / 4
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	type suggestion struct {
+		candidate string
+		distance  int
+		prefix    int
+	}
+
+	var suggestions []suggestion
+	for _, candidate := range candidates {
+		if candidate == value {
+			continue
+		}
+
+		distance := strutil.EditDistance(strings.ToLower(value), strings.ToLower(candidate))
+		if distance <= maxDistance {
+			suggestions = append(suggestions, suggestion{candidate, distance, commonPrefixLength(value, candidate)})
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].distance != suggestions[j].distance {
+			return suggestions[i].distance < suggestions[j].distance
+		}
+		if suggestions[i].prefix != suggestions[j].prefix {
+			return suggestions[i].prefix > suggestions[j].prefix
+		}
+		return suggestions[i].candidate < suggestions[j].candidate
+	})
+
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+
+	result := make([]string, len(suggestions))
+	for index, s := range suggestions {
+		result[index] = s.candidate
+	}
+
+	return result
+}
+
+func commonPrefixLength(a string, b string) int {
+	length := 0
+	for length < len(a) && length < len(b) && a[length] == b[length] {
+		length++
+	}
+
+	return length
+}
+
+// Suggestions returns the values that the failing value is likely a typo of,
+// the closest first: the close items of a failing "enum", or the close
+// declared properties of a property that "additionalProperties" rejects.
+func (e SchemaValidationError) Suggestions() []string {
+	return paramSuggestions(e.params)
+}
+
+// Suggestions returns the values that the failing value is likely a typo of,
+// like SchemaValidationError.Suggestions().
+func (e ValidationError) Suggestions() []string {
+	return paramSuggestions(e.params)
+}
+
+func paramSuggestions(params map[string]interface{}) []string {
+	suggestions, _ := params["suggestions"].([]string)
+	return append([]string(nil), suggestions...)
+}
@@ -0,0 +1,87 @@
+package jsonvalidator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnumSuggestions(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{"enum": ["pending", "paid", "shipped", 1]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator := NewValidator(rootSchema)
+
+	tests := []struct {
+		document    string
+		suggestions []string
+	}{
+		{`"pendng"`, []string{"pending"}},
+		{`"PAID"`, []string{"paid"}},
+		{`"shiped"`, []string{"shipped"}},
+		{`"refunded"`, nil},
+		{`2`, nil},
+	}
+
+	for _, test := range tests {
+		err := validator.Validate([]byte(test.document))
+		schemaValidationError, ok := err.(SchemaValidationError)
+		if !ok {
+			t.Errorf("%s: expected a SchemaValidationError, got %v", test.document, err)
+			continue
+		}
+
+		if suggestions := schemaValidationError.Suggestions(); !reflect.DeepEqual(suggestions, test.suggestions) {
+			t.Errorf("%s: expected suggestions %v, got %v", test.document, test.suggestions, suggestions)
+		}
+		if mentioned := strings.Contains(err.Error(), "did you mean"); mentioned != (test.suggestions != nil) {
+			t.Errorf("%s: unexpected message %q", test.document, err.Error())
+		}
+	}
+
+	result, err := validator.ValidateResult([]byte(`"pendng"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions := result.Errors()[0].Suggestions(); !reflect.DeepEqual(suggestions, []string{"pending"}) {
+		t.Errorf("expected the suggestions in the result, got %v", suggestions)
+	}
+}
+
+func TestAdditionalPropertySuggestions(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"properties": {"username": {"type": "string"}, "email": {"type": "string"}},
+		"additionalProperties": false
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator := NewValidator(rootSchema)
+
+	tests := []struct {
+		document    string
+		suggestions []string
+	}{
+		{`{"usrename": "x"}`, []string{"username"}},
+		{`{"emial": {}}`, []string{"email"}},
+		{`{"username": "x", "usernam": "y"}`, nil},
+		{`{"nickname": "x"}`, nil},
+	}
+
+	for _, test := range tests {
+		err := validator.Validate([]byte(test.document))
+		schemaValidationError, ok := err.(SchemaValidationError)
+		if !ok {
+			t.Errorf("%s: expected a SchemaValidationError, got %v", test.document, err)
+			continue
+		}
+
+		if suggestions := schemaValidationError.Suggestions(); !reflect.DeepEqual(suggestions, test.suggestions) {
+			t.Errorf("%s: expected suggestions %v, got %v", test.document, test.suggestions, suggestions)
+		}
+		if mentioned := strings.Contains(err.Error(), "did you mean"); mentioned != (test.suggestions != nil) {
+			t.Errorf("%s: unexpected message %q", test.document, err.Error())
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+// The operations of the "transform" keyword, which is applied only if the
+// validator enables transforms.
+// "toEnumCase" is applied by transform.apply(), since it needs the schema.
+var transformOperations = map[string]func(value string) string{
+	"trim":        strings.TrimSpace,
+	"trimStart":   trimStart,
+	"trimLeft":    trimStart,
+	"trimEnd":     trimEnd,
+	"trimRight":   trimEnd,
+	"toLowerCase": strings.ToLower,
+	"toUpperCase": strings.ToUpper,
+	"toEnumCase":  nil,
+}
+
+// transform holds the operations of the ajv "transform" keyword, which
+// normalizes string values before the other keywords of the schema validate
+// them.
+type transform []string
+
+// compile verifies that the operations of the keyword exist. "toEnumCase"
+// needs an "enum" of strings to take the case from.
+func (t transform) compile(schemaPath string, js *JsonSchema) error {
+	var errs SchemaCompilationErrors
+	for index, operation := range t {
+		if _, ok := transformOperations[operation]; !ok {
+			errs = append(errs, SchemaCompilationError{
+				path: schemaPath + "/transform/" + strconv.Itoa(index),
+				err:  "unknown transform operation \"" + operation + "\"",
+			})
+		} else if operation == "toEnumCase" && js.Enum == nil {
+			errs = append(errs, SchemaCompilationError{
+				path: schemaPath + "/transform/" + strconv.Itoa(index),
+				err:  "the \"toEnumCase\" transform requires an \"enum\"",
+			})
+		}
+	}
+
+	return errs.err()
+}
+
+// apply applies the operations of the keyword to a string value in order.
+func (t transform) apply(value string, js *JsonSchema) string {
+	for _, operation := range t {
+		if operation == "toEnumCase" {
+			value = toEnumCase(value, js)
+		} else {
+			value = transformOperations[operation](value)
+		}
+	}
+
+	return value
+}
+
+func trimStart(value string) string {
+	return strings.TrimLeftFunc(value, unicode.IsSpace)
+}
+
+func trimEnd(value string) string {
+	return strings.TrimRightFunc(value, unicode.IsSpace)
+}
+
+// toEnumCase returns the "enum" value that equals the value
+// case-insensitively, or the value itself if there is none.
+func toEnumCase(value string, js *JsonSchema) string {
+	for _, item := range js.Enum {
+		if enumValue, ok := item.(string); ok && strings.EqualFold(enumValue, value) {
+			return enumValue
+		}
+	}
+
+	return value
+}
+
+// transformValue applies the "transform" keyword of the schema to the value
+// at jsonPath, if transforms are enabled, and returns the transformed value,
+// which the other keywords of the schema validate. The transformed values are
+// recorded for ValidateTransform().
+func (js *JsonSchema) transformValue(jsonPath string, jsonData jsonData, ctx *validationContext) (jsonData, error) {
+	v, ok := jsonData.value.(string)
+	if !ok || !ctx.transforms {
+		return jsonData, nil
+	}
+
+	transformed := js.Transform.apply(v, js)
+	if transformed == v {
+		return jsonData, nil
+	}
+
+	if ctx.transformed == nil {
+		ctx.transformed = map[string]string{}
+	}
+	ctx.transformed[jsonPath] = transformed
+
+	return newJsonData(transformed), nil
+}
+
+// ValidateTransform validates a json document like Validate() with the
+// "transform" keyword enabled, and returns the document with the transformed
+// string values, even if the document is invalid. Only the values are
+// transformed, so keywords of parent schemas, like the "enum" of an object,
+// see the values that the document holds.
+func (v *Validator) ValidateTransform(bytes []byte) ([]byte, error) {
+	ctx := newValidationContext(v, len(bytes))
+	ctx.transforms = true
+
+	validationErr := v.validate(bytes, ctx)
+	if len(ctx.transformed) == 0 {
+		return append([]byte(nil), bytes...), validationErr
+	}
+
+	document, err := v.instanceDecoder().Decode(bytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	for path, value := range ctx.transformed {
+		document = setValue(document, strings.Split(path, "/")[1:], value)
+	}
+
+	transformed, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+
+	return transformed, validationErr
+}
+
+// setValue sets the value at the escaped json pointer tokens in a decoded
+// json document, and returns the document.
+func setValue(document interface{}, tokens []string, value interface{}) interface{} {
+	if len(tokens) == 0 {
+		return value
+	}
+
+	token := jsonwalker.UnescapeToken(tokens[0])
+	switch v := document.(type) {
+	case map[string]interface{}:
+		if child, ok := v[token]; ok {
+			v[token] = setValue(child, tokens[1:], value)
+		}
+	case []interface{}:
+		if index, err := strconv.Atoi(token); err == nil && index >= 0 && index < len(v) {
+			v[index] = setValue(v[index], tokens[1:], value)
+		}
+	}
+
+	return document
+}
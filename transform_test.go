@@ -0,0 +1,59 @@
+package jsonvalidator
+
+import "testing"
+
+func TestValidateTransform(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"properties": {
+			"email": {"type": "string", "transform": ["trim", "toLowerCase"], "pattern": "^[a-z@.]+$"},
+			"country": {"transform": ["trimEnd", "toEnumCase"], "enum": ["DE", "FR"]},
+			"tags": {"items": {"transform": ["toUpperCase"], "maxLength": 3}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		document string
+		expected string
+		valid    bool
+	}{
+		{`{"email": "  Bob@Example.com ", "count": 1.50}`, `{"count":1.50,"email":"bob@example.com"}`, true},
+		{`{"country": "fr  ", "tags": ["a", "bcd"]}`, `{"country":"FR","tags":["A","BCD"]}`, true},
+		{`{"tags": ["abcd"]}`, `{"tags":["ABCD"]}`, false},
+		{`{"country": "es"}`, `{"country": "es"}`, false},
+	}
+
+	for _, testCase := range testCases {
+		document, err := NewValidator(rootSchema).ValidateTransform([]byte(testCase.document))
+		if testCase.valid != (err == nil) {
+			t.Errorf("%s: expected valid=%v, got %v", testCase.document, testCase.valid, err)
+		}
+		if string(document) != testCase.expected {
+			t.Errorf("%s: expected %s, got %s", testCase.document, testCase.expected, document)
+		}
+	}
+
+	// Without transforms, the keyword is ignored.
+	if err := NewValidator(rootSchema).Validate([]byte(`{"email": "Bob"}`)); err == nil {
+		t.Error("expected the untransformed value to be invalid")
+	}
+	if err := NewValidator(rootSchema).Transforms(true).Validate([]byte(`{"email": "Bob"}`)); err != nil {
+		t.Errorf("expected the transformed value to be valid, got %v", err)
+	}
+}
+
+func TestTransformCompilation(t *testing.T) {
+	testCases := []string{
+		`{"transform": ["capitalize"]}`,
+		`{"transform": ["toEnumCase"]}`,
+	}
+
+	for _, schema := range testCases {
+		_, err := NewRootJsonSchema([]byte(schema))
+		if _, ok := err.(SchemaCompilationErrors); !ok {
+			t.Errorf("%s: expected SchemaCompilationErrors, got %v", schema, err)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package jsonvalidator
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+// uniqueItemProperties holds the ajv-keywords "uniqueItemProperties"
+// keyword, which lists properties whose values must be unique among the
+// object items of an array. A property is given by its name, or by a json
+// pointer (which starts with "/") for nested properties.
+type uniqueItemProperties struct {
+	properties []string
+	tokens     [][]string
+}
+
+func (uip *uniqueItemProperties) validate(jsonPath string, jsonData jsonData, rootSchemaId string, ctx *validationContext) error {
+	array, ok := jsonData.value.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	for index, tokens := range uip.tokens {
+		// The items that are not objects or do not have the property are
+		// ignored, so the values are collected with the indices of their
+		// items.
+		values := make([]interface{}, 0, len(array))
+		indices := make([]int, 0, len(array))
+		for itemIndex, item := range array {
+			if value, ok := propertyValue(item, tokens); ok {
+				values = append(values, value)
+				indices = append(indices, itemIndex)
+			}
+		}
+
+		first, second, found := findEqualItems(values)
+		if found {
+			return KeywordValidationError{
+				keyword: "uniqueItemProperties",
+				reason: "the items at indices " + strconv.Itoa(indices[first]) + ", " + strconv.Itoa(indices[second]) +
+					" have the same \"" + uip.properties[index] + "\" property",
+				params: map[string]interface{}{"property": uip.properties[index], "i": indices[first], "j": indices[second]},
+			}
+		}
+	}
+
+	return nil
+}
+
+// propertyValue returns the value at the json pointer tokens of an object.
+func propertyValue(value interface{}, tokens []string) (interface{}, bool) {
+	for _, token := range tokens {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		value, ok = object[token]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+func (uip *uniqueItemProperties) UnmarshalJSON(data []byte) error {
+	err := json.Unmarshal(data, &uip.properties)
+	if err != nil {
+		return err
+	}
+
+	uip.tokens = make([][]string, len(uip.properties))
+	for index, property := range uip.properties {
+		if !strings.HasPrefix(property, "/") {
+			uip.tokens[index] = []string{property}
+			continue
+		}
+
+		tokens := strings.Split(property, "/")[1:]
+		for tokenIndex, token := range tokens {
+			tokens[tokenIndex] = jsonwalker.UnescapeToken(token)
+		}
+		uip.tokens[index] = tokens
+	}
+
+	return nil
+}
+
+func (uip *uniqueItemProperties) MarshalJSON() ([]byte, error) {
+	return json.Marshal(uip.properties)
+}
@@ -0,0 +1,34 @@
+package jsonvalidator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUniqueItemProperties(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{"uniqueItemProperties": ["id", "/owner/email"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		document string
+		reason   string
+	}{
+		{`[{"id": 1}, {"id": 2}, {"name": "no id"}, {"name": "no id"}, 3, 3]`, ""},
+		{`[{"id": 1}, {"id": 2}, {"id": 1.0}]`, `the items at indices 0, 2 have the same "id" property`},
+		{`[{"id": {"a": 1, "b": 2}}, {"id": {"b": 2, "a": 1}}]`, `the items at indices 0, 1 have the same "id" property`},
+		{`[{"owner": {"email": "a@b.c"}}, {"owner": {}}, {"id": 2, "owner": {"email": "a@b.c"}}]`, `the items at indices 0, 2 have the same "/owner/email" property`},
+		{`{"id": 1}`, ""},
+	}
+
+	for _, testCase := range testCases {
+		err := NewValidator(rootSchema).Validate([]byte(testCase.document))
+		if testCase.reason == "" && err != nil {
+			t.Errorf("%s: expected the document to be valid, got %v", testCase.document, err)
+		}
+		if testCase.reason != "" && (err == nil || !strings.Contains(err.Error(), testCase.reason)) {
+			t.Errorf("%s: expected %q, got %v", testCase.document, testCase.reason, err)
+		}
+	}
+}
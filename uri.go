@@ -0,0 +1,104 @@
+package jsonvalidator
+
+import "strings"
+
+// resolveURI resolves a URI reference (without a fragment) against a base
+// URI, as described in section 5.2 of RFC 3986. It is implemented here in
+// order to keep the net packages out of the module's core. A reference
+// that cannot be resolved, because the base URI is empty, is returned as
+// is.
+func resolveURI(base, reference string) string {
+	if base == "" || hasScheme(reference) {
+		return reference
+	}
+
+	// Remove the query and the fragment of the base URI.
+	if index := strings.IndexAny(base, "?#"); index >= 0 {
+		base = base[:index]
+	}
+	if reference == "" {
+		return base
+	}
+
+	// Split the base URI into its scheme and authority, and its path.
+	var prefix, path string
+	if index := strings.Index(base, ":"); index >= 0 && hasScheme(base) {
+		prefix, path = base[:index+1], base[index+1:]
+	} else {
+		path = base
+	}
+	if strings.HasPrefix(path, "//") {
+		index := strings.Index(path[2:], "/")
+		if index < 0 {
+			prefix, path = prefix+path, ""
+		} else {
+			prefix, path = prefix+path[:index+2], path[index+2:]
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(reference, "//"):
+		return prefix[:strings.Index(prefix, ":")+1] + reference
+	case strings.HasPrefix(reference, "/"):
+		return prefix + removeDotSegments(reference)
+	case strings.HasPrefix(reference, "?"):
+		return prefix + path + reference
+	}
+
+	// Merge the reference with the directory of the base path.
+	if path == "" && strings.Contains(prefix, "//") {
+		path = "/"
+	}
+	directory := path[:strings.LastIndex(path, "/")+1]
+
+	return prefix + removeDotSegments(directory+reference)
+}
+
+// hasScheme returns true if the URI starts with a scheme, like "http:".
+func hasScheme(uri string) bool {
+	for index, char := range uri {
+		switch {
+		case char >= 'a' && char <= 'z', char >= 'A' && char <= 'Z':
+		case index > 0 && (char >= '0' && char <= '9' || char == '+' || char == '-' || char == '.'):
+		case index > 0 && char == ':':
+			return true
+		default:
+			return false
+		}
+	}
+
+	return false
+}
+
+// removeDotSegments removes the "." and ".." segments of a path.
+func removeDotSegments(path string) string {
+	segments := strings.Split(path, "/")
+
+	// The empty segment before the first slash of an absolute path is kept.
+	minimum := 0
+	if strings.HasPrefix(path, "/") {
+		minimum = 1
+	}
+
+	output := make([]string, 0, len(segments))
+	for index, segment := range segments {
+		last := index == len(segments)-1
+		switch segment {
+		case ".":
+		case "..":
+			if len(output) > minimum {
+				output = output[:len(output)-1]
+			}
+		default:
+			output = append(output, segment)
+			continue
+		}
+
+		// A path that ends with a dot segment refers to a directory.
+		if last {
+			output = append(output, "")
+		}
+	}
+
+	return strings.Join(output, "/")
+}
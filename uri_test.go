@@ -0,0 +1,29 @@
+package jsonvalidator
+
+import "testing"
+
+func TestResolveURI(t *testing.T) {
+	testCases := []struct {
+		base      string
+		reference string
+		expected  string
+	}{
+		{"", "common.json", "common.json"},
+		{"http://example.com/schemas/a.json", "", "http://example.com/schemas/a.json"},
+		{"http://example.com/schemas/a.json", "common.json", "http://example.com/schemas/common.json"},
+		{"http://example.com/schemas/a.json", "../b.json", "http://example.com/b.json"},
+		{"http://example.com/schemas/a.json", "/b.json", "http://example.com/b.json"},
+		{"http://example.com/schemas/a.json", "//other.com/b.json", "http://other.com/b.json"},
+		{"http://example.com", "b.json", "http://example.com/b.json"},
+		{"http://example.com/a.json", "urn:example:b", "urn:example:b"},
+		{"file:///schemas/a/b.json", "./c/../d.json", "file:///schemas/a/d.json"},
+		{"schemas/a.json", "b.json", "schemas/b.json"},
+	}
+
+	for _, testCase := range testCases {
+		resolved := resolveURI(testCase.base, testCase.reference)
+		if resolved != testCase.expected {
+			t.Errorf("%s %s: expected %s, got %s", testCase.base, testCase.reference, testCase.expected, resolved)
+		}
+	}
+}
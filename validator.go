@@ -0,0 +1,813 @@
+package jsonvalidator
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/itayankri/gojsonvalidator/formatchecker"
+	"github.com/itayankri/gojsonvalidator/jsonpointer"
+)
+
+// The default number of visited nodes between two calls to a ProgressFunc.
+const defaultProgressInterval = 1000
+
+// Progress describes how much of a json document was processed by a
+// Validator so far.
+// NodesVisited is the number of (sub-schema, json value) pairs that were
+// validated, and BytesProcessed is the accumulated size of the json values
+// that were inspected in those validations. Since a value is inspected once
+// for every sub-schema that applies to it, BytesProcessed measures the work
+// done by the validator and may be greater than TotalBytes.
+type Progress struct {
+	NodesVisited   int
+	BytesProcessed int
+	TotalBytes     int
+}
+
+// ProgressFunc is a callback that is invoked periodically during validation.
+// If it returns an error, the validation is aborted and the error is returned
+// by Validator.Validate().
+type ProgressFunc func(progress Progress) error
+
+// ItemFunc is a callback that is invoked for every item of a top-level json
+// array with the index of the item and the result of its validation (nil if
+// the item is valid).
+type ItemFunc func(index int, err error)
+
+// Branch describes a conditional branch that was applied to a json value
+// during validation: the "then" or "else" schema of an "if" keyword, or the
+// single schema of a "oneOf" keyword that the value is valid against.
+type Branch struct {
+	// InstanceLocation is the json pointer of the value in the validated
+	// document.
+	InstanceLocation string
+
+	// KeywordLocation is the location of the "if" or "oneOf" keyword,
+	// relative to the root schema and following the path of the validation.
+	KeywordLocation string
+
+	// BranchLocation is the location of the applied branch, for example
+	// ".../then", ".../else" or ".../oneOf/1". The "then" and "else" branches
+	// are reported even if the schema does not define them.
+	BranchLocation string
+}
+
+// BranchFunc is a callback that is invoked for every conditional branch that
+// was applied during validation.
+type BranchFunc func(branch Branch)
+
+// Validator validates json documents against a RootJsonSchema according to
+// a set of options.
+// A Validator is configured once and may then be used to validate any number
+// of documents. Once it is configured, a Validator is safe for concurrent
+// use: every validation keeps its state to itself, and the shared state that
+// validations touch (the registries, the ResultCache, the Profiler, and the
+// format and extension registries) is guarded. Options must not be changed
+// while documents are validated.
+type Validator struct {
+	schema           *RootJsonSchema
+	progressFunc     ProgressFunc
+	progressInterval int
+	itemFunc         ItemFunc
+	strictNumbers    bool
+	strictHostnames  bool
+	strictFormats    bool
+	strictContent    bool
+	disabledFormats  map[string]bool
+	comparator       Comparator
+	numericTolerance float64
+	transforms       bool
+	injectDefaults   bool
+	cache            *ResultCache
+	fingerprint      [sha256.Size]byte
+	uniqueItemsLimit int
+	branchFunc       BranchFunc
+	profiler         *Profiler
+	registries       registries
+	decoder          InstanceDecoder
+	outputFormat     string
+
+	// The keywords and formats of the extensions that the validator uses.
+	extensionKeywords map[string]KeywordFunc
+	extensionFormats  map[string]formatchecker.Checker
+}
+
+// NewValidator creates a new Validator for the given root schema.
+func NewValidator(schema *RootJsonSchema) *Validator {
+	return &Validator{
+		schema:           schema,
+		registries:       schema.registries(),
+		progressInterval: defaultProgressInterval,
+	}
+}
+
+// OnProgress registers a ProgressFunc that is called every time the number of
+// visited nodes grows by interval. An interval that is not positive falls back
+// to the default interval.
+// It returns the receiver in order to allow chaining of options.
+func (v *Validator) OnProgress(interval int, progressFunc ProgressFunc) *Validator {
+	if interval <= 0 {
+		interval = defaultProgressInterval
+	}
+
+	v.progressFunc = progressFunc
+	v.progressInterval = interval
+	return v
+}
+
+// OnItem registers an ItemFunc that is called for every item of a top-level
+// json array that is validated by the root schema's "items" or
+// "additionalItems" keywords. When an ItemFunc is registered, a failing item
+// does not stop the validation of the rest of the array, so valid and invalid
+// items can be told apart in a single pass. Validate() still returns the
+// first failure.
+// It returns the receiver in order to allow chaining of options.
+func (v *Validator) OnItem(itemFunc ItemFunc) *Validator {
+	v.itemFunc = itemFunc
+	return v
+}
+
+// StrictNumbers enables or disables the strict numeric mode. In strict
+// numeric mode json numbers are not converted to float64 when they cannot be
+// represented exactly, so integers are told apart from other numbers for
+// all magnitudes (for example, 1e309 is an integer and
+// 9007199254740993.5 is not), and numbers that overflow float64 do not fail
+// the validation.
+// It returns the receiver in order to allow chaining of options.
+func (v *Validator) StrictNumbers(strict bool) *Validator {
+	v.strictNumbers = strict
+	return v
+}
+
+// StrictHostnames enables or disables the strict hostname mode. In strict
+// hostname mode the "hostname" format is checked by
+// formatchecker.IsValidHostnameStrict, which limits hostnames to 253
+// characters and rejects empty labels, trailing dots and misplaced hyphens.
+// Otherwise the lenient formatchecker.IsValidHostname is used.
+// It returns the receiver in order to allow chaining of options.
+func (v *Validator) StrictHostnames(strict bool) *Validator {
+	v.strictHostnames = strict
+	return v
+}
+
+// StrictFormats enables or disables the strict format mode. In strict
+// format mode a "format" keyword whose format is neither built-in nor
+// registered fails the validation of every value, which catches typos like
+// "date-tme". Otherwise unknown formats are ignored, and reported as
+// warnings in the Result of ValidateResult(). Disabled formats are not
+// unknown.
+// It returns the receiver in order to allow chaining of options.
+func (v *Validator) StrictFormats(strict bool) *Validator {
+	v.strictFormats = strict
+	return v
+}
+
+// StrictContent enables or disables the strict content mode. In strict
+// content mode strings that cannot be decoded with their "contentEncoding"
+// (one of the ENCODING_* encodings) fail the validation, and so do strings
+// whose decoded contents are not valid json documents if their
+// "contentMediaType" is a json media type, like "application/json", or are
+// not valid against their "contentSchema".
+// Otherwise the content keywords are annotations, as the specification
+// allows, and are recorded in the Result of ValidateResult().
+// It returns the receiver in order to allow chaining of options.
+func (v *Validator) StrictContent(strict bool) *Validator {
+	v.strictContent = strict
+	return v
+}
+
+// DisableFormats turns off the checks of the given formats, for formats
+// that are too strict or too slow for an application. The "format" keywords
+// of disabled formats always pass, and are recorded as annotations in the
+// Result of ValidateResult(), like unknown formats are ignored but without
+// their warning.
+// It returns the receiver in order to allow chaining of options.
+func (v *Validator) DisableFormats(formats ...string) *Validator {
+	if v.disabledFormats == nil {
+		v.disabledFormats = map[string]bool{}
+	}
+	for _, name := range formats {
+		v.disabledFormats[name] = true
+	}
+	return v
+}
+
+// CompareValues sets the Comparator that the "const" and "enum" keywords
+// use to compare values, for domain-specific equality like EqualFold or
+// EqualDateTimes. By default values are equal if their json encodings are.
+// It returns the receiver in order to allow chaining of options.
+func (v *Validator) CompareValues(comparator Comparator) *Validator {
+	v.comparator = comparator
+	return v
+}
+
+// NumericTolerance sets an absolute tolerance for numeric comparisons, for
+// measured floating-point data where exact equality is meaningless. Numbers
+// are valid against "multipleOf" if they are within epsilon of a multiple
+// of its value, and they equal a number of "const" or "enum" if they are
+// within epsilon of it, which takes precedence over the Comparator. An
+// epsilon that is not positive restores the exact comparisons, which is the
+// default.
+// It returns the receiver in order to allow chaining of options.
+func (v *Validator) NumericTolerance(epsilon float64) *Validator {
+	v.numericTolerance = epsilon
+	return v
+}
+
+// Transforms enables or disables the ajv "transform" keyword, which
+// normalizes string values (for example with "trim" and "toLowerCase")
+// before the other keywords of their schema validate them. The keyword is
+// disabled by default, and ValidateTransform() always enables it.
+// It returns the receiver in order to allow chaining of options.
+func (v *Validator) Transforms(enabled bool) *Validator {
+	v.transforms = enabled
+	return v
+}
+
+// InjectDefaults enables or disables the injection of default values. When
+// it is enabled, the missing properties of an object that have a "default"
+// in the "properties" of its schema, and the missing items at the end of an
+// array that have a "default" in the tuple of its schema, are filled in with
+// the defaults before the other keywords of the schema validate the value,
+// so for example a required property with a default is not reported as
+// missing. The defaults of sub-schemas that do not apply to the value (like
+// the failed alternatives of "anyOf", or the schema of "not") are dropped.
+// Injection is disabled by default, and ValidateDefaults() always enables
+// it.
+// It returns the receiver in order to allow chaining of options.
+func (v *Validator) InjectDefaults(enabled bool) *Validator {
+	v.injectDefaults = enabled
+	return v
+}
+
+// Cache makes Validate() look up the results of documents in cache, and
+// store the results of the documents it validates there. Documents are
+// identified by their exact bytes. The results are not reused once a schema
+// is added to, replaced in or removed from the registries that references
+// are resolved in (see Registries()). The hooks of the validator, like the
+// ProgressFunc and the ItemFunc, are not invoked for the documents whose
+// results are found in the cache. A nil cache disables caching.
+// It returns the receiver in order to allow chaining of options.
+func (v *Validator) Cache(cache *ResultCache) *Validator {
+	v.cache = cache
+	v.fingerprint = sha256.Sum256(v.schema.document)
+	return v
+}
+
+// Registries sets the registries that references are resolved in, in
+// priority order. A root schema is looked up in the first registry that has
+// it, and if none of them has it, in the registry that the schema of the
+// validator was compiled in and then in the DefaultRegistry (where the
+// package-level functions, like AddResource(), register schemas). For
+// example, a registry of a tenant's overrides followed by a registry of
+// shared definitions lets the tenant customize the leaf schemas that the
+// shared definitions reference.
+// It returns the receiver in order to allow chaining of options.
+func (v *Validator) Registries(registries ...*Registry) *Validator {
+	resolved := v.schema.registries()
+	resolved.list = append(append([]*Registry(nil), registries...), resolved.list...)
+	v.registries = resolved
+	return v
+}
+
+// Decoder sets the InstanceDecoder that decodes the validated documents, for
+// example to use a faster json parser, or to validate documents of another
+// wire format. A nil decoder restores the StandardDecoder, which is the
+// default.
+// It returns the receiver in order to allow chaining of options.
+func (v *Validator) Decoder(decoder InstanceDecoder) *Validator {
+	v.decoder = decoder
+	return v
+}
+
+// UniqueItemsLimit limits the "uniqueItems" keyword to the first limit items
+// of every array, which bounds the time and memory that the keyword takes
+// for very large arrays, at the cost of not detecting duplicates beyond the
+// limit. A limit that is not positive removes the limit, which is the
+// default.
+// It returns the receiver in order to allow chaining of options.
+func (v *Validator) UniqueItemsLimit(limit int) *Validator {
+	v.uniqueItemsLimit = limit
+	return v
+}
+
+// OnBranch registers a BranchFunc that is called, after the validation of a
+// document, for every conditional branch that was applied to one of its
+// values, in the order of the document. Branches that were evaluated only
+// within sub-schemas that did not apply to the value (like the failed
+// alternatives of "anyOf", or the schema of "not") are not reported. The
+// branches are reported even if the document is invalid, but not if the
+// validation was aborted.
+// It returns the receiver in order to allow chaining of options.
+func (v *Validator) OnBranch(branchFunc BranchFunc) *Validator {
+	v.branchFunc = branchFunc
+	return v
+}
+
+// Profile registers a Profiler that accumulates the number of evaluations of
+// every keyword, and the time spent in them, across the validations of the
+// validator. Profiling slows the validation down, and it should not be
+// enabled in production unless needed. A nil profiler disables profiling.
+// It returns the receiver in order to allow chaining of options.
+func (v *Validator) Profile(profiler *Profiler) *Validator {
+	v.profiler = profiler
+	return v
+}
+
+// OutputFormat sets the output format (one of the OUTPUT_* constants) that
+// ValidateOutput() returns the results of the validations in.
+// It returns the receiver in order to allow chaining of options.
+func (v *Validator) OutputFormat(format string) *Validator {
+	v.outputFormat = format
+	return v
+}
+
+// Validate validates the json document in bytes against the validator's
+// schema. It returns nil if the document is valid.
+func (v *Validator) Validate(bytes []byte) error {
+	if v.cache == nil {
+		return v.validate(bytes, newValidationContext(v, len(bytes)))
+	}
+
+	key := resultKey{v.fingerprint, v.registries.generation(), sha256.Sum256(bytes)}
+	if err, ok := v.cache.get(key); ok {
+		return err
+	}
+
+	ctx := newValidationContext(v, len(bytes))
+	err := v.validate(bytes, ctx)
+
+	// Aborted validations have no result.
+	if ctx.abortErr == nil {
+		v.cache.put(key, err)
+	}
+	return err
+}
+
+// ValidateInterface validates a Go value against the validator's schema. The
+// value is validated by its encoding/json encoding, so it may be a value that
+// was decoded by json.Unmarshal (like a map[string]interface{}), or any
+// value that json.Marshal accepts, like a struct with json tags. It returns
+// nil if the value is valid, and the error of json.Marshal if the value
+// cannot be encoded.
+func (v *Validator) ValidateInterface(value interface{}) error {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return v.Validate(bytes)
+}
+
+// Validate compiles the json schema in schema and validates the json document
+// in instance against it. It returns nil if the document is valid, a
+// SchemaValidationError if it is not, and the compilation error if the
+// schema is invalid. The schema is compiled on every call, so schemas that
+// validate more than one document should be compiled once with
+// NewRootJsonSchema() and used through RootJsonSchema.Validate() or a
+// Validator. The schema is compiled in a registry of its own, which is
+// dropped after the call, so it is not registered in the DefaultRegistry
+// (its references are still resolved there).
+func Validate(schema []byte, instance []byte) error {
+	rootSchema, err := newRootJsonSchema(NewRegistry(), schema, "", "")
+	if err != nil {
+		return err
+	}
+
+	return rootSchema.Validate(instance)
+}
+
+// validate validates the json document in bytes in the given context, and
+// reports the recorded profiles and branches to the validator's hooks.
+func (v *Validator) validate(bytes []byte, ctx *validationContext) error {
+	return v.finish(v.schema.validateBytes(bytes, ctx), ctx)
+}
+
+// finish reports the profiles and the branches that were recorded in the
+// validation of ctx to the validator's hooks, and returns the result of the
+// validation, which returned err.
+func (v *Validator) finish(err error, ctx *validationContext) error {
+	if v.profiler != nil {
+		v.profiler.merge(ctx.profiles)
+	}
+
+	// An aborted validation takes precedence over the validation result,
+	// because keywords like "not" and "anyOf" may have swallowed the error.
+	if ctx.abortErr != nil {
+		return ctx.abortErr
+	}
+
+	if v.branchFunc != nil {
+		for _, branch := range ctx.branches {
+			v.branchFunc(branch)
+		}
+	}
+
+	return err
+}
+
+// comparesDecoded returns true if "const" and "enum" compare decoded values
+// with valuesEqual(), rather than json encodings.
+func (v *Validator) comparesDecoded() bool {
+	return v.comparator != nil || v.numericTolerance > 0
+}
+
+// valuesEqual returns true if a value of a "const" or an "enum" keyword
+// equals a value of the validated document, according to the numeric
+// tolerance and the Comparator of the validator.
+func (v *Validator) valuesEqual(schemaValue interface{}, value interface{}) bool {
+	if v.numericTolerance > 0 {
+		x, ok := numberValue(schemaValue)
+		y, ok2 := numberValue(value)
+		if ok && ok2 {
+			return math.Abs(x-y) <= v.numericTolerance
+		}
+	}
+
+	if v.comparator != nil {
+		return v.comparator(schemaValue, value)
+	}
+
+	return equalValues(schemaValue, value)
+}
+
+// validationContext holds the state of a single validation of a json
+// document. It is created by the Validator and passed down to every
+// keywordValidator.
+type validationContext struct {
+	validator *Validator
+	progress  Progress
+	abortErr  error
+
+	// depth is the nesting level of the schema that is currently validated,
+	// where the root schema is at depth 1.
+	depth int
+
+	// schemaTokens are the tokens of the json pointer of the schema that is
+	// currently validated, relative to the root schema and following the
+	// path of the validation (including "$ref" hops). The locations are
+	// joined into strings only when an error is reported.
+	schemaTokens []string
+
+	// schemaBases holds the absolute URI of the root schema, followed by
+	// the absolute URIs of the schemas that the "$ref" hops on the current
+	// path point to.
+	schemaBases []schemaBase
+
+	// collectsOutput is true if the annotations, the warnings and the
+	// errors of the validation are collected for a Result. The validation
+	// then goes on after a failure, so all the failures are found.
+	collectsOutput bool
+
+	// transforms is true if the "transform" keyword is applied, and
+	// transformed holds the transformed string values by their json
+	// pointers in the document.
+	transforms  bool
+	transformed map[string]string
+
+	// injectDefaults is true if default values are injected, and defaults
+	// holds the injected values in the order they were injected, parents
+	// before their children.
+	injectDefaults bool
+	defaults       []injectedDefault
+
+	// branches are the conditional branches that were applied so far. They
+	// are recorded only if the validator has a BranchFunc, or if the output
+	// of the validation is collected.
+	branches []Branch
+
+	// annotations, warnings and errors are recorded only if the output of
+	// the validation is collected.
+	annotations []Annotation
+	warnings    []Warning
+	errors      []SchemaValidationError
+
+	// scopes are the schemas that the values on the current path are
+	// validated against, which are tracked only if the output of the
+	// validation is collected.
+	scopes []scopeFrame
+
+	// profiles holds the profiles of the keyword locations that were
+	// evaluated in the validation, keyed by their locations. They are
+	// recorded only if the validator has a Profiler.
+	profiles map[string]*KeywordProfile
+}
+
+// schemaBase is the absolute URI of a schema that the validation reached,
+// and the number of schemaTokens at the time it was reached.
+type schemaBase struct {
+	uri    string
+	tokens int
+}
+
+// schemaLocation is the location of a schema (or of one of its keywords) as
+// it was reached during validation.
+// keywordLocation is a json pointer relative to the root schema that follows
+// the path of the validation, including "$ref" hops, and
+// absoluteKeywordLocation is the absolute URI of the same location after the
+// references were resolved.
+type schemaLocation struct {
+	keywordLocation         string
+	absoluteKeywordLocation string
+}
+
+// scopeFrame is a schema that is applied to the value at instanceLocation,
+// where tokens and bases are the numbers of schemaTokens and schemaBases
+// at the location of the schema. The location is resolved when it is first
+// needed.
+type scopeFrame struct {
+	instanceLocation string
+	tokens           int
+	bases            int
+	location         *schemaLocation
+}
+
+// outputScope is a schema that a value was validated against, as an error
+// or an annotation that was recorded in it reached it.
+type outputScope struct {
+	instanceLocation string
+	schemaLocation
+}
+
+func newValidationContext(validator *Validator, totalBytes int) *validationContext {
+	rootSchemaID := validator.schema.id()
+
+	ctx := &validationContext{
+		validator: validator,
+		progress: Progress{
+			TotalBytes: totalBytes,
+		},
+		schemaBases: []schemaBase{{uri: rootSchemaID + "#"}},
+		transforms:  validator.transforms,
+
+		injectDefaults: validator.injectDefaults,
+	}
+
+	if validator.profiler != nil {
+		ctx.profiles = map[string]*KeywordProfile{}
+	}
+
+	return ctx
+}
+
+// enterSchema moves the current location to a sub-schema of the currently
+// validated schema, which is found at the given tokens (for example
+// "properties" and a property name). It returns a mark that restores the
+// previous location when passed to leaveSchema().
+func (ctx *validationContext) enterSchema(tokens ...string) int {
+	mark := len(ctx.schemaTokens)
+	ctx.schemaTokens = append(ctx.schemaTokens, tokens...)
+	return mark
+}
+
+// leaveSchema restores the location that was current before the call to
+// enterSchema() that returned mark.
+func (ctx *validationContext) leaveSchema(mark int) {
+	ctx.schemaTokens = ctx.schemaTokens[:mark]
+}
+
+// enterReference moves the current location through a reference keyword
+// ("$ref" or "$dynamicRef") to the schema at the given absolute URI. It
+// returns a mark that restores the previous location when passed to
+// leaveReference().
+func (ctx *validationContext) enterReference(keyword string, uri string) int {
+	mark := ctx.enterSchema(keyword)
+	ctx.schemaBases = append(ctx.schemaBases, schemaBase{
+		uri:    uri,
+		tokens: len(ctx.schemaTokens),
+	})
+	return mark
+}
+
+// leaveReference restores the location that was current before the call to
+// enterReference() that returned mark.
+func (ctx *validationContext) leaveReference(mark int) {
+	ctx.schemaBases = ctx.schemaBases[:len(ctx.schemaBases)-1]
+	ctx.leaveSchema(mark)
+}
+
+// location returns the current location, followed by the given tokens.
+func (ctx *validationContext) location(tokens ...string) schemaLocation {
+	base := ctx.schemaBases[len(ctx.schemaBases)-1]
+	return locationOf(append(ctx.schemaTokens[:len(ctx.schemaTokens):len(ctx.schemaTokens)], tokens...), base)
+}
+
+// locationOf returns the location of the schema at the given tokens, where
+// base is the last schema that a reference on the way pointed to.
+func locationOf(tokens []string, base schemaBase) schemaLocation {
+	var keywordLocation, relativeLocation strings.Builder
+	for index, token := range tokens {
+		escaped := "/" + jsonwalker.EscapeToken(token)
+		keywordLocation.WriteString(escaped)
+		if index >= base.tokens {
+			relativeLocation.WriteString(escaped)
+		}
+	}
+
+	return schemaLocation{
+		keywordLocation:         keywordLocation.String(),
+		absoluteKeywordLocation: base.uri + relativeLocation.String(),
+	}
+}
+
+// enterScope records that the schema at the current location is applied to
+// the value at jsonPath, so the errors and the annotations that are
+// recorded in it can be nested in the hierarchical output formats. The
+// scope is left by leaveScope().
+func (ctx *validationContext) enterScope(jsonPath string) {
+	ctx.scopes = append(ctx.scopes, scopeFrame{
+		instanceLocation: jsonPath,
+		tokens:           len(ctx.schemaTokens),
+		bases:            len(ctx.schemaBases),
+	})
+}
+
+// leaveScope leaves the scope of the last call to enterScope().
+func (ctx *validationContext) leaveScope() {
+	ctx.scopes = ctx.scopes[:len(ctx.scopes)-1]
+}
+
+// outputScopes returns the schemas that the values on the current path are
+// validated against, from the root schema down. The locations of the
+// scopes are resolved once, and are kept while the scopes are entered.
+func (ctx *validationContext) outputScopes() []outputScope {
+	scopes := make([]outputScope, len(ctx.scopes))
+	for index := range ctx.scopes {
+		frame := &ctx.scopes[index]
+		if frame.location == nil {
+			location := locationOf(ctx.schemaTokens[:frame.tokens], ctx.schemaBases[frame.bases-1])
+			frame.location = &location
+		}
+
+		scopes[index] = outputScope{
+			instanceLocation: frame.instanceLocation,
+			schemaLocation:   *frame.location,
+		}
+	}
+
+	return scopes
+}
+
+// schemaValidationError converts a KeywordValidationError of a keyword of the
+// currently validated schema into a SchemaValidationError for the value at
+// jsonPath. Other errors are returned as is.
+func (ctx *validationContext) schemaValidationError(jsonPath string, err error) error {
+	keywordValidationError, ok := err.(KeywordValidationError)
+	if !ok {
+		return err
+	}
+
+	location := ctx.location(keywordValidationError.keyword)
+	return SchemaValidationError{
+		path:                    jsonPath,
+		keyword:                 keywordValidationError.keyword,
+		keywordLocation:         location.keywordLocation,
+		absoluteKeywordLocation: location.absoluteKeywordLocation,
+		err:                     keywordValidationError.Error(),
+		params:                  keywordValidationError.params,
+		bestMatch:               keywordValidationError.bestMatch,
+		cause:                   keywordValidationError,
+	}
+}
+
+// visit records that a json value was inspected, and invokes the
+// validator's ProgressFunc if the progress interval was reached. The size of
+// the value is only measured if the validator has a ProgressFunc, which
+// reports it. It returns a non-nil error if the validation was aborted.
+func (ctx *validationContext) visit(value interface{}) error {
+	if ctx.abortErr != nil {
+		return ctx.abortErr
+	}
+
+	validator := ctx.validator
+	ctx.progress.NodesVisited++
+	if validator.progressFunc != nil {
+		ctx.progress.BytesProcessed += encodedSize(value)
+	}
+
+	if validator.progressFunc != nil && ctx.progress.NodesVisited%validator.progressInterval == 0 {
+		ctx.abortErr = validator.progressFunc(ctx.progress)
+	}
+
+	return ctx.abortErr
+}
+
+// reportsItems returns true if the items of the currently validated array
+// should be reported to the validator's ItemFunc, which happens only for the
+// top-level array that is validated by the root schema.
+func (ctx *validationContext) reportsItems() bool {
+	return ctx.validator.itemFunc != nil && ctx.depth == 1
+}
+
+// reportItem passes the validation result of a top-level array item to the
+// validator's ItemFunc.
+func (ctx *validationContext) reportItem(index int, err error) {
+	ctx.validator.itemFunc(index, err)
+}
+
+// outputMark is a mark of the branches, the annotations, the errors and the
+// injected defaults that were recorded so far in a validation.
+type outputMark struct {
+	branches    int
+	annotations int
+	errors      int
+	defaults    int
+}
+
+// outputMark returns a mark of the branches, the annotations, the errors and
+// the injected defaults that were recorded so far, to be passed to
+// recordBranch() or discardOutput().
+func (ctx *validationContext) outputMark() outputMark {
+	return outputMark{len(ctx.branches), len(ctx.annotations), len(ctx.errors), len(ctx.defaults)}
+}
+
+// recordBranch records that the branch at the given tokens of keyword (which
+// is a keyword of the currently validated schema) was applied to the value at
+// jsonPath. The branch is inserted at mark, before the branches that were
+// recorded while the keyword's sub-schemas were validated.
+func (ctx *validationContext) recordBranch(mark outputMark, jsonPath, keyword string, tokens ...string) {
+	if ctx.validator.branchFunc == nil && !ctx.collectsOutput {
+		return
+	}
+
+	branch := Branch{
+		InstanceLocation: jsonPath,
+		KeywordLocation:  ctx.location(keyword).keywordLocation,
+		BranchLocation:   ctx.location(tokens...).keywordLocation,
+	}
+
+	ctx.branches = append(ctx.branches, Branch{})
+	copy(ctx.branches[mark.branches+1:], ctx.branches[mark.branches:])
+	ctx.branches[mark.branches] = branch
+}
+
+// discardOutput drops the branches, the annotations, the errors and the
+// injected defaults that were recorded after mark, because the sub-schema
+// that they were recorded in does not apply to the value, or because its
+// failure is reported by the keyword that holds it.
+func (ctx *validationContext) discardOutput(mark outputMark) {
+	ctx.branches = ctx.branches[:mark.branches]
+	ctx.annotations = ctx.annotations[:mark.annotations]
+	ctx.errors = ctx.errors[:mark.errors]
+	ctx.defaults = ctx.defaults[:mark.defaults]
+}
+
+// recordError records err, if it is a validation failure and the output of
+// the validation is collected.
+func (ctx *validationContext) recordError(err error) {
+	if schemaValidationError, ok := err.(SchemaValidationError); ok && ctx.collectsOutput {
+		schemaValidationError.scopes = ctx.outputScopes()
+		ctx.errors = append(ctx.errors, schemaValidationError)
+	}
+}
+
+// continuesAfter returns true if the validation goes on after err, which
+// failed the validation of a keyword or of a child value. This happens only
+// when the output of the validation is collected, and never after errors
+// that are not validation failures, like the error of an aborted
+// validation.
+func (ctx *validationContext) continuesAfter(err error) bool {
+	if !ctx.collectsOutput {
+		return false
+	}
+
+	switch err.(type) {
+	case SchemaValidationError, KeywordValidationError:
+		return true
+	default:
+		return false
+	}
+}
+
+// profiling returns true if the keywords of the validation are profiled.
+func (ctx *validationContext) profiling() bool {
+	return ctx.profiles != nil
+}
+
+// usesFastPath returns true if primitive values may be validated by
+// validatePrimitive(), which neither profiles keywords nor collects
+// annotations.
+func (ctx *validationContext) usesFastPath() bool {
+	return !ctx.profiling() && !ctx.collectsOutput && len(ctx.validator.extensionKeywords) == 0
+}
+
+// profileKeyword adds an evaluation of the given keyword of the currently
+// validated schema, which took duration, to the profiles of the validation.
+func (ctx *validationContext) profileKeyword(keyword string, duration time.Duration) {
+	location := ctx.location(keyword).keywordLocation
+
+	profile, ok := ctx.profiles[location]
+	if !ok {
+		profile = &KeywordProfile{
+			Keyword:         keyword,
+			KeywordLocation: location,
+		}
+		ctx.profiles[location] = profile
+	}
+
+	profile.Calls++
+	profile.Duration += duration
+}
@@ -0,0 +1,562 @@
+package jsonvalidator
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestValidatorOnProgress(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{"items": {"type": "integer"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document := []byte("[" + strings.Repeat("1,", 99) + "1]")
+
+	var reports []Progress
+	validator := NewValidator(rootSchema).OnProgress(10, func(progress Progress) error {
+		reports = append(reports, progress)
+		return nil
+	})
+
+	err = validator.Validate(document)
+	if err != nil {
+		t.Fatalf("expected a valid document, got %v", err)
+	}
+
+	// The array itself and each of its 100 items are visited.
+	if len(reports) != 10 {
+		t.Fatalf("expected 10 progress reports, got %d", len(reports))
+	}
+
+	last := reports[len(reports)-1]
+	if last.NodesVisited != 100 || last.TotalBytes != len(document) || last.BytesProcessed == 0 {
+		t.Errorf("unexpected progress report %+v", last)
+	}
+}
+
+func TestValidatorOnProgressAbort(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{"items": {"not": {"type": "string"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limitErr := errors.New("node limit exceeded")
+	validator := NewValidator(rootSchema).OnProgress(5, func(progress Progress) error {
+		return limitErr
+	})
+
+	err = validator.Validate([]byte("[1, 2, 3, 4, 5, 6, 7, 8, 9, 10]"))
+	if err != limitErr {
+		t.Errorf("expected the progress error, got %v", err)
+	}
+}
+
+func TestValidatorOnProgressBytes(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"properties": {"a": {"items": {"enum": [1, "b"]}}},
+		"const": {"a": [1, "b"], "c": {"d": null}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var last Progress
+	validator := NewValidator(rootSchema).OnProgress(1, func(progress Progress) error {
+		last = progress
+		return nil
+	})
+
+	document := `{"a":[1,"b"],"c":{"d":null}}`
+	err = validator.Validate([]byte(document))
+	if err != nil {
+		t.Fatalf("expected a valid document, got %v", err)
+	}
+
+	// The document, the array of "a", and its items are inspected, each
+	// with the size of its compact encoding.
+	expected := len(document) + len(`[1,"b"]`) + len(`1`) + len(`"b"`)
+	if last.NodesVisited != 4 || last.BytesProcessed != expected {
+		t.Errorf("expected 4 nodes and %d bytes, got %+v", expected, last)
+	}
+}
+
+func TestValidatorOnItem(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"items": {
+			"type": "object",
+			"required": ["id"],
+			"properties": {
+				"name": {"type": "string"}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := map[int]bool{}
+	validator := NewValidator(rootSchema).OnItem(func(index int, err error) {
+		results[index] = err == nil
+	})
+
+	err = validator.Validate([]byte(`[{"id": 1, "name": "a"}, {"name": "b"}, {"id": 3, "name": 3}, {"id": 4}]`))
+	if err == nil {
+		t.Error("expected the validation to fail")
+	}
+
+	// Only the items of the top-level array are reported.
+	expected := map[int]bool{0: true, 1: false, 2: false, 3: true}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d reported items, got %v", len(expected), results)
+	}
+
+	for index, valid := range expected {
+		if results[index] != valid {
+			t.Errorf("expected item %d to be valid=%t", index, valid)
+		}
+	}
+}
+
+func TestValidatorStrictNumbers(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{"items": {"type": "integer"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		description string
+		data        string
+		valid       bool
+	}{
+		{"an integer that overflows float64", `[1e309]`, true},
+		{"a large integer with a fraction", `[9007199254740993.5]`, false},
+		{"an integer beyond the range of int", `[1e20]`, true},
+		{"an integer with a zero fraction", `[1.0]`, true},
+		{"a float", `[1.5]`, false},
+	}
+
+	validator := NewValidator(rootSchema).StrictNumbers(true)
+	for _, testCase := range testCases {
+		err := validator.Validate([]byte(testCase.data))
+		if (err == nil) != testCase.valid {
+			t.Errorf("%s: expected valid=%t, got %v", testCase.description, testCase.valid, err)
+		}
+	}
+}
+
+func TestValidatorStrictNumbersUniqueItems(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{"uniqueItems": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = NewValidator(rootSchema).StrictNumbers(true).Validate([]byte(`[1, 1.0]`))
+	if err == nil {
+		t.Error("expected 1 and 1.0 to be equal items")
+	}
+}
+
+func TestValidatorStrictHostnames(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{"format": "hostname"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 255 characters, which is allowed only in the lenient mode.
+	hostname := `"` + strings.Repeat(strings.Repeat("a", 62)+".", 4) + `com"`
+
+	if err := NewValidator(rootSchema).Validate([]byte(hostname)); err != nil {
+		t.Errorf("expected the lenient mode to accept the hostname, got %v", err)
+	}
+	if err := NewValidator(rootSchema).StrictHostnames(true).Validate([]byte(hostname)); err == nil {
+		t.Error("expected the strict mode to reject the hostname")
+	}
+}
+
+func TestValidatorStrictFormats(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{"properties": {"created": {"format": "date-tme"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		validator *Validator
+		valid     bool
+	}{
+		{NewValidator(rootSchema), true},
+		{NewValidator(rootSchema).StrictFormats(true), false},
+		{NewValidator(rootSchema).StrictFormats(true).DisableFormats("date-tme"), true},
+	}
+
+	for index, testCase := range testCases {
+		err := testCase.validator.Validate([]byte(`{"created": "2020-01-01T00:00:00Z"}`))
+		if testCase.valid && err != nil {
+			t.Errorf("%d: expected the document to be valid, got %v", index, err)
+		}
+		if !testCase.valid && (err == nil || !strings.Contains(err.Error(), `unknown format "date-tme"`)) {
+			t.Errorf("%d: expected an unknown format error, got %v", index, err)
+		}
+	}
+}
+
+func TestValidatorDisableFormats(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"properties": {
+			"host": {"format": "hostname"},
+			"email": {"format": "email"}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document := []byte(`{"host": "-invalid-", "email": "bob"}`)
+	if err := NewValidator(rootSchema).Validate(document); err == nil {
+		t.Error("expected the formats to be checked by default")
+	}
+
+	validator := NewValidator(rootSchema).DisableFormats("hostname")
+	if err := validator.Validate(document); err == nil || !strings.Contains(err.Error(), "email") {
+		t.Errorf("expected only the email format to be checked, got %v", err)
+	}
+
+	validator.DisableFormats("email")
+	result, err := validator.ValidateResult(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Valid() {
+		t.Fatalf("expected the disabled formats to pass, got %v", result.Errors())
+	}
+
+	annotations := map[string]interface{}{}
+	for _, annotation := range result.Annotations() {
+		annotations[annotation.KeywordLocation] = annotation.Value
+	}
+	if annotations["/properties/host/format"] != "hostname" || annotations["/properties/email/format"] != "email" {
+		t.Errorf("expected the disabled formats as annotations, got %v", annotations)
+	}
+}
+
+func TestValidatorCompareValues(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"properties": {
+			"currency": {"enum": ["USD", "EUR"]},
+			"country": {"const": "DE"},
+			"since": {"const": "2020-01-01T00:00:00Z"},
+			"count": {"enum": [1, 2]}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		comparator Comparator
+		document   string
+		valid      bool
+	}{
+		{nil, `{"currency": "usd"}`, false},
+		{EqualFold, `{"currency": "usd", "country": "de"}`, true},
+		{EqualFold, `{"currency": "gbp"}`, false},
+		{EqualFold, `{"count": 1.0}`, true},
+		{nil, `{"since": "2020-01-01T02:00:00+02:00"}`, false},
+		{EqualDateTimes, `{"since": "2020-01-01T02:00:00+02:00"}`, true},
+		{EqualDateTimes, `{"since": "2020-01-01T02:00:00Z"}`, false},
+		{func(schemaValue, value interface{}) bool { return true }, `{"country": "FR", "count": 3}`, true},
+	}
+
+	for index, testCase := range testCases {
+		err := NewValidator(rootSchema).CompareValues(testCase.comparator).Validate([]byte(testCase.document))
+		if testCase.valid != (err == nil) {
+			t.Errorf("%d: %s: expected valid=%v, got %v", index, testCase.document, testCase.valid, err)
+		}
+	}
+}
+
+func TestValidatorNumericTolerance(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"properties": {
+			"step": {"multipleOf": 0.1},
+			"ratio": {"const": 0.3},
+			"level": {"enum": [0.5, "max"]}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		epsilon  float64
+		document string
+		valid    bool
+	}{
+		{0, `{"step": 0.7}`, false},
+		{1e-9, `{"step": 0.7}`, true},
+		{1e-9, `{"step": -0.7}`, true},
+		{1e-9, `{"step": 0.75}`, false},
+		{0, `{"ratio": 0.30000000000000004}`, false},
+		{1e-9, `{"ratio": 0.30000000000000004}`, true},
+		{1e-9, `{"ratio": 0.31}`, false},
+		{1e-9, `{"level": 0.5000000001}`, true},
+		{1e-9, `{"level": "max"}`, true},
+		{1e-9, `{"level": 0.6}`, false},
+	}
+
+	for index, testCase := range testCases {
+		err := NewValidator(rootSchema).NumericTolerance(testCase.epsilon).Validate([]byte(testCase.document))
+		if testCase.valid != (err == nil) {
+			t.Errorf("%d: %s: expected valid=%v, got %v", index, testCase.document, testCase.valid, err)
+		}
+	}
+}
+
+func TestValidatorUniqueItemsLimit(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{"uniqueItems": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte(`[1, 2, 3, 1]`)
+	if err := NewValidator(rootSchema).UniqueItemsLimit(3).Validate(data); err != nil {
+		t.Errorf("expected the duplicate beyond the limit to be ignored, got %v", err)
+	}
+	if err := NewValidator(rootSchema).UniqueItemsLimit(4).Validate(data); err == nil {
+		t.Error("expected the duplicate within the limit to be detected")
+	}
+}
+
+func TestValidatorOnBranch(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"properties": {
+			"payment": {
+				"if": {"properties": {"method": {"const": "card"}}},
+				"then": {"required": ["number"]},
+				"else": {"oneOf": [{"required": ["iban"]}, {"required": ["email"]}]}
+			},
+			"note": {"not": {"if": {"type": "string"}, "then": {"maxLength": 3}}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		description string
+		data        string
+		expected    []Branch
+	}{
+		{
+			"then branch",
+			`{"payment": {"method": "card", "number": "4111"}}`,
+			[]Branch{{"/payment", "/properties/payment/if", "/properties/payment/then"}},
+		},
+		{
+			"else branch and oneOf",
+			`{"payment": {"method": "paypal", "email": "a@b.c"}}`,
+			[]Branch{
+				{"/payment", "/properties/payment/if", "/properties/payment/else"},
+				{"/payment", "/properties/payment/else/oneOf", "/properties/payment/else/oneOf/1"},
+			},
+		},
+		{
+			"branches under not are discarded",
+			`{"note": "a long note"}`,
+			nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		var branches []Branch
+		validator := NewValidator(rootSchema).OnBranch(func(branch Branch) {
+			branches = append(branches, branch)
+		})
+
+		if err := validator.Validate([]byte(testCase.data)); err != nil {
+			t.Errorf("%s: unexpected error %v", testCase.description, err)
+		}
+		if !reflect.DeepEqual(branches, testCase.expected) {
+			t.Errorf("%s: expected branches %v, got %v", testCase.description, testCase.expected, branches)
+		}
+	}
+}
+
+func TestValidateInterface(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"type": "object",
+		"required": ["name"],
+		"properties": {"name": {"type": "string"}, "age": {"type": "integer", "minimum": 0}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type person struct {
+		Name string `json:"name,omitempty"`
+		Age  int    `json:"age"`
+	}
+
+	testCases := []struct {
+		description string
+		value       interface{}
+		valid       bool
+	}{
+		{"a valid struct", person{Name: "Bob", Age: 30}, true},
+		{"a struct without a required field", person{Age: 30}, false},
+		{"a struct with an invalid field", person{Name: "Bob", Age: -1}, false},
+		{"a decoded document", map[string]interface{}{"name": "Bob", "age": 30.0}, true},
+		{"a decoded document with an invalid field", map[string]interface{}{"name": 1}, false},
+		{"a value of another type", []interface{}{"Bob"}, false},
+	}
+
+	for _, testCase := range testCases {
+		err := rootSchema.ValidateInterface(testCase.value)
+		if (err == nil) != testCase.valid {
+			t.Errorf("%s: expected valid=%t, got %v", testCase.description, testCase.valid, err)
+		}
+		if _, ok := err.(SchemaValidationError); err != nil && !ok {
+			t.Errorf("%s: expected a SchemaValidationError, got %T", testCase.description, err)
+		}
+	}
+
+	if err := rootSchema.ValidateInterface(make(chan int)); err == nil {
+		t.Error("expected an error for a value that cannot be encoded")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	schema := []byte(`{"type": "array", "items": {"type": "integer"}}`)
+
+	testCases := []struct {
+		description string
+		schema      []byte
+		instance    string
+		valid       bool
+	}{
+		{"a valid document", schema, `[1, 2]`, true},
+		{"an invalid document", schema, `[1, "2"]`, false},
+		{"an invalid json document", schema, `[1,`, false},
+		{"an invalid schema", []byte(`{"type": 1}`), `[]`, false},
+	}
+
+	for _, testCase := range testCases {
+		err := Validate(testCase.schema, []byte(testCase.instance))
+		if (err == nil) != testCase.valid {
+			t.Errorf("%s: expected valid=%t, got %v", testCase.description, testCase.valid, err)
+		}
+	}
+
+	rootSchema, err := NewRootJsonSchema(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rootSchema.Validate([]byte(`[1, "2"]`)); err == nil {
+		t.Error("expected a validation error")
+	}
+
+	// The schemas are not registered, so a schema with the "$id" of a
+	// schema that was validated before is not shadowed by it.
+	for _, itemType := range []string{"integer", "string"} {
+		schema := []byte(`{
+			"$id": "http://example.com/validate/items.json",
+			"items": {"$ref": "#/definitions/item"},
+			"definitions": {"item": {"type": "` + itemType + `"}}
+		}`)
+		if err := Validate(schema, []byte(`[1]`)); (err == nil) != (itemType == "integer") {
+			t.Errorf("%s items: unexpected result %v", itemType, err)
+		}
+	}
+	if _, ok := RegisteredSchemas()["http://example.com/validate/items.json"]; ok {
+		t.Error("expected the schema not to be registered")
+	}
+}
+
+func TestConcurrentValidate(t *testing.T) {
+	_, err := AddResource("http://example.com/concurrent/common.json", []byte(`{
+		"x-names": {"name": {"type": "string", "pattern": "^[a-z]+$"}},
+		"definitions": {"id": {"type": "integer", "minimum": 1}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootSchema, err := NewRootJsonSchema([]byte(`{
+		"$id": "http://example.com/concurrent/root.json",
+		"type": "object",
+		"required": ["id"],
+		"properties": {
+			"id": {"$ref": "common.json#/definitions/id"},
+			"name": {"$ref": "common.json#/x-names/name"},
+			"email": {"type": "string", "format": "email"},
+			"tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true},
+			"kind": {"$ref": "#/definitions/kind"}
+		},
+		"definitions": {"kind": {"enum": ["a", "b"]}},
+		"patternProperties": {"^x-": {"type": "number"}},
+		"additionalProperties": false,
+		"if": {"required": ["kind"], "properties": {"kind": {"const": "a"}}},
+		"then": {"required": ["name"]},
+		"oneOf": [{"required": ["email"]}, {"not": {"required": ["email"]}}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	documents := []struct {
+		document string
+		valid    bool
+	}{
+		{`{"id": 1, "name": "alice", "kind": "a", "tags": ["x", "y"], "x-score": 1}`, true},
+		{`{"id": 2, "email": "bob@example.com"}`, true},
+		{`{"id": 0}`, false},
+		{`{"id": 1, "name": "Alice"}`, false},
+		{`{"id": 1, "kind": "a"}`, false},
+		{`{"id": 1, "tags": ["x", "x"]}`, false},
+		{`{"id": 1, "other": true}`, false},
+	}
+
+	// A single validator and the root schema are shared by all the
+	// goroutines, while other schemas are compiled and registered.
+	validator := NewValidator(rootSchema).Cache(NewResultCache(4))
+	var wait sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wait.Add(2)
+		go func(worker int) {
+			defer wait.Done()
+			for iteration := 0; iteration < 50; iteration++ {
+				for _, d := range documents {
+					err := validator.Validate([]byte(d.document))
+					if valid := err == nil; valid != d.valid {
+						t.Errorf("Validate(%s) returned %v, expected valid: %v", d.document, err, d.valid)
+					}
+
+					result, err := validator.ValidateResult([]byte(d.document))
+					if err != nil {
+						t.Error(err)
+					} else if result.Valid() != d.valid {
+						t.Errorf("ValidateResult(%s) is valid: %v, expected %v", d.document, result.Valid(), d.valid)
+					}
+				}
+			}
+		}(worker)
+
+		go func(worker int) {
+			defer wait.Done()
+			for iteration := 0; iteration < 20; iteration++ {
+				_, err := AddResource("http://example.com/concurrent/other"+strconv.Itoa(worker)+".json", []byte(`{
+					"$id": "http://example.com/concurrent/root.json",
+					"properties": {"id": {"type": "string"}, "other": {"type": "string"}}
+				}`))
+				if err != nil {
+					t.Error(err)
+				}
+			}
+		}(worker)
+	}
+	wait.Wait()
+}
@@ -0,0 +1,61 @@
+package jsonvalidator
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// SchemaVersion is a root schema with the label of its version, which may
+// be empty.
+type SchemaVersion struct {
+	Schema  *RootJsonSchema
+	Version string
+}
+
+// VersionedSchema holds the current version of a schema that is replaced at
+// runtime, for services that roll schema versions forward and back without
+// restarting. Load() and Swap() are atomic, so a validation that loaded a
+// version keeps validating against it while the version is swapped. A
+// VersionedSchema is safe for concurrent use, and must not be copied after
+// its first use.
+type VersionedSchema struct {
+	current atomic.Value
+
+	// swapMutex serializes the swaps, since atomic.Value has no Swap()
+	// before go 1.17.
+	swapMutex sync.Mutex
+}
+
+// NewVersionedSchema creates a VersionedSchema that holds the given schema
+// and version label.
+func NewVersionedSchema(schema *RootJsonSchema, version string) *VersionedSchema {
+	vs := &VersionedSchema{}
+	vs.current.Store(SchemaVersion{schema, version})
+	return vs
+}
+
+// Load returns the current version of the schema.
+func (vs *VersionedSchema) Load() SchemaVersion {
+	current, _ := vs.current.Load().(SchemaVersion)
+	return current
+}
+
+// Swap makes the given schema and version label the current version, and
+// returns the version that it replaced, so it can be swapped back to roll
+// the change back.
+func (vs *VersionedSchema) Swap(schema *RootJsonSchema, version string) SchemaVersion {
+	vs.swapMutex.Lock()
+	defer vs.swapMutex.Unlock()
+
+	previous := vs.Load()
+	vs.current.Store(SchemaVersion{schema, version})
+	return previous
+}
+
+// Validate validates the json document in bytes against the current version
+// of the schema, and returns the label of the version that it was validated
+// against.
+func (vs *VersionedSchema) Validate(bytes []byte) (string, error) {
+	current := vs.Load()
+	return current.Version, NewValidator(current.Schema).Validate(bytes)
+}
@@ -0,0 +1,71 @@
+package jsonvalidator
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestVersionedSchema(t *testing.T) {
+	v1, err := NewRootJsonSchema([]byte(`{"type": "string"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2, err := NewRootJsonSchema([]byte(`{"type": "string", "maxLength": 3}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := NewVersionedSchema(v1, "v1")
+	if version, err := schema.Validate([]byte(`"Alice"`)); version != "v1" || err != nil {
+		t.Errorf("expected Alice to be valid against v1, got %s: %v", version, err)
+	}
+
+	previous := schema.Swap(v2, "v2")
+	if previous.Schema != v1 || previous.Version != "v1" {
+		t.Errorf("expected Swap() to return v1, got %v", previous)
+	}
+	if version, err := schema.Validate([]byte(`"Alice"`)); version != "v2" || err == nil {
+		t.Errorf("expected Alice to be invalid against v2, got %s: %v", version, err)
+	}
+
+	// Rolling back swaps the previous version in.
+	schema.Swap(previous.Schema, previous.Version)
+	if current := schema.Load(); current.Schema != v1 || current.Version != "v1" {
+		t.Errorf("expected the rollback to restore v1, got %v", current)
+	}
+}
+
+func TestVersionedSchemaConcurrentSwaps(t *testing.T) {
+	rootSchema, err := NewRootJsonSchema([]byte(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := NewVersionedSchema(rootSchema, "0")
+	seen := make(chan string, 100)
+
+	var wait sync.WaitGroup
+	for index := 1; index <= 100; index++ {
+		wait.Add(1)
+		go func(version string) {
+			defer wait.Done()
+			seen <- schema.Swap(rootSchema, version).Version
+			schema.Validate([]byte(`{}`))
+		}(strconv.Itoa(index))
+	}
+	wait.Wait()
+	close(seen)
+
+	// Every version is replaced exactly once, except for the last one.
+	replaced := map[string]bool{}
+	for version := range seen {
+		if replaced[version] {
+			t.Errorf("expected version %s to be replaced once", version)
+		}
+		replaced[version] = true
+	}
+	if last := schema.Load().Version; replaced[last] || len(replaced) != 100 {
+		t.Errorf("expected all the versions but %s to be replaced, got %v", last, replaced)
+	}
+}